@@ -0,0 +1,88 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+const (
+	// adaptiveMinLevel and adaptiveMaxLevel bound the levels chosen by
+	// adaptive mode to those the linked OpenZL accepts; adaptiveStartLevel,
+	// midway between them, is used for the first frame.
+	adaptiveMinLevel   = MinCompressionLevel
+	adaptiveMaxLevel   = MaxCompressionLevel
+	adaptiveStartLevel = (adaptiveMinLevel + adaptiveMaxLevel) / 2
+
+	// adaptiveLowRatio and adaptiveHighRatio are the smoothed compression
+	// ratios below which the level is lowered and above which it is raised.
+	adaptiveLowRatio  = 1.1
+	adaptiveHighRatio = 4.0
+
+	// adaptiveWeight is the weight of the newest frame in the moving average.
+	adaptiveWeight = 0.25
+)
+
+// adaptiveLevel picks a compression level for each frame from the ratios
+// observed on recent frames.
+//
+// Spending effort on incompressible data (media, encrypted or already
+// compressed content) costs throughput for no gain, while highly redundant
+// data such as logs rewards a higher level. Tracking a moving average of the
+// ratio and nudging the level one step at a time keeps throughput steady on
+// mixed content without reacting to every outlier frame.
+type adaptiveLevel struct {
+	level int     // Level for the next frame
+	ratio float64 // Exponential moving average of recent frame ratios
+}
+
+// newAdaptiveLevel returns a controller starting at adaptiveStartLevel.
+func newAdaptiveLevel() *adaptiveLevel {
+	return &adaptiveLevel{level: adaptiveStartLevel}
+}
+
+// observe records the sizes of a frame compressed at the current level and
+// returns the level to use for the next frame.
+func (a *adaptiveLevel) observe(inputSize, compressedSize int) int {
+	if inputSize <= 0 || compressedSize <= 0 {
+		return a.level
+	}
+
+	r := float64(inputSize) / float64(compressedSize)
+	if a.ratio == 0 {
+		a.ratio = r
+	} else {
+		a.ratio += adaptiveWeight * (r - a.ratio)
+	}
+
+	switch {
+	case a.ratio < adaptiveLowRatio && a.level > adaptiveMinLevel:
+		a.level--
+	case a.ratio > adaptiveHighRatio && a.level < adaptiveMaxLevel:
+		a.level++
+	}
+	return a.level
+}
+
+// reset forgets observed ratios and returns to the starting level.
+func (a *adaptiveLevel) reset() {
+	a.level = adaptiveStartLevel
+	a.ratio = 0
+}
+
+// WithAdaptiveLevel lets the Writer adjust the compression level frame by
+// frame based on the ratio of recent frames.
+//
+// When recent frames barely compress, the level is lowered so incompressible
+// stretches pass through quickly; when they compress very well, it is raised
+// to take advantage of the redundancy. The level moves by one step per frame,
+// between MinCompressionLevel and MaxCompressionLevel, starting midway
+// between them. Frames remain independent and the stream format is
+// unchanged, so any Reader can decode the output.
+func WithAdaptiveLevel(enabled bool) WriterOption {
+	return func(w *Writer) error {
+		if enabled {
+			w.adaptive = newAdaptiveLevel()
+		} else {
+			w.adaptive = nil
+		}
+		return nil
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestAdaptiveLevel(t *testing.T) {
+	a := newAdaptiveLevel()
+
+	// Incompressible frames walk the level down to the minimum
+	steps := adaptiveMaxLevel - adaptiveMinLevel + 10
+	for i := 0; i < steps; i++ {
+		a.observe(1000, 1010)
+	}
+	if a.level != adaptiveMinLevel {
+		t.Errorf("level after incompressible frames = %d, want %d", a.level, adaptiveMinLevel)
+	}
+
+	// Highly compressible frames walk it back up to the maximum
+	for i := 0; i < 2*steps; i++ {
+		a.observe(1000, 50)
+	}
+	if a.level != adaptiveMaxLevel {
+		t.Errorf("level after compressible frames = %d, want %d", a.level, adaptiveMaxLevel)
+	}
+
+	// A single outlier does not move the level
+	if level := a.observe(1000, 1000); level != adaptiveMaxLevel {
+		t.Errorf("level after one outlier = %d, want %d", level, adaptiveMaxLevel)
+	}
+
+	a.reset()
+	if a.level != adaptiveStartLevel {
+		t.Errorf("level after reset = %d, want %d", a.level, adaptiveStartLevel)
+	}
+}
+
+func TestWriterReader_AdaptiveLevel(t *testing.T) {
+	// Alternate incompressible and repetitive stretches
+	var data []byte
+	state := uint32(7)
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 2*MinFrameSize; j++ {
+			state = state*1664525 + 1013904223
+			data = append(data, byte(state>>24))
+		}
+		data = append(data, bytes.Repeat([]byte("log line "), 2*MinFrameSize/9)...)
+	}
+
+	var buf bytes.Buffer
+	writer, err := NewWriter(&buf, WithFrameSize(MinFrameSize), WithAdaptiveLevel(true))
+	if err != nil {
+		t.Fatalf("NewWriter() failed: %v", err)
+	}
+	if _, err := writer.Write(data); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	reader, err := NewReader(&buf)
+	if err != nil {
+		t.Fatalf("NewReader() failed: %v", err)
+	}
+	defer reader.Close()
+	got, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll() failed: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Error("decompressed data does not match")
+	}
+}
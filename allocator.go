@@ -0,0 +1,186 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"math/bits"
+	"sync"
+
+	"github.com/borischu/go-openzl/internal/cgo"
+)
+
+// Allocator provides the large internal buffers of the package, so that
+// embedders such as databases can account for them in their own memory
+// management. Set it for the whole package with WithAllocator.
+//
+// The buffers obtained from the Allocator are the frame buffer of each
+// Writer, the compressed-frame buffer of each Reader, and the scratch
+// buffers of Compressor.CompressBatch and Compressor.CompressReader. They
+// never escape to the caller: every buffer is returned with Free when the
+// operation finishes or the Writer or Reader is closed or reset. A Writer or
+// Reader that is garbage collected without Close does not return its buffer.
+//
+// Data returned to the caller, such as the result of Compress, is always
+// allocated on the Go heap, since its lifetime is up to the caller.
+//
+// Implementations must be safe for concurrent use.
+type Allocator interface {
+	// Alloc returns a slice of length n. Its contents are unspecified.
+	Alloc(n int) []byte
+
+	// Free releases a slice returned by Alloc, with the length and
+	// capacity Alloc gave it. The package does not use the slice again.
+	Free(b []byte)
+}
+
+// WithAllocator sets the Allocator for the internal buffers of every object
+// the package creates. If not specified, HeapAllocator is used.
+//
+// Example:
+//
+//	openzl.Init(openzl.WithAllocator(openzl.NewPoolAllocator()))
+func WithAllocator(a Allocator) InitOption {
+	return func(cfg *globalConfig) error {
+		if a == nil {
+			return &OptionError{Option: "WithAllocator", Value: a, Allowed: "non-nil"}
+		}
+		cfg.alloc = a
+		return nil
+	}
+}
+
+// allocBuf returns a buffer of n bytes from the package Allocator.
+func allocBuf(n int) []byte {
+	return defaults().alloc.Alloc(n)
+}
+
+// freeBuf returns a buffer obtained from allocBuf. A nil buffer is ignored.
+func freeBuf(b []byte) {
+	if b != nil {
+		defaults().alloc.Free(b)
+	}
+}
+
+// HeapAllocator allocates on the Go heap and leaves freeing to the garbage
+// collector. It is the default Allocator.
+var HeapAllocator Allocator = heapAllocator{}
+
+type heapAllocator struct{}
+
+func (heapAllocator) Alloc(n int) []byte { return make([]byte, n) }
+func (heapAllocator) Free([]byte)        {}
+
+// MallocAllocator allocates with C malloc. Its buffers are invisible to the
+// Go garbage collector, so they neither grow the heap nor raise its
+// collection target, and count towards the process's native memory instead.
+var MallocAllocator Allocator = mallocAllocator{}
+
+type mallocAllocator struct{}
+
+func (mallocAllocator) Alloc(n int) []byte { return cgo.Malloc(n) }
+func (mallocAllocator) Free(b []byte)      { cgo.FreeBytes(b) }
+
+// poolClasses is the number of size classes of a pool allocator, one per
+// power of two up to 1GB.
+const poolClasses = 31
+
+// PoolAllocator recycles buffers through one sync.Pool per power-of-two size
+// class. Buffers idle in a pool are dropped by the garbage collector, so it
+// reduces allocation churn without pinning memory.
+type PoolAllocator struct {
+	pools [poolClasses]sync.Pool
+}
+
+// NewPoolAllocator creates a PoolAllocator.
+func NewPoolAllocator() *PoolAllocator {
+	return &PoolAllocator{}
+}
+
+// poolClass returns the size class holding buffers of n bytes: the smallest
+// power of two not below n.
+func poolClass(n int) int {
+	if n <= 1 {
+		return 0
+	}
+	return bits.Len(uint(n - 1))
+}
+
+// Alloc returns a buffer of n bytes, reusing a freed one of the same size
+// class if available.
+func (p *PoolAllocator) Alloc(n int) []byte {
+	c := poolClass(n)
+	if c >= poolClasses {
+		return make([]byte, n)
+	}
+	if b, ok := p.pools[c].Get().(*[]byte); ok {
+		return (*b)[:n]
+	}
+	return make([]byte, n, 1<<c)
+}
+
+// Free makes b available to later calls of Alloc.
+func (p *PoolAllocator) Free(b []byte) {
+	c := poolClass(cap(b))
+	if c >= poolClasses || cap(b) != 1<<c {
+		return
+	}
+	b = b[:cap(b)]
+	p.pools[c].Put(&b)
+}
+
+// ArenaAllocator hands out buffers from large chunks, for embedders that
+// manage memory per query or per request. Free is a no-op; Reset rewinds the
+// arena so that its current chunk is reused for the next request. Buffers
+// larger than a quarter chunk are allocated on their own.
+type ArenaAllocator struct {
+	mu        sync.Mutex
+	chunkSize int
+	chunk     []byte // Chunk being filled
+	off       int    // Bytes used in chunk
+	allocated int64  // Bytes handed out since the last Reset
+}
+
+// NewArenaAllocator creates an ArenaAllocator that allocates chunkSize bytes
+// at a time, at least 4KB.
+func NewArenaAllocator(chunkSize int) *ArenaAllocator {
+	return &ArenaAllocator{chunkSize: max(chunkSize, 4096)}
+}
+
+// Alloc returns n bytes from the current chunk, starting a new chunk if it
+// is full.
+func (a *ArenaAllocator) Alloc(n int) []byte {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.allocated += int64(n)
+	if n > a.chunkSize/4 {
+		return make([]byte, n)
+	}
+	if a.chunk == nil || a.off+n > len(a.chunk) {
+		a.chunk = make([]byte, a.chunkSize)
+		a.off = 0
+	}
+	b := a.chunk[a.off : a.off+n : a.off+n]
+	a.off += n
+	return b
+}
+
+// Free does nothing; see Reset.
+func (a *ArenaAllocator) Free([]byte) {}
+
+// Allocated returns the number of bytes handed out since the last Reset.
+func (a *ArenaAllocator) Allocated() int64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.allocated
+}
+
+// Reset rewinds the arena, so that the memory of every buffer handed out
+// may be handed out again. It must only be called once no Writer, Reader,
+// or operation using the arena is active.
+func (a *ArenaAllocator) Reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.off = 0
+	a.allocated = 0
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"testing"
+)
+
+// countingAllocator tracks buffers that were allocated and not yet freed.
+type countingAllocator struct {
+	mu   sync.Mutex
+	live map[*byte]int
+	max  int
+}
+
+func (a *countingAllocator) Alloc(n int) []byte {
+	b := make([]byte, n)
+	if n > 0 {
+		a.mu.Lock()
+		a.live[&b[0]] = n
+		a.max = max(a.max, len(a.live))
+		a.mu.Unlock()
+	}
+	return b
+}
+
+func (a *countingAllocator) Free(b []byte) {
+	if len(b) == 0 {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, ok := a.live[&b[0]]; !ok {
+		panic("Free of a buffer not from Alloc")
+	}
+	delete(a.live, &b[0])
+}
+
+func TestWithAllocator(t *testing.T) {
+	resetDefaults(t)
+	alloc := &countingAllocator{live: make(map[*byte]int)}
+	if err := Init(WithAllocator(alloc)); err != nil {
+		t.Fatalf("Init() failed: %v", err)
+	}
+
+	data := bytes.Repeat([]byte("allocator accounting "), 20000)
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, WithFrameSize(MinFrameSize), WithStoredFallback(true))
+	if err != nil {
+		t.Fatalf("NewWriter() failed: %v", err)
+	}
+	w.Write(data)
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	r, err := NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("NewReader() failed: %v", err)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("round trip failed: %v", err)
+	}
+	r.Close()
+
+	c, err := NewCompressor()
+	if err != nil {
+		t.Fatalf("NewCompressor() failed: %v", err)
+	}
+	defer c.Close()
+	if _, err := c.CompressBatch([][]byte{data[:100], data[100:300]}); err != nil {
+		t.Fatalf("CompressBatch() failed: %v", err)
+	}
+	if _, err := c.CompressReader(bytes.NewReader(data)); err != nil {
+		t.Fatalf("CompressReader() failed: %v", err)
+	}
+
+	if alloc.max == 0 {
+		t.Error("allocator was never used")
+	}
+	if len(alloc.live) != 0 {
+		t.Errorf("%d buffers not freed", len(alloc.live))
+	}
+}
+
+func TestAllocators(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		alloc Allocator
+	}{
+		{"heap", HeapAllocator},
+		{"malloc", MallocAllocator},
+		{"pool", NewPoolAllocator()},
+		{"arena", NewArenaAllocator(1 << 16)},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, n := range []int{1, 100, 4096, 5000, 1 << 20} {
+				b := tt.alloc.Alloc(n)
+				if len(b) != n {
+					t.Fatalf("Alloc(%d) returned %d bytes", n, len(b))
+				}
+				b[0], b[n-1] = 1, 2
+				tt.alloc.Free(b)
+			}
+		})
+	}
+
+	arena := NewArenaAllocator(4096)
+	a, b := arena.Alloc(100), arena.Alloc(100)
+	if &a[99] == &b[0] || cap(a) != 100 {
+		t.Error("arena buffers overlap")
+	}
+	if arena.Allocated() != 200 {
+		t.Errorf("Allocated() = %d, want 200", arena.Allocated())
+	}
+	arena.Reset()
+	if c := arena.Alloc(100); &c[0] != &a[0] {
+		t.Error("Reset did not rewind the arena")
+	}
+}
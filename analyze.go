@@ -0,0 +1,245 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"fmt"
+	"math"
+)
+
+// Sampling of AnalyzeData: inputs up to analyzeMaxSample bytes are analyzed
+// whole; larger ones through evenly spaced blocks adding up to that size.
+const (
+	analyzeMaxSample = 1 << 20
+	analyzeBlockSize = 4096
+)
+
+// RecommendedAPI is the compression entry point AnalyzeData suggests.
+type RecommendedAPI int
+
+const (
+	// RecommendCompress suggests Compress, or a Writer, for serial data
+	// such as text, logs, or mixed binary formats.
+	RecommendCompress RecommendedAPI = iota
+
+	// RecommendCompressNumeric suggests CompressNumeric with elements of
+	// DataAnalysis.Stride bytes, for arrays of fixed-width numbers.
+	RecommendCompressNumeric
+
+	// RecommendStore suggests not compressing: the data looks random or
+	// already compressed. WithStoredFallback does this per frame.
+	RecommendStore
+)
+
+// String returns the name of the recommendation.
+func (r RecommendedAPI) String() string {
+	switch r {
+	case RecommendCompress:
+		return "Compress"
+	case RecommendCompressNumeric:
+		return "CompressNumeric"
+	case RecommendStore:
+		return "store"
+	default:
+		return fmt.Sprintf("RecommendedAPI(%d)", int(r))
+	}
+}
+
+// DataAnalysis describes the statistical structure of a payload, as
+// estimated by AnalyzeData.
+type DataAnalysis struct {
+	// Size is the size of the payload; SampledBytes is how much of it was
+	// analyzed.
+	Size, SampledBytes int
+
+	// Entropy is the order-0 entropy in bits per byte, from 0 for a single
+	// repeated byte to 8 for random data. It bounds what coding bytes
+	// independently of their context can achieve.
+	Entropy float64
+
+	// Stride is the element width in bytes, 2, 4, or 8, at which the data
+	// looks like an array of fixed-width numbers, or 1 if it does not.
+	Stride int
+
+	// StrideEntropy is the average entropy in bits per byte of the byte
+	// planes at Stride: byte i of every element taken together. Numeric
+	// arrays have planes much more predictable than their bytes overall.
+	StrideEntropy float64
+
+	// NumericLikelihood estimates, from 0 to 1, how likely the data is an
+	// array of Stride-byte numbers, which compress best with their type
+	// known.
+	NumericLikelihood float64
+
+	// Printable is the fraction of bytes that are printable ASCII or
+	// whitespace.
+	Printable float64
+
+	// Recommendation is the suggested entry point, and Reason a one-line
+	// explanation of it.
+	Recommendation RecommendedAPI
+	Reason         string
+}
+
+// String summarizes the analysis on one line, for logs.
+func (a DataAnalysis) String() string {
+	return fmt.Sprintf("%d bytes: entropy %.2f bits/byte, stride %d (%.2f bits/byte), numeric %.0f%%: %v (%s)",
+		a.Size, a.Entropy, a.Stride, a.StrideEntropy, 100*a.NumericLikelihood, a.Recommendation, a.Reason)
+}
+
+// AnalyzeData samples data and estimates its structure: its entropy, whether
+// it is an array of fixed-width numbers and of which width, and which API
+// should compress it best. It explains poor compression ratios and can
+// drive automatic selection between Compress and CompressNumeric.
+//
+// The analysis is statistical and cheap: it reads at most 1MB, in evenly
+// spaced 4KB blocks for larger inputs, and does not compress anything.
+// Numbers are assumed little-endian, as written by encoding/binary and by
+// Go's memory layout on common platforms.
+//
+// Example:
+//
+//	a, err := openzl.AnalyzeData(payload)
+//	if err != nil {
+//		return err
+//	}
+//	if a.Recommendation == openzl.RecommendCompressNumeric && a.Stride == 4 {
+//		values := unsafe.Slice((*uint32)(unsafe.Pointer(&payload[0])), len(payload)/4)
+//		return openzl.CompressNumeric(values)
+//	}
+//	return openzl.Compress(payload)
+//
+// Returns ErrEmptyInput if data is empty.
+func AnalyzeData(data []byte) (DataAnalysis, error) {
+	if len(data) == 0 {
+		return DataAnalysis{}, ErrEmptyInput
+	}
+	sample := sampleData(data)
+	a := DataAnalysis{
+		Size:         len(data),
+		SampledBytes: len(sample),
+		Entropy:      byteEntropy(sample, 0, 1),
+		Stride:       1,
+		Printable:    printableFraction(sample),
+	}
+	a.StrideEntropy = a.Entropy
+
+	// The gain of a width is how much more predictable its byte planes are
+	// than the bytes overall. A wider multiple of the true width gains about
+	// as much, a little more from having fewer samples per plane, so take the
+	// narrowest width close to the best gain.
+	var gains, planes [3]float64
+	best := 0.0
+	for i, w := range []int{2, 4, 8} {
+		if len(sample) < 16*w {
+			break
+		}
+		planes[i] = planeEntropy(sample, w)
+		gains[i] = a.Entropy - planes[i]
+		if gains[i] > best {
+			best = gains[i]
+		}
+	}
+	for i, w := range []int{2, 4, 8} {
+		if best > 0 && gains[i] >= 0.75*best {
+			a.Stride, a.StrideEntropy = w, planes[i]
+			break
+		}
+	}
+
+	// A gain of 0.1 bit per byte is within sampling noise; 0.6 is typical
+	// of numeric arrays.
+	gain := a.Entropy - a.StrideEntropy
+	switch {
+	case gain <= 0.1:
+		a.NumericLikelihood = 0
+	case gain >= 0.6:
+		a.NumericLikelihood = 1
+	default:
+		a.NumericLikelihood = (gain - 0.1) / 0.5
+	}
+	if a.Printable > 0.95 {
+		a.NumericLikelihood *= 1 - a.Printable
+	}
+	whole := len(data)%a.Stride == 0
+	if !whole {
+		a.NumericLikelihood /= 2
+	}
+
+	switch {
+	case a.Stride > 1 && whole && a.NumericLikelihood >= 0.5:
+		a.Recommendation = RecommendCompressNumeric
+		a.Reason = fmt.Sprintf("byte planes of %d-byte elements are %.1f bits/byte more predictable than the bytes overall", a.Stride, gain)
+	case a.Entropy >= 7.5 && a.StrideEntropy >= 7.5:
+		a.Recommendation = RecommendStore
+		a.Reason = "data looks random or already compressed"
+	case a.Stride > 1 && !whole && gain >= 0.6:
+		a.Reason = fmt.Sprintf("data looks like %d-byte numbers but its size is not a multiple of %d", a.Stride, a.Stride)
+	case a.Printable > 0.95:
+		a.Reason = "data looks like text"
+	default:
+		a.Reason = "no fixed-width numeric structure found"
+	}
+	return a, nil
+}
+
+// sampleData returns data if it is small enough to analyze whole, or evenly
+// spaced blocks of it, aligned to 8 bytes so that strides are preserved.
+func sampleData(data []byte) []byte {
+	if len(data) <= analyzeMaxSample {
+		return data
+	}
+	blocks := analyzeMaxSample / analyzeBlockSize
+	step := (len(data) - analyzeBlockSize) / (blocks - 1) &^ 7
+	sample := make([]byte, 0, analyzeMaxSample)
+	for i := range blocks {
+		off := i * step
+		sample = append(sample, data[off:off+analyzeBlockSize]...)
+	}
+	return sample
+}
+
+// byteEntropy returns the order-0 entropy in bits per byte of the bytes of
+// data at offsets off, off+step, off+2*step, and so on.
+func byteEntropy(data []byte, off, step int) float64 {
+	var counts [256]int
+	n := 0
+	for i := off; i < len(data); i += step {
+		counts[data[i]]++
+		n++
+	}
+	if n == 0 {
+		return 0
+	}
+	h := 0.0
+	for _, c := range counts {
+		if c > 0 {
+			p := float64(c) / float64(n)
+			h -= p * math.Log2(p)
+		}
+	}
+	return h
+}
+
+// planeEntropy returns the average entropy of the w byte planes of data.
+func planeEntropy(data []byte, w int) float64 {
+	data = data[:len(data)/w*w]
+	h := 0.0
+	for i := range w {
+		h += byteEntropy(data, i, w)
+	}
+	return h / float64(w)
+}
+
+// printableFraction returns the fraction of printable ASCII and whitespace
+// bytes in data.
+func printableFraction(data []byte) float64 {
+	n := 0
+	for _, b := range data {
+		if b >= 0x20 && b < 0x7f || b == '\n' || b == '\r' || b == '\t' {
+			n++
+		}
+	}
+	return float64(n) / float64(len(data))
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func TestAnalyzeData(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	counters := make([]byte, 0, 4*10000)
+	for i := range 10000 {
+		counters = binary.LittleEndian.AppendUint32(counters, uint32(i*3))
+	}
+	values := make([]byte, 0, 8*10000)
+	for range 10000 {
+		values = binary.LittleEndian.AppendUint64(values, uint64(rng.Intn(1<<16)))
+	}
+	random := make([]byte, 3<<20)
+	rng.Read(random)
+	text := bytes.Repeat([]byte("The quick brown fox jumps over the lazy dog.\n"), 1000)
+
+	for _, tc := range []struct {
+		name   string
+		data   []byte
+		want   RecommendedAPI
+		stride int
+	}{
+		{"uint32 counters", counters, RecommendCompressNumeric, 4},
+		{"uint64 values", values, RecommendCompressNumeric, 8},
+		{"text", text, RecommendCompress, 0},
+		{"random", random, RecommendStore, 0},
+	} {
+		a, err := AnalyzeData(tc.data)
+		if err != nil {
+			t.Fatalf("%s: AnalyzeData() failed: %v", tc.name, err)
+		}
+		if a.Recommendation != tc.want || tc.stride != 0 && a.Stride != tc.stride {
+			t.Errorf("%s: AnalyzeData() = %v, want %v with stride %d", tc.name, a, tc.want, tc.stride)
+		}
+	}
+
+	if a, _ := AnalyzeData(random); a.SampledBytes != analyzeMaxSample {
+		t.Errorf("AnalyzeData(3MB) sampled %d bytes, want %d", a.SampledBytes, analyzeMaxSample)
+	}
+	if a, _ := AnalyzeData(counters[:len(counters)-1]); a.Recommendation == RecommendCompressNumeric {
+		t.Errorf("AnalyzeData(truncated counters) = %v, want no numeric recommendation", a)
+	}
+	if _, err := AnalyzeData(nil); !errors.Is(err, ErrEmptyInput) {
+		t.Errorf("AnalyzeData(nil) error = %v, want ErrEmptyInput", err)
+	}
+}
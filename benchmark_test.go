@@ -5,6 +5,7 @@ package openzl
 
 import (
 	"bytes"
+	"fmt"
 	"testing"
 )
 
@@ -43,6 +44,34 @@ func BenchmarkDecompress(b *testing.B) {
 	}
 }
 
+func BenchmarkCompressParallel(b *testing.B) {
+	data := benchMediumText
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := Compress(data); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+func BenchmarkDecompressParallel(b *testing.B) {
+	compressed, err := Compress(benchMediumText)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := Decompress(compressed); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
 // Context API benchmarks (Phase 2)
 
 func BenchmarkCompressorCompress(b *testing.B) {
@@ -273,3 +302,50 @@ func BenchmarkDecompressorParallel(b *testing.B) {
 		}
 	})
 }
+
+// Batched cgo transitions: compressing many small messages one call at a
+// time versus a single CompressBatch call.
+
+func benchSmallMessages() [][]byte {
+	msgs := make([][]byte, 256)
+	for i := range msgs {
+		msgs[i] = []byte(fmt.Sprintf(`{"id":%d,"event":"click","ok":true}`, i))
+	}
+	return msgs
+}
+
+func BenchmarkCompressor_SmallMessagesLoop(b *testing.B) {
+	compressor, err := NewCompressor()
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer compressor.Close()
+
+	msgs := benchSmallMessages()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, msg := range msgs {
+			if _, err := compressor.Compress(msg); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkCompressor_SmallMessagesBatch(b *testing.B) {
+	compressor, err := NewCompressor()
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer compressor.Close()
+
+	msgs := benchSmallMessages()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := compressor.CompressBatch(msgs); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
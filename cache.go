@@ -0,0 +1,254 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"container/list"
+	"math"
+	"sync"
+)
+
+// DefaultCacheThreshold is the value size from which a CompressedCache
+// compresses values, unless set with WithCacheThreshold. Smaller values
+// rarely shrink enough to pay for decompressing them on every Get.
+const DefaultCacheThreshold = 1 << 10
+
+// CacheStats is a snapshot of the activity and contents of a
+// CompressedCache.
+type CacheStats struct {
+	// Hits and Misses count the calls to Get that found a value and those
+	// that did not.
+	Hits   int64
+	Misses int64
+
+	// Evictions is the number of values dropped to stay within the limit
+	// set with WithCacheMaxBytes.
+	Evictions int64
+
+	// Errors is the number of values dropped because they failed to
+	// decompress, each of which also counts as a miss.
+	Errors int64
+
+	// Entries is the number of values held, and Compressed how many of them
+	// are held compressed.
+	Entries    int
+	Compressed int
+
+	// Bytes is the memory held by the values as stored, and
+	// UncompressedBytes the size of the values as set. Their ratio is the
+	// memory saved by compression.
+	Bytes             int64
+	UncompressedBytes int64
+}
+
+// CacheOption configures a CompressedCache.
+type CacheOption func(*CompressedCache) error
+
+// WithCacheThreshold sets the size in bytes from which values are
+// compressed; smaller values are held as is. The default is
+// DefaultCacheThreshold.
+func WithCacheThreshold(n int) CacheOption {
+	return func(c *CompressedCache) error {
+		if n < 0 {
+			return rangeError("WithCacheThreshold", n, 0, math.MaxInt64)
+		}
+		c.threshold = n
+		return nil
+	}
+}
+
+// WithCacheMaxBytes bounds the memory held by the values, as stored. When a
+// Set would exceed it, the least recently used values are evicted. A value
+// larger than the limit by itself is not cached. Zero, the default, means
+// no limit.
+func WithCacheMaxBytes(n int64) CacheOption {
+	return func(c *CompressedCache) error {
+		if n < 0 {
+			return rangeError("WithCacheMaxBytes", n, 0, math.MaxInt64)
+		}
+		c.maxBytes = n
+		return nil
+	}
+}
+
+// cacheEntry is a value of a CompressedCache and its place in the LRU list.
+type cacheEntry struct {
+	key        string
+	data       []byte // Value, compressed if compressed is set
+	size       int    // Uncompressed size of the value
+	compressed bool
+}
+
+// CompressedCache is an in-memory key-value cache that holds large values
+// compressed, for services that cache big JSON or protobuf blobs and would
+// rather spend a little CPU than several times the memory.
+//
+// Set compresses values of at least the threshold size (see
+// WithCacheThreshold) and keeps the compressed form only if it is smaller;
+// Get decompresses transparently. Compression and decompression use the
+// package's shared pool of contexts, and run outside the cache's lock, so
+// concurrent callers do not wait on each other's compression.
+//
+// A CompressedCache is safe for concurrent use.
+//
+// Example:
+//
+//	cache, err := openzl.NewCompressedCache(openzl.WithCacheMaxBytes(512 << 20))
+//	if err != nil {
+//		return err
+//	}
+//	cache.Set(userID, profileJSON)
+//	...
+//	if b, ok := cache.Get(userID); ok {
+//		return b, nil
+//	}
+//	...
+//	s := cache.Stats()
+//	log.Printf("cache: %d entries, %d bytes held for %d", s.Entries, s.Bytes, s.UncompressedBytes)
+type CompressedCache struct {
+	threshold int
+	maxBytes  int64
+
+	mu      sync.Mutex
+	entries map[string]*list.Element // Of *cacheEntry
+	lru     list.List                // Most recently used first
+	stats   CacheStats
+}
+
+// NewCompressedCache returns an empty CompressedCache.
+//
+// Returns an *OptionError if an option is invalid.
+func NewCompressedCache(opts ...CacheOption) (*CompressedCache, error) {
+	c := &CompressedCache{
+		threshold: DefaultCacheThreshold,
+		entries:   make(map[string]*list.Element),
+	}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Set stores a copy of value under key, replacing any previous value.
+//
+// Returns the compression error, in which case the cache is unchanged.
+func (c *CompressedCache) Set(key string, value []byte) error {
+	e := &cacheEntry{key: key, size: len(value)}
+	if len(value) > 0 && len(value) >= c.threshold {
+		compressed, err := Compress(value)
+		if err != nil {
+			return err
+		}
+		if len(compressed) < len(value) {
+			e.data, e.compressed = compressed, true
+		}
+	}
+	if !e.compressed {
+		e.data = append([]byte(nil), value...)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remove(key)
+	if c.maxBytes > 0 && int64(len(e.data)) > c.maxBytes {
+		return nil
+	}
+	c.entries[key] = c.lru.PushFront(e)
+	c.account(e, 1)
+	for c.maxBytes > 0 && c.stats.Bytes > c.maxBytes {
+		c.remove(c.lru.Back().Value.(*cacheEntry).key)
+		c.stats.Evictions++
+	}
+	return nil
+}
+
+// Get returns the value stored under key, and whether there was one. The
+// returned slice belongs to the caller.
+//
+// A value that fails to decompress is dropped and reported as missing; see
+// CacheStats.Errors.
+func (c *CompressedCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	el, ok := c.entries[key]
+	if !ok {
+		c.stats.Misses++
+		c.mu.Unlock()
+		return nil, false
+	}
+	c.lru.MoveToFront(el)
+	e := el.Value.(*cacheEntry)
+	c.mu.Unlock()
+
+	// Entries are never modified once stored, so e can be read unlocked
+	if !e.compressed {
+		c.count(&c.stats.Hits)
+		return append([]byte(nil), e.data...), true
+	}
+	value, err := Decompress(e.data)
+	if err != nil {
+		c.mu.Lock()
+		if c.entries[key] == el {
+			c.remove(key)
+		}
+		c.stats.Errors++
+		c.stats.Misses++
+		c.mu.Unlock()
+		return nil, false
+	}
+	c.count(&c.stats.Hits)
+	return value, true
+}
+
+// Delete removes the value stored under key, if any.
+func (c *CompressedCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remove(key)
+}
+
+// Len returns the number of values held.
+func (c *CompressedCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// Stats returns a snapshot of the cache's activity and contents.
+func (c *CompressedCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// count increments the counter n of stats.
+func (c *CompressedCache) count(n *int64) {
+	c.mu.Lock()
+	*n++
+	c.mu.Unlock()
+}
+
+// remove drops the value stored under key, if any. It must be called with
+// mu held.
+func (c *CompressedCache) remove(key string) {
+	el, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	delete(c.entries, key)
+	c.lru.Remove(el)
+	c.account(el.Value.(*cacheEntry), -1)
+}
+
+// account adds e to the contents of stats, or subtracts it if sign is -1.
+// It must be called with mu held.
+func (c *CompressedCache) account(e *cacheEntry, sign int) {
+	c.stats.Entries += sign
+	if e.compressed {
+		c.stats.Compressed += sign
+	}
+	c.stats.Bytes += int64(sign * len(e.data))
+	c.stats.UncompressedBytes += int64(sign * e.size)
+}
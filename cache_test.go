@@ -0,0 +1,129 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/borischu/go-openzl/datagen"
+)
+
+func TestCompressedCache(t *testing.T) {
+	cache, err := NewCompressedCache()
+	if err != nil {
+		t.Fatalf("NewCompressedCache() failed: %v", err)
+	}
+
+	large := datagen.Logs(64 << 10)
+	small := []byte(`{"id":1}`)
+	cache.Set("large", large)
+	cache.Set("small", small)
+	cache.Set("empty", nil)
+
+	for key, want := range map[string][]byte{"large": large, "small": small, "empty": {}} {
+		got, ok := cache.Get(key)
+		if !ok || !bytes.Equal(got, want) {
+			t.Errorf("Get(%q) = %d bytes, %v; want %d bytes", key, len(got), ok, len(want))
+		}
+	}
+	if _, ok := cache.Get("missing"); ok {
+		t.Error("Get(missing) found a value")
+	}
+
+	// Only the large value is compressed, if compression shrinks it
+	frame, err := Compress(large)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compressed, bytesHeld := 1, len(frame)+len(small)
+	if len(frame) >= len(large) {
+		compressed, bytesHeld = 0, len(large)+len(small)
+	}
+	s := cache.Stats()
+	if s.Entries != 3 || s.Compressed != compressed || s.Hits != 3 || s.Misses != 1 {
+		t.Errorf("Stats() = %+v", s)
+	}
+	if s.UncompressedBytes != int64(len(large)+len(small)) || s.Bytes != int64(bytesHeld) {
+		t.Errorf("Stats() holds %d bytes for %d, want %d", s.Bytes, s.UncompressedBytes, bytesHeld)
+	}
+
+	// Values belong to the caller on both sides
+	got, _ := cache.Get("small")
+	got[0] = 'x'
+	if again, _ := cache.Get("small"); !bytes.Equal(again, small) {
+		t.Error("modifying a value returned by Get changed the cache")
+	}
+
+	cache.Set("large", small)
+	cache.Delete("small")
+	if s := cache.Stats(); cache.Len() != 2 || s.Compressed != 0 || s.UncompressedBytes != int64(len(small)) {
+		t.Errorf("after replace and delete: Len() = %d, Stats() = %+v", cache.Len(), s)
+	}
+}
+
+func TestCompressedCacheEviction(t *testing.T) {
+	cache, err := NewCompressedCache(WithCacheThreshold(0), WithCacheMaxBytes(100))
+	if err != nil {
+		t.Fatalf("NewCompressedCache() failed: %v", err)
+	}
+	value := datagen.Random(40)
+	for _, key := range []string{"a", "b"} {
+		cache.Set(key, value)
+	}
+	cache.Get("a") // b is now the least recently used
+	cache.Set("c", value)
+
+	if _, ok := cache.Get("b"); ok {
+		t.Error("least recently used value was not evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("Get(%q) missing", key)
+		}
+	}
+	if s := cache.Stats(); s.Evictions != 1 || s.Bytes > 100 {
+		t.Errorf("Stats() = %+v", s)
+	}
+
+	cache.Set("huge", datagen.Random(200))
+	if _, ok := cache.Get("huge"); ok || cache.Len() != 2 {
+		t.Errorf("value larger than the limit was cached")
+	}
+}
+
+func TestCompressedCacheOptions(t *testing.T) {
+	for _, opt := range []CacheOption{WithCacheThreshold(-1), WithCacheMaxBytes(-1)} {
+		var oe *OptionError
+		if _, err := NewCompressedCache(opt); !errors.As(err, &oe) {
+			t.Errorf("got error %v, want an *OptionError", err)
+		}
+	}
+}
+
+func TestCompressedCacheConcurrent(t *testing.T) {
+	cache, _ := NewCompressedCache(WithCacheMaxBytes(64 << 10))
+	value := datagen.Logs(8 << 10)
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 50 {
+				key := fmt.Sprint((g + i) % 16)
+				cache.Set(key, value)
+				if got, ok := cache.Get(key); ok && !bytes.Equal(got, value) {
+					t.Errorf("Get(%q) returned wrong value", key)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if s := cache.Stats(); s.Bytes > 64<<10 {
+		t.Errorf("cache holds %d bytes, limit %d", s.Bytes, 64<<10)
+	}
+}
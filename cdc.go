@@ -0,0 +1,100 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import "math/bits"
+
+// gearTable maps each byte value to a pseudo-random 64-bit value for the
+// gear rolling hash used by content-defined chunking.
+//
+// The table is generated from a fixed seed with splitmix64. Chunk boundaries,
+// and therefore frame contents, depend on it, so it must never change:
+// otherwise identical content written by different versions of this package
+// would no longer produce identical frames.
+var gearTable = func() [256]uint64 {
+	var t [256]uint64
+	state := uint64(0x6f70656e7a6c6364) // "openzlcd"
+	for i := range t {
+		state += 0x9e3779b97f4a7c15
+		z := state
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		t[i] = z ^ (z >> 31)
+	}
+	return t
+}()
+
+// cdcChunker finds content-defined chunk boundaries with a gear rolling hash.
+//
+// A boundary is declared after a byte where the low bits of the hash are all
+// zero, which happens on average once every avgSize bytes. Because the hash
+// only depends on the most recent 64 bytes, boundaries resynchronize shortly
+// after an insertion or deletion, so shifted copies of the same content are
+// cut into identical chunks.
+type cdcChunker struct {
+	minSize int    // No boundary is declared before this many bytes
+	mask    uint64 // Boundary when hash&mask == 0
+	hash    uint64 // Rolling hash state
+	pos     int    // Number of bytes of the current chunk already scanned
+	cut     int    // Pending boundary not yet flushed, or 0
+}
+
+// newCDCChunker returns a chunker producing chunks of avgSize bytes on average.
+// avgSize must be a power of two.
+func newCDCChunker(avgSize int) *cdcChunker {
+	return &cdcChunker{
+		minSize: avgSize / 4,
+		mask:    uint64(avgSize - 1),
+	}
+}
+
+// scan continues hashing chunk, the bytes of the current chunk so far, and
+// returns the length of the chunk up to the next boundary, or -1 if there is
+// no boundary yet. The boundary is remembered until reset is called.
+func (c *cdcChunker) scan(chunk []byte) int {
+	if c.cut > 0 {
+		return c.cut
+	}
+	for ; c.pos < len(chunk); c.pos++ {
+		c.hash = (c.hash << 1) + gearTable[chunk[c.pos]]
+		if c.pos+1 >= c.minSize && c.hash&c.mask == 0 {
+			c.pos++
+			c.cut = c.pos
+			return c.cut
+		}
+	}
+	return -1
+}
+
+// reset prepares the chunker for a new chunk.
+func (c *cdcChunker) reset() {
+	c.hash = 0
+	c.pos = 0
+	c.cut = 0
+}
+
+// WithContentDefinedChunking cuts frames at content-defined boundaries instead
+// of fixed offsets.
+//
+// With fixed-size frames, inserting a single byte near the start of a file
+// shifts every later frame boundary, so no frame matches the previous version.
+// Content-defined chunking places boundaries with a rolling hash over the data
+// itself, so repeated content across files or versions produces identical
+// compressed frames that a deduplication layer can store once.
+//
+// avgSize is the average frame size and must be a power of two between 1KB
+// and MaxFrameSize/2. Frames are at least avgSize/4 bytes (except the last)
+// and never larger than the configured frame size, which therefore must be at
+// least 2*avgSize.
+func WithContentDefinedChunking(avgSize int) WriterOption {
+	return func(w *Writer) error {
+		if avgSize < 1024 || avgSize > MaxFrameSize/2 || bits.OnesCount(uint(avgSize)) != 1 {
+			err := rangeError("WithContentDefinedChunking", avgSize, 1024, MaxFrameSize/2)
+			err.Allowed = "a power of two " + err.Allowed
+			return err
+		}
+		w.cdc = newCDCChunker(avgSize)
+		return nil
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+
+	"github.com/borischu/go-openzl/internal/cgo"
+)
+
+// Chunking of large one-shot inputs, see WithChunkThreshold.
+//
+// Compress splits inputs above the threshold into chunks of the threshold
+// size and stores their frames as the sections of a container of kind
+// containerChunks. Keeping every frame well under 2GB keeps its sizes within
+// the int range of 32-bit platforms and the C int lengths of OpenZL, and
+// bounds the working memory of each native call.
+const (
+	// DefaultChunkThreshold is the chunk threshold unless set with
+	// WithChunkThreshold.
+	DefaultChunkThreshold = 1 << 30
+
+	// MinChunkThreshold and MaxChunkThreshold bound WithChunkThreshold.
+	MinChunkThreshold = 1 << 20
+	MaxChunkThreshold = math.MaxInt32
+)
+
+// isChunked reports whether src is the output of Compress for an input above
+// the chunk threshold.
+func isChunked(src []byte) bool {
+	return len(src) > len(containerMagic) &&
+		string(src[:len(containerMagic)]) == containerMagic &&
+		containerKind(src[len(containerMagic)]) == containerChunks
+}
+
+// compressChunks compresses src in chunks of size bytes with ctx.
+//
+// The chunks are compressed into a Go buffer reused for all of them rather
+// than with CompressAlloc, whose C scratch buffer, too large to be kept by
+// the context, would be allocated and freed again for every chunk.
+func compressChunks(ctx *cgo.CCtx, src []byte, size int) ([]byte, error) {
+	bound, err := compressBound(size, 1)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, bound)
+	frames := make([][]byte, 0, (len(src)+size-1)/size)
+	for off := 0; off < len(src); off += size {
+		n, err := ctx.Compress(buf, src[off:min(off+size, len(src))])
+		if err != nil {
+			return nil, fmt.Errorf("chunk %d: %w", len(frames), err)
+		}
+		frames = append(frames, bytes.Clone(buf[:n]))
+	}
+	return encodeContainer(containerChunks, frames...), nil
+}
+
+// chunkSizes returns the frames of a chunked src, the decompressed size of
+// each, and their total.
+func chunkSizes(src []byte) (frames [][]byte, sizes []int, total int, err error) {
+	frames, err = decodeContainer(src, containerChunks, -1)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	sizes = make([]int, len(frames))
+	for i, frame := range frames {
+		if len(frame) == 0 {
+			return nil, nil, 0, fmt.Errorf("%w: empty chunk %d", ErrCorruptedData, i)
+		}
+		if sizes[i], err = cgo.GetDecompressedSize(frame); err != nil {
+			return nil, nil, 0, fmt.Errorf("chunk %d: get decompressed size: %w", i, err)
+		}
+		if total > math.MaxInt-sizes[i] {
+			return nil, nil, 0, fmt.Errorf("%w: chunks total more than %d bytes", ErrTooLarge, math.MaxInt)
+		}
+		total += sizes[i]
+	}
+	return frames, sizes, total, nil
+}
+
+// decompressChunks reassembles the output of compressChunks, decompressing
+// each frame with decompress into its place in the result. check is called
+// with the total size before it is allocated.
+func decompressChunks(src []byte, decompress func(dst, src []byte) (int, error), check func(int) error) ([]byte, error) {
+	frames, sizes, total, err := chunkSizes(src)
+	if err != nil {
+		return nil, err
+	}
+	if err := check(total); err != nil {
+		return nil, err
+	}
+	dst := make([]byte, total)
+	if err := decompressFrames(dst, frames, sizes, decompress); err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+// decompressFrames decompresses the chunk frames one after the other into
+// dst, which holds at least the sum of sizes.
+func decompressFrames(dst []byte, frames [][]byte, sizes []int, decompress func(dst, src []byte) (int, error)) error {
+	off := 0
+	for i, frame := range frames {
+		n, err := decompress(dst[off:off+sizes[i]], frame)
+		if err != nil {
+			return fmt.Errorf("chunk %d: decompress: %w", i, err)
+		}
+		if n != sizes[i] {
+			return fmt.Errorf("%w: chunk %d decompressed to %d bytes, header says %d", ErrCorruptedData, i, n, sizes[i])
+		}
+		off += n
+	}
+	return nil
+}
+
+// scatterChunks decompresses the output of compressChunks across dsts, as
+// DecompressScatter does for a single frame, with scatter decompressing
+// each frame into the buffers left. Nothing is written unless all of the
+// chunks fit.
+func scatterChunks(dsts [][]byte, src []byte, scatter func(dsts [][]byte, src []byte) (int, int, error)) (int, error) {
+	frames, sizes, total, err := chunkSizes(src)
+	if err != nil {
+		return 0, err
+	}
+	avail := 0
+	for _, dst := range dsts {
+		avail += len(dst)
+	}
+	if total > avail {
+		return 0, fmt.Errorf("%w: need %d bytes", ErrBufferTooSmall, total)
+	}
+
+	rest := slices.Clone(dsts)
+	written := 0
+	for i, frame := range frames {
+		n, _, err := scatter(rest, frame)
+		if err != nil {
+			return 0, fmt.Errorf("chunk %d: decompress: %w", i, err)
+		}
+		if n != sizes[i] {
+			return 0, fmt.Errorf("%w: chunk %d decompressed to %d bytes, header says %d", ErrCorruptedData, i, n, sizes[i])
+		}
+		written += n
+
+		// Skip the bytes written, which may end inside a buffer
+		for n > 0 {
+			k := min(n, len(rest[0]))
+			rest[0] = rest[0][k:]
+			n -= k
+			if len(rest[0]) == 0 {
+				rest = rest[1:]
+			}
+		}
+	}
+	return written, nil
+}
+
+// writeChunks decompresses the output of compressChunks chunk by chunk into
+// native buffers, each released once written to w.
+func writeChunks(w io.Writer, ctx *cgo.DCtx, src []byte) (int64, error) {
+	frames, err := decodeContainer(src, containerChunks, -1)
+	if err != nil {
+		return 0, err
+	}
+	var written int64
+	for i, frame := range frames {
+		n, err := writeNative(w, ctx, frame)
+		written += n
+		if err != nil {
+			return written, fmt.Errorf("chunk %d: %w", i, err)
+		}
+	}
+	return written, nil
+}
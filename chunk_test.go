@@ -0,0 +1,115 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/borischu/go-openzl/datagen"
+)
+
+func TestCompressChunked(t *testing.T) {
+	resetDefaults(t)
+	if err := Init(WithChunkThreshold(MinChunkThreshold - 1)); !errors.Is(err, ErrInvalidParameter) {
+		t.Fatalf("Init(WithChunkThreshold(too small)) error = %v, want ErrInvalidParameter", err)
+	}
+	if err := Init(WithChunkThreshold(MinChunkThreshold)); err != nil {
+		t.Fatalf("Init() failed: %v", err)
+	}
+
+	small := datagen.Text(MinChunkThreshold)
+	compressed, err := Compress(small)
+	if err != nil {
+		t.Fatalf("Compress(threshold bytes) failed: %v", err)
+	}
+	if isChunked(compressed) {
+		t.Error("Compress() chunked an input of exactly the threshold")
+	}
+
+	data := datagen.Text(3*MinChunkThreshold + 1000)
+	compressed, err = Compress(data)
+	if err != nil {
+		t.Fatalf("Compress() failed: %v", err)
+	}
+	if !isChunked(compressed) {
+		t.Fatal("Compress() did not chunk an input above the threshold")
+	}
+	if frames, _, _, err := chunkSizes(compressed); err != nil || len(frames) != 4 {
+		t.Errorf("Compress() wrote %d chunks (%v), want 4", len(frames), err)
+	}
+	if size, err := DecompressedSize(compressed); err != nil || size != int64(len(data)) {
+		t.Errorf("DecompressedSize() = %d, %v, want %d", size, err, len(data))
+	}
+
+	out, err := Decompress(compressed)
+	if err != nil {
+		t.Fatalf("Decompress() failed: %v", err)
+	}
+	if !bytes.Equal(out, data) {
+		t.Fatal("Decompress() output differs from input")
+	}
+
+	d, err := NewDecompressor()
+	if err != nil {
+		t.Fatalf("NewDecompressor() failed: %v", err)
+	}
+	defer d.Close()
+	if out, err := d.Decompress(compressed); err != nil || !bytes.Equal(out, data) {
+		t.Errorf("Decompressor.Decompress() round trip failed: %v", err)
+	}
+
+	limited, err := NewDecompressor(WithMemoryBudget(int64(len(data))))
+	if err != nil {
+		t.Fatalf("NewDecompressor() failed: %v", err)
+	}
+	defer limited.Close()
+	if _, err := limited.Decompress(compressed); !errors.Is(err, ErrMemoryBudgetExceeded) {
+		t.Errorf("Decompressor.Decompress() over budget error = %v, want ErrMemoryBudgetExceeded", err)
+	}
+
+	// The large-output APIs must all recognise chunked input
+	var buf bytes.Buffer
+	if n, err := DecompressTo(&buf, compressed); err != nil || n != int64(len(data)) || !bytes.Equal(buf.Bytes(), data) {
+		t.Errorf("DecompressTo() = %d, %v", n, err)
+	}
+	path := filepath.Join(t.TempDir(), "out")
+	if n, err := DecompressToFile(path, compressed); err != nil || n != int64(len(data)) {
+		t.Errorf("DecompressToFile() = %d, %v", n, err)
+	} else if got, _ := os.ReadFile(path); !bytes.Equal(got, data) {
+		t.Error("DecompressToFile() output differs from input")
+	}
+	// Buffers that do not line up with the chunks
+	pages := make([][]byte, len(data)/(MinChunkThreshold/3)+1)
+	for i := range pages {
+		pages[i] = make([]byte, MinChunkThreshold/3)
+	}
+	for name, scatter := range map[string]func([][]byte, []byte) (int, error){
+		"DecompressScatter":              DecompressScatter,
+		"Decompressor.DecompressScatter": d.DecompressScatter,
+	} {
+		if n, err := scatter(pages, compressed); err != nil || n != len(data) || !bytes.Equal(bytes.Join(pages, nil)[:n], data) {
+			t.Errorf("%s() = %d, %v", name, n, err)
+		}
+		if _, err := scatter(pages[:3], compressed); !errors.Is(err, ErrBufferTooSmall) {
+			t.Errorf("%s(too small) error = %v, want ErrBufferTooSmall", name, err)
+		}
+	}
+	tenants, err := NewTenantRegistry(WithDefaultTenant(TenantConfig{}))
+	if err != nil {
+		t.Fatalf("NewTenantRegistry() failed: %v", err)
+	}
+	defer tenants.Close()
+	if out, err := tenants.DecompressForTenant("a", compressed); err != nil || !bytes.Equal(out, data) {
+		t.Errorf("DecompressForTenant() round trip failed: %v", err)
+	}
+
+	truncated := compressed[:len(compressed)-10]
+	if _, err := Decompress(truncated); !errors.Is(err, ErrCorruptedData) {
+		t.Errorf("Decompress(truncated) error = %v, want ErrCorruptedData", err)
+	}
+}
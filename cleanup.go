@@ -0,0 +1,115 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// CleanupPolicy selects what happens when a Compressor, Decompressor, Writer,
+// or Reader is garbage collected without having been closed.
+//
+// Whatever the policy, the native context of the object is released by the
+// garbage collector, so a forgotten Close does not leak C memory. The policy
+// only decides whether the mistake is reported. A Writer that was not closed
+// has lost its buffered data and end-of-stream marker all the same.
+type CleanupPolicy int32
+
+const (
+	// CleanupSilent releases the object without reporting it. This is the
+	// default.
+	CleanupSilent CleanupPolicy = iota
+
+	// CleanupLog releases the object and logs its type and where it was
+	// created with the standard log package.
+	CleanupLog
+
+	// CleanupPanic panics with the type of the object and where it was
+	// created. The panic happens on the runtime's cleanup goroutine and
+	// terminates the program, which makes a missing Close impossible to
+	// overlook in tests.
+	CleanupPanic
+)
+
+// String returns the name of the policy, such as "log".
+func (p CleanupPolicy) String() string {
+	switch p {
+	case CleanupSilent:
+		return "silent"
+	case CleanupLog:
+		return "log"
+	case CleanupPanic:
+		return "panic"
+	}
+	return fmt.Sprintf("CleanupPolicy(%d)", int32(p))
+}
+
+var cleanupPolicy atomic.Int32
+
+// SetCleanupPolicy sets what happens when an object of the package is garbage
+// collected without Close. Unlike the defaults set by Init, the policy may be
+// changed at any time; it applies to every object collected afterwards,
+// including objects created before the change.
+//
+// Example:
+//
+//	func TestMain(m *testing.M) {
+//		openzl.SetCleanupPolicy(openzl.CleanupPanic)
+//		os.Exit(m.Run())
+//	}
+func SetCleanupPolicy(p CleanupPolicy) {
+	cleanupPolicy.Store(int32(p))
+}
+
+// unclosed is the argument of the cleanup that releases an object that was
+// garbage collected without Close. It must not reference the object itself,
+// or the object would never be collected.
+type unclosed struct {
+	kind string // Type reported, such as "Compressor"
+	site string // file:line of the first caller outside the package
+	free func() // Releases the native context
+}
+
+// watchUnclosed registers a cleanup that calls free and reports the object
+// according to the cleanup policy if obj is garbage collected first. Close
+// methods stop the returned cleanup.
+func watchUnclosed[T any](obj *T, kind string, free func()) (runtime.Cleanup, *unclosed) {
+	u := &unclosed{kind: kind, site: callerSite(), free: free}
+	return runtime.AddCleanup(obj, (*unclosed).collected, u), u
+}
+
+// collected runs on the runtime's cleanup goroutine.
+func (u *unclosed) collected() {
+	u.free()
+	msg := fmt.Sprintf("openzl: %s created at %s was garbage collected without Close", u.kind, u.site)
+	switch CleanupPolicy(cleanupPolicy.Load()) {
+	case CleanupLog:
+		log.Print(msg)
+	case CleanupPanic:
+		panic(msg)
+	}
+}
+
+// callerSite returns the file:line of the innermost caller outside the
+// package, so that an object created by NewWriter is attributed to the
+// caller of NewWriter rather than to NewWriter itself. Test files of the
+// package count as callers.
+func callerSite() string {
+	pcs := make([]uintptr, 16)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		f, more := frames.Next()
+		internal := strings.HasPrefix(f.Function, "github.com/borischu/go-openzl.") && !strings.HasSuffix(f.File, "_test.go")
+		if f.Function != "" && !internal {
+			return fmt.Sprintf("%s:%d", f.File, f.Line)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a bytes.Buffer safe for the cleanup goroutine to write.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// captureCleanupLog sets the CleanupLog policy and returns the log output.
+func captureCleanupLog(t *testing.T) *syncBuffer {
+	t.Helper()
+	out, prev := &syncBuffer{}, log.Writer()
+	log.SetOutput(out)
+	SetCleanupPolicy(CleanupLog)
+	t.Cleanup(func() {
+		SetCleanupPolicy(CleanupSilent)
+		log.SetOutput(prev)
+	})
+	return out
+}
+
+// collect runs the garbage collector until want appears in out.
+func collect(t *testing.T, out *syncBuffer, want string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(out.String(), want) {
+		if time.Now().After(deadline) {
+			t.Fatalf("no %q reported, log: %q", want, out.String())
+		}
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCleanupPolicy_Log(t *testing.T) {
+	out := captureCleanupLog(t)
+
+	func() {
+		if _, err := NewCompressor(); err != nil {
+			t.Fatalf("NewCompressor() failed: %v", err)
+		}
+		if _, err := NewWriter(io.Discard); err != nil {
+			t.Fatalf("NewWriter() failed: %v", err)
+		}
+	}()
+	collect(t, out, "Compressor created at ")
+	collect(t, out, "Writer created at ")
+	if !strings.Contains(out.String(), "cleanup_test.go:") {
+		t.Errorf("creation site not in log: %q", out.String())
+	}
+}
+
+func TestCleanupPolicy_Closed(t *testing.T) {
+	out := captureCleanupLog(t)
+
+	func() {
+		c, err := NewCompressor()
+		if err != nil {
+			t.Fatalf("NewCompressor() failed: %v", err)
+		}
+		c.Close()
+		if _, err := NewDecompressor(); err != nil {
+			t.Fatalf("NewDecompressor() failed: %v", err)
+		}
+	}()
+	collect(t, out, "Decompressor created at ")
+	if strings.Contains(out.String(), "Compressor created") {
+		t.Errorf("closed Compressor reported: %q", out.String())
+	}
+}
+
+func TestCleanupPolicy_String(t *testing.T) {
+	for p, want := range map[CleanupPolicy]string{CleanupSilent: "silent", CleanupLog: "log", CleanupPanic: "panic", 7: "CleanupPolicy(7)"} {
+		if got := p.String(); got != want {
+			t.Errorf("String() = %q, want %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/borischu/go-openzl/compat"
+)
+
+// runCompat implements `zlgo compat` and returns the exit status.
+func runCompat(args []string) int {
+	fs := flag.NewFlagSet("compat", flag.ExitOnError)
+	write := fs.String("write", "", "write a corpus of every compression mode to `dir`")
+	version := fs.String("version", "", "version label stored in the written corpus's manifest")
+	verify := fs.String("verify", "", "verify the corpus in `dir`, or every corpus in its subdirectories")
+	fs.Parse(args)
+
+	if (*write == "") == (*verify == "") {
+		fmt.Fprintln(os.Stderr, "zlgo compat: exactly one of -write and -verify is required")
+		fs.Usage()
+		return 2
+	}
+
+	if *write != "" {
+		if *version == "" {
+			fmt.Fprintln(os.Stderr, "zlgo compat: -write requires -version")
+			return 2
+		}
+		if err := compat.WriteCorpus(*write, *version); err != nil {
+			fmt.Fprintf(os.Stderr, "zlgo compat: %v\n", err)
+			return 2
+		}
+		fmt.Printf("wrote %d modes to %s\n", len(compat.Modes()), *write)
+		return 0
+	}
+
+	failures, err := compat.VerifyAll(*verify)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "zlgo compat: %v\n", err)
+		return 2
+	}
+	for _, f := range failures {
+		fmt.Println(f)
+	}
+	if len(failures) > 0 {
+		return 1
+	}
+	fmt.Println("all corpora decoded")
+	return 0
+}
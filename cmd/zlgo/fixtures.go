@@ -0,0 +1,458 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/borischu/go-openzl"
+	"github.com/borischu/go-openzl/datagen"
+	"github.com/borischu/go-openzl/internal/cgo"
+	"github.com/borischu/go-openzl/spec"
+)
+
+// fixtureIndexFile is the name of the manifest listing the fixtures of a
+// directory.
+const fixtureIndexFile = "manifest.json"
+
+// fixtureIndex is the manifest of a fixture directory.
+type fixtureIndex struct {
+	Generator     string   `json:"generator"`
+	OpenZLVersion string   `json:"openzl_version"`
+	StreamFormat  int      `json:"stream_format"` // spec.Version of stream fixtures
+	ByteOrder     string   `json:"byte_order"`    // Of numeric expected outputs
+	Fixtures      []string `json:"fixtures"`      // Each described by <name>.json
+}
+
+// fixtureManifest describes one fixture: a compressed file and the outputs
+// it must decompress to.
+type fixtureManifest struct {
+	Name             string          `json:"name"`
+	API              string          `json:"api"`    // "oneshot", "stream", "typed", or "multi"
+	Format           string          `json:"format"` // "frame" or "stream"
+	Description      string          `json:"description"`
+	Compressed       string          `json:"compressed"`
+	CompressedSize   int             `json:"compressed_size"`
+	CompressedSHA256 string          `json:"compressed_sha256"`
+	Outputs          []fixtureOutput `json:"outputs"`
+}
+
+// fixtureOutput is one expected decompressed output of a fixture.
+type fixtureOutput struct {
+	File         string `json:"file"`
+	Type         string `json:"type"`                    // "serial" or "numeric"
+	ElementType  string `json:"element_type,omitempty"`  // Go name of numeric elements, such as "int32"
+	ElementWidth int    `json:"element_width,omitempty"` // In bytes
+	Elements     int    `json:"elements"`
+	Size         int    `json:"size"`
+	SHA256       string `json:"sha256"`
+}
+
+// fixtureData is an input of a fixture, and therefore one of its expected
+// outputs, with numbers encoded little-endian.
+type fixtureData struct {
+	typ      string
+	elemType string
+	width    int
+	elements int
+	data     []byte
+}
+
+// serialData returns the fixture data of untyped bytes.
+func serialData(b []byte) fixtureData {
+	return fixtureData{typ: "serial", width: 1, elements: len(b), data: b}
+}
+
+// numericData returns the fixture data of values.
+func numericData[T openzl.Numeric](values []T) fixtureData {
+	var zero T
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, values)
+	return fixtureData{
+		typ:      "numeric",
+		elemType: fmt.Sprintf("%T", zero),
+		width:    binary.Size(zero),
+		elements: len(values),
+		data:     buf.Bytes(),
+	}
+}
+
+// fixture is one entry of the interop matrix.
+type fixture struct {
+	name        string
+	api         string
+	description string
+	inputs      []fixtureData
+	compress    func(inputs []fixtureData) ([]byte, error)
+}
+
+// format returns the container format of the fixture's compressed file.
+func (f fixture) format() string {
+	if f.api == "stream" {
+		return "stream"
+	}
+	return "frame"
+}
+
+// oneShotFixture returns a fixture compressed by Compress.
+func oneShotFixture(name, description string, data []byte) fixture {
+	return fixture{
+		name:        name,
+		api:         "oneshot",
+		description: description,
+		inputs:      []fixtureData{serialData(data)},
+		compress:    func(in []fixtureData) ([]byte, error) { return openzl.Compress(in[0].data) },
+	}
+}
+
+// levelFixture returns a fixture compressed by a Compressor at level.
+func levelFixture(name string, level int, data []byte) fixture {
+	return fixture{
+		name:        name,
+		api:         "oneshot",
+		description: fmt.Sprintf("Compressor with WithCompressionLevel(%d).", level),
+		inputs:      []fixtureData{serialData(data)},
+		compress: func(in []fixtureData) ([]byte, error) {
+			c, err := openzl.NewCompressor(openzl.WithCompressionLevel(level))
+			if err != nil {
+				return nil, err
+			}
+			defer c.Close()
+			return c.Compress(in[0].data)
+		},
+	}
+}
+
+// streamFixture returns a fixture compressed by a Writer.
+func streamFixture(name, description string, data []byte, opts ...openzl.WriterOption) fixture {
+	return fixture{
+		name:        name,
+		api:         "stream",
+		description: description,
+		inputs:      []fixtureData{serialData(data)},
+		compress: func(in []fixtureData) ([]byte, error) {
+			return openzl.CompressFrom(bytes.NewReader(in[0].data), opts...)
+		},
+	}
+}
+
+// typedFixture returns a fixture compressed by CompressNumeric.
+func typedFixture[T openzl.Numeric](values []T) fixture {
+	d := numericData(values)
+	return fixture{
+		name:        "typed-" + d.elemType,
+		api:         "typed",
+		description: fmt.Sprintf("CompressNumeric of %d %s values.", len(values), d.elemType),
+		inputs:      []fixtureData{d},
+		compress:    func([]fixtureData) ([]byte, error) { return openzl.CompressNumeric(values) },
+	}
+}
+
+// convert converts each element of a slice.
+func convert[From, To openzl.Numeric](values []From) []To {
+	out := make([]To, len(values))
+	for i, v := range values {
+		out[i] = To(v)
+	}
+	return out
+}
+
+// fixtures returns the interop matrix. Inputs are deterministic, so that
+// every run writes the same expected outputs; compressed files may differ
+// between OpenZL releases.
+func fixtures() []fixture {
+	seq := datagen.Int64Sequence(4096)
+	return []fixture{
+		oneShotFixture("oneshot-logs", "Compress of log lines.", datagen.Logs(64<<10)),
+		oneShotFixture("oneshot-text", "Compress of English-like text.", datagen.Text(32<<10)),
+		oneShotFixture("oneshot-random", "Compress of incompressible bytes.", datagen.Random(4<<10)),
+		levelFixture("oneshot-level-min", openzl.MinCompressionLevel, datagen.CSV(32<<10)),
+		levelFixture("oneshot-level-max", openzl.MaxCompressionLevel, datagen.CSV(32<<10)),
+		{
+			name:        "oneshot-gather",
+			api:         "oneshot",
+			description: "Compress2D of a header and a body; decompresses to their concatenation.",
+			inputs:      []fixtureData{serialData(append(datagen.CSV(1<<10), datagen.Logs(16<<10)...))},
+			compress: func(in []fixtureData) ([]byte, error) {
+				split := len(datagen.CSV(1 << 10))
+				return openzl.Compress2D([][]byte{in[0].data[:split], in[0].data[split:]})
+			},
+		},
+		streamFixture("stream", "Writer with default options: a single frame.", datagen.Text(64<<10)),
+		streamFixture("stream-frames", "Writer with WithFrameSize(MinFrameSize): many frames.",
+			datagen.Logs(200<<10), openzl.WithFrameSize(openzl.MinFrameSize)),
+		streamFixture("stream-checksum", "Writer with WithFrameChecksum(true): CRC32C trailers.",
+			datagen.CSV(64<<10), openzl.WithFrameSize(openzl.MinFrameSize), openzl.WithFrameChecksum(true)),
+		streamFixture("stream-stored", "Writer with WithStoredFallback(true): stored frames.",
+			datagen.Random(16<<10), openzl.WithFrameSize(openzl.MinFrameSize), openzl.WithStoredFallback(true)),
+		typedFixture(convert[int64, int8](seq)),
+		typedFixture(convert[int64, uint8](seq)),
+		typedFixture(convert[int64, int16](seq)),
+		typedFixture(convert[int64, uint16](seq)),
+		typedFixture(convert[int64, int32](seq)),
+		typedFixture(convert[int64, uint32](seq)),
+		typedFixture(seq),
+		typedFixture(convert[int64, uint64](datagen.Timestamps(4096))),
+		typedFixture(convert[float64, float32](datagen.Float64Walk(4096))),
+		typedFixture(datagen.Float64Walk(4096)),
+		{
+			name:        "multi",
+			api:         "multi",
+			description: "One frame of four typed inputs: serial, int64, float64, and uint16; outputs in input order.",
+			inputs: []fixtureData{
+				serialData(datagen.Logs(8 << 10)),
+				numericData(datagen.Timestamps(1024)),
+				numericData(datagen.Float64Walk(1024)),
+				numericData(convert[int64, uint16](seq)),
+			},
+			compress: compressMulti,
+		},
+	}
+}
+
+// compressMulti compresses the inputs into a single multi-input frame.
+// Numeric inputs are passed in native byte order, which is little-endian on
+// every platform the fixtures are generated on.
+func compressMulti(inputs []fixtureData) ([]byte, error) {
+	ctx, err := cgo.NewCCtx()
+	if err != nil {
+		return nil, err
+	}
+	defer ctx.Free()
+
+	var trefs []*cgo.TypedRef
+	defer func() {
+		for _, t := range trefs {
+			t.Free()
+		}
+	}()
+	size := 0
+	for _, in := range inputs {
+		var tref *cgo.TypedRef
+		switch in.width {
+		case 1:
+			tref, err = cgo.NewTypedRefSerial(in.data)
+		case 2:
+			tref, err = cgo.NewTypedRefNumeric(fromLE[uint16](in))
+		case 8:
+			tref, err = cgo.NewTypedRefNumeric(fromLE[uint64](in))
+		default:
+			err = fmt.Errorf("unsupported element width %d", in.width)
+		}
+		if err != nil {
+			return nil, err
+		}
+		trefs = append(trefs, tref)
+		size += len(in.data)
+	}
+
+	// Typed compression may need more space than CompressBound for raw bytes
+	dst := make([]byte, 2*cgo.CompressBound(size))
+	n, err := ctx.CompressMultiTypedRef(dst, trefs)
+	if err != nil {
+		return nil, err
+	}
+	return dst[:n], nil
+}
+
+// fromLE decodes the little-endian elements of d.
+func fromLE[T uint16 | uint64](d fixtureData) []T {
+	out := make([]T, d.elements)
+	binary.Read(bytes.NewReader(d.data), binary.LittleEndian, out)
+	return out
+}
+
+// runFixtures implements `zlgo fixtures` and returns the exit status.
+func runFixtures(args []string) int {
+	fs := flag.NewFlagSet("fixtures", flag.ExitOnError)
+	out := fs.String("out", "", "write the interop fixtures to `dir`")
+	verify := fs.String("verify", "", "check that the fixtures in `dir` decompress to their expected outputs")
+	fs.Parse(args)
+
+	if (*out == "") == (*verify == "") || fs.NArg() != 0 {
+		fmt.Fprintln(os.Stderr, "zlgo fixtures: exactly one of -out and -verify is required")
+		fs.Usage()
+		return 2
+	}
+
+	if *out != "" {
+		n, err := writeFixtures(*out)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "zlgo fixtures: %v\n", err)
+			return 2
+		}
+		fmt.Printf("wrote %d fixtures to %s\n", n, *out)
+		return 0
+	}
+
+	failures, err := verifyFixtures(*verify)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "zlgo fixtures: %v\n", err)
+		return 2
+	}
+	for _, f := range failures {
+		fmt.Println(f)
+	}
+	if len(failures) > 0 {
+		return 1
+	}
+	fmt.Println("all fixtures decoded")
+	return 0
+}
+
+// writeFixtures writes the compressed file, expected outputs, and manifest
+// of every fixture to dir, which is created if needed, and returns the
+// number of fixtures.
+func writeFixtures(dir string) (int, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return 0, err
+	}
+	index := fixtureIndex{
+		Generator:     "zlgo fixtures",
+		OpenZLVersion: openzl.OpenZLVersion(),
+		StreamFormat:  spec.Version,
+		ByteOrder:     "little",
+	}
+	for _, f := range fixtures() {
+		compressed, err := f.compress(f.inputs)
+		if err != nil {
+			return 0, fmt.Errorf("%s: compress: %w", f.name, err)
+		}
+		m := fixtureManifest{
+			Name:             f.name,
+			API:              f.api,
+			Format:           f.format(),
+			Description:      f.description,
+			Compressed:       f.name + ".zl",
+			CompressedSize:   len(compressed),
+			CompressedSHA256: sha256Hex(compressed),
+		}
+		if err := os.WriteFile(filepath.Join(dir, m.Compressed), compressed, 0o644); err != nil {
+			return 0, err
+		}
+		for i, in := range f.inputs {
+			o := fixtureOutput{
+				File:     fmt.Sprintf("%s.%d.raw", f.name, i),
+				Type:     in.typ,
+				Elements: in.elements,
+				Size:     len(in.data),
+				SHA256:   sha256Hex(in.data),
+			}
+			if in.typ == "numeric" {
+				o.ElementType, o.ElementWidth = in.elemType, in.width
+			}
+			if err := os.WriteFile(filepath.Join(dir, o.File), in.data, 0o644); err != nil {
+				return 0, err
+			}
+			m.Outputs = append(m.Outputs, o)
+		}
+		if err := writeJSON(filepath.Join(dir, f.name+".json"), m); err != nil {
+			return 0, err
+		}
+		index.Fixtures = append(index.Fixtures, f.name)
+	}
+	return len(index.Fixtures), writeJSON(filepath.Join(dir, fixtureIndexFile), index)
+}
+
+// verifyFixtures decompresses every fixture of dir and compares its outputs
+// with the expected ones. It returns one message per fixture that does not
+// match; the error is non-nil only if the fixtures cannot be read.
+func verifyFixtures(dir string) ([]string, error) {
+	var index fixtureIndex
+	if err := readJSON(filepath.Join(dir, fixtureIndexFile), &index); err != nil {
+		return nil, err
+	}
+	var failures []string
+	for _, name := range index.Fixtures {
+		var m fixtureManifest
+		if err := readJSON(filepath.Join(dir, name+".json"), &m); err != nil {
+			return nil, err
+		}
+		compressed, err := os.ReadFile(filepath.Join(dir, m.Compressed))
+		if err != nil {
+			return nil, err
+		}
+		if err := checkFixture(m, compressed); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	return failures, nil
+}
+
+// checkFixture decompresses compressed as described by m and compares the
+// outputs with the sizes and digests of m.
+func checkFixture(m fixtureManifest, compressed []byte) error {
+	var outputs [][]byte
+	var err error
+	switch m.API {
+	case "oneshot":
+		var out []byte
+		out, err = openzl.Decompress(compressed)
+		outputs = [][]byte{out}
+	case "stream":
+		var r *openzl.Reader
+		if r, err = openzl.NewReader(bytes.NewReader(compressed)); err == nil {
+			var out []byte
+			out, err = io.ReadAll(r)
+			r.Close()
+			outputs = [][]byte{out}
+		}
+	case "typed", "multi":
+		var ctx *cgo.DCtx
+		if ctx, err = cgo.NewDCtx(); err == nil {
+			outputs, err = ctx.DecompressMulti(compressed)
+			ctx.Free()
+		}
+	default:
+		return fmt.Errorf("unknown api %q", m.API)
+	}
+	if err != nil {
+		return fmt.Errorf("decompress: %w", err)
+	}
+	if len(outputs) != len(m.Outputs) {
+		return fmt.Errorf("got %d outputs, want %d", len(outputs), len(m.Outputs))
+	}
+	for i, o := range m.Outputs {
+		if len(outputs[i]) != o.Size || sha256Hex(outputs[i]) != o.SHA256 {
+			return fmt.Errorf("output %d differs from %s", i, o.File)
+		}
+	}
+	return nil
+}
+
+// sha256Hex returns the SHA-256 digest of b in hexadecimal.
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// writeJSON writes v to path as indented JSON.
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// readJSON decodes the JSON file at path into v.
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("parse %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFixtures(t *testing.T) {
+	dir := t.TempDir()
+	n, err := writeFixtures(dir)
+	if err != nil {
+		t.Fatalf("writeFixtures() failed: %v", err)
+	}
+	if n != len(fixtures()) {
+		t.Errorf("writeFixtures() = %d, want %d", n, len(fixtures()))
+	}
+
+	var index fixtureIndex
+	if err := readJSON(filepath.Join(dir, fixtureIndexFile), &index); err != nil {
+		t.Fatal(err)
+	}
+	apis := make(map[string]bool)
+	for _, name := range index.Fixtures {
+		var m fixtureManifest
+		if err := readJSON(filepath.Join(dir, name+".json"), &m); err != nil {
+			t.Fatal(err)
+		}
+		apis[m.API] = true
+		if m.API == "multi" && len(m.Outputs) != 4 {
+			t.Errorf("%s: %d outputs, want 4", name, len(m.Outputs))
+		}
+	}
+	for _, api := range []string{"oneshot", "stream", "typed", "multi"} {
+		if !apis[api] {
+			t.Errorf("no %s fixture", api)
+		}
+	}
+
+	failures, err := verifyFixtures(dir)
+	if err != nil || len(failures) != 0 {
+		t.Fatalf("verifyFixtures() = %v, %v, want no failures", failures, err)
+	}
+
+	// Replace an expected output
+	if err := os.WriteFile(filepath.Join(dir, "typed-int32.0.raw"), []byte("wrong"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var m fixtureManifest
+	readJSON(filepath.Join(dir, "typed-int32.json"), &m)
+	m.Outputs[0].SHA256 = sha256Hex([]byte("wrong"))
+	writeJSON(filepath.Join(dir, "typed-int32.json"), m)
+	failures, err = verifyFixtures(dir)
+	if err != nil || len(failures) != 1 || !strings.HasPrefix(failures[0], "typed-int32:") {
+		t.Errorf("verifyFixtures(changed output) = %v, %v, want one failure for typed-int32", failures, err)
+	}
+}
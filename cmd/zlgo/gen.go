@@ -0,0 +1,387 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/borischu/go-openzl/internal/recordtag"
+)
+
+// genSuffix ends the names of generated files, which are ignored when
+// reading the package so that regenerating sees the same input.
+const genSuffix = "_zlgo.go"
+
+// runGen implements `zlgo gen` and returns the exit status.
+func runGen(args []string) int {
+	fs := flag.NewFlagSet("gen", flag.ExitOnError)
+	types := fs.String("type", "", "comma-separated struct types to generate record codecs for (required)")
+	out := fs.String("o", "", "write the generated code to `file` (default <type>"+genSuffix+" in the package directory)")
+	dir := fs.String("dir", ".", "`directory` of the package that declares the types")
+	fs.Parse(args)
+
+	if *types == "" {
+		fmt.Fprintln(os.Stderr, "zlgo gen: -type is required")
+		fs.Usage()
+		return 2
+	}
+	names := strings.Split(*types, ",")
+
+	code, err := generate(*dir, names)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "zlgo gen: %v\n", err)
+		return 2
+	}
+	path := *out
+	if path == "" {
+		path = filepath.Join(*dir, strings.ToLower(names[0])+genSuffix)
+	}
+	if err := os.WriteFile(path, code, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "zlgo gen: %v\n", err)
+		return 2
+	}
+	return 0
+}
+
+// genColumn describes how one struct field is stored. It mirrors the
+// reflection-based plan of openzl.CompressRecords.
+type genColumn struct {
+	field    string // Field name, also the column name
+	typ      string // Field type as written in the source
+	elem     string // Element type of the encoder column: int64, uint64, float32, ...
+	method   string // RecordEncoder and RecordDecoder method
+	width    int    // Stored width of integer columns
+	platform bool   // Platform-sized integer, which may not hold 8 bytes
+}
+
+// genBasic maps the predeclared types that can be stored to their column
+// method and natural width.
+var genBasic = map[string]struct {
+	method string
+	width  int
+}{
+	"int": {"Int", 8}, "int8": {"Int", 1}, "int16": {"Int", 2}, "int32": {"Int", 4}, "int64": {"Int", 8}, "rune": {"Int", 4},
+	"uint": {"Uint", 8}, "uint8": {"Uint", 1}, "uint16": {"Uint", 2}, "uint32": {"Uint", 4}, "uint64": {"Uint", 8}, "uintptr": {"Uint", 8}, "byte": {"Uint", 1},
+	"float32": {"Float32", 4}, "float64": {"Float64", 8},
+	"bool":   {"Bool", 0},
+	"string": {"String", 0},
+}
+
+// genElem is the element type of each column method.
+var genElem = map[string]string{
+	"Int": "int64", "Uint": "uint64", "Float32": "float32", "Float64": "float64", "Bool": "bool", "String": "string",
+}
+
+// genPackage holds the declarations of the package being generated for.
+type genPackage struct {
+	name  string
+	types map[string]*ast.TypeSpec
+}
+
+// loadPackage parses the non-test, non-generated Go files in dir.
+func loadPackage(dir string) (*genPackage, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	pkg := &genPackage{types: make(map[string]*ast.TypeSpec)}
+	fset := token.NewFileSet()
+	for _, path := range files {
+		if strings.HasSuffix(path, "_test.go") || strings.HasSuffix(path, genSuffix) {
+			continue
+		}
+		f, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		if pkg.name == "" {
+			pkg.name = f.Name.Name
+		} else if pkg.name != f.Name.Name {
+			return nil, fmt.Errorf("%s: found packages %s and %s", dir, pkg.name, f.Name.Name)
+		}
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				ts := spec.(*ast.TypeSpec)
+				pkg.types[ts.Name.Name] = ts
+			}
+		}
+	}
+	if pkg.name == "" {
+		return nil, fmt.Errorf("%s: no Go files", dir)
+	}
+	return pkg, nil
+}
+
+// generate returns the formatted codecs for the named struct types of the
+// package in dir.
+func generate(dir string, names []string) ([]byte, error) {
+	pkg, err := loadPackage(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	imports := make(map[string]bool)
+	for _, name := range names {
+		columns, err := pkg.columns(name)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range columns {
+			if c.platform && c.width == 8 {
+				imports["fmt"] = true
+			}
+			if strings.HasPrefix(c.typ, "time.") {
+				imports["time"] = true
+			}
+		}
+		writeCodec(&body, name, columns)
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by \"zlgo gen -type %s\"; DO NOT EDIT.\n\n", strings.Join(names, ","))
+	fmt.Fprintf(&b, "package %s\n\nimport (\n", pkg.name)
+	paths := make([]string, 0, len(imports))
+	for path := range imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		fmt.Fprintf(&b, "\t%q\n", path)
+	}
+	if len(paths) > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString("\t\"github.com/borischu/go-openzl\"\n)\n")
+	b.Write(body.Bytes())
+
+	code, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w", err)
+	}
+	return code, nil
+}
+
+// columns returns the stored fields of the named struct type, following the
+// same rules as openzl.CompressRecords.
+func (p *genPackage) columns(name string) ([]genColumn, error) {
+	ts, ok := p.types[name]
+	if !ok {
+		return nil, fmt.Errorf("type %s not found in package %s", name, p.name)
+	}
+	st, ok := ts.Type.(*ast.StructType)
+	if !ok || ts.TypeParams != nil {
+		return nil, fmt.Errorf("%s is not a non-generic struct type", name)
+	}
+
+	var columns []genColumn
+	for _, field := range st.Fields.List {
+		tag := ""
+		if field.Tag != nil {
+			s, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid tag %s", name, field.Tag.Value)
+			}
+			tag = s
+		}
+		names := field.Names
+		if len(names) == 0 {
+			// Embedded fields are named after their type
+			names = []*ast.Ident{ast.NewIdent(embeddedName(field.Type))}
+		}
+		for _, fieldName := range names {
+			c, skip, err := p.column(fieldName.Name, field.Type, reflect.StructTag(tag))
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", name, fieldName.Name, err)
+			}
+			if !skip {
+				columns = append(columns, c)
+			}
+		}
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("%s has no fields to store", name)
+	}
+	return columns, nil
+}
+
+// column derives the column of one field from its type and openzl tag. It
+// reports skip for unexported and skipped fields.
+func (p *genPackage) column(name string, typ ast.Expr, tag reflect.StructTag) (c genColumn, skip bool, err error) {
+	value, tagged := tag.Lookup(recordtag.Key)
+	opts, err := recordtag.Parse(value)
+	if err != nil {
+		return c, false, err
+	}
+	if opts.Skip {
+		return c, true, nil
+	}
+	if !ast.IsExported(name) {
+		if tagged {
+			return c, false, errors.New("unexported field cannot be stored")
+		}
+		return c, true, nil
+	}
+
+	c.field = name
+	c.typ = typeString(typ)
+	basic, ok := p.underlying(typ, 0)
+	if !ok {
+		return c, false, fmt.Errorf("unsupported type %s, tag it openzl:\"skip\"", c.typ)
+	}
+	info := genBasic[basic]
+	c.method, c.width = info.method, info.width
+	c.elem = genElem[c.method]
+	c.platform = basic == "int" || basic == "uint" || basic == "uintptr"
+
+	if opts.Width != 0 {
+		if c.method != "Int" && c.method != "Uint" {
+			return c, false, errors.New("width applies only to integer fields")
+		}
+		if opts.Width > c.width {
+			return c, false, fmt.Errorf("width %d exceeds the %d-byte field", opts.Width, c.width)
+		}
+		c.width = opts.Width
+	}
+	return c, false, nil
+}
+
+// underlying resolves typ to a predeclared type that can be stored, following
+// type declarations of the package and time.Duration.
+func (p *genPackage) underlying(typ ast.Expr, depth int) (string, bool) {
+	if depth > len(p.types) {
+		return "", false
+	}
+	switch t := typ.(type) {
+	case *ast.Ident:
+		if _, ok := genBasic[t.Name]; ok {
+			return t.Name, true
+		}
+		if ts, ok := p.types[t.Name]; ok && ts.TypeParams == nil {
+			return p.underlying(ts.Type, depth+1)
+		}
+	case *ast.SelectorExpr:
+		if pkg, ok := t.X.(*ast.Ident); ok && pkg.Name == "time" && t.Sel.Name == "Duration" {
+			return "int64", true
+		}
+	case *ast.ParenExpr:
+		return p.underlying(t.X, depth)
+	}
+	return "", false
+}
+
+// embeddedName returns the field name of an embedded field of type typ.
+func embeddedName(typ ast.Expr) string {
+	switch t := typ.(type) {
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.IndexExpr:
+		return embeddedName(t.X)
+	case *ast.IndexListExpr:
+		return embeddedName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return "_"
+}
+
+// typeString formats a type expression as written in the source.
+func typeString(typ ast.Expr) string {
+	var b bytes.Buffer
+	format.Node(&b, token.NewFileSet(), typ)
+	return b.String()
+}
+
+// writeCodec writes the compress and decompress functions of one type.
+func writeCodec(w *bytes.Buffer, name string, columns []genColumn) {
+	fmt.Fprintf(w, `
+// Compress%[1]sRecords compresses records column by column. It produces the
+// same output as openzl.CompressRecords, without reflection.
+func Compress%[1]sRecords(records []%[1]s) ([]byte, error) {
+	if len(records) == 0 {
+		return nil, openzl.ErrEmptyInput
+	}
+
+	enc := openzl.NewRecordEncoder(len(records))
+`, name)
+	for i, c := range columns {
+		value := "records[j]." + c.field
+		if c.typ != c.elem {
+			value = c.elem + "(" + value + ")"
+		}
+		if i > 0 {
+			w.WriteString("\n")
+		}
+		fmt.Fprintf(w, "\tcol%d := make([]%s, len(records))\n", i, c.elem)
+		fmt.Fprintf(w, "\tfor j := range records {\n\t\tcol%d[j] = %s\n\t}\n", i, value)
+		if c.method == "Int" || c.method == "Uint" {
+			fmt.Fprintf(w, "\tenc.%s(%q, col%d, %d)\n", c.method, c.field, i, c.width)
+		} else {
+			fmt.Fprintf(w, "\tenc.%s(%q, col%d)\n", c.method, c.field, i)
+		}
+	}
+	fmt.Fprintf(w, `
+	return enc.Encode()
+}
+
+// Decompress%[1]sRecords restores records compressed by Compress%[1]sRecords
+// or openzl.CompressRecords. It behaves like openzl.DecompressRecords,
+// without reflection.
+func Decompress%[1]sRecords(compressed []byte) ([]%[1]s, error) {
+	if len(compressed) == 0 {
+		return nil, openzl.ErrEmptyInput
+	}
+	dec, err := openzl.NewRecordDecoder(compressed)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]%[1]s, dec.Len())
+`, name)
+	for _, c := range columns {
+		value := "v"
+		if c.typ != c.elem {
+			value = "(" + c.typ + ")(v)"
+			if !strings.ContainsAny(c.typ, "*([") {
+				value = c.typ + "(v)"
+			}
+		}
+		if c.method == "Int" || c.method == "Uint" {
+			fmt.Fprintf(w, "\tfor j, v := range dec.%s(%q, %d) {\n", c.method, c.field, c.width)
+		} else {
+			fmt.Fprintf(w, "\tfor j, v := range dec.%s(%q) {\n", c.method, c.field)
+		}
+		if c.platform && c.width == 8 {
+			// int, uint, and uintptr may be 4 bytes on this platform
+			fmt.Fprintf(w, "\t\tif %s(%s) != v {\n", c.elem, value)
+			fmt.Fprintf(w, "\t\t\treturn nil, fmt.Errorf(\"%%w: value %%d at row %%d overflows field %s\", openzl.ErrCorruptedData, v, j)\n\t\t}\n", c.field)
+		}
+		fmt.Fprintf(w, "\t\trecords[j].%s = %s\n\t}\n", c.field, value)
+	}
+	w.WriteString(`	if err := dec.Finish(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+`)
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writePackage writes src as the only file of a package in a new directory.
+func writePackage(t *testing.T, src string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "types.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestGenerate(t *testing.T) {
+	dir := writePackage(t, `package sample
+
+import "time"
+
+type Level int16
+
+type Row struct {
+	ID       int
+	Small    uint32 `+"`openzl:\"width=2\"`"+`
+	Level    Level
+	A, B     float64
+	Wait     time.Duration
+	Name     string
+	Tags     []string `+"`openzl:\"skip\"`"+`
+	internal int
+}
+`)
+	code, err := generate(dir, []string{"Row"})
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	out := string(code)
+
+	for _, want := range []string{
+		"package sample",
+		`"fmt"`,
+		`"time"`,
+		"func CompressRowRecords(records []Row) ([]byte, error)",
+		"func DecompressRowRecords(compressed []byte) ([]Row, error)",
+		`enc.Int("ID", col0, 8)`,
+		`enc.Uint("Small", col1, 2)`,
+		`enc.Int("Level", col2, 2)`,
+		`enc.Float64("A", col3)`,
+		`enc.Float64("B", col4)`,
+		`enc.Int("Wait", col5, 8)`,
+		`enc.String("Name", col6)`,
+		"records[j].Level = Level(v)",
+		"records[j].Wait = time.Duration(v)",
+		"if int64(int(v)) != v {",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("generated code lacks %q:\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"Tags", "internal"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("generated code stores skipped field %s", unwanted)
+		}
+	}
+}
+
+func TestGenerateInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"unsupported type", "type Row struct{ Data []byte }", "unsupported type []byte"},
+		{"width on float", "type Row struct{ X float64 `openzl:\"width=4\"` }", "width applies only to integer fields"},
+		{"width too large", "type Row struct{ X int16 `openzl:\"width=4\"` }", "width 4 exceeds the 2-byte field"},
+		{"unknown option", "type Row struct{ X int `openzl:\"delta\"` }", "unknown tag option"},
+		{"tagged unexported", "type Row struct{ x int `openzl:\"column\"` }", "unexported field cannot be stored"},
+		{"no fields", "type Row struct{ x int }", "no fields to store"},
+		{"not a struct", "type Row []int", "not a non-generic struct type"},
+		{"missing", "type Other struct{ X int }", "type Row not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writePackage(t, "package sample\n\n"+tt.src+"\n")
+			_, err := generate(dir, []string{"Row"})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("generate error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestGenerateExampleUpToDate checks that the checked-in generated code of
+// the records example matches the generator.
+func TestGenerateExampleUpToDate(t *testing.T) {
+	dir := filepath.Join("..", "..", "examples", "records")
+	code, err := generate(dir, []string{"Event"})
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	want, err := os.ReadFile(filepath.Join(dir, "event"+genSuffix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(code, want) {
+		t.Errorf("examples/records/event%s is stale, run go generate ./examples/records", genSuffix)
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Command zlgo provides maintenance tools for go-openzl.
+//
+// Usage:
+//
+//	zlgo perf [flags]
+//	zlgo gen -type T[,T...] [flags]
+//	zlgo compat -write dir -version v | -verify dir
+//	zlgo recompress [flags] in out
+//	zlgo verify [flags] archive.zl
+//	zlgo spec [-check file...]
+//	zlgo fixtures -out dir | -verify dir
+//
+// The perf subcommand runs the standardized benchmarks of package perf. With
+// -out it saves the results as a baseline; with -baseline it compares against
+// a saved baseline and exits with status 1 if throughput or ratio regressed
+// beyond the thresholds:
+//
+//	zlgo perf -out baseline.json        # before upgrading OpenZL
+//	zlgo perf -baseline baseline.json   # after upgrading
+//
+// The gen subcommand generates reflection-free record codecs for the named
+// struct types of the Go package in the current directory. For a type T, it
+// writes CompressTRecords and DecompressTRecords, which produce and read the
+// same format as openzl.CompressRecords and honor the same openzl struct
+// tags. It is meant to be run by go generate:
+//
+//	//go:generate go run github.com/borischu/go-openzl/cmd/zlgo gen -type Event
+//
+// The compat subcommand writes a snapshot corpus of every compression mode,
+// or verifies that corpora written by earlier versions still decode to the
+// same values (see package compat). It exits with status 1 if a corpus case
+// fails.
+//
+// The recompress subcommand decodes a stream or frame and re-encodes it with
+// new Writer settings (see openzl.Transcode), for example to move historical
+// data to a larger frame size or to add frame checksums:
+//
+//	zlgo recompress -frame-size 1048576 -checksum 2024.zl 2024.zl
+//
+// The verify subcommand checks the integrity of a stream for archival audits
+// (see openzl.VerifyStream): every frame checksum is compared and every frame
+// is decompressed, on -workers frames in parallel. It prints the failed
+// frames, or every frame with -v, and exits with status 1 if any frame is
+// damaged or the stream is incomplete:
+//
+//	zlgo verify -workers 8 2024.zl
+//
+// The spec subcommand prints the specification of the stream and container
+// formats of package spec as Markdown, the FORMAT.md of the repository.
+// With -check it instead checks the framing of each named file, a stream or
+// a container, without decompressing it, for example to test the output of
+// another implementation. It exits with status 1 if a file is invalid:
+//
+//	zlgo spec -check testdata/*.zl
+//
+// The fixtures subcommand writes interop fixtures for the test suites of
+// other language bindings: a matrix of one-shot, streamed, typed, and
+// multi-input compressed files, each with a JSON manifest and the raw
+// outputs it must decompress to. manifest.json lists the fixtures; numeric
+// outputs are little-endian. With -verify it checks that the fixtures of a
+// directory still decompress to their expected outputs:
+//
+//	zlgo fixtures -out testdata/interop
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	switch os.Args[1] {
+	case "perf":
+		os.Exit(runPerf(os.Args[2:]))
+	case "gen":
+		os.Exit(runGen(os.Args[2:]))
+	case "compat":
+		os.Exit(runCompat(os.Args[2:]))
+	case "recompress":
+		os.Exit(runRecompress(os.Args[2:]))
+	case "verify":
+		os.Exit(runVerify(os.Args[2:]))
+	case "spec":
+		os.Exit(runSpec(os.Args[2:]))
+	case "fixtures":
+		os.Exit(runFixtures(os.Args[2:]))
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: zlgo perf [flags] | zlgo gen -type T[,T...] [flags] | zlgo compat -write dir -version v | -verify dir | zlgo recompress [flags] in out | zlgo verify [flags] archive.zl | zlgo spec [-check file...] | zlgo fixtures -out dir | -verify dir")
+	os.Exit(2)
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/borischu/go-openzl/perf"
+)
+
+// runPerf implements `zlgo perf` and returns the exit status.
+func runPerf(args []string) int {
+	fs := flag.NewFlagSet("perf", flag.ExitOnError)
+	baseline := fs.String("baseline", "", "compare against the report in `file` and fail on regressions")
+	out := fs.String("out", "", "write the report to `file`")
+	workloads := fs.String("workloads", "", "comma-separated workloads to run (default all: "+strings.Join(perf.Workloads(), ",")+")")
+	size := fs.Int("size", perf.DefaultSize, "input size of each workload in bytes")
+	duration := fs.Duration("duration", perf.DefaultDuration, "minimum measuring time per workload and direction")
+	maxThroughputDrop := fs.Float64("max-throughput-drop", perf.DefaultThresholds.Throughput, "largest acceptable relative throughput drop")
+	maxRatioDrop := fs.Float64("max-ratio-drop", perf.DefaultThresholds.Ratio, "largest acceptable relative ratio drop")
+	gzip := fs.Bool("gzip", false, "also measure gzip on each workload for comparison")
+	fs.Parse(args)
+
+	var base *perf.Report
+	if *baseline != "" {
+		var err error
+		if base, err = perf.LoadReport(*baseline); err != nil {
+			fmt.Fprintf(os.Stderr, "zlgo: %v\n", err)
+			return 2
+		}
+	}
+
+	cfg := perf.Config{Size: *size, Duration: *duration}
+	if *workloads != "" {
+		cfg.Workloads = strings.Split(*workloads, ",")
+	}
+	if *gzip {
+		cfg.Codecs = append(cfg.Codecs, perf.Gzip)
+	}
+	report, err := perf.Run(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "zlgo: %v\n", err)
+		return 2
+	}
+
+	fmt.Printf("OpenZL %s, go-openzl %s, %s/%s\n", report.OpenZLVersion, report.Version, report.GOOS, report.GOARCH)
+	fmt.Printf("%-16s %-8s %12s %8s %14s %16s\n", "workload", "codec", "compressed", "ratio", "compress MB/s", "decompress MB/s")
+	for _, r := range report.Results {
+		codec := r.Codec
+		if codec == "" {
+			codec = "openzl"
+		}
+		fmt.Printf("%-16s %-8s %12d %8.2f %14.1f %16.1f\n", r.Workload, codec, r.CompressedSize, r.Ratio, r.CompressMBps, r.DecompressMBps)
+	}
+
+	if *out != "" {
+		if err := perf.WriteReport(*out, report); err != nil {
+			fmt.Fprintf(os.Stderr, "zlgo: %v\n", err)
+			return 2
+		}
+	}
+
+	if base == nil {
+		return 0
+	}
+	regs := perf.Compare(base, report, perf.Thresholds{Throughput: *maxThroughputDrop, Ratio: *maxRatioDrop})
+	if len(regs) == 0 {
+		fmt.Printf("\nno regressions against %s\n", *baseline)
+		return 0
+	}
+	fmt.Printf("\n%d regressions against %s:\n", len(regs), *baseline)
+	for _, r := range regs {
+		fmt.Printf("  %s\n", r)
+	}
+	return 1
+}
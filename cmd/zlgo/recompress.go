@@ -0,0 +1,115 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/borischu/go-openzl"
+)
+
+// runRecompress implements `zlgo recompress` and returns the exit status.
+func runRecompress(args []string) int {
+	fs := flag.NewFlagSet("recompress", flag.ExitOnError)
+	level := fs.Int("level", 0, "compression level 1-9 (default: library default)")
+	frameSize := fs.Int("frame-size", 0, "frame size in bytes (default: openzl.DefaultFrameSize)")
+	longWindow := fs.Int("long-window", 0, "use long-window frames of `n` bytes")
+	cdc := fs.Int("cdc", 0, "cut content-defined frames of average size `n`")
+	adaptive := fs.Bool("adaptive", false, "adjust the compression level frame by frame")
+	checksum := fs.Bool("checksum", false, "append a CRC32C to each frame")
+	stored := fs.Bool("stored", false, "store frames raw when compression does not help")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: zlgo recompress [flags] in out")
+		fmt.Fprintln(os.Stderr, "\nin and out may be - for standard input and output; out may equal in.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 2
+	}
+
+	if *level != 0 {
+		if err := openzl.Init(openzl.WithDefaultLevel(*level)); err != nil {
+			fmt.Fprintf(os.Stderr, "zlgo recompress: %v\n", err)
+			return 2
+		}
+	}
+	var opts []openzl.WriterOption
+	if *frameSize != 0 {
+		opts = append(opts, openzl.WithFrameSize(*frameSize))
+	}
+	if *longWindow != 0 {
+		opts = append(opts, openzl.WithLongWindow(*longWindow))
+	}
+	if *cdc != 0 {
+		opts = append(opts, openzl.WithContentDefinedChunking(*cdc))
+	}
+	opts = append(opts,
+		openzl.WithAdaptiveLevel(*adaptive),
+		openzl.WithFrameChecksum(*checksum),
+		openzl.WithStoredFallback(*stored),
+	)
+
+	in, out := fs.Arg(0), fs.Arg(1)
+	read, written, err := recompress(in, out, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "zlgo recompress: %v\n", err)
+		return 1
+	}
+	if out != "-" {
+		fmt.Printf("%s: %d -> %d bytes\n", out, read, written)
+	}
+	return 0
+}
+
+// recompress transcodes the file in to the file out and returns the sizes of
+// both. A file out is replaced only once it has been written completely.
+func recompress(in, out string, opts []openzl.WriterOption) (read, written int64, err error) {
+	src := io.Reader(os.Stdin)
+	if in != "-" {
+		f, err := os.Open(in)
+		if err != nil {
+			return 0, 0, err
+		}
+		defer f.Close()
+		src = f
+	}
+	cr := &countingReader{r: src}
+
+	if out == "-" {
+		written, err = openzl.Transcode(os.Stdout, cr, opts...)
+		return cr.n, written, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(out), filepath.Base(out)+".*.tmp")
+	if err != nil {
+		return 0, 0, err
+	}
+	defer os.Remove(tmp.Name())
+	if written, err = openzl.Transcode(tmp, cr, opts...); err != nil {
+		tmp.Close()
+		return cr.n, written, fmt.Errorf("%s: %w", in, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return cr.n, written, err
+	}
+	return cr.n, written, os.Rename(tmp.Name(), out)
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
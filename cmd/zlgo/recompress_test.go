@@ -0,0 +1,61 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/borischu/go-openzl"
+)
+
+func TestRecompress(t *testing.T) {
+	data := bytes.Repeat([]byte("2024-01-01T00:00:00Z GET /index.html 200\n"), 2000)
+	compressed, err := openzl.CompressFrom(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("CompressFrom() failed: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "logs.zl")
+	if err := os.WriteFile(path, compressed, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Recompress in place
+	read, written, err := recompress(path, path, []openzl.WriterOption{openzl.WithFrameChecksum(true)})
+	if err != nil {
+		t.Fatalf("recompress: %v", err)
+	}
+	if read != int64(len(compressed)) {
+		t.Errorf("read %d bytes, want %d", read, len(compressed))
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int64(len(got)) != written {
+		t.Errorf("file has %d bytes, recompress reported %d", len(got), written)
+	}
+	if h, err := openzl.ParseFrameHeader(got); err != nil || !h.Checksum {
+		t.Errorf("first frame header = %+v, %v, want a checksum", h, err)
+	}
+	var out bytes.Buffer
+	if _, err := openzl.DecompressTo(&out, got); err != nil || !bytes.Equal(out.Bytes(), data) {
+		t.Errorf("recompressed file does not decode to the input: %v", err)
+	}
+
+	// A failed transcode leaves the output untouched
+	bad := filepath.Join(t.TempDir(), "bad.zl")
+	os.WriteFile(bad, compressed[:len(compressed)/2], 0o644)
+	if _, _, err := recompress(bad, path, nil); err == nil {
+		t.Error("recompress of a truncated stream succeeded")
+	}
+	if after, _ := os.ReadFile(path); !bytes.Equal(after, got) {
+		t.Error("failed recompress modified the output")
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+		t.Errorf("output directory has %d entries, want no leftover temporary file", len(entries))
+	}
+}
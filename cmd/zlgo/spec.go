@@ -0,0 +1,84 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/borischu/go-openzl/spec"
+)
+
+// runSpec implements `zlgo spec` and returns the exit status.
+func runSpec(args []string) int {
+	fs := flag.NewFlagSet("spec", flag.ExitOnError)
+	check := fs.Bool("check", false, "check the named files against the specification instead of printing it")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: zlgo spec > FORMAT.md | zlgo spec -check file...")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if !*check {
+		if fs.NArg() != 0 {
+			fs.Usage()
+			return 2
+		}
+		if err := spec.Markdown(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "zlgo spec: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+	status := 0
+	for _, name := range fs.Args() {
+		if err := checkSpec(os.Stdout, name); err != nil {
+			fmt.Fprintf(os.Stderr, "zlgo spec: %s: %v\n", name, err)
+			status = 1
+		}
+	}
+	return status
+}
+
+// checkSpec checks the file name, a stream or a container, and prints a
+// summary of it to w.
+func checkSpec(w io.Writer, name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	br := bufio.NewReader(f)
+
+	if magic, _ := br.Peek(len(spec.ContainerMagic)); string(magic) == spec.ContainerMagic {
+		b, err := io.ReadAll(br)
+		if err != nil {
+			return err
+		}
+		kind, sections, err := spec.ValidateContainer(b)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s: %s container, %d sections, %d bytes: ok\n", name, kind.Name, len(sections), len(b))
+		return nil
+	}
+
+	info, err := spec.ValidateStream(br)
+	if err != nil {
+		return fmt.Errorf("after %d valid frames: %w", len(info.Frames), err)
+	}
+	if !info.Complete {
+		return fmt.Errorf("stream of %d frames ends without its end-of-stream marker", len(info.Frames))
+	}
+	fmt.Fprintf(w, "%s: stream, %d frames, %d bytes: ok\n", name, len(info.Frames), info.Size)
+	return nil
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/borischu/go-openzl"
+)
+
+func TestCheckSpec(t *testing.T) {
+	dir := t.TempDir()
+	stream := filepath.Join(dir, "stream.zl")
+	f, err := os.Create(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, _ := openzl.NewWriter(f)
+	w.Write([]byte(strings.Repeat("spec check ", 1000)))
+	w.Close()
+	f.Close()
+
+	container := filepath.Join(dir, "profile.zlgc")
+	compressed, err := openzl.CompressProfile([]byte(strings.Repeat("profile ", 1000)))
+	if err != nil {
+		t.Fatalf("CompressProfile() failed: %v", err)
+	}
+	os.WriteFile(container, compressed, 0o644)
+
+	var out strings.Builder
+	if err := checkSpec(&out, stream); err != nil {
+		t.Errorf("checkSpec(stream) failed: %v", err)
+	}
+	if err := checkSpec(&out, container); err != nil {
+		t.Errorf("checkSpec(container) failed: %v", err)
+	}
+	if got := out.String(); !strings.Contains(got, "stream, 1 frames") || !strings.Contains(got, "profile container, 2 sections") {
+		t.Errorf("checkSpec() printed %q", got)
+	}
+
+	data, _ := os.ReadFile(stream)
+	truncated := filepath.Join(dir, "truncated.zl")
+	os.WriteFile(truncated, data[:len(data)-4], 0o644)
+	if err := checkSpec(&out, truncated); err == nil {
+		t.Error("checkSpec(stream without end marker) succeeded")
+	}
+}
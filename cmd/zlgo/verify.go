@@ -0,0 +1,93 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/borischu/go-openzl"
+)
+
+// runVerify implements `zlgo verify` and returns the exit status.
+func runVerify(args []string) int {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	workers := fs.Int("workers", 0, "verify `n` frames in parallel (default: GOMAXPROCS)")
+	verbose := fs.Bool("v", false, "print the status of every frame, not only failed ones")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: zlgo verify [flags] archive.zl")
+		fmt.Fprintln(os.Stderr, "\narchive.zl may be - for standard input.")
+		fs.PrintDefaults()
+	}
+
+	// Accept flags after the file name too, as in `zlgo verify a.zl -workers 8`
+	var files []string
+	for {
+		fs.Parse(args)
+		if fs.NArg() == 0 {
+			break
+		}
+		files = append(files, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(files) != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	var opts []openzl.VerifyOption
+	if *workers != 0 {
+		opts = append(opts, openzl.WithVerifyWorkers(*workers))
+	}
+	report, err := verify(files[0], opts)
+	if report != nil {
+		printVerifyReport(os.Stdout, files[0], report, *verbose)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "zlgo verify: %s: %v\n", files[0], err)
+		return 1
+	}
+	if !report.OK() {
+		return 1
+	}
+	return 0
+}
+
+// verify opens the file name and verifies it.
+func verify(name string, opts []openzl.VerifyOption) (*openzl.VerifyReport, error) {
+	src := io.Reader(os.Stdin)
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		src = f
+	}
+	return openzl.VerifyStream(src, opts...)
+}
+
+// printVerifyReport writes one line per failed frame, or per frame if
+// verbose, followed by a summary.
+func printVerifyReport(w io.Writer, name string, report *openzl.VerifyReport, verbose bool) {
+	for _, f := range report.Frames {
+		switch {
+		case f.Err != nil:
+			fmt.Fprintf(w, "frame %d at offset %d: FAILED: %v\n", f.Index, f.Offset, f.Err)
+		case verbose:
+			fmt.Fprintf(w, "frame %d at offset %d: ok, %d -> %d bytes\n", f.Index, f.Offset, f.Header.PayloadSize, f.DecompressedSize)
+		}
+	}
+	failed := len(report.Failed())
+	status := "ok"
+	switch {
+	case failed > 0:
+		status = "DAMAGED"
+	case !report.Complete:
+		status = "INCOMPLETE (no end-of-stream marker)"
+	}
+	fmt.Fprintf(w, "%s: %d frames, %d failed, %d bytes: %s\n", name, len(report.Frames), failed, report.DecompressedSize, status)
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/borischu/go-openzl"
+)
+
+func TestVerify(t *testing.T) {
+	data := bytes.Repeat([]byte("2024-01-01T00:00:00Z GET /index.html 200\n"), 2000)
+	var buf bytes.Buffer
+	w, err := openzl.NewWriter(&buf, openzl.WithFrameSize(openzl.MinFrameSize), openzl.WithFrameChecksum(true))
+	if err != nil {
+		t.Fatalf("NewWriter() failed: %v", err)
+	}
+	w.Write(data)
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "logs.zl")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := verify(path, []openzl.VerifyOption{openzl.WithVerifyWorkers(2)})
+	if err != nil || !report.OK() {
+		t.Fatalf("verify() = %+v, %v, want an intact stream", report, err)
+	}
+	var out bytes.Buffer
+	printVerifyReport(&out, "logs.zl", report, false)
+	if got := out.String(); !strings.HasSuffix(got, ": ok\n") || strings.Count(got, "\n") != 1 {
+		t.Errorf("report of an intact stream = %q, want a single ok summary", got)
+	}
+
+	// Damage the last byte of the first frame's payload
+	damaged := bytes.Clone(buf.Bytes())
+	damaged[report.Frames[0].Header.FrameSize()-openzl.FrameChecksumSize-1] ^= 0xff
+	os.WriteFile(path, damaged, 0o644)
+	report, err = verify(path, nil)
+	if err != nil || report.OK() {
+		t.Fatalf("verify(damaged) = %+v, %v, want a damaged stream", report, err)
+	}
+	out.Reset()
+	printVerifyReport(&out, "logs.zl", report, false)
+	if got := out.String(); !strings.HasPrefix(got, "frame 0 at offset 0: FAILED") || !strings.Contains(got, "DAMAGED") {
+		t.Errorf("report of a damaged stream = %q", got)
+	}
+}
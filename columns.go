@@ -0,0 +1,357 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/borischu/go-openzl/internal/cgo"
+)
+
+// columnsVersion is the first byte of the schema of CompressColumns.
+const columnsVersion = 1
+
+// columnType identifies the element type of a column in the schema.
+type columnType byte
+
+const (
+	columnInt8 columnType = iota + 1
+	columnUint8
+	columnInt16
+	columnUint16
+	columnInt32
+	columnUint32
+	columnInt64
+	columnUint64
+	columnFloat32
+	columnFloat64
+)
+
+var columnTypeNames = [...]string{
+	columnInt8: "int8", columnUint8: "uint8", columnInt16: "int16", columnUint16: "uint16",
+	columnInt32: "int32", columnUint32: "uint32", columnInt64: "int64", columnUint64: "uint64",
+	columnFloat32: "float32", columnFloat64: "float64",
+}
+
+func (t columnType) String() string {
+	if t == 0 || int(t) >= len(columnTypeNames) {
+		return fmt.Sprintf("columnType(%d)", byte(t))
+	}
+	return columnTypeNames[t]
+}
+
+// numericType returns the column type of T.
+func numericType[T Numeric]() columnType {
+	var zero T
+	switch any(zero).(type) {
+	case int8:
+		return columnInt8
+	case uint8:
+		return columnUint8
+	case int16:
+		return columnInt16
+	case uint16:
+		return columnUint16
+	case int32:
+		return columnInt32
+	case uint32:
+		return columnUint32
+	case int64:
+		return columnInt64
+	case uint64:
+		return columnUint64
+	case float32:
+		return columnFloat32
+	default:
+		return columnFloat64
+	}
+}
+
+// column is one named column of a Columns.
+type column struct {
+	name string
+	typ  columnType
+	len  int
+	data any                           // []T of typ
+	ref  func() (*cgo.TypedRef, error) // Typed reference to data
+}
+
+func newColumn[T Numeric](name string, data []T) column {
+	return column{
+		name: name,
+		typ:  numericType[T](),
+		len:  len(data),
+		data: data,
+		ref:  func() (*cgo.TypedRef, error) { return cgo.NewTypedRefNumeric(data) },
+	}
+}
+
+// Columns is a set of named numeric columns of possibly different types,
+// such as the timestamps, values, and ids of a batch of metric samples,
+// compressed together by CompressColumns.
+//
+// Columns are added with AddColumn and read with Column, both generic
+// functions since Go methods cannot take type parameters. Columns need not
+// have the same length. The zero value is an empty set ready to use.
+type Columns struct {
+	cols []column
+}
+
+// AddColumn adds to c the column name holding data. The data is not copied
+// and must not be modified until c is compressed.
+//
+// Returns ErrInvalidParameter if name is empty or c already has a column of
+// that name.
+func AddColumn[T Numeric](c *Columns, name string, data []T) error {
+	if name == "" {
+		return fmt.Errorf("%w: empty column name", ErrInvalidParameter)
+	}
+	if c.find(name) >= 0 {
+		return fmt.Errorf("%w: duplicate column %q", ErrInvalidParameter, name)
+	}
+	c.cols = append(c.cols, newColumn(name, data))
+	return nil
+}
+
+// Column returns the column name of c. T must be the column's element type.
+//
+// Returns ErrInvalidParameter if c has no column of that name, or
+// ErrTypeMismatch if its elements are not of type T.
+func Column[T Numeric](c *Columns, name string) ([]T, error) {
+	i := c.find(name)
+	if i < 0 {
+		return nil, fmt.Errorf("%w: no column %q", ErrInvalidParameter, name)
+	}
+	data, ok := c.cols[i].data.([]T)
+	if !ok {
+		return nil, fmt.Errorf("%w: column %q holds %s, not %s", ErrTypeMismatch, name, c.cols[i].typ, numericType[T]())
+	}
+	return data, nil
+}
+
+// Names returns the names of the columns of c, in the order they were added.
+func (c *Columns) Names() []string {
+	names := make([]string, len(c.cols))
+	for i, col := range c.cols {
+		names[i] = col.name
+	}
+	return names
+}
+
+// Len returns the number of columns of c.
+func (c *Columns) Len() int {
+	return len(c.cols)
+}
+
+// find returns the position of the column name, or -1.
+func (c *Columns) find(name string) int {
+	for i, col := range c.cols {
+		if col.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// CompressColumns compresses the columns of c into a single multi-input
+// OpenZL frame.
+//
+// Each column becomes its own typed input, so OpenZL picks numeric codecs
+// per column and models the columns together, rather than compressing one
+// slice at a time. A schema, stored as one more input of the same frame,
+// records the name, type, and length of each column. Empty columns are
+// kept in the schema but take no input.
+//
+// Example:
+//
+//	var batch openzl.Columns
+//	openzl.AddColumn(&batch, "ts", timestamps)   // []int64
+//	openzl.AddColumn(&batch, "value", values)    // []float64
+//	openzl.AddColumn(&batch, "id", ids)          // []uint32
+//	compressed, err := openzl.CompressColumns(&batch)
+//	...
+//	batch2, err := openzl.DecompressColumns(compressed)
+//	values, err = openzl.Column[float64](batch2, "value")
+//
+// Returns ErrEmptyInput if c has no columns.
+func CompressColumns(c *Columns) ([]byte, error) {
+	if c.Len() == 0 {
+		return nil, ErrEmptyInput
+	}
+
+	schema := []byte{columnsVersion}
+	schema = binary.AppendUvarint(schema, uint64(len(c.cols)))
+	for _, col := range c.cols {
+		schema = binary.AppendUvarint(schema, uint64(len(col.name)))
+		schema = append(schema, col.name...)
+		schema = append(schema, byte(col.typ))
+		schema = binary.AppendUvarint(schema, uint64(col.len))
+	}
+
+	trefs := make([]*cgo.TypedRef, 0, len(c.cols)+1)
+	defer func() {
+		for _, t := range trefs {
+			t.Free()
+		}
+	}()
+	tref, err := cgo.NewTypedRefSerial(schema)
+	if err != nil {
+		return nil, fmt.Errorf("create typed ref: %w", err)
+	}
+	trefs = append(trefs, tref)
+	size := len(schema)
+	for _, col := range c.cols {
+		if col.len == 0 {
+			continue
+		}
+		tref, err := col.ref()
+		if err != nil {
+			return nil, fmt.Errorf("column %q: create typed ref: %w", col.name, err)
+		}
+		trefs = append(trefs, tref)
+		size += col.len * tref.ElementSize()
+	}
+
+	ctx, err := newCCtx()
+	if err != nil {
+		return nil, fmt.Errorf("create context: %w", err)
+	}
+	defer ctx.Free()
+
+	// Typed compression may need more space than CompressBound for raw bytes
+	dstSize, err := compressBound(size, 2)
+	if err != nil {
+		return nil, err
+	}
+	dst := make([]byte, dstSize)
+	n, err := ctx.CompressMultiTypedRef(dst, trefs)
+	if err != nil {
+		return nil, fmt.Errorf("compress typed: %w", err)
+	}
+	return dst[:n], nil
+}
+
+// DecompressColumns restores columns compressed by CompressColumns, with
+// their names, types, and order.
+func DecompressColumns(compressed []byte) (*Columns, error) {
+	if len(compressed) == 0 {
+		return nil, ErrEmptyInput
+	}
+
+	ctx, err := cgo.NewDCtx()
+	if err != nil {
+		return nil, fmt.Errorf("create context: %w", err)
+	}
+	defer ctx.Free()
+
+	outputs, err := ctx.DecompressMulti(compressed)
+	if err != nil {
+		return nil, fmt.Errorf("decompress typed: %w", diagnoseFrameError(compressed, err))
+	}
+	if len(outputs) == 0 {
+		return nil, fmt.Errorf("%w: missing column schema", ErrCorruptedData)
+	}
+	schema := outputs[0]
+	if len(schema) < 1 || schema[0] != columnsVersion {
+		return nil, fmt.Errorf("%w: invalid column schema", ErrCorruptedData)
+	}
+	schema = schema[1:]
+
+	uvarint := func() (uint64, error) {
+		v, k := binary.Uvarint(schema)
+		if k <= 0 {
+			return 0, fmt.Errorf("%w: truncated column schema", ErrCorruptedData)
+		}
+		schema = schema[k:]
+		return v, nil
+	}
+	count, err := uvarint()
+	if err != nil {
+		return nil, err
+	}
+	// Every column takes at least three bytes of the schema
+	if count > uint64(len(schema))/3 {
+		return nil, fmt.Errorf("%w: truncated column schema", ErrCorruptedData)
+	}
+
+	c := &Columns{cols: make([]column, 0, count)}
+	next := 1
+	for range count {
+		nameLen, err := uvarint()
+		if err != nil {
+			return nil, err
+		}
+		if nameLen >= uint64(len(schema)) {
+			return nil, fmt.Errorf("%w: truncated column schema", ErrCorruptedData)
+		}
+		name := string(schema[:nameLen])
+		typ := columnType(schema[nameLen])
+		schema = schema[nameLen+1:]
+		n, err := uvarint()
+		if err != nil {
+			return nil, err
+		}
+
+		var data []byte
+		if n > 0 {
+			if next >= len(outputs) {
+				return nil, fmt.Errorf("%w: column %q does not match the schema", ErrCorruptedData, name)
+			}
+			data = outputs[next]
+			next++
+		}
+		col, err := decodeColumn(name, typ, data, n)
+		if err != nil {
+			return nil, err
+		}
+		c.cols = append(c.cols, col)
+	}
+	if len(schema) != 0 || next != len(outputs) {
+		return nil, fmt.Errorf("%w: column schema does not match the frame", ErrCorruptedData)
+	}
+	return c, nil
+}
+
+// decodeColumn returns the column name of type typ holding the n elements
+// of data.
+func decodeColumn(name string, typ columnType, data []byte, n uint64) (column, error) {
+	switch typ {
+	case columnInt8:
+		return decodeColumnOf[int8](name, data, n)
+	case columnUint8:
+		return decodeColumnOf[uint8](name, data, n)
+	case columnInt16:
+		return decodeColumnOf[int16](name, data, n)
+	case columnUint16:
+		return decodeColumnOf[uint16](name, data, n)
+	case columnInt32:
+		return decodeColumnOf[int32](name, data, n)
+	case columnUint32:
+		return decodeColumnOf[uint32](name, data, n)
+	case columnInt64:
+		return decodeColumnOf[int64](name, data, n)
+	case columnUint64:
+		return decodeColumnOf[uint64](name, data, n)
+	case columnFloat32:
+		return decodeColumnOf[float32](name, data, n)
+	case columnFloat64:
+		return decodeColumnOf[float64](name, data, n)
+	}
+	return column{}, fmt.Errorf("%w: column %q has unknown type %d", ErrCorruptedData, name, byte(typ))
+}
+
+func decodeColumnOf[T Numeric](name string, data []byte, n uint64) (column, error) {
+	if n == 0 {
+		return newColumn(name, []T{}), nil
+	}
+	values, err := cgo.BytesToTypedSlice[T](data)
+	if err != nil {
+		return column{}, fmt.Errorf("column %q: convert to typed slice: %w", name, err)
+	}
+	if uint64(len(values)) != n {
+		return column{}, fmt.Errorf("%w: column %q does not match the schema", ErrCorruptedData, name)
+	}
+	return newColumn(name, values), nil
+}
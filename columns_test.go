@@ -0,0 +1,122 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/borischu/go-openzl/internal/cgo"
+)
+
+func TestCompressColumns(t *testing.T) {
+	ts := make([]int64, 1000)
+	values := make([]float64, 1000)
+	ids := make([]uint32, 1000)
+	for i := range ts {
+		ts[i] = 1_700_000_000_000 + int64(i)*250
+		values[i] = 20 + float64(i%40)/8
+		ids[i] = uint32(i % 16)
+	}
+	flags := []int8{1, -1, 0}
+
+	var c Columns
+	for _, err := range []error{
+		AddColumn(&c, "ts", ts),
+		AddColumn(&c, "value", values),
+		AddColumn(&c, "id", ids),
+		AddColumn(&c, "flags", flags),
+		AddColumn(&c, "empty", []uint16{}),
+	} {
+		if err != nil {
+			t.Fatalf("AddColumn() failed: %v", err)
+		}
+	}
+	if err := AddColumn(&c, "ts", ts); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("AddColumn(duplicate) error = %v, want ErrInvalidParameter", err)
+	}
+
+	compressed, err := CompressColumns(&c)
+	if err != nil {
+		t.Fatalf("CompressColumns() failed: %v", err)
+	}
+	got, err := DecompressColumns(compressed)
+	if err != nil {
+		t.Fatalf("DecompressColumns() failed: %v", err)
+	}
+	if !slices.Equal(got.Names(), c.Names()) {
+		t.Errorf("Names() = %v, want %v", got.Names(), c.Names())
+	}
+	checkColumn(t, got, "ts", ts)
+	checkColumn(t, got, "value", values)
+	checkColumn(t, got, "id", ids)
+	checkColumn(t, got, "flags", flags)
+	if empty, err := Column[uint16](got, "empty"); err != nil || empty == nil || len(empty) != 0 {
+		t.Errorf("Column(empty) = %v, %v", empty, err)
+	}
+
+	if _, err := Column[int32](got, "ts"); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("Column[int32](int64 column) error = %v, want ErrTypeMismatch", err)
+	}
+	if _, err := Column[int64](got, "missing"); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("Column(missing) error = %v, want ErrInvalidParameter", err)
+	}
+}
+
+func checkColumn[T Numeric](t *testing.T, c *Columns, name string, want []T) {
+	t.Helper()
+	got, err := Column[T](c, name)
+	if err != nil {
+		t.Fatalf("Column(%q) failed: %v", name, err)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("column %q mismatch", name)
+	}
+}
+
+func TestCompressColumns_Invalid(t *testing.T) {
+	if _, err := CompressColumns(&Columns{}); !errors.Is(err, ErrEmptyInput) {
+		t.Errorf("CompressColumns(empty) error = %v, want ErrEmptyInput", err)
+	}
+	if err := AddColumn(&Columns{}, "", []int64{1}); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("AddColumn(\"\") error = %v, want ErrInvalidParameter", err)
+	}
+
+	numeric, err := CompressNumeric([]float64{1, 2, 3})
+	if err != nil {
+		t.Fatalf("CompressNumeric() failed: %v", err)
+	}
+	if _, err := DecompressColumns(numeric); !errors.Is(err, ErrCorruptedData) {
+		t.Errorf("DecompressColumns(numeric frame) error = %v, want ErrCorruptedData", err)
+	}
+}
+
+func TestDecompressColumns_Corrupt(t *testing.T) {
+	// A column name length near MaxUint64 must not wrap the bounds check
+	schema := binary.AppendUvarint([]byte{columnsVersion}, 1)
+	schema = binary.AppendUvarint(schema, math.MaxUint64)
+	schema = append(schema, "ts"...)
+	tref, err := cgo.NewTypedRefSerial(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tref.Free()
+	ctx, err := newCCtx()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ctx.Free()
+	dst := make([]byte, 1<<10)
+	n, err := ctx.CompressMultiTypedRef(dst, []*cgo.TypedRef{tref})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := DecompressColumns(dst[:n]); !errors.Is(err, ErrCorruptedData) {
+		t.Errorf("DecompressColumns() error = %v, want ErrCorruptedData", err)
+	}
+}
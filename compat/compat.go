@@ -0,0 +1,199 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Package compat writes and verifies snapshot corpora that pin go-openzl's
+// wire formats.
+//
+// A corpus holds the output of every public compression mode for fixed
+// inputs, together with the canonical decoded form of each output. Corpora
+// written by a release are kept, and every later version must still decode
+// them to the same values, so changes to the Writer framing, the typed
+// formats, or the domain containers cannot silently break data at rest.
+//
+// Example:
+//
+//	// At release time
+//	err := compat.WriteCorpus("compat/testdata/v1.2.0", "v1.2.0")
+//
+//	// In CI, for every corpus of every prior release
+//	failures, err := compat.VerifyAll("compat/testdata")
+//	for _, f := range failures {
+//		log.Println(f)
+//	}
+//
+// The zlgo command wraps this package as `zlgo compat`.
+package compat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/borischu/go-openzl"
+)
+
+// ManifestFile is the name of the manifest in a corpus directory.
+const ManifestFile = "manifest.json"
+
+// Manifest describes a corpus.
+type Manifest struct {
+	Version       string  `json:"version"`        // Module version that wrote the corpus
+	OpenZLVersion string  `json:"openzl_version"` // Vendored OpenZL library version
+	Cases         []Entry `json:"cases"`
+}
+
+// Entry is one mode of a corpus. The compressed output is stored in
+// <Name>.zl and its canonical decoded form in <Name>.json.
+type Entry struct {
+	Name string `json:"name"`
+}
+
+// Failure is a corpus case that the current version cannot decode, or
+// decodes to a different value.
+type Failure struct {
+	Corpus string // Corpus directory
+	Case   string
+	Err    error
+}
+
+func (f Failure) String() string {
+	return fmt.Sprintf("%s: %s: %v", f.Corpus, f.Case, f.Err)
+}
+
+// Modes returns the names of the compression modes written to a corpus.
+func Modes() []string {
+	names := make([]string, len(modes))
+	for i, m := range modes {
+		names[i] = m.name
+	}
+	return names
+}
+
+// WriteCorpus compresses the inputs of every mode and writes the outputs,
+// their decoded forms, and a manifest labeled with version to dir, which is
+// created if needed.
+func WriteCorpus(dir, version string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	manifest := Manifest{Version: version, OpenZLVersion: openzl.OpenZLVersion()}
+	for _, m := range modes {
+		compressed, err := m.encode()
+		if err != nil {
+			return fmt.Errorf("%s: encode: %w", m.name, err)
+		}
+		want, err := decodeJSON(m, compressed)
+		if err != nil {
+			return fmt.Errorf("%s: %w", m.name, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, m.name+".zl"), compressed, 0o644); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, m.name+".json"), want, 0o644); err != nil {
+			return err
+		}
+		manifest.Cases = append(manifest.Cases, Entry{Name: m.name})
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, ManifestFile), append(data, '\n'), 0o644)
+}
+
+// Verify decodes every case of the corpus in dir with the current version
+// and compares the result with the decoded form stored in the corpus.
+//
+// It returns one Failure per case that does not match, including cases of
+// modes the current version no longer knows. The error is non-nil only if
+// the corpus itself cannot be read.
+func Verify(dir string) ([]Failure, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", ManifestFile, err)
+	}
+
+	var failures []Failure
+	fail := func(name string, err error) {
+		failures = append(failures, Failure{Corpus: dir, Case: name, Err: err})
+	}
+	for _, e := range manifest.Cases {
+		i := slices.IndexFunc(modes, func(m mode) bool { return m.name == e.Name })
+		if i < 0 {
+			fail(e.Name, fmt.Errorf("mode is no longer supported"))
+			continue
+		}
+		compressed, err := os.ReadFile(filepath.Join(dir, e.Name+".zl"))
+		if err != nil {
+			return nil, err
+		}
+		want, err := os.ReadFile(filepath.Join(dir, e.Name+".json"))
+		if err != nil {
+			return nil, err
+		}
+		got, err := decodeJSON(modes[i], compressed)
+		if err != nil {
+			fail(e.Name, err)
+			continue
+		}
+		if !bytes.Equal(got, want) {
+			fail(e.Name, fmt.Errorf("decoded value differs from the corpus"))
+		}
+	}
+	return failures, nil
+}
+
+// VerifyAll verifies every corpus in the subdirectories of root, as well as
+// root itself if it holds a manifest.
+func VerifyAll(root string) ([]Failure, error) {
+	dirs := []string{root}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			dirs = append(dirs, filepath.Join(root, e.Name()))
+		}
+	}
+
+	var failures []Failure
+	found := false
+	for _, dir := range dirs {
+		if _, err := os.Stat(filepath.Join(dir, ManifestFile)); err != nil {
+			continue
+		}
+		found = true
+		f, err := Verify(dir)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", dir, err)
+		}
+		failures = append(failures, f...)
+	}
+	if !found {
+		return nil, fmt.Errorf("%s: no corpus found", root)
+	}
+	return failures, nil
+}
+
+// decodeJSON decodes compressed with m and returns the canonical JSON form of
+// the decoded value.
+func decodeJSON(m mode, compressed []byte) ([]byte, error) {
+	v, err := m.decode(compressed)
+	if err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal decoded value: %w", err)
+	}
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package compat
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteVerify(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "v0.0.0-test")
+	if err := WriteCorpus(dir, "v0.0.0-test"); err != nil {
+		t.Fatalf("WriteCorpus() failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Version != "v0.0.0-test" || len(m.Cases) != len(Modes()) {
+		t.Errorf("manifest = %s, %d cases; want v0.0.0-test, %d cases", m.Version, len(m.Cases), len(Modes()))
+	}
+
+	failures, err := VerifyAll(root)
+	if err != nil {
+		t.Fatalf("VerifyAll() failed: %v", err)
+	}
+	for _, f := range failures {
+		t.Error(f)
+	}
+}
+
+func TestVerify_DetectsChanges(t *testing.T) {
+	dir := t.TempDir()
+	if err := WriteCorpus(dir, "test"); err != nil {
+		t.Fatalf("WriteCorpus() failed: %v", err)
+	}
+
+	// A changed decoded value, an undecodable output, and an unknown mode
+	if err := os.WriteFile(filepath.Join(dir, "numeric-int64.json"), []byte("[1]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "records.zl"), []byte("garbage"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	manifest, _ := os.ReadFile(filepath.Join(dir, ManifestFile))
+	manifest = []byte(strings.Replace(string(manifest), `"cases": [`, `"cases": [{"name": "retired"},`, 1))
+	if err := os.WriteFile(filepath.Join(dir, ManifestFile), manifest, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	failures, err := Verify(dir)
+	if err != nil {
+		t.Fatalf("Verify() failed: %v", err)
+	}
+	got := map[string]bool{}
+	for _, f := range failures {
+		got[f.Case] = true
+	}
+	for _, want := range []string{"numeric-int64", "records", "retired"} {
+		if !got[want] {
+			t.Errorf("Verify() did not report case %s", want)
+		}
+	}
+	if len(failures) != 3 {
+		t.Errorf("Verify() reported %d failures, want 3: %v", len(failures), failures)
+	}
+}
+
+// TestCorpora verifies the corpora checked in by past releases.
+func TestCorpora(t *testing.T) {
+	if _, err := os.Stat("testdata"); os.IsNotExist(err) {
+		t.Skip("no checked-in corpora")
+	}
+	failures, err := VerifyAll("testdata")
+	if err != nil {
+		t.Fatalf("VerifyAll() failed: %v", err)
+	}
+	for _, f := range failures {
+		t.Error(f)
+	}
+}
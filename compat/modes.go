@@ -0,0 +1,280 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package compat
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/netip"
+
+	"github.com/borischu/go-openzl"
+	"github.com/borischu/go-openzl/datagen"
+)
+
+// mode is one public compression mode. Modes are never removed or renamed:
+// Verify reports corpus cases whose mode is unknown as failures.
+type mode struct {
+	name   string
+	encode func() ([]byte, error)
+	decode func([]byte) (any, error)
+}
+
+// event is the record type of the record modes. Its fields must not change,
+// since the corpus stores its columns by name and type.
+type event struct {
+	Timestamp int64
+	UserID    uint64 `openzl:"width=4"`
+	Kind      string
+	Score     float32
+	Error     bool
+}
+
+// events returns n deterministic records.
+func events(n int) []event {
+	rng := rand.New(rand.NewPCG(1, 2))
+	kinds := []string{"view", "click", "purchase"}
+	out := make([]event, n)
+	for i := range out {
+		out[i] = event{
+			Timestamp: 1_700_000_000_000 + int64(i)*250 + rng.Int64N(50),
+			UserID:    uint64(rng.IntN(1000)),
+			Kind:      kinds[rng.IntN(len(kinds))],
+			Score:     float32(rng.IntN(100)) / 4,
+			Error:     rng.IntN(20) == 0,
+		}
+	}
+	return out
+}
+
+// streamMode returns a mode that compresses data with a Writer.
+func streamMode(name string, data []byte, opts ...openzl.WriterOption) mode {
+	return mode{
+		name: name,
+		encode: func() ([]byte, error) {
+			opts := append([]openzl.WriterOption{openzl.WithFrameSize(openzl.MinFrameSize)}, opts...)
+			return openzl.CompressFrom(bytes.NewReader(data), opts...)
+		},
+		decode: func(b []byte) (any, error) {
+			r, err := openzl.NewReader(bytes.NewReader(b))
+			if err != nil {
+				return nil, err
+			}
+			defer r.Close()
+			return io.ReadAll(r)
+		},
+	}
+}
+
+// numericMode returns a mode that compresses data with CompressNumeric.
+func numericMode[T openzl.Numeric](name string, data []T) mode {
+	return mode{
+		name:   name,
+		encode: func() ([]byte, error) { return openzl.CompressNumeric(data) },
+		decode: func(b []byte) (any, error) { return openzl.DecompressNumeric[T](b) },
+	}
+}
+
+// convert converts each element of a slice.
+func convert[From, To openzl.Numeric](values []From) []To {
+	out := make([]To, len(values))
+	for i, v := range values {
+		out[i] = To(v)
+	}
+	return out
+}
+
+var modes = []mode{
+	{
+		name:   "compress",
+		encode: func() ([]byte, error) { return openzl.Compress(datagen.Logs(16 << 10)) },
+		decode: func(b []byte) (any, error) { return openzl.Decompress(b) },
+	},
+	streamMode("stream", datagen.Text(20<<10)),
+	streamMode("stream-checksum", datagen.CSV(20<<10), openzl.WithFrameChecksum(true)),
+	streamMode("stream-stored", datagen.Random(8<<10), openzl.WithStoredFallback(true)),
+	numericMode("numeric-int64", datagen.Int64Sequence(4096)),
+	numericMode("numeric-uint16", convert[int64, uint16](datagen.Int64Sequence(4096))),
+	numericMode("numeric-float64", datagen.Float64Walk(4096)),
+	{
+		name: "numeric-runs",
+		encode: func() ([]byte, error) {
+			c, err := openzl.NewCompressor(openzl.WithRunShortCircuit(true))
+			if err != nil {
+				return nil, err
+			}
+			defer c.Close()
+			data := make([]int32, 4096)
+			for i := range data {
+				data[i] = int32(i / 512)
+			}
+			return openzl.CompressorCompressNumeric(c, data)
+		},
+		decode: func(b []byte) (any, error) { return openzl.DecompressNumeric[int32](b) },
+	},
+	{
+		name:   "profile",
+		encode: func() ([]byte, error) { return openzl.CompressProfile(datagen.Mixed(8 << 10)) },
+		decode: func(b []byte) (any, error) { return openzl.DecompressProfile(b) },
+	},
+	{
+		name: "uuids",
+		encode: func() ([]byte, error) {
+			rng := rand.New(rand.NewPCG(3, 4))
+			ids := make([][16]byte, 256)
+			for i := range ids {
+				for j := range ids[i] {
+					ids[i][j] = byte(rng.Uint32())
+				}
+				ids[i][6] = ids[i][6]&0x0f | 0x40 // Version 4
+				ids[i][8] = ids[i][8]&0x3f | 0x80 // RFC 4122 variant
+			}
+			return openzl.CompressUUIDs(ids)
+		},
+		decode: func(b []byte) (any, error) { return openzl.DecompressUUIDs(b) },
+	},
+	{
+		name: "ips",
+		encode: func() ([]byte, error) {
+			addrs := make([]netip.Addr, 256)
+			for i := range addrs {
+				if i%4 == 3 {
+					addrs[i] = netip.AddrFrom16([16]byte{0x20, 0x01, 0x0d, 0xb8, 15: byte(i)})
+				} else {
+					addrs[i] = netip.AddrFrom4([4]byte{10, 0, byte(i / 16), byte(i)})
+				}
+			}
+			return openzl.CompressIPs(addrs)
+		},
+		decode: func(b []byte) (any, error) { return openzl.DecompressIPs(b) },
+	},
+	{
+		name: "flows",
+		encode: func() ([]byte, error) {
+			var f openzl.FlowColumns
+			for i := range 256 {
+				f.SrcAddr = append(f.SrcAddr, netip.AddrFrom4([4]byte{192, 168, 1, byte(i % 32)}))
+				f.DstAddr = append(f.DstAddr, netip.AddrFrom4([4]byte{10, 0, 0, byte(i % 7)}))
+				f.SrcPort = append(f.SrcPort, uint16(32768+i))
+				f.DstPort = append(f.DstPort, []uint16{80, 443, 53}[i%3])
+				f.Protocol = append(f.Protocol, []uint8{6, 17}[i%2])
+			}
+			return openzl.CompressFlows(f)
+		},
+		decode: func(b []byte) (any, error) { return openzl.DecompressFlows(b) },
+	},
+	{
+		name: "ticks",
+		encode: func() ([]byte, error) {
+			ts := datagen.Timestamps(1024)
+			px := datagen.Float64Walk(1024)
+			size := convert[int64, uint32](datagen.Int64Sequence(1024))
+			return openzl.CompressTicks(ts, px, size)
+		},
+		decode: func(b []byte) (any, error) {
+			ts, px, size, err := openzl.DecompressTicks(b)
+			return struct {
+				Ts   []int64
+				Px   []float64
+				Size []uint32
+			}{ts, px, size}, err
+		},
+	},
+	{
+		name: "reads",
+		encode: func() ([]byte, error) {
+			rng := rand.New(rand.NewPCG(5, 6))
+			reads := make([]openzl.SequenceRead, 64)
+			for i := range reads {
+				bases := make([]byte, 100)
+				quality := make([]byte, 100)
+				for j := range bases {
+					bases[j] = "ACGTN"[rng.IntN(5)]
+					quality[j] = byte('!' + rng.IntN(41))
+				}
+				reads[i] = openzl.SequenceRead{ID: fmt.Sprintf("read%d/1", i), Bases: bases, Quality: quality}
+			}
+			return openzl.CompressReads(reads)
+		},
+		decode: func(b []byte) (any, error) { return openzl.DecompressReads(b) },
+	},
+	{
+		name:   "embeddings",
+		encode: func() ([]byte, error) { return openzl.CompressEmbeddings(vectors(), 16) },
+		decode: func(b []byte) (any, error) { return openzl.DecompressEmbeddings(b) },
+	},
+	{
+		name: "embeddings-quantized",
+		encode: func() ([]byte, error) {
+			return openzl.CompressEmbeddings(vectors(), 16, openzl.WithQuantizedEmbeddings(true))
+		},
+		decode: func(b []byte) (any, error) { return openzl.DecompressEmbeddings(b) },
+	},
+	{
+		name:   "lossy",
+		encode: func() ([]byte, error) { return openzl.CompressFloatsLossy(datagen.Float64Walk(4096), 0.01) },
+		decode: func(b []byte) (any, error) { return openzl.DecompressFloatsLossy[float64](b) },
+	},
+	{
+		name: "sparse",
+		encode: func() ([]byte, error) {
+			return openzl.CompressSparse([]int64{3, 17, 18, 250, 1000}, []float64{0.5, -1, 2.25, 8, 1e-3}, 4096)
+		},
+		decode: func(b []byte) (any, error) {
+			indices, values, length, err := openzl.DecompressSparse(b)
+			return struct {
+				Indices []int64
+				Values  []float64
+				Length  int
+			}{indices, values, length}, err
+		},
+	},
+	{
+		name:   "records",
+		encode: func() ([]byte, error) { return openzl.CompressRecords(events(500)) },
+		decode: func(b []byte) (any, error) { return openzl.DecompressRecords[event](b) },
+	},
+	{
+		name: "record-stream",
+		encode: func() ([]byte, error) {
+			var buf bytes.Buffer
+			w, err := openzl.NewRecordWriter[event](&buf, openzl.WithRecordBatchSize(128))
+			if err != nil {
+				return nil, err
+			}
+			if err := w.Write(events(500)...); err != nil {
+				return nil, err
+			}
+			if err := w.Close(); err != nil {
+				return nil, err
+			}
+			return buf.Bytes(), nil
+		},
+		decode: func(b []byte) (any, error) {
+			r, err := openzl.NewRecordReader[event](bytes.NewReader(b))
+			if err != nil {
+				return nil, err
+			}
+			var out []event
+			for ev := range r.All() {
+				out = append(out, ev)
+			}
+			return out, r.Err()
+		},
+	},
+}
+
+// vectors returns deterministic embeddings quantized to multiples of 1/64.
+func vectors() [][]float32 {
+	rng := rand.New(rand.NewPCG(7, 8))
+	out := make([][]float32, 64)
+	for i := range out {
+		out[i] = make([]float32, 16)
+		for j := range out[i] {
+			out[i][j] = float32(rng.IntN(129)-64) / 64
+		}
+	}
+	return out
+}
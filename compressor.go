@@ -5,7 +5,11 @@ package openzl
 
 import (
 	"fmt"
+	"io"
+	"math"
+	"runtime"
 	"sync"
+	"time"
 
 	"github.com/borischu/go-openzl/internal/cgo"
 )
@@ -38,9 +42,15 @@ import (
 //		// Use compressed data...
 //	}
 type Compressor struct {
-	mu  sync.Mutex   // Protects ctx for thread safety
-	ctx *cgo.CCtx    // Underlying compression context
-	cfg *config      // Configuration options
+	mu     sync.Mutex // Protects ctx for thread safety
+	ctx    *cgo.CCtx  // Underlying compression context
+	cfg    *config    // Configuration options
+	report Report     // Report from the most recent compression
+
+	latency *latencyHistogram // Operation latencies, nil unless WithLatencyHistogram
+
+	cleanup  runtime.Cleanup // Releases ctx if the Compressor is never closed
+	unclosed *unclosed       // Argument of cleanup
 }
 
 // CompressorOption configures a Compressor during creation.
@@ -48,8 +58,14 @@ type CompressorOption func(*config) error
 
 // config holds the configuration options for Compressor.
 type config struct {
+	stageReport     bool // Record per-stage sizes (WithStageReport)
+	runShortCircuit bool // Store run-dominated numeric data as runs (WithRunShortCircuit)
+	latency         bool // Record operation latencies (WithLatencyHistogram)
+
+	compressionLevel int    // Level, 0 for the package default (WithCompressionLevel)
+	graph            *Graph // Custom graph, nil for the default (WithGraph)
+
 	// Future options will be added here:
-	// - compressionLevel int
 	// - checksum bool
 	// - dictionary []byte
 }
@@ -64,7 +80,7 @@ type config struct {
 //
 //	compressor, err := openzl.NewCompressor(
 //		openzl.WithCompressionLevel(9),
-//		openzl.WithChecksum(true),
+//		openzl.WithStageReport(true),
 //	)
 //
 // Returns an error if the underlying compression context cannot be created
@@ -79,15 +95,33 @@ func NewCompressor(opts ...CompressorOption) (*Compressor, error) {
 	}
 
 	// Create compression context
-	ctx, err := cgo.NewCCtx()
+	ctx, err := newCCtx()
 	if err != nil {
 		return nil, fmt.Errorf("create context: %w", err)
 	}
+	if cfg.compressionLevel != 0 {
+		ctx.SetCompressionLevel(cfg.compressionLevel)
+	}
+	if cfg.graph != nil {
+		ctx.SetGraph(cfg.graph.graph)
+	}
 
-	return &Compressor{
+	if cfg.stageReport {
+		if err := ctx.EnableReport(); err != nil {
+			ctx.Free()
+			return nil, fmt.Errorf("enable report: %w", err)
+		}
+	}
+
+	c := &Compressor{
 		ctx: ctx,
 		cfg: cfg,
-	}, nil
+	}
+	if cfg.latency {
+		c.latency = &latencyHistogram{}
+	}
+	c.cleanup, c.unclosed = watchUnclosed(c, "Compressor", ctx.Free)
+	return c, nil
 }
 
 // Compress compresses the input data using the reusable compression context.
@@ -117,18 +151,193 @@ func (c *Compressor) Compress(src []byte) ([]byte, error) {
 	// Lock for thread safety
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	if c.latency != nil {
+		defer c.latency.observe(time.Now())
+	}
+
+	// Compress using reusable context and its output buffer, in a single
+	// cgo call
+	dst, err := c.ctx.CompressAlloc(src)
+	if err != nil {
+		return nil, fmt.Errorf("compress: %w", err)
+	}
+	c.recordReport(len(src), len(dst))
+
+	return dst, nil
+}
+
+// Compress2D compresses the concatenation of srcs into a single frame, for
+// data held in chained buffers such as the fragments of a network packet.
+// The result is the same as compressing the fragments joined together and is
+// read back with Decompress.
+//
+// The fragments are not concatenated in Go memory: they are gathered in a
+// native buffer owned by the Compressor, in the same cgo call as the
+// compression. Empty fragments are skipped.
+//
+// Example:
+//
+//	compressed, err := compressor.Compress2D([][]byte{header, body, trailer})
+//
+// This method is safe for concurrent use by multiple goroutines.
+//
+// Returns ErrEmptyInput if the fragments hold no data.
+func (c *Compressor) Compress2D(srcs [][]byte) ([]byte, error) {
+	total := 0
+	for _, src := range srcs {
+		total += len(src)
+	}
+	if total == 0 {
+		return nil, ErrEmptyInput
+	}
 
-	// Allocate destination buffer
-	dstSize := cgo.CompressBound(len(src))
-	dst := make([]byte, dstSize)
+	// Lock for thread safety
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.latency != nil {
+		defer c.latency.observe(time.Now())
+	}
 
-	// Compress using reusable context
-	n, err := c.ctx.Compress(dst, src)
+	dst, err := c.ctx.CompressGather(srcs)
 	if err != nil {
 		return nil, fmt.Errorf("compress: %w", err)
 	}
+	c.recordReport(total, len(dst))
+
+	return dst, nil
+}
+
+// setLevel sets the compression level used by subsequent compressions.
+// Level 0 restores the library default.
+func (c *Compressor) setLevel(level int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.ctx != nil {
+		c.ctx.SetCompressionLevel(level)
+	}
+}
+
+// CompressBatch compresses each element of srcs independently and returns
+// one compressed frame per input.
+//
+// The whole batch is compressed in a single cgo transition, which removes
+// most of the per-call overhead when compressing many small messages. Each
+// returned frame is a standalone OpenZL frame that can be passed to
+// Decompress.
+//
+// The returned frames share one backing allocation; each has its capacity
+// limited to its length, so appending to one never overwrites another.
+//
+// This method is safe for concurrent use by multiple goroutines.
+//
+// Returns an error if:
+//   - any element of srcs is empty (use ErrEmptyInput check)
+//   - the underlying compression operation fails for any element
+func (c *Compressor) CompressBatch(srcs [][]byte) ([][]byte, error) {
+	if len(srcs) == 0 {
+		return nil, nil
+	}
+
+	dstSize := 0
+	for _, src := range srcs {
+		if len(src) == 0 {
+			return nil, ErrEmptyInput
+		}
+		bound, err := compressBound(len(src), 1)
+		if err != nil {
+			return nil, err
+		}
+		if dstSize > math.MaxInt-bound {
+			return nil, fmt.Errorf("%w: batch compression bound", ErrTooLarge)
+		}
+		dstSize += bound
+	}
+
+	// Lock for thread safety
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.latency != nil {
+		defer c.latency.observe(time.Now())
+	}
+
+	dst := allocBuf(dstSize)
+	defer freeBuf(dst)
+	sizes, err := c.ctx.CompressBatch(dst, srcs)
+	if err != nil {
+		return nil, fmt.Errorf("compress batch: %w", err)
+	}
+
+	// Compact the frames into a right-sized buffer so the bound-sized
+	// scratch buffer is not retained by the caller.
+	total := 0
+	for _, n := range sizes {
+		total += n
+	}
+	out := make([]byte, total)
+	frames := make([][]byte, len(srcs))
+	srcOff, dstOff := 0, 0
+	for i, n := range sizes {
+		copy(out[dstOff:], dst[srcOff:srcOff+n])
+		frames[i] = out[dstOff : dstOff+n : dstOff+n]
+		srcOff += cgo.CompressBound(len(srcs[i]))
+		dstOff += n
+	}
+
+	return frames, nil
+}
+
+// CompressReader reads r to the end and compresses it into a multi-frame
+// stream in the format of Writer, for callers that have an io.Reader of
+// unknown length but want the result as a byte slice.
+//
+// Input is read and compressed one frame at a time, using the default frame
+// size of Writer, so only the output grows with the input. The result is
+// read back with NewReader or DecompressTo; Decompress rejects it with
+// ErrStreamInput. An empty reader produces a stream holding only the
+// end-of-stream marker.
+//
+// This method is safe for concurrent use by multiple goroutines; the
+// Compressor is locked for each frame, not for the whole stream.
+//
+// Example:
+//
+//	resp, err := http.Get(url)
+//	...
+//	defer resp.Body.Close()
+//	compressed, err := compressor.CompressReader(resp.Body)
+//
+// Returns an error if reading from r or compression fails.
+func (c *Compressor) CompressReader(r io.Reader) ([]byte, error) {
+	if r == nil {
+		return nil, fmt.Errorf("%w: nil reader", ErrInvalidParameter)
+	}
+
+	buf := allocBuf(defaults().frameSize)
+	defer freeBuf(buf)
+	var out []byte
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			compressed, cerr := c.Compress(buf[:n])
+			if cerr != nil {
+				return nil, cerr
+			}
+			var header [FrameHeaderSize]byte
+			putFrameHeader(header[:], len(compressed), 0)
+			out = append(out, header[:]...)
+			out = append(out, compressed...)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read: %w", err)
+		}
+	}
 
-	return dst[:n], nil
+	// End-of-stream marker (zero-length frame)
+	return append(out, 0, 0, 0, 0), nil
 }
 
 // Close releases the underlying compression context and frees associated memory.
@@ -149,6 +358,7 @@ func (c *Compressor) Close() error {
 	defer c.mu.Unlock()
 
 	if c.ctx != nil {
+		c.cleanup.Stop()
 		c.ctx.Free()
 		c.ctx = nil
 	}
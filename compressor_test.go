@@ -5,8 +5,12 @@ package openzl
 
 import (
 	"bytes"
+	"errors"
+	"io"
+	"strings"
 	"sync"
 	"testing"
+	"testing/iotest"
 )
 
 func TestCompressor(t *testing.T) {
@@ -245,3 +249,240 @@ func TestDecompressorCorrupted(t *testing.T) {
 		t.Error("expected error when decompressing corrupted data, got nil")
 	}
 }
+
+func TestCompressorLastReport(t *testing.T) {
+	data := bytes.Repeat([]byte("report stages "), 1000)
+
+	t.Run("Disabled", func(t *testing.T) {
+		compressor, err := NewCompressor()
+		if err != nil {
+			t.Fatalf("NewCompressor() failed: %v", err)
+		}
+		defer compressor.Close()
+
+		compressed, err := compressor.Compress(data)
+		if err != nil {
+			t.Fatalf("Compress() failed: %v", err)
+		}
+
+		report := compressor.LastReport()
+		if report.InputSize != len(data) || report.OutputSize != len(compressed) {
+			t.Errorf("report sizes = %d/%d, want %d/%d",
+				report.InputSize, report.OutputSize, len(data), len(compressed))
+		}
+		if len(report.Stages) != 0 {
+			t.Errorf("got %d stages without WithStageReport, want 0", len(report.Stages))
+		}
+	})
+
+	t.Run("Enabled", func(t *testing.T) {
+		compressor, err := NewCompressor(WithStageReport(true))
+		if err != nil {
+			t.Fatalf("NewCompressor() failed: %v", err)
+		}
+		defer compressor.Close()
+
+		if _, err := compressor.Compress(data); err != nil {
+			t.Fatalf("Compress() failed: %v", err)
+		}
+
+		report := compressor.LastReport()
+		if len(report.Stages) == 0 {
+			t.Fatal("expected at least one stage")
+		}
+		for _, s := range report.Stages {
+			t.Logf("%-24s %8d -> %8d (%.2fx)", s.Name, s.InputSize, s.OutputSize, s.Ratio())
+		}
+	})
+}
+
+func TestWithCompressionLevel(t *testing.T) {
+	data := bytes.Repeat([]byte("level test data with some repetition "), 2000)
+
+	for _, level := range []int{0, MinCompressionLevel, MaxCompressionLevel} {
+		compressor, err := NewCompressor(WithCompressionLevel(level))
+		if err != nil {
+			t.Fatalf("NewCompressor(WithCompressionLevel(%d)) failed: %v", level, err)
+		}
+		compressed, err := compressor.Compress(data)
+		compressor.Close()
+		if err != nil {
+			t.Fatalf("level %d: Compress() failed: %v", level, err)
+		}
+		decompressed, err := Decompress(compressed)
+		if err != nil {
+			t.Fatalf("level %d: Decompress() failed: %v", level, err)
+		}
+		if !bytes.Equal(decompressed, data) {
+			t.Errorf("level %d: round trip mismatch", level)
+		}
+	}
+
+	for _, level := range []int{-1, MaxCompressionLevel + 1} {
+		_, err := NewCompressor(WithCompressionLevel(level))
+		var oe *OptionError
+		if !errors.As(err, &oe) || !errors.Is(err, ErrInvalidParameter) {
+			t.Errorf("WithCompressionLevel(%d): got error %v, want an *OptionError", level, err)
+		}
+	}
+}
+
+func TestCompressorCompressBatch(t *testing.T) {
+	compressor, err := NewCompressor()
+	if err != nil {
+		t.Fatalf("NewCompressor() failed: %v", err)
+	}
+	defer compressor.Close()
+
+	srcs := [][]byte{
+		[]byte("first message"),
+		bytes.Repeat([]byte("second "), 200),
+		{0x00, 0xFF, 0xAA},
+	}
+
+	frames, err := compressor.CompressBatch(srcs)
+	if err != nil {
+		t.Fatalf("CompressBatch() failed: %v", err)
+	}
+	if len(frames) != len(srcs) {
+		t.Fatalf("got %d frames, want %d", len(frames), len(srcs))
+	}
+
+	for i, frame := range frames {
+		decompressed, err := Decompress(frame)
+		if err != nil {
+			t.Fatalf("Decompress(frame %d) failed: %v", i, err)
+		}
+		if !bytes.Equal(decompressed, srcs[i]) {
+			t.Errorf("frame %d round-trip mismatch", i)
+		}
+	}
+
+	// Empty elements are rejected
+	if _, err := compressor.CompressBatch([][]byte{[]byte("ok"), {}}); err != ErrEmptyInput {
+		t.Errorf("expected ErrEmptyInput, got: %v", err)
+	}
+}
+
+func TestDecompressorMemoryBudget(t *testing.T) {
+	data := bytes.Repeat([]byte("budget "), 10000)
+	compressed, err := Compress(data)
+	if err != nil {
+		t.Fatalf("Compress() failed: %v", err)
+	}
+
+	small, err := NewDecompressor(WithMemoryBudget(int64(len(data))))
+	if err != nil {
+		t.Fatalf("NewDecompressor() failed: %v", err)
+	}
+	defer small.Close()
+	if _, err := small.Decompress(compressed); !errors.Is(err, ErrMemoryBudgetExceeded) {
+		t.Errorf("Decompress() error = %v, want ErrMemoryBudgetExceeded", err)
+	}
+
+	large, err := NewDecompressor(WithMemoryBudget(int64(4 * len(data))))
+	if err != nil {
+		t.Fatalf("NewDecompressor() failed: %v", err)
+	}
+	defer large.Close()
+	got, err := large.Decompress(compressed)
+	if err != nil {
+		t.Fatalf("Decompress() failed: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Error("decompressed data does not match")
+	}
+
+	if _, err := NewDecompressor(WithMemoryBudget(0)); err == nil {
+		t.Error("NewDecompressor() with zero budget succeeded")
+	}
+}
+
+func TestDecompressorVaryingSizes(t *testing.T) {
+	compressor, err := NewCompressor()
+	if err != nil {
+		t.Fatalf("NewCompressor() failed: %v", err)
+	}
+	defer compressor.Close()
+	decompressor, err := NewDecompressor()
+	if err != nil {
+		t.Fatalf("NewDecompressor() failed: %v", err)
+	}
+	defer decompressor.Close()
+
+	// Alternate sizes so the output size predicted from the previous call
+	// is sometimes too small and sometimes too large
+	for _, size := range []int{100, 100, 50000, 10, 50000, 50000, 1} {
+		data := bytes.Repeat([]byte{byte(size)}, size)
+		compressed, err := compressor.Compress(data)
+		if err != nil {
+			t.Fatalf("Compress(%d bytes) failed: %v", size, err)
+		}
+		got, err := decompressor.Decompress(compressed)
+		if err != nil {
+			t.Fatalf("Decompress(%d bytes) failed: %v", size, err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Fatalf("round trip of %d bytes returned %d bytes", size, len(got))
+		}
+	}
+}
+
+func TestCompressorCompressReader(t *testing.T) {
+	compressor, err := NewCompressor()
+	if err != nil {
+		t.Fatalf("NewCompressor() failed: %v", err)
+	}
+	defer compressor.Close()
+
+	for _, size := range []int{0, 100, DefaultFrameSize, 3*DefaultFrameSize + 17} {
+		data := bytes.Repeat([]byte("unknown length "), size/15+1)[:size]
+		compressed, err := compressor.CompressReader(io.LimitReader(bytes.NewReader(data), int64(size)))
+		if err != nil {
+			t.Fatalf("CompressReader(%d bytes) failed: %v", size, err)
+		}
+		var out bytes.Buffer
+		if _, err := DecompressTo(&out, compressed); err != nil {
+			t.Fatalf("DecompressTo(%d bytes) failed: %v", size, err)
+		}
+		if !bytes.Equal(out.Bytes(), data) {
+			t.Errorf("round trip of %d bytes mismatch", size)
+		}
+	}
+
+	readErr := errors.New("read failed")
+	if _, err := compressor.CompressReader(io.MultiReader(strings.NewReader("partial"), iotest.ErrReader(readErr))); !errors.Is(err, readErr) {
+		t.Errorf("CompressReader() error = %v, want %v", err, readErr)
+	}
+}
+
+func TestCompress2D(t *testing.T) {
+	compressor, err := NewCompressor()
+	if err != nil {
+		t.Fatalf("NewCompressor() failed: %v", err)
+	}
+	defer compressor.Close()
+
+	data := bytes.Repeat([]byte("chained buffer fragment "), 200)
+	fragments := [][]byte{data[:7], nil, data[7:1000], {}, data[1000:]}
+
+	for name, compress := range map[string]func([][]byte) ([]byte, error){
+		"Compressor": compressor.Compress2D,
+		"package":    Compress2D,
+	} {
+		compressed, err := compress(fragments)
+		if err != nil {
+			t.Fatalf("%s Compress2D() failed: %v", name, err)
+		}
+		got, err := Decompress(compressed)
+		if err != nil {
+			t.Fatalf("%s Decompress() failed: %v", name, err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("%s round trip mismatch", name)
+		}
+		if _, err := compress([][]byte{nil, {}}); !errors.Is(err, ErrEmptyInput) {
+			t.Errorf("%s Compress2D(empty fragments) error = %v, want ErrEmptyInput", name, err)
+		}
+	}
+}
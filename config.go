@@ -0,0 +1,217 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import "io"
+
+// Plain-struct configuration for applications that read their settings from
+// YAML, JSON, or similar files.
+//
+// Each Config type mirrors the functional options of one constructor. Its
+// zero value means the defaults, and every field maps to exactly one option,
+// so a Config can be decoded with encoding/json or a YAML library and passed
+// on without mapping strings to options by hand. Invalid values are reported
+// by the constructor as an *OptionError naming the corresponding option.
+
+// CompressorConfig configures a Compressor; see NewCompressorFromConfig.
+type CompressorConfig struct {
+	// Level is the compression level, 0 for the package default
+	// (WithCompressionLevel).
+	Level int `json:"level,omitempty" yaml:"level,omitempty"`
+
+	// StageReport enables per-stage reports (WithStageReport).
+	StageReport bool `json:"stage_report,omitempty" yaml:"stage_report,omitempty"`
+
+	// RunShortCircuit stores run-dominated numeric data as runs
+	// (WithRunShortCircuit).
+	RunShortCircuit bool `json:"run_short_circuit,omitempty" yaml:"run_short_circuit,omitempty"`
+
+	// LatencyHistogram records operation latencies (WithLatencyHistogram).
+	LatencyHistogram bool `json:"latency_histogram,omitempty" yaml:"latency_histogram,omitempty"`
+}
+
+// Options returns the functional options equivalent to cfg.
+func (cfg CompressorConfig) Options() []CompressorOption {
+	var opts []CompressorOption
+	if cfg.Level != 0 {
+		opts = append(opts, WithCompressionLevel(cfg.Level))
+	}
+	if cfg.StageReport {
+		opts = append(opts, WithStageReport(true))
+	}
+	if cfg.RunShortCircuit {
+		opts = append(opts, WithRunShortCircuit(true))
+	}
+	if cfg.LatencyHistogram {
+		opts = append(opts, WithLatencyHistogram(true))
+	}
+	return opts
+}
+
+// NewCompressorFromConfig is like NewCompressor with the options of cfg.
+func NewCompressorFromConfig(cfg CompressorConfig) (*Compressor, error) {
+	return NewCompressor(cfg.Options()...)
+}
+
+// DecompressorConfig configures a Decompressor; see
+// NewDecompressorFromConfig.
+type DecompressorConfig struct {
+	// MemoryBudget bounds the memory of each operation in bytes, 0 for no
+	// limit (WithMemoryBudget).
+	MemoryBudget int64 `json:"memory_budget,omitempty" yaml:"memory_budget,omitempty"`
+
+	// LatencyHistogram records operation latencies
+	// (WithDecompressLatencyHistogram).
+	LatencyHistogram bool `json:"latency_histogram,omitempty" yaml:"latency_histogram,omitempty"`
+}
+
+// Options returns the functional options equivalent to cfg.
+func (cfg DecompressorConfig) Options() []DecompressorOption {
+	var opts []DecompressorOption
+	if cfg.MemoryBudget != 0 {
+		opts = append(opts, WithMemoryBudget(cfg.MemoryBudget))
+	}
+	if cfg.LatencyHistogram {
+		opts = append(opts, WithDecompressLatencyHistogram(true))
+	}
+	return opts
+}
+
+// NewDecompressorFromConfig is like NewDecompressor with the options of cfg.
+func NewDecompressorFromConfig(cfg DecompressorConfig) (*Decompressor, error) {
+	return NewDecompressor(cfg.Options()...)
+}
+
+// WriterConfig configures a Writer; see NewWriterFromConfig.
+//
+// Example:
+//
+//	// writer.json: {"frame_size": 262144, "frame_checksum": true}
+//	var cfg openzl.WriterConfig
+//	if err := json.Unmarshal(data, &cfg); err != nil {
+//		return err
+//	}
+//	writer, err := openzl.NewWriterFromConfig(file, cfg)
+type WriterConfig struct {
+	// FrameSize is the frame size in bytes, 0 for the package default
+	// (WithFrameSize).
+	FrameSize int `json:"frame_size,omitempty" yaml:"frame_size,omitempty"`
+
+	// LongWindow enables long-window mode with a window of this many bytes,
+	// 0 to disable it (WithLongWindow). It takes precedence over FrameSize.
+	LongWindow int `json:"long_window,omitempty" yaml:"long_window,omitempty"`
+
+	// ContentDefinedChunking cuts frames at content-defined boundaries of
+	// this average size in bytes, 0 to disable it
+	// (WithContentDefinedChunking).
+	ContentDefinedChunking int `json:"content_defined_chunking,omitempty" yaml:"content_defined_chunking,omitempty"`
+
+	// MaxCompressedFrameSize bounds the size of every compressed frame in
+	// bytes, 0 for no bound (WithMaxCompressedFrameSize).
+	MaxCompressedFrameSize int `json:"max_compressed_frame_size,omitempty" yaml:"max_compressed_frame_size,omitempty"`
+
+	// MaxInFlight enables asynchronous writes of up to this many bytes, 0
+	// for synchronous writes (WithMaxInFlight).
+	MaxInFlight int `json:"max_in_flight,omitempty" yaml:"max_in_flight,omitempty"`
+
+	// FrameChecksum appends a CRC32C to each frame (WithFrameChecksum).
+	FrameChecksum bool `json:"frame_checksum,omitempty" yaml:"frame_checksum,omitempty"`
+
+	// StoredFallback stores frames raw when compression does not help
+	// (WithStoredFallback).
+	StoredFallback bool `json:"stored_fallback,omitempty" yaml:"stored_fallback,omitempty"`
+
+	// AdaptiveLevel adjusts the compression level frame by frame
+	// (WithAdaptiveLevel).
+	AdaptiveLevel bool `json:"adaptive_level,omitempty" yaml:"adaptive_level,omitempty"`
+
+	// FlushOnEmptyWrite makes empty writes flush (WithFlushOnEmptyWrite).
+	FlushOnEmptyWrite bool `json:"flush_on_empty_write,omitempty" yaml:"flush_on_empty_write,omitempty"`
+
+	// OmitEmptyEndMarker writes nothing for a stream without data
+	// (WithEmptyPolicy(EmptyNoOutput)).
+	OmitEmptyEndMarker bool `json:"omit_empty_end_marker,omitempty" yaml:"omit_empty_end_marker,omitempty"`
+
+	// Compressor configures the Writer's compression context
+	// (WithCompressorOptions).
+	Compressor CompressorConfig `json:"compressor,omitzero" yaml:"compressor,omitempty"`
+}
+
+// Options returns the functional options equivalent to cfg.
+func (cfg WriterConfig) Options() []WriterOption {
+	var opts []WriterOption
+	if cfg.FrameSize != 0 {
+		opts = append(opts, WithFrameSize(cfg.FrameSize))
+	}
+	if cfg.LongWindow != 0 {
+		opts = append(opts, WithLongWindow(cfg.LongWindow))
+	}
+	if cfg.ContentDefinedChunking != 0 {
+		opts = append(opts, WithContentDefinedChunking(cfg.ContentDefinedChunking))
+	}
+	if cfg.MaxCompressedFrameSize != 0 {
+		opts = append(opts, WithMaxCompressedFrameSize(cfg.MaxCompressedFrameSize))
+	}
+	if cfg.MaxInFlight != 0 {
+		opts = append(opts, WithMaxInFlight(cfg.MaxInFlight))
+	}
+	if cfg.FrameChecksum {
+		opts = append(opts, WithFrameChecksum(true))
+	}
+	if cfg.StoredFallback {
+		opts = append(opts, WithStoredFallback(true))
+	}
+	if cfg.AdaptiveLevel {
+		opts = append(opts, WithAdaptiveLevel(true))
+	}
+	if cfg.FlushOnEmptyWrite {
+		opts = append(opts, WithFlushOnEmptyWrite(true))
+	}
+	if cfg.OmitEmptyEndMarker {
+		opts = append(opts, WithEmptyPolicy(EmptyNoOutput))
+	}
+	if copts := cfg.Compressor.Options(); len(copts) > 0 {
+		opts = append(opts, WithCompressorOptions(copts...))
+	}
+	return opts
+}
+
+// NewWriterFromConfig is like NewWriter with the options of cfg.
+func NewWriterFromConfig(w io.Writer, cfg WriterConfig) (*Writer, error) {
+	return NewWriter(w, cfg.Options()...)
+}
+
+// ReaderConfig configures a Reader; see NewReaderFromConfig.
+type ReaderConfig struct {
+	// BareFrames accepts one-shot frames as input (WithBareFrames).
+	BareFrames bool `json:"bare_frames,omitempty" yaml:"bare_frames,omitempty"`
+
+	// StrictReset rejects Reset before the previous stream was consumed
+	// (WithStrictReset).
+	StrictReset bool `json:"strict_reset,omitempty" yaml:"strict_reset,omitempty"`
+
+	// AllowTruncated recovers the complete frames of a cut stream
+	// (WithAllowTruncated).
+	AllowTruncated bool `json:"allow_truncated,omitempty" yaml:"allow_truncated,omitempty"`
+}
+
+// Options returns the functional options equivalent to cfg.
+func (cfg ReaderConfig) Options() []ReaderOption {
+	var opts []ReaderOption
+	if cfg.BareFrames {
+		opts = append(opts, WithBareFrames(true))
+	}
+	if cfg.StrictReset {
+		opts = append(opts, WithStrictReset(true))
+	}
+	if cfg.AllowTruncated {
+		opts = append(opts, WithAllowTruncated(true))
+	}
+	return opts
+}
+
+// NewReaderFromConfig is like NewReader with the options of cfg.
+func NewReaderFromConfig(r io.Reader, cfg ReaderConfig) (*Reader, error) {
+	return NewReader(r, cfg.Options()...)
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestWriterConfig_JSON(t *testing.T) {
+	data := []byte(`{
+		"frame_size": 131072,
+		"frame_checksum": true,
+		"omit_empty_end_marker": true,
+		"compressor": {"stage_report": true}
+	}`)
+	var cfg WriterConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriterFromConfig(&buf, cfg)
+	if err != nil {
+		t.Fatalf("NewWriterFromConfig failed: %v", err)
+	}
+	if w.frameSize != 131072 || !w.checksum || w.emptyPolicy != EmptyNoOutput {
+		t.Errorf("writer has frame size %d, checksum %v, empty policy %v", w.frameSize, w.checksum, w.emptyPolicy)
+	}
+	if !w.compressor.cfg.stageReport {
+		t.Error("compressor options were not applied")
+	}
+
+	input := bytes.Repeat([]byte("config driven "), 20000)
+	if _, err := w.Write(input); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	r, err := NewReaderFromConfig(&buf, ReaderConfig{StrictReset: true})
+	if err != nil {
+		t.Fatalf("NewReaderFromConfig failed: %v", err)
+	}
+	defer r.Close()
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+	if !bytes.Equal(got, input) {
+		t.Error("round trip mismatch")
+	}
+
+	// Marshaling the zero value writes no settings
+	if out, _ := json.Marshal(WriterConfig{}); string(out) != "{}" {
+		t.Errorf("zero WriterConfig marshals to %s, want {}", out)
+	}
+}
+
+func TestConfig_InvalidValue(t *testing.T) {
+	_, err := NewWriterFromConfig(io.Discard, WriterConfig{FrameSize: 100})
+	var oe *OptionError
+	if !errors.As(err, &oe) || oe.Option != "WithFrameSize" {
+		t.Errorf("got error %v, want an *OptionError for WithFrameSize", err)
+	}
+
+	_, err = NewDecompressorFromConfig(DecompressorConfig{MemoryBudget: -1})
+	if !errors.As(err, &oe) || oe.Option != "WithMemoryBudget" {
+		t.Errorf("got error %v, want an *OptionError for WithMemoryBudget", err)
+	}
+
+	_, err = NewCompressorFromConfig(CompressorConfig{Level: MaxCompressionLevel + 1})
+	if !errors.As(err, &oe) || oe.Option != "WithCompressionLevel" {
+		t.Errorf("got error %v, want an *OptionError for WithCompressionLevel", err)
+	}
+
+	c, err := NewCompressorFromConfig(CompressorConfig{})
+	if err != nil {
+		t.Fatalf("zero CompressorConfig failed: %v", err)
+	}
+	c.Close()
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/borischu/go-openzl/spec"
+)
+
+// Container format used by the domain helpers (CompressProfile and friends).
+//
+// Domain helpers split their input into several sections, such as metadata
+// and separately compressed columns, and store them together in one buffer:
+//
+//	+-------------+----------+------------------+---------------------------+
+//	| magic "ZLGC"| kind (1) | count (uvarint)  | count x (len uvarint, data) |
+//	+-------------+----------+------------------+---------------------------+
+//
+// The kind byte identifies the helper that wrote the container, so passing
+// the output of one helper to the decoder of another fails cleanly. New
+// kinds must be added to spec.ContainerKinds too.
+const containerMagic = spec.ContainerMagic
+
+// containerKind identifies the helper that produced a container.
+type containerKind byte
+
+const (
+	containerProfile containerKind = iota + 1
+	containerUUIDs
+	containerIPs
+	containerFlows
+	containerTicks
+	containerReads
+	containerEmbeddings
+	containerLossy
+	containerRuns
+	containerSparse
+	containerRecords
+	containerDurations
+	containerCounters
+	containerFallback
+	containerChunks
+)
+
+// encodeContainer concatenates sections into a container of the given kind.
+func encodeContainer(kind containerKind, sections ...[]byte) []byte {
+	size := len(containerMagic) + 1 + binary.MaxVarintLen64
+	for _, s := range sections {
+		size += binary.MaxVarintLen64 + len(s)
+	}
+
+	out := make([]byte, 0, size)
+	out = append(out, containerMagic...)
+	out = append(out, byte(kind))
+	out = binary.AppendUvarint(out, uint64(len(sections)))
+	for _, s := range sections {
+		out = binary.AppendUvarint(out, uint64(len(s)))
+		out = append(out, s...)
+	}
+	return out
+}
+
+// decodeContainer splits a container of the given kind into its sections.
+// It returns ErrCorruptedData unless b holds exactly n sections, or any
+// number of sections if n is negative.
+func decodeContainer(b []byte, kind containerKind, n int) ([][]byte, error) {
+	if len(b) < len(containerMagic)+1 || string(b[:len(containerMagic)]) != containerMagic {
+		return nil, fmt.Errorf("%w: not a go-openzl container", ErrCorruptedData)
+	}
+	if got := containerKind(b[len(containerMagic)]); got != kind {
+		return nil, fmt.Errorf("%w: container kind %d, want %d", ErrCorruptedData, got, kind)
+	}
+	b = b[len(containerMagic)+1:]
+
+	count, k := binary.Uvarint(b)
+	if k <= 0 || (n >= 0 && count != uint64(n)) {
+		return nil, fmt.Errorf("%w: container has %d sections, want %d", ErrCorruptedData, count, n)
+	}
+	b = b[k:]
+
+	// Every section takes at least one byte for its length
+	if count > uint64(len(b)) {
+		return nil, fmt.Errorf("%w: truncated container", ErrCorruptedData)
+	}
+	sections := make([][]byte, count)
+	for i := range sections {
+		size, k := binary.Uvarint(b)
+		if k <= 0 || size > uint64(len(b)-k) {
+			return nil, fmt.Errorf("%w: truncated container section %d", ErrCorruptedData, i)
+		}
+		b = b[k:]
+		sections[i] = b[:size:size]
+		b = b[size:]
+	}
+	if len(b) != 0 {
+		return nil, fmt.Errorf("%w: %d trailing bytes after container", ErrCorruptedData, len(b))
+	}
+	return sections, nil
+}
+
+// compressSection compresses a section that may be empty. Empty sections are
+// stored as zero-length sections, since OpenZL frames cannot be empty.
+func compressSection(b []byte) ([]byte, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+	return Compress(b)
+}
+
+// decompressSection reverses compressSection.
+func decompressSection(b []byte) ([]byte, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+	return Decompress(b)
+}
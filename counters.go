@@ -0,0 +1,119 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"fmt"
+	"time"
+)
+
+// CompressDurations compresses a column of durations, such as request
+// latencies or the intervals between events.
+//
+// Durations are delta-encoded zero, one, or two times, whichever makes the
+// values smallest: independent latencies are stored as they are, while
+// cumulative or steadily growing durations (uptimes, elapsed times sampled
+// at a fixed rate) become near-constant small integers. The choice is made
+// from the data and recorded in the output, so DecompressDurations needs no
+// parameters.
+//
+// Example:
+//
+//	compressed, err := openzl.CompressDurations(latencies)
+//	...
+//	latencies, err := openzl.DecompressDurations(compressed)
+func CompressDurations(d []time.Duration) ([]byte, error) {
+	if len(d) == 0 {
+		return nil, ErrEmptyInput
+	}
+	values := make([]int64, len(d))
+	for i, v := range d {
+		values[i] = int64(v)
+	}
+	return compressDeltaColumn(containerDurations, values)
+}
+
+// DecompressDurations restores durations compressed by CompressDurations.
+func DecompressDurations(compressed []byte) ([]time.Duration, error) {
+	values, err := decompressDeltaColumn(containerDurations, compressed)
+	if err != nil {
+		return nil, err
+	}
+	d := make([]time.Duration, len(values))
+	for i, v := range values {
+		d[i] = time.Duration(v)
+	}
+	return d, nil
+}
+
+// CompressCounters compresses successive readings of monotonically
+// increasing counters, such as cumulative request or byte counts from a
+// metrics system.
+//
+// Readings are delta-encoded once or twice, like in CompressDurations:
+// a counter growing at a steady rate turns into a run of near-zero
+// delta-of-deltas, and one growing irregularly into small deltas. Counter
+// resets and wraparound need no special handling; they only cost a few
+// larger values, and the round trip stays exact.
+//
+// Example:
+//
+//	compressed, err := openzl.CompressCounters(requestsTotal)
+//	...
+//	requestsTotal, err := openzl.DecompressCounters(compressed)
+func CompressCounters(c []uint64) ([]byte, error) {
+	if len(c) == 0 {
+		return nil, ErrEmptyInput
+	}
+	values := make([]int64, len(c))
+	for i, v := range c {
+		values[i] = int64(v)
+	}
+	return compressDeltaColumn(containerCounters, values)
+}
+
+// DecompressCounters restores counter readings compressed by
+// CompressCounters.
+func DecompressCounters(compressed []byte) ([]uint64, error) {
+	values, err := decompressDeltaColumn(containerCounters, compressed)
+	if err != nil {
+		return nil, err
+	}
+	c := make([]uint64, len(values))
+	for i, v := range values {
+		c[i] = uint64(v)
+	}
+	return c, nil
+}
+
+// compressDeltaColumn delta-encodes values to the order chooseDeltaOrder
+// picks and stores the order and the compressed differences in a container
+// of the given kind.
+func compressDeltaColumn(kind containerKind, values []int64) ([]byte, error) {
+	order := chooseDeltaOrder(values)
+	frame, err := CompressNumeric(deltaEncodeOrder(values, order))
+	if err != nil {
+		return nil, fmt.Errorf("compress values: %w", err)
+	}
+	return encodeContainer(kind, []byte{byte(order)}, frame), nil
+}
+
+// decompressDeltaColumn reverses compressDeltaColumn.
+func decompressDeltaColumn(kind containerKind, compressed []byte) ([]int64, error) {
+	if len(compressed) == 0 {
+		return nil, ErrEmptyInput
+	}
+	sections, err := decodeContainer(compressed, kind, 2)
+	if err != nil {
+		return nil, err
+	}
+	if len(sections[0]) != 1 || sections[0][0] > maxDeltaOrder {
+		return nil, fmt.Errorf("%w: invalid delta order", ErrCorruptedData)
+	}
+	deltas, err := DecompressNumeric[int64](sections[1])
+	if err != nil {
+		return nil, fmt.Errorf("decompress values: %w", err)
+	}
+	return deltaDecodeOrder(deltas, int(sections[0][0])), nil
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestCompressDurations(t *testing.T) {
+	latencies := make([]time.Duration, 1000)
+	uptimes := make([]time.Duration, 1000)
+	for i := range latencies {
+		latencies[i] = time.Duration(i*7919%1000) * time.Microsecond
+		uptimes[i] = time.Duration(i)*15*time.Second + time.Duration(i%3)*time.Millisecond
+	}
+
+	for name, d := range map[string][]time.Duration{
+		"latencies": latencies,
+		"uptimes":   uptimes,
+		"single":    {-time.Hour},
+	} {
+		t.Run(name, func(t *testing.T) {
+			compressed, err := CompressDurations(d)
+			if err != nil {
+				t.Fatalf("CompressDurations() failed: %v", err)
+			}
+			got, err := DecompressDurations(compressed)
+			if err != nil {
+				t.Fatalf("DecompressDurations() failed: %v", err)
+			}
+			if !slices.Equal(got, d) {
+				t.Error("round trip mismatch")
+			}
+		})
+	}
+}
+
+func TestCompressCounters(t *testing.T) {
+	steady := make([]uint64, 1000)
+	reset := make([]uint64, 1000)
+	for i := range steady {
+		steady[i] = 1e9 + uint64(i)*1200 + uint64(i%4)
+		reset[i] = uint64(i%300) * 17
+	}
+	wrap := []uint64{math.MaxUint64 - 2, math.MaxUint64, 1, 3}
+
+	for name, c := range map[string][]uint64{"steady": steady, "reset": reset, "wrap": wrap} {
+		t.Run(name, func(t *testing.T) {
+			compressed, err := CompressCounters(c)
+			if err != nil {
+				t.Fatalf("CompressCounters() failed: %v", err)
+			}
+			got, err := DecompressCounters(compressed)
+			if err != nil {
+				t.Fatalf("DecompressCounters() failed: %v", err)
+			}
+			if !slices.Equal(got, c) {
+				t.Error("round trip mismatch")
+			}
+		})
+	}
+
+	if _, err := CompressCounters(nil); !errors.Is(err, ErrEmptyInput) {
+		t.Errorf("CompressCounters(nil) error = %v, want ErrEmptyInput", err)
+	}
+	durations, _ := CompressDurations([]time.Duration{1, 2, 3})
+	if _, err := DecompressCounters(durations); !errors.Is(err, ErrCorruptedData) {
+		t.Errorf("DecompressCounters(durations) error = %v, want ErrCorruptedData", err)
+	}
+}
+
+func TestChooseDeltaOrder(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []int64
+		want   int
+	}{
+		{"unordered", []int64{5, -3, 9, 0, 7, -8, 2}, 0},
+		{"counter", []int64{1000, 1003, 1043, 1044, 1069, 1071, 1131, 1136}, 1},
+		{"steady", []int64{1e12, 1e12 + 60, 1e12 + 120, 1e12 + 180, 1e12 + 240, 1e12 + 300}, 2},
+	}
+	for _, tt := range tests {
+		if got := chooseDeltaOrder(tt.values); got != tt.want {
+			t.Errorf("%s: chooseDeltaOrder() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
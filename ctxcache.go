@@ -0,0 +1,113 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"math/rand/v2"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/borischu/go-openzl/internal/cgo"
+)
+
+// ctxCacheDepth is the number of idle contexts kept per stripe.
+const ctxCacheDepth = 2
+
+// ctxCache keeps idle native contexts for the one-shot functions, so that
+// Compress and Decompress do not pay for creating and freeing a context on
+// every call.
+//
+// A single locked free list would serialize every one-shot call in the
+// program, so the cache is split into GOMAXPROCS stripes, each with its own
+// lock, and a call picks a stripe at random. Go does not expose the current
+// P, but with as many stripes as Ps, concurrent calls rarely share one. Each
+// stripe keeps at most ctxCacheDepth contexts, which bounds the native memory
+// held by idle contexts. A sync.Pool would not do: it drops entries at every
+// garbage collection without a chance to free their C memory.
+//
+// The cache also counts the contexts it has handed out, so that Shutdown can
+// wait for one-shot operations in flight.
+type ctxCache[T any] struct {
+	stripes []ctxStripe[T]
+	create  func() (T, error)
+	destroy func(T)
+	inUse   atomic.Int64 // Contexts returned by get and not yet put or discarded
+}
+
+// ctxStripe is one independently locked free list of a ctxCache.
+type ctxStripe[T any] struct {
+	mu   sync.Mutex
+	free []T
+	_    [64]byte // Keep neighboring stripes on separate cache lines
+}
+
+func newCtxCache[T any](create func() (T, error), destroy func(T)) *ctxCache[T] {
+	return &ctxCache[T]{
+		stripes: make([]ctxStripe[T], runtime.GOMAXPROCS(0)),
+		create:  create,
+		destroy: destroy,
+	}
+}
+
+var (
+	cctxCache = newCtxCache(newCCtx, (*cgo.CCtx).Free)
+	dctxCache = newCtxCache(cgo.NewDCtx, (*cgo.DCtx).Free)
+)
+
+// get returns an idle context, or a new one if the chosen stripe has none.
+// Every context obtained from get must be given back with put or discard.
+func (c *ctxCache[T]) get() (T, error) {
+	c.inUse.Add(1)
+	s := &c.stripes[rand.IntN(len(c.stripes))]
+	s.mu.Lock()
+	if n := len(s.free); n > 0 {
+		ctx := s.free[n-1]
+		s.free = s.free[:n-1]
+		s.mu.Unlock()
+		return ctx, nil
+	}
+	s.mu.Unlock()
+	ctx, err := c.create()
+	if err != nil {
+		c.inUse.Add(-1)
+	}
+	return ctx, err
+}
+
+// put returns a context after successful use. After Shutdown, contexts are
+// destroyed instead of kept.
+func (c *ctxCache[T]) put(ctx T) {
+	defer c.inUse.Add(-1)
+	s := &c.stripes[rand.IntN(len(c.stripes))]
+	s.mu.Lock()
+	if len(s.free) < ctxCacheDepth && !shutDown.Load() {
+		s.free = append(s.free, ctx)
+		s.mu.Unlock()
+		return
+	}
+	s.mu.Unlock()
+	c.destroy(ctx)
+}
+
+// discard destroys a context that took part in a failed operation, so that
+// no context in an unknown state is reused.
+func (c *ctxCache[T]) discard(ctx T) {
+	c.destroy(ctx)
+	c.inUse.Add(-1)
+}
+
+// drain destroys every idle context.
+func (c *ctxCache[T]) drain() {
+	for i := range c.stripes {
+		s := &c.stripes[i]
+		s.mu.Lock()
+		free := s.free
+		s.free = nil
+		s.mu.Unlock()
+		for _, ctx := range free {
+			c.destroy(ctx)
+		}
+	}
+}
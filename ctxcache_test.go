@@ -0,0 +1,78 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"bytes"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCtxCache(t *testing.T) {
+	var created, destroyed atomic.Int64
+	c := newCtxCache(func() (*int, error) {
+		created.Add(1)
+		return new(int), nil
+	}, func(*int) {
+		destroyed.Add(1)
+	})
+
+	var wg sync.WaitGroup
+	for range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 1000 {
+				ctx, err := c.get()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				*ctx++
+				c.put(ctx)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Idle contexts are bounded, and every other context was destroyed
+	idle := int64(0)
+	for i := range c.stripes {
+		idle += int64(len(c.stripes[i].free))
+	}
+	if limit := int64(len(c.stripes) * ctxCacheDepth); idle > limit {
+		t.Errorf("%d idle contexts, want at most %d", idle, limit)
+	}
+	if created.Load() != idle+destroyed.Load() {
+		t.Errorf("created %d contexts, but %d are idle and %d destroyed", created.Load(), idle, destroyed.Load())
+	}
+	if created.Load() >= 16*1000 {
+		t.Errorf("created %d contexts for 16000 calls, want reuse", created.Load())
+	}
+}
+
+func TestCompress_Concurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 200 {
+				data := bytes.Repeat([]byte{byte(g), byte(i)}, 100+i)
+				compressed, err := Compress(data)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				got, err := Decompress(compressed)
+				if err != nil || !bytes.Equal(got, data) {
+					t.Errorf("round trip failed: %v", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Package datagen generates standardized, deterministic corpora for
+// benchmarking compression.
+//
+// These are the same generators used by go-openzl's own comparison
+// benchmarks, exported so downstream users can benchmark profiles and
+// options on identical data. Every generator is deterministic: the same
+// arguments always produce byte-identical output across runs, platforms,
+// and releases, so results remain comparable over time.
+//
+// The package does not depend on the OpenZL C library and can be used in
+// builds without cgo.
+//
+// Example:
+//
+//	data := datagen.Logs(1 << 20)
+//	compressed, _ := openzl.Compress(data)
+//	fmt.Printf("ratio: %.2fx\n", float64(len(data))/float64(len(compressed)))
+package datagen
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+)
+
+// seed is the fixed PRNG seed shared by all generators.
+const seed = 0x6f70656e7a6c
+
+// newRand returns a deterministic PRNG for one generator call.
+func newRand() *rand.Rand {
+	return rand.New(rand.NewPCG(seed, seed))
+}
+
+// Repeated returns size bytes of a short sentence repeated end to end.
+// It represents highly redundant data and compresses extremely well.
+func Repeated(size int) []byte {
+	pattern := []byte("This is a test pattern that repeats. ")
+	data := make([]byte, 0, size+len(pattern))
+	for len(data) < size {
+		data = append(data, pattern...)
+	}
+	return data[:size]
+}
+
+// Mixed returns size bytes alternating between a short repeating pattern
+// and a more varied byte sequence every 50 bytes.
+func Mixed(size int) []byte {
+	data := make([]byte, size)
+	for i := range data {
+		if i%100 < 50 {
+			// Repeated pattern
+			data[i] = byte(i % 10)
+		} else {
+			// More varied
+			data[i] = byte((i * 7) % 256)
+		}
+	}
+	return data
+}
+
+// Text returns size bytes of space-separated English words.
+func Text(size int) []byte {
+	words := []string{"the", "quick", "brown", "fox", "jumps", "over", "lazy", "dog"}
+	var buf bytes.Buffer
+	buf.Grow(size + 8)
+	for buf.Len() < size {
+		for _, word := range words {
+			buf.WriteString(word)
+			buf.WriteByte(' ')
+			if buf.Len() >= size {
+				break
+			}
+		}
+	}
+	return buf.Bytes()[:size]
+}
+
+// Int64Sequence returns n values increasing by a constant step of 10.
+// This is the best case for delta-based numeric graphs.
+func Int64Sequence(n int) []int64 {
+	data := make([]int64, n)
+	for i := range data {
+		data[i] = int64(i * 10)
+	}
+	return data
+}
+
+// Timestamps returns n nanosecond Unix timestamps starting at 2025-01-01,
+// spaced about one millisecond apart with small random jitter, as found in
+// event and metrics streams.
+func Timestamps(n int) []int64 {
+	r := newRand()
+	data := make([]int64, n)
+	ts := int64(1735689600) * 1e9
+	for i := range data {
+		ts += 1e6 + r.Int64N(1e4)
+		data[i] = ts
+	}
+	return data
+}
+
+// Float64Walk returns n values of a bounded random walk, resembling sensor
+// readings or prices.
+func Float64Walk(n int) []float64 {
+	r := newRand()
+	data := make([]float64, n)
+	v := 100.0
+	for i := range data {
+		v += r.NormFloat64() * 0.1
+		data[i] = v
+	}
+	return data
+}
+
+// Logs returns size bytes of JSON-lines structured log records with
+// timestamps, levels, services, and latencies drawn from small vocabularies.
+func Logs(size int) []byte {
+	r := newRand()
+	levels := []string{"debug", "info", "info", "info", "warn", "error"}
+	services := []string{"api", "auth", "billing", "search", "worker"}
+	messages := []string{
+		"request completed",
+		"cache miss",
+		"user authenticated",
+		"retrying upstream call",
+		"query executed",
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(size + 256)
+	ts := int64(1735689600000)
+	for buf.Len() < size {
+		ts += r.Int64N(50)
+		fmt.Fprintf(&buf, `{"ts":%d,"level":%q,"service":%q,"msg":%q,"latency_ms":%d,"status":%d}`+"\n",
+			ts,
+			levels[r.IntN(len(levels))],
+			services[r.IntN(len(services))],
+			messages[r.IntN(len(messages))],
+			r.IntN(500),
+			[]int{200, 200, 200, 201, 404, 500}[r.IntN(6)],
+		)
+	}
+	return buf.Bytes()[:size]
+}
+
+// CSV returns size bytes of comma-separated rows with a header line and
+// integer, decimal, and categorical columns.
+func CSV(size int) []byte {
+	r := newRand()
+	regions := []string{"us-east", "us-west", "eu-central", "ap-south"}
+
+	var buf bytes.Buffer
+	buf.Grow(size + 128)
+	buf.WriteString("id,timestamp,region,price,quantity\n")
+	ts := int64(1735689600)
+	for id := 1; buf.Len() < size; id++ {
+		ts += r.Int64N(10)
+		fmt.Fprintf(&buf, "%d,%d,%s,%.2f,%d\n",
+			id,
+			ts,
+			regions[r.IntN(len(regions))],
+			10+r.Float64()*90,
+			1+r.IntN(20),
+		)
+	}
+	return buf.Bytes()[:size]
+}
+
+// Random returns size bytes of uniformly random data, which is effectively
+// incompressible and useful for measuring worst-case overhead.
+func Random(size int) []byte {
+	r := newRand()
+	data := make([]byte, size)
+	for i := 0; i+8 <= size; i += 8 {
+		v := r.Uint64()
+		for j := 0; j < 8; j++ {
+			data[i+j] = byte(v >> (8 * j))
+		}
+	}
+	for i := size &^ 7; i < size; i++ {
+		data[i] = byte(r.Uint32())
+	}
+	return data
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package datagen
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGenerators_SizeAndDeterminism(t *testing.T) {
+	generators := []struct {
+		name string
+		fn   func(int) []byte
+	}{
+		{"Repeated", Repeated},
+		{"Mixed", Mixed},
+		{"Text", Text},
+		{"Logs", Logs},
+		{"CSV", CSV},
+		{"Random", Random},
+	}
+
+	for _, g := range generators {
+		t.Run(g.name, func(t *testing.T) {
+			for _, size := range []int{0, 1, 13, 4096} {
+				a := g.fn(size)
+				if len(a) != size {
+					t.Errorf("len = %d, want %d", len(a), size)
+				}
+				if b := g.fn(size); !bytes.Equal(a, b) {
+					t.Errorf("output for size %d is not deterministic", size)
+				}
+			}
+		})
+	}
+}
+
+func TestNumericGenerators(t *testing.T) {
+	if got := Int64Sequence(4); got[3] != 30 {
+		t.Errorf("Int64Sequence(4)[3] = %d, want 30", got[3])
+	}
+
+	ts := Timestamps(100)
+	for i := 1; i < len(ts); i++ {
+		if ts[i] <= ts[i-1] {
+			t.Fatalf("Timestamps not increasing at %d", i)
+		}
+	}
+
+	if a, b := Float64Walk(10), Float64Walk(10); a[9] != b[9] {
+		t.Error("Float64Walk is not deterministic")
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"fmt"
+	"math"
+	"os"
+)
+
+// DecompressToFile decompresses compressed into the file at path, creating
+// or truncating it, and returns the number of bytes written.
+//
+// For a bare frame from Compress, disk space for the decompressed size
+// recorded in the frame header is first reserved, the file is
+// memory-mapped, and OpenZL decompresses directly into the mapping.
+// Restoring a file of many gigabytes therefore needs neither a heap buffer
+// nor a native one of that size; the operating system writes the pages back
+// as it sees fit. The reservation makes a full disk fail the call rather
+// than fault the mapping. Where space cannot be reserved, as on platforms
+// other than Linux or file systems without fallocate, or the file cannot be
+// mapped, for example because it is larger than the address space,
+// DecompressToFile falls back to DecompressTo.
+// Writer streams do not record their total size and always take that path.
+//
+// As with any write to a file, the data is not guaranteed to be on stable
+// storage until the file is synced.
+//
+// Example:
+//
+//	n, err := openzl.DecompressToFile("restore/dump.bin", compressed)
+//
+// Returns ErrEmptyInput if compressed is empty, a decompression error if it
+// is invalid or corrupted, or the error from creating, sizing, or writing
+// the file. On error, the file at path is removed.
+func DecompressToFile(path string, compressed []byte) (written int64, err error) {
+	if len(compressed) == 0 {
+		return 0, ErrEmptyInput
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(path)
+		}
+	}()
+
+	if isStream(compressed) {
+		return DecompressTo(f, compressed)
+	}
+
+	size, err := DecompressedSize(compressed)
+	if err != nil {
+		return 0, err
+	}
+	if size == 0 || size > math.MaxInt {
+		return DecompressTo(f, compressed)
+	}
+	if err := reserveFile(f, size); err != nil {
+		// The buffered path reports a full disk as a write error
+		if err := f.Truncate(0); err != nil {
+			return 0, err
+		}
+		return DecompressTo(f, compressed)
+	}
+	m, unmap, err := mapFile(f, int(size))
+	if err != nil {
+		if err := f.Truncate(0); err != nil {
+			return 0, err
+		}
+		return DecompressTo(f, compressed)
+	}
+
+	n, err := decompressInto(m, compressed)
+	if uerr := unmap(); err == nil {
+		err = uerr
+	}
+	if err != nil {
+		return 0, err
+	}
+	if int64(n) < size {
+		err = f.Truncate(int64(n))
+	}
+	return int64(n), err
+}
+
+// decompressInto decompresses the bare frame or chunked input src into dst
+// with a cached context.
+func decompressInto(dst, src []byte) (int, error) {
+	ctx, err := dctxCache.get()
+	if err != nil {
+		return 0, fmt.Errorf("create context: %w", err)
+	}
+	if isChunked(src) {
+		frames, sizes, total, err := chunkSizes(src)
+		if err == nil && total > len(dst) {
+			err = fmt.Errorf("%w: need %d bytes", ErrBufferTooSmall, total)
+		}
+		if err == nil {
+			err = decompressFrames(dst, frames, sizes, ctx.Decompress)
+		}
+		if err != nil {
+			dctxCache.discard(ctx)
+			return 0, err
+		}
+		dctxCache.put(ctx)
+		return total, nil
+	}
+	n, err := ctx.Decompress(dst, src)
+	if err != nil {
+		dctxCache.discard(ctx)
+		return 0, fmt.Errorf("decompress: %w", err)
+	}
+	dctxCache.put(ctx)
+	return n, nil
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/borischu/go-openzl/datagen"
+)
+
+func TestDecompressToFile(t *testing.T) {
+	data := datagen.Text(3<<20 + 17)
+	frame, err := Compress(data)
+	if err != nil {
+		t.Fatalf("Compress() failed: %v", err)
+	}
+	stream, err := CompressFrom(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("CompressFrom() failed: %v", err)
+	}
+
+	for name, compressed := range map[string][]byte{"frame": frame, "stream": stream} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "out.bin")
+			// A longer existing file must be truncated
+			if err := os.WriteFile(path, make([]byte, len(data)*2), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			n, err := DecompressToFile(path, compressed)
+			if err != nil {
+				t.Fatalf("DecompressToFile() failed: %v", err)
+			}
+			if n != int64(len(data)) {
+				t.Errorf("wrote %d bytes, want %d", n, len(data))
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Errorf("file content mismatch (%d bytes)", len(got))
+			}
+		})
+	}
+}
+
+func TestDecompressToFile_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.bin")
+	if _, err := DecompressToFile(path, nil); !errors.Is(err, ErrEmptyInput) {
+		t.Errorf("DecompressToFile(nil) error = %v, want ErrEmptyInput", err)
+	}
+	if _, err := DecompressToFile(path, []byte("not a frame")); err == nil {
+		t.Error("DecompressToFile() accepted garbage")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("file left behind after failure: %v", err)
+	}
+}
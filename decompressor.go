@@ -5,7 +5,10 @@ package openzl
 
 import (
 	"fmt"
+	"math"
+	"runtime"
 	"sync"
+	"time"
 
 	"github.com/borischu/go-openzl/internal/cgo"
 )
@@ -38,8 +41,49 @@ import (
 //		// Use decompressed data...
 //	}
 type Decompressor struct {
-	mu  sync.Mutex   // Protects ctx for thread safety
-	ctx *cgo.DCtx    // Underlying decompression context
+	mu     sync.Mutex // Protects ctx for thread safety
+	ctx    *cgo.DCtx  // Underlying decompression context
+	budget int64      // Memory budget per operation in bytes, 0 for unlimited
+
+	latency *latencyHistogram // Operation latencies, nil unless WithDecompressLatencyHistogram
+
+	// Sizes of the previous operation, used to predict the output size
+	lastIn, lastOut int
+
+	cleanup  runtime.Cleanup // Releases ctx if the Decompressor is never closed
+	unclosed *unclosed       // Argument of cleanup
+}
+
+// DecompressorOption configures a Decompressor during creation.
+type DecompressorOption func(*decompressorConfig) error
+
+// decompressorConfig holds the configuration options for Decompressor.
+type decompressorConfig struct {
+	memoryBudget int64 // Maximum memory per operation (WithMemoryBudget)
+	latency      bool  // Record operation latencies (WithDecompressLatencyHistogram)
+}
+
+// WithMemoryBudget limits the memory a single decompression may use to n
+// bytes, counting both the output buffer and the native decoder's working
+// memory.
+//
+// Frame headers declare their decompressed size, so a small malicious or
+// corrupt frame can otherwise make a shared decompression service allocate
+// gigabytes. With a budget, such frames are rejected with
+// ErrMemoryBudgetExceeded before anything is allocated.
+//
+// Native working memory cannot be measured directly, so it is estimated as
+// one more copy of the output; the check is conservative accordingly. Calls
+// on one Decompressor are serialized, so the budget also bounds the total
+// memory held by the Decompressor at any time.
+func WithMemoryBudget(n int64) DecompressorOption {
+	return func(cfg *decompressorConfig) error {
+		if n < 1 {
+			return rangeError("WithMemoryBudget", n, 1, math.MaxInt64)
+		}
+		cfg.memoryBudget = n
+		return nil
+	}
 }
 
 // NewDecompressor creates a new reusable Decompressor.
@@ -50,22 +94,50 @@ type Decompressor struct {
 //
 // Example:
 //
-//	decompressor, err := openzl.NewDecompressor()
+//	decompressor, err := openzl.NewDecompressor(openzl.WithMemoryBudget(64 << 20))
 //	if err != nil {
 //		return err
 //	}
 //	defer decompressor.Close()
 //
-// Returns an error if the underlying decompression context cannot be created.
-func NewDecompressor() (*Decompressor, error) {
+// Returns an error if the underlying decompression context cannot be created
+// or if any of the provided options are invalid.
+func NewDecompressor(opts ...DecompressorOption) (*Decompressor, error) {
+	cfg := &decompressorConfig{}
+	for _, opt := range opts {
+		if err := opt(cfg); err != nil {
+			return nil, fmt.Errorf("apply option: %w", err)
+		}
+	}
+
 	ctx, err := cgo.NewDCtx()
 	if err != nil {
 		return nil, fmt.Errorf("create context: %w", err)
 	}
 
-	return &Decompressor{
-		ctx: ctx,
-	}, nil
+	d := &Decompressor{
+		ctx:    ctx,
+		budget: cfg.memoryBudget,
+	}
+	if cfg.latency {
+		d.latency = &latencyHistogram{}
+	}
+	d.cleanup, d.unclosed = watchUnclosed(d, "Decompressor", ctx.Free)
+	return d, nil
+}
+
+// checkBudget returns ErrMemoryBudgetExceeded if decompressing a frame of
+// decompressed size n would exceed the memory budget.
+func (d *Decompressor) checkBudget(n int) error {
+	if d.budget == 0 {
+		return nil
+	}
+	// Output buffer plus estimated native working memory
+	need := 2 * int64(n)
+	if need > d.budget {
+		return fmt.Errorf("%w: frame needs about %d bytes, budget is %d", ErrMemoryBudgetExceeded, need, d.budget)
+	}
+	return nil
 }
 
 // Decompress decompresses OpenZL-compressed data using the reusable decompression context.
@@ -77,6 +149,9 @@ func NewDecompressor() (*Decompressor, error) {
 // The input data is not modified. The returned decompressed data is a newly
 // allocated slice containing only the decompressed bytes (no extra capacity).
 //
+// Like Decompress, it reassembles the chunks of large inputs split by
+// Compress; the memory budget then applies to their total size.
+//
 // Returns an error if:
 //   - src is empty (use ErrEmptyInput check)
 //   - src does not contain valid OpenZL compressed data
@@ -97,23 +172,103 @@ func (d *Decompressor) Decompress(src []byte) ([]byte, error) {
 	// Lock for thread safety
 	d.mu.Lock()
 	defer d.mu.Unlock()
+	if d.latency != nil {
+		defer d.latency.observe(time.Now())
+	}
+	if isChunked(src) {
+		return decompressChunks(src, d.ctx.Decompress, d.checkBudget)
+	}
 
-	// Get decompressed size from frame header
-	dstSize, err := cgo.GetDecompressedSize(src)
+	// Guess the output size from the previous operation's ratio, so that
+	// the size lookup and decompression usually take a single cgo call
+	var dst []byte
+	if guess := d.sizeGuess(len(src)); guess > 0 && d.checkBudget(guess) == nil {
+		dst = make([]byte, guess)
+	}
+	n, dstSize, err := d.ctx.DecompressSized(dst, src)
 	if err != nil {
-		return nil, fmt.Errorf("get decompressed size: %w", err)
+		if dstSize == 0 {
+			return nil, fmt.Errorf("get decompressed size: %w", diagnoseFrameError(src, err))
+		}
+		return nil, fmt.Errorf("decompress: %w", err)
 	}
+	if err := d.checkBudget(dstSize); err != nil {
+		return nil, err
+	}
+
+	// The guess was too small: decompress again into an exact buffer
+	if dstSize > len(dst) {
+		dst = make([]byte, dstSize)
+		n, err = d.ctx.Decompress(dst, src)
+		if err != nil {
+			return nil, fmt.Errorf("decompress: %w", err)
+		}
+	}
+	d.lastIn, d.lastOut = len(src), n
 
-	// Allocate destination buffer
-	dst := make([]byte, dstSize)
+	return dst[:n:n], nil
+}
+
+// DecompressScatter decompresses src across the pre-allocated buffers dsts,
+// filling each completely before moving to the next, and returns the number
+// of bytes written. It is the counterpart of Compressor.Compress2D, for
+// network stacks and other callers whose memory comes in fixed-size chunks.
+//
+// Buffers after the last one written are left untouched. Empty buffers are
+// skipped. Since the output never exceeds the buffers, the memory budget
+// does not apply.
+//
+// Example:
+//
+//	pages := [][]byte{page0, page1, page2}
+//	n, err := decompressor.DecompressScatter(pages, compressed)
+//
+// This method is safe for concurrent use by multiple goroutines.
+//
+// Returns ErrBufferTooSmall if the decompressed data does not fit in dsts,
+// in which case nothing is written, or a decompression error.
+func (d *Decompressor) DecompressScatter(dsts [][]byte, src []byte) (int, error) {
+	if len(src) == 0 {
+		return 0, ErrEmptyInput
+	}
 
-	// Decompress using reusable context
-	n, err := d.ctx.Decompress(dst, src)
+	// Lock for thread safety
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.latency != nil {
+		defer d.latency.observe(time.Now())
+	}
+	if isChunked(src) {
+		return scatterChunks(dsts, src, d.ctx.DecompressScatter)
+	}
+
+	n, size, err := d.ctx.DecompressScatter(dsts, src)
 	if err != nil {
-		return nil, fmt.Errorf("decompress: %w", err)
+		if size == 0 {
+			return 0, fmt.Errorf("get decompressed size: %w", diagnoseFrameError(src, err))
+		}
+		return 0, fmt.Errorf("decompress: %w", err)
+	}
+	if n < size {
+		return 0, fmt.Errorf("%w: need %d bytes", ErrBufferTooSmall, size)
 	}
+	d.lastIn, d.lastOut = len(src), n
+
+	return n, nil
+}
 
-	return dst[:n], nil
+// sizeGuess predicts the decompressed size of a frame of n bytes from the
+// previous operation, with some headroom. It returns 0 if there is no
+// previous operation.
+func (d *Decompressor) sizeGuess(n int) int {
+	if d.lastIn == 0 || d.lastOut == 0 {
+		return 0
+	}
+	guess := float64(n) * float64(d.lastOut) / float64(d.lastIn) * 1.25
+	if guess > MaxLongWindowSize {
+		return 0
+	}
+	return int(guess) + 64
 }
 
 // Close releases the underlying decompression context and frees associated memory.
@@ -134,6 +289,7 @@ func (d *Decompressor) Close() error {
 	defer d.mu.Unlock()
 
 	if d.ctx != nil {
+		d.cleanup.Stop()
 		d.ctx.Free()
 		d.ctx = nil
 	}
@@ -45,6 +45,13 @@
 // Compressor and Decompressor instances are safe for concurrent use by multiple goroutines.
 // Each instance uses a mutex to serialize access to the underlying C context.
 //
+// # Resource Management
+//
+// Compressors, Decompressors, Writers, and Readers hold native memory until
+// Close. If one is garbage collected without Close, its memory is released
+// anyway; SetCleanupPolicy decides whether that is ignored, logged, or turned
+// into a panic, for example to catch missing Close calls in tests.
+//
 // # Requirements
 //
 // This package requires CGO and links against the OpenZL C library. The library will be
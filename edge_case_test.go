@@ -5,6 +5,7 @@ package openzl
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"strings"
@@ -130,32 +131,32 @@ func TestCompress_UncompressibleData(t *testing.T) {
 		len(random), len(compressed), ratio)
 }
 
-// TestTypedCompression_TypeMismatch documents behavior when type mismatches occur
+// TestTypedCompression_TypeMismatch tests that typed data read as a type of
+// another width is rejected rather than reinterpreted
 func TestTypedCompression_TypeMismatch(t *testing.T) {
-	// Compress as int64
 	numbers := []int64{1, 2, 3, 4, 5}
 	compressed, err := CompressNumeric(numbers)
 	if err != nil {
 		t.Fatalf("CompressNumeric failed: %v", err)
 	}
 
-	// Try to decompress as int32 (wrong type)
-	// NOTE: This currently succeeds but gives wrong values
-	// OpenZL compressed data doesn't store type information
-	decompressed32, err := DecompressNumeric[int32](compressed)
+	if got, err := DecompressNumeric[int32](compressed); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("DecompressNumeric[int32](int64 data) = %v, %v, want ErrTypeMismatch", got, err)
+	}
+	decompressor, err := NewDecompressor()
 	if err != nil {
-		// If it errors, that's actually better (type safety)
-		t.Logf("Type mismatch detected (good): %v", err)
-		return
+		t.Fatal(err)
+	}
+	defer decompressor.Close()
+	if _, err := DecompressorDecompressNumeric[uint8](decompressor, compressed); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("DecompressorDecompressNumeric[uint8](int64 data) error = %v, want ErrTypeMismatch", err)
 	}
 
-	// If it succeeds, document the behavior
-	t.Logf("WARNING: Type mismatch NOT detected")
-	t.Logf("Original int64: %v", numbers)
-	t.Logf("Decompressed as int32: %v (first %d values)", decompressed32, min(5, len(decompressed32)))
-
-	// This is expected behavior - user must ensure type consistency
-	// Document this in godoc
+	// The matching type still round trips
+	got, err := DecompressNumeric[int64](compressed)
+	if err != nil || len(got) != len(numbers) {
+		t.Errorf("DecompressNumeric[int64] = %v, %v", got, err)
+	}
 }
 
 // TestTypedCompression_ZeroLengthArray tests empty array handling
@@ -345,6 +346,41 @@ func TestDecompress_ErrorMessages(t *testing.T) {
 	}
 }
 
+// TestDecompress_StreamInput tests that Writer streams passed to Decompress,
+// and bare frames passed to NewReader, produce errors naming the right API
+func TestDecompress_StreamInput(t *testing.T) {
+	original := []byte("framed or not framed")
+
+	var stream bytes.Buffer
+	writer, err := NewWriter(&stream)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	writer.Write(original)
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if _, err := Decompress(stream.Bytes()); !errors.Is(err, ErrStreamInput) {
+		t.Errorf("Decompress(stream): expected ErrStreamInput, got: %v", err)
+	}
+
+	frame, err := Compress(original)
+	if err != nil {
+		t.Fatalf("Compress failed: %v", err)
+	}
+
+	reader, err := NewReader(bytes.NewReader(frame))
+	if err != nil {
+		t.Fatalf("NewReader failed: %v", err)
+	}
+	defer reader.Close()
+
+	if _, err := io.ReadAll(reader); !errors.Is(err, ErrFrameInput) {
+		t.Errorf("NewReader(frame): expected ErrFrameInput, got: %v", err)
+	}
+}
+
 // TestConcurrency_Stress is a stress test for concurrent operations
 func TestConcurrency_Stress(t *testing.T) {
 	if testing.Short() {
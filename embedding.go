@@ -0,0 +1,153 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// embeddingShuffled marks embeddings stored as float byte planes.
+const embeddingShuffled = 1 << 0
+
+// EmbeddingOption configures CompressEmbeddings.
+type EmbeddingOption func(*embeddingConfig) error
+
+// embeddingConfig holds the configuration options for CompressEmbeddings.
+type embeddingConfig struct {
+	quantized bool // Split floats into byte planes (WithQuantizedEmbeddings)
+}
+
+// WithQuantizedEmbeddings optimizes for vectors that were quantized before
+// being stored as float32, for example decoded from float16, bfloat16, or
+// int8 with a scale factor.
+//
+// Such values use only a few significant bits, leaving the low mantissa
+// bytes of every float constant. The floats are split into byte planes so
+// those bytes form long runs that compress to almost nothing. For
+// full-precision embeddings, the default numeric graph usually does better.
+func WithQuantizedEmbeddings(enabled bool) EmbeddingOption {
+	return func(cfg *embeddingConfig) error {
+		cfg.quantized = enabled
+		return nil
+	}
+}
+
+// CompressEmbeddings compresses a batch of embedding vectors that all have
+// dims dimensions, such as a vector database snapshot.
+//
+// Values of the same dimension share a distribution across vectors, while
+// neighboring dimensions of one vector are unrelated. The vectors are
+// therefore reordered dimension by dimension before compression, so OpenZL
+// sees each dimension's values as one contiguous column.
+//
+// Returns ErrInvalidParameter if dims is not positive or any vector has a
+// different length.
+//
+// Example:
+//
+//	compressed, err := openzl.CompressEmbeddings(vectors, 768)
+//	...
+//	vectors, err := openzl.DecompressEmbeddings(compressed)
+func CompressEmbeddings(vectors [][]float32, dims int, opts ...EmbeddingOption) ([]byte, error) {
+	cfg := &embeddingConfig{}
+	for _, opt := range opts {
+		if err := opt(cfg); err != nil {
+			return nil, fmt.Errorf("apply option: %w", err)
+		}
+	}
+	if dims < 1 {
+		return nil, fmt.Errorf("%w: dimensions must be positive, got %d", ErrInvalidParameter, dims)
+	}
+	if len(vectors) == 0 {
+		return nil, ErrEmptyInput
+	}
+
+	n := len(vectors)
+	columns := make([]float32, n*dims)
+	for i, v := range vectors {
+		if len(v) != dims {
+			return nil, fmt.Errorf("%w: vector %d has %d dimensions, want %d", ErrInvalidParameter, i, len(v), dims)
+		}
+		for d, x := range v {
+			columns[d*n+i] = x
+		}
+	}
+
+	var flags byte
+	var frame []byte
+	var err error
+	if cfg.quantized {
+		flags |= embeddingShuffled
+		raw := make([]byte, 4*len(columns))
+		for i, x := range columns {
+			binary.LittleEndian.PutUint32(raw[4*i:], math.Float32bits(x))
+		}
+		frame, err = Compress(splitBytePlanes(raw, 4))
+	} else {
+		frame, err = CompressNumeric(columns)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	meta := binary.AppendUvarint(nil, uint64(dims))
+	meta = append(meta, flags)
+	return encodeContainer(containerEmbeddings, meta, frame), nil
+}
+
+// DecompressEmbeddings restores vectors compressed by CompressEmbeddings.
+func DecompressEmbeddings(compressed []byte) ([][]float32, error) {
+	if len(compressed) == 0 {
+		return nil, ErrEmptyInput
+	}
+	sections, err := decodeContainer(compressed, containerEmbeddings, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	dims, k := binary.Uvarint(sections[0])
+	if k <= 0 || len(sections[0]) != k+1 || dims == 0 {
+		return nil, fmt.Errorf("%w: invalid embedding metadata", ErrCorruptedData)
+	}
+	flags := sections[0][k]
+
+	var columns []float32
+	if flags&embeddingShuffled != 0 {
+		planes, err := Decompress(sections[1])
+		if err != nil {
+			return nil, err
+		}
+		if len(planes)%4 != 0 {
+			return nil, fmt.Errorf("%w: truncated embedding data", ErrCorruptedData)
+		}
+		raw := joinBytePlanes(planes, 4)
+		columns = make([]float32, len(raw)/4)
+		for i := range columns {
+			columns[i] = math.Float32frombits(binary.LittleEndian.Uint32(raw[4*i:]))
+		}
+	} else {
+		columns, err = DecompressNumeric[float32](sections[1])
+		if err != nil {
+			return nil, err
+		}
+	}
+	if uint64(len(columns))%dims != 0 {
+		return nil, fmt.Errorf("%w: %d values do not divide into %d dimensions", ErrCorruptedData, len(columns), dims)
+	}
+
+	d := int(dims)
+	n := len(columns) / d
+	values := make([]float32, len(columns))
+	vectors := make([][]float32, n)
+	for i := range vectors {
+		v := values[i*d : (i+1)*d : (i+1)*d]
+		for j := range v {
+			v[j] = columns[j*n+i]
+		}
+		vectors[i] = v
+	}
+	return vectors, nil
+}
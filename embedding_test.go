@@ -0,0 +1,48 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestCompressEmbeddings(t *testing.T) {
+	rng := rand.New(rand.NewPCG(5, 6))
+	const dims = 64
+	vectors := make([][]float32, 200)
+	for i := range vectors {
+		vectors[i] = make([]float32, dims)
+		for d := range vectors[i] {
+			// int8-quantized values with a per-model scale
+			vectors[i][d] = float32(rng.IntN(256)-128) / 64
+		}
+	}
+
+	for _, quantized := range []bool{false, true} {
+		compressed, err := CompressEmbeddings(vectors, dims, WithQuantizedEmbeddings(quantized))
+		if err != nil {
+			t.Fatalf("CompressEmbeddings(quantized=%v) failed: %v", quantized, err)
+		}
+		got, err := DecompressEmbeddings(compressed)
+		if err != nil {
+			t.Fatalf("DecompressEmbeddings(quantized=%v) failed: %v", quantized, err)
+		}
+		if len(got) != len(vectors) {
+			t.Fatalf("got %d vectors, want %d", len(got), len(vectors))
+		}
+		for i := range vectors {
+			if !slices.Equal(got[i], vectors[i]) {
+				t.Fatalf("quantized=%v: vector %d does not match", quantized, i)
+			}
+		}
+	}
+
+	vectors[3] = vectors[3][:dims-1]
+	if _, err := CompressEmbeddings(vectors, dims); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("CompressEmbeddings() with short vector error = %v, want ErrInvalidParameter", err)
+	}
+}
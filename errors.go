@@ -3,17 +3,24 @@
 
 package openzl
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/borischu/go-openzl/internal/cgo"
+	"github.com/borischu/go-openzl/zlapi"
+)
 
 var (
 	// ErrEmptyInput indicates that the input buffer is empty
-	ErrEmptyInput = errors.New("openzl: empty input")
+	ErrEmptyInput = zlapi.ErrEmptyInput
 
 	// ErrBufferTooSmall indicates that the destination buffer is too small
 	ErrBufferTooSmall = errors.New("openzl: buffer too small")
 
 	// ErrCorruptedData indicates that the compressed data is corrupted
-	ErrCorruptedData = errors.New("openzl: corrupted data")
+	ErrCorruptedData = zlapi.ErrCorruptedData
 
 	// ErrInvalidParameter indicates an invalid parameter was passed
 	ErrInvalidParameter = errors.New("openzl: invalid parameter")
@@ -23,4 +30,110 @@ var (
 
 	// ErrOutOfMemory indicates that memory allocation failed
 	ErrOutOfMemory = errors.New("openzl: out of memory")
+
+	// ErrChecksumMismatch indicates that a frame failed its CRC32C check
+	ErrChecksumMismatch = zlapi.ErrChecksumMismatch
+
+	// ErrStreamInput indicates that a Writer stream was passed to a one-shot
+	// decompression function; use NewReader to read streams
+	ErrStreamInput = errors.New("openzl: input is a Writer stream, use NewReader")
+
+	// ErrFrameInput indicates that a bare one-shot frame was passed to
+	// NewReader; use Decompress, or NewReader with WithBareFrames(true)
+	ErrFrameInput = errors.New("openzl: input is a bare frame, use Decompress")
+
+	// ErrPoolClosed indicates that work was submitted to a closed Pool
+	ErrPoolClosed = errors.New("openzl: pool closed")
+
+	// ErrWouldBlock indicates that a non-blocking write could not proceed
+	// without waiting for the underlying writer
+	ErrWouldBlock = errors.New("openzl: operation would block")
+
+	// ErrUnconsumedData indicates that a strict Reader was reset before its
+	// previous stream was read to the end or discarded
+	ErrUnconsumedData = errors.New("openzl: previous stream not fully consumed")
+
+	// ErrMemoryBudgetExceeded indicates that an operation would use more
+	// memory than allowed by WithMemoryBudget
+	ErrMemoryBudgetExceeded = errors.New("openzl: memory budget exceeded")
+
+	// ErrQuotaExceeded indicates that no native context could be allocated
+	// under a ContextQuota in QuotaFailFast mode
+	ErrQuotaExceeded = errors.New("openzl: context quota exceeded")
+
+	// ErrAlreadyInitialized indicates that Init was called after the
+	// package configuration was already fixed
+	ErrAlreadyInitialized = errors.New("openzl: already initialized")
+
+	// ErrOptionIgnored is wrapped by warnings about options that had no
+	// effect; see WithWarnHandler
+	ErrOptionIgnored = errors.New("openzl: option ignored")
+
+	// ErrShutdown indicates that a Pool was created after Shutdown
+	ErrShutdown = errors.New("openzl: package shut down")
+
+	// ErrUnknownCodec indicates that a FallbackCompressor frame or a
+	// ValueCodec value was written by a codec the reader does not know
+	ErrUnknownCodec = errors.New("openzl: unknown codec")
+
+	// ErrTypeMismatch indicates that typed data was decompressed as a type
+	// other than the one it was compressed from, such as int64 values read
+	// with DecompressNumeric[int32]
+	ErrTypeMismatch = errors.New("openzl: element type mismatch")
+
+	// ErrTooLarge indicates that a size does not fit in an int, such as that
+	// of a frame of 2GB or more decompressed on a 32-bit platform; see
+	// DecompressedSize
+	ErrTooLarge = cgo.ErrTooLarge
+
+	// ErrInputTooLarge indicates that an input exceeds MaxInputSize, the
+	// largest content of a single frame; it is returned before any native
+	// call
+	ErrInputTooLarge = cgo.ErrInputTooLarge
 )
+
+// OptionError reports an option given a value it does not accept. Every
+// option constructor of the package fails with an OptionError, so that
+// services configured from files or flags can tell users which setting is
+// wrong and what it may be.
+//
+// For options that take a number, Min and Max hold the inclusive range of
+// accepted values, with Max set to math.MaxInt64 if there is no upper
+// bound. For other options both are zero. Allowed always describes the
+// accepted values in words.
+//
+// OptionError wraps ErrInvalidParameter.
+//
+// Example:
+//
+//	_, err := openzl.NewWriter(w, openzl.WithFrameSize(cfg.FrameSize))
+//	var oe *openzl.OptionError
+//	if errors.As(err, &oe) {
+//		return fmt.Errorf("frame_size: got %v, want %s", oe.Value, oe.Allowed)
+//	}
+type OptionError struct {
+	Option  string // Option constructor, such as "WithFrameSize"
+	Value   any    // Rejected value
+	Min     int64  // Smallest accepted value of a numeric option
+	Max     int64  // Largest accepted value of a numeric option
+	Allowed string // Accepted values, such as "between 4096 and 1048576"
+}
+
+func (e *OptionError) Error() string {
+	return fmt.Sprintf("openzl: %s(%v): must be %s", e.Option, e.Value, e.Allowed)
+}
+
+// Unwrap returns ErrInvalidParameter.
+func (e *OptionError) Unwrap() error {
+	return ErrInvalidParameter
+}
+
+// rangeError returns an OptionError for a numeric option that accepts values
+// from min to max inclusive; max is math.MaxInt64 for no upper bound.
+func rangeError[T ~int | ~int64](option string, value T, min, max int64) *OptionError {
+	allowed := fmt.Sprintf("between %d and %d", min, max)
+	if max == math.MaxInt64 {
+		allowed = fmt.Sprintf("at least %d", min)
+	}
+	return &OptionError{Option: option, Value: value, Min: min, Max: max, Allowed: allowed}
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"errors"
+	"io"
+	"math"
+	"testing"
+)
+
+func TestOptionError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		option   string
+		min, max int64
+	}{
+		{"frame size", newWriterErr(WithFrameSize(100)), "WithFrameSize", MinFrameSize, MaxFrameSize},
+		{"long window", newWriterErr(WithLongWindow(1)), "WithLongWindow", MaxFrameSize, MaxLongWindowSize},
+		{"cdc", newWriterErr(WithContentDefinedChunking(3000)), "WithContentDefinedChunking", 1024, MaxFrameSize / 2},
+		{"in flight", newWriterErr(WithMaxInFlight(0)), "WithMaxInFlight", 1, math.MaxInt64},
+		{"empty policy", newWriterErr(WithEmptyPolicy(9)), "WithEmptyPolicy", 0, 0},
+		{"workers", poolErr(WithWorkers(0)), "WithWorkers", 1, math.MaxInt64},
+		{"budget", decompressorErr(WithMemoryBudget(-1)), "WithMemoryBudget", 1, math.MaxInt64},
+		{"level", WithDefaultLevel(12)(&globalConfig{}), "WithDefaultLevel", 0, 9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var oe *OptionError
+			if !errors.As(tt.err, &oe) {
+				t.Fatalf("error %v is not an *OptionError", tt.err)
+			}
+			if oe.Option != tt.option || oe.Min != tt.min || oe.Max != tt.max || oe.Allowed == "" {
+				t.Errorf("got %+v, want option %s in [%d, %d]", oe, tt.option, tt.min, tt.max)
+			}
+			if !errors.Is(tt.err, ErrInvalidParameter) {
+				t.Errorf("error %v does not match ErrInvalidParameter", tt.err)
+			}
+		})
+	}
+
+	err := &OptionError{Option: "WithFrameSize", Value: 100, Min: MinFrameSize, Max: MaxFrameSize, Allowed: "between 4096 and 1048576"}
+	if got, want := err.Error(), "openzl: WithFrameSize(100): must be between 4096 and 1048576"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func newWriterErr(opt WriterOption) error {
+	_, err := NewWriter(io.Discard, opt)
+	return err
+}
+
+func poolErr(opt PoolOption) error {
+	_, err := NewPool(opt)
+	return err
+}
+
+func decompressorErr(opt DecompressorOption) error {
+	_, err := NewDecompressor(opt)
+	return err
+}
@@ -0,0 +1,29 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import "time"
+
+//go:generate go run github.com/borischu/go-openzl/cmd/zlgo gen -type Event
+
+// Kind classifies an event.
+type Kind uint8
+
+const (
+	KindView Kind = iota
+	KindClick
+	KindPurchase
+)
+
+// Event is one row of a clickstream log.
+type Event struct {
+	Timestamp int64
+	UserID    uint64 `openzl:"width=4"` // Values fit in 32 bits
+	Kind      Kind
+	Page      string
+	Latency   time.Duration
+	Score     float32
+	Bot       bool
+	Raw       []byte `openzl:"skip"` // Kept in memory only
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"math/rand"
+	"time"
+
+	"github.com/borischu/go-openzl"
+)
+
+func main() {
+	fmt.Println("OpenZL Record Compression Example")
+	fmt.Println("=================================")
+	fmt.Println()
+
+	rng := rand.New(rand.NewSource(1))
+	pages := []string{"/", "/search", "/item", "/cart", "/checkout"}
+	events := make([]Event, 10000)
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+	for i := range events {
+		events[i] = Event{
+			Timestamp: start + int64(i)*250 + rng.Int63n(50),
+			UserID:    uint64(rng.Intn(500)),
+			Kind:      Kind(rng.Intn(3)),
+			Page:      pages[rng.Intn(len(pages))],
+			Latency:   time.Duration(rng.Intn(200)) * time.Millisecond,
+			Score:     float32(rng.Intn(100)) / 10,
+			Bot:       rng.Intn(50) == 0,
+		}
+	}
+
+	// The generated codec avoids reflection
+	compressed, err := CompressEventRecords(events)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Compressed %d events to %d bytes\n", len(events), len(compressed))
+
+	// Both paths produce the same format
+	reflected, err := openzl.CompressRecords(events)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Same as CompressRecords: %v\n", bytes.Equal(compressed, reflected))
+
+	restored, err := DecompressEventRecords(compressed)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Restored %d events, first: %+v\n", len(restored), restored[0])
+}
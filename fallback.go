@@ -0,0 +1,266 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Codec is a one-shot compression codec used by FallbackCompressor.
+//
+// ID tags every frame the codec produces, so that frames can be decompressed
+// without knowing which codec wrote them. IDs must be stable for as long as
+// frames are kept. IDs 0 to 15 are reserved for the codecs of this package;
+// application codecs, such as a zstd wrapper, use 16 and above.
+//
+// Compress and Decompress must be safe for concurrent use.
+type Codec struct {
+	ID         byte
+	Name       string
+	Compress   func(src []byte) ([]byte, error)
+	Decompress func(src []byte) ([]byte, error)
+}
+
+// minAppCodecID is the lowest Codec ID available to applications.
+const minAppCodecID = 16
+
+var (
+	// StoredCodec stores data uncompressed. It never fails and expands data
+	// only by the frame tag, which makes it the secondary of last resort.
+	StoredCodec = Codec{
+		ID:         0,
+		Name:       "stored",
+		Compress:   func(src []byte) ([]byte, error) { return src, nil },
+		Decompress: func(src []byte) ([]byte, error) { return slices.Clone(src), nil },
+	}
+
+	// OpenZLCodec compresses with Compress and Decompress.
+	OpenZLCodec = Codec{
+		ID:         1,
+		Name:       "openzl",
+		Compress:   Compress,
+		Decompress: Decompress,
+	}
+)
+
+// FallbackPolicy decides, after the primary codec has run, whether a
+// FallbackCompressor should use the secondary codec instead. It receives the
+// input, the primary's output, and the primary's error; compressed is nil if
+// err is not.
+type FallbackPolicy func(src, compressed []byte, err error) bool
+
+// FallbackOnErrorOrExpansion is the default FallbackPolicy. It falls back if
+// the primary codec fails or does not make the data smaller.
+func FallbackOnErrorOrExpansion(src, compressed []byte, err error) bool {
+	return err != nil || len(compressed) >= len(src)
+}
+
+// FallbackOption configures a FallbackCompressor.
+type FallbackOption func(*FallbackCompressor) error
+
+// WithFallbackPolicy sets the policy that decides when to fall back to the
+// secondary codec. The policy is also the place for rollout hooks, such as
+// counting fallbacks or logging primary errors.
+//
+// Example:
+//
+//	var fallbacks atomic.Int64
+//	openzl.WithFallbackPolicy(func(src, compressed []byte, err error) bool {
+//		fallback := openzl.FallbackOnErrorOrExpansion(src, compressed, err)
+//		if fallback {
+//			fallbacks.Add(1)
+//		}
+//		return fallback
+//	})
+func WithFallbackPolicy(policy FallbackPolicy) FallbackOption {
+	return func(f *FallbackCompressor) error {
+		if policy == nil {
+			return &OptionError{Option: "WithFallbackPolicy", Value: policy, Allowed: "non-nil"}
+		}
+		f.policy = policy
+		return nil
+	}
+}
+
+// WithFallbackDecoders adds codecs that Decompress recognizes besides the
+// primary and secondary, such as the codec a deployment used before it
+// switched to OpenZL. The codecs are never used for compression.
+func WithFallbackDecoders(codecs ...Codec) FallbackOption {
+	return func(f *FallbackCompressor) error {
+		for _, c := range codecs {
+			if err := f.addDecoder(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// FallbackCompressor compresses with a primary codec and falls back to a
+// secondary codec when a policy says so, for example to roll out OpenZL with
+// zstd as a safety net.
+//
+// Every frame is tagged with the ID of the codec that wrote it, and
+// Decompress dispatches on that tag. Frames therefore stay readable when
+// the primary and secondary are later swapped or replaced, as long as the
+// old codecs remain known to the decompressing side, either as primary,
+// secondary, or through WithFallbackDecoders. StoredCodec and OpenZLCodec
+// are always known.
+//
+// Frames are a go-openzl container rather than a plain OpenZL frame, so
+// they can only be read by a FallbackCompressor. A FallbackCompressor holds
+// no native resources and is safe for concurrent use.
+type FallbackCompressor struct {
+	primary   Codec
+	secondary Codec
+	policy    FallbackPolicy
+	decoders  map[byte]Codec
+}
+
+// NewFallbackCompressor creates a FallbackCompressor that compresses with
+// primary and falls back to secondary. Without WithFallbackPolicy, it falls
+// back if primary fails or expands the data.
+//
+// Example:
+//
+//	zstdCodec := openzl.Codec{
+//		ID:   16,
+//		Name: "zstd",
+//		Compress: func(src []byte) ([]byte, error) {
+//			return encoder.EncodeAll(src, nil), nil
+//		},
+//		Decompress: func(src []byte) ([]byte, error) {
+//			return decoder.DecodeAll(src, nil)
+//		},
+//	}
+//	fc, err := openzl.NewFallbackCompressor(openzl.OpenZLCodec, zstdCodec)
+//	...
+//	frame, err := fc.Compress(data)
+//	...
+//	data, err = fc.Decompress(frame)
+//
+// Returns ErrInvalidParameter if a codec lacks a function, uses an ID
+// reserved for this package, or shares its ID with another, or an error if
+// an option is invalid.
+func NewFallbackCompressor(primary, secondary Codec, opts ...FallbackOption) (*FallbackCompressor, error) {
+	f := &FallbackCompressor{
+		primary:   primary,
+		secondary: secondary,
+		policy:    FallbackOnErrorOrExpansion,
+		decoders:  make(map[byte]Codec),
+	}
+	if err := f.addDecoder(primary); err != nil {
+		return nil, fmt.Errorf("primary: %w", err)
+	}
+	if err := f.addDecoder(secondary); err != nil {
+		return nil, fmt.Errorf("secondary: %w", err)
+	}
+	for _, opt := range opts {
+		if err := opt(f); err != nil {
+			return nil, fmt.Errorf("apply option: %w", err)
+		}
+	}
+	for _, c := range []Codec{StoredCodec, OpenZLCodec} {
+		if _, ok := f.decoders[c.ID]; !ok {
+			f.decoders[c.ID] = c
+		}
+	}
+	return f, nil
+}
+
+// addDecoder registers c for Decompress.
+func (f *FallbackCompressor) addDecoder(c Codec) error {
+	if c.Compress == nil || c.Decompress == nil {
+		return fmt.Errorf("%w: codec %q lacks Compress or Decompress", ErrInvalidParameter, c.Name)
+	}
+	if c.ID < minAppCodecID && !isBuiltinCodec(c) {
+		return fmt.Errorf("%w: codec %q uses reserved ID %d, application codecs start at %d",
+			ErrInvalidParameter, c.Name, c.ID, minAppCodecID)
+	}
+	if old, ok := f.decoders[c.ID]; ok {
+		return fmt.Errorf("%w: codecs %q and %q share ID %d", ErrInvalidParameter, old.Name, c.Name, c.ID)
+	}
+	f.decoders[c.ID] = c
+	return nil
+}
+
+// isBuiltinCodec reports whether c is one of the codecs of this package,
+// which alone may use the reserved IDs.
+func isBuiltinCodec(c Codec) bool {
+	for _, b := range []Codec{StoredCodec, OpenZLCodec} {
+		if c.ID == b.ID && c.Name == b.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// Compress compresses src with the primary codec, or with the secondary
+// codec if the policy rejects the primary's result, and tags the frame with
+// the codec used.
+//
+// Returns ErrEmptyInput if src is empty, or the secondary's error if the
+// secondary codec fails.
+func (f *FallbackCompressor) Compress(src []byte) ([]byte, error) {
+	if len(src) == 0 {
+		return nil, ErrEmptyInput
+	}
+	codec := f.primary
+	compressed, err := f.primary.Compress(src)
+	if err != nil {
+		compressed = nil
+	}
+	if f.policy(src, compressed, err) {
+		codec = f.secondary
+		if compressed, err = f.secondary.Compress(src); err != nil {
+			return nil, fmt.Errorf("%s: %w", f.secondary.Name, err)
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("%s: %w", f.primary.Name, err)
+	}
+	return encodeContainer(containerFallback, []byte{codec.ID}, compressed), nil
+}
+
+// Decompress decompresses a frame written by Compress with any known codec.
+//
+// Returns ErrUnknownCodec if the frame was written by a codec this
+// FallbackCompressor does not know.
+func (f *FallbackCompressor) Decompress(frame []byte) ([]byte, error) {
+	if len(frame) == 0 {
+		return nil, ErrEmptyInput
+	}
+	id, payload, err := splitFallbackFrame(frame)
+	if err != nil {
+		return nil, err
+	}
+	codec, ok := f.decoders[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: ID %d", ErrUnknownCodec, id)
+	}
+	out, err := codec.Decompress(payload)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", codec.Name, err)
+	}
+	return out, nil
+}
+
+// FrameCodec returns the ID of the codec that wrote a frame of
+// FallbackCompressor.Compress, without decompressing it.
+func FrameCodec(frame []byte) (byte, error) {
+	id, _, err := splitFallbackFrame(frame)
+	return id, err
+}
+
+// splitFallbackFrame returns the codec ID and payload of a frame.
+func splitFallbackFrame(frame []byte) (byte, []byte, error) {
+	sections, err := decodeContainer(frame, containerFallback, 2)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(sections[0]) != 1 {
+		return 0, nil, fmt.Errorf("%w: invalid codec tag", ErrCorruptedData)
+	}
+	return sections[0][0], sections[1], nil
+}
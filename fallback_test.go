@@ -0,0 +1,102 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// reverseCodec is an application codec that is easy to recognize in tests.
+var reverseCodec = Codec{
+	ID:   16,
+	Name: "reverse",
+	Compress: func(src []byte) ([]byte, error) {
+		out := bytes.Clone(src)
+		for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+			out[i], out[j] = out[j], out[i]
+		}
+		return out, nil
+	},
+}
+
+func init() {
+	reverseCodec.Decompress = reverseCodec.Compress
+}
+
+func TestFallbackCompressor(t *testing.T) {
+	failing := Codec{
+		ID:         17,
+		Name:       "failing",
+		Compress:   func([]byte) ([]byte, error) { return nil, errors.New("boom") },
+		Decompress: func([]byte) ([]byte, error) { return nil, errors.New("boom") },
+	}
+	compressible := bytes.Repeat([]byte("fallback "), 1000)
+	onError := WithFallbackPolicy(func(_, _ []byte, err error) bool { return err != nil })
+
+	tests := []struct {
+		name      string
+		primary   Codec
+		secondary Codec
+		opts      []FallbackOption
+		want      byte
+	}{
+		{"primary", OpenZLCodec, reverseCodec, []FallbackOption{onError}, OpenZLCodec.ID},
+		{"primary error", failing, reverseCodec, nil, reverseCodec.ID},
+		{"expansion", reverseCodec, StoredCodec, nil, StoredCodec.ID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fc, err := NewFallbackCompressor(tt.primary, tt.secondary, tt.opts...)
+			if err != nil {
+				t.Fatalf("NewFallbackCompressor failed: %v", err)
+			}
+			frame, err := fc.Compress(compressible)
+			if err != nil {
+				t.Fatalf("Compress failed: %v", err)
+			}
+			if id, err := FrameCodec(frame); err != nil || id != tt.want {
+				t.Errorf("FrameCodec = %d, %v, want %d", id, err, tt.want)
+			}
+			got, err := fc.Decompress(frame)
+			if err != nil {
+				t.Fatalf("Decompress failed: %v", err)
+			}
+			if !bytes.Equal(got, compressible) {
+				t.Error("round trip mismatch")
+			}
+		})
+	}
+
+	// A deployment that rolled back still reads frames of the old primary
+	fc, _ := NewFallbackCompressor(reverseCodec, StoredCodec, WithFallbackPolicy(func([]byte, []byte, error) bool { return false }))
+	frame, _ := fc.Compress([]byte("abc"))
+	rolledBack, _ := NewFallbackCompressor(OpenZLCodec, StoredCodec)
+	if _, err := rolledBack.Decompress(frame); !errors.Is(err, ErrUnknownCodec) {
+		t.Errorf("Decompress error = %v, want ErrUnknownCodec", err)
+	}
+	withOld, _ := NewFallbackCompressor(OpenZLCodec, StoredCodec, WithFallbackDecoders(reverseCodec))
+	if got, err := withOld.Decompress(frame); err != nil || string(got) != "abc" {
+		t.Errorf("Decompress = %q, %v, want abc", got, err)
+	}
+
+	if _, err := NewFallbackCompressor(OpenZLCodec, OpenZLCodec); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("duplicate IDs: error = %v, want ErrInvalidParameter", err)
+	}
+	if _, err := NewFallbackCompressor(failing, failing); err == nil {
+		t.Error("accepted codecs sharing an ID")
+	}
+	reserved := reverseCodec
+	reserved.ID = 2
+	if _, err := NewFallbackCompressor(OpenZLCodec, reserved); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("reserved ID: error = %v, want ErrInvalidParameter", err)
+	}
+	if _, err := NewFallbackCompressor(OpenZLCodec, StoredCodec, WithFallbackDecoders(reserved)); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("reserved decoder ID: error = %v, want ErrInvalidParameter", err)
+	}
+	if _, err := fc.Decompress([]byte("not a frame")); !errors.Is(err, ErrCorruptedData) {
+		t.Errorf("garbage: error = %v, want ErrCorruptedData", err)
+	}
+}
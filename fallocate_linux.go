@@ -0,0 +1,20 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"os"
+	"syscall"
+)
+
+// reserveFile extends f to size bytes and allocates its blocks, so that
+// writing through a mapping of it cannot run out of disk space.
+func reserveFile(f *os.File, size int64) error {
+	for {
+		err := syscall.Fallocate(int(f.Fd()), 0, 0, size)
+		if err != syscall.EINTR {
+			return os.NewSyscallError("fallocate", err)
+		}
+	}
+}
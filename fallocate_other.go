@@ -0,0 +1,17 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+//go:build !linux
+
+package openzl
+
+import (
+	"errors"
+	"os"
+)
+
+// reserveFile reports that disk space cannot be reserved, so that
+// DecompressToFile writes through the file instead of mapping it.
+func reserveFile(f *os.File, size int64) error {
+	return errors.ErrUnsupported
+}
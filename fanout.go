@@ -0,0 +1,153 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// FanOut is an io.Writer that duplicates its writes to several destinations,
+// isolating their failures.
+//
+// Passing a FanOut to NewWriter compresses the data once and writes the same
+// frames everywhere, for example to local disk and to the network. Unlike
+// io.MultiWriter, a failing destination does not stop the others: it is
+// dropped, its error is recorded, and writing continues to the remaining
+// destinations. Write only fails once every destination has failed. After
+// closing the Writer, Err reports which destinations did not receive the
+// complete stream.
+//
+// Example:
+//
+//	fan := openzl.NewFanOut(file, conn)
+//	writer, _ := openzl.NewWriter(fan)
+//	io.Copy(writer, src)
+//	if err := writer.Close(); err != nil {
+//		return err // every destination failed
+//	}
+//	if err := fan.Err(); err != nil {
+//		log.Printf("partial failure: %v", err) // some destinations failed
+//	}
+type FanOut struct {
+	mu   sync.Mutex
+	dsts []io.Writer
+	errs []error // Per destination, nil while healthy
+}
+
+// NewFanOut returns a FanOut writing to dsts.
+func NewFanOut(dsts ...io.Writer) *FanOut {
+	return &FanOut{
+		dsts: dsts,
+		errs: make([]error, len(dsts)),
+	}
+}
+
+// Write writes p to every healthy destination. A destination that fails or
+// accepts fewer than len(p) bytes is marked as failed and skipped from then
+// on.
+//
+// Write returns len(p) if at least one destination accepted all of p, and a
+// *FanOutError otherwise.
+func (f *FanOut) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.dsts) == 0 {
+		return 0, fmt.Errorf("%w: fan-out without destinations", ErrInvalidParameter)
+	}
+	ok := false
+	for i, dst := range f.dsts {
+		if f.errs[i] != nil {
+			continue
+		}
+		n, err := dst.Write(p)
+		if err == nil && n < len(p) {
+			err = io.ErrShortWrite
+		}
+		if err != nil {
+			f.errs[i] = err
+			continue
+		}
+		ok = true
+	}
+	if !ok {
+		return 0, f.errLocked()
+	}
+	return len(p), nil
+}
+
+// Healthy returns the number of destinations that have not failed.
+func (f *FanOut) Healthy() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n := 0
+	for _, err := range f.errs {
+		if err == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// Err returns nil if every destination received all writes so far, and a
+// *FanOutError describing the failed destinations otherwise.
+func (f *FanOut) Err() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.errLocked()
+}
+
+func (f *FanOut) errLocked() error {
+	failed := make(map[int]error)
+	for i, err := range f.errs {
+		if err != nil {
+			failed[i] = err
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return &FanOutError{Failed: failed, Total: len(f.dsts)}
+}
+
+// FanOutError reports the destinations of a FanOut that failed.
+type FanOutError struct {
+	Failed map[int]error // Error of each failed destination, by index
+	Total  int           // Number of destinations
+}
+
+func (e *FanOutError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "openzl: %d of %d destinations failed", len(e.Failed), e.Total)
+	for i := range e.Total {
+		if err, ok := e.Failed[i]; ok {
+			fmt.Fprintf(&b, "; destination %d: %v", i, err)
+		}
+	}
+	return b.String()
+}
+
+// Unwrap returns the errors of the failed destinations, so that errors.Is
+// and errors.As match any of them.
+func (e *FanOutError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failed))
+	for i := range e.Total {
+		if err, ok := e.Failed[i]; ok {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// AllFailed reports whether every destination failed.
+func (e *FanOutError) AllFailed() bool {
+	return len(e.Failed) == e.Total
+}
+
+// Ensure FanOut implements io.Writer
+var _ io.Writer = (*FanOut)(nil)
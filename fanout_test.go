@@ -0,0 +1,64 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestFanOut(t *testing.T) {
+	data := bytes.Repeat([]byte("compress once, write everywhere "), 20000)
+
+	var disk, network bytes.Buffer
+	flaky := &failingWriter{failAfter: 100}
+	fan := NewFanOut(&disk, flaky, &network)
+
+	writer, err := NewWriter(fan, WithFrameSize(MinFrameSize))
+	if err != nil {
+		t.Fatalf("NewWriter() failed: %v", err)
+	}
+	if _, err := io.Copy(writer, bytes.NewReader(data)); err != nil {
+		t.Fatalf("Copy() failed: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	if !bytes.Equal(disk.Bytes(), network.Bytes()) {
+		t.Error("healthy destinations received different streams")
+	}
+	var out bytes.Buffer
+	if _, err := DecompressTo(&out, disk.Bytes()); err != nil || !bytes.Equal(out.Bytes(), data) {
+		t.Errorf("healthy destination does not hold the stream: %v", err)
+	}
+
+	if got := fan.Healthy(); got != 2 {
+		t.Errorf("Healthy() = %d, want 2", got)
+	}
+	var fe *FanOutError
+	if !errors.As(fan.Err(), &fe) {
+		t.Fatalf("Err() = %v, want *FanOutError", fan.Err())
+	}
+	if _, ok := fe.Failed[1]; !ok || len(fe.Failed) != 1 || fe.AllFailed() {
+		t.Errorf("Err() reports failed destinations %v, want only 1", fe.Failed)
+	}
+}
+
+func TestFanOut_AllFailed(t *testing.T) {
+	fan := NewFanOut(&failingWriter{}, &failingWriter{})
+	if _, err := fan.Write([]byte("nowhere")); err == nil {
+		t.Fatal("Write() succeeded with every destination failing")
+	}
+	var fe *FanOutError
+	if !errors.As(fan.Err(), &fe) || !fe.AllFailed() {
+		t.Errorf("Err() = %v, want all destinations failed", fan.Err())
+	}
+
+	if _, err := NewFanOut().Write([]byte("x")); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("Write() without destinations error = %v, want ErrInvalidParameter", err)
+	}
+}
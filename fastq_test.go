@@ -0,0 +1,59 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestCompressReads(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 4))
+	reads := make([]SequenceRead, 300)
+	for i := range reads {
+		n := 50 + rng.IntN(100)
+		if i == 7 {
+			n = 0 // empty reads are valid
+		}
+		r := SequenceRead{
+			ID:      fmt.Sprintf("SRR000001.%d length=%d", i, n),
+			Bases:   make([]byte, n),
+			Quality: make([]byte, n),
+		}
+		for j := 0; j < n; j++ {
+			r.Bases[j] = "ACGT"[rng.IntN(4)]
+			if rng.IntN(50) == 0 {
+				r.Bases[j] = 'N'
+			}
+			r.Quality[j] = byte('!' + 30 + rng.IntN(12))
+		}
+		reads[i] = r
+	}
+
+	compressed, err := CompressReads(reads)
+	if err != nil {
+		t.Fatalf("CompressReads() failed: %v", err)
+	}
+	got, err := DecompressReads(compressed)
+	if err != nil {
+		t.Fatalf("DecompressReads() failed: %v", err)
+	}
+	if len(got) != len(reads) {
+		t.Fatalf("got %d reads, want %d", len(got), len(reads))
+	}
+	for i := range reads {
+		if got[i].ID != reads[i].ID || !bytes.Equal(got[i].Bases, reads[i].Bases) ||
+			!bytes.Equal(got[i].Quality, reads[i].Quality) {
+			t.Fatalf("read %d does not match", i)
+		}
+	}
+
+	bad := []SequenceRead{{ID: "r", Bases: []byte("ACGT"), Quality: []byte("II")}}
+	if _, err := CompressReads(bad); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("CompressReads() with short quality error = %v, want ErrInvalidParameter", err)
+	}
+}
@@ -0,0 +1,26 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+//go:build !openzl_faults
+
+package faultinject_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/borischu/go-openzl"
+	"github.com/borischu/go-openzl/faultinject"
+)
+
+func TestDisabled(t *testing.T) {
+	if faultinject.Enabled {
+		t.Fatal("Enabled without the openzl_faults tag")
+	}
+	if err := faultinject.Inject(faultinject.Compress, faultinject.Fault{}); !errors.Is(err, faultinject.ErrNotEnabled) {
+		t.Errorf("Inject() = %v, want ErrNotEnabled", err)
+	}
+	if _, err := openzl.Compress([]byte("hello")); err != nil {
+		t.Errorf("Compress() = %v", err)
+	}
+}
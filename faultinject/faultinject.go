@@ -0,0 +1,109 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Package faultinject simulates go-openzl library failures so that
+// applications can test their error handling against realistic faults: C API
+// calls that fail, allocations that run out of memory, and streams that
+// return short reads or errors.
+//
+// Faults only take effect in binaries built with the openzl_faults tag:
+//
+//	go test -tags openzl_faults ./...
+//
+// Without the tag, the injection points are compiled out of the library,
+// Enabled is false, and Inject returns ErrNotEnabled.
+//
+// Faults are global to the process, so tests that inject them must not run
+// in parallel with other tests that use go-openzl.
+//
+// Example:
+//
+//	func TestUploadRetriesCompression(t *testing.T) {
+//		if !faultinject.Enabled {
+//			t.Skip("build with -tags openzl_faults")
+//		}
+//		defer faultinject.Reset()
+//		faultinject.Inject(faultinject.Compress, faultinject.Fault{Count: 1})
+//
+//		// The first compression fails, the retry succeeds
+//		if err := upload(data); err != nil {
+//			t.Fatal(err)
+//		}
+//	}
+package faultinject
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/borischu/go-openzl"
+	"github.com/borischu/go-openzl/internal/fault"
+)
+
+// Enabled reports whether the library was built with the openzl_faults tag.
+const Enabled = fault.Enabled
+
+// Point identifies a place where faults can be injected.
+type Point = fault.Point
+
+const (
+	// Compress fails C compression calls, as used by Compress, Compressor,
+	// Writer, and the typed and domain helpers.
+	Compress = fault.Compress
+
+	// Decompress fails C decompression calls.
+	Decompress = fault.Decompress
+
+	// Alloc fails the creation of native contexts and buffers. Its default
+	// error matches openzl.ErrOutOfMemory.
+	Alloc = fault.Alloc
+
+	// Read affects reads from the underlying streams of openzl.Reader and
+	// openzl.RecordReader, with errors or, with Fault.ShortRead, short reads.
+	Read = fault.Read
+)
+
+// Fault describes the failures injected at a point.
+type Fault = fault.Fault
+
+// ErrInjected is matched by the errors of injected faults that do not set
+// Fault.Err.
+var ErrInjected = fault.ErrInjected
+
+// ErrNotEnabled is returned by Inject in builds without the openzl_faults tag.
+var ErrNotEnabled = errors.New("faultinject: fault injection requires the openzl_faults build tag")
+
+// Inject installs f at p, replacing any fault there, and resets the count of
+// fired faults at p.
+func Inject(p Point, f Fault) error {
+	if !Enabled {
+		return ErrNotEnabled
+	}
+	switch p {
+	case Compress, Decompress, Alloc, Read:
+	default:
+		return fmt.Errorf("%w: unknown fault point %q", openzl.ErrInvalidParameter, p)
+	}
+	if f.Skip < 0 || f.Count < 0 || f.ShortRead < 0 {
+		return fmt.Errorf("%w: negative fault parameter", openzl.ErrInvalidParameter)
+	}
+	if f.ShortRead > 0 && p != Read {
+		return fmt.Errorf("%w: short reads apply only to the Read point", openzl.ErrInvalidParameter)
+	}
+	if f.Err == nil && p == Alloc {
+		f.Err = fmt.Errorf("%w: %w", openzl.ErrOutOfMemory, ErrInjected)
+	}
+	fault.Set(p, f)
+	return nil
+}
+
+// Fired returns the number of calls at p that failed since its fault was
+// injected.
+func Fired(p Point) int {
+	return fault.Fired(p)
+}
+
+// Reset removes all faults.
+func Reset() {
+	fault.Clear()
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+//go:build openzl_faults
+
+package faultinject_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/borischu/go-openzl"
+	"github.com/borischu/go-openzl/faultinject"
+)
+
+func inject(t *testing.T, p faultinject.Point, f faultinject.Fault) {
+	t.Helper()
+	if err := faultinject.Inject(p, f); err != nil {
+		t.Fatalf("Inject(%s) failed: %v", p, err)
+	}
+	t.Cleanup(faultinject.Reset)
+}
+
+func TestCompressFault(t *testing.T) {
+	inject(t, faultinject.Compress, faultinject.Fault{Count: 1})
+
+	data := []byte("hello hello hello hello")
+	if _, err := openzl.Compress(data); !errors.Is(err, faultinject.ErrInjected) {
+		t.Errorf("Compress() = %v, want ErrInjected", err)
+	}
+	if _, err := openzl.Compress(data); err != nil {
+		t.Errorf("Compress() after the fault = %v", err)
+	}
+	if n := faultinject.Fired(faultinject.Compress); n != 1 {
+		t.Errorf("Fired() = %d, want 1", n)
+	}
+}
+
+func TestDecompressFault(t *testing.T) {
+	compressed, err := openzl.Compress([]byte("hello hello hello hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	errCustom := errors.New("custom")
+	inject(t, faultinject.Decompress, faultinject.Fault{Skip: 1, Err: errCustom})
+
+	if _, err := openzl.Decompress(compressed); err != nil {
+		t.Errorf("Decompress() before the fault = %v", err)
+	}
+	for range 2 {
+		if _, err := openzl.Decompress(compressed); !errors.Is(err, errCustom) {
+			t.Errorf("Decompress() = %v, want the injected error", err)
+		}
+	}
+}
+
+func TestAllocFault(t *testing.T) {
+	inject(t, faultinject.Alloc, faultinject.Fault{})
+
+	_, err := openzl.NewCompressor()
+	if !errors.Is(err, openzl.ErrOutOfMemory) || !errors.Is(err, faultinject.ErrInjected) {
+		t.Errorf("NewCompressor() = %v, want ErrOutOfMemory and ErrInjected", err)
+	}
+	if _, err := openzl.NewDecompressor(); !errors.Is(err, openzl.ErrOutOfMemory) {
+		t.Errorf("NewDecompressor() = %v, want ErrOutOfMemory", err)
+	}
+}
+
+func TestReadFaults(t *testing.T) {
+	data := bytes.Repeat([]byte("stream data "), 10000)
+	stream, err := openzl.CompressFrom(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Short reads must not change the result
+	inject(t, faultinject.Read, faultinject.Fault{ShortRead: 3})
+	r, err := openzl.NewReader(bytes.NewReader(stream))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil || !bytes.Equal(got, data) {
+		t.Errorf("ReadAll() with short reads = %d bytes, %v; want %d bytes", len(got), err, len(data))
+	}
+
+	// Read errors surface to the caller
+	inject(t, faultinject.Read, faultinject.Fault{Skip: 1})
+	r, err = openzl.NewReader(bytes.NewReader(stream))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadAll(r); !errors.Is(err, faultinject.ErrInjected) {
+		t.Errorf("ReadAll() = %v, want ErrInjected", err)
+	}
+}
+
+func TestInject_Invalid(t *testing.T) {
+	defer faultinject.Reset()
+	for name, err := range map[string]error{
+		"unknown point":        faultinject.Inject("nope", faultinject.Fault{}),
+		"negative skip":        faultinject.Inject(faultinject.Compress, faultinject.Fault{Skip: -1}),
+		"short read elsewhere": faultinject.Inject(faultinject.Compress, faultinject.Fault{ShortRead: 1}),
+	} {
+		if !errors.Is(err, openzl.ErrInvalidParameter) {
+			t.Errorf("%s: Inject() = %v, want ErrInvalidParameter", name, err)
+		}
+	}
+}
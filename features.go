@@ -0,0 +1,72 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import "github.com/borischu/go-openzl/internal/cgo"
+
+// FeatureSet reports which graphs and codecs the linked OpenZL library supports.
+//
+// Programs built against older libopenzl releases can use it to adapt at
+// runtime, for example by falling back to Compress when numeric graphs are
+// unavailable.
+type FeatureSet struct {
+	// Numeric is true if numeric typed compression (CompressNumeric) is available.
+	Numeric bool
+
+	// Struct is true if fixed-width struct typed compression is available.
+	Struct bool
+
+	// String is true if variable-length string typed compression
+	// (CompressStrings) is available.
+	String bool
+
+	// SDDL is true if the Simple Data Description Language (NewSDDLGraph) is
+	// available.
+	SDDL bool
+
+	// Training is true if trained (serialized) compressor profiles are available.
+	Training bool
+
+	// StageReport is true if per-stage reports (WithStageReport) are available.
+	StageReport bool
+
+	// MinFormatVersion is the oldest wire format version the library can write.
+	MinFormatVersion int
+
+	// MaxFormatVersion is the newest wire format version the library can write.
+	MaxFormatVersion int
+
+	// Version is the library release as "major.minor.patch", or "" for
+	// releases whose headers do not declare it.
+	Version string
+}
+
+// Features returns the feature set of the linked OpenZL library.
+//
+// Feature detection happens when the package is built, against the headers
+// of whichever libopenzl release it is linked with, so the result is constant
+// for the lifetime of the program. Features missing from an older release are
+// reported as unavailable rather than breaking the build.
+//
+// Example:
+//
+//	if openzl.Features().Numeric {
+//		compressed, err = openzl.CompressNumeric(values)
+//	} else {
+//		compressed, err = openzl.Compress(raw)
+//	}
+func Features() FeatureSet {
+	f := cgo.QueryFeatures()
+	return FeatureSet{
+		Numeric:          f.Numeric,
+		Struct:           f.Struct,
+		String:           f.String,
+		SDDL:             f.SDDL,
+		Training:         f.Training,
+		StageReport:      f.Introspection,
+		MinFormatVersion: f.MinFormatVersion,
+		MaxFormatVersion: f.MaxFormatVersion,
+		Version:          f.Version,
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFeatures(t *testing.T) {
+	f := Features()
+
+	// Typed compression is exercised throughout the test suite, so the
+	// library this package is built against must support numeric graphs.
+	if !f.Numeric {
+		t.Error("expected numeric graph support")
+	}
+
+	if f.MaxFormatVersion <= 0 {
+		t.Errorf("MaxFormatVersion = %d, want > 0", f.MaxFormatVersion)
+	}
+	if f.MinFormatVersion > f.MaxFormatVersion {
+		t.Errorf("MinFormatVersion %d > MaxFormatVersion %d", f.MinFormatVersion, f.MaxFormatVersion)
+	}
+
+	if v := OpenZLVersion(); v != f.Version && !(f.Version == "" && v == "unknown") {
+		t.Errorf("OpenZLVersion() = %q, want %q", v, f.Version)
+	}
+
+	t.Logf("Features: %+v", f)
+}
+
+func TestBuildInfo(t *testing.T) {
+	b := BuildInfo()
+	if b.Version != Version || b.OpenZLVersion != OpenZLVersion() {
+		t.Errorf("BuildInfo() = %+v, want versions %s and %s", b, Version, OpenZLVersion())
+	}
+	switch b.LinkMode {
+	case "static", "dynamic":
+	default:
+		t.Errorf("LinkMode = %q", b.LinkMode)
+	}
+	if s := b.String(); !strings.Contains(s, Version) || !strings.Contains(s, b.LinkMode) {
+		t.Errorf("String() = %q", s)
+	}
+	t.Logf("BuildInfo: %v", b)
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+
+	"github.com/borischu/go-openzl/internal/cgo"
+	"github.com/borischu/go-openzl/spec"
+)
+
+// Stream framing used by Writer and Reader.
+//
+// A stream is a sequence of frames, each holding one independently decodable
+// OpenZL frame. Each frame starts with a 4-byte little-endian header. The low
+// 30 bits hold the length of the payload that follows; the high bits are
+// flags. A header of zero marks the end of the stream; an end marker with any
+// flag set is invalid.
+//
+// The payload is normally an OpenZL frame. If flagStored is set, it is the
+// uncompressed data itself (see WithStoredFallback).
+//
+//	+----------------+-------------------+----------------------+
+//	| header (4, LE) | payload (size)    | CRC32C (4, LE)       |
+//	+----------------+-------------------+----------------------+
+//	                                      only if flagChecksum set
+//
+// External tools such as indexers or range readers can walk a stream with
+// ParseFrameHeader without going through Reader. Package spec holds the
+// complete specification, for implementations in other languages.
+const (
+	// FrameHeaderSize is the size of the per-frame header in bytes.
+	FrameHeaderSize = spec.FrameHeaderSize
+
+	// FrameChecksumSize is the size of the optional CRC32C trailer in bytes.
+	FrameChecksumSize = spec.FrameChecksumSize
+)
+
+const (
+	// frameFlagChecksum marks a frame followed by a CRC32C of its payload.
+	frameFlagChecksum = spec.FlagChecksum
+
+	// frameFlagStored marks a frame whose payload is stored uncompressed.
+	// Readers that predate it reject it as a reserved flag.
+	frameFlagStored = spec.FlagStored
+
+	// frameSizeMask extracts the payload length from a frame header.
+	frameSizeMask = spec.SizeMask
+)
+
+// crc32cTable is the Castagnoli table used for frame checksums. CRC32C is
+// hardware accelerated on amd64 and arm64.
+var crc32cTable = crc32.MakeTable(spec.ChecksumPolynomial)
+
+// putFrameHeader encodes a frame header for a payload of the given size.
+func putFrameHeader(b []byte, size int, flags uint32) {
+	binary.LittleEndian.PutUint32(b, uint32(size)|flags)
+}
+
+// parseFrameHeader decodes a frame header into payload size and flags.
+func parseFrameHeader(b []byte) (size int, flags uint32) {
+	v := binary.LittleEndian.Uint32(b)
+	return int(v & frameSizeMask), v &^ frameSizeMask
+}
+
+// FrameHeader is a decoded Writer stream frame header.
+type FrameHeader struct {
+	// PayloadSize is the length of the compressed OpenZL frame that follows
+	// the header. It is zero for the end-of-stream marker.
+	PayloadSize int
+
+	// Checksum reports whether the payload is followed by a CRC32C trailer
+	// of FrameChecksumSize bytes, computed over the payload.
+	Checksum bool
+
+	// Stored reports whether the payload is uncompressed data rather than
+	// an OpenZL frame.
+	Stored bool
+}
+
+// EndOfStream reports whether the header is the end-of-stream marker.
+func (h FrameHeader) EndOfStream() bool {
+	return h.PayloadSize == 0
+}
+
+// FrameSize returns the total number of bytes the frame occupies in the
+// stream: header, payload, and checksum trailer if present. The next frame
+// header starts FrameSize bytes after this one.
+func (h FrameHeader) FrameSize() int {
+	if h.EndOfStream() {
+		return FrameHeaderSize
+	}
+	n := FrameHeaderSize + h.PayloadSize
+	if h.Checksum {
+		n += FrameChecksumSize
+	}
+	return n
+}
+
+// ParseFrameHeader decodes the frame header at the start of b.
+//
+// It returns io.ErrUnexpectedEOF if b is shorter than FrameHeaderSize, and
+// ErrCorruptedData if the header is an end-of-stream marker with flags set.
+//
+// Example:
+//
+//	// List the frames of a stream held in memory
+//	for off := 0; off < len(stream); {
+//		h, err := openzl.ParseFrameHeader(stream[off:])
+//		if err != nil {
+//			return err
+//		}
+//		if h.EndOfStream() {
+//			break
+//		}
+//		fmt.Printf("frame at %d: %d bytes\n", off, h.PayloadSize)
+//		off += h.FrameSize()
+//	}
+func ParseFrameHeader(b []byte) (FrameHeader, error) {
+	if len(b) < FrameHeaderSize {
+		return FrameHeader{}, io.ErrUnexpectedEOF
+	}
+	size, flags := parseFrameHeader(b)
+	if size == 0 && flags != 0 {
+		return FrameHeader{}, fmt.Errorf("%w: flags set on end-of-stream marker", ErrCorruptedData)
+	}
+	return FrameHeader{
+		PayloadSize: size,
+		Checksum:    flags&frameFlagChecksum != 0,
+		Stored:      flags&frameFlagStored != 0,
+	}, nil
+}
+
+// FrameHeaderLength returns the size in bytes of the OpenZL frame header at
+// the start of compressed, as produced by Compress or a Compressor. The frame
+// payload starts at that offset.
+//
+// This lets indexing tools store or cache headers separately from payloads.
+// Only the header itself needs to be present in compressed: a prefix of the
+// frame is enough. Note that this is the header of an OpenZL frame, not the
+// FrameHeaderSize-byte header that a Writer places in front of each frame.
+//
+// Example:
+//
+//	n, err := openzl.FrameHeaderLength(compressed)
+//	if err != nil {
+//		return err
+//	}
+//	header, payload := compressed[:n], compressed[n:]
+//
+// Returns ErrEmptyInput if compressed is empty, ErrStreamInput if it is a
+// Writer stream, or an error if it does not start with a complete OpenZL
+// frame header.
+func FrameHeaderLength(compressed []byte) (int, error) {
+	if len(compressed) == 0 {
+		return 0, ErrEmptyInput
+	}
+	n, err := cgo.HeaderSize(compressed)
+	if err != nil {
+		return 0, fmt.Errorf("get header size: %w", diagnoseFrameError(compressed, err))
+	}
+	return n, nil
+}
+
+// isBareFrame reports whether b begins with an OpenZL frame magic number.
+func isBareFrame(b []byte) bool {
+	_, err := cgo.FrameFormatVersion(b)
+	return err == nil
+}
+
+// isStream reports whether b looks like the start of a Writer stream: a
+// valid frame header followed by an OpenZL frame (or an end marker alone).
+func isStream(b []byte) bool {
+	h, err := ParseFrameHeader(b)
+	if err != nil {
+		return false
+	}
+	if h.EndOfStream() {
+		return len(b) == FrameHeaderSize
+	}
+	if h.Stored {
+		return len(b) >= h.FrameSize()
+	}
+	return isBareFrame(b[FrameHeaderSize:])
+}
+
+// diagnoseFrameError replaces a low-level header error from a one-shot
+// decompression with ErrStreamInput if src is actually a Writer stream.
+func diagnoseFrameError(src []byte, err error) error {
+	if isStream(src) {
+		return fmt.Errorf("%w (%v)", ErrStreamInput, err)
+	}
+	return err
+}
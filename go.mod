@@ -1,5 +1,3 @@
 module github.com/borischu/go-openzl
 
 go 1.24.4
-
-require github.com/klauspost/compress v1.18.1 // indirect
@@ -0,0 +1,408 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/borischu/go-openzl/internal/cgo"
+)
+
+// Transform is a standard OpenZL node that splits or rewrites its input
+// before the data reaches a backend, such as delta coding or transposition.
+type Transform int
+
+// Transforms available to GraphBuilder.AddNode.
+const (
+	// TransformDelta replaces each numeric value with its difference from
+	// the previous one. One output.
+	TransformDelta Transform = iota + 1
+
+	// TransformZigzag maps signed numeric values to unsigned ones so that
+	// small magnitudes get small codes. One output.
+	TransformZigzag
+
+	// TransformTokenize splits numeric values into an alphabet of distinct
+	// values (output 0) and the index of each value in it (output 1).
+	TransformTokenize
+
+	// TransformTranspose2, TransformTranspose4, and TransformTranspose8
+	// split serial data of 2-, 4-, or 8-byte records into one output per
+	// byte position.
+	TransformTranspose2
+	TransformTranspose4
+	TransformTranspose8
+
+	// TransformInterpretLE16, TransformInterpretLE32, and
+	// TransformInterpretLE64 read serial data as little-endian numbers of
+	// that width, for the numeric transforms that follow. One output.
+	TransformInterpretLE16
+	TransformInterpretLE32
+	TransformInterpretLE64
+)
+
+var transforms = [...]struct {
+	name    string
+	node    int
+	outputs int
+}{
+	TransformDelta:         {"delta", cgo.NodeDelta, 1},
+	TransformZigzag:        {"zigzag", cgo.NodeZigzag, 1},
+	TransformTokenize:      {"tokenize", cgo.NodeTokenize, 2},
+	TransformTranspose2:    {"transpose2", cgo.NodeTransposeSplit2, 2},
+	TransformTranspose4:    {"transpose4", cgo.NodeTransposeSplit4, 4},
+	TransformTranspose8:    {"transpose8", cgo.NodeTransposeSplit8, 8},
+	TransformInterpretLE16: {"interpret-le16", cgo.NodeInterpretLE16, 1},
+	TransformInterpretLE32: {"interpret-le32", cgo.NodeInterpretLE32, 1},
+	TransformInterpretLE64: {"interpret-le64", cgo.NodeInterpretLE64, 1},
+}
+
+func (t Transform) valid() bool {
+	return t > 0 && int(t) < len(transforms)
+}
+
+// String returns the name of the transform, such as "delta".
+func (t Transform) String() string {
+	if !t.valid() {
+		return fmt.Sprintf("Transform(%d)", int(t))
+	}
+	return transforms[t].name
+}
+
+// Outputs returns the number of outputs of the transform, each of which
+// must be connected to a node or a backend.
+func (t Transform) Outputs() int {
+	if !t.valid() {
+		return 0
+	}
+	return transforms[t].outputs
+}
+
+// Backend is a standard OpenZL graph that ends a branch of a custom graph,
+// encoding whatever reaches it.
+type Backend int
+
+// Backends available to GraphBuilder.ConnectBackend.
+const (
+	BackendStore   Backend = iota + 1 // Stores the data as is
+	BackendEntropy                    // Huffman or FSE, whichever is smaller
+	BackendHuffman                    // Huffman coding
+	BackendFSE                        // Finite state entropy coding
+	BackendZstd                       // Zstandard
+	BackendBitpack                    // Packs numbers into their significant bits
+	BackendFieldLZ                    // LZ over fixed-width fields
+	BackendGeneric                    // OpenZL's general-purpose graph
+	BackendNumeric                    // OpenZL's numeric graph
+)
+
+var backends = [...]struct {
+	name  string
+	graph int
+}{
+	BackendStore:   {"store", cgo.GraphStore},
+	BackendEntropy: {"entropy", cgo.GraphEntropy},
+	BackendHuffman: {"huffman", cgo.GraphHuffman},
+	BackendFSE:     {"fse", cgo.GraphFSE},
+	BackendZstd:    {"zstd", cgo.GraphZstd},
+	BackendBitpack: {"bitpack", cgo.GraphBitpack},
+	BackendFieldLZ: {"field-lz", cgo.GraphFieldLZ},
+	BackendGeneric: {"generic", cgo.GraphGeneric},
+	BackendNumeric: {"numeric", cgo.GraphNumeric},
+}
+
+func (b Backend) valid() bool {
+	return b > 0 && int(b) < len(backends)
+}
+
+// String returns the name of the backend, such as "entropy".
+func (b Backend) String() string {
+	if !b.valid() {
+		return fmt.Sprintf("Backend(%d)", int(b))
+	}
+	return backends[b].name
+}
+
+// graphNode is a node added to a GraphBuilder. Each output holds the name
+// of the node it is connected to, or the backend.
+type graphNode struct {
+	name      string
+	transform Transform
+	nodes     []string
+	backends  []Backend
+}
+
+// GraphBuilder composes transforms and backends into a custom compression
+// graph, for formats whose structure the default graph does not exploit.
+//
+// Nodes are added with AddNode and named so that Connect can route each of
+// their outputs to another node or, with ConnectBackend, to a backend. Data
+// enters at the first node added, or the one named with Start. The methods
+// return the builder so calls can be chained; the first error is kept and
+// returned by Build.
+//
+// Example:
+//
+//	// Timestamps: delta, then zigzag, then bitpack
+//	graph, err := openzl.NewGraphBuilder().
+//		AddNode("delta", openzl.TransformDelta).
+//		AddNode("zigzag", openzl.TransformZigzag).
+//		Connect("delta", 0, "zigzag").
+//		ConnectBackend("zigzag", 0, openzl.BackendBitpack).
+//		Build()
+//	if err != nil {
+//		return err
+//	}
+//	compressor, err := openzl.NewCompressor(openzl.WithGraph(graph))
+//	...
+//	compressed, err := openzl.CompressorCompressNumeric(compressor, timestamps)
+type GraphBuilder struct {
+	nodes []*graphNode
+	start string
+	err   error
+}
+
+// NewGraphBuilder returns an empty GraphBuilder.
+func NewGraphBuilder() *GraphBuilder {
+	return &GraphBuilder{}
+}
+
+// fail records err unless an error was already recorded.
+func (b *GraphBuilder) fail(format string, args ...any) *GraphBuilder {
+	if b.err == nil {
+		b.err = fmt.Errorf("%w: graph: %s", ErrInvalidParameter, fmt.Sprintf(format, args...))
+	}
+	return b
+}
+
+// node returns the node named name, or nil.
+func (b *GraphBuilder) node(name string) *graphNode {
+	for _, n := range b.nodes {
+		if n.name == name {
+			return n
+		}
+	}
+	return nil
+}
+
+// output returns the node named from, checking that its output exists and
+// is not yet connected.
+func (b *GraphBuilder) output(from string, output int) *graphNode {
+	n := b.node(from)
+	switch {
+	case n == nil:
+		b.fail("unknown node %q", from)
+		return nil
+	case output < 0 || output >= len(n.nodes):
+		b.fail("node %q (%s) has no output %d", from, n.transform, output)
+		return nil
+	case n.nodes[output] != "" || n.backends[output] != 0:
+		b.fail("output %d of node %q is already connected", output, from)
+		return nil
+	}
+	return n
+}
+
+// AddNode adds a node named name that applies transform t.
+func (b *GraphBuilder) AddNode(name string, t Transform) *GraphBuilder {
+	switch {
+	case name == "":
+		return b.fail("empty node name")
+	case b.node(name) != nil:
+		return b.fail("duplicate node %q", name)
+	case !t.valid():
+		return b.fail("node %q: unknown %s", name, t)
+	}
+	b.nodes = append(b.nodes, &graphNode{
+		name:      name,
+		transform: t,
+		nodes:     make([]string, t.Outputs()),
+		backends:  make([]Backend, t.Outputs()),
+	})
+	return b
+}
+
+// Connect routes output number output of node from into node to.
+func (b *GraphBuilder) Connect(from string, output int, to string) *GraphBuilder {
+	if n := b.output(from, output); n != nil {
+		n.nodes[output] = to
+	}
+	return b
+}
+
+// ConnectBackend routes output number output of node from into backend.
+func (b *GraphBuilder) ConnectBackend(from string, output int, backend Backend) *GraphBuilder {
+	if !backend.valid() {
+		return b.fail("unknown %s", backend)
+	}
+	if n := b.output(from, output); n != nil {
+		n.backends[output] = backend
+	}
+	return b
+}
+
+// Start makes data enter the graph at the node named name rather than at
+// the first node added.
+func (b *GraphBuilder) Start(name string) *GraphBuilder {
+	b.start = name
+	return b
+}
+
+// Build checks the graph and registers it with OpenZL.
+//
+// Returns an error wrapping ErrInvalidParameter if a call to the builder
+// failed, the graph is empty, an output is left unconnected, the nodes
+// form a cycle, or a node cannot be reached from the start node. Returns an
+// error wrapping errors.ErrUnsupported if the linked library lacks one of
+// the transforms or backends. OpenZL itself may reject the graph, for
+// example when a transform receives data of a type it does not accept.
+func (b *GraphBuilder) Build() (*Graph, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if len(b.nodes) == 0 {
+		return nil, b.fail("no nodes").err
+	}
+	start := b.start
+	if start == "" {
+		start = b.nodes[0].name
+	}
+
+	index := make(map[string]int, len(b.nodes))
+	for i, n := range b.nodes {
+		index[n.name] = i
+	}
+	s, ok := index[start]
+	if !ok {
+		return nil, b.fail("unknown start node %q", start).err
+	}
+
+	spec := cgo.GraphSpec{Nodes: make([]cgo.GraphNode, len(b.nodes)), Start: s}
+	for i, n := range b.nodes {
+		if !cgo.NodeAvailable(transforms[n.transform].node) {
+			return nil, fmt.Errorf("graph: transform %s: %w", n.transform, errors.ErrUnsupported)
+		}
+		outputs := make([]cgo.GraphTarget, len(n.nodes))
+		for j := range outputs {
+			switch {
+			case n.backends[j] != 0:
+				backend := n.backends[j]
+				if !cgo.GraphAvailable(backends[backend].graph) {
+					return nil, fmt.Errorf("graph: backend %s: %w", backend, errors.ErrUnsupported)
+				}
+				outputs[j].Graph = backends[backend].graph
+			case n.nodes[j] != "":
+				to, ok := index[n.nodes[j]]
+				if !ok {
+					return nil, b.fail("output %d of node %q: unknown node %q", j, n.name, n.nodes[j]).err
+				}
+				outputs[j].Node = to
+			default:
+				return nil, b.fail("output %d of node %q is not connected", j, n.name).err
+			}
+		}
+		spec.Nodes[i] = cgo.GraphNode{Kind: transforms[n.transform].node, Outputs: outputs}
+	}
+
+	// Every node must be reachable from the start node without passing
+	// through itself.
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make([]int, len(spec.Nodes))
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case visiting:
+			return b.fail("cycle through node %q", b.nodes[i].name).err
+		case done:
+			return nil
+		}
+		state[i] = visiting
+		for _, t := range spec.Nodes[i].Outputs {
+			if t.Graph == 0 {
+				if err := visit(t.Node); err != nil {
+					return err
+				}
+			}
+		}
+		state[i] = done
+		return nil
+	}
+	if err := visit(s); err != nil {
+		return nil, err
+	}
+	for i, st := range state {
+		if st != done {
+			return nil, b.fail("node %q is not reachable from %q", b.nodes[i].name, start).err
+		}
+	}
+
+	g, err := cgo.NewGraph(spec)
+	if err != nil {
+		return nil, fmt.Errorf("build graph: %w", err)
+	}
+	return &Graph{graph: g, desc: b.describe(index, s)}, nil
+}
+
+// describe returns the graph in the notation of Graph.String.
+func (b *GraphBuilder) describe(index map[string]int, i int) string {
+	n := b.nodes[i]
+	var sb strings.Builder
+	sb.WriteString(n.transform.String())
+	if len(n.nodes) > 1 {
+		sb.WriteString("(")
+	} else {
+		sb.WriteString(" -> ")
+	}
+	for j := range n.nodes {
+		if j > 0 {
+			sb.WriteString(", ")
+		}
+		if n.backends[j] != 0 {
+			sb.WriteString(n.backends[j].String())
+		} else {
+			sb.WriteString(b.describe(index, index[n.nodes[j]]))
+		}
+	}
+	if len(n.nodes) > 1 {
+		sb.WriteString(")")
+	}
+	return sb.String()
+}
+
+// Graph is a custom compression graph built with GraphBuilder. It is
+// immutable and may be shared by any number of Compressors; see WithGraph.
+type Graph struct {
+	graph *cgo.Graph
+	desc  string
+}
+
+// String describes the graph from its start node, such as
+// "delta -> zigzag -> bitpack" or "tokenize(entropy, delta -> bitpack)".
+func (g *Graph) String() string {
+	return g.desc
+}
+
+// WithGraph makes the Compressor compress with a custom graph instead of
+// OpenZL's default graph. The graph applies to Compress and to typed
+// compression such as CompressorCompressNumeric; multi-input compression keeps
+// the default graph.
+//
+// Frames compressed with a custom graph decompress with Decompress as
+// usual, since OpenZL frames describe their own decoding.
+//
+// Returns an *OptionError from NewCompressor if g is nil.
+func WithGraph(g *Graph) CompressorOption {
+	return func(cfg *config) error {
+		if g == nil {
+			return &OptionError{Option: "WithGraph", Value: g, Allowed: "a graph from GraphBuilder.Build"}
+		}
+		cfg.graph = g
+		return nil
+	}
+}
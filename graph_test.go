@@ -0,0 +1,166 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestGraphBuilder(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *GraphBuilder
+		want    string
+	}{
+		{
+			name: "chain",
+			builder: NewGraphBuilder().
+				AddNode("delta", TransformDelta).
+				AddNode("zigzag", TransformZigzag).
+				Connect("delta", 0, "zigzag").
+				ConnectBackend("zigzag", 0, BackendBitpack),
+			want: "delta -> zigzag -> bitpack",
+		},
+		{
+			name: "branches",
+			builder: NewGraphBuilder().
+				AddNode("delta", TransformDelta).
+				AddNode("tokenize", TransformTokenize).
+				ConnectBackend("tokenize", 0, BackendEntropy).
+				Connect("tokenize", 1, "delta").
+				ConnectBackend("delta", 0, BackendBitpack).
+				Start("tokenize"),
+			want: "tokenize(entropy, delta -> bitpack)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := tt.builder.Build()
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			if got := g.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGraphBuilderErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *GraphBuilder
+	}{
+		{"empty", NewGraphBuilder()},
+		{"empty name", NewGraphBuilder().AddNode("", TransformDelta)},
+		{"duplicate node", NewGraphBuilder().
+			AddNode("a", TransformDelta).
+			AddNode("a", TransformZigzag)},
+		{"unknown transform", NewGraphBuilder().AddNode("a", Transform(99))},
+		{"unknown backend", NewGraphBuilder().
+			AddNode("a", TransformDelta).
+			ConnectBackend("a", 0, Backend(0))},
+		{"unknown from", NewGraphBuilder().
+			AddNode("a", TransformDelta).
+			ConnectBackend("b", 0, BackendStore)},
+		{"output out of range", NewGraphBuilder().
+			AddNode("a", TransformDelta).
+			ConnectBackend("a", 1, BackendStore)},
+		{"output connected twice", NewGraphBuilder().
+			AddNode("a", TransformDelta).
+			ConnectBackend("a", 0, BackendStore).
+			ConnectBackend("a", 0, BackendEntropy)},
+		{"unconnected output", NewGraphBuilder().
+			AddNode("a", TransformTokenize).
+			ConnectBackend("a", 0, BackendStore)},
+		{"unknown to", NewGraphBuilder().
+			AddNode("a", TransformDelta).
+			Connect("a", 0, "b")},
+		{"unknown start", NewGraphBuilder().
+			AddNode("a", TransformDelta).
+			ConnectBackend("a", 0, BackendStore).
+			Start("b")},
+		{"cycle", NewGraphBuilder().
+			AddNode("a", TransformDelta).
+			AddNode("b", TransformZigzag).
+			Connect("a", 0, "b").
+			Connect("b", 0, "a")},
+		{"unreachable", NewGraphBuilder().
+			AddNode("a", TransformDelta).
+			AddNode("b", TransformZigzag).
+			ConnectBackend("a", 0, BackendStore).
+			ConnectBackend("b", 0, BackendStore)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := tt.builder.Build()
+			if !errors.Is(err, ErrInvalidParameter) {
+				t.Fatalf("Build() = %v, %v; want ErrInvalidParameter", g, err)
+			}
+		})
+	}
+}
+
+func TestWithGraph(t *testing.T) {
+	_, err := NewCompressor(WithGraph(nil))
+	var oe *OptionError
+	if !errors.As(err, &oe) || oe.Option != "WithGraph" {
+		t.Errorf("WithGraph(nil): got error %v, want an *OptionError", err)
+	}
+
+	g, err := NewGraphBuilder().
+		AddNode("delta", TransformDelta).
+		ConnectBackend("delta", 0, BackendEntropy).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	compressor, err := NewCompressor(WithGraph(g))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer compressor.Close()
+
+	values := make([]int64, 4096)
+	for i := range values {
+		values[i] = 1_700_000_000 + int64(i)*10
+	}
+	// Twice, since OpenZL resets the graph after each compression
+	for range 2 {
+		compressed, err := CompressorCompressNumeric(compressor, values)
+		if err != nil {
+			t.Fatalf("CompressorCompressNumeric() error = %v", err)
+		}
+		got, err := DecompressNumeric[int64](compressed)
+		if err != nil {
+			t.Fatalf("DecompressNumeric() error = %v", err)
+		}
+		if !slices.Equal(got, values) {
+			t.Fatal("round trip mismatch")
+		}
+	}
+}
+
+func TestNewSDDLGraph(t *testing.T) {
+	if _, err := NewSDDLGraph(nil); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("NewSDDLGraph(nil) = %v, want ErrInvalidParameter", err)
+	}
+	_, err := NewSDDLGraph([]byte("\x00not a compiled description"))
+	if !Features().SDDL {
+		if !errors.Is(err, errors.ErrUnsupported) {
+			t.Errorf("NewSDDLGraph() without SDDL = %v, want errors.ErrUnsupported", err)
+		}
+	} else if err == nil {
+		t.Error("NewSDDLGraph(invalid) succeeded")
+	}
+
+	if _, err := CompileSDDL(""); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("CompileSDDL(\"\") = %v, want ErrInvalidParameter", err)
+	}
+	if _, err := CompileSDDL("record Trade { price: f64 }"); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("CompileSDDL() = %v, want errors.ErrUnsupported", err)
+	}
+}
@@ -0,0 +1,394 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/borischu/go-openzl/internal/cgo"
+	"github.com/borischu/go-openzl/spec"
+)
+
+// IndexSidecarExt is the extension of index sidecar files, appended to the
+// name of the archive they index.
+const IndexSidecarExt = spec.IndexExt
+
+// IndexEntry locates one frame of a Writer stream.
+type IndexEntry struct {
+	// Offset is the position of the frame header in the stream, and Size
+	// the bytes the frame occupies: header, payload, and checksum trailer.
+	Offset int64
+	Size   int
+
+	// UncompressedOffset is the position of the frame's data in the
+	// uncompressed content, and UncompressedSize its length.
+	UncompressedOffset int64
+	UncompressedSize   int
+}
+
+// FrameIndex lists the frames of a Writer stream, for random access to
+// archives that were written without seek support in mind. It is stored in
+// a sidecar file next to the archive; see WriteIndexSidecar.
+type FrameIndex struct {
+	Frames []IndexEntry
+}
+
+// UncompressedSize returns the size of the uncompressed content.
+func (x *FrameIndex) UncompressedSize() int64 {
+	if len(x.Frames) == 0 {
+		return 0
+	}
+	last := x.Frames[len(x.Frames)-1]
+	return last.UncompressedOffset + int64(last.UncompressedSize)
+}
+
+// Find returns the position in Frames of the frame holding the uncompressed
+// byte at off, and false if off is outside the content.
+func (x *FrameIndex) Find(off int64) (int, bool) {
+	if off < 0 || off >= x.UncompressedSize() {
+		return 0, false
+	}
+	i := sort.Search(len(x.Frames), func(i int) bool {
+		e := x.Frames[i]
+		return e.UncompressedOffset+int64(e.UncompressedSize) > off
+	})
+	return i, true
+}
+
+// add appends a frame of size bytes holding n bytes of data.
+func (x *FrameIndex) add(size, n int) {
+	var e IndexEntry
+	if len(x.Frames) > 0 {
+		last := x.Frames[len(x.Frames)-1]
+		e.Offset = last.Offset + int64(last.Size)
+		e.UncompressedOffset = last.UncompressedOffset + int64(last.UncompressedSize)
+	}
+	e.Size, e.UncompressedSize = size, n
+	x.Frames = append(x.Frames, e)
+}
+
+// Serialize returns x in the index sidecar format of package spec, as read
+// back by ReadFrameIndex.
+func (x *FrameIndex) Serialize() []byte {
+	b := append([]byte(spec.IndexMagic), spec.IndexVersion)
+	b = binary.AppendUvarint(b, uint64(len(x.Frames)))
+	for _, e := range x.Frames {
+		b = binary.AppendUvarint(b, uint64(e.Size))
+		b = binary.AppendUvarint(b, uint64(e.UncompressedSize))
+	}
+	return binary.LittleEndian.AppendUint32(b, crc32.Checksum(b, crc32cTable))
+}
+
+// WriteTo writes x in the index sidecar format of package spec.
+func (x *FrameIndex) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(x.Serialize())
+	return int64(n), err
+}
+
+// ReadFrameIndex reads an index in the sidecar format from r.
+//
+// Returns ErrCorruptedData if the data is not a valid index, or
+// ErrChecksumMismatch if its checksum does not match.
+func ReadFrameIndex(r io.Reader) (*FrameIndex, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read index: %w", err)
+	}
+	if len(b) < len(spec.IndexMagic)+1+spec.IndexChecksumSize || string(b[:len(spec.IndexMagic)]) != spec.IndexMagic {
+		return nil, fmt.Errorf("%w: not a frame index", ErrCorruptedData)
+	}
+	body, sum := b[:len(b)-spec.IndexChecksumSize], b[len(b)-spec.IndexChecksumSize:]
+	if binary.LittleEndian.Uint32(sum) != crc32.Checksum(body, crc32cTable) {
+		return nil, fmt.Errorf("frame index: %w", ErrChecksumMismatch)
+	}
+	if v := body[len(spec.IndexMagic)]; v != spec.IndexVersion {
+		return nil, fmt.Errorf("%w: frame index version %d, want %d", ErrCorruptedData, v, spec.IndexVersion)
+	}
+	body = body[len(spec.IndexMagic)+1:]
+
+	uvarint := func() (int, error) {
+		v, k := binary.Uvarint(body)
+		if k <= 0 || v > math.MaxInt {
+			return 0, fmt.Errorf("%w: invalid frame index", ErrCorruptedData)
+		}
+		body = body[k:]
+		return int(v), nil
+	}
+	count, err := uvarint()
+	if err != nil {
+		return nil, err
+	}
+	if count > len(body)/2 {
+		return nil, fmt.Errorf("%w: frame index of %d frames truncated", ErrCorruptedData, count)
+	}
+	x := &FrameIndex{Frames: make([]IndexEntry, 0, count)}
+	for range count {
+		size, err := uvarint()
+		if err != nil {
+			return nil, err
+		}
+		n, err := uvarint()
+		if err != nil {
+			return nil, err
+		}
+		if size <= FrameHeaderSize {
+			return nil, fmt.Errorf("%w: frame index lists a frame of %d bytes", ErrCorruptedData, size)
+		}
+		x.add(size, n)
+	}
+	if len(body) != 0 {
+		return nil, fmt.Errorf("%w: %d bytes after frame index", ErrCorruptedData, len(body))
+	}
+	return x, nil
+}
+
+// indexProbeSize is how much of a compressed payload BuildIndex reads to
+// find its uncompressed size in the OpenZL frame header.
+const indexProbeSize = 1 << 10
+
+// BuildIndex reads a Writer stream through r and returns the index of its
+// frames, one frame at a time. Stored payloads are skipped and only the
+// start of compressed payloads is read: the size of each frame's data is
+// taken from its OpenZL frame header. Existing archives can thereby be
+// indexed, and made seekable, without reading them in full or recompressing
+// them.
+//
+// The stream may lack its end-of-stream marker, as when its Writer was never
+// closed; the frames it holds are indexed.
+//
+// Example:
+//
+//	f, err := os.Open("2023.zl")
+//	...
+//	x, err := openzl.BuildIndex(f)
+//	...
+//	err = os.WriteFile("2023.zl"+openzl.IndexSidecarExt, x.Serialize(), 0o644)
+//
+// Returns ErrFrameInput for a bare frame from Compress, ErrCorruptedData
+// for an invalid frame header, or io.ErrUnexpectedEOF if the stream ends
+// inside a frame.
+func BuildIndex(r io.ReaderAt) (*FrameIndex, error) {
+	x := &FrameIndex{}
+	var off int64
+	for {
+		var header [FrameHeaderSize]byte
+		if n, err := r.ReadAt(header[:], off); n < len(header) {
+			if n == 0 && err == io.EOF {
+				return x, nil
+			}
+			if err == io.EOF {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, fmt.Errorf("read header: %w", err)
+		}
+		if len(x.Frames) == 0 && isBareFrame(header[:]) {
+			return nil, ErrFrameInput
+		}
+		h, err := ParseFrameHeader(header[:])
+		if err != nil {
+			return nil, fmt.Errorf("frame %d: %w", len(x.Frames), err)
+		}
+		if h.EndOfStream() {
+			return x, nil
+		}
+
+		n := h.PayloadSize
+		if !h.Stored {
+			n, err = payloadSize(r, off+FrameHeaderSize, h.PayloadSize)
+			if err != nil {
+				return nil, fmt.Errorf("frame %d: %w", len(x.Frames), err)
+			}
+		}
+		// Check that the stream holds the whole frame, checksum included
+		end := off + int64(h.FrameSize())
+		if k, err := r.ReadAt(header[:1], end-1); k == 0 {
+			return nil, readFrameError("read frame", err)
+		}
+		x.add(h.FrameSize(), n)
+		off = end
+	}
+}
+
+// payloadSize returns the uncompressed size of the OpenZL frame of size
+// bytes at off in r. It reads the first indexProbeSize bytes of the frame,
+// and the whole frame only if its header does not fit in them.
+func payloadSize(r io.ReaderAt, off int64, size int) (int, error) {
+	n := min(size, indexProbeSize)
+	for {
+		b := allocBuf(n)
+		k, err := r.ReadAt(b, off)
+		if k < n {
+			freeBuf(b)
+			return 0, readFrameError("read frame", err)
+		}
+		uncompressed, err := cgo.GetDecompressedSize(b)
+		freeBuf(b)
+		if err == nil {
+			return uncompressed, nil
+		}
+		if n == size {
+			return 0, fmt.Errorf("get decompressed size: %w", err)
+		}
+		n = size
+	}
+}
+
+// WriteIndexSidecar indexes the Writer stream in the file archive and writes
+// the index to the file index, or to archive+IndexSidecarExt if index is
+// empty. Archives written without WithIndexSidecar thereby gain random
+// access through NewIndexedReader, without being rewritten.
+//
+// Example:
+//
+//	if err := openzl.WriteIndexSidecar("2024.zl", ""); err != nil {
+//		return err
+//	}
+//	// Later: read 4KB at uncompressed offset 1GB
+//	x, err := openzl.ReadIndexSidecar("2024.zl.zli")
+//	...
+//	f, _ := os.Open("2024.zl")
+//	n, err := openzl.NewIndexedReader(f, x).ReadAt(buf[:4096], 1<<30)
+//
+// Returns the errors of BuildIndex, or the error from reading archive or
+// writing index. On error, the index file is removed.
+func WriteIndexSidecar(archive, index string) (err error) {
+	if index == "" {
+		index = archive + IndexSidecarExt
+	}
+	in, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	x, err := BuildIndex(in)
+	if err != nil {
+		return fmt.Errorf("index %s: %w", archive, err)
+	}
+
+	out, err := os.Create(index)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(index)
+		}
+	}()
+	_, err = x.WriteTo(out)
+	return err
+}
+
+// ReadIndexSidecar reads the index sidecar file at path.
+func ReadIndexSidecar(path string) (*FrameIndex, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	x, err := ReadFrameIndex(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return x, nil
+}
+
+// IndexedReader reads the uncompressed content of a Writer stream at any
+// offset, decompressing only the frames that hold the requested bytes.
+//
+// The most recently decompressed frame is kept, so sequential reads of
+// small ranges decompress each frame once. An IndexedReader is safe for
+// concurrent use.
+type IndexedReader struct {
+	archive io.ReaderAt
+	index   *FrameIndex
+
+	mu    sync.Mutex
+	frame int    // Position in index.Frames of data, -1 for none
+	data  []byte // Uncompressed data of frame
+}
+
+// NewIndexedReader returns an IndexedReader of the stream archive, whose
+// frames are listed by index.
+func NewIndexedReader(archive io.ReaderAt, index *FrameIndex) *IndexedReader {
+	return &IndexedReader{archive: archive, index: index, frame: -1}
+}
+
+// Size returns the size of the uncompressed content.
+func (r *IndexedReader) Size() int64 {
+	return r.index.UncompressedSize()
+}
+
+// ReadAt implements io.ReaderAt over the uncompressed content.
+//
+// Returns ErrCorruptedData if a frame of the archive does not match the
+// index, ErrChecksumMismatch if its checksum does not match its payload, or
+// the decompression error.
+func (r *IndexedReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("%w: negative offset %d", ErrInvalidParameter, off)
+	}
+	n := 0
+	for n < len(p) {
+		i, ok := r.index.Find(off + int64(n))
+		if !ok {
+			return n, io.EOF
+		}
+		e := r.index.Frames[i]
+		r.mu.Lock()
+		data, err := r.load(i)
+		if err == nil {
+			n += copy(p[n:], data[off+int64(n)-e.UncompressedOffset:])
+		}
+		r.mu.Unlock()
+		if err != nil {
+			return n, fmt.Errorf("frame %d at offset %d: %w", i, e.Offset, err)
+		}
+	}
+	return n, nil
+}
+
+// load returns the uncompressed data of frame i. It must be called with mu
+// held.
+func (r *IndexedReader) load(i int) ([]byte, error) {
+	if r.frame == i {
+		return r.data, nil
+	}
+	e := r.index.Frames[i]
+	b := make([]byte, e.Size)
+	if _, err := r.archive.ReadAt(b, e.Offset); err != nil {
+		return nil, readFrameError("read frame", err)
+	}
+	h, err := ParseFrameHeader(b)
+	if err != nil {
+		return nil, err
+	}
+	if h.FrameSize() != e.Size {
+		return nil, fmt.Errorf("%w: frame of %d bytes, index lists %d", ErrCorruptedData, h.FrameSize(), e.Size)
+	}
+	payload := b[FrameHeaderSize : FrameHeaderSize+h.PayloadSize]
+	if h.Checksum && binary.LittleEndian.Uint32(b[len(b)-FrameChecksumSize:]) != crc32.Checksum(payload, crc32cTable) {
+		return nil, ErrChecksumMismatch
+	}
+	data := payload
+	if !h.Stored {
+		if data, err = Decompress(payload); err != nil {
+			return nil, err
+		}
+	}
+	if len(data) != e.UncompressedSize {
+		return nil, fmt.Errorf("%w: frame holds %d bytes, index lists %d", ErrCorruptedData, len(data), e.UncompressedSize)
+	}
+	r.frame, r.data = i, data
+	return r.data, nil
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/borischu/go-openzl/datagen"
+)
+
+func TestIndexSidecar(t *testing.T) {
+	data := append(datagen.Logs(40<<10), datagen.Random(10<<10)...)
+	var archive, sidecar bytes.Buffer
+	w, err := NewWriter(&archive, WithFrameSize(MinFrameSize), WithFrameChecksum(true),
+		WithStoredFallback(true), WithIndexSidecar(&sidecar))
+	if err != nil {
+		t.Fatalf("NewWriter() failed: %v", err)
+	}
+	w.Write(data)
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	x, err := ReadFrameIndex(bytes.NewReader(sidecar.Bytes()))
+	if err != nil {
+		t.Fatalf("ReadFrameIndex() failed: %v", err)
+	}
+	if got, want := len(x.Frames), (len(data)+MinFrameSize-1)/MinFrameSize; got != want {
+		t.Errorf("index lists %d frames, want %d", got, want)
+	}
+	if x.UncompressedSize() != int64(len(data)) {
+		t.Errorf("UncompressedSize() = %d, want %d", x.UncompressedSize(), len(data))
+	}
+
+	// The sidecar built from the archive matches the one the Writer wrote
+	dir := t.TempDir()
+	path := filepath.Join(dir, "archive.zl")
+	os.WriteFile(path, archive.Bytes(), 0o644)
+	if err := WriteIndexSidecar(path, ""); err != nil {
+		t.Fatalf("WriteIndexSidecar() failed: %v", err)
+	}
+	built, err := os.ReadFile(path + IndexSidecarExt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(built, sidecar.Bytes()) {
+		t.Error("WriteIndexSidecar() differs from the index written by the Writer")
+	}
+
+	r := NewIndexedReader(bytes.NewReader(archive.Bytes()), x)
+	for _, c := range []struct{ off, n int }{{0, 10}, {MinFrameSize - 3, 100}, {17000, 20000}, {len(data) - 5, 5}} {
+		buf := make([]byte, c.n)
+		n, err := r.ReadAt(buf, int64(c.off))
+		if err != nil || n != c.n || !bytes.Equal(buf, data[c.off:c.off+c.n]) {
+			t.Errorf("ReadAt(%d bytes at %d) = %d, %v", c.n, c.off, n, err)
+		}
+	}
+	if n, err := r.ReadAt(make([]byte, 10), int64(len(data)-4)); n != 4 || err != io.EOF {
+		t.Errorf("ReadAt past the end = %d, %v, want 4, io.EOF", n, err)
+	}
+
+	// A damaged archive is detected through the frame checksum
+	damaged := bytes.Clone(archive.Bytes())
+	damaged[x.Frames[2].Offset+FrameHeaderSize] ^= 0xff
+	r = NewIndexedReader(bytes.NewReader(damaged), x)
+	if _, err := r.ReadAt(make([]byte, 10), x.Frames[2].UncompressedOffset); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("ReadAt(damaged frame) = %v, want ErrChecksumMismatch", err)
+	}
+
+	corrupt := bytes.Clone(sidecar.Bytes())
+	corrupt[6] ^= 0xff
+	if _, err := ReadFrameIndex(bytes.NewReader(corrupt)); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("ReadFrameIndex(corrupted) = %v, want ErrChecksumMismatch", err)
+	}
+
+	frame, _ := Compress(data)
+	if _, err := BuildIndex(bytes.NewReader(frame)); !errors.Is(err, ErrFrameInput) {
+		t.Errorf("BuildIndex(bare frame) = %v, want ErrFrameInput", err)
+	}
+}
+
+func TestBuildIndex(t *testing.T) {
+	data := append(datagen.Logs(30<<10), datagen.Random(8<<10)...)
+	var archive, sidecar bytes.Buffer
+	w, err := NewWriter(&archive, WithFrameSize(MinFrameSize), WithStoredFallback(true), WithIndexSidecar(&sidecar))
+	if err != nil {
+		t.Fatalf("NewWriter() failed: %v", err)
+	}
+	w.Write(data)
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	x, err := BuildIndex(bytes.NewReader(archive.Bytes()))
+	if err != nil {
+		t.Fatalf("BuildIndex() failed: %v", err)
+	}
+	want, err := ReadFrameIndex(bytes.NewReader(sidecar.Bytes()))
+	if err != nil {
+		t.Fatalf("ReadFrameIndex() failed: %v", err)
+	}
+	if !bytes.Equal(x.Serialize(), want.Serialize()) {
+		t.Errorf("BuildIndex() = %+v, want %+v", x.Frames, want.Frames)
+	}
+
+	// Serialize round-trips through ReadFrameIndex
+	back, err := ReadFrameIndex(bytes.NewReader(x.Serialize()))
+	if err != nil {
+		t.Fatalf("ReadFrameIndex() failed: %v", err)
+	}
+	r := NewIndexedReader(bytes.NewReader(archive.Bytes()), back)
+	got := make([]byte, len(data))
+	if n, err := r.ReadAt(got, 0); n != len(data) || err != nil || !bytes.Equal(got, data) {
+		t.Errorf("ReadAt(all) = %d, %v", n, err)
+	}
+
+	// A stream cut inside a frame is reported, one without its end marker is not
+	end := len(archive.Bytes()) - FrameHeaderSize
+	if x, err := BuildIndex(bytes.NewReader(archive.Bytes()[:end])); err != nil || len(x.Frames) != len(want.Frames) {
+		t.Errorf("BuildIndex(unterminated) = %v, %v", x, err)
+	}
+	if _, err := BuildIndex(bytes.NewReader(archive.Bytes()[:end-1])); err != io.ErrUnexpectedEOF {
+		t.Errorf("BuildIndex(truncated) = %v, want io.ErrUnexpectedEOF", err)
+	}
+}
@@ -0,0 +1,276 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/borischu/go-openzl/internal/cgo"
+)
+
+// globalConfig holds the package-wide defaults set by Init.
+type globalConfig struct {
+	level       int       // Default compression level, 0 for the library default
+	poolWorkers int       // Default Pool worker count, 0 for GOMAXPROCS
+	poolQueue   int       // Default Pool queue size, -1 for four jobs per worker
+	frameSize   int       // Default Writer frame size
+	chunkSize   int       // Compress input size above which it is chunked
+	untyped     bool      // Compress numeric data as plain bytes
+	alloc       Allocator // Provides large internal buffers
+
+	warn func(error) // Receives ignored options and dropped data, nil to discard
+	env  bool        // Apply environment overrides after the options
+}
+
+// InitOption configures the package-wide defaults applied by Init.
+type InitOption func(*globalConfig) error
+
+var (
+	globalOnce sync.Once
+	global     *globalConfig
+)
+
+// WithDefaultLevel sets the compression level used by every compression
+// context the package creates: one-shot functions, Compressors, Writers, and
+// Pools. Level 0 keeps the OpenZL library default; otherwise the level must
+// be between MinCompressionLevel and MaxCompressionLevel.
+// WithCompressionLevel overrides it for one Compressor.
+//
+// WithAdaptiveLevel still adjusts the level of its Writer starting from its
+// own midpoint.
+func WithDefaultLevel(level int) InitOption {
+	return func(cfg *globalConfig) error {
+		if level != 0 && (level < MinCompressionLevel || level > MaxCompressionLevel) {
+			return rangeError("WithDefaultLevel", level, 0, MaxCompressionLevel)
+		}
+		cfg.level = level
+		return nil
+	}
+}
+
+// WithDefaultPoolWorkers sets the number of workers of Pools created without
+// WithWorkers. If not specified, runtime.GOMAXPROCS(0) is used.
+func WithDefaultPoolWorkers(n int) InitOption {
+	return func(cfg *globalConfig) error {
+		if n < 1 {
+			return rangeError("WithDefaultPoolWorkers", n, 1, math.MaxInt64)
+		}
+		cfg.poolWorkers = n
+		return nil
+	}
+}
+
+// WithDefaultPoolQueueSize sets the queue size of Pools created without
+// WithQueueSize. If not specified, each priority queue holds four jobs per
+// worker.
+func WithDefaultPoolQueueSize(n int) InitOption {
+	return func(cfg *globalConfig) error {
+		if n < 0 {
+			return rangeError("WithDefaultPoolQueueSize", n, 0, math.MaxInt64)
+		}
+		cfg.poolQueue = n
+		return nil
+	}
+}
+
+// WithDefaultFrameSize sets the frame size of Writers created without
+// WithFrameSize or WithLongWindow. The size must be between MinFrameSize and
+// MaxFrameSize. If not specified, DefaultFrameSize is used.
+func WithDefaultFrameSize(size int) InitOption {
+	return func(cfg *globalConfig) error {
+		if size < MinFrameSize || size > MaxFrameSize {
+			return rangeError("WithDefaultFrameSize", size, MinFrameSize, MaxFrameSize)
+		}
+		cfg.frameSize = size
+		return nil
+	}
+}
+
+// WithChunkThreshold sets the input size above which Compress splits its
+// input into chunks of that size, each compressed into its own frame. The
+// threshold must be between MinChunkThreshold and MaxChunkThreshold, and at
+// most MaxInputSize for the chunks to fit in a frame. If not specified,
+// DefaultChunkThreshold is used.
+func WithChunkThreshold(n int) InitOption {
+	return func(cfg *globalConfig) error {
+		if n < MinChunkThreshold || n > MaxChunkThreshold {
+			return rangeError("WithChunkThreshold", n, MinChunkThreshold, MaxChunkThreshold)
+		}
+		cfg.chunkSize = n
+		return nil
+	}
+}
+
+// WithTypedCompression enables or disables typed compression of numeric
+// data. Disabled, CompressNumeric and CompressorCompressNumeric compress the
+// raw bytes of the slice like Compress does. The result usually compresses
+// worse, but decompresses with DecompressNumeric all the same, so typed
+// compression can be turned off in a deployed program without affecting data
+// already written. Typed compression is enabled by default.
+func WithTypedCompression(enabled bool) InitOption {
+	return func(cfg *globalConfig) error {
+		cfg.untyped = !enabled
+		return nil
+	}
+}
+
+// WithEnvOverrides makes Init read overrides of the defaults from the
+// environment, so that operators can tune a deployed program without code
+// changes:
+//
+//	OPENZL_LEVEL          compression level, as WithDefaultLevel
+//	OPENZL_FRAME_SIZE     Writer frame size in bytes, as WithDefaultFrameSize
+//	OPENZL_DISABLE_TYPED  true to disable typed compression, as
+//	                      WithTypedCompression(false)
+//
+// Unset or empty variables are ignored. Variables that are set take
+// precedence over the other options passed to Init, wherever this option
+// appears among them. The environment is read only by Init; a program that
+// does not call Init with this option is not affected by these variables.
+//
+// Example:
+//
+//	if err := openzl.Init(openzl.WithDefaultLevel(3), openzl.WithEnvOverrides()); err != nil {
+//		log.Fatal(err)
+//	}
+func WithEnvOverrides() InitOption {
+	return func(cfg *globalConfig) error {
+		cfg.env = true
+		return nil
+	}
+}
+
+// applyEnv applies the environment overrides documented at WithEnvOverrides.
+func applyEnv(cfg *globalConfig) error {
+	ints := []struct {
+		name string
+		opt  func(int) InitOption
+	}{
+		{"OPENZL_LEVEL", WithDefaultLevel},
+		{"OPENZL_FRAME_SIZE", WithDefaultFrameSize},
+	}
+	for _, v := range ints {
+		s := os.Getenv(v.name)
+		if s == "" {
+			continue
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("%s=%q: %w", v.name, s, ErrInvalidParameter)
+		}
+		if err := v.opt(n)(cfg); err != nil {
+			return fmt.Errorf("%s: %w", v.name, err)
+		}
+	}
+	if s := os.Getenv("OPENZL_DISABLE_TYPED"); s != "" {
+		disable, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("OPENZL_DISABLE_TYPED=%q: %w", s, ErrInvalidParameter)
+		}
+		cfg.untyped = disable
+	}
+	return nil
+}
+
+// WithWarnHandler sets a function that receives a warning whenever the
+// package ignores an option or drops data instead of failing, for example
+// when a later WithLongWindow overrides WithFrameSize, or when Reader.Reset
+// abandons a stream that was not read to its end.
+//
+// Warnings wrap ErrOptionIgnored or ErrUnconsumedData, so callers can tell
+// them apart with errors.Is. fn may be called from any goroutine and must be
+// safe for concurrent use. Without a handler, warnings are discarded.
+//
+// Example:
+//
+//	openzl.Init(openzl.WithWarnHandler(func(err error) {
+//		slog.Warn("openzl", "err", err)
+//	}))
+func WithWarnHandler(fn func(error)) InitOption {
+	return func(cfg *globalConfig) error {
+		cfg.warn = fn
+		return nil
+	}
+}
+
+// Init sets package-wide defaults. It must be called at most once, at
+// program start, before any other function of the package is used.
+//
+// Calling Init is optional: the package initializes itself lazily with the
+// library defaults on first use. Either way the configuration is fixed from
+// then on, so that concurrently running code never observes it changing.
+// Explicit per-object options, such as WithWorkers for a Pool, always take
+// precedence over these defaults.
+//
+// Example:
+//
+//	func main() {
+//		if err := openzl.Init(openzl.WithDefaultLevel(3), openzl.WithDefaultPoolWorkers(4)); err != nil {
+//			log.Fatal(err)
+//		}
+//		...
+//	}
+//
+// Returns ErrAlreadyInitialized if the configuration was already fixed by an
+// earlier Init call or by use of the package, or an error if any option is
+// invalid. A failed call leaves the package uninitialized.
+func Init(opts ...InitOption) error {
+	cfg := defaultGlobalConfig()
+	for _, opt := range opts {
+		if err := opt(cfg); err != nil {
+			return fmt.Errorf("apply option: %w", err)
+		}
+	}
+	if cfg.env {
+		if err := applyEnv(cfg); err != nil {
+			return fmt.Errorf("apply environment: %w", err)
+		}
+	}
+
+	applied := false
+	globalOnce.Do(func() {
+		global = cfg
+		applied = true
+	})
+	if !applied {
+		return ErrAlreadyInitialized
+	}
+	return nil
+}
+
+// defaultGlobalConfig returns the configuration used without Init.
+func defaultGlobalConfig() *globalConfig {
+	return &globalConfig{poolQueue: -1, frameSize: DefaultFrameSize, chunkSize: DefaultChunkThreshold, alloc: HeapAllocator}
+}
+
+// defaults returns the package-wide configuration, initializing it with the
+// library defaults if Init has not been called.
+func defaults() *globalConfig {
+	globalOnce.Do(func() {
+		global = defaultGlobalConfig()
+	})
+	return global
+}
+
+// warn passes err to the handler set with WithWarnHandler, if any.
+func warn(err error) {
+	if fn := defaults().warn; fn != nil {
+		fn(err)
+	}
+}
+
+// newCCtx creates a compression context configured with the package-wide
+// defaults.
+func newCCtx() (*cgo.CCtx, error) {
+	ctx, err := cgo.NewCCtx()
+	if err != nil {
+		return nil, err
+	}
+	ctx.SetCompressionLevel(defaults().level)
+	return ctx, nil
+}
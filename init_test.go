@@ -0,0 +1,211 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// resetDefaults makes the package uninitialized for the duration of a test.
+func resetDefaults(t *testing.T) {
+	globalOnce = sync.Once{}
+	global = nil
+	t.Cleanup(func() {
+		globalOnce = sync.Once{}
+		global = nil
+	})
+}
+
+func TestInit(t *testing.T) {
+	resetDefaults(t)
+
+	if err := Init(WithDefaultLevel(12)); err == nil {
+		t.Fatal("Init() accepted an invalid level")
+	}
+	if err := Init(WithDefaultLevel(3), WithDefaultPoolWorkers(3), WithDefaultPoolQueueSize(1)); err != nil {
+		t.Fatalf("Init() after failed call: %v", err)
+	}
+	if err := Init(); !errors.Is(err, ErrAlreadyInitialized) {
+		t.Errorf("second Init() error = %v, want ErrAlreadyInitialized", err)
+	}
+
+	pool, err := NewPool()
+	if err != nil {
+		t.Fatalf("NewPool() failed: %v", err)
+	}
+	defer pool.Close()
+	if got := pool.Workers(); got != 3 {
+		t.Errorf("Workers() = %d, want 3", got)
+	}
+
+	pool2, err := NewPool(WithWorkers(2))
+	if err != nil {
+		t.Fatalf("NewPool() failed: %v", err)
+	}
+	defer pool2.Close()
+	if got := pool2.Workers(); got != 2 {
+		t.Errorf("Workers() = %d, want explicit 2", got)
+	}
+
+	data := []byte("default level round trip")
+	compressed, err := Compress(data)
+	if err != nil {
+		t.Fatalf("Compress() failed: %v", err)
+	}
+	decompressed, err := Decompress(compressed)
+	if err != nil || string(decompressed) != string(data) {
+		t.Fatalf("round trip failed: %q, %v", decompressed, err)
+	}
+}
+
+func TestInit_AfterUse(t *testing.T) {
+	resetDefaults(t)
+
+	if _, err := Compress([]byte("lazy initialization")); err != nil {
+		t.Fatalf("Compress() failed: %v", err)
+	}
+	if err := Init(WithDefaultLevel(1)); !errors.Is(err, ErrAlreadyInitialized) {
+		t.Errorf("Init() after use error = %v, want ErrAlreadyInitialized", err)
+	}
+}
+
+func TestWithWarnHandler(t *testing.T) {
+	resetDefaults(t)
+
+	var mu sync.Mutex
+	var warnings []error
+	if err := Init(WithDefaultLevel(3), WithWarnHandler(func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		warnings = append(warnings, err)
+	})); err != nil {
+		t.Fatalf("Init() failed: %v", err)
+	}
+	take := func() []error {
+		mu.Lock()
+		defer mu.Unlock()
+		w := warnings
+		warnings = nil
+		return w
+	}
+
+	// Options without conflicts produce no warnings
+	w, err := NewWriter(io.Discard, WithFrameSize(MinFrameSize), WithFrameSize(MaxFrameSize))
+	if err != nil {
+		t.Fatalf("NewWriter() failed: %v", err)
+	}
+	w.Close()
+	if got := take(); len(got) != 0 {
+		t.Errorf("warnings = %v, want none", got)
+	}
+
+	for name, opts := range map[string][]WriterOption{
+		"frame size overridden":  {WithFrameSize(MinFrameSize), WithLongWindow(0)},
+		"long window overridden": {WithLongWindow(0), WithFrameSize(MinFrameSize)},
+		"adaptive level":         {WithAdaptiveLevel(true)},
+	} {
+		w, err := NewWriter(io.Discard, opts...)
+		if err != nil {
+			t.Fatalf("%s: NewWriter() failed: %v", name, err)
+		}
+		w.Close()
+		if got := take(); len(got) != 1 || !errors.Is(got[0], ErrOptionIgnored) {
+			t.Errorf("%s: warnings = %v, want one ErrOptionIgnored", name, got)
+		}
+	}
+
+	// A Reader reset before the end of its stream reports the dropped data
+	var stream bytes.Buffer
+	sw, _ := NewWriter(&stream)
+	sw.Write(bytes.Repeat([]byte("unread "), 1000))
+	sw.Close()
+	r, err := NewReader(bytes.NewReader(stream.Bytes()))
+	if err != nil {
+		t.Fatalf("NewReader() failed: %v", err)
+	}
+	defer r.Close()
+	if _, err := r.Read(make([]byte, 10)); err != nil {
+		t.Fatalf("Read() failed: %v", err)
+	}
+	if err := r.Reset(bytes.NewReader(stream.Bytes())); err != nil {
+		t.Fatalf("Reset() failed: %v", err)
+	}
+	if got := take(); len(got) != 1 || !errors.Is(got[0], ErrUnconsumedData) {
+		t.Errorf("Reset() warnings = %v, want one ErrUnconsumedData", got)
+	}
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		t.Fatalf("Copy() failed: %v", err)
+	}
+	if err := r.Reset(bytes.NewReader(stream.Bytes())); err != nil {
+		t.Fatalf("Reset() failed: %v", err)
+	}
+	if got := take(); len(got) != 0 {
+		t.Errorf("Reset() after full read warnings = %v, want none", got)
+	}
+}
+
+func TestWithEnvOverrides(t *testing.T) {
+	t.Setenv("OPENZL_LEVEL", "5")
+	t.Setenv("OPENZL_FRAME_SIZE", "131072")
+	t.Setenv("OPENZL_DISABLE_TYPED", "true")
+
+	resetDefaults(t)
+	if err := Init(WithDefaultLevel(2)); err != nil {
+		t.Fatalf("Init() failed: %v", err)
+	}
+	if cfg := defaults(); cfg.level != 2 || cfg.frameSize != DefaultFrameSize || cfg.untyped {
+		t.Errorf("environment applied without WithEnvOverrides: %+v", cfg)
+	}
+
+	resetDefaults(t)
+	if err := Init(WithEnvOverrides(), WithDefaultLevel(2)); err != nil {
+		t.Fatalf("Init() failed: %v", err)
+	}
+	if cfg := defaults(); cfg.level != 5 || cfg.frameSize != 131072 || !cfg.untyped {
+		t.Errorf("overrides not applied: %+v", cfg)
+	}
+
+	w, err := NewWriter(io.Discard)
+	if err != nil {
+		t.Fatalf("NewWriter() failed: %v", err)
+	}
+	if w.frameSize != 131072 {
+		t.Errorf("frame size = %d, want 131072", w.frameSize)
+	}
+	w.Close()
+
+	values := []int32{1, 2, 3, 5, 8, 13, 21}
+	compressed, err := CompressNumeric(values)
+	if err != nil {
+		t.Fatalf("CompressNumeric() failed: %v", err)
+	}
+	got, err := DecompressNumeric[int32](compressed)
+	if err != nil {
+		t.Fatalf("DecompressNumeric() failed: %v", err)
+	}
+	if !slices.Equal(got, values) {
+		t.Errorf("round trip = %v, want %v", got, values)
+	}
+
+	for _, env := range []struct{ name, value string }{
+		{"OPENZL_LEVEL", "high"},
+		{"OPENZL_FRAME_SIZE", "100"},
+		{"OPENZL_DISABLE_TYPED", "maybe"},
+	} {
+		t.Run(env.name, func(t *testing.T) {
+			t.Setenv(env.name, env.value)
+			resetDefaults(t)
+			err := Init(WithEnvOverrides())
+			if !errors.Is(err, ErrInvalidParameter) || !strings.Contains(err.Error(), env.name) {
+				t.Errorf("Init() error = %v, want invalid %s", err, env.name)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package cgo
+
+/*
+#include <stdlib.h>
+#include "zlgo_compat.h"
+
+// zlgo_compressBatch compresses n independent inputs in a single cgo
+// transition. Input i is written to dst + sum(dstCaps[0..i)) and its
+// compressed size is stored in outSizes[i].
+//
+// On failure, the error is returned and *failed holds the index of the
+// input that could not be compressed. A level of 0 keeps the library default,
+// and a NULL graph the default graph.
+static ZL_Report zlgo_compressBatch(ZL_CCtx* cctx, int level, ZL_Compressor* graph,
+        char* dst, const size_t* dstCaps,
+        const void* const* srcs, const size_t* srcSizes, size_t n,
+        size_t* outSizes, size_t* failed) {
+    for (size_t i = 0; i < n; i++) {
+        // OpenZL resets parameters after each compression
+        ZL_Report r = zlgo_setParameters(cctx, level, graph);
+        if (!ZL_isError(r)) {
+            r = ZL_CCtx_compress(cctx, dst, dstCaps[i], srcs[i], srcSizes[i]);
+        }
+        if (ZL_isError(r)) {
+            *failed = i;
+            return r;
+        }
+        outSizes[i] = ZL_validResult(r);
+        dst += dstCaps[i];
+    }
+    return ZL_returnSuccess();
+}
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"unsafe"
+
+	"github.com/borischu/go-openzl/internal/fault"
+)
+
+// CompressBatch compresses each of srcs independently using a single cgo
+// call, amortizing the transition cost across many small inputs.
+//
+// The dst buffer is split into consecutive regions of CompressBound(len(srcs[i]))
+// bytes, one per input, so it must be at least the sum of those bounds.
+// Compressed frame i starts at the beginning of region i; its length is
+// returned in sizes[i].
+//
+// The array of source pointers is handed to C in C-allocated memory, which
+// the cgo rules only permit for pinned Go memory, so each source is pinned
+// for the duration of the call.
+//
+// Returns an error if any source is empty, dst is too small, or compression
+// of any input fails. No partial results are returned.
+func (c *CCtx) CompressBatch(dst []byte, srcs [][]byte) ([]int, error) {
+	n := len(srcs)
+	if n == 0 {
+		return nil, nil
+	}
+	if err := fault.Check(fault.Compress); err != nil {
+		return nil, err
+	}
+
+	dstCaps := make([]C.size_t, n)
+	srcSizes := make([]C.size_t, n)
+	total := 0
+	for i, src := range srcs {
+		if len(src) == 0 {
+			return nil, fmt.Errorf("empty input at index %d", i)
+		}
+		if err := checkInput(uint64(len(src))); err != nil {
+			return nil, fmt.Errorf("input %d: %w", i, err)
+		}
+		bound := CompressBound(len(src))
+		dstCaps[i] = C.size_t(bound)
+		srcSizes[i] = C.size_t(len(src))
+		total += bound
+	}
+	if len(dst) < total {
+		return nil, errors.New("destination buffer too small for batch")
+	}
+
+	mem := C.malloc(C.size_t(n) * C.size_t(unsafe.Sizeof(uintptr(0))))
+	if mem == nil {
+		return nil, errors.New("failed to allocate batch pointers")
+	}
+	defer C.free(mem)
+	ptrs := unsafe.Slice((*unsafe.Pointer)(mem), n)
+
+	var pinner runtime.Pinner
+	defer pinner.Unpin()
+	for i, src := range srcs {
+		pinner.Pin(&src[0])
+		ptrs[i] = unsafe.Pointer(&src[0])
+	}
+
+	outSizes := make([]C.size_t, n)
+	var failed C.size_t
+	result := C.zlgo_compressBatch(
+		c.ctx,
+		C.int(c.level),
+		c.graphRef(),
+		(*C.char)(unsafe.Pointer(&dst[0])),
+		&dstCaps[0],
+		&ptrs[0],
+		&srcSizes[0],
+		C.size_t(n),
+		&outSizes[0],
+		&failed,
+	)
+	if C.ZL_isError(result) != 0 {
+		return nil, fmt.Errorf("input %d: %w", int(failed), c.getError(result))
+	}
+
+	sizes := make([]int, n)
+	for i, s := range outSizes {
+		sizes[i] = int(s)
+	}
+	return sizes, nil
+}
@@ -0,0 +1,15 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package cgo
+
+// Compiler flags shared by every file in this package.
+//
+// OpenZL headers are searched in vendor/openzl/include, the OpenZL checkout
+// that `make build-openzl` builds. Linker flags depend on how the library is
+// provided; see link_static.go and link_dynamic.go.
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../../vendor/openzl/include
+*/
+import "C"
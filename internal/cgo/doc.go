@@ -8,4 +8,46 @@
 //
 // The bindings in this package are thin wrappers around the OpenZL C API,
 // handling memory management, error translation, and type conversions.
+//
+// # Supported libopenzl Releases
+//
+// The bindings compile against several libopenzl releases. Differences
+// between releases are detected at build time by zlgo_compat.h, which turns
+// the presence of graph macros, optional headers, and version macros into
+// ZLGO_HAS_* capability macros. C code in this package includes that header
+// instead of the OpenZL headers, references optional symbols only behind a
+// ZLGO_HAS_* guard, and degrades gracefully when one is missing:
+// QueryFeatures reports the capability as unavailable and the functions that
+// need it return an error. Support for a new release is added to
+// zlgo_compat.h alone.
+//
+// # Memory Safety Model
+//
+// Go slices are passed to C without copying. The cgo pointer rules make this
+// safe as long as C does not retain a Go pointer after the call returns and
+// no Go pointer is stored in C memory. The bindings follow three rules:
+//
+//   - Single calls (Compress, Decompress, GetDecompressedSize) pass &slice[0]
+//     directly. Go's garbage collector does not move heap objects and the
+//     runtime keeps arguments alive for the duration of the call, so no extra
+//     work is needed.
+//   - TypedRef keeps a pointer to caller memory between cgo calls. The slice
+//     is pinned with runtime.Pinner when the TypedRef is created and unpinned
+//     by Free, so Free must always be called.
+//   - Batched calls (CCtx.CompressBatch) store pointers to many Go slices in a
+//     C-allocated array. Every slice is pinned for the duration of the call,
+//     which is what allows Go pointers to be placed in C memory.
+//
+// Most destination buffers are allocated by Go and sized up front, so large
+// buffers never cross the boundary more than once per operation. C memory
+// is allocated in two places, each with a single owner that frees it:
+//
+//   - DCtx.DecompressNative returns a NativeBuffer holding its output in
+//     memory from malloc. The caller owns it and must call Free; Bytes
+//     aliases that memory and is invalid afterwards.
+//   - CCtx.CompressAlloc and CCtx.CompressGather, and DCtx.DecompressScatter,
+//     write through scratch and gather buffers owned by the context. They
+//     grow on demand, are copied into Go memory before the call returns, and
+//     are freed after the call if they exceed maxRetainedScratch, or else by
+//     the context's Free. Go code never holds a pointer into them.
 package cgo
@@ -0,0 +1,76 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package cgo
+
+/*
+#include "zlgo_compat.h"
+
+// Capabilities are detected at build time by zlgo_compat.h; these functions
+// only expose its macros to Go.
+
+static int zlgo_hasNumeric(void) { return ZLGO_HAS_NUMERIC; }
+static int zlgo_hasStruct(void) { return ZLGO_HAS_STRUCT; }
+static int zlgo_hasString(void) { return ZLGO_HAS_STRING; }
+static int zlgo_hasSDDL(void) { return ZLGO_HAS_SDDL; }
+static int zlgo_hasTraining(void) { return ZLGO_HAS_TRAINING; }
+static int zlgo_hasIntrospection(void) { return ZLGO_HAS_INTROSPECTION; }
+static int zlgo_minFormatVersion(void) { return ZLGO_MIN_FORMAT_VERSION; }
+static int zlgo_maxFormatVersion(void) { return ZLGO_MAX_FORMAT_VERSION; }
+static int zlgo_versionMajor(void) { return ZLGO_VERSION_MAJOR; }
+static int zlgo_versionMinor(void) { return ZLGO_VERSION_MINOR; }
+static int zlgo_versionPatch(void) { return ZLGO_VERSION_PATCH; }
+*/
+import "C"
+import "fmt"
+
+// MaxContentSize is the largest content of a single frame the library
+// accepts, as detected by zlgo_compat.h.
+const MaxContentSize = C.ZLGO_MAX_CONTENT_SIZE
+
+// MinCompressionLevel and MaxCompressionLevel bound the levels accepted by
+// SetCompressionLevel, as detected by zlgo_compat.h.
+const (
+	MinCompressionLevel = C.ZLGO_MIN_COMPRESSION_LEVEL
+	MaxCompressionLevel = C.ZLGO_MAX_COMPRESSION_LEVEL
+)
+
+// Features describes the graphs and codecs available in the linked
+// OpenZL library.
+type Features struct {
+	Numeric          bool   // ZL_GRAPH_NUMERIC for numeric typed inputs
+	Struct           bool   // ZL_GRAPH_FIELD_LZ for fixed-width struct inputs
+	String           bool   // Variable-length string typed inputs
+	SDDL             bool   // Simple Data Description Language graphs
+	Training         bool   // Compressor serialization used by trained profiles
+	Introspection    bool   // Compression hooks used by per-stage reports
+	MinFormatVersion int    // Oldest wire format the library can produce
+	MaxFormatVersion int    // Newest wire format the library can produce
+	Version          string // Library release, "" if the headers do not say
+}
+
+// QueryFeatures reports the features compiled into the linked OpenZL library.
+//
+// The result is fixed at build time, so callers may cache it.
+func QueryFeatures() Features {
+	return Features{
+		Numeric:          C.zlgo_hasNumeric() != 0,
+		Struct:           C.zlgo_hasStruct() != 0,
+		String:           C.zlgo_hasString() != 0,
+		SDDL:             C.zlgo_hasSDDL() != 0,
+		Training:         C.zlgo_hasTraining() != 0,
+		Introspection:    C.zlgo_hasIntrospection() != 0,
+		MinFormatVersion: int(C.zlgo_minFormatVersion()),
+		MaxFormatVersion: int(C.zlgo_maxFormatVersion()),
+		Version:          libraryVersion(),
+	}
+}
+
+// libraryVersion formats the release from zl_version.h as "major.minor.patch".
+func libraryVersion() string {
+	major, minor, patch := int(C.zlgo_versionMajor()), int(C.zlgo_versionMinor()), int(C.zlgo_versionPatch())
+	if major == 0 && minor == 0 && patch == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d.%d.%d", major, minor, patch)
+}
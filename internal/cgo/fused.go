@@ -0,0 +1,444 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package cgo
+
+/*
+#include <stdlib.h>
+#include <string.h>
+#include "zlgo_compat.h"
+
+// zlgo_compressScratch compresses src into *scratch, growing it to
+// ZL_compressBound(srcSize) first if needed, so that a compression costs a
+// single cgo transition. *oom is set if the buffer could not be grown.
+static ZL_Report zlgo_compressScratch(ZL_CCtx* cctx, int level, ZL_Compressor* graph,
+        void** scratch, size_t* scratchCap,
+        const void* src, size_t srcSize, int* oom) {
+    size_t bound = ZL_compressBound(srcSize);
+    if (*scratchCap < bound) {
+        void* p = realloc(*scratch, bound);
+        if (p == NULL) {
+            *oom = 1;
+            return ZL_returnSuccess();
+        }
+        *scratch = p;
+        *scratchCap = bound;
+    }
+    ZL_Report r = zlgo_setParameters(cctx, level, graph);
+    if (ZL_isError(r)) {
+        return r;
+    }
+    return ZL_CCtx_compress(cctx, *scratch, *scratchCap, src, srcSize);
+}
+
+// zlgo_compressGather copies the n fragments srcs[i] into *gather, growing it
+// to their total size first if needed, and compresses the result into
+// *scratch as zlgo_compressScratch does. OpenZL only accepts contiguous
+// input, so this is the one copy a fragmented input costs.
+static ZL_Report zlgo_compressGather(ZL_CCtx* cctx, int level, ZL_Compressor* graph,
+        void** scratch, size_t* scratchCap,
+        void** gather, size_t* gatherCap,
+        const void* const* srcs, const size_t* srcSizes, size_t n, int* oom) {
+    size_t total = 0;
+    for (size_t i = 0; i < n; i++) {
+        total += srcSizes[i];
+    }
+    if (*gatherCap < total) {
+        void* p = realloc(*gather, total);
+        if (p == NULL) {
+            *oom = 1;
+            return ZL_returnSuccess();
+        }
+        *gather = p;
+        *gatherCap = total;
+    }
+    char* dst = *gather;
+    for (size_t i = 0; i < n; i++) {
+        memcpy(dst, srcs[i], srcSizes[i]);
+        dst += srcSizes[i];
+    }
+    return zlgo_compressScratch(cctx, level, graph, scratch, scratchCap, *gather, total, oom);
+}
+
+// zlgo_decompressSized reads the decompressed size of src into *needed and,
+// if it fits in dstCap, decompresses in the same cgo transition. *needed is
+// left at 0 if the frame header cannot be read.
+static ZL_Report zlgo_decompressSized(ZL_DCtx* dctx,
+        void* dst, size_t dstCap,
+        const void* src, size_t srcSize, size_t* needed) {
+    ZL_Report r = ZL_getDecompressedSize(src, srcSize);
+    if (ZL_isError(r)) {
+        return r;
+    }
+    *needed = ZL_validResult(r);
+    if (*needed > dstCap) {
+        return ZL_returnSuccess();
+    }
+    return ZL_DCtx_decompress(dctx, dst, dstCap, src, srcSize);
+}
+
+// zlgo_decompressScatter decompresses src across the n buffers dsts[i], in
+// order. The decompressed size is stored in *needed; if it exceeds the total
+// capacity, nothing is decompressed. Output that fits in dsts[0] is
+// decompressed there directly; otherwise it goes through *scratch, grown as
+// needed, and is copied out. *oom is set if scratch could not be grown.
+static ZL_Report zlgo_decompressScatter(ZL_DCtx* dctx,
+        void** scratch, size_t* scratchCap,
+        void* const* dsts, const size_t* dstCaps, size_t n,
+        const void* src, size_t srcSize, size_t* needed, int* oom) {
+    ZL_Report r = ZL_getDecompressedSize(src, srcSize);
+    if (ZL_isError(r)) {
+        return r;
+    }
+    *needed = ZL_validResult(r);
+    size_t total = 0;
+    for (size_t i = 0; i < n; i++) {
+        total += dstCaps[i];
+    }
+    if (*needed > total || *needed == 0) {
+        return ZL_returnSuccess();
+    }
+    if (dstCaps[0] >= *needed) {
+        return ZL_DCtx_decompress(dctx, dsts[0], dstCaps[0], src, srcSize);
+    }
+    if (*scratchCap < *needed) {
+        void* p = realloc(*scratch, *needed);
+        if (p == NULL) {
+            *oom = 1;
+            return ZL_returnSuccess();
+        }
+        *scratch = p;
+        *scratchCap = *needed;
+    }
+    r = ZL_DCtx_decompress(dctx, *scratch, *scratchCap, src, srcSize);
+    if (ZL_isError(r)) {
+        return r;
+    }
+    const char* out = *scratch;
+    size_t left = ZL_validResult(r);
+    for (size_t i = 0; i < n && left > 0; i++) {
+        size_t k = dstCaps[i] < left ? dstCaps[i] : left;
+        memcpy(dsts[i], out, k);
+        out += k;
+        left -= k;
+    }
+    return r;
+}
+*/
+import "C"
+import (
+	"errors"
+	"runtime"
+	"unsafe"
+
+	"github.com/borischu/go-openzl/internal/fault"
+)
+
+// maxRetainedScratch is the largest scratch or gather buffer a context keeps
+// between calls. Larger ones, grown for an unusually large input, are freed
+// after the call, so that a long-lived cached context does not pin them.
+const maxRetainedScratch = 8 << 20
+
+// CompressAlloc compresses src and returns the compressed frame in a newly
+// allocated slice of exactly its size.
+//
+// Unlike Compress, it needs no CompressBound call and no bound-sized Go
+// buffer: the frame is written to a C buffer owned by the context, which is
+// grown on demand and reused across calls up to maxRetainedScratch, and then
+// copied out. A compression therefore costs one cgo transition instead of
+// two.
+func (c *CCtx) CompressAlloc(src []byte) ([]byte, error) {
+	if len(src) == 0 {
+		return nil, errors.New("empty input")
+	}
+	if err := checkInput(uint64(len(src))); err != nil {
+		return nil, err
+	}
+	if err := fault.Check(fault.Compress); err != nil {
+		return nil, err
+	}
+	defer c.trimScratch()
+
+	scratch := c.scratch
+	scratchCap := C.size_t(c.scratchCap)
+	var oom C.int
+	result := C.zlgo_compressScratch(
+		c.ctx,
+		C.int(c.level),
+		c.graphRef(),
+		&scratch,
+		&scratchCap,
+		unsafe.Pointer(&src[0]),
+		C.size_t(len(src)),
+		&oom,
+	)
+	c.scratch = scratch
+	c.scratchCap = int(scratchCap)
+
+	if oom != 0 {
+		return nil, errors.New("failed to allocate compression buffer")
+	}
+	if C.ZL_isError(result) != 0 {
+		return nil, c.getError(result)
+	}
+	n, err := toInt(uint64(C.ZL_validResult(result)))
+	if err != nil {
+		return nil, err
+	}
+	return goBytes(c.scratch, n), nil
+}
+
+// CompressGather compresses the concatenation of srcs, as CompressAlloc
+// would compress a single slice holding it, and returns the frame in a newly
+// allocated slice.
+//
+// The fragments are gathered in a second C buffer owned by the context, in
+// the same cgo transition as the compression, so no contiguous copy of the
+// input is ever made in Go memory. As in CompressBatch, the array of source
+// pointers lives in C memory, so each source is pinned for the call. Empty
+// fragments are skipped.
+func (c *CCtx) CompressGather(srcs [][]byte) ([]byte, error) {
+	n, total := 0, uint64(0)
+	for _, src := range srcs {
+		if len(src) > 0 {
+			n++
+			total += uint64(len(src))
+		}
+	}
+	if n == 0 {
+		return nil, errors.New("empty input")
+	}
+	if err := checkInput(total); err != nil {
+		return nil, err
+	}
+	if err := fault.Check(fault.Compress); err != nil {
+		return nil, err
+	}
+
+	mem := C.malloc(C.size_t(n) * C.size_t(unsafe.Sizeof(uintptr(0))))
+	if mem == nil {
+		return nil, errors.New("failed to allocate fragment pointers")
+	}
+	defer C.free(mem)
+	ptrs := unsafe.Slice((*unsafe.Pointer)(mem), n)
+	sizes := make([]C.size_t, n)
+
+	var pinner runtime.Pinner
+	defer pinner.Unpin()
+	defer c.trimScratch()
+	i := 0
+	for _, src := range srcs {
+		if len(src) == 0 {
+			continue
+		}
+		pinner.Pin(&src[0])
+		ptrs[i] = unsafe.Pointer(&src[0])
+		sizes[i] = C.size_t(len(src))
+		i++
+	}
+
+	scratch, gather := c.scratch, c.gather
+	scratchCap, gatherCap := C.size_t(c.scratchCap), C.size_t(c.gatherCap)
+	var oom C.int
+	result := C.zlgo_compressGather(
+		c.ctx,
+		C.int(c.level),
+		c.graphRef(),
+		&scratch,
+		&scratchCap,
+		&gather,
+		&gatherCap,
+		&ptrs[0],
+		&sizes[0],
+		C.size_t(n),
+		&oom,
+	)
+	c.scratch, c.gather = scratch, gather
+	c.scratchCap, c.gatherCap = int(scratchCap), int(gatherCap)
+
+	if oom != 0 {
+		return nil, errors.New("failed to allocate compression buffer")
+	}
+	if C.ZL_isError(result) != 0 {
+		return nil, c.getError(result)
+	}
+	n, err := toInt(uint64(C.ZL_validResult(result)))
+	if err != nil {
+		return nil, err
+	}
+	return goBytes(c.scratch, n), nil
+}
+
+// DecompressScatter decompresses src across dsts, filling each in order, in
+// one cgo transition.
+//
+// It returns the decompressed size in needed. If needed exceeds the total
+// length of dsts, nothing is decompressed and n is 0. Output that fits in
+// the first non-empty destination is decompressed there directly; larger
+// output is decompressed into a C buffer owned by the context and copied
+// out. Empty destinations are skipped.
+//
+// On error, needed is 0 if the frame header could not be read, and the
+// decompressed size otherwise.
+func (d *DCtx) DecompressScatter(dsts [][]byte, src []byte) (n, needed int, err error) {
+	if len(src) == 0 {
+		return 0, 0, errors.New("empty input")
+	}
+	if err := fault.Check(fault.Decompress); err != nil {
+		return 0, 0, err
+	}
+
+	count := 0
+	for _, dst := range dsts {
+		if len(dst) > 0 {
+			count++
+		}
+	}
+	// Keep at least one entry so that the arrays can be passed to C; a
+	// zero-capacity destination only lets the size be read
+	mem := C.malloc(C.size_t(max(count, 1)) * C.size_t(unsafe.Sizeof(uintptr(0))))
+	if mem == nil {
+		return 0, 0, errors.New("failed to allocate destination pointers")
+	}
+	defer C.free(mem)
+	ptrs := unsafe.Slice((*unsafe.Pointer)(mem), max(count, 1))
+	caps := make([]C.size_t, max(count, 1))
+
+	var pinner runtime.Pinner
+	defer pinner.Unpin()
+	i, total := 0, 0
+	for _, dst := range dsts {
+		if len(dst) == 0 {
+			continue
+		}
+		pinner.Pin(&dst[0])
+		ptrs[i] = unsafe.Pointer(&dst[0])
+		caps[i] = C.size_t(len(dst))
+		total += len(dst)
+		i++
+	}
+
+	defer d.trimScratch()
+	scratch := d.scratch
+	scratchCap := C.size_t(d.scratchCap)
+	var size C.size_t
+	var oom C.int
+	result := C.zlgo_decompressScatter(
+		d.ctx,
+		&scratch,
+		&scratchCap,
+		&ptrs[0],
+		&caps[0],
+		C.size_t(count),
+		unsafe.Pointer(&src[0]),
+		C.size_t(len(src)),
+		&size,
+		&oom,
+	)
+	d.scratch = scratch
+	d.scratchCap = int(scratchCap)
+
+	needed, err = toInt(uint64(size))
+	if err != nil {
+		return 0, 0, err
+	}
+	if oom != 0 {
+		return 0, needed, errors.New("failed to allocate decompression buffer")
+	}
+	if C.ZL_isError(result) != 0 {
+		return 0, needed, d.getError(result)
+	}
+	if needed > total || needed == 0 {
+		return 0, needed, nil
+	}
+	return int(C.ZL_validResult(result)), needed, nil
+}
+
+// freeScratch releases the buffers allocated by CompressAlloc and
+// CompressGather, if any.
+func (c *CCtx) freeScratch() {
+	if c.scratch != nil {
+		C.free(c.scratch)
+		c.scratch = nil
+		c.scratchCap = 0
+	}
+	if c.gather != nil {
+		C.free(c.gather)
+		c.gather = nil
+		c.gatherCap = 0
+	}
+}
+
+// trimScratch releases the buffers of CompressAlloc and CompressGather that
+// grew beyond maxRetainedScratch.
+func (c *CCtx) trimScratch() {
+	if c.scratchCap > maxRetainedScratch {
+		C.free(c.scratch)
+		c.scratch = nil
+		c.scratchCap = 0
+	}
+	if c.gatherCap > maxRetainedScratch {
+		C.free(c.gather)
+		c.gather = nil
+		c.gatherCap = 0
+	}
+}
+
+// DecompressSized reads the decompressed size of src and, if it fits in dst,
+// decompresses src into dst, all in one cgo transition.
+//
+// It returns the decompressed size in needed. If needed exceeds len(dst),
+// nothing is decompressed and n is 0; the caller can allocate needed bytes and
+// call Decompress. dst may be empty to only query the size.
+//
+// On error, needed is 0 if the frame header could not be read, and the
+// decompressed size otherwise.
+func (d *DCtx) DecompressSized(dst, src []byte) (n, needed int, err error) {
+	if len(src) == 0 {
+		return 0, 0, errors.New("empty input")
+	}
+	if err := fault.Check(fault.Decompress); err != nil {
+		return 0, 0, err
+	}
+
+	var dstPtr unsafe.Pointer
+	if len(dst) > 0 {
+		dstPtr = unsafe.Pointer(&dst[0])
+	}
+	var size C.size_t
+	result := C.zlgo_decompressSized(
+		d.ctx,
+		dstPtr,
+		C.size_t(len(dst)),
+		unsafe.Pointer(&src[0]),
+		C.size_t(len(src)),
+		&size,
+	)
+	needed, err = toInt(uint64(size))
+	if err != nil {
+		return 0, 0, err
+	}
+	if C.ZL_isError(result) != 0 {
+		return 0, needed, d.getError(result)
+	}
+	return int(C.ZL_validResult(result)), needed, nil
+}
+
+// freeScratch releases the buffer allocated by DecompressScatter, if any.
+func (d *DCtx) freeScratch() {
+	if d.scratch != nil {
+		C.free(d.scratch)
+		d.scratch = nil
+		d.scratchCap = 0
+	}
+}
+
+// trimScratch releases the buffer of DecompressScatter if it grew beyond
+// maxRetainedScratch.
+func (d *DCtx) trimScratch() {
+	if d.scratchCap > maxRetainedScratch {
+		C.free(d.scratch)
+		d.scratch = nil
+		d.scratchCap = 0
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package cgo
+
+/*
+#include "zlgo_compat.h"
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"unsafe"
+)
+
+// Kinds of the standard nodes custom graphs are built from.
+const (
+	NodeDelta           = C.ZLGO_NODE_DELTA
+	NodeZigzag          = C.ZLGO_NODE_ZIGZAG
+	NodeTokenize        = C.ZLGO_NODE_TOKENIZE
+	NodeTransposeSplit2 = C.ZLGO_NODE_TRANSPOSE_SPLIT2
+	NodeTransposeSplit4 = C.ZLGO_NODE_TRANSPOSE_SPLIT4
+	NodeTransposeSplit8 = C.ZLGO_NODE_TRANSPOSE_SPLIT8
+	NodeInterpretLE16   = C.ZLGO_NODE_INTERPRET_LE16
+	NodeInterpretLE32   = C.ZLGO_NODE_INTERPRET_LE32
+	NodeInterpretLE64   = C.ZLGO_NODE_INTERPRET_LE64
+)
+
+// Kinds of the standard graphs that end custom graphs.
+const (
+	GraphStore   = C.ZLGO_GRAPH_STORE
+	GraphEntropy = C.ZLGO_GRAPH_ENTROPY
+	GraphHuffman = C.ZLGO_GRAPH_HUFFMAN
+	GraphFSE     = C.ZLGO_GRAPH_FSE
+	GraphZstd    = C.ZLGO_GRAPH_ZSTD
+	GraphBitpack = C.ZLGO_GRAPH_BITPACK
+	GraphFieldLZ = C.ZLGO_GRAPH_FIELD_LZ
+	GraphGeneric = C.ZLGO_GRAPH_GENERIC
+	GraphNumeric = C.ZLGO_GRAPH_NUMERIC
+)
+
+// NodeAvailable reports whether the linked library has the node of kind.
+func NodeAvailable(kind int) bool {
+	var id C.ZL_NodeID
+	return C.zlgo_node(C.int(kind), &id) != 0
+}
+
+// GraphAvailable reports whether the linked library has the graph of kind.
+func GraphAvailable(kind int) bool {
+	var id C.ZL_GraphID
+	return C.zlgo_graph(C.int(kind), &id) != 0
+}
+
+// GraphTarget is where an output of a node goes: another node of the spec
+// if Graph is 0, or the standard graph of kind Graph.
+type GraphTarget struct {
+	Node  int
+	Graph int
+}
+
+// GraphNode is a node of a GraphSpec and the targets of its outputs, in
+// output order.
+type GraphNode struct {
+	Kind    int
+	Outputs []GraphTarget
+}
+
+// GraphSpec describes a custom graph: a directed acyclic graph of nodes
+// whose input enters at Nodes[Start].
+type GraphSpec struct {
+	Nodes []GraphNode
+	Start int
+}
+
+// Graph is a custom compressor graph (ZL_Compressor), built once and shared
+// by any number of contexts. Its C memory is released once it is no longer
+// reachable.
+type Graph struct {
+	compressor *C.ZL_Compressor
+}
+
+// NewGraph registers the static graphs of spec, from its last nodes up to
+// its start node, on a new ZL_Compressor.
+//
+// Returns an error if a node or graph is not available in the linked
+// library or OpenZL rejects the graph, for example because a node does not
+// accept the type its predecessor outputs.
+func NewGraph(spec GraphSpec) (*Graph, error) {
+	if spec.Start < 0 || spec.Start >= len(spec.Nodes) {
+		return nil, errors.New("invalid start node")
+	}
+	compressor := C.ZL_Compressor_create()
+	if compressor == nil {
+		return nil, errors.New("failed to create ZL_Compressor")
+	}
+
+	ids := make([]C.ZL_GraphID, len(spec.Nodes))
+	built := make([]bool, len(spec.Nodes))
+	var build func(i int) (C.ZL_GraphID, error)
+	build = func(i int) (C.ZL_GraphID, error) {
+		if built[i] {
+			return ids[i], nil
+		}
+		n := spec.Nodes[i]
+		var node C.ZL_NodeID
+		if C.zlgo_node(C.int(n.Kind), &node) == 0 {
+			return ids[i], fmt.Errorf("node %d: kind %d not available", i, n.Kind)
+		}
+		dsts := make([]C.ZL_GraphID, len(n.Outputs))
+		for j, t := range n.Outputs {
+			if t.Graph != 0 {
+				if C.zlgo_graph(C.int(t.Graph), &dsts[j]) == 0 {
+					return ids[i], fmt.Errorf("node %d output %d: graph kind %d not available", i, j, t.Graph)
+				}
+				continue
+			}
+			id, err := build(t.Node)
+			if err != nil {
+				return ids[i], err
+			}
+			dsts[j] = id
+		}
+		var dst *C.ZL_GraphID
+		if len(dsts) > 0 {
+			dst = &dsts[0]
+		}
+		ids[i] = C.ZL_Compressor_registerStaticGraph_fromNode(compressor, node, dst, C.size_t(len(dsts)))
+		if C.zlgo_graphIsValid(ids[i]) == 0 {
+			return ids[i], fmt.Errorf("node %d: openzl: graph rejected", i)
+		}
+		built[i] = true
+		return ids[i], nil
+	}
+
+	start, err := build(spec.Start)
+	if err != nil {
+		C.ZL_Compressor_free(compressor)
+		return nil, err
+	}
+	return startGraph(compressor, start)
+}
+
+// NewSDDLGraph returns a graph that parses its input with the compiled SDDL
+// description and compresses the fields it finds with the generic graph.
+//
+// Returns an error if the linked library has no SDDL support or rejects the
+// description.
+func NewSDDLGraph(description []byte) (*Graph, error) {
+	if len(description) == 0 {
+		return nil, errors.New("empty description")
+	}
+	compressor := C.ZL_Compressor_create()
+	if compressor == nil {
+		return nil, errors.New("failed to create ZL_Compressor")
+	}
+	var start C.ZL_GraphID
+	if C.zlgo_sddlGraph(compressor, unsafe.Pointer(&description[0]), C.size_t(len(description)), &start) == 0 {
+		C.ZL_Compressor_free(compressor)
+		return nil, errors.New("openzl: SDDL description rejected")
+	}
+	return startGraph(compressor, start)
+}
+
+// startGraph selects start as the starting graph of compressor and wraps it
+// in a Graph, or frees compressor on error.
+func startGraph(compressor *C.ZL_Compressor, start C.ZL_GraphID) (*Graph, error) {
+	result := C.ZL_Compressor_selectStartingGraphID(compressor, start)
+	if C.ZL_isError(result) != 0 {
+		C.ZL_Compressor_free(compressor)
+		errName := C.GoString(C.ZL_ErrorCode_toString(C.ZL_errorCode(result)))
+		return nil, fmt.Errorf("select starting graph: openzl: %s", errName)
+	}
+
+	g := &Graph{compressor: compressor}
+	runtime.AddCleanup(g, func(c *C.ZL_Compressor) { C.ZL_Compressor_free(c) }, compressor)
+	return g, nil
+}
+
+// SetGraph makes subsequent single-input compressions, serial or typed, use
+// g instead of the library's default graph, or of the numeric graph for
+// typed inputs. A nil g restores them. Multi-input compression always uses
+// the default graph.
+func (c *CCtx) SetGraph(g *Graph) {
+	c.graph = g
+}
+
+// graphRef returns the compressor of the context's custom graph, or nil.
+func (c *CCtx) graphRef() *C.ZL_Compressor {
+	if c.graph == nil {
+		return nil
+	}
+	return c.graph.compressor
+}
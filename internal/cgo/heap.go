@@ -0,0 +1,35 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package cgo
+
+/*
+#include <stdlib.h>
+
+// mallinfo2 is only available in glibc 2.33 and later. Elsewhere the heap
+// size is reported as unknown.
+#if defined(__GLIBC__) && (__GLIBC__ > 2 || (__GLIBC__ == 2 && __GLIBC_MINOR__ >= 33))
+#include <malloc.h>
+
+static long long zlgo_heapInUse(void) {
+    struct mallinfo2 mi = mallinfo2();
+    return (long long)(mi.uordblks + mi.hblkhd);
+}
+#else
+static long long zlgo_heapInUse(void) {
+    return -1;
+}
+#endif
+*/
+import "C"
+
+// HeapInUse returns the number of bytes currently allocated from the C heap,
+// including memory held by OpenZL contexts and buffers.
+//
+// Go's memory statistics do not cover C allocations, so this is the only way
+// to observe native leaks from Go. It reports false if the C library does not
+// provide the statistic (only glibc 2.33 and later do).
+func HeapInUse() (int64, bool) {
+	n := int64(C.zlgo_heapInUse())
+	return n, n >= 0
+}
@@ -0,0 +1,10 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+//go:build openzl_dynamic && openzl_static
+
+package cgo
+
+// The link mode tags are mutually exclusive. This undefined identifier turns
+// a conflicting combination into a compile error that names the problem.
+const _ = openzl_dynamic_cannot_be_combined_with_openzl_static
@@ -0,0 +1,18 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+//go:build openzl_dynamic
+
+package cgo
+
+// Link dynamically against a system-wide libopenzl located with pkg-config,
+// for distribution packages that must not bundle their own copy. Set
+// PKG_CONFIG_PATH if openzl.pc is installed in a non-standard location.
+
+/*
+#cgo pkg-config: openzl
+*/
+import "C"
+
+// LinkMode names how the OpenZL library is provided to this build.
+const LinkMode = "dynamic"
@@ -0,0 +1,17 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+//go:build !openzl_dynamic
+
+package cgo
+
+// Link statically against the libraries produced by `make build-openzl`.
+// This is the default; the openzl_static tag selects it explicitly.
+
+/*
+#cgo LDFLAGS: ${SRCDIR}/../../vendor/openzl/lib/libopenzl.a ${SRCDIR}/../../vendor/openzl/lib/libzstd.a -lm -lpthread
+*/
+import "C"
+
+// LinkMode names how the OpenZL library is provided to this build.
+const LinkMode = "static"
@@ -0,0 +1,159 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package cgo
+
+/*
+#include <stdlib.h>
+#include "zlgo_compat.h"
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"github.com/borischu/go-openzl/internal/fault"
+)
+
+// NewTypedRefSerial creates a TypedRef for opaque bytes.
+//
+// Like NewTypedRefNumeric, the data slice is pinned until Free is called.
+//
+// Returns an error if data is empty or TypedRef creation fails.
+func NewTypedRefSerial(data []byte) (*TypedRef, error) {
+	if len(data) == 0 {
+		return nil, errors.New("empty data slice")
+	}
+	if err := checkInput(uint64(len(data))); err != nil {
+		return nil, err
+	}
+
+	t := &TypedRef{elementSize: 1}
+	t.pinner.Pin(&data[0])
+	t.ref = C.ZL_TypedRef_createSerial(unsafe.Pointer(&data[0]), C.size_t(len(data)))
+	if t.ref == nil {
+		t.pinner.Unpin()
+		return nil, errors.New("failed to create TypedRef")
+	}
+	return t, nil
+}
+
+// CompressMultiTypedRef compresses several typed inputs into a single frame.
+//
+// The inputs keep their types and order, so DecompressMulti returns them as
+// separate outputs. The frame is compressed with the library's default
+// graph, which accepts any number of inputs of any type; unlike
+// CompressTypedRef, no single-input numeric or custom graph is attached.
+//
+// Returns the number of bytes written to dst, or an error if trefs or dst
+// is empty or compression fails.
+func (c *CCtx) CompressMultiTypedRef(dst []byte, trefs []*TypedRef) (int, error) {
+	if len(trefs) == 0 {
+		return 0, errors.New("no inputs")
+	}
+	if len(dst) == 0 {
+		return 0, errors.New("empty destination buffer")
+	}
+	if err := fault.Check(fault.Compress); err != nil {
+		return 0, err
+	}
+
+	// The array holds C pointers only, so it may live in C memory
+	mem := C.malloc(C.size_t(len(trefs)) * C.size_t(unsafe.Sizeof(uintptr(0))))
+	if mem == nil {
+		return 0, errors.New("failed to allocate input array")
+	}
+	defer C.free(mem)
+	refs := unsafe.Slice((**C.ZL_TypedRef)(mem), len(trefs))
+	for i, t := range trefs {
+		if t == nil || t.ref == nil {
+			return 0, fmt.Errorf("nil TypedRef at index %d", i)
+		}
+		refs[i] = t.ref
+	}
+
+	if err := c.setParameters(nil); err != nil {
+		return 0, err
+	}
+	result := C.ZL_CCtx_compressMultiTypedRef(
+		c.ctx,
+		unsafe.Pointer(&dst[0]),
+		C.size_t(len(dst)),
+		(**C.ZL_TypedRef)(mem),
+		C.size_t(len(trefs)),
+	)
+	if C.ZL_isError(result) != 0 {
+		return 0, c.getError(result)
+	}
+	return int(C.ZL_validResult(result)), nil
+}
+
+// DecompressMulti decompresses every output of a frame, such as one written
+// by CompressMultiTypedRef, and returns the raw bytes of each. Numeric
+// outputs are returned in native byte order; BytesToTypedSlice converts them.
+//
+// Returns an error if src is empty, is not a valid frame, or decompression
+// fails.
+func (d *DCtx) DecompressMulti(src []byte) ([][]byte, error) {
+	if len(src) == 0 {
+		return nil, errors.New("empty input")
+	}
+	if err := fault.Check(fault.Decompress); err != nil {
+		return nil, err
+	}
+
+	info := C.ZL_FrameInfo_create(unsafe.Pointer(&src[0]), C.size_t(len(src)))
+	if info == nil {
+		return nil, errors.New("openzl: invalid frame header")
+	}
+	result := C.ZL_FrameInfo_getNumOutputs(info)
+	C.ZL_FrameInfo_free(info)
+	if C.ZL_isError(result) != 0 {
+		return nil, d.getError(result)
+	}
+	n := int(C.ZL_validResult(result))
+	if n == 0 {
+		return nil, nil
+	}
+
+	mem := C.calloc(C.size_t(n), C.size_t(unsafe.Sizeof(uintptr(0))))
+	if mem == nil {
+		return nil, errors.New("failed to allocate output array")
+	}
+	defer C.free(mem)
+	bufs := unsafe.Slice((**C.ZL_TypedBuffer)(mem), n)
+	defer func() {
+		for _, b := range bufs {
+			if b != nil {
+				C.ZL_TypedBuffer_free(b)
+			}
+		}
+	}()
+	for i := range bufs {
+		if bufs[i] = C.ZL_TypedBuffer_create(); bufs[i] == nil {
+			return nil, errors.New("failed to create output buffer")
+		}
+	}
+
+	result = C.ZL_DCtx_decompressMultiTBuffer(
+		d.ctx,
+		(**C.ZL_TypedBuffer)(mem),
+		C.size_t(n),
+		unsafe.Pointer(&src[0]),
+		C.size_t(len(src)),
+	)
+	if C.ZL_isError(result) != 0 {
+		return nil, d.getError(result)
+	}
+
+	outs := make([][]byte, n)
+	for i, b := range bufs {
+		size, err := toInt(uint64(C.ZL_TypedBuffer_byteSize(b)))
+		if err != nil {
+			return nil, fmt.Errorf("output %d: %w", i, err)
+		}
+		outs[i] = goBytes(C.ZL_TypedBuffer_rPtr(b), size)
+	}
+	return outs, nil
+}
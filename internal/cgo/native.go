@@ -0,0 +1,107 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package cgo
+
+/*
+#include <stdlib.h>
+#include "zlgo_compat.h"
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"github.com/borischu/go-openzl/internal/fault"
+)
+
+// NativeBuffer holds decompressed data in C memory.
+//
+// Large outputs held in C memory do not grow the Go heap, so they do not
+// raise the garbage collector's target or linger until the next collection.
+// The buffer must be released with Free as soon as it is no longer needed.
+type NativeBuffer struct {
+	ptr unsafe.Pointer // C allocation, nil after Free
+	n   int            // Number of valid bytes
+}
+
+// Bytes returns the decompressed data. The slice aliases C memory and must
+// not be used after Free.
+func (b *NativeBuffer) Bytes() []byte {
+	if b.ptr == nil {
+		return nil
+	}
+	return unsafe.Slice((*byte)(b.ptr), b.n)
+}
+
+// Free releases the C memory. Calling Free multiple times is safe.
+func (b *NativeBuffer) Free() {
+	if b.ptr != nil {
+		C.free(b.ptr)
+		b.ptr = nil
+		b.n = 0
+	}
+}
+
+// DecompressNative decompresses src into a newly allocated C buffer.
+//
+// Returns an error if the frame header cannot be read, the buffer cannot be
+// allocated, or decompression fails.
+func (d *DCtx) DecompressNative(src []byte) (*NativeBuffer, error) {
+	size, err := GetDecompressedSize(src)
+	if err != nil {
+		return nil, err
+	}
+	if size == 0 {
+		return &NativeBuffer{}, nil
+	}
+	if err := fault.Check(fault.Alloc); err != nil {
+		return nil, fmt.Errorf("failed to allocate decompression buffer: %w", err)
+	}
+
+	ptr := C.malloc(C.size_t(size))
+	if ptr == nil {
+		return nil, errors.New("failed to allocate decompression buffer")
+	}
+	if err := fault.Check(fault.Decompress); err != nil {
+		C.free(ptr)
+		return nil, err
+	}
+
+	result := C.ZL_DCtx_decompress(
+		d.ctx,
+		ptr,
+		C.size_t(size),
+		unsafe.Pointer(&src[0]),
+		C.size_t(len(src)),
+	)
+	if C.ZL_isError(result) != 0 {
+		C.free(ptr)
+		return nil, d.getError(result)
+	}
+
+	return &NativeBuffer{ptr: ptr, n: int(C.ZL_validResult(result))}, nil
+}
+
+// Malloc allocates n bytes of C memory and returns them as a byte slice. The
+// memory is not zeroed and must be released with FreeBytes. Malloc panics if
+// the allocation fails, like make does.
+func Malloc(n int) []byte {
+	if n <= 0 {
+		return nil
+	}
+	ptr := C.malloc(C.size_t(n))
+	if ptr == nil {
+		panic(fmt.Sprintf("openzl: C allocation of %d bytes failed", n))
+	}
+	return unsafe.Slice((*byte)(ptr), n)
+}
+
+// FreeBytes releases memory returned by Malloc. b must start at the
+// beginning of the allocation.
+func FreeBytes(b []byte) {
+	if cap(b) > 0 {
+		C.free(unsafe.Pointer(unsafe.SliceData(b)))
+	}
+}
@@ -4,16 +4,17 @@
 package cgo
 
 /*
-#cgo CFLAGS: -I${SRCDIR}/../../vendor/openzl/include
-#cgo LDFLAGS: ${SRCDIR}/../../vendor/openzl/lib/libopenzl.a ${SRCDIR}/../../vendor/openzl/lib/libzstd.a -lm -lpthread
 #include <stdlib.h>
-#include <openzl/openzl.h>
+#include "zlgo_compat.h"
 */
 import "C"
 import (
 	"errors"
 	"fmt"
+	"math"
 	"unsafe"
+
+	"github.com/borischu/go-openzl/internal/fault"
 )
 
 // CCtx wraps the OpenZL C compression context (ZL_CCtx).
@@ -24,7 +25,15 @@ import (
 // The context must be freed with Free() when no longer needed to avoid
 // memory leaks.
 type CCtx struct {
-	ctx *C.ZL_CCtx // Underlying OpenZL compression context
+	ctx    *C.ZL_CCtx     // Underlying OpenZL compression context
+	report unsafe.Pointer // Optional per-codec report (C memory), see EnableReport
+	level  int            // Compression level, 0 for the library default
+	graph  *Graph         // Custom graph for single-input compression, nil for the default
+
+	scratch    unsafe.Pointer // Output buffer (C memory) for CompressAlloc
+	scratchCap int            // Capacity of scratch in bytes
+	gather     unsafe.Pointer // Input buffer (C memory) for CompressGather
+	gatherCap  int            // Capacity of gather in bytes
 }
 
 // NewCCtx creates a new compression context.
@@ -36,6 +45,9 @@ type CCtx struct {
 // Returns an error if the underlying C context creation fails or if
 // the format version cannot be set.
 func NewCCtx() (*CCtx, error) {
+	if err := fault.Check(fault.Alloc); err != nil {
+		return nil, fmt.Errorf("failed to create compression context: %w", err)
+	}
 	ctx := C.ZL_CCtx_create()
 	if ctx == nil {
 		return nil, errors.New("failed to create compression context")
@@ -62,6 +74,8 @@ func (c *CCtx) Free() {
 		C.ZL_CCtx_free(c.ctx)
 		c.ctx = nil
 	}
+	c.freeReport()
+	c.freeScratch()
 }
 
 // Compress compresses src into dst using the OpenZL C API.
@@ -88,15 +102,20 @@ func (c *CCtx) Compress(dst, src []byte) (int, error) {
 	if len(dst) == 0 {
 		return 0, errors.New("empty destination buffer")
 	}
+	if err := checkInput(uint64(len(src))); err != nil {
+		return 0, err
+	}
+	if err := fault.Check(fault.Compress); err != nil {
+		return 0, err
+	}
 
 	// OpenZL resets parameters after each compression, so we must
-	// re-set the format version before each compress call
-	result := C.ZL_CCtx_setParameter(c.ctx, C.ZL_CParam_formatVersion, C.ZL_MAX_FORMAT_VERSION)
-	if C.ZL_isError(result) != 0 {
-		return 0, c.getError(result)
+	// re-set them before each compress call
+	if err := c.setParameters(c.graphRef()); err != nil {
+		return 0, err
 	}
 
-	result = C.ZL_CCtx_compress(
+	result := C.ZL_CCtx_compress(
 		c.ctx,
 		unsafe.Pointer(&dst[0]),
 		C.size_t(len(dst)),
@@ -111,6 +130,28 @@ func (c *CCtx) Compress(dst, src []byte) (int, error) {
 	return int(C.ZL_validResult(result)), nil
 }
 
+// SetCompressionLevel sets the compression level applied by subsequent
+// Compress calls. Level 0 restores the library default.
+//
+// Like the format version, the level is re-applied before every compression,
+// including typed and batch compression, because OpenZL resets parameters
+// afterwards.
+func (c *CCtx) SetCompressionLevel(level int) {
+	c.level = level
+}
+
+// setParameters applies the format version, compression level, and graph
+// to the context. OpenZL resets parameters after each compression, so this
+// must be called before every compression. A nil graph keeps the library's
+// default graph.
+func (c *CCtx) setParameters(graph *C.ZL_Compressor) error {
+	result := C.zlgo_setParameters(c.ctx, C.int(c.level), graph)
+	if C.ZL_isError(result) != 0 {
+		return c.getError(result)
+	}
+	return nil
+}
+
 // getError translates an OpenZL C error Result into a Go error.
 //
 // OpenZL uses a Result type (ZL_Report) that can contain either a value
@@ -131,6 +172,9 @@ func (c *CCtx) getError(result C.ZL_Report) error {
 // memory leaks.
 type DCtx struct {
 	ctx *C.ZL_DCtx // Underlying OpenZL decompression context
+
+	scratch    unsafe.Pointer // Output buffer (C memory) for DecompressScatter
+	scratchCap int            // Capacity of scratch in bytes
 }
 
 // NewDCtx creates a new decompression context.
@@ -140,6 +184,9 @@ type DCtx struct {
 //
 // Returns an error if the underlying C context creation fails.
 func NewDCtx() (*DCtx, error) {
+	if err := fault.Check(fault.Alloc); err != nil {
+		return nil, fmt.Errorf("failed to create decompression context: %w", err)
+	}
 	ctx := C.ZL_DCtx_create()
 	if ctx == nil {
 		return nil, errors.New("failed to create decompression context")
@@ -156,6 +203,7 @@ func (d *DCtx) Free() {
 		C.ZL_DCtx_free(d.ctx)
 		d.ctx = nil
 	}
+	d.freeScratch()
 }
 
 // Decompress decompresses src into dst using the OpenZL C API.
@@ -180,6 +228,9 @@ func (d *DCtx) Decompress(dst, src []byte) (int, error) {
 	if len(dst) == 0 {
 		return 0, errors.New("empty destination buffer")
 	}
+	if err := fault.Check(fault.Decompress); err != nil {
+		return 0, err
+	}
 
 	result := C.ZL_DCtx_decompress(
 		d.ctx,
@@ -217,7 +268,19 @@ func (d *DCtx) getError(result C.ZL_Report) error {
 //   - src is empty
 //   - src does not contain a valid OpenZL compressed frame
 //   - the frame header is corrupted
+//   - the size does not fit in an int (ErrTooLarge, on 32-bit platforms)
 func GetDecompressedSize(src []byte) (int, error) {
+	size, err := GetDecompressedSize64(src)
+	if err != nil {
+		return 0, err
+	}
+	return toInt(size)
+}
+
+// GetDecompressedSize64 is like GetDecompressedSize but returns the size
+// without converting it to an int, so that it can be inspected on 32-bit
+// platforms even when the frame is too large to decompress in memory.
+func GetDecompressedSize64(src []byte) (uint64, error) {
 	if len(src) == 0 {
 		return 0, errors.New("empty input")
 	}
@@ -233,7 +296,7 @@ func GetDecompressedSize(src []byte) (int, error) {
 		return 0, fmt.Errorf("openzl: %s", errName)
 	}
 
-	return int(C.ZL_validResult(result)), nil
+	return uint64(C.ZL_validResult(result)), nil
 }
 
 // CompressBound returns the maximum possible compressed size for input of the given size.
@@ -247,6 +310,64 @@ func GetDecompressedSize(src []byte) (int, error) {
 //	dst := make([]byte, cgo.CompressBound(len(src)))
 //	n, err := ctx.Compress(dst, src)
 //	compressed := dst[:n]
+//
+// Returns -1 if the bound does not fit in an int, which only happens for
+// inputs close to the maximum int, that is 2GB on 32-bit platforms.
 func CompressBound(srcSize int) int {
-	return int(C.ZL_compressBound(C.size_t(srcSize)))
+	bound := uint64(C.ZL_compressBound(C.size_t(srcSize)))
+	// On 32-bit platforms the C computation can wrap around
+	if bound > math.MaxInt || bound < uint64(srcSize) {
+		return -1
+	}
+	return int(bound)
+}
+
+// FrameFormatVersion returns the OpenZL format version encoded in the magic
+// number at the start of src.
+//
+// Only the first few bytes are inspected, so this is a cheap way to tell
+// whether src begins with an OpenZL frame at all.
+//
+// Returns an error if src is empty or does not start with an OpenZL magic number.
+func FrameFormatVersion(src []byte) (int, error) {
+	if len(src) == 0 {
+		return 0, errors.New("empty input")
+	}
+
+	result := C.ZL_getFormatVersionFromFrame(
+		unsafe.Pointer(&src[0]),
+		C.size_t(len(src)),
+	)
+
+	if C.ZL_isError(result) != 0 {
+		errCode := C.ZL_errorCode(result)
+		errName := C.GoString(C.ZL_ErrorCode_toString(errCode))
+		return 0, fmt.Errorf("openzl: %s", errName)
+	}
+
+	return int(C.ZL_validResult(result)), nil
+}
+
+// HeaderSize returns the size in bytes of the OpenZL frame header at the
+// start of src, that is, the offset at which the frame's payload begins.
+//
+// Returns an error if src is empty, does not start with an OpenZL frame, or
+// is too short to contain the whole header.
+func HeaderSize(src []byte) (int, error) {
+	if len(src) == 0 {
+		return 0, errors.New("empty input")
+	}
+
+	result := C.ZL_getHeaderSize(
+		unsafe.Pointer(&src[0]),
+		C.size_t(len(src)),
+	)
+
+	if C.ZL_isError(result) != 0 {
+		errCode := C.ZL_errorCode(result)
+		errName := C.GoString(C.ZL_ErrorCode_toString(errCode))
+		return 0, fmt.Errorf("openzl: %s", errName)
+	}
+
+	return int(C.ZL_validResult(result)), nil
 }
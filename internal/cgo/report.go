@@ -0,0 +1,167 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package cgo
+
+/*
+#include <stdlib.h>
+#include <string.h>
+#include "zlgo_compat.h"
+
+#define ZLGO_MAX_STAGES 64
+#define ZLGO_MAX_NAME 64
+
+// zlgo_stage records the bytes entering and leaving one codec invocation.
+typedef struct {
+    char name[ZLGO_MAX_NAME];
+    size_t in;
+    size_t out;
+} zlgo_stage;
+
+// zlgo_report accumulates stages for the most recent compression.
+// Stages beyond ZLGO_MAX_STAGES are counted but not recorded.
+typedef struct {
+    zlgo_stage stages[ZLGO_MAX_STAGES];
+    size_t nbStages;
+    size_t dropped;
+} zlgo_report;
+
+#if ZLGO_HAS_INTROSPECTION
+
+static void zlgo_onCompressStart(void* opaque, ZL_CCtx* cctx,
+        void* dst, size_t dstCapacity, const ZL_TypedRef* inputs[], size_t nbInputs) {
+    (void)cctx; (void)dst; (void)dstCapacity; (void)inputs; (void)nbInputs;
+    zlgo_report* r = (zlgo_report*)opaque;
+    r->nbStages = 0;
+    r->dropped = 0;
+}
+
+static void zlgo_onCodecStart(void* opaque, ZL_Encoder* eictx,
+        const ZL_Compressor* compressor, ZL_NodeID nid,
+        const ZL_Input* inStreams[], size_t nbInStreams) {
+    (void)eictx;
+    zlgo_report* r = (zlgo_report*)opaque;
+    if (r->nbStages >= ZLGO_MAX_STAGES) {
+        r->dropped++;
+        return;
+    }
+    zlgo_stage* s = &r->stages[r->nbStages];
+    const char* name = ZL_Compressor_Node_getName(compressor, nid);
+    strncpy(s->name, name ? name : "unknown", ZLGO_MAX_NAME - 1);
+    s->name[ZLGO_MAX_NAME - 1] = '\0';
+    s->in = 0;
+    s->out = 0;
+    for (size_t i = 0; i < nbInStreams; i++) {
+        s->in += ZL_Input_contentSize(inStreams[i]);
+    }
+}
+
+static void zlgo_onCodecEnd(void* opaque, ZL_Encoder* eictx,
+        const ZL_Output* outStreams[], size_t nbOutputs, ZL_Report codecExecResult) {
+    (void)eictx;
+    zlgo_report* r = (zlgo_report*)opaque;
+    if (r->dropped > 0 || r->nbStages >= ZLGO_MAX_STAGES) {
+        return;
+    }
+    zlgo_stage* s = &r->stages[r->nbStages];
+    if (!ZL_isError(codecExecResult)) {
+        for (size_t i = 0; i < nbOutputs; i++) {
+            ZL_Report size = ZL_Output_contentSize((ZL_Output*)outStreams[i]);
+            if (!ZL_isError(size)) {
+                s->out += ZL_validResult(size);
+            }
+        }
+    }
+    r->nbStages++;
+}
+
+static ZL_Report zlgo_attachReport(ZL_CCtx* cctx, zlgo_report* r) {
+    ZL_CompressIntrospectionHooks hooks;
+    memset(&hooks, 0, sizeof(hooks));
+    hooks.opaque = r;
+    hooks.on_ZL_CCtx_compressMultiTypedRef_start = zlgo_onCompressStart;
+    hooks.on_codecEncode_start = zlgo_onCodecStart;
+    hooks.on_codecEncode_end = zlgo_onCodecEnd;
+    return ZL_CCtx_attachIntrospectionHooks(cctx, &hooks);
+}
+#else
+// Never called: EnableReport checks ZLGO_HAS_INTROSPECTION first.
+static ZL_Report zlgo_attachReport(ZL_CCtx* cctx, zlgo_report* r) {
+    (void)cctx; (void)r;
+    return ZL_returnSuccess();
+}
+#endif
+*/
+import "C"
+import (
+	"errors"
+	"unsafe"
+)
+
+// Stage describes the data volume entering and leaving one codec invocation
+// during compression.
+type Stage struct {
+	Name       string // Codec (node) name as registered in the graph
+	InputSize  int    // Total bytes across all input streams
+	OutputSize int    // Total bytes across all output streams
+}
+
+// EnableReport attaches introspection hooks that record a per-codec size
+// report for every subsequent compression on this context.
+//
+// The report buffer is allocated in C memory and freed by Free.
+func (c *CCtx) EnableReport() error {
+	if c.ctx == nil {
+		return errors.New("context freed")
+	}
+	if c.report != nil {
+		return nil
+	}
+	if C.ZLGO_HAS_INTROSPECTION == 0 {
+		return errors.New("stage reports require a libopenzl with introspection hooks")
+	}
+
+	report := (*C.zlgo_report)(C.calloc(1, C.sizeof_zlgo_report))
+	if report == nil {
+		return errors.New("failed to allocate report")
+	}
+
+	result := C.zlgo_attachReport(c.ctx, report)
+	if C.ZL_isError(result) != 0 {
+		C.free(unsafe.Pointer(report))
+		return c.getError(result)
+	}
+
+	c.report = unsafe.Pointer(report)
+	return nil
+}
+
+// Report returns the stages recorded during the most recent compression.
+//
+// Returns nil if EnableReport has not been called.
+func (c *CCtx) Report() []Stage {
+	if c.report == nil {
+		return nil
+	}
+
+	report := (*C.zlgo_report)(c.report)
+	n := int(report.nbStages)
+	stages := make([]Stage, n)
+	for i := 0; i < n; i++ {
+		s := &report.stages[i]
+		stages[i] = Stage{
+			Name:       C.GoString(&s.name[0]),
+			InputSize:  int(s.in),
+			OutputSize: int(s.out),
+		}
+	}
+	return stages
+}
+
+// freeReport releases the report buffer, if any.
+func (c *CCtx) freeReport() {
+	if c.report != nil {
+		C.free(c.report)
+		c.report = nil
+	}
+}
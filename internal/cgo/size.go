@@ -0,0 +1,53 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package cgo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"unsafe"
+)
+
+// ErrTooLarge is returned when a size reported by OpenZL does not fit in an
+// int. On 64-bit platforms that cannot happen for data in memory; on 32-bit
+// platforms it happens for frames that decompress to 2GB or more.
+var ErrTooLarge = errors.New("openzl: size exceeds the int range of this platform")
+
+// ErrInputTooLarge is returned, before any C call, for inputs larger than
+// MaxContentSize.
+var ErrInputTooLarge = errors.New("openzl: input exceeds the maximum frame content size")
+
+// checkInput returns ErrInputTooLarge if n bytes do not fit in one frame.
+func checkInput(n uint64) error {
+	if uint64(n) > MaxContentSize {
+		return fmt.Errorf("%w: %d bytes, maximum is %d", ErrInputTooLarge, n, uint64(MaxContentSize))
+	}
+	return nil
+}
+
+// Go sizes convert to size_t without loss, since size_t is at least as wide
+// as int on every platform Go supports. Counts of bytes written to a Go
+// buffer convert back to int without loss too, being at most its length.
+// Other sizes, read from frame headers or computed by OpenZL, must go
+// through toInt.
+
+// toInt converts a size reported by OpenZL to an int, or returns ErrTooLarge
+// if it does not fit.
+func toInt(n uint64) (int, error) {
+	if n > math.MaxInt {
+		return 0, fmt.Errorf("%w: %d bytes", ErrTooLarge, n)
+	}
+	return int(n), nil
+}
+
+// goBytes copies n bytes of C memory at p into a new Go slice. Unlike
+// C.GoBytes, whose length is a C int, it copies 2GB or more.
+func goBytes(p unsafe.Pointer, n int) []byte {
+	if n == 0 {
+		return []byte{}
+	}
+	return bytes.Clone(unsafe.Slice((*byte)(p), n))
+}
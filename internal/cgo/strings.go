@@ -0,0 +1,105 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package cgo
+
+/*
+#include "zlgo_compat.h"
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"github.com/borischu/go-openzl/internal/fault"
+)
+
+// NewTypedRefString creates a TypedRef for an array of variable-length
+// strings, stored back to back in content, lengths[i] being the length of
+// the i-th. Compress it with CompressMultiTypedRef, whose default graph
+// accepts string inputs.
+//
+// Like NewTypedRefNumeric, content and lengths are pinned until Free is
+// called.
+//
+// Returns an error if lengths is empty, does not add up to len(content),
+// the linked library cannot compress strings, or TypedRef creation fails.
+func NewTypedRefString(content []byte, lengths []uint32) (*TypedRef, error) {
+	if len(lengths) == 0 {
+		return nil, errors.New("empty data slice")
+	}
+	var total uint64
+	for _, n := range lengths {
+		total += uint64(n)
+	}
+	if total != uint64(len(content)) {
+		return nil, fmt.Errorf("string lengths add up to %d, content holds %d bytes", total, len(content))
+	}
+	if err := checkInput(total + uint64(len(lengths))*4); err != nil {
+		return nil, err
+	}
+	if len(content) == 0 {
+		// Every string is empty; the buffer pointer must still be valid
+		content = make([]byte, 1)[:0]
+	}
+
+	t := &TypedRef{elementSize: 1}
+	t.pinner.Pin(unsafe.SliceData(content))
+	t.pinner.Pin(&lengths[0])
+	t.ref = C.zlgo_createString(
+		unsafe.Pointer(unsafe.SliceData(content)),
+		C.size_t(len(content)),
+		(*C.uint32_t)(unsafe.Pointer(&lengths[0])),
+		C.size_t(len(lengths)),
+	)
+	if t.ref == nil {
+		t.pinner.Unpin()
+		return nil, errors.New("failed to create string TypedRef")
+	}
+	return t, nil
+}
+
+// DecompressStrings decompresses a frame holding a single string output,
+// such as one written from a NewTypedRefString reference, and returns the
+// strings stored back to back and their lengths.
+//
+// Returns an error if src is empty, is not a valid frame, does not hold
+// strings, or decompression fails.
+func (d *DCtx) DecompressStrings(src []byte) ([]byte, []uint32, error) {
+	if len(src) == 0 {
+		return nil, nil, errors.New("empty input")
+	}
+	if err := fault.Check(fault.Decompress); err != nil {
+		return nil, nil, err
+	}
+
+	b := C.ZL_TypedBuffer_create()
+	if b == nil {
+		return nil, nil, errors.New("failed to create output buffer")
+	}
+	defer C.ZL_TypedBuffer_free(b)
+
+	result := C.ZL_DCtx_decompressTBuffer(d.ctx, b, unsafe.Pointer(&src[0]), C.size_t(len(src)))
+	if C.ZL_isError(result) != 0 {
+		return nil, nil, d.getError(result)
+	}
+	lens := C.zlgo_stringLens(b)
+	if lens == nil {
+		return nil, nil, errors.New("output does not hold strings")
+	}
+
+	n, err := toInt(uint64(C.ZL_TypedBuffer_numElts(b)))
+	if err != nil {
+		return nil, nil, err
+	}
+	size, err := toInt(uint64(C.ZL_TypedBuffer_byteSize(b)))
+	if err != nil {
+		return nil, nil, err
+	}
+	lengths := make([]uint32, n)
+	if n > 0 {
+		copy(lengths, unsafe.Slice((*uint32)(unsafe.Pointer(lens)), n))
+	}
+	return goBytes(C.ZL_TypedBuffer_rPtr(b), size), lengths, nil
+}
@@ -5,13 +5,12 @@ package cgo
 
 /*
 #include <stdlib.h>
-#include <openzl/openzl.h>
-#include <openzl/codecs/zl_generic.h>
+#include "zlgo_compat.h"
 
-// Simple graph function that returns the ZL_GRAPH_NUMERIC graph for numeric compression
+// Simple graph function that returns the numeric graph for numeric compression
 ZL_GraphID numericGraphFn(ZL_Compressor* compressor) {
     (void)compressor; // unused
-    return ZL_GRAPH_NUMERIC;
+    return zlgo_numericGraph();
 }
 
 // Helper to get the numeric graph function pointer
@@ -23,7 +22,10 @@ import "C"
 import (
 	"errors"
 	"fmt"
+	"runtime"
 	"unsafe"
+
+	"github.com/borischu/go-openzl/internal/fault"
 )
 
 // TypedRef wraps the OpenZL ZL_TypedRef for typed compression.
@@ -33,9 +35,14 @@ import (
 // ratios (2-5x) on structured data compared to untyped byte compression.
 //
 // The TypedRef must be freed with Free() when no longer needed.
+//
+// ZL_TypedRef keeps a pointer to the Go slice between cgo calls, which the
+// cgo pointer rules only allow for pinned memory. The slice is therefore
+// pinned with a runtime.Pinner until Free is called.
 type TypedRef struct {
 	ref         *C.ZL_TypedRef // Underlying OpenZL typed reference
 	elementSize int            // Size of each element in bytes
+	pinner      runtime.Pinner // Keeps the referenced Go slice pinned
 }
 
 // NewTypedRefNumeric creates a TypedRef for a numeric array.
@@ -45,7 +52,8 @@ type TypedRef struct {
 //
 // Supported element sizes: 1, 2, 4, 8 bytes (int8, int16, int32, int64, etc.)
 //
-// The data slice must remain valid for the lifetime of the TypedRef.
+// The data slice must remain valid for the lifetime of the TypedRef. It is
+// pinned until Free is called, so Free must always be called.
 //
 // Returns an error if:
 //   - data is empty
@@ -63,22 +71,26 @@ func NewTypedRefNumeric[T any](data []T) (*TypedRef, error) {
 	if elementSize != 1 && elementSize != 2 && elementSize != 4 && elementSize != 8 {
 		return nil, fmt.Errorf("unsupported element size: %d (must be 1, 2, 4, or 8)", elementSize)
 	}
+	if err := checkInput(uint64(len(data)) * uint64(elementSize)); err != nil {
+		return nil, err
+	}
+
+	t := &TypedRef{elementSize: elementSize}
+	t.pinner.Pin(&data[0])
 
 	// Create TypedRef using OpenZL's numeric array API
-	ref := C.ZL_TypedRef_createNumeric(
+	t.ref = C.ZL_TypedRef_createNumeric(
 		unsafe.Pointer(&data[0]),
 		C.size_t(elementSize),
 		C.size_t(len(data)),
 	)
 
-	if ref == nil {
+	if t.ref == nil {
+		t.pinner.Unpin()
 		return nil, errors.New("failed to create TypedRef")
 	}
 
-	return &TypedRef{
-		ref:         ref,
-		elementSize: elementSize,
-	}, nil
+	return t, nil
 }
 
 // ElementSize returns the size of each element in bytes.
@@ -95,6 +107,7 @@ func (t *TypedRef) Free() {
 		C.ZL_TypedRef_free(t.ref)
 		t.ref = nil
 	}
+	t.pinner.Unpin()
 }
 
 // CompressTypedRef compresses data using a TypedRef for format-aware compression.
@@ -121,39 +134,41 @@ func (c *CCtx) CompressTypedRef(dst []byte, tref *TypedRef) (int, error) {
 	if tref == nil || tref.ref == nil {
 		return 0, errors.New("nil TypedRef")
 	}
+	if err := fault.Check(fault.Compress); err != nil {
+		return 0, err
+	}
 
-	// Create a compression graph (required for typed compression)
-	// This is what we were missing! Found in test_generic_clustering.cpp
-	compressor := C.ZL_Compressor_create()
+	// Typed compression requires a compressor graph: the custom graph set
+	// with SetGraph, or the numeric graph
+	compressor := c.graphRef()
 	if compressor == nil {
-		return 0, errors.New("failed to create ZL_Compressor")
-	}
-	defer C.ZL_Compressor_free(compressor)
+		// This is what we were missing! Found in test_generic_clustering.cpp
+		compressor = C.ZL_Compressor_create()
+		if compressor == nil {
+			return 0, errors.New("failed to create ZL_Compressor")
+		}
+		defer C.ZL_Compressor_free(compressor)
 
-	// Initialize the compressor with the numeric graph function
-	// This sets up the graph structure needed for typed compression
-	result := C.ZL_Compressor_initUsingGraphFn(compressor, C.getNumericGraphFn())
-	if C.ZL_isError(result) != 0 {
-		return 0, c.getError(result)
+		// Initialize the compressor with the numeric graph function
+		// This sets up the graph structure needed for typed compression
+		result := C.ZL_Compressor_initUsingGraphFn(compressor, C.getNumericGraphFn())
+		if C.ZL_isError(result) != 0 {
+			return 0, c.getError(result)
+		}
 	}
 
 	// Reset parameters to clean state before typed compression
-	result = C.ZL_CCtx_resetParameters(c.ctx)
-	if C.ZL_isError(result) != 0 {
-		return 0, c.getError(result)
-	}
-
-	// Set format version (required by OpenZL before each compression)
-	result = C.ZL_CCtx_setParameter(c.ctx, C.ZL_CParam_formatVersion, C.ZL_MAX_FORMAT_VERSION)
+	result := C.ZL_CCtx_resetParameters(c.ctx)
 	if C.ZL_isError(result) != 0 {
 		return 0, c.getError(result)
 	}
 
-	// Link the compression context to the compressor graph
-	// This is the critical missing step discovered from OpenZL examples!
-	result = C.ZL_CCtx_refCompressor(c.ctx, compressor)
-	if C.ZL_isError(result) != 0 {
-		return 0, c.getError(result)
+	// Set format version and level (required by OpenZL before each
+	// compression), and link the compression context to the compressor
+	// graph. This is the critical missing step discovered from OpenZL
+	// examples!
+	if err := c.setParameters(compressor); err != nil {
+		return 0, err
 	}
 
 	// Compress using typed reference (should now work!)
@@ -171,10 +186,28 @@ func (c *CCtx) CompressTypedRef(dst []byte, tref *TypedRef) (int, error) {
 	return int(C.ZL_validResult(result)), nil
 }
 
+// OutputType is the type of a decompressed output, as recorded in the frame.
+type OutputType int
+
+const (
+	OutputSerial  = OutputType(C.ZL_Type_serial)  // Untyped bytes
+	OutputStruct  = OutputType(C.ZL_Type_struct)  // Fixed-width records
+	OutputNumeric = OutputType(C.ZL_Type_numeric) // Numbers of 1, 2, 4 or 8 bytes
+	OutputString  = OutputType(C.ZL_Type_string)  // Variable-length strings
+)
+
+// OutputInfo describes the output of a typed frame.
+type OutputInfo struct {
+	Type     OutputType // Type of the output
+	Width    int        // Element width in bytes; 1 for serial outputs
+	Elements int        // Number of elements
+}
+
 // DecompressTypedToBytes decompresses data that was compressed with typed compression.
 //
 // This method decompresses data compressed with OpenZL's typed API and returns
-// the result as a byte slice. The caller is responsible for converting the bytes
+// the result as a byte slice, with the type and element width recorded in the
+// frame. The caller is responsible for checking them and converting the bytes
 // to the appropriate typed slice.
 //
 // For typed compression, we must use ZL_DCtx_decompressTyped() instead of
@@ -184,19 +217,27 @@ func (c *CCtx) CompressTypedRef(dst []byte, tref *TypedRef) (int, error) {
 //   - src is empty
 //   - src does not contain valid OpenZL compressed data
 //   - the decompression operation fails
-func (d *DCtx) DecompressTypedToBytes(src []byte) ([]byte, error) {
+func (d *DCtx) DecompressTypedToBytes(src []byte) ([]byte, OutputInfo, error) {
 	if len(src) == 0 {
-		return nil, errors.New("empty input")
+		return nil, OutputInfo{}, errors.New("empty input")
+	}
+	if err := fault.Check(fault.Decompress); err != nil {
+		return nil, OutputInfo{}, err
 	}
 
 	// Get decompressed size from frame header
 	dstSize, err := GetDecompressedSize(src)
 	if err != nil {
-		return nil, fmt.Errorf("get decompressed size: %w", err)
+		return nil, OutputInfo{}, fmt.Errorf("get decompressed size: %w", err)
 	}
 
 	// Allocate byte buffer for decompression
 	dstBytes := make([]byte, dstSize)
+	if dstSize == 0 {
+		// The frame declares no output; the buffer pointer must still be
+		// valid, and decompression still checks the frame and reads its type
+		dstBytes = make([]byte, 1)[:0]
+	}
 
 	// Output info structure to receive type information
 	var outInfo C.ZL_OutputInfo
@@ -206,16 +247,21 @@ func (d *DCtx) DecompressTypedToBytes(src []byte) ([]byte, error) {
 	result := C.ZL_DCtx_decompressTyped(
 		d.ctx,
 		&outInfo,
-		unsafe.Pointer(&dstBytes[0]),
+		unsafe.Pointer(unsafe.SliceData(dstBytes)),
 		C.size_t(len(dstBytes)),
 		unsafe.Pointer(&src[0]),
 		C.size_t(len(src)),
 	)
 
 	if C.ZL_isError(result) != 0 {
-		return nil, d.getError(result)
+		return nil, OutputInfo{}, d.getError(result)
 	}
 
 	n := int(C.ZL_validResult(result))
-	return dstBytes[:n], nil
+	info := OutputInfo{
+		Type:     OutputType(outInfo._type),
+		Width:    int(outInfo.fixedWidth),
+		Elements: int(outInfo.numElts),
+	}
+	return dstBytes[:n], info, nil
 }
@@ -0,0 +1,281 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Capability shim over the libopenzl headers the package is built against.
+//
+// Every difference between libopenzl releases that the bindings care about
+// is detected here, once, from macros and headers, and exposed as a
+// ZLGO_HAS_* macro set to 0 or 1. The Go files include this header instead
+// of testing release-specific macros themselves, and must not reference an
+// optional symbol outside of a ZLGO_HAS_* guard. Supporting a new release
+// therefore means extending this file, not the bindings.
+
+#ifndef ZLGO_COMPAT_H
+#define ZLGO_COMPAT_H
+
+#include <openzl/openzl.h>
+#include <stdint.h>
+
+#if defined(__has_include)
+#define ZLGO_HAS_INCLUDE(h) __has_include(h)
+#else
+#define ZLGO_HAS_INCLUDE(h) 0
+#endif
+
+// Library version, or 0.0.0 for releases without zl_version.h.
+#if ZLGO_HAS_INCLUDE(<openzl/zl_version.h>)
+#include <openzl/zl_version.h>
+#endif
+#if defined(ZL_LIBRARY_VERSION_MAJOR) && defined(ZL_LIBRARY_VERSION_MINOR) && defined(ZL_LIBRARY_VERSION_PATCH)
+#define ZLGO_VERSION_MAJOR ZL_LIBRARY_VERSION_MAJOR
+#define ZLGO_VERSION_MINOR ZL_LIBRARY_VERSION_MINOR
+#define ZLGO_VERSION_PATCH ZL_LIBRARY_VERSION_PATCH
+#else
+#define ZLGO_VERSION_MAJOR 0
+#define ZLGO_VERSION_MINOR 0
+#define ZLGO_VERSION_PATCH 0
+#endif
+
+// Format versions. Releases before ZL_MIN_FORMAT_VERSION only wrote the
+// newest format.
+#define ZLGO_MAX_FORMAT_VERSION ZL_MAX_FORMAT_VERSION
+#ifdef ZL_MIN_FORMAT_VERSION
+#define ZLGO_MIN_FORMAT_VERSION ZL_MIN_FORMAT_VERSION
+#else
+#define ZLGO_MIN_FORMAT_VERSION ZL_MAX_FORMAT_VERSION
+#endif
+
+// Largest content size of a single frame. Releases that do not declare one
+// are only bounded by size_t; half its range keeps ZL_compressBound and the
+// conversion to Go int from overflowing.
+#ifdef ZL_MAX_CONTENT_SIZE
+#define ZLGO_MAX_CONTENT_SIZE ZL_MAX_CONTENT_SIZE
+#else
+#define ZLGO_MAX_CONTENT_SIZE (SIZE_MAX / 2)
+#endif
+
+// Range of ZL_CParam_compressionLevel. Releases that do not declare it
+// accept the levels 1 to 9.
+#if defined(ZL_MIN_COMPRESSION_LEVEL) && defined(ZL_MAX_COMPRESSION_LEVEL)
+#define ZLGO_MIN_COMPRESSION_LEVEL ZL_MIN_COMPRESSION_LEVEL
+#define ZLGO_MAX_COMPRESSION_LEVEL ZL_MAX_COMPRESSION_LEVEL
+#else
+#define ZLGO_MIN_COMPRESSION_LEVEL 1
+#define ZLGO_MAX_COMPRESSION_LEVEL 9
+#endif
+
+// Graph IDs are macros, so their presence tells whether the graph exists.
+#ifdef ZL_GRAPH_NUMERIC
+#define ZLGO_HAS_NUMERIC 1
+#else
+#define ZLGO_HAS_NUMERIC 0
+#endif
+
+#ifdef ZL_GRAPH_FIELD_LZ
+#define ZLGO_HAS_STRUCT 1
+#else
+#define ZLGO_HAS_STRUCT 0
+#endif
+
+#if defined(ZL_GRAPH_COMPRESS_GENERIC) && ZL_MAX_FORMAT_VERSION >= 10
+#define ZLGO_HAS_STRING 1
+#else
+#define ZLGO_HAS_STRING 0
+#endif
+
+// Optional components ship as separate headers.
+#if ZLGO_HAS_INCLUDE(<openzl/codecs/zl_sddl.h>)
+#define ZLGO_HAS_SDDL 1
+#include <openzl/codecs/zl_sddl.h>
+#else
+#define ZLGO_HAS_SDDL 0
+#endif
+
+#if ZLGO_HAS_INCLUDE(<openzl/zl_compressor_serialization.h>)
+#define ZLGO_HAS_TRAINING 1
+#else
+#define ZLGO_HAS_TRAINING 0
+#endif
+
+#if ZLGO_HAS_INCLUDE(<openzl/zl_introspection.h>)
+#define ZLGO_HAS_INTROSPECTION 1
+#include <openzl/zl_introspection.h>
+#else
+#define ZLGO_HAS_INTROSPECTION 0
+#endif
+
+#if ZLGO_HAS_INCLUDE(<openzl/codecs/zl_generic.h>)
+#include <openzl/codecs/zl_generic.h>
+#endif
+
+// zlgo_numericGraph returns the graph used for numeric typed inputs. Without
+// ZL_GRAPH_NUMERIC, numeric data is still compressed, as plain bytes.
+static inline ZL_GraphID zlgo_numericGraph(void) {
+#if ZLGO_HAS_NUMERIC
+    return ZL_GRAPH_NUMERIC;
+#elif defined(ZL_GRAPH_COMPRESS_GENERIC)
+    return ZL_GRAPH_COMPRESS_GENERIC;
+#else
+    return ZL_GRAPH_STORE;
+#endif
+}
+
+// zlgo_createString returns a typed reference to nbStrings strings stored
+// back to back in buffer, or NULL if the linked release cannot compress
+// string inputs.
+static inline ZL_TypedRef* zlgo_createString(const void* buffer, size_t bufferSize, const uint32_t* lengths, size_t nbStrings) {
+#if ZLGO_HAS_STRING
+    return ZL_TypedRef_createString(buffer, bufferSize, lengths, nbStrings);
+#else
+    (void)buffer; (void)bufferSize; (void)lengths; (void)nbStrings;
+    return NULL;
+#endif
+}
+
+// zlgo_stringLens returns the string lengths of a decompressed output, or
+// NULL if it does not hold strings.
+static inline const uint32_t* zlgo_stringLens(const ZL_TypedBuffer* b) {
+#if ZLGO_HAS_STRING
+    if (ZL_TypedBuffer_type(b) != ZL_Type_string) {
+        return NULL;
+    }
+    return ZL_TypedBuffer_rStringLens(b);
+#else
+    (void)b;
+    return NULL;
+#endif
+}
+
+// zlgo_setParameters applies the parameters OpenZL resets after every
+// compression: the format version, the compression level unless it is 0,
+// and the compressor graph unless it is NULL.
+static inline ZL_Report zlgo_setParameters(ZL_CCtx* cctx, int level, ZL_Compressor* graph) {
+    ZL_Report r = ZL_CCtx_setParameter(cctx, ZL_CParam_formatVersion, ZL_MAX_FORMAT_VERSION);
+    if (!ZL_isError(r) && level != 0) {
+        r = ZL_CCtx_setParameter(cctx, ZL_CParam_compressionLevel, level);
+    }
+    if (!ZL_isError(r) && graph != NULL) {
+        r = ZL_CCtx_refCompressor(cctx, graph);
+    }
+    return r;
+}
+
+// Kinds of the standard nodes and graphs custom graphs are built from, as
+// numbered by graph.go. Node and graph IDs are macros, so each kind is only
+// available if the linked release defines it.
+#define ZLGO_NODE_DELTA 1
+#define ZLGO_NODE_ZIGZAG 2
+#define ZLGO_NODE_TOKENIZE 3
+#define ZLGO_NODE_TRANSPOSE_SPLIT2 4
+#define ZLGO_NODE_TRANSPOSE_SPLIT4 5
+#define ZLGO_NODE_TRANSPOSE_SPLIT8 6
+#define ZLGO_NODE_INTERPRET_LE16 7
+#define ZLGO_NODE_INTERPRET_LE32 8
+#define ZLGO_NODE_INTERPRET_LE64 9
+
+#define ZLGO_GRAPH_STORE 1
+#define ZLGO_GRAPH_ENTROPY 2
+#define ZLGO_GRAPH_HUFFMAN 3
+#define ZLGO_GRAPH_FSE 4
+#define ZLGO_GRAPH_ZSTD 5
+#define ZLGO_GRAPH_BITPACK 6
+#define ZLGO_GRAPH_FIELD_LZ 7
+#define ZLGO_GRAPH_GENERIC 8
+#define ZLGO_GRAPH_NUMERIC 9
+
+// zlgo_node stores the node of kind in *out and returns 1, or returns 0 if
+// the linked release does not have it.
+static inline int zlgo_node(int kind, ZL_NodeID* out) {
+    switch (kind) {
+#ifdef ZL_NODE_DELTA_INT
+    case ZLGO_NODE_DELTA: *out = ZL_NODE_DELTA_INT; return 1;
+#endif
+#ifdef ZL_NODE_ZIGZAG
+    case ZLGO_NODE_ZIGZAG: *out = ZL_NODE_ZIGZAG; return 1;
+#endif
+#ifdef ZL_NODE_TOKENIZE
+    case ZLGO_NODE_TOKENIZE: *out = ZL_NODE_TOKENIZE; return 1;
+#endif
+#ifdef ZL_NODE_TRANSPOSE_SPLIT2
+    case ZLGO_NODE_TRANSPOSE_SPLIT2: *out = ZL_NODE_TRANSPOSE_SPLIT2; return 1;
+#endif
+#ifdef ZL_NODE_TRANSPOSE_SPLIT4
+    case ZLGO_NODE_TRANSPOSE_SPLIT4: *out = ZL_NODE_TRANSPOSE_SPLIT4; return 1;
+#endif
+#ifdef ZL_NODE_TRANSPOSE_SPLIT8
+    case ZLGO_NODE_TRANSPOSE_SPLIT8: *out = ZL_NODE_TRANSPOSE_SPLIT8; return 1;
+#endif
+#ifdef ZL_NODE_INTERPRET_AS_LE16
+    case ZLGO_NODE_INTERPRET_LE16: *out = ZL_NODE_INTERPRET_AS_LE16; return 1;
+#endif
+#ifdef ZL_NODE_INTERPRET_AS_LE32
+    case ZLGO_NODE_INTERPRET_LE32: *out = ZL_NODE_INTERPRET_AS_LE32; return 1;
+#endif
+#ifdef ZL_NODE_INTERPRET_AS_LE64
+    case ZLGO_NODE_INTERPRET_LE64: *out = ZL_NODE_INTERPRET_AS_LE64; return 1;
+#endif
+    default: return 0;
+    }
+}
+
+// zlgo_graph stores the graph of kind in *out and returns 1, or returns 0 if
+// the linked release does not have it.
+static inline int zlgo_graph(int kind, ZL_GraphID* out) {
+    switch (kind) {
+    case ZLGO_GRAPH_STORE: *out = ZL_GRAPH_STORE; return 1;
+#ifdef ZL_GRAPH_ENTROPY
+    case ZLGO_GRAPH_ENTROPY: *out = ZL_GRAPH_ENTROPY; return 1;
+#endif
+#ifdef ZL_GRAPH_HUFFMAN
+    case ZLGO_GRAPH_HUFFMAN: *out = ZL_GRAPH_HUFFMAN; return 1;
+#endif
+#ifdef ZL_GRAPH_FSE
+    case ZLGO_GRAPH_FSE: *out = ZL_GRAPH_FSE; return 1;
+#endif
+#ifdef ZL_GRAPH_ZSTD
+    case ZLGO_GRAPH_ZSTD: *out = ZL_GRAPH_ZSTD; return 1;
+#endif
+#ifdef ZL_GRAPH_BITPACK
+    case ZLGO_GRAPH_BITPACK: *out = ZL_GRAPH_BITPACK; return 1;
+#endif
+#ifdef ZL_GRAPH_FIELD_LZ
+    case ZLGO_GRAPH_FIELD_LZ: *out = ZL_GRAPH_FIELD_LZ; return 1;
+#endif
+#ifdef ZL_GRAPH_COMPRESS_GENERIC
+    case ZLGO_GRAPH_GENERIC: *out = ZL_GRAPH_COMPRESS_GENERIC; return 1;
+#endif
+#if ZLGO_HAS_NUMERIC
+    case ZLGO_GRAPH_NUMERIC: *out = ZL_GRAPH_NUMERIC; return 1;
+#endif
+    default: return 0;
+    }
+}
+
+// zlgo_graphIsValid reports whether a graph registration succeeded.
+static inline int zlgo_graphIsValid(ZL_GraphID g) {
+    return g.gid != ZL_GRAPH_ILLEGAL.gid;
+}
+
+// zlgo_sddlGraph registers on compressor the SDDL graph of a compiled
+// description, followed by the generic graph, and stores it in *out. It
+// returns 0 if the linked release has no SDDL support or rejects the
+// description.
+static inline int zlgo_sddlGraph(ZL_Compressor* compressor, const void* description, size_t size, ZL_GraphID* out) {
+#if ZLGO_HAS_SDDL
+    ZL_GraphID successor;
+    if (!zlgo_graph(ZLGO_GRAPH_GENERIC, &successor)) {
+        successor = ZL_GRAPH_STORE;
+    }
+    ZL_RESULT_OF(ZL_GraphID) r = ZL_Compressor_buildSDDLGraph(compressor, description, size, successor);
+    if (ZL_RES_isError(r)) {
+        return 0;
+    }
+    *out = ZL_RES_value(r);
+    return zlgo_graphIsValid(*out);
+#else
+    (void)compressor; (void)description; (void)size; (void)out;
+    return 0;
+#endif
+}
+
+#endif // ZLGO_COMPAT_H
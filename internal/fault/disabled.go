@@ -0,0 +1,26 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+//go:build !openzl_faults
+
+package fault
+
+import "io"
+
+// Enabled reports whether fault injection is compiled in.
+const Enabled = false
+
+// Set does nothing without the openzl_faults build tag.
+func Set(Point, Fault) {}
+
+// Clear does nothing without the openzl_faults build tag.
+func Clear() {}
+
+// Fired always returns 0 without the openzl_faults build tag.
+func Fired(Point) int { return 0 }
+
+// Check always returns nil without the openzl_faults build tag.
+func Check(Point) error { return nil }
+
+// Reader returns r without the openzl_faults build tag.
+func Reader(r io.Reader) io.Reader { return r }
@@ -0,0 +1,101 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+//go:build openzl_faults
+
+package fault
+
+import (
+	"io"
+	"sync"
+)
+
+// Enabled reports whether fault injection is compiled in.
+const Enabled = true
+
+var (
+	mu     sync.Mutex
+	faults = make(map[Point]*state)
+)
+
+// state is an installed fault and its progress.
+type state struct {
+	Fault
+	calls int // Calls seen at the point
+	fired int // Calls that failed
+}
+
+// Set installs f at p, replacing any fault there.
+func Set(p Point, f Fault) {
+	mu.Lock()
+	defer mu.Unlock()
+	faults[p] = &state{Fault: f}
+}
+
+// Clear removes all faults.
+func Clear() {
+	mu.Lock()
+	defer mu.Unlock()
+	clear(faults)
+}
+
+// Fired returns the number of calls at p that failed since its fault was set.
+func Fired(p Point) int {
+	mu.Lock()
+	defer mu.Unlock()
+	if s, ok := faults[p]; ok {
+		return s.fired
+	}
+	return 0
+}
+
+// next counts a call at p and returns its fault if the call must fail.
+func next(p Point) (Fault, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	s, ok := faults[p]
+	if !ok {
+		return Fault{}, false
+	}
+	s.calls++
+	if s.calls <= s.Skip || (s.Count > 0 && s.fired >= s.Count) {
+		return Fault{}, false
+	}
+	s.fired++
+	f := s.Fault
+	if f.Err == nil {
+		f.Err = ErrInjected
+	}
+	return f, true
+}
+
+// Check returns the error of the fault at p if the current call must fail.
+func Check(p Point) error {
+	if f, ok := next(p); ok {
+		return f.Err
+	}
+	return nil
+}
+
+// Reader wraps r so that its reads are subject to the Read fault.
+func Reader(r io.Reader) io.Reader {
+	if r == nil {
+		return nil
+	}
+	return &reader{r}
+}
+
+type reader struct {
+	r io.Reader
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	f, ok := next(Read)
+	if !ok {
+		return r.r.Read(p)
+	}
+	if f.ShortRead > 0 {
+		return r.r.Read(p[:min(len(p), f.ShortRead)])
+	}
+	return 0, f.Err
+}
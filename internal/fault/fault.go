@@ -0,0 +1,43 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Package fault is the fault-injection layer behind package faultinject.
+//
+// The library calls Check at the points where real failures can occur, such
+// as C API calls and native allocations, and wraps the readers it consumes
+// with Reader. Both are compiled in only with the openzl_faults build tag;
+// without it, Enabled is false, Check always returns nil, and Reader returns
+// its argument, so production builds pay nothing.
+package fault
+
+import "errors"
+
+// Point identifies a place where faults can be injected.
+type Point string
+
+const (
+	Compress   Point = "compress"   // C compression calls
+	Decompress Point = "decompress" // C decompression calls
+	Alloc      Point = "alloc"      // Native context and buffer allocations
+	Read       Point = "read"       // Reads from a Reader's underlying stream
+)
+
+// Fault describes the failures injected at a point.
+type Fault struct {
+	// Err is returned by each failing call. If nil, ErrInjected is used.
+	Err error
+
+	// Skip is the number of calls that succeed before the fault fires.
+	Skip int
+
+	// Count is the number of calls that fail once the fault fires. Zero
+	// means every later call fails.
+	Count int
+
+	// ShortRead, for the Read point, makes failing reads return at most
+	// this many bytes and no error instead of failing with Err.
+	ShortRead int
+}
+
+// ErrInjected is the default error of an injected fault.
+var ErrInjected = errors.New("openzl: injected fault")
@@ -0,0 +1,47 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Package recordtag parses the openzl struct tags of record types.
+//
+// It is shared by CompressRecords, which reads tags through reflection, and
+// the zlgo gen code generator, which reads them from source, so that both
+// store a given struct identically.
+package recordtag
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Key is the struct tag key.
+const Key = "openzl"
+
+// Options are the parsed options of one field's tag.
+type Options struct {
+	Skip  bool // Leave the field out ("skip" or "-")
+	Width int  // Integer width in bytes ("width=N"), 0 for the natural width
+}
+
+// Parse parses the value of an openzl tag: comma-separated options among
+// "column" (the default, accepted for clarity), "skip" or "-", and "width=N"
+// with N one of 1, 2, 4, or 8.
+func Parse(tag string) (Options, error) {
+	var o Options
+	for _, opt := range strings.Split(tag, ",") {
+		switch {
+		case opt == "column" || opt == "":
+		case opt == "skip" || opt == "-":
+			o.Skip = true
+		case strings.HasPrefix(opt, "width="):
+			w, err := strconv.Atoi(strings.TrimPrefix(opt, "width="))
+			if err != nil || (w != 1 && w != 2 && w != 4 && w != 8) {
+				return Options{}, fmt.Errorf("invalid tag option %q, width must be 1, 2, 4, or 8", opt)
+			}
+			o.Width = w
+		default:
+			return Options{}, fmt.Errorf("unknown tag option %q", opt)
+		}
+	}
+	return o, nil
+}
@@ -0,0 +1,214 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"math"
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+// latencyBuckets is the number of histogram buckets. Bucket boundaries are
+// spaced four per power of two of nanoseconds, so every bucket is at most 25%
+// wider than its lower bound, from 1ns up to the largest time.Duration.
+const latencyBuckets = 4 * 62
+
+// latencyBucket returns the bucket of a duration of ns nanoseconds.
+func latencyBucket(ns uint64) int {
+	if ns < 4 {
+		return int(ns)
+	}
+	e := bits.Len64(ns) - 1
+	return 4*(e-1) + int(ns>>(e-2)&3)
+}
+
+// latencyBucketLower returns the smallest duration in bucket i.
+func latencyBucketLower(i int) time.Duration {
+	if i < 4 {
+		return time.Duration(i)
+	}
+	e := i/4 + 1
+	return time.Duration(uint64(4+i%4) << (e - 2))
+}
+
+// latencyHistogram records operation latencies without locking, so that
+// Stats does not wait for an operation in progress.
+type latencyHistogram struct {
+	counts [latencyBuckets]atomic.Uint64
+	count  atomic.Uint64
+	sum    atomic.Int64
+	max    atomic.Int64
+}
+
+// observe records the time since start. Use it as
+// defer h.observe(time.Now()).
+func (h *latencyHistogram) observe(start time.Time) {
+	h.record(max(time.Since(start), 0))
+}
+
+// record records a latency of d.
+func (h *latencyHistogram) record(d time.Duration) {
+	h.counts[latencyBucket(uint64(d))].Add(1)
+	h.count.Add(1)
+	h.sum.Add(int64(d))
+	for {
+		m := h.max.Load()
+		if int64(d) <= m || h.max.CompareAndSwap(m, int64(d)) {
+			break
+		}
+	}
+}
+
+// snapshot returns the recorded latencies. Operations finishing during the
+// snapshot may be partially included.
+func (h *latencyHistogram) snapshot() LatencyHistogram {
+	if h == nil {
+		return LatencyHistogram{}
+	}
+	s := LatencyHistogram{
+		Count: h.count.Load(),
+		Sum:   time.Duration(h.sum.Load()),
+		Max:   time.Duration(h.max.Load()),
+	}
+	s.counts = make([]uint64, latencyBuckets)
+	for i := range h.counts {
+		s.counts[i] = h.counts[i].Load()
+	}
+	return s
+}
+
+// LatencyHistogram is a snapshot of the latencies of the operations of a
+// Compressor or Decompressor.
+//
+// Latencies are kept in buckets spaced four per power of two, so the
+// percentiles it reports are accurate to within 25%, which is enough to see
+// the long tail of cgo calls that an average hides.
+type LatencyHistogram struct {
+	// Count is the number of operations recorded.
+	Count uint64
+
+	// Sum is the total latency of the operations recorded.
+	Sum time.Duration
+
+	// Max is the largest latency recorded.
+	Max time.Duration
+
+	counts []uint64
+}
+
+// LatencyBucket is one bucket of a LatencyHistogram.
+type LatencyBucket struct {
+	// UpperBound is the exclusive upper bound of the bucket's latencies.
+	UpperBound time.Duration
+
+	// Count is the number of operations in the bucket.
+	Count uint64
+}
+
+// Mean returns the average latency, or 0 if no operation was recorded.
+func (h LatencyHistogram) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Sum / time.Duration(h.Count)
+}
+
+// Percentile returns the latency below which a fraction p of the operations
+// fall, for p between 0 and 1; Percentile(0.99) is the 99th percentile. The
+// result is the upper bound of the bucket holding that operation, but never
+// more than Max. It returns 0 if no operation was recorded.
+func (h LatencyHistogram) Percentile(p float64) time.Duration {
+	var total uint64
+	for _, n := range h.counts {
+		total += n
+	}
+	if total == 0 {
+		return 0
+	}
+	rank := uint64(1)
+	if p > 0 {
+		rank = max(uint64(math.Ceil(p*float64(total))), 1)
+	}
+	var seen uint64
+	for i, n := range h.counts {
+		seen += n
+		if seen >= rank {
+			if i+1 == latencyBuckets || latencyBucketLower(i+1) > h.Max {
+				return h.Max
+			}
+			return latencyBucketLower(i + 1)
+		}
+	}
+	return h.Max
+}
+
+// Buckets returns the non-empty buckets in increasing order, for export to
+// a metrics system. Counts are per bucket; add them up for a cumulative
+// histogram such as Prometheus expects.
+func (h LatencyHistogram) Buckets() []LatencyBucket {
+	var buckets []LatencyBucket
+	for i, n := range h.counts {
+		if n == 0 {
+			continue
+		}
+		upper := time.Duration(math.MaxInt64)
+		if i+1 < latencyBuckets {
+			upper = latencyBucketLower(i + 1)
+		}
+		buckets = append(buckets, LatencyBucket{UpperBound: upper, Count: n})
+	}
+	return buckets
+}
+
+// OperationStats holds the statistics of a Compressor or Decompressor.
+type OperationStats struct {
+	// Latency is the latency histogram of the operations, each measured
+	// from acquiring the context to returning, so that it includes the cgo
+	// call and the Go-side buffer handling but not waiting for other
+	// goroutines. It is empty unless the histogram was enabled with
+	// WithLatencyHistogram or WithDecompressLatencyHistogram.
+	Latency LatencyHistogram
+}
+
+// WithLatencyHistogram makes a Compressor record the latency of each
+// operation in a histogram, reported by Stats. Recording costs two clock
+// reads and a few atomic additions per operation, so it is disabled by
+// default.
+//
+// Example:
+//
+//	compressor, _ := openzl.NewCompressor(openzl.WithLatencyHistogram(true))
+//	...
+//	lat := compressor.Stats().Latency
+//	log.Printf("compress p50=%v p99=%v max=%v", lat.Percentile(0.5), lat.Percentile(0.99), lat.Max)
+func WithLatencyHistogram(enabled bool) CompressorOption {
+	return func(cfg *config) error {
+		cfg.latency = enabled
+		return nil
+	}
+}
+
+// WithDecompressLatencyHistogram is the Decompressor equivalent of
+// WithLatencyHistogram.
+func WithDecompressLatencyHistogram(enabled bool) DecompressorOption {
+	return func(cfg *decompressorConfig) error {
+		cfg.latency = enabled
+		return nil
+	}
+}
+
+// Stats returns the statistics recorded by the Compressor. Operations
+// covered are Compress, Compress2D, CompressBatch, and
+// CompressorCompressNumeric.
+func (c *Compressor) Stats() OperationStats {
+	return OperationStats{Latency: c.latency.snapshot()}
+}
+
+// Stats returns the statistics recorded by the Decompressor. Operations
+// covered are Decompress, DecompressScatter, and
+// DecompressorDecompressNumeric.
+func (d *Decompressor) Stats() OperationStats {
+	return OperationStats{Latency: d.latency.snapshot()}
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestLatencyBucket(t *testing.T) {
+	for _, ns := range []uint64{0, 1, 3, 4, 5, 7, 8, 9, 100, 1000, 123456789, 1 << 62, 1<<63 - 1} {
+		i := latencyBucket(ns)
+		if i < 0 || i >= latencyBuckets {
+			t.Fatalf("latencyBucket(%d) = %d out of range", ns, i)
+		}
+		lower := uint64(latencyBucketLower(i))
+		if ns < lower || (i+1 < latencyBuckets && ns >= uint64(latencyBucketLower(i+1))) {
+			t.Errorf("%d not in bucket %d [%d, %d)", ns, i, lower, latencyBucketLower(i+1))
+		}
+	}
+}
+
+func TestLatencyHistogram(t *testing.T) {
+	var h latencyHistogram
+	for _, d := range []time.Duration{time.Microsecond, time.Microsecond, time.Microsecond, 10 * time.Millisecond} {
+		h.record(d)
+	}
+	s := h.snapshot()
+	if s.Count != 4 || s.Max != 10*time.Millisecond {
+		t.Fatalf("snapshot = %+v", s)
+	}
+	if p := s.Percentile(0.5); p < time.Microsecond || p > 1250*time.Nanosecond {
+		t.Errorf("p50 = %v, want about 1µs", p)
+	}
+	if p := s.Percentile(1); p != s.Max {
+		t.Errorf("p100 = %v, want Max %v", p, s.Max)
+	}
+	var total uint64
+	for _, b := range s.Buckets() {
+		total += b.Count
+	}
+	if total != 4 {
+		t.Errorf("bucket counts add up to %d, want 4", total)
+	}
+}
+
+func TestStats_Latency(t *testing.T) {
+	c, err := NewCompressor(WithLatencyHistogram(true))
+	if err != nil {
+		t.Fatalf("NewCompressor failed: %v", err)
+	}
+	defer c.Close()
+	d, err := NewDecompressor(WithDecompressLatencyHistogram(true))
+	if err != nil {
+		t.Fatalf("NewDecompressor failed: %v", err)
+	}
+	defer d.Close()
+
+	data := bytes.Repeat([]byte("latency "), 1000)
+	for range 5 {
+		compressed, err := c.Compress(data)
+		if err != nil {
+			t.Fatalf("Compress failed: %v", err)
+		}
+		if _, err := d.Decompress(compressed); err != nil {
+			t.Fatalf("Decompress failed: %v", err)
+		}
+	}
+	if _, err := CompressorCompressNumeric(c, []int64{1, 2, 3}); err != nil {
+		t.Fatalf("CompressorCompressNumeric failed: %v", err)
+	}
+
+	if lat := c.Stats().Latency; lat.Count != 6 || lat.Percentile(0.99) <= 0 || lat.Mean() <= 0 {
+		t.Errorf("compressor latency = %+v, want 6 operations", lat)
+	}
+	if lat := d.Stats().Latency; lat.Count != 5 {
+		t.Errorf("decompressor latency count = %d, want 5", lat.Count)
+	}
+
+	plain, err := NewCompressor()
+	if err != nil {
+		t.Fatalf("NewCompressor failed: %v", err)
+	}
+	defer plain.Close()
+	plain.Compress(data)
+	if lat := plain.Stats().Latency; lat.Count != 0 || lat.Percentile(0.5) != 0 {
+		t.Errorf("latency recorded without WithLatencyHistogram: %+v", lat)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"os"
+	"testing"
+
+	"github.com/borischu/go-openzl/openzltest"
+)
+
+// leakIterations returns the number of calls per leak check. Set
+// OPENZL_SOAK=1 (make soak) for the full million-call soak test.
+func leakIterations() int {
+	if os.Getenv("OPENZL_SOAK") != "" {
+		return openzltest.DefaultIterations
+	}
+	return 20000
+}
+
+func TestNativeLeaks(t *testing.T) {
+	data := []byte("The quick brown fox jumps over the lazy dog. ")
+	for len(data) < 4096 {
+		data = append(data, data...)
+	}
+	compressed, err := Compress(data)
+	if err != nil {
+		t.Fatalf("Compress() failed: %v", err)
+	}
+	numbers := make([]int64, 512)
+	for i := range numbers {
+		numbers[i] = int64(i * 3)
+	}
+
+	t.Run("one-shot", func(t *testing.T) {
+		openzltest.CheckLeaks(t, func() error {
+			c, err := Compress(data)
+			if err != nil {
+				return err
+			}
+			_, err = Decompress(c)
+			return err
+		}, openzltest.WithIterations(leakIterations()))
+	})
+
+	t.Run("contexts", func(t *testing.T) {
+		openzltest.CheckLeaks(t, func() error {
+			c, err := NewCompressor()
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+			d, err := NewDecompressor()
+			if err != nil {
+				return err
+			}
+			defer d.Close()
+			if _, err := c.Compress(data); err != nil {
+				return err
+			}
+			_, err = d.Decompress(compressed)
+			return err
+		}, openzltest.WithIterations(leakIterations()))
+	})
+
+	t.Run("typed", func(t *testing.T) {
+		openzltest.CheckLeaks(t, func() error {
+			c, err := CompressNumeric(numbers)
+			if err != nil {
+				return err
+			}
+			_, err = DecompressNumeric[int64](c)
+			return err
+		}, openzltest.WithIterations(leakIterations()))
+	})
+}
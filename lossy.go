@@ -0,0 +1,120 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"unsafe"
+)
+
+const (
+	// lossyStepMargin shrinks the quantization step slightly below twice the
+	// error bound, leaving room for floating-point rounding on reconstruction.
+	lossyStepMargin = 0.999
+
+	// lossyMaxSteps bounds |value/step| so that reconstruction rounding stays
+	// well inside the margin.
+	lossyMaxSteps = 1 << 40
+)
+
+// Float is a constraint that permits the floating-point types supported by
+// lossy compression.
+type Float interface {
+	float32 | float64
+}
+
+// CompressFloatsLossy compresses floating-point data with a bounded absolute
+// error, trading exactness for much higher ratios.
+//
+// Every value is quantized to a multiple of a step just under 2*maxAbsError,
+// so the decompressed value differs from the original by at most maxAbsError.
+// The quantized integers are delta-encoded and compressed with OpenZL's
+// numeric graph; the step is recorded alongside them, so DecompressFloatsLossy
+// needs no parameters. Smooth sensor signals typically shrink by an order of
+// magnitude more than with lossless compression.
+//
+// Returns ErrInvalidParameter if maxAbsError is not a positive finite number,
+// if data contains NaN or infinities, or if a value is too large relative to
+// maxAbsError to be quantized.
+//
+// Example:
+//
+//	// Temperatures only matter to 0.01 degrees
+//	compressed, err := openzl.CompressFloatsLossy(readings, 0.005)
+//	...
+//	approx, err := openzl.DecompressFloatsLossy[float64](compressed)
+func CompressFloatsLossy[T Float](data []T, maxAbsError float64) ([]byte, error) {
+	if !(maxAbsError > 0) || math.IsInf(maxAbsError, 1) {
+		return nil, fmt.Errorf("%w: max absolute error must be positive and finite, got %v", ErrInvalidParameter, maxAbsError)
+	}
+	if len(data) == 0 {
+		return nil, ErrEmptyInput
+	}
+
+	step := 2 * maxAbsError * lossyStepMargin
+	q := make([]int64, len(data))
+	for i, v := range data {
+		x := float64(v)
+		s := math.Round(x / step)
+		if math.IsNaN(s) || math.Abs(s) >= lossyMaxSteps {
+			return nil, fmt.Errorf("%w: value %v at index %d cannot be quantized with error %v", ErrInvalidParameter, x, i, maxAbsError)
+		}
+		q[i] = int64(s)
+		if math.Abs(float64(T(float64(q[i])*step))-x) > maxAbsError {
+			return nil, fmt.Errorf("%w: value %v at index %d cannot meet error bound %v", ErrInvalidParameter, x, i, maxAbsError)
+		}
+	}
+
+	frame, err := CompressNumeric(deltaEncode(q))
+	if err != nil {
+		return nil, err
+	}
+
+	var zero T
+	meta := binary.LittleEndian.AppendUint64(nil, math.Float64bits(step))
+	meta = append(meta, byte(unsafe.Sizeof(zero)))
+	return encodeContainer(containerLossy, meta, frame), nil
+}
+
+// DecompressFloatsLossy restores data compressed by CompressFloatsLossy.
+// Each value is within the requested error bound of the original.
+//
+// Returns ErrCorruptedData if the data was compressed from a different
+// element type than T.
+func DecompressFloatsLossy[T Float](compressed []byte) ([]T, error) {
+	if len(compressed) == 0 {
+		return nil, ErrEmptyInput
+	}
+	sections, err := decodeContainer(compressed, containerLossy, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := sections[0]
+	var zero T
+	if len(meta) != 9 {
+		return nil, fmt.Errorf("%w: invalid quantization parameters", ErrCorruptedData)
+	}
+	if int(meta[8]) != int(unsafe.Sizeof(zero)) {
+		return nil, fmt.Errorf("%w: data holds %d-byte floats, not %d-byte", ErrCorruptedData, meta[8], unsafe.Sizeof(zero))
+	}
+	step := math.Float64frombits(binary.LittleEndian.Uint64(meta))
+	if !(step > 0) || math.IsInf(step, 1) {
+		return nil, fmt.Errorf("%w: invalid quantization step", ErrCorruptedData)
+	}
+
+	deltas, err := DecompressNumeric[int64](sections[1])
+	if err != nil {
+		return nil, err
+	}
+	q := deltaDecode(deltas)
+
+	data := make([]T, len(q))
+	for i, s := range q {
+		data[i] = T(float64(s) * step)
+	}
+	return data, nil
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestCompressFloatsLossy(t *testing.T) {
+	data := make([]float64, 5000)
+	for i := range data {
+		data[i] = 20 + 5*math.Sin(float64(i)/100) + 0.001*float64(i%7)
+	}
+
+	for _, maxErr := range []float64{1e-6, 0.005, 0.5} {
+		compressed, err := CompressFloatsLossy(data, maxErr)
+		if err != nil {
+			t.Fatalf("CompressFloatsLossy(%v) failed: %v", maxErr, err)
+		}
+		got, err := DecompressFloatsLossy[float64](compressed)
+		if err != nil {
+			t.Fatalf("DecompressFloatsLossy(%v) failed: %v", maxErr, err)
+		}
+		if len(got) != len(data) {
+			t.Fatalf("got %d values, want %d", len(got), len(data))
+		}
+		for i := range data {
+			if d := math.Abs(got[i] - data[i]); d > maxErr {
+				t.Fatalf("maxErr %v: value %d off by %v", maxErr, i, d)
+			}
+		}
+	}
+
+	// float32 round trip and element type checking
+	f32 := []float32{1.5, -2.25, 3.125}
+	compressed, err := CompressFloatsLossy(f32, 0.01)
+	if err != nil {
+		t.Fatalf("CompressFloatsLossy(float32) failed: %v", err)
+	}
+	if _, err := DecompressFloatsLossy[float64](compressed); !errors.Is(err, ErrCorruptedData) {
+		t.Errorf("DecompressFloatsLossy[float64] on float32 data error = %v, want ErrCorruptedData", err)
+	}
+
+	if _, err := CompressFloatsLossy([]float64{math.NaN()}, 0.1); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("CompressFloatsLossy(NaN) error = %v, want ErrInvalidParameter", err)
+	}
+	if _, err := CompressFloatsLossy(data, 0); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("CompressFloatsLossy(maxErr=0) error = %v, want ErrInvalidParameter", err)
+	}
+}
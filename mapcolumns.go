@@ -0,0 +1,181 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"encoding/binary"
+	"fmt"
+	"maps"
+	"slices"
+	"unsafe"
+
+	"github.com/borischu/go-openzl/internal/cgo"
+)
+
+// mapIndexVersion is the first byte of the key index of CompressMapColumns.
+const mapIndexVersion = 1
+
+// CompressMapColumns compresses a set of named numeric series, such as a
+// metrics snapshot keyed by label set, into a single OpenZL frame.
+//
+// Each series becomes its own typed input of a multi-input frame, so OpenZL
+// picks numeric codecs per series instead of seeing one concatenated
+// buffer. A key index, stored as one more input of the same frame, records
+// the keys in sorted order and the length of each series. Empty series are
+// kept in the index but take no input.
+//
+// Example:
+//
+//	snapshot := map[string][]float64{
+//		`http_requests{code="200"}`: {1520, 1544, 1581},
+//		`http_requests{code="500"}`: {3, 3, 4},
+//	}
+//	compressed, err := openzl.CompressMapColumns(snapshot)
+//	...
+//	snapshot, err = openzl.DecompressMapColumns[float64](compressed)
+//
+// Returns ErrEmptyInput if columns is empty.
+func CompressMapColumns[T Numeric](columns map[string][]T) ([]byte, error) {
+	if len(columns) == 0 {
+		return nil, ErrEmptyInput
+	}
+	var zero T
+	width := int(unsafe.Sizeof(zero))
+
+	keys := slices.Sorted(maps.Keys(columns))
+	index := []byte{mapIndexVersion, byte(width)}
+	index = binary.AppendUvarint(index, uint64(len(keys)))
+	for _, k := range keys {
+		index = binary.AppendUvarint(index, uint64(len(k)))
+		index = append(index, k...)
+		index = binary.AppendUvarint(index, uint64(len(columns[k])))
+	}
+
+	trefs := make([]*cgo.TypedRef, 0, len(keys)+1)
+	defer func() {
+		for _, t := range trefs {
+			t.Free()
+		}
+	}()
+	tref, err := cgo.NewTypedRefSerial(index)
+	if err != nil {
+		return nil, fmt.Errorf("create typed ref: %w", err)
+	}
+	trefs = append(trefs, tref)
+	size := len(index)
+	for _, k := range keys {
+		if len(columns[k]) == 0 {
+			continue
+		}
+		tref, err := cgo.NewTypedRefNumeric(columns[k])
+		if err != nil {
+			return nil, fmt.Errorf("column %q: create typed ref: %w", k, err)
+		}
+		trefs = append(trefs, tref)
+		size += len(columns[k]) * width
+	}
+
+	ctx, err := newCCtx()
+	if err != nil {
+		return nil, fmt.Errorf("create context: %w", err)
+	}
+	defer ctx.Free()
+
+	// Typed compression may need more space than CompressBound for raw bytes
+	dstSize, err := compressBound(size, 2)
+	if err != nil {
+		return nil, err
+	}
+	dst := make([]byte, dstSize)
+	n, err := ctx.CompressMultiTypedRef(dst, trefs)
+	if err != nil {
+		return nil, fmt.Errorf("compress typed: %w", err)
+	}
+	return dst[:n], nil
+}
+
+// DecompressMapColumns restores series compressed by CompressMapColumns.
+// The type parameter T must match the type used during compression; one of
+// another width fails with ErrTypeMismatch.
+func DecompressMapColumns[T Numeric](compressed []byte) (map[string][]T, error) {
+	if len(compressed) == 0 {
+		return nil, ErrEmptyInput
+	}
+
+	ctx, err := cgo.NewDCtx()
+	if err != nil {
+		return nil, fmt.Errorf("create context: %w", err)
+	}
+	defer ctx.Free()
+
+	outputs, err := ctx.DecompressMulti(compressed)
+	if err != nil {
+		return nil, fmt.Errorf("decompress typed: %w", diagnoseFrameError(compressed, err))
+	}
+	if len(outputs) == 0 {
+		return nil, fmt.Errorf("%w: missing key index", ErrCorruptedData)
+	}
+
+	var zero T
+	width := int(unsafe.Sizeof(zero))
+	index := outputs[0]
+	if len(index) < 2 || index[0] != mapIndexVersion {
+		return nil, fmt.Errorf("%w: invalid key index", ErrCorruptedData)
+	}
+	if int(index[1]) != width {
+		return nil, fmt.Errorf("%w: columns have %d-byte elements, want %d", ErrTypeMismatch, index[1], width)
+	}
+	index = index[2:]
+
+	uvarint := func() (uint64, error) {
+		v, k := binary.Uvarint(index)
+		if k <= 0 {
+			return 0, fmt.Errorf("%w: truncated key index", ErrCorruptedData)
+		}
+		index = index[k:]
+		return v, nil
+	}
+	count, err := uvarint()
+	if err != nil {
+		return nil, err
+	}
+	// Every key takes at least two bytes of the index
+	if count > uint64(len(index))/2 {
+		return nil, fmt.Errorf("%w: truncated key index", ErrCorruptedData)
+	}
+
+	columns := make(map[string][]T, count)
+	next := 1
+	for range count {
+		keyLen, err := uvarint()
+		if err != nil {
+			return nil, err
+		}
+		if keyLen > uint64(len(index)) {
+			return nil, fmt.Errorf("%w: truncated key index", ErrCorruptedData)
+		}
+		key := string(index[:keyLen])
+		index = index[keyLen:]
+		n, err := uvarint()
+		if err != nil {
+			return nil, err
+		}
+
+		if n == 0 {
+			columns[key] = []T{}
+			continue
+		}
+		if next >= len(outputs) || n > uint64(len(outputs[next])) || uint64(len(outputs[next])) != n*uint64(width) {
+			return nil, fmt.Errorf("%w: column %q does not match the key index", ErrCorruptedData, key)
+		}
+		if columns[key], err = cgo.BytesToTypedSlice[T](outputs[next]); err != nil {
+			return nil, fmt.Errorf("column %q: convert to typed slice: %w", key, err)
+		}
+		next++
+	}
+	if len(index) != 0 || next != len(outputs) {
+		return nil, fmt.Errorf("%w: key index does not match the frame", ErrCorruptedData)
+	}
+	return columns, nil
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestCompressMapColumns(t *testing.T) {
+	requests := make([]float64, 500)
+	for i := range requests {
+		requests[i] = float64(1000 + i*12 + i%3)
+	}
+	snapshot := map[string][]float64{
+		`http_requests{code="200"}`: requests,
+		`http_requests{code="500"}`: {3, 3, 4, 4, 4},
+		`queue_depth{}`:             {},
+		"":                          {-1.5},
+	}
+
+	compressed, err := CompressMapColumns(snapshot)
+	if err != nil {
+		t.Fatalf("CompressMapColumns() failed: %v", err)
+	}
+	got, err := DecompressMapColumns[float64](compressed)
+	if err != nil {
+		t.Fatalf("DecompressMapColumns() failed: %v", err)
+	}
+	if !maps.EqualFunc(got, snapshot, slices.Equal) {
+		t.Errorf("round trip mismatch: got %v", got)
+	}
+	if got[`queue_depth{}`] == nil {
+		t.Error("empty column decoded as nil")
+	}
+
+	if _, err := DecompressMapColumns[int32](compressed); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("DecompressMapColumns[int32]() error = %v, want ErrTypeMismatch", err)
+	}
+}
+
+func TestCompressMapColumns_Invalid(t *testing.T) {
+	if _, err := CompressMapColumns(map[string][]int64{}); !errors.Is(err, ErrEmptyInput) {
+		t.Errorf("CompressMapColumns(empty) error = %v, want ErrEmptyInput", err)
+	}
+
+	numeric, err := CompressNumeric([]float64{1, 2, 3})
+	if err != nil {
+		t.Fatalf("CompressNumeric() failed: %v", err)
+	}
+	if _, err := DecompressMapColumns[float64](numeric); !errors.Is(err, ErrCorruptedData) {
+		t.Errorf("DecompressMapColumns(numeric frame) error = %v, want ErrCorruptedData", err)
+	}
+}
@@ -0,0 +1,17 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+//go:build !unix
+
+package openzl
+
+import (
+	"errors"
+	"os"
+)
+
+// mapFile reports that memory-mapped files are not supported, so that
+// DecompressToFile writes through the file instead.
+func mapFile(f *os.File, size int) ([]byte, func() error, error) {
+	return nil, nil, errors.ErrUnsupported
+}
@@ -0,0 +1,22 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+//go:build unix
+
+package openzl
+
+import (
+	"os"
+	"syscall"
+)
+
+// mapFile maps the first size bytes of f into memory for reading and
+// writing, with changes written back to the file. The returned function
+// removes the mapping.
+func mapFile(f *os.File, size int) ([]byte, func() error, error) {
+	m, err := syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, os.NewSyscallError("mmap", err)
+	}
+	return m, func() error { return syscall.Munmap(m) }, nil
+}
@@ -0,0 +1,227 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+// Address family markers in the family column of an IP column.
+const (
+	ipFamily4 = 4
+	ipFamily6 = 6
+)
+
+// CompressIPs compresses a column of IPv4 and IPv6 addresses.
+//
+// Addresses are split by family and stored as byte planes: the first octet of
+// every address, then the second, and so on. Telemetry addresses cluster in a
+// few networks, so the high octets form long runs and only the low octets
+// carry much information. The address order, and therefore any mix of IPv4
+// and IPv6, is preserved. IPv4-mapped IPv6 addresses stay IPv6.
+//
+// Addresses with an IPv6 zone, and the invalid zero Addr, are rejected with
+// ErrInvalidParameter.
+func CompressIPs(addrs []netip.Addr) ([]byte, error) {
+	if len(addrs) == 0 {
+		return nil, ErrEmptyInput
+	}
+	sections, err := compressIPColumn(addrs)
+	if err != nil {
+		return nil, err
+	}
+	return encodeContainer(containerIPs, sections...), nil
+}
+
+// DecompressIPs restores addresses compressed by CompressIPs.
+func DecompressIPs(compressed []byte) ([]netip.Addr, error) {
+	if len(compressed) == 0 {
+		return nil, ErrEmptyInput
+	}
+	sections, err := decodeContainer(compressed, containerIPs, ipColumnSections)
+	if err != nil {
+		return nil, err
+	}
+	return decompressIPColumn(sections)
+}
+
+// FlowColumns is a batch of network flow records, such as netflow or pcap
+// index entries, stored column by column. All columns must have the same
+// length.
+type FlowColumns struct {
+	SrcAddr  []netip.Addr
+	DstAddr  []netip.Addr
+	SrcPort  []uint16
+	DstPort  []uint16
+	Protocol []uint8
+}
+
+// Len returns the number of records, or -1 if the columns differ in length.
+func (f FlowColumns) Len() int {
+	n := len(f.SrcAddr)
+	if len(f.DstAddr) != n || len(f.SrcPort) != n || len(f.DstPort) != n || len(f.Protocol) != n {
+		return -1
+	}
+	return n
+}
+
+// CompressFlows compresses a batch of flow records.
+//
+// Addresses are compressed as in CompressIPs; ports and protocol numbers go
+// through OpenZL's numeric graphs, where the handful of well-known ports and
+// protocols compress to almost nothing.
+func CompressFlows(f FlowColumns) ([]byte, error) {
+	n := f.Len()
+	if n < 0 {
+		return nil, fmt.Errorf("%w: flow columns differ in length", ErrInvalidParameter)
+	}
+	if n == 0 {
+		return nil, ErrEmptyInput
+	}
+
+	src, err := compressIPColumn(f.SrcAddr)
+	if err != nil {
+		return nil, fmt.Errorf("source addresses: %w", err)
+	}
+	dst, err := compressIPColumn(f.DstAddr)
+	if err != nil {
+		return nil, fmt.Errorf("destination addresses: %w", err)
+	}
+	srcPort, err := CompressNumeric(f.SrcPort)
+	if err != nil {
+		return nil, fmt.Errorf("source ports: %w", err)
+	}
+	dstPort, err := CompressNumeric(f.DstPort)
+	if err != nil {
+		return nil, fmt.Errorf("destination ports: %w", err)
+	}
+	proto, err := CompressNumeric(f.Protocol)
+	if err != nil {
+		return nil, fmt.Errorf("protocols: %w", err)
+	}
+
+	sections := append(src, dst...)
+	sections = append(sections, srcPort, dstPort, proto)
+	return encodeContainer(containerFlows, sections...), nil
+}
+
+// DecompressFlows restores flow records compressed by CompressFlows.
+func DecompressFlows(compressed []byte) (FlowColumns, error) {
+	var f FlowColumns
+	if len(compressed) == 0 {
+		return f, ErrEmptyInput
+	}
+	sections, err := decodeContainer(compressed, containerFlows, 2*ipColumnSections+3)
+	if err != nil {
+		return f, err
+	}
+
+	if f.SrcAddr, err = decompressIPColumn(sections[:ipColumnSections]); err != nil {
+		return f, fmt.Errorf("source addresses: %w", err)
+	}
+	sections = sections[ipColumnSections:]
+	if f.DstAddr, err = decompressIPColumn(sections[:ipColumnSections]); err != nil {
+		return f, fmt.Errorf("destination addresses: %w", err)
+	}
+	sections = sections[ipColumnSections:]
+	if f.SrcPort, err = DecompressNumeric[uint16](sections[0]); err != nil {
+		return f, fmt.Errorf("source ports: %w", err)
+	}
+	if f.DstPort, err = DecompressNumeric[uint16](sections[1]); err != nil {
+		return f, fmt.Errorf("destination ports: %w", err)
+	}
+	if f.Protocol, err = DecompressNumeric[uint8](sections[2]); err != nil {
+		return f, fmt.Errorf("protocols: %w", err)
+	}
+	if f.Len() < 0 {
+		return FlowColumns{}, fmt.Errorf("%w: flow columns differ in length", ErrCorruptedData)
+	}
+	return f, nil
+}
+
+// ipColumnSections is the number of container sections for one IP column:
+// the family of each address, IPv4 byte planes, and IPv6 byte planes.
+const ipColumnSections = 3
+
+// compressIPColumn encodes addrs as ipColumnSections sections. Empty
+// sections are stored for families that do not occur.
+func compressIPColumn(addrs []netip.Addr) ([][]byte, error) {
+	family := make([]byte, len(addrs))
+	var v4, v6 []byte
+	for i, a := range addrs {
+		switch {
+		case !a.IsValid():
+			return nil, fmt.Errorf("%w: invalid address at index %d", ErrInvalidParameter, i)
+		case a.Zone() != "":
+			return nil, fmt.Errorf("%w: zoned address %s at index %d", ErrInvalidParameter, a, i)
+		case a.Is4():
+			family[i] = ipFamily4
+			b := a.As4()
+			v4 = append(v4, b[:]...)
+		default:
+			family[i] = ipFamily6
+			b := a.As16()
+			v6 = append(v6, b[:]...)
+		}
+	}
+
+	sections := make([][]byte, ipColumnSections)
+	var err error
+	if sections[0], err = Compress(family); err != nil {
+		return nil, err
+	}
+	if len(v4) > 0 {
+		if sections[1], err = Compress(splitBytePlanes(v4, 4)); err != nil {
+			return nil, err
+		}
+	}
+	if len(v6) > 0 {
+		if sections[2], err = Compress(splitBytePlanes(v6, 16)); err != nil {
+			return nil, err
+		}
+	}
+	return sections, nil
+}
+
+// decompressIPColumn reverses compressIPColumn.
+func decompressIPColumn(sections [][]byte) ([]netip.Addr, error) {
+	family, err := Decompress(sections[0])
+	if err != nil {
+		return nil, err
+	}
+	var v4, v6 []byte
+	if len(sections[1]) > 0 {
+		planes, err := Decompress(sections[1])
+		if err != nil {
+			return nil, err
+		}
+		v4 = joinBytePlanes(planes, 4)
+	}
+	if len(sections[2]) > 0 {
+		planes, err := Decompress(sections[2])
+		if err != nil {
+			return nil, err
+		}
+		v6 = joinBytePlanes(planes, 16)
+	}
+
+	addrs := make([]netip.Addr, len(family))
+	for i, f := range family {
+		switch {
+		case f == ipFamily4 && len(v4) >= 4:
+			addrs[i] = netip.AddrFrom4([4]byte(v4[:4]))
+			v4 = v4[4:]
+		case f == ipFamily6 && len(v6) >= 16:
+			addrs[i] = netip.AddrFrom16([16]byte(v6[:16]))
+			v6 = v6[16:]
+		default:
+			return nil, fmt.Errorf("%w: address column does not match family column", ErrCorruptedData)
+		}
+	}
+	if len(v4) != 0 || len(v6) != 0 {
+		return nil, fmt.Errorf("%w: address column does not match family column", ErrCorruptedData)
+	}
+	return addrs, nil
+}
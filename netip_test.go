@@ -0,0 +1,80 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"errors"
+	"net/netip"
+	"slices"
+	"testing"
+)
+
+func testAddrs(n int) []netip.Addr {
+	addrs := make([]netip.Addr, n)
+	for i := range addrs {
+		if i%5 == 0 {
+			addrs[i] = netip.AddrFrom16([16]byte{0x20, 0x01, 0x0d, 0xb8, 15: byte(i)})
+		} else {
+			addrs[i] = netip.AddrFrom4([4]byte{10, 0, byte(i >> 8), byte(i)})
+		}
+	}
+	return addrs
+}
+
+func TestCompressIPs(t *testing.T) {
+	addrs := testAddrs(1000)
+	addrs[3] = netip.MustParseAddr("::ffff:192.0.2.1") // IPv4-mapped stays IPv6
+
+	compressed, err := CompressIPs(addrs)
+	if err != nil {
+		t.Fatalf("CompressIPs() failed: %v", err)
+	}
+	got, err := DecompressIPs(compressed)
+	if err != nil {
+		t.Fatalf("DecompressIPs() failed: %v", err)
+	}
+	if !slices.Equal(got, addrs) {
+		t.Error("decompressed addresses do not match")
+	}
+
+	zoned := []netip.Addr{netip.MustParseAddr("fe80::1%eth0")}
+	if _, err := CompressIPs(zoned); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("CompressIPs(zoned) error = %v, want ErrInvalidParameter", err)
+	}
+}
+
+func TestCompressFlows(t *testing.T) {
+	n := 500
+	f := FlowColumns{
+		SrcAddr:  testAddrs(n),
+		DstAddr:  testAddrs(n),
+		SrcPort:  make([]uint16, n),
+		DstPort:  make([]uint16, n),
+		Protocol: make([]uint8, n),
+	}
+	for i := 0; i < n; i++ {
+		f.SrcPort[i] = uint16(49152 + i)
+		f.DstPort[i] = 443
+		f.Protocol[i] = 6
+	}
+
+	compressed, err := CompressFlows(f)
+	if err != nil {
+		t.Fatalf("CompressFlows() failed: %v", err)
+	}
+	got, err := DecompressFlows(compressed)
+	if err != nil {
+		t.Fatalf("DecompressFlows() failed: %v", err)
+	}
+	if !slices.Equal(got.SrcAddr, f.SrcAddr) || !slices.Equal(got.DstAddr, f.DstAddr) ||
+		!slices.Equal(got.SrcPort, f.SrcPort) || !slices.Equal(got.DstPort, f.DstPort) ||
+		!slices.Equal(got.Protocol, f.Protocol) {
+		t.Error("decompressed flows do not match")
+	}
+
+	f.Protocol = f.Protocol[:1]
+	if _, err := CompressFlows(f); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("CompressFlows() with ragged columns error = %v, want ErrInvalidParameter", err)
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Package openzltest provides test helpers for code using go-openzl.
+//
+// OpenZL allocates its contexts, typed references, and working buffers from
+// the C heap, which Go's race detector, memory profiler, and runtime
+// statistics cannot see. A missing Close or a leak inside the bindings only
+// shows up as slowly growing process memory. CheckLeaks turns that symptom
+// into a test failure.
+package openzltest
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"testing"
+
+	"github.com/borischu/go-openzl/internal/cgo"
+)
+
+// Defaults for CheckLeaks.
+const (
+	DefaultIterations = 1_000_000
+	DefaultSamples    = 10
+	DefaultTolerance  = 4 << 20
+)
+
+// leakConfig holds the settings of one CheckLeaks run.
+type leakConfig struct {
+	iterations int
+	samples    int
+	warmup     int
+	tolerance  int64
+}
+
+// LeakOption configures CheckLeaks.
+type LeakOption func(*leakConfig)
+
+// WithIterations sets the number of measured calls (DefaultIterations).
+func WithIterations(n int) LeakOption {
+	return func(cfg *leakConfig) { cfg.iterations = n }
+}
+
+// WithSamples sets how many times memory is sampled while the measured calls
+// run (DefaultSamples).
+func WithSamples(n int) LeakOption {
+	return func(cfg *leakConfig) { cfg.samples = n }
+}
+
+// WithWarmup sets the number of unmeasured calls made first, so that caches,
+// pools, and allocator arenas reach their steady state. It defaults to 1% of
+// the iterations.
+func WithWarmup(n int) LeakOption {
+	return func(cfg *leakConfig) { cfg.warmup = n }
+}
+
+// WithTolerance sets the largest memory growth in bytes, between the first
+// and the last sample, that is not reported as a leak (DefaultTolerance).
+func WithTolerance(bytes int64) LeakOption {
+	return func(cfg *leakConfig) { cfg.tolerance = bytes }
+}
+
+// sample is a memory measurement taken between measured calls.
+type sample struct {
+	heap int64 // C heap in use, or -1 if unknown
+	rss  int64 // Resident set size, or -1 if unknown
+}
+
+// take measures memory after collecting garbage, so that finalizers of
+// unreachable contexts have run and freed their native memory.
+func take() sample {
+	runtime.GC()
+	runtime.GC()
+	debug.FreeOSMemory()
+
+	s := sample{heap: -1, rss: -1}
+	if n, ok := cgo.HeapInUse(); ok {
+		s.heap = n
+	}
+	if n, ok := residentSetSize(); ok {
+		s.rss = n
+	}
+	return s
+}
+
+// CheckLeaks calls fn many times and fails tb if native or resident memory
+// keeps growing.
+//
+// After a warm-up, fn is called the configured number of times while memory
+// is sampled at regular intervals. Memory is measured with the C allocator's
+// statistics where available (glibc) and the process resident set size where
+// available (Linux). If either grows by more than the tolerance between the
+// first and the last sample, the test fails with the per-call growth and all
+// samples. A leak of a few bytes per call is therefore only caught over many
+// calls: keep the default of a million iterations when soak testing.
+//
+// CheckLeaks is skipped in short mode and on platforms where neither
+// statistic is available. It fails tb immediately if fn returns an error.
+//
+// Example:
+//
+//	func TestCompressorNoLeaks(t *testing.T) {
+//		c, _ := openzl.NewCompressor()
+//		defer c.Close()
+//		openzltest.CheckLeaks(t, func() error {
+//			_, err := c.Compress(data)
+//			return err
+//		})
+//	}
+func CheckLeaks(tb testing.TB, fn func() error, opts ...LeakOption) {
+	tb.Helper()
+	if testing.Short() {
+		tb.Skip("skipping leak check in short mode")
+	}
+
+	cfg := leakConfig{
+		iterations: DefaultIterations,
+		samples:    DefaultSamples,
+		warmup:     -1,
+		tolerance:  DefaultTolerance,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.warmup < 0 {
+		cfg.warmup = cfg.iterations / 100
+	}
+	if cfg.samples < 2 || cfg.iterations < cfg.samples {
+		tb.Fatalf("openzltest: need at least 2 samples and one iteration per sample, got %d samples for %d iterations", cfg.samples, cfg.iterations)
+	}
+
+	call := func(i int) {
+		if err := fn(); err != nil {
+			tb.Fatalf("openzltest: call %d failed: %v", i, err)
+		}
+	}
+	for i := range cfg.warmup {
+		call(i)
+	}
+
+	samples := []sample{take()}
+	if samples[0].heap < 0 && samples[0].rss < 0 {
+		tb.Skip("openzltest: no memory statistics available on this platform")
+	}
+	done := 0
+	for s := 1; s < cfg.samples; s++ {
+		// Spread the iterations evenly over the sampling intervals
+		until := cfg.iterations * s / (cfg.samples - 1)
+		for ; done < until; done++ {
+			call(cfg.warmup + done)
+		}
+		samples = append(samples, take())
+	}
+
+	first, last := samples[0], samples[len(samples)-1]
+	check := func(name string, before, after int64) {
+		growth := after - before
+		if before < 0 || growth <= cfg.tolerance {
+			return
+		}
+		tb.Errorf("openzltest: %s grew by %d bytes over %d calls (%.1f bytes/call), tolerance %d\n%s",
+			name, growth, cfg.iterations, float64(growth)/float64(cfg.iterations), cfg.tolerance, formatSamples(samples))
+	}
+	check("C heap", first.heap, last.heap)
+	check("resident memory", first.rss, last.rss)
+}
+
+// formatSamples formats samples as a table for failure messages.
+func formatSamples(samples []sample) string {
+	s := "sample        C heap   resident\n"
+	for i, x := range samples {
+		s += fmt.Sprintf("%6d %13d %10d\n", i, x.heap, x.rss)
+	}
+	return s
+}
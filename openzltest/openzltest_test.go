@@ -0,0 +1,52 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzltest
+
+import (
+	"fmt"
+	"testing"
+)
+
+// recordingTB captures failures instead of failing the test.
+type recordingTB struct {
+	*testing.T
+	failures []string
+}
+
+func (r *recordingTB) Errorf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestCheckLeaks(t *testing.T) {
+	if _, ok := residentSetSize(); !ok {
+		t.Skip("resident set size not available")
+	}
+
+	t.Run("steady", func(t *testing.T) {
+		tb := &recordingTB{T: t}
+		CheckLeaks(tb, func() error {
+			_ = make([]byte, 1024)
+			return nil
+		}, WithIterations(10000))
+		if len(tb.failures) != 0 {
+			t.Errorf("steady workload reported as leaking: %v", tb.failures)
+		}
+	})
+
+	t.Run("leaking", func(t *testing.T) {
+		var retained [][]byte
+		tb := &recordingTB{T: t}
+		CheckLeaks(tb, func() error {
+			b := make([]byte, 1024)
+			for i := range b {
+				b[i] = 1
+			}
+			retained = append(retained, b)
+			return nil
+		}, WithIterations(10000))
+		if len(tb.failures) == 0 {
+			t.Error("leaking workload not reported")
+		}
+	})
+}
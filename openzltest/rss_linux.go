@@ -0,0 +1,30 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+//go:build linux
+
+package openzltest
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+)
+
+// residentSetSize returns the resident set size of the process in bytes.
+func residentSetSize() (int64, bool) {
+	// The second field of statm is the resident size in pages
+	b, err := os.ReadFile("/proc/self/statm")
+	if err != nil {
+		return 0, false
+	}
+	fields := bytes.Fields(b)
+	if len(fields) < 2 {
+		return 0, false
+	}
+	pages, err := strconv.ParseInt(string(fields[1]), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return pages * int64(os.Getpagesize()), true
+}
@@ -0,0 +1,11 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+//go:build !linux
+
+package openzltest
+
+// residentSetSize reports that the resident set size is unavailable.
+func residentSetSize() (int64, bool) {
+	return 0, false
+}
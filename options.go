@@ -3,29 +3,64 @@
 
 package openzl
 
-// This file will contain configuration options for Compressor.
+import "github.com/borischu/go-openzl/internal/cgo"
+
+// This file contains configuration options for Compressor.
 //
 // Note: Phase 2 establishes the options pattern framework.
-// Actual option implementations (WithCompressionLevel, WithChecksum, etc.)
-// will be added as we discover which OpenZL parameters are available and useful.
+// Further option implementations (WithChecksum, etc.) will be added as we
+// discover which OpenZL parameters are available and useful.
+
+// MinCompressionLevel and MaxCompressionLevel bound the levels accepted by
+// WithCompressionLevel and WithDefaultLevel, as supported by the linked
+// OpenZL library.
+const (
+	MinCompressionLevel = cgo.MinCompressionLevel
+	MaxCompressionLevel = cgo.MaxCompressionLevel
+)
+
+// WithCompressionLevel sets the compression level of the Compressor,
+// overriding the package default set with WithDefaultLevel. Higher levels
+// compress better but more slowly.
 //
-// For now, the config struct exists but has no fields.
-// This allows the API to accept options without breaking changes when we add them.
+// Level 0 keeps the package default; otherwise the level must be between
+// MinCompressionLevel and MaxCompressionLevel, or NewCompressor returns an
+// *OptionError.
+//
+// Example:
+//
+//	fast, err := openzl.NewCompressor(openzl.WithCompressionLevel(openzl.MinCompressionLevel))
+//	...
+//	archive, err := openzl.NewCompressor(openzl.WithCompressionLevel(openzl.MaxCompressionLevel))
+func WithCompressionLevel(level int) CompressorOption {
+	return func(cfg *config) error {
+		if level != 0 && (level < MinCompressionLevel || level > MaxCompressionLevel) {
+			return rangeError("WithCompressionLevel", level, 0, MaxCompressionLevel)
+		}
+		cfg.compressionLevel = level
+		return nil
+	}
+}
 
-// Example future options:
+// WithStageReport enables per-stage compression reports.
 //
-// WithCompressionLevel sets the compression level (1-9).
-// Higher levels provide better compression but are slower.
+// When enabled, the Compressor records how many bytes entered and left each
+// codec in the compression graph, available from LastReport after every
+// Compress call. This shows whether, for example, delta or entropy stages are
+// doing the work when tuning a graph.
 //
-//	func WithCompressionLevel(level int) CompressorOption {
-//		return func(cfg *config) error {
-//			if level < 1 || level > 9 {
-//				return fmt.Errorf("compression level must be 1-9, got %d", level)
-//			}
-//			cfg.compressionLevel = level
-//			return nil
-//		}
-//	}
+// Recording uses OpenZL's introspection hooks and adds a small per-codec
+// overhead, so it is disabled by default. Libraries built without the hooks
+// report Features().StageReport as false, and NewCompressor then fails when
+// this option is enabled.
+func WithStageReport(enabled bool) CompressorOption {
+	return func(cfg *config) error {
+		cfg.stageReport = enabled
+		return nil
+	}
+}
+
+// Example future options:
 //
 // WithChecksum enables checksum verification.
 //
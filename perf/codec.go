@@ -0,0 +1,34 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package perf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+)
+
+// Gzip is the standard library's gzip at its default level, a baseline that
+// needs no dependency outside the standard library.
+var Gzip = Codec{
+	Name: "gzip",
+	Compress: func(src []byte) ([]byte, error) {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(src); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	},
+	Decompress: func(src []byte) ([]byte, error) {
+		r, err := gzip.NewReader(bytes.NewReader(src))
+		if err != nil {
+			return nil, err
+		}
+		return io.ReadAll(r)
+	},
+}
@@ -1,69 +1,30 @@
-package openzl
+package compare
 
 import (
 	"bytes"
 	"compress/gzip"
 	"testing"
 
+	"github.com/borischu/go-openzl"
+	"github.com/borischu/go-openzl/datagen"
 	"github.com/klauspost/compress/zstd"
 )
 
-// Benchmark data generators
-func generateRepeatedData(size int) []byte {
-	pattern := []byte("This is a test pattern that repeats. ")
-	data := make([]byte, 0, size)
-	for len(data) < size {
-		data = append(data, pattern...)
-	}
-	return data[:size]
-}
-
-func generateMixedData(size int) []byte {
-	// Mix of repeated and varied data
-	data := make([]byte, size)
-	for i := range data {
-		if i%100 < 50 {
-			// Repeated pattern
-			data[i] = byte(i % 10)
-		} else {
-			// More varied
-			data[i] = byte((i * 7) % 256)
-		}
-	}
-	return data
-}
-
-func generateTextData(size int) []byte {
-	// Simulate text with spaces and common words
-	words := []string{"the", "quick", "brown", "fox", "jumps", "over", "lazy", "dog"}
-	var buf bytes.Buffer
-	for buf.Len() < size {
-		for _, word := range words {
-			buf.WriteString(word)
-			buf.WriteByte(' ')
-			if buf.Len() >= size {
-				break
-			}
-		}
-	}
-	return buf.Bytes()[:size]
-}
-
 // ============================================================================
 // Small Data Benchmarks (1KB)
 // ============================================================================
 
 func BenchmarkSmall_OpenZL(b *testing.B) {
-	data := generateRepeatedData(1024)
+	data := datagen.Repeated(1024)
 	b.ResetTimer()
 	b.ReportAllocs()
 
 	for i := 0; i < b.N; i++ {
-		compressed, err := Compress(data)
+		compressed, err := openzl.Compress(data)
 		if err != nil {
 			b.Fatal(err)
 		}
-		_, err = Decompress(compressed)
+		_, err = openzl.Decompress(compressed)
 		if err != nil {
 			b.Fatal(err)
 		}
@@ -71,7 +32,7 @@ func BenchmarkSmall_OpenZL(b *testing.B) {
 }
 
 func BenchmarkSmall_Gzip(b *testing.B) {
-	data := generateRepeatedData(1024)
+	data := datagen.Repeated(1024)
 	b.ResetTimer()
 	b.ReportAllocs()
 
@@ -98,7 +59,7 @@ func BenchmarkSmall_Gzip(b *testing.B) {
 }
 
 func BenchmarkSmall_Zstd(b *testing.B) {
-	data := generateRepeatedData(1024)
+	data := datagen.Repeated(1024)
 	encoder, _ := zstd.NewWriter(nil)
 	decoder, _ := zstd.NewReader(nil)
 	defer encoder.Close()
@@ -121,16 +82,16 @@ func BenchmarkSmall_Zstd(b *testing.B) {
 // ============================================================================
 
 func BenchmarkMedium_OpenZL(b *testing.B) {
-	data := generateRepeatedData(100 * 1024)
+	data := datagen.Repeated(100 * 1024)
 	b.ResetTimer()
 	b.ReportAllocs()
 
 	for i := 0; i < b.N; i++ {
-		compressed, err := Compress(data)
+		compressed, err := openzl.Compress(data)
 		if err != nil {
 			b.Fatal(err)
 		}
-		_, err = Decompress(compressed)
+		_, err = openzl.Decompress(compressed)
 		if err != nil {
 			b.Fatal(err)
 		}
@@ -138,7 +99,7 @@ func BenchmarkMedium_OpenZL(b *testing.B) {
 }
 
 func BenchmarkMedium_Gzip(b *testing.B) {
-	data := generateRepeatedData(100 * 1024)
+	data := datagen.Repeated(100 * 1024)
 	b.ResetTimer()
 	b.ReportAllocs()
 
@@ -156,7 +117,7 @@ func BenchmarkMedium_Gzip(b *testing.B) {
 }
 
 func BenchmarkMedium_Zstd(b *testing.B) {
-	data := generateRepeatedData(100 * 1024)
+	data := datagen.Repeated(100 * 1024)
 	encoder, _ := zstd.NewWriter(nil)
 	decoder, _ := zstd.NewReader(nil)
 	defer encoder.Close()
@@ -176,16 +137,16 @@ func BenchmarkMedium_Zstd(b *testing.B) {
 // ============================================================================
 
 func BenchmarkLarge_OpenZL(b *testing.B) {
-	data := generateRepeatedData(1024 * 1024)
+	data := datagen.Repeated(1024 * 1024)
 	b.ResetTimer()
 	b.ReportAllocs()
 
 	for i := 0; i < b.N; i++ {
-		compressed, err := Compress(data)
+		compressed, err := openzl.Compress(data)
 		if err != nil {
 			b.Fatal(err)
 		}
-		_, err = Decompress(compressed)
+		_, err = openzl.Decompress(compressed)
 		if err != nil {
 			b.Fatal(err)
 		}
@@ -193,7 +154,7 @@ func BenchmarkLarge_OpenZL(b *testing.B) {
 }
 
 func BenchmarkLarge_Gzip(b *testing.B) {
-	data := generateRepeatedData(1024 * 1024)
+	data := datagen.Repeated(1024 * 1024)
 	b.ResetTimer()
 	b.ReportAllocs()
 
@@ -211,7 +172,7 @@ func BenchmarkLarge_Gzip(b *testing.B) {
 }
 
 func BenchmarkLarge_Zstd(b *testing.B) {
-	data := generateRepeatedData(1024 * 1024)
+	data := datagen.Repeated(1024 * 1024)
 	encoder, _ := zstd.NewWriter(nil)
 	decoder, _ := zstd.NewReader(nil)
 	defer encoder.Close()
@@ -231,15 +192,15 @@ func BenchmarkLarge_Zstd(b *testing.B) {
 // ============================================================================
 
 func BenchmarkRatio_Repeated_OpenZL(b *testing.B) {
-	data := generateRepeatedData(100 * 1024)
-	compressed, _ := Compress(data)
+	data := datagen.Repeated(100 * 1024)
+	compressed, _ := openzl.Compress(data)
 	ratio := float64(len(data)) / float64(len(compressed))
 	b.ReportMetric(ratio, "ratio")
 	b.ReportMetric(float64(len(compressed)), "compressed_bytes")
 }
 
 func BenchmarkRatio_Repeated_Gzip(b *testing.B) {
-	data := generateRepeatedData(100 * 1024)
+	data := datagen.Repeated(100 * 1024)
 	var buf bytes.Buffer
 	w := gzip.NewWriter(&buf)
 	w.Write(data)
@@ -250,7 +211,7 @@ func BenchmarkRatio_Repeated_Gzip(b *testing.B) {
 }
 
 func BenchmarkRatio_Repeated_Zstd(b *testing.B) {
-	data := generateRepeatedData(100 * 1024)
+	data := datagen.Repeated(100 * 1024)
 	encoder, _ := zstd.NewWriter(nil)
 	defer encoder.Close()
 	compressed := encoder.EncodeAll(data, nil)
@@ -260,15 +221,15 @@ func BenchmarkRatio_Repeated_Zstd(b *testing.B) {
 }
 
 func BenchmarkRatio_Mixed_OpenZL(b *testing.B) {
-	data := generateMixedData(100 * 1024)
-	compressed, _ := Compress(data)
+	data := datagen.Mixed(100 * 1024)
+	compressed, _ := openzl.Compress(data)
 	ratio := float64(len(data)) / float64(len(compressed))
 	b.ReportMetric(ratio, "ratio")
 	b.ReportMetric(float64(len(compressed)), "compressed_bytes")
 }
 
 func BenchmarkRatio_Mixed_Gzip(b *testing.B) {
-	data := generateMixedData(100 * 1024)
+	data := datagen.Mixed(100 * 1024)
 	var buf bytes.Buffer
 	w := gzip.NewWriter(&buf)
 	w.Write(data)
@@ -279,7 +240,7 @@ func BenchmarkRatio_Mixed_Gzip(b *testing.B) {
 }
 
 func BenchmarkRatio_Mixed_Zstd(b *testing.B) {
-	data := generateMixedData(100 * 1024)
+	data := datagen.Mixed(100 * 1024)
 	encoder, _ := zstd.NewWriter(nil)
 	defer encoder.Close()
 	compressed := encoder.EncodeAll(data, nil)
@@ -289,15 +250,15 @@ func BenchmarkRatio_Mixed_Zstd(b *testing.B) {
 }
 
 func BenchmarkRatio_Text_OpenZL(b *testing.B) {
-	data := generateTextData(100 * 1024)
-	compressed, _ := Compress(data)
+	data := datagen.Text(100 * 1024)
+	compressed, _ := openzl.Compress(data)
 	ratio := float64(len(data)) / float64(len(compressed))
 	b.ReportMetric(ratio, "ratio")
 	b.ReportMetric(float64(len(compressed)), "compressed_bytes")
 }
 
 func BenchmarkRatio_Text_Gzip(b *testing.B) {
-	data := generateTextData(100 * 1024)
+	data := datagen.Text(100 * 1024)
 	var buf bytes.Buffer
 	w := gzip.NewWriter(&buf)
 	w.Write(data)
@@ -308,7 +269,7 @@ func BenchmarkRatio_Text_Gzip(b *testing.B) {
 }
 
 func BenchmarkRatio_Text_Zstd(b *testing.B) {
-	data := generateTextData(100 * 1024)
+	data := datagen.Text(100 * 1024)
 	encoder, _ := zstd.NewWriter(nil)
 	defer encoder.Close()
 	compressed := encoder.EncodeAll(data, nil)
@@ -322,13 +283,13 @@ func BenchmarkRatio_Text_Zstd(b *testing.B) {
 // ============================================================================
 
 func BenchmarkCompressOnly_OpenZL(b *testing.B) {
-	data := generateRepeatedData(100 * 1024)
+	data := datagen.Repeated(100 * 1024)
 	b.ResetTimer()
 	b.ReportAllocs()
 	b.SetBytes(int64(len(data)))
 
 	for i := 0; i < b.N; i++ {
-		_, err := Compress(data)
+		_, err := openzl.Compress(data)
 		if err != nil {
 			b.Fatal(err)
 		}
@@ -336,7 +297,7 @@ func BenchmarkCompressOnly_OpenZL(b *testing.B) {
 }
 
 func BenchmarkCompressOnly_Gzip(b *testing.B) {
-	data := generateRepeatedData(100 * 1024)
+	data := datagen.Repeated(100 * 1024)
 	b.ResetTimer()
 	b.ReportAllocs()
 	b.SetBytes(int64(len(data)))
@@ -350,7 +311,7 @@ func BenchmarkCompressOnly_Gzip(b *testing.B) {
 }
 
 func BenchmarkCompressOnly_Zstd(b *testing.B) {
-	data := generateRepeatedData(100 * 1024)
+	data := datagen.Repeated(100 * 1024)
 	encoder, _ := zstd.NewWriter(nil)
 	defer encoder.Close()
 
@@ -368,15 +329,15 @@ func BenchmarkCompressOnly_Zstd(b *testing.B) {
 // ============================================================================
 
 func BenchmarkDecompressOnly_OpenZL(b *testing.B) {
-	data := generateRepeatedData(100 * 1024)
-	compressed, _ := Compress(data)
+	data := datagen.Repeated(100 * 1024)
+	compressed, _ := openzl.Compress(data)
 
 	b.ResetTimer()
 	b.ReportAllocs()
 	b.SetBytes(int64(len(data)))
 
 	for i := 0; i < b.N; i++ {
-		_, err := Decompress(compressed)
+		_, err := openzl.Decompress(compressed)
 		if err != nil {
 			b.Fatal(err)
 		}
@@ -384,7 +345,7 @@ func BenchmarkDecompressOnly_OpenZL(b *testing.B) {
 }
 
 func BenchmarkDecompressOnly_Gzip(b *testing.B) {
-	data := generateRepeatedData(100 * 1024)
+	data := datagen.Repeated(100 * 1024)
 	var buf bytes.Buffer
 	w := gzip.NewWriter(&buf)
 	w.Write(data)
@@ -404,7 +365,7 @@ func BenchmarkDecompressOnly_Gzip(b *testing.B) {
 }
 
 func BenchmarkDecompressOnly_Zstd(b *testing.B) {
-	data := generateRepeatedData(100 * 1024)
+	data := datagen.Repeated(100 * 1024)
 	encoder, _ := zstd.NewWriter(nil)
 	compressed := encoder.EncodeAll(data, nil)
 	encoder.Close()
@@ -427,20 +388,17 @@ func BenchmarkDecompressOnly_Zstd(b *testing.B) {
 
 func BenchmarkNumeric_Int64_OpenZL(b *testing.B) {
 	// Sequential int64 array - OpenZL's strength
-	data := make([]int64, 1000)
-	for i := range data {
-		data[i] = int64(i * 10)
-	}
+	data := datagen.Int64Sequence(1000)
 
 	b.ResetTimer()
 	b.ReportAllocs()
 
 	for i := 0; i < b.N; i++ {
-		compressed, err := CompressNumeric(data)
+		compressed, err := openzl.CompressNumeric(data)
 		if err != nil {
 			b.Fatal(err)
 		}
-		_, err = DecompressNumeric[int64](compressed)
+		_, err = openzl.DecompressNumeric[int64](compressed)
 		if err != nil {
 			b.Fatal(err)
 		}
@@ -449,10 +407,7 @@ func BenchmarkNumeric_Int64_OpenZL(b *testing.B) {
 
 func BenchmarkNumeric_Int64_AsBytes_Gzip(b *testing.B) {
 	// Convert int64 to bytes for gzip (typical approach)
-	data := make([]int64, 1000)
-	for i := range data {
-		data[i] = int64(i * 10)
-	}
+	data := datagen.Int64Sequence(1000)
 
 	// Convert to bytes
 	byteData := make([]byte, len(data)*8)
@@ -485,10 +440,7 @@ func BenchmarkNumeric_Int64_AsBytes_Gzip(b *testing.B) {
 }
 
 func BenchmarkNumeric_Int64_AsBytes_Zstd(b *testing.B) {
-	data := make([]int64, 1000)
-	for i := range data {
-		data[i] = int64(i * 10)
-	}
+	data := datagen.Int64Sequence(1000)
 
 	byteData := make([]byte, len(data)*8)
 	for i, v := range data {
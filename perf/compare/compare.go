@@ -0,0 +1,37 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Package compare provides codecs from outside the standard library for
+// measuring OpenZL against them with package perf.
+//
+// It is a separate module so that the go-openzl module itself does not
+// depend on a competing compressor. Only benchmarking code should import it.
+//
+// Example:
+//
+//	report, err := perf.Run(perf.Config{Codecs: compare.Codecs()})
+package compare
+
+import (
+	"github.com/borischu/go-openzl/perf"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Zstd returns a codec for github.com/klauspost/compress/zstd at its default
+// level. The encoder and decoder are created once and reused by every call.
+func Zstd() perf.Codec {
+	// NewWriter and NewReader only fail on invalid options.
+	enc, _ := zstd.NewWriter(nil)
+	dec, _ := zstd.NewReader(nil)
+	return perf.Codec{
+		Name:       "zstd",
+		Compress:   func(src []byte) ([]byte, error) { return enc.EncodeAll(src, nil), nil },
+		Decompress: func(src []byte) ([]byte, error) { return dec.DecodeAll(src, nil) },
+	}
+}
+
+// Codecs returns every codec OpenZL is usually compared with: perf.Gzip and
+// Zstd.
+func Codecs() []perf.Codec {
+	return []perf.Codec{perf.Gzip, Zstd()}
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package compare
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/borischu/go-openzl/datagen"
+	"github.com/borischu/go-openzl/perf"
+)
+
+func TestCodecs_RoundTrip(t *testing.T) {
+	data := datagen.Text(64 << 10)
+	for _, c := range Codecs() {
+		compressed, err := c.Compress(data)
+		if err != nil {
+			t.Fatalf("%s: Compress() failed: %v", c.Name, err)
+		}
+		got, err := c.Decompress(compressed)
+		if err != nil {
+			t.Fatalf("%s: Decompress() failed: %v", c.Name, err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("%s: round trip mismatch", c.Name)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	report, err := perf.Run(perf.Config{Workloads: []string{"text"}, Size: 64 << 10, Duration: time.Millisecond, Codecs: Codecs()})
+	if err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+	if len(report.Results) != 3 || report.Results[2].Codec != "zstd" {
+		t.Errorf("unexpected results: %+v", report.Results)
+	}
+}
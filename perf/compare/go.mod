@@ -0,0 +1,11 @@
+module github.com/borischu/go-openzl/perf/compare
+
+go 1.24.4
+
+require (
+	github.com/borischu/go-openzl v0.0.0
+	github.com/klauspost/compress v1.18.1
+)
+
+// Benchmarks always measure the bindings in this checkout.
+replace github.com/borischu/go-openzl => ../..
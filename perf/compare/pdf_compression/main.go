@@ -1,3 +1,9 @@
+// Command pdf_compression compares OpenZL with zstd on a PDF file, by
+// default the examples/test.pdf of the repository.
+//
+// Usage:
+//
+//	go run ./pdf_compression [file.pdf]
 package main
 
 import (
@@ -11,7 +17,10 @@ import (
 
 func main() {
 	// Read the PDF file
-	pdfPath := "../test.pdf"
+	pdfPath := "../../examples/test.pdf"
+	if len(os.Args) > 1 {
+		pdfPath = os.Args[1]
+	}
 	data, err := os.ReadFile(pdfPath)
 	if err != nil {
 		fmt.Printf("Error reading PDF: %v\n", err)
@@ -0,0 +1,347 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Package perf runs go-openzl's standardized benchmarks and compares their
+// results against a saved baseline.
+//
+// It is meant for gating upgrades of the vendored OpenZL library: record a
+// baseline with the current library, upgrade, then run again and check that
+// neither throughput nor compression ratio regressed beyond a threshold.
+// Workloads are built from the deterministic datagen corpora, so ratios are
+// reproducible across machines; throughput is only comparable on the same
+// hardware.
+//
+// Example:
+//
+//	baseline, err := perf.LoadReport("baseline.json")
+//	...
+//	report, err := perf.Run(perf.Config{})
+//	...
+//	if regs := perf.Compare(baseline, report, perf.DefaultThresholds); len(regs) > 0 {
+//		for _, r := range regs {
+//			log.Println(r)
+//		}
+//		os.Exit(1)
+//	}
+//
+// Other codecs can be measured on the same workloads for comparison by
+// listing them in Config.Codecs. Gzip is built in; codecs that need a third
+// party dependency, such as zstd, live in the separate module
+// github.com/borischu/go-openzl/perf/compare, so that importing go-openzl
+// never pulls in a competing compressor.
+//
+// The zlgo command wraps this package as `zlgo perf`.
+package perf
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"slices"
+	"time"
+
+	"github.com/borischu/go-openzl"
+	"github.com/borischu/go-openzl/datagen"
+)
+
+// Default benchmark parameters, used for zero Config fields.
+const (
+	DefaultSize     = 1 << 20
+	DefaultDuration = 200 * time.Millisecond
+)
+
+// Config selects the workloads to run and how long to measure each.
+type Config struct {
+	// Workloads lists the workloads to run by name. Empty runs all of
+	// Workloads().
+	Workloads []string
+
+	// Size is the input size of each workload in bytes (DefaultSize if 0).
+	Size int
+
+	// Duration is the minimum time spent measuring each of compression and
+	// decompression per workload (DefaultDuration if 0).
+	Duration time.Duration
+
+	// Codecs lists other codecs to measure on the same inputs, after
+	// OpenZL. They compress the raw bytes of each workload, including the
+	// little-endian encoding of the numeric workloads.
+	Codecs []Codec
+}
+
+// Codec is a compressor to compare OpenZL against.
+type Codec struct {
+	Name       string
+	Compress   func(src []byte) ([]byte, error)
+	Decompress func(src []byte) ([]byte, error)
+}
+
+// Result holds the measurements for one workload.
+type Result struct {
+	Workload       string  `json:"workload"`
+	Codec          string  `json:"codec,omitempty"` // Empty for OpenZL
+	InputSize      int     `json:"input_size"`
+	CompressedSize int     `json:"compressed_size"`
+	Ratio          float64 `json:"ratio"`
+	CompressMBps   float64 `json:"compress_mbps"`
+	DecompressMBps float64 `json:"decompress_mbps"`
+}
+
+// Report is the outcome of Run. It is serialized as JSON for baselines.
+type Report struct {
+	Version       string    `json:"version"`
+	OpenZLVersion string    `json:"openzl_version"`
+	GOOS          string    `json:"goos"`
+	GOARCH        string    `json:"goarch"`
+	Time          time.Time `json:"time"`
+	Results       []Result  `json:"results"`
+}
+
+// workload compresses and decompresses one standardized input.
+type workload struct {
+	name string
+	// setup generates the input and returns its raw bytes along with
+	// functions performing one OpenZL compression and decompression of it.
+	setup func(size int, c *openzl.Compressor, d *openzl.Decompressor) (raw []byte, compress func() ([]byte, error), decompress func([]byte) error)
+}
+
+// bytesWorkload benchmarks the generic byte graph on a datagen corpus.
+func bytesWorkload(name string, gen func(int) []byte) workload {
+	return workload{name, func(size int, c *openzl.Compressor, d *openzl.Decompressor) ([]byte, func() ([]byte, error), func([]byte) error) {
+		data := gen(size)
+		return data,
+			func() ([]byte, error) { return c.Compress(data) },
+			func(b []byte) error { _, err := d.Decompress(b); return err }
+	}}
+}
+
+// numericWorkload benchmarks the numeric graph on a datagen column.
+func numericWorkload[T openzl.Numeric](name string, gen func(int) []T) workload {
+	return workload{name, func(size int, c *openzl.Compressor, d *openzl.Decompressor) ([]byte, func() ([]byte, error), func([]byte) error) {
+		data := gen(size / 8)
+		raw, _ := binary.Append(nil, binary.LittleEndian, data)
+		return raw,
+			func() ([]byte, error) { return openzl.CompressorCompressNumeric(c, data) },
+			func(b []byte) error { _, err := openzl.DecompressorDecompressNumeric[T](d, b); return err }
+	}}
+}
+
+// workloads is the standardized benchmark suite. Names are part of the
+// baseline format and must not change.
+var workloads = []workload{
+	bytesWorkload("repeated", datagen.Repeated),
+	bytesWorkload("mixed", datagen.Mixed),
+	bytesWorkload("text", datagen.Text),
+	bytesWorkload("logs", datagen.Logs),
+	bytesWorkload("csv", datagen.CSV),
+	bytesWorkload("random", datagen.Random),
+	numericWorkload("int64-sequence", datagen.Int64Sequence),
+	numericWorkload("timestamps", datagen.Timestamps),
+	numericWorkload("float64-walk", datagen.Float64Walk),
+}
+
+// Workloads returns the names of all available workloads.
+func Workloads() []string {
+	names := make([]string, len(workloads))
+	for i, w := range workloads {
+		names[i] = w.name
+	}
+	return names
+}
+
+// Run runs the selected workloads and returns their results.
+//
+// Returns an error if a workload name is unknown, if Size is too small for
+// the numeric workloads, or if compression or decompression fails.
+func Run(cfg Config) (*Report, error) {
+	if cfg.Size == 0 {
+		cfg.Size = DefaultSize
+	}
+	if cfg.Duration == 0 {
+		cfg.Duration = DefaultDuration
+	}
+	if cfg.Size < 8 {
+		return nil, fmt.Errorf("size must be at least 8 bytes, got %d", cfg.Size)
+	}
+
+	selected := workloads
+	if len(cfg.Workloads) > 0 {
+		selected = nil
+		for _, name := range cfg.Workloads {
+			i := slices.IndexFunc(workloads, func(w workload) bool { return w.name == name })
+			if i < 0 {
+				return nil, fmt.Errorf("unknown workload %q", name)
+			}
+			selected = append(selected, workloads[i])
+		}
+	}
+
+	c, err := openzl.NewCompressor()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	d, err := openzl.NewDecompressor()
+	if err != nil {
+		return nil, err
+	}
+	defer d.Close()
+
+	report := &Report{
+		Version:       openzl.Version,
+		OpenZLVersion: openzl.OpenZLVersion(),
+		GOOS:          runtime.GOOS,
+		GOARCH:        runtime.GOARCH,
+		Time:          time.Now().UTC(),
+	}
+	for _, w := range selected {
+		raw, compress, decompress := w.setup(cfg.Size, c, d)
+		res, err := measure(len(raw), cfg, compress, decompress)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", w.name, err)
+		}
+		res.Workload = w.name
+		report.Results = append(report.Results, res)
+
+		for _, codec := range cfg.Codecs {
+			res, err := measure(len(raw), cfg,
+				func() ([]byte, error) { return codec.Compress(raw) },
+				func(b []byte) error { _, err := codec.Decompress(b); return err })
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", w.name, codec.Name, err)
+			}
+			res.Workload, res.Codec = w.name, codec.Name
+			report.Results = append(report.Results, res)
+		}
+	}
+	return report, nil
+}
+
+// measure runs compress and decompress on an input of in bytes for at least
+// cfg.Duration each.
+func measure(in int, cfg Config, compress func() ([]byte, error), decompress func([]byte) error) (Result, error) {
+	var compressed []byte
+	compressMBps, err := throughput(in, cfg.Duration, func() (err error) {
+		compressed, err = compress()
+		return err
+	})
+	if err != nil {
+		return Result{}, fmt.Errorf("compress: %w", err)
+	}
+	decompressMBps, err := throughput(in, cfg.Duration, func() error {
+		return decompress(compressed)
+	})
+	if err != nil {
+		return Result{}, fmt.Errorf("decompress: %w", err)
+	}
+
+	return Result{
+		InputSize:      in,
+		CompressedSize: len(compressed),
+		Ratio:          float64(in) / float64(len(compressed)),
+		CompressMBps:   compressMBps,
+		DecompressMBps: decompressMBps,
+	}, nil
+}
+
+// throughput calls fn repeatedly for at least d and returns the rate at
+// which it processed n bytes per call, in MB/s.
+func throughput(n int, d time.Duration, fn func() error) (float64, error) {
+	// Warm up once so the first call's allocations are not measured
+	if err := fn(); err != nil {
+		return 0, err
+	}
+	iters := 0
+	start := time.Now()
+	for time.Since(start) < d {
+		if err := fn(); err != nil {
+			return 0, err
+		}
+		iters++
+	}
+	elapsed := time.Since(start).Seconds()
+	return float64(n) * float64(iters) / elapsed / 1e6, nil
+}
+
+// Thresholds are the largest acceptable relative drops, as fractions of the
+// baseline value, before Compare reports a regression.
+type Thresholds struct {
+	Throughput float64 // Compression or decompression MB/s
+	Ratio      float64 // Compression ratio
+}
+
+// DefaultThresholds tolerates the run-to-run noise of throughput on a quiet
+// machine while catching any meaningful ratio change, which is deterministic.
+var DefaultThresholds = Thresholds{Throughput: 0.10, Ratio: 0.01}
+
+// Regression describes one metric of one workload that regressed.
+type Regression struct {
+	Workload string
+	Codec    string // Empty for OpenZL
+	Metric   string // "ratio", "compress_mbps", or "decompress_mbps"
+	Baseline float64
+	Current  float64
+}
+
+// Change returns the relative change from the baseline, negative for drops.
+func (r Regression) Change() float64 {
+	return (r.Current - r.Baseline) / r.Baseline
+}
+
+// String formats the regression for display.
+func (r Regression) String() string {
+	name := r.Workload
+	if r.Codec != "" {
+		name += "/" + r.Codec
+	}
+	return fmt.Sprintf("%s: %s %.2f -> %.2f (%+.1f%%)", name, r.Metric, r.Baseline, r.Current, 100*r.Change())
+}
+
+// Compare returns the metrics of current that dropped below baseline by more
+// than the thresholds allow. Results are matched by workload and codec;
+// results missing from either report are ignored.
+func Compare(baseline, current *Report, th Thresholds) []Regression {
+	var regs []Regression
+	check := func(cur Result, metric string, base, value, limit float64) {
+		if base > 0 && value < base*(1-limit) {
+			regs = append(regs, Regression{cur.Workload, cur.Codec, metric, base, value})
+		}
+	}
+	for _, cur := range current.Results {
+		i := slices.IndexFunc(baseline.Results, func(r Result) bool {
+			return r.Workload == cur.Workload && r.Codec == cur.Codec
+		})
+		if i < 0 {
+			continue
+		}
+		base := baseline.Results[i]
+		check(cur, "ratio", base.Ratio, cur.Ratio, th.Ratio)
+		check(cur, "compress_mbps", base.CompressMBps, cur.CompressMBps, th.Throughput)
+		check(cur, "decompress_mbps", base.DecompressMBps, cur.DecompressMBps, th.Throughput)
+	}
+	return regs
+}
+
+// LoadReport reads a report written by WriteReport.
+func LoadReport(path string) (*Report, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var r Report
+	if err := json.Unmarshal(b, &r); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return &r, nil
+}
+
+// WriteReport writes r to path as indented JSON.
+func WriteReport(path string, r *Report) error {
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
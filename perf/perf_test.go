@@ -0,0 +1,92 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package perf
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	report, err := Run(Config{Workloads: []string{"text", "timestamps"}, Size: 64 << 10, Duration: time.Millisecond})
+	if err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+	if len(report.Results) != 2 {
+		t.Fatalf("got %d results, want 2", len(report.Results))
+	}
+	for _, r := range report.Results {
+		if r.CompressedSize <= 0 || r.Ratio <= 0 || r.CompressMBps <= 0 || r.DecompressMBps <= 0 {
+			t.Errorf("%s: incomplete result %+v", r.Workload, r)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := WriteReport(path, report); err != nil {
+		t.Fatalf("WriteReport() failed: %v", err)
+	}
+	loaded, err := LoadReport(path)
+	if err != nil {
+		t.Fatalf("LoadReport() failed: %v", err)
+	}
+	if regs := Compare(loaded, report, Thresholds{}); len(regs) != 0 {
+		t.Errorf("report regressed against itself: %v", regs)
+	}
+}
+
+func TestRun_UnknownWorkload(t *testing.T) {
+	if _, err := Run(Config{Workloads: []string{"nope"}}); err == nil {
+		t.Error("Run() accepted an unknown workload")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	baseline := &Report{Results: []Result{
+		{Workload: "logs", Ratio: 10, CompressMBps: 100, DecompressMBps: 400},
+		{Workload: "csv", Ratio: 5, CompressMBps: 100, DecompressMBps: 400},
+	}}
+	current := &Report{Results: []Result{
+		{Workload: "logs", Ratio: 9.5, CompressMBps: 95, DecompressMBps: 300},
+		{Workload: "csv", Ratio: 5, CompressMBps: 100, DecompressMBps: 400},
+		{Workload: "random", Ratio: 1, CompressMBps: 1, DecompressMBps: 1},
+	}}
+
+	regs := Compare(baseline, current, Thresholds{Throughput: 0.10, Ratio: 0.01})
+	if len(regs) != 2 {
+		t.Fatalf("got %d regressions, want 2: %v", len(regs), regs)
+	}
+	if regs[0].Metric != "ratio" || regs[1].Metric != "decompress_mbps" {
+		t.Errorf("unexpected regressions: %v", regs)
+	}
+	if got := regs[1].Change(); got != -0.25 {
+		t.Errorf("Change() = %v, want -0.25", got)
+	}
+}
+
+func TestRun_Codecs(t *testing.T) {
+	report, err := Run(Config{Workloads: []string{"text", "timestamps"}, Size: 64 << 10, Duration: time.Millisecond, Codecs: []Codec{Gzip}})
+	if err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+	var names []string
+	for _, r := range report.Results {
+		names = append(names, r.Workload+"/"+r.Codec)
+		if r.InputSize != 64<<10 || r.Ratio <= 0 {
+			t.Errorf("%s/%s: incomplete result %+v", r.Workload, r.Codec, r)
+		}
+	}
+	want := []string{"text/", "text/gzip", "timestamps/", "timestamps/gzip"}
+	if !slices.Equal(names, want) {
+		t.Errorf("results = %v, want %v", names, want)
+	}
+
+	// A codec's results are only compared with the same codec's.
+	current := &Report{Results: []Result{{Workload: "text", Codec: "gzip", Ratio: 1, CompressMBps: 1, DecompressMBps: 1}}}
+	regs := Compare(report, current, Thresholds{})
+	if len(regs) == 0 || regs[0].Codec != "gzip" {
+		t.Errorf("Compare() = %v, want gzip regressions", regs)
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+)
+
+// Stage is one reversible step of a Pipeline, such as a preprocessor, the
+// compressor, or an encryptor.
+//
+// Forward and Reverse append their output to dst, as append does, and
+// return the extended slice. dst is a buffer recycled by the Pipeline, so
+// writing into it rather than returning a new slice is what saves the
+// allocation. Any other result, such as src itself or memory the stage
+// owns, is copied into the Pipeline's buffers before the next stage runs.
+// Neither function may retain dst or src after returning. Both must be safe
+// for concurrent use.
+type Stage struct {
+	Name    string
+	Forward func(dst, src []byte) ([]byte, error)
+	Reverse func(dst, src []byte) ([]byte, error)
+}
+
+// CodecStage adapts a Codec, such as OpenZLCodec or an application codec, to
+// a Stage. Its output is copied into the Pipeline's buffers.
+func CodecStage(c Codec) Stage {
+	return Stage{
+		Name: c.Name,
+		Forward: func(dst, src []byte) ([]byte, error) {
+			out, err := c.Compress(src)
+			return append(dst, out...), err
+		},
+		Reverse: func(dst, src []byte) ([]byte, error) {
+			out, err := c.Decompress(src)
+			return append(dst, out...), err
+		},
+	}
+}
+
+// CompressStage returns the Stage that compresses with Compress and
+// decompresses with Decompress.
+func CompressStage() Stage {
+	return CodecStage(OpenZLCodec)
+}
+
+// AEADStage returns a Stage that encrypts with aead, such as AES-GCM from
+// crypto/cipher. Each output starts with a random nonce of aead.NonceSize()
+// bytes, followed by the sealed data. Reverse returns ErrCorruptedData if
+// the data fails authentication.
+//
+// Random nonces limit how much data one key may seal; see the documentation
+// of the AEAD for its bound.
+func AEADStage(aead cipher.AEAD) Stage {
+	ns := aead.NonceSize()
+	return Stage{
+		Name: "aead",
+		Forward: func(dst, src []byte) ([]byte, error) {
+			nonce := make([]byte, ns)
+			if _, err := rand.Read(nonce); err != nil {
+				return dst, fmt.Errorf("generate nonce: %w", err)
+			}
+			return aead.Seal(append(dst, nonce...), nonce, src, nil), nil
+		},
+		Reverse: func(dst, src []byte) ([]byte, error) {
+			if len(src) < ns+aead.Overhead() {
+				return dst, fmt.Errorf("%w: sealed data too short", ErrCorruptedData)
+			}
+			out, err := aead.Open(dst, src[:ns], src[ns:], nil)
+			if err != nil {
+				return dst, fmt.Errorf("%w: %v", ErrCorruptedData, err)
+			}
+			return out, nil
+		},
+	}
+}
+
+// Pipeline runs data through a sequence of Stages, giving wrappers such as
+// preprocessing, compression, and encryption a single integration point
+// instead of each application nesting them by hand.
+//
+// Compress runs the Forward function of each stage in order, and Decompress
+// the Reverse functions in the opposite order. Intermediate results live in
+// two buffers recycled across calls, so a multi-stage pipeline allocates
+// little more than its result; CompressTo writes the result to a sink
+// without even that copy.
+//
+// A Pipeline holds no native resources and is safe for concurrent use. It
+// satisfies zlapi.Codec.
+type Pipeline struct {
+	stages []Stage
+	bufs   sync.Pool // *[2][]byte
+}
+
+// NewPipeline creates a Pipeline running stages in order.
+//
+// Example:
+//
+//	block, _ := aes.NewCipher(key)
+//	gcm, _ := cipher.NewGCM(block)
+//	pipeline, err := openzl.NewPipeline(
+//		normalizeStage,
+//		openzl.CompressStage(),
+//		openzl.AEADStage(gcm),
+//	)
+//	...
+//	sealed, err := pipeline.Compress(record)
+//	...
+//	record, err = pipeline.Decompress(sealed)
+//
+// Returns ErrInvalidParameter if a stage lacks Forward or Reverse.
+func NewPipeline(stages ...Stage) (*Pipeline, error) {
+	for i, s := range stages {
+		if s.Forward == nil || s.Reverse == nil {
+			return nil, fmt.Errorf("%w: stage %d (%q) lacks Forward or Reverse", ErrInvalidParameter, i, s.Name)
+		}
+	}
+	p := &Pipeline{stages: slices.Clone(stages)}
+	p.bufs.New = func() any { return new([2][]byte) }
+	return p, nil
+}
+
+// Compress runs src through the stages and returns the result.
+//
+// Returns ErrEmptyInput if src is empty, or the first stage error, prefixed
+// with the stage name.
+func (p *Pipeline) Compress(src []byte) ([]byte, error) {
+	var out []byte
+	err := p.run(src, true, func(b []byte) error {
+		out = slices.Clone(b)
+		return nil
+	})
+	return out, err
+}
+
+// CompressTo runs src through the stages and writes the result to w,
+// returning the number of bytes written.
+func (p *Pipeline) CompressTo(w io.Writer, src []byte) (int, error) {
+	var n int
+	err := p.run(src, true, func(b []byte) error {
+		var err error
+		n, err = w.Write(b)
+		return err
+	})
+	return n, err
+}
+
+// Decompress reverses Compress.
+func (p *Pipeline) Decompress(src []byte) ([]byte, error) {
+	var out []byte
+	err := p.run(src, false, func(b []byte) error {
+		out = slices.Clone(b)
+		return nil
+	})
+	return out, err
+}
+
+// run applies the stages to src, forward or in reverse, and calls sink with
+// the result while it is still in the recycled buffers.
+func (p *Pipeline) run(src []byte, forward bool, sink func([]byte) error) error {
+	if len(src) == 0 {
+		return ErrEmptyInput
+	}
+	bufs := p.bufs.Get().(*[2][]byte)
+	defer p.bufs.Put(bufs)
+
+	cur := src
+	for i := range p.stages {
+		s, fn := p.stages[i], p.stages[i].Forward
+		if !forward {
+			s = p.stages[len(p.stages)-1-i]
+			fn = s.Reverse
+		}
+		out, err := fn(bufs[i%2][:0], cur)
+		if err != nil {
+			return fmt.Errorf("%s: %w", s.Name, err)
+		}
+		// Only memory from the pipeline's own buffers is recycled; anything
+		// else, including a buffer the stage grew, is copied into them
+		if !sameArray(out, bufs[i%2]) {
+			out = append(bufs[i%2][:0], out...)
+		}
+		bufs[i%2] = out[:0]
+		cur = out
+	}
+	return sink(cur)
+}
+
+// sameArray reports whether b starts at the start of the backing array of
+// buf.
+func sameArray(b, buf []byte) bool {
+	return cap(b) > 0 && cap(buf) > 0 && &b[:1][0] == &buf[:1][0]
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"testing"
+)
+
+func TestPipeline(t *testing.T) {
+	block, err := aes.NewCipher(make([]byte, 16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	invert := Stage{
+		Name: "invert",
+		Forward: func(dst, src []byte) ([]byte, error) {
+			for _, b := range src {
+				dst = append(dst, ^b)
+			}
+			return dst, nil
+		},
+	}
+	invert.Reverse = invert.Forward
+
+	p, err := NewPipeline(invert, CompressStage(), AEADStage(gcm))
+	if err != nil {
+		t.Fatalf("NewPipeline() failed: %v", err)
+	}
+
+	data := bytes.Repeat([]byte("pipeline stage "), 500)
+	for range 3 {
+		sealed, err := p.Compress(data)
+		if err != nil {
+			t.Fatalf("Compress() failed: %v", err)
+		}
+		got, err := p.Decompress(sealed)
+		if err != nil || !bytes.Equal(got, data) {
+			t.Fatalf("Decompress() = %d bytes, %v, want %d bytes", len(got), err, len(data))
+		}
+	}
+
+	var sink bytes.Buffer
+	n, err := p.CompressTo(&sink, data)
+	if err != nil || n != sink.Len() {
+		t.Fatalf("CompressTo() = %d, %v, wrote %d bytes", n, err, sink.Len())
+	}
+	sealed := sink.Bytes()
+	sealed[len(sealed)-1] ^= 1
+	if _, err := p.Decompress(sealed); !errors.Is(err, ErrCorruptedData) {
+		t.Errorf("Decompress(tampered) error = %v, want ErrCorruptedData", err)
+	}
+
+	if _, err := p.Compress(nil); !errors.Is(err, ErrEmptyInput) {
+		t.Errorf("Compress(nil) error = %v, want ErrEmptyInput", err)
+	}
+	if _, err := NewPipeline(Stage{Name: "half", Forward: invert.Forward}); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("NewPipeline(no Reverse) error = %v, want ErrInvalidParameter", err)
+	}
+}
+
+func TestPipeline_ForeignOutput(t *testing.T) {
+	// A stage returning src, or a slice it keeps, must not have that
+	// memory overwritten by the stages after it
+	identity := Stage{
+		Name:    "identity",
+		Forward: func(dst, src []byte) ([]byte, error) { return src, nil },
+		Reverse: func(dst, src []byte) ([]byte, error) { return src, nil },
+	}
+	owned := bytes.Repeat([]byte("owned by the stage "), 100)
+	keep := bytes.Clone(owned)
+	constant := Stage{
+		Name:    "constant",
+		Forward: func(dst, src []byte) ([]byte, error) { return owned, nil },
+		Reverse: func(dst, src []byte) ([]byte, error) { return owned, nil },
+	}
+	shout := Stage{
+		Name:    "shout",
+		Forward: func(dst, src []byte) ([]byte, error) { return append(dst, bytes.ToUpper(src)...), nil },
+		Reverse: func(dst, src []byte) ([]byte, error) { return append(dst, bytes.ToLower(src)...), nil },
+	}
+
+	p, err := NewPipeline(identity, shout, identity, constant, shout)
+	if err != nil {
+		t.Fatalf("NewPipeline() failed: %v", err)
+	}
+	data := []byte("caller data")
+	for range 3 {
+		out, err := p.Compress(data)
+		if err != nil || !bytes.Equal(out, bytes.ToUpper(keep)) {
+			t.Fatalf("Compress() = %q, %v", out, err)
+		}
+	}
+	if !bytes.Equal(data, []byte("caller data")) {
+		t.Errorf("Compress() modified its input: %q", data)
+	}
+	if !bytes.Equal(owned, keep) {
+		t.Error("Compress() overwrote memory returned by a stage")
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// PolicyRule selects Writer options for files matching Pattern.
+//
+// A Pattern containing a slash is matched against the file's MIME type, as
+// in "image/*" or "application/json"; any other Pattern is a glob matched
+// against the lowercased base name, as in "*.json". Patterns use the syntax
+// of path.Match.
+type PolicyRule struct {
+	Pattern string
+	Options []WriterOption
+}
+
+// Policy chooses how to compress a file from its name and content type.
+//
+// Rules are tried in order and the first match wins; files that match no
+// rule use Default. A Policy is read-only once in use and may be shared.
+//
+// Example:
+//
+//	policy := &openzl.Policy{
+//		Rules: append([]openzl.PolicyRule{
+//			{Pattern: "*.parquet", Options: []openzl.WriterOption{openzl.WithStoredFallback(true)}},
+//		}, openzl.DefaultPolicy().Rules...),
+//	}
+//	_, err := openzl.CompressFile("events.json.zl", "events.json", policy)
+type Policy struct {
+	Rules   []PolicyRule
+	Default []WriterOption
+}
+
+// DefaultPolicy returns a Policy suited to mixed directories of files.
+//
+// Formats that are already compressed (images, audio, video, and archives)
+// use WithStoredFallback, so they pass through at almost no cost instead of
+// growing. Text and structured formats such as JSON, CSV, and logs use
+// MaxFrameSize frames, which give OpenZL more context per frame. Everything
+// else uses the Writer defaults. Each call returns a new Policy that the
+// caller may modify.
+func DefaultPolicy() *Policy {
+	stored := []WriterOption{WithStoredFallback(true)}
+	text := []WriterOption{WithFrameSize(MaxFrameSize)}
+	p := &Policy{}
+	for _, pattern := range []string{
+		"image/*", "audio/*", "video/*",
+		"*.jpg", "*.jpeg", "*.png", "*.gif", "*.webp", "*.avif", "*.heic",
+		"*.mp3", "*.mp4", "*.mkv", "*.mov", "*.webm", "*.ogg", "*.flac",
+		"*.zip", "*.gz", "*.tgz", "*.bz2", "*.xz", "*.zst", "*.7z", "*.rar", "*.zl",
+		"application/zip", "application/gzip", "application/x-gzip",
+	} {
+		p.Rules = append(p.Rules, PolicyRule{Pattern: pattern, Options: stored})
+	}
+	for _, pattern := range []string{
+		"*.json", "*.jsonl", "*.ndjson", "*.csv", "*.tsv", "*.log", "*.txt", "*.xml",
+		"text/*", "application/json", "application/xml",
+	} {
+		p.Rules = append(p.Rules, PolicyRule{Pattern: pattern, Options: text})
+	}
+	return p
+}
+
+// Options returns the Writer options for a file with the given name and MIME
+// type. Either may be empty; MIME parameters such as charset are ignored.
+func (p *Policy) Options(name, mimeType string) []WriterOption {
+	if i := p.Match(name, mimeType); i >= 0 {
+		return p.Rules[i].Options
+	}
+	return p.Default
+}
+
+// Match returns the index in Rules of the rule that applies to a file with
+// the given name and MIME type, or -1 if Default applies. Archivers use it
+// to group files that share options.
+func (p *Policy) Match(name, mimeType string) int {
+	base := strings.ToLower(filepath.Base(name))
+	if mt, _, err := mime.ParseMediaType(mimeType); err == nil {
+		mimeType = mt
+	}
+	for i, rule := range p.Rules {
+		subject := base
+		if strings.Contains(rule.Pattern, "/") {
+			subject = mimeType
+		}
+		if subject == "" {
+			continue
+		}
+		if ok, _ := path.Match(rule.Pattern, subject); ok {
+			return i
+		}
+	}
+	return -1
+}
+
+// CompressFile compresses the file src into a Writer stream written to the
+// file dst, with the options policy selects for src, and returns the number
+// of compressed bytes written. A nil policy means DefaultPolicy.
+//
+// The MIME type is taken from the file extension, or sniffed from the first
+// 512 bytes of content if the extension is unknown. Sniffing recognises the
+// compressed image, audio, video and archive formats of DefaultPolicy, and
+// plain text.
+//
+// Returns an error if a pattern of policy is malformed, or if reading src,
+// compressing, or writing dst fails. On error, the file dst is removed.
+func CompressFile(dst, src string, policy *Policy) (written int64, err error) {
+	if policy == nil {
+		policy = DefaultPolicy()
+	}
+	for _, rule := range policy.Rules {
+		if _, err := path.Match(rule.Pattern, ""); err != nil {
+			return 0, fmt.Errorf("%w: policy pattern %q: %v", ErrInvalidParameter, rule.Pattern, err)
+		}
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+	r := bufio.NewReader(in)
+
+	mimeType := mime.TypeByExtension(filepath.Ext(src))
+	if mimeType == "" {
+		head, err := r.Peek(512)
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+		mimeType = sniffContentType(head)
+	}
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(dst)
+		}
+	}()
+	return CompressStream(out, r, policy.Options(src, mimeType)...)
+}
+
+// contentSignatures are the magic numbers sniffContentType recognises, at
+// their offset in the content.
+var contentSignatures = []struct {
+	offset int
+	magic  string
+	mime   string
+}{
+	{0, "\xff\xd8\xff", "image/jpeg"},
+	{0, "\x89PNG\r\n\x1a\n", "image/png"},
+	{0, "GIF87a", "image/gif"},
+	{0, "GIF89a", "image/gif"},
+	{8, "WEBP", "image/webp"},
+	{8, "WAVE", "audio/wav"},
+	{8, "AVI ", "video/avi"},
+	{8, "avif", "image/avif"},
+	{8, "heic", "image/heic"},
+	{8, "heix", "image/heic"},
+	{8, "mif1", "image/heic"},
+	{8, "M4A ", "audio/mp4"},
+	{4, "ftyp", "video/mp4"},
+	{0, "\x1a\x45\xdf\xa3", "video/webm"},
+	{0, "OggS", "audio/ogg"},
+	{0, "fLaC", "audio/flac"},
+	{0, "ID3", "audio/mpeg"},
+	{0, "PK\x03\x04", "application/zip"},
+	{0, "\x1f\x8b", "application/gzip"},
+	{0, "BZh", "application/x-bzip2"},
+	{0, "\xfd7zXZ\x00", "application/x-xz"},
+	{0, "\x28\xb5\x2f\xfd", "application/zstd"},
+	{0, "7z\xbc\xaf\x27\x1c", "application/x-7z-compressed"},
+	{0, "Rar!\x1a\x07", "application/vnd.rar"},
+	{0, "%PDF-", "application/pdf"},
+}
+
+// sniffContentType returns the MIME type of content starting with head, or
+// "" if it is not recognised. It covers the formats DefaultPolicy cares
+// about, without the weight of net/http's full sniffer.
+func sniffContentType(head []byte) string {
+	for _, sig := range contentSignatures {
+		if len(head) >= sig.offset+len(sig.magic) && string(head[sig.offset:sig.offset+len(sig.magic)]) == sig.magic {
+			// RIFF and ISO media containers are told apart by their brand
+			if sig.offset == 8 && string(head[4:8]) != "ftyp" && string(head[:4]) != "RIFF" {
+				continue
+			}
+			return sig.mime
+		}
+	}
+	if len(head) > 0 && isText(head) {
+		return "text/plain; charset=utf-8"
+	}
+	return ""
+}
+
+// isText reports whether head is UTF-8 text without control characters
+// other than whitespace. A rune cut off at the end of head is allowed.
+func isText(head []byte) bool {
+	for len(head) > 0 {
+		r, size := utf8.DecodeRune(head)
+		if r == utf8.RuneError && size == 1 {
+			return !utf8.FullRune(head)
+		}
+		if r < 0x20 && r != '\t' && r != '\n' && r != '\r' && r != '\f' || r == 0x7f {
+			return false
+		}
+		head = head[size:]
+	}
+	return true
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// policyWriter returns the Writer configuration opts produce.
+func policyWriter(t *testing.T, opts []WriterOption) *Writer {
+	t.Helper()
+	w, err := newWriterConfig(opts)
+	if err != nil {
+		t.Fatalf("newWriterConfig() failed: %v", err)
+	}
+	return w
+}
+
+func TestDefaultPolicy(t *testing.T) {
+	p := DefaultPolicy()
+	tests := []struct {
+		name, mimeType string
+		stored         bool
+		frameSize      int
+	}{
+		{"photos/IMG_0001.JPG", "", true, DefaultFrameSize},
+		{"backup.tar.gz", "", true, DefaultFrameSize},
+		{"upload", "video/mp4", true, DefaultFrameSize},
+		{"events.json", "", false, MaxFrameSize},
+		{"README", "text/plain; charset=utf-8", false, MaxFrameSize},
+		{"data.bin", "application/octet-stream", false, DefaultFrameSize},
+	}
+	for _, tt := range tests {
+		w := policyWriter(t, p.Options(tt.name, tt.mimeType))
+		if w.stored != tt.stored || w.frameSize != tt.frameSize {
+			t.Errorf("Options(%q, %q): stored=%v frameSize=%d, want stored=%v frameSize=%d",
+				tt.name, tt.mimeType, w.stored, w.frameSize, tt.stored, tt.frameSize)
+		}
+	}
+}
+
+func TestCompressFile(t *testing.T) {
+	dir := t.TempDir()
+	data := bytes.Repeat([]byte("plain text without an extension\n"), 1000)
+	src := filepath.Join(dir, "notes")
+	if err := os.WriteFile(src, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A custom rule takes precedence, and content is sniffed without an extension
+	policy := &Policy{Rules: []PolicyRule{
+		{Pattern: "text/*", Options: []WriterOption{WithFrameSize(MinFrameSize), WithFrameChecksum(true)}},
+	}}
+	dst := filepath.Join(dir, "notes.zl")
+	written, err := CompressFile(dst, src, policy)
+	if err != nil {
+		t.Fatalf("CompressFile() failed: %v", err)
+	}
+	compressed, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int64(len(compressed)) != written {
+		t.Errorf("CompressFile() = %d, file has %d bytes", written, len(compressed))
+	}
+	if h, err := ParseFrameHeader(compressed); err != nil || !h.Checksum {
+		t.Errorf("first frame header = %+v, %v, want the policy's checksum", h, err)
+	}
+	var out bytes.Buffer
+	if _, err := DecompressTo(&out, compressed); err != nil || !bytes.Equal(out.Bytes(), data) {
+		t.Errorf("compressed file does not decode to the input: %v", err)
+	}
+
+	// The default policy is used without one
+	if _, err := CompressFile(dst, src, nil); err != nil {
+		t.Errorf("CompressFile() with default policy failed: %v", err)
+	}
+
+	// A failed compression leaves no partial output behind
+	unreadable := filepath.Join(dir, "dir.json")
+	if err := os.Mkdir(unreadable, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	failed := filepath.Join(dir, "failed.zl")
+	if _, err := CompressFile(failed, unreadable, nil); err == nil {
+		t.Error("CompressFile() of a directory succeeded")
+	}
+	if _, err := os.Stat(failed); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("CompressFile() left %s behind: %v", failed, err)
+	}
+
+	bad := &Policy{Rules: []PolicyRule{{Pattern: "[", Options: nil}}}
+	if _, err := CompressFile(dst, src, bad); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("CompressFile() with malformed pattern = %v, want ErrInvalidParameter", err)
+	}
+}
+
+func TestSniffContentType(t *testing.T) {
+	gz, err := CompressFrom(bytes.NewReader([]byte("x")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		head string
+		want string
+	}{
+		{"\xff\xd8\xff\xe0\x00\x10JFIF", "image/jpeg"},
+		{"\x89PNG\r\n\x1a\n\x00\x00", "image/png"},
+		{"RIFF\x00\x00\x00\x00WEBPVP8 ", "image/webp"},
+		{"\x00\x00\x00\x20ftypisom\x00\x00", "video/mp4"},
+		{"\x00\x00\x00\x1cftypheic\x00\x00", "image/heic"},
+		{"\x1f\x8b\x08\x00", "application/gzip"},
+		{"PK\x03\x04\x14\x00", "application/zip"},
+		{"{\"level\":\"info\"}\n", "text/plain; charset=utf-8"},
+		{"caf\xc3", "text/plain; charset=utf-8"}, // rune cut off by the sniff window
+		{"\x00\x01\x02\x03", ""},
+		{string(gz), ""},
+	}
+	for _, tt := range tests {
+		if got := sniffContentType([]byte(tt.head)); got != tt.want {
+			t.Errorf("sniffContentType(%q) = %q, want %q", tt.head, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,449 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"sync"
+)
+
+// Result is the outcome of an asynchronous Pool operation.
+type Result struct {
+	// Data is the compressed or decompressed output. It is nil if Err is set.
+	Data []byte
+
+	// Err is the error returned by the operation, if any.
+	Err error
+}
+
+// Pool is a fixed set of workers, each owning its own compression and
+// decompression context, that process independent chunks concurrently.
+//
+// A single Compressor serializes all callers behind one mutex. Pool removes
+// that bottleneck without requiring applications to write their own
+// goroutine orchestration: submit chunks with CompressAsync or
+// DecompressAsync and receive each result on its own channel.
+//
+// Pool is safe for concurrent use by multiple goroutines.
+//
+// Example:
+//
+//	pool, err := openzl.NewPool(openzl.WithWorkers(8))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer pool.Close()
+//
+//	results := make([]<-chan openzl.Result, len(chunks))
+//	for i, chunk := range chunks {
+//		results[i] = pool.CompressAsync(chunk)
+//	}
+//	for _, ch := range results {
+//		r := <-ch
+//		if r.Err != nil {
+//			log.Fatal(r.Err)
+//		}
+//		// Use r.Data...
+//	}
+type Pool struct {
+	mu       sync.RWMutex                // Protects closed and sends on jobs
+	jobs     [numPriorities]chan poolJob // Pending work per priority, consumed by workers
+	workers  int                         // Number of worker goroutines
+	reserved int                         // Workers that only serve PriorityHigh
+	quota    *ContextQuota               // Shared context cap, nil for eager contexts
+	mode     QuotaMode                   // Behavior when quota is exhausted
+	wg       sync.WaitGroup              // Tracks running workers
+	closed   bool                        // Whether Close() has been called
+}
+
+// Priority is the quality-of-service class of a job submitted to a Pool.
+//
+// Workers always pick the highest-priority job that is waiting, so
+// latency-sensitive small messages are not stuck behind queued batch work.
+// Priorities are strict: a steady stream of high-priority jobs can delay
+// lower-priority jobs indefinitely.
+type Priority int
+
+const (
+	// PriorityLow is for background batch work that can tolerate delay.
+	PriorityLow Priority = iota
+
+	// PriorityNormal is the default priority used by CompressAsync and
+	// DecompressAsync.
+	PriorityNormal
+
+	// PriorityHigh is for latency-sensitive work. It is also the only
+	// priority served by reserved workers (see WithReservedWorkers).
+	PriorityHigh
+
+	numPriorities = 3
+)
+
+// poolJob is a single unit of work submitted to a Pool.
+type poolJob struct {
+	src        []byte
+	decompress bool
+	result     chan Result
+}
+
+// poolConfig holds the configuration options for Pool.
+type poolConfig struct {
+	workers   int
+	reserved  int
+	queueSize int
+	quota     *ContextQuota
+	quotaMode QuotaMode
+}
+
+// PoolOption configures a Pool during creation.
+type PoolOption func(*poolConfig) error
+
+// WithWorkers sets the number of worker goroutines, and therefore the number
+// of native contexts, owned by the Pool.
+//
+// If not specified, runtime.GOMAXPROCS(0) workers are started, unless a
+// different default was set with WithDefaultPoolWorkers.
+func WithWorkers(n int) PoolOption {
+	return func(cfg *poolConfig) error {
+		if n < 1 {
+			return rangeError("WithWorkers", n, 1, math.MaxInt64)
+		}
+		cfg.workers = n
+		return nil
+	}
+}
+
+// WithQueueSize sets how many submitted jobs of each priority may wait for a
+// free worker before CompressAsync and DecompressAsync block.
+//
+// If not specified, each priority queue holds four jobs per worker, unless a
+// different default was set with WithDefaultPoolQueueSize.
+func WithQueueSize(n int) PoolOption {
+	return func(cfg *poolConfig) error {
+		if n < 0 {
+			return rangeError("WithQueueSize", n, 0, math.MaxInt64)
+		}
+		cfg.queueSize = n
+		return nil
+	}
+}
+
+// WithReservedWorkers dedicates n of the Pool's workers to PriorityHigh jobs.
+//
+// Priority ordering alone cannot help a small message if every worker is
+// already busy with a large batch job. Reserved workers never pick up normal
+// or low priority jobs, so they are free whenever high-priority work arrives.
+// The number of reserved workers must be less than the total worker count.
+func WithReservedWorkers(n int) PoolOption {
+	return func(cfg *poolConfig) error {
+		if n < 0 {
+			return rangeError("WithReservedWorkers", n, 0, math.MaxInt64)
+		}
+		cfg.reserved = n
+		return nil
+	}
+}
+
+// NewPool creates a Pool and starts its workers.
+//
+// Each worker allocates one Compressor and one Decompressor, unless the
+// contexts are governed by WithContextQuota. When finished, call Close() to
+// stop the workers and release their contexts.
+//
+// Returns ErrShutdown after Shutdown, or an error if any option is invalid
+// or a context cannot be created.
+func NewPool(opts ...PoolOption) (*Pool, error) {
+	if shutDown.Load() {
+		return nil, ErrShutdown
+	}
+	cfg := &poolConfig{
+		workers:   defaults().poolWorkers,
+		queueSize: defaults().poolQueue,
+	}
+	if cfg.workers == 0 {
+		cfg.workers = runtime.GOMAXPROCS(0)
+	}
+	for _, opt := range opts {
+		if err := opt(cfg); err != nil {
+			return nil, fmt.Errorf("apply option: %w", err)
+		}
+	}
+	if cfg.queueSize < 0 {
+		cfg.queueSize = 4 * cfg.workers
+	}
+	if cfg.reserved >= cfg.workers {
+		return nil, fmt.Errorf("reserved workers (%d) must be fewer than workers (%d)", cfg.reserved, cfg.workers)
+	}
+
+	p := &Pool{
+		workers:  cfg.workers,
+		reserved: cfg.reserved,
+		quota:    cfg.quota,
+		mode:     cfg.quotaMode,
+	}
+	for i := range p.jobs {
+		p.jobs[i] = make(chan poolJob, cfg.queueSize)
+	}
+
+	// With a quota, workers create contexts on demand
+	if p.quota != nil {
+		p.wg.Add(cfg.workers)
+		for i := 0; i < cfg.workers; i++ {
+			go p.workWithQuota(i < cfg.reserved)
+		}
+		if err := p.register(); err != nil {
+			return nil, err
+		}
+		return p, nil
+	}
+
+	// Create all contexts up front so a failure is reported here rather
+	// than from an arbitrary later job.
+	compressors := make([]*Compressor, cfg.workers)
+	decompressors := make([]*Decompressor, cfg.workers)
+	for i := 0; i < cfg.workers; i++ {
+		c, err := NewCompressor()
+		if err != nil {
+			closeWorkerContexts(compressors, decompressors)
+			return nil, fmt.Errorf("create compressor: %w", err)
+		}
+		compressors[i] = c
+
+		d, err := NewDecompressor()
+		if err != nil {
+			closeWorkerContexts(compressors, decompressors)
+			return nil, fmt.Errorf("create decompressor: %w", err)
+		}
+		decompressors[i] = d
+	}
+
+	p.wg.Add(cfg.workers)
+	for i := 0; i < cfg.workers; i++ {
+		go p.work(compressors[i], decompressors[i], i < cfg.reserved)
+	}
+
+	if err := p.register(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// register makes p known to Shutdown. If Shutdown has already started, the
+// pool is closed instead and ErrShutdown is returned.
+func (p *Pool) register() error {
+	if err := registerPool(p); err != nil {
+		p.Close()
+		return err
+	}
+	return nil
+}
+
+// closeWorkerContexts releases the contexts created so far by NewPool.
+func closeWorkerContexts(compressors []*Compressor, decompressors []*Decompressor) {
+	for _, c := range compressors {
+		if c != nil {
+			c.Close()
+		}
+	}
+	for _, d := range decompressors {
+		if d != nil {
+			d.Close()
+		}
+	}
+}
+
+// work processes jobs until the queues are closed, then frees its contexts.
+func (p *Pool) work(c *Compressor, d *Decompressor, reserved bool) {
+	defer p.wg.Done()
+	defer c.Close()
+	defer d.Close()
+
+	for {
+		job, ok := p.next(reserved)
+		if !ok {
+			return
+		}
+
+		var r Result
+		if job.decompress {
+			r.Data, r.Err = d.Decompress(job.src)
+		} else {
+			r.Data, r.Err = c.Compress(job.src)
+		}
+		job.result <- r
+	}
+}
+
+// workWithQuota processes jobs like work, but creates contexts on demand
+// under the Pool's ContextQuota and gives them back whenever it is idle.
+func (p *Pool) workWithQuota(reserved bool) {
+	defer p.wg.Done()
+
+	contexts := &quotaContexts{quota: p.quota, mode: p.mode}
+	defer contexts.release()
+
+	for {
+		job, ok := p.tryNext(reserved)
+		if !ok {
+			// Nothing waiting: free the context for other workers first
+			contexts.release()
+			if job, ok = p.next(reserved); !ok {
+				return
+			}
+		}
+
+		var r Result
+		if job.decompress {
+			var d *Decompressor
+			if d, r.Err = contexts.decompressor(); r.Err == nil {
+				r.Data, r.Err = d.Decompress(job.src)
+			}
+		} else {
+			var c *Compressor
+			if c, r.Err = contexts.compressor(); r.Err == nil {
+				r.Data, r.Err = c.Compress(job.src)
+			}
+		}
+		job.result <- r
+	}
+}
+
+// tryNext returns the highest-priority job that is already waiting, or false
+// if there is none. Closed queues are treated as empty.
+func (p *Pool) tryNext(reserved bool) (poolJob, bool) {
+	prios := [...]Priority{PriorityHigh, PriorityNormal, PriorityLow}
+	n := len(prios)
+	if reserved {
+		n = 1
+	}
+	for _, prio := range prios[:n] {
+		select {
+		case job, ok := <-p.jobs[prio]:
+			if ok {
+				return job, true
+			}
+		default:
+		}
+	}
+	return poolJob{}, false
+}
+
+// next returns the highest-priority waiting job, blocking until one is
+// available. It returns false once the Pool is closed and drained.
+func (p *Pool) next(reserved bool) (poolJob, bool) {
+	if reserved {
+		job, ok := <-p.jobs[PriorityHigh]
+		return job, ok
+	}
+
+	// Fast path: take the highest-priority job that is already waiting
+	if job, ok := p.tryNext(false); ok {
+		return job, true
+	}
+
+	// Nothing waiting: block on all queues, dropping each once it is closed.
+	high, normal, low := p.jobs[PriorityHigh], p.jobs[PriorityNormal], p.jobs[PriorityLow]
+	for high != nil || normal != nil || low != nil {
+		select {
+		case job, ok := <-high:
+			if ok {
+				return job, true
+			}
+			high = nil
+		case job, ok := <-normal:
+			if ok {
+				return job, true
+			}
+			normal = nil
+		case job, ok := <-low:
+			if ok {
+				return job, true
+			}
+			low = nil
+		}
+	}
+	return poolJob{}, false
+}
+
+// CompressAsync submits src for compression and returns a channel that
+// receives exactly one Result.
+//
+// The src slice must not be modified until the result has been received.
+// If all workers are busy and the queue is full, CompressAsync blocks until
+// a slot frees up. If the Pool is closed, the returned channel immediately
+// yields ErrPoolClosed.
+func (p *Pool) CompressAsync(src []byte) <-chan Result {
+	return p.submit(src, false, PriorityNormal)
+}
+
+// CompressAsyncPriority is like CompressAsync but submits the job with the
+// given priority.
+func (p *Pool) CompressAsyncPriority(src []byte, prio Priority) <-chan Result {
+	return p.submit(src, false, prio)
+}
+
+// DecompressAsync submits src for decompression and returns a channel that
+// receives exactly one Result.
+//
+// It follows the same blocking and ownership rules as CompressAsync.
+func (p *Pool) DecompressAsync(src []byte) <-chan Result {
+	return p.submit(src, true, PriorityNormal)
+}
+
+// DecompressAsyncPriority is like DecompressAsync but submits the job with
+// the given priority.
+func (p *Pool) DecompressAsyncPriority(src []byte, prio Priority) <-chan Result {
+	return p.submit(src, true, prio)
+}
+
+// submit enqueues a job, or fails it immediately if the Pool is closed.
+func (p *Pool) submit(src []byte, decompress bool, prio Priority) <-chan Result {
+	// Buffered so workers never block on callers that stop listening
+	result := make(chan Result, 1)
+
+	if prio < PriorityLow || prio > PriorityHigh {
+		result <- Result{Err: fmt.Errorf("%w: priority %d", ErrInvalidParameter, prio)}
+		return result
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		result <- Result{Err: ErrPoolClosed}
+		return result
+	}
+
+	p.jobs[prio] <- poolJob{src: src, decompress: decompress, result: result}
+	return result
+}
+
+// Workers returns the number of worker goroutines in the Pool.
+func (p *Pool) Workers() int {
+	return p.workers
+}
+
+// Close stops accepting new work, waits for queued jobs to finish, and
+// releases all worker contexts.
+//
+// Every job submitted before Close still receives its result. Calling Close
+// multiple times is safe and has no effect after the first call.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	for _, jobs := range p.jobs {
+		close(jobs)
+	}
+	p.mu.Unlock()
+
+	p.wg.Wait()
+	unregisterPool(p)
+	return nil
+}
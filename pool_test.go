@@ -0,0 +1,187 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestPool_CompressAsync(t *testing.T) {
+	pool, err := NewPool(WithWorkers(4))
+	if err != nil {
+		t.Fatalf("NewPool() failed: %v", err)
+	}
+	defer pool.Close()
+
+	// Submit all chunks before collecting any results
+	chunks := make([][]byte, 64)
+	results := make([]<-chan Result, len(chunks))
+	for i := range chunks {
+		chunks[i] = bytes.Repeat([]byte(fmt.Sprintf("chunk %d ", i)), 100+i)
+		results[i] = pool.CompressAsync(chunks[i])
+	}
+
+	for i, ch := range results {
+		r := <-ch
+		if r.Err != nil {
+			t.Fatalf("chunk %d: compress failed: %v", i, r.Err)
+		}
+
+		d := <-pool.DecompressAsync(r.Data)
+		if d.Err != nil {
+			t.Fatalf("chunk %d: decompress failed: %v", i, d.Err)
+		}
+		if !bytes.Equal(d.Data, chunks[i]) {
+			t.Errorf("chunk %d: round-trip mismatch", i)
+		}
+	}
+}
+
+func TestPool_Errors(t *testing.T) {
+	pool, err := NewPool(WithWorkers(1))
+	if err != nil {
+		t.Fatalf("NewPool() failed: %v", err)
+	}
+
+	if r := <-pool.CompressAsync(nil); r.Err != ErrEmptyInput {
+		t.Errorf("expected ErrEmptyInput, got: %v", r.Err)
+	}
+
+	if err := pool.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if r := <-pool.CompressAsync([]byte("late")); r.Err != ErrPoolClosed {
+		t.Errorf("expected ErrPoolClosed, got: %v", r.Err)
+	}
+
+	// Closing again should be safe
+	if err := pool.Close(); err != nil {
+		t.Errorf("second Close() failed: %v", err)
+	}
+}
+
+func TestPool_InvalidOptions(t *testing.T) {
+	if _, err := NewPool(WithWorkers(0)); err == nil {
+		t.Error("NewPool(WithWorkers(0)) succeeded, want error")
+	}
+	if _, err := NewPool(WithQueueSize(-1)); err == nil {
+		t.Error("NewPool(WithQueueSize(-1)) succeeded, want error")
+	}
+}
+
+func TestPool_Priority(t *testing.T) {
+	// One shared worker plus one reserved for high-priority work
+	pool, err := NewPool(WithWorkers(2), WithReservedWorkers(1))
+	if err != nil {
+		t.Fatalf("NewPool() failed: %v", err)
+	}
+	defer pool.Close()
+
+	large := bytes.Repeat([]byte("batch payload "), 64*1024)
+	batch := make([]<-chan Result, 8)
+	for i := range batch {
+		batch[i] = pool.CompressAsyncPriority(large, PriorityLow)
+	}
+
+	// The high-priority message is served by the reserved worker even
+	// though the shared worker is busy with batch jobs.
+	small := []byte("latency sensitive")
+	r := <-pool.CompressAsyncPriority(small, PriorityHigh)
+	if r.Err != nil {
+		t.Fatalf("high priority compress failed: %v", r.Err)
+	}
+
+	for i, ch := range batch {
+		if r := <-ch; r.Err != nil {
+			t.Fatalf("batch job %d failed: %v", i, r.Err)
+		}
+	}
+
+	if r := <-pool.CompressAsyncPriority(small, Priority(42)); r.Err == nil {
+		t.Error("expected error for invalid priority")
+	}
+}
+
+func TestPool_ReservedWorkersInvalid(t *testing.T) {
+	if _, err := NewPool(WithWorkers(2), WithReservedWorkers(2)); err == nil {
+		t.Error("NewPool() with all workers reserved succeeded, want error")
+	}
+	if _, err := NewPool(WithReservedWorkers(-1)); err == nil {
+		t.Error("NewPool(WithReservedWorkers(-1)) succeeded, want error")
+	}
+}
+
+func TestPool_ContextQuota(t *testing.T) {
+	quota, err := NewContextQuota(2)
+	if err != nil {
+		t.Fatalf("NewContextQuota() failed: %v", err)
+	}
+
+	// Two pools with more workers than the quota allows contexts
+	pools := make([]*Pool, 2)
+	for i := range pools {
+		pools[i], err = NewPool(WithWorkers(4), WithContextQuota(quota, QuotaWait))
+		if err != nil {
+			t.Fatalf("NewPool() failed: %v", err)
+		}
+	}
+	if quota.InUse() != 0 {
+		t.Errorf("InUse() = %d before any work, want 0", quota.InUse())
+	}
+
+	var results []<-chan Result
+	var inputs [][]byte
+	for i := 0; i < 64; i++ {
+		src := []byte(fmt.Sprintf("quota chunk %d", i))
+		inputs = append(inputs, src)
+		results = append(results, pools[i%2].CompressAsync(src))
+	}
+	for i, ch := range results {
+		r := <-ch
+		if r.Err != nil {
+			t.Fatalf("chunk %d: %v", i, r.Err)
+		}
+		if in := quota.InUse(); in > quota.Limit() {
+			t.Fatalf("InUse() = %d exceeds limit %d", in, quota.Limit())
+		}
+		got, err := Decompress(r.Data)
+		if err != nil || !bytes.Equal(got, inputs[i]) {
+			t.Errorf("chunk %d: round trip failed: %v", i, err)
+		}
+	}
+
+	for _, p := range pools {
+		p.Close()
+	}
+	if quota.InUse() != 0 {
+		t.Errorf("InUse() = %d after Close(), want 0", quota.InUse())
+	}
+}
+
+func TestPool_ContextQuotaFailFast(t *testing.T) {
+	quota, _ := NewContextQuota(1)
+
+	// Hold the only slot so the pool cannot allocate a context
+	if err := quota.acquire(QuotaWait); err != nil {
+		t.Fatalf("acquire() failed: %v", err)
+	}
+
+	pool, err := NewPool(WithWorkers(1), WithContextQuota(quota, QuotaFailFast))
+	if err != nil {
+		t.Fatalf("NewPool() failed: %v", err)
+	}
+	defer pool.Close()
+
+	if r := <-pool.CompressAsync([]byte("data")); !errors.Is(r.Err, ErrQuotaExceeded) {
+		t.Errorf("CompressAsync() error = %v, want ErrQuotaExceeded", r.Err)
+	}
+
+	quota.release()
+	if r := <-pool.CompressAsync([]byte("data")); r.Err != nil {
+		t.Errorf("CompressAsync() after release failed: %v", r.Err)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+// profileGzip marks a profile that was gzip-compressed before CompressProfile.
+const profileGzip = 1 << 0
+
+// maxProfileSize bounds the size of a gzip-compressed profile once
+// decompressed, so that a gzip bomb cannot exhaust memory. Real profiles are
+// far smaller.
+var maxProfileSize int64 = 1 << 30
+
+// CompressProfile compresses a Go pprof profile or runtime execution trace.
+//
+// Profiles written by runtime/pprof are gzip-compressed protobufs. Gzip hides
+// the protobuf structure from OpenZL and leaves little redundancy to exploit,
+// so CompressProfile decompresses the gzip layer first and compresses the
+// raw protobuf instead, which is typically several times smaller than the
+// original file. Execution traces and uncompressed profiles are compressed
+// as-is.
+//
+// The result is a go-openzl container, not a bare OpenZL frame; read it back
+// with DecompressProfile.
+//
+// No tuned profile is shipped yet: the module contains no trained graph for
+// pprof or trace data, so the raw content is compressed with the default
+// graph, as Compress does. The gains come from removing the gzip layer.
+//
+// Example:
+//
+//	var buf bytes.Buffer
+//	pprof.Lookup("heap").WriteTo(&buf, 0)
+//	compressed, err := openzl.CompressProfile(buf.Bytes())
+//
+// Returns ErrEmptyInput if data is empty, ErrInputTooLarge if a gzip
+// profile decompresses to more than 1GB, or an error if the gzip data is
+// invalid or compression fails.
+func CompressProfile(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, ErrEmptyInput
+	}
+
+	var flags byte
+	raw := data
+	if len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("read gzip profile: %w", err)
+		}
+		raw, err = io.ReadAll(io.LimitReader(zr, maxProfileSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("read gzip profile: %w", err)
+		}
+		if int64(len(raw)) > maxProfileSize {
+			return nil, fmt.Errorf("%w: gzip profile expands past %d bytes", ErrInputTooLarge, maxProfileSize)
+		}
+		flags |= profileGzip
+	}
+
+	var compressed []byte
+	if len(raw) > 0 {
+		var err error
+		compressed, err = Compress(raw)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return encodeContainer(containerProfile, []byte{flags}, compressed), nil
+}
+
+// DecompressProfile restores a profile or trace compressed by CompressProfile.
+//
+// Profiles that were gzip-compressed are gzip-compressed again, so the result
+// can be passed straight to `go tool pprof`. The protobuf content is
+// identical to the original, but the gzip bytes may differ since gzip output
+// depends on the compressor settings. Traces are restored byte for byte.
+func DecompressProfile(compressed []byte) ([]byte, error) {
+	if len(compressed) == 0 {
+		return nil, ErrEmptyInput
+	}
+
+	sections, err := decodeContainer(compressed, containerProfile, 2)
+	if err != nil {
+		return nil, err
+	}
+	if len(sections[0]) != 1 {
+		return nil, fmt.Errorf("%w: invalid profile flags", ErrCorruptedData)
+	}
+	flags := sections[0][0]
+
+	var raw []byte
+	if len(sections[1]) > 0 {
+		raw, err = Decompress(sections[1])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if flags&profileGzip == 0 {
+		return raw, nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(raw); err != nil {
+		return nil, fmt.Errorf("write gzip profile: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("write gzip profile: %w", err)
+	}
+	return buf.Bytes(), nil
+}
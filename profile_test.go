@@ -0,0 +1,92 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"runtime/pprof"
+	"runtime/trace"
+	"testing"
+)
+
+func gunzip(t *testing.T, b []byte) []byte {
+	t.Helper()
+	zr, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("gzip.NewReader() failed: %v", err)
+	}
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("gunzip failed: %v", err)
+	}
+	return raw
+}
+
+func TestCompressProfile_Pprof(t *testing.T) {
+	var buf bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 0); err != nil {
+		t.Fatalf("WriteTo() failed: %v", err)
+	}
+	original := buf.Bytes()
+
+	compressed, err := CompressProfile(original)
+	if err != nil {
+		t.Fatalf("CompressProfile() failed: %v", err)
+	}
+	restored, err := DecompressProfile(compressed)
+	if err != nil {
+		t.Fatalf("DecompressProfile() failed: %v", err)
+	}
+
+	// Still a gzip file with the same protobuf inside
+	if !bytes.Equal(gunzip(t, restored), gunzip(t, original)) {
+		t.Error("restored profile content does not match")
+	}
+}
+
+func TestCompressProfile_Trace(t *testing.T) {
+	var buf bytes.Buffer
+	if err := trace.Start(&buf); err != nil {
+		t.Skipf("tracing unavailable: %v", err)
+	}
+	for i := 0; i < 100; i++ {
+		Compress([]byte("trace me"))
+	}
+	trace.Stop()
+
+	compressed, err := CompressProfile(buf.Bytes())
+	if err != nil {
+		t.Fatalf("CompressProfile() failed: %v", err)
+	}
+	restored, err := DecompressProfile(compressed)
+	if err != nil {
+		t.Fatalf("DecompressProfile() failed: %v", err)
+	}
+	if !bytes.Equal(restored, buf.Bytes()) {
+		t.Error("restored trace does not match")
+	}
+}
+
+func TestDecompressProfile_WrongInput(t *testing.T) {
+	frame, _ := Compress([]byte("not a profile container"))
+	if _, err := DecompressProfile(frame); !errors.Is(err, ErrCorruptedData) {
+		t.Errorf("DecompressProfile() error = %v, want ErrCorruptedData", err)
+	}
+}
+
+func TestCompressProfile_GzipBomb(t *testing.T) {
+	defer func(n int64) { maxProfileSize = n }(maxProfileSize)
+	maxProfileSize = 1 << 20
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(make([]byte, maxProfileSize+1))
+	zw.Close()
+	if _, err := CompressProfile(buf.Bytes()); !errors.Is(err, ErrInputTooLarge) {
+		t.Errorf("CompressProfile(gzip bomb) error = %v, want ErrInputTooLarge", err)
+	}
+}
@@ -0,0 +1,311 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultProfilePollInterval is how often a ProfileWatcher checks its
+// directory for changes unless set with WithProfilePollInterval.
+const DefaultProfilePollInterval = 5 * time.Second
+
+// profileExt is the extension of the profile files a ProfileWatcher loads.
+const profileExt = ".json"
+
+// ProfileWatcherOption configures a ProfileWatcher.
+type ProfileWatcherOption func(*ProfileWatcher) error
+
+// WithProfilePollInterval sets how often the directory is checked for
+// changed profile files.
+func WithProfilePollInterval(d time.Duration) ProfileWatcherOption {
+	return func(w *ProfileWatcher) error {
+		if d <= 0 {
+			return &OptionError{Option: "WithProfilePollInterval", Value: d, Allowed: "positive"}
+		}
+		w.interval = d
+		return nil
+	}
+}
+
+// WithProfileReloadHandler sets a function called after every attempt to
+// load a changed profile file, with a nil error if the new profile is now
+// active, for example to log or count rollouts. A profile that fails to
+// load is also reported to the handler set with WithWarnHandler.
+//
+// The handler is called from the watcher's goroutine, or from Reload.
+func WithProfileReloadHandler(fn func(name string, err error)) ProfileWatcherOption {
+	return func(w *ProfileWatcher) error {
+		w.onReload = fn
+		return nil
+	}
+}
+
+// fileStamp identifies a version of a profile file.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// ProfileWatcher keeps a set of named compression profiles in sync with the
+// files of a directory, so that configuration management can roll out new
+// settings without a redeploy.
+//
+// Each file name.json in the directory holds a CompressorConfig in JSON and
+// defines the profile name. The directory is polled for changes; a new or
+// changed file is loaded into a new Compressor, which replaces the previous
+// one of the profile atomically: every Compress call uses either the old or
+// the new profile, never a mix, and the old Compressor is closed once the
+// calls using it have returned. A file that fails to load, for example
+// because of a syntax error, leaves the previous profile active. Removing a
+// file removes its profile.
+//
+// Files should be replaced atomically, by writing a temporary file without
+// the .json extension and renaming it, since a file polled while half
+// written fails to load until its next change.
+//
+// A ProfileWatcher is safe for concurrent use.
+type ProfileWatcher struct {
+	dir      string
+	interval time.Duration
+	onReload func(name string, err error)
+
+	mu       sync.RWMutex
+	profiles map[string]*Compressor
+	configs  map[string]CompressorConfig
+	closed   bool
+
+	scanMu sync.Mutex           // Serializes scans
+	seen   map[string]fileStamp // Last version of each file loaded or tried
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewProfileWatcher loads the profiles of dir and starts watching it.
+//
+// Example:
+//
+//	// /etc/myapp/profiles/events.json: {"run_short_circuit": true}
+//	profiles, err := openzl.NewProfileWatcher("/etc/myapp/profiles")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer profiles.Close()
+//	...
+//	compressed, err := profiles.Compress("events", payload)
+//
+// Returns an error if dir cannot be read or one of its profiles is invalid,
+// so that a broken configuration is caught at startup.
+func NewProfileWatcher(dir string, opts ...ProfileWatcherOption) (*ProfileWatcher, error) {
+	w := &ProfileWatcher{
+		dir:      dir,
+		interval: DefaultProfilePollInterval,
+		profiles: make(map[string]*Compressor),
+		configs:  make(map[string]CompressorConfig),
+		seen:     make(map[string]fileStamp),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		if err := opt(w); err != nil {
+			return nil, fmt.Errorf("apply option: %w", err)
+		}
+	}
+	if err := w.Reload(); err != nil {
+		w.closeProfiles()
+		return nil, err
+	}
+	go w.run()
+	return w, nil
+}
+
+// run polls the directory until Close.
+func (w *ProfileWatcher) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.Reload()
+		}
+	}
+}
+
+// Reload checks the directory for changes now, without waiting for the next
+// poll, and returns the errors of the files that failed to load.
+func (w *ProfileWatcher) Reload() error {
+	w.scanMu.Lock()
+	defer w.scanMu.Unlock()
+
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return fmt.Errorf("read profile directory: %w", err)
+	}
+
+	var errs []error
+	loaded := make(map[string]*Compressor)
+	configs := make(map[string]CompressorConfig)
+	present := make(map[string]bool)
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), profileExt)
+		if !ok || name == "" || e.IsDir() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		present[name] = true
+		stamp := fileStamp{modTime: info.ModTime(), size: info.Size()}
+		if old, ok := w.seen[name]; ok && old == stamp {
+			continue
+		}
+		w.seen[name] = stamp
+
+		cfg, c, err := loadProfile(filepath.Join(w.dir, e.Name()))
+		if err != nil {
+			err = fmt.Errorf("profile %q: %w", name, err)
+			errs = append(errs, err)
+			warn(err)
+		} else {
+			loaded[name], configs[name] = c, cfg
+		}
+		if w.onReload != nil {
+			w.onReload(name, err)
+		}
+	}
+	for name := range w.seen {
+		if !present[name] {
+			delete(w.seen, name)
+		}
+	}
+
+	// Swap the profiles; waiting for the write lock waits for the Compress
+	// calls using the old ones
+	var old []*Compressor
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		for _, c := range loaded {
+			c.Close()
+		}
+		return ErrContextClosed
+	}
+	for name, c := range w.profiles {
+		if _, replaced := loaded[name]; replaced || !present[name] {
+			old = append(old, c)
+			delete(w.profiles, name)
+			delete(w.configs, name)
+		}
+	}
+	for name, c := range loaded {
+		w.profiles[name], w.configs[name] = c, configs[name]
+	}
+	w.mu.Unlock()
+
+	for _, c := range old {
+		c.Close()
+	}
+	return errors.Join(errs...)
+}
+
+// loadProfile reads a CompressorConfig from path and creates its Compressor.
+// Unknown fields are rejected, so that a misspelled setting is not silently
+// ignored.
+func loadProfile(path string) (CompressorConfig, *Compressor, error) {
+	var cfg CompressorConfig
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return cfg, nil, fmt.Errorf("%w: %v", ErrInvalidParameter, err)
+	}
+	c, err := NewCompressorFromConfig(cfg)
+	if err != nil {
+		return cfg, nil, err
+	}
+	return cfg, c, nil
+}
+
+// Compress compresses src with the current version of the profile name.
+//
+// Returns ErrInvalidParameter if there is no such profile, or
+// ErrContextClosed after Close.
+func (w *ProfileWatcher) Compress(name string, src []byte) ([]byte, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return nil, ErrContextClosed
+	}
+	c, ok := w.profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown profile %q", ErrInvalidParameter, name)
+	}
+	return c.Compress(src)
+}
+
+// Config returns the configuration of the current version of the profile
+// name, and whether the profile exists.
+func (w *ProfileWatcher) Config(name string) (CompressorConfig, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	cfg, ok := w.configs[name]
+	return cfg, ok
+}
+
+// Profiles returns the names of the loaded profiles in sorted order.
+func (w *ProfileWatcher) Profiles() []string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	names := make([]string, 0, len(w.profiles))
+	for name := range w.profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Close stops watching and closes the Compressors of all profiles. Calling
+// Close more than once has no effect.
+func (w *ProfileWatcher) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	close(w.stop)
+	<-w.done
+	w.closeProfiles()
+	return nil
+}
+
+// closeProfiles closes the Compressors of all profiles.
+func (w *ProfileWatcher) closeProfiles() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for name, c := range w.profiles {
+		c.Close()
+		delete(w.profiles, name)
+		delete(w.configs, name)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestProfileWatcher(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("events.json", `{}`)
+	write("notes.txt", `ignored`)
+
+	var reloads []string
+	w, err := NewProfileWatcher(dir, WithProfilePollInterval(time.Hour), WithProfileReloadHandler(func(name string, err error) {
+		if err == nil {
+			reloads = append(reloads, name)
+		}
+	}))
+	if err != nil {
+		t.Fatalf("NewProfileWatcher() failed: %v", err)
+	}
+	defer w.Close()
+
+	data := []byte("profile rollout payload")
+	if _, err := w.Compress("events", data); err != nil {
+		t.Fatalf("Compress(events) failed: %v", err)
+	}
+	if _, err := w.Compress("missing", data); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("Compress(missing) error = %v, want ErrInvalidParameter", err)
+	}
+
+	// A changed file swaps the profile, a broken one keeps the previous
+	write("events.json", `{"run_short_circuit": true}`)
+	write("metrics.json", `{"run_short_circuit": `)
+	if err := w.Reload(); err == nil {
+		t.Error("Reload() with a broken profile succeeded")
+	}
+	if cfg, ok := w.Config("events"); !ok || !cfg.RunShortCircuit {
+		t.Errorf("Config(events) = %+v, %v, want the new version", cfg, ok)
+	}
+	if got := w.Profiles(); !slices.Equal(got, []string{"events"}) {
+		t.Errorf("Profiles() = %v, want [events]", got)
+	}
+	write("metrics.json", `{"stage_report": true}`)
+	if err := w.Reload(); err != nil {
+		t.Errorf("Reload() failed: %v", err)
+	}
+	if got := w.Profiles(); !slices.Equal(got, []string{"events", "metrics"}) {
+		t.Errorf("Profiles() = %v, want [events metrics]", got)
+	}
+	if !slices.Equal(reloads, []string{"events", "events", "metrics"}) {
+		t.Errorf("reload handler saw %v", reloads)
+	}
+
+	os.Remove(filepath.Join(dir, "events.json"))
+	w.Reload()
+	if _, ok := w.Config("events"); ok {
+		t.Error("profile of a removed file is still loaded")
+	}
+
+	write("typo.json", `{"run_shortcircuit": true}`)
+	if err := w.Reload(); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("Reload() with an unknown field error = %v, want ErrInvalidParameter", err)
+	}
+
+	w.Close()
+	if _, err := w.Compress("metrics", data); !errors.Is(err, ErrContextClosed) {
+		t.Errorf("Compress() after Close error = %v, want ErrContextClosed", err)
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import "fmt"
+
+// ContextQuota caps the number of native compression and decompression
+// contexts that may exist at the same time across every Pool sharing it.
+//
+// Each context holds native memory that the Go runtime neither sees nor
+// accounts for in GOMEMLIMIT. In containers with tight memory limits, a
+// quota bounds that memory regardless of how many pools or workers exist.
+//
+// Example:
+//
+//	quota, _ := openzl.NewContextQuota(4)
+//	ingest, _ := openzl.NewPool(openzl.WithWorkers(8), openzl.WithContextQuota(quota, openzl.QuotaWait))
+//	export, _ := openzl.NewPool(openzl.WithWorkers(8), openzl.WithContextQuota(quota, openzl.QuotaFailFast))
+type ContextQuota struct {
+	tokens chan struct{} // One element per allocated context
+}
+
+// QuotaMode selects what a Pool does when its ContextQuota is exhausted.
+type QuotaMode int
+
+const (
+	// QuotaWait makes the job wait until another worker frees a context.
+	QuotaWait QuotaMode = iota
+
+	// QuotaFailFast fails the job immediately with ErrQuotaExceeded.
+	QuotaFailFast
+)
+
+// NewContextQuota returns a quota allowing at most n contexts at a time.
+func NewContextQuota(n int) (*ContextQuota, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("context quota must be at least 1, got %d", n)
+	}
+	return &ContextQuota{tokens: make(chan struct{}, n)}, nil
+}
+
+// Limit returns the maximum number of contexts allowed by the quota.
+func (q *ContextQuota) Limit() int {
+	return cap(q.tokens)
+}
+
+// InUse returns the number of contexts currently allocated under the quota.
+func (q *ContextQuota) InUse() int {
+	return len(q.tokens)
+}
+
+// acquire reserves one context, waiting for a free slot if mode is QuotaWait.
+func (q *ContextQuota) acquire(mode QuotaMode) error {
+	if mode == QuotaFailFast {
+		select {
+		case q.tokens <- struct{}{}:
+			return nil
+		default:
+			return ErrQuotaExceeded
+		}
+	}
+	q.tokens <- struct{}{}
+	return nil
+}
+
+// release returns a context slot reserved by acquire.
+func (q *ContextQuota) release() {
+	<-q.tokens
+}
+
+// WithContextQuota makes the Pool allocate its contexts under q instead of
+// creating two per worker up front.
+//
+// Each worker then creates a context only when a job needs one and holds at
+// most one at a time, releasing it whenever it runs out of work. Because a
+// worker never waits for a slot while holding one, workers sharing a quota
+// cannot deadlock. When the quota is exhausted, mode decides whether the job
+// waits for a free slot or fails with ErrQuotaExceeded.
+//
+// Under load, contexts are still reused across consecutive jobs of the same
+// kind; alternating compression and decompression on one worker recreates
+// the context each time.
+func WithContextQuota(q *ContextQuota, mode QuotaMode) PoolOption {
+	return func(cfg *poolConfig) error {
+		if q == nil {
+			return &OptionError{Option: "WithContextQuota", Value: q, Allowed: "a non-nil quota"}
+		}
+		if mode != QuotaWait && mode != QuotaFailFast {
+			return &OptionError{Option: "WithContextQuota", Value: mode, Allowed: "QuotaWait or QuotaFailFast"}
+		}
+		cfg.quota = q
+		cfg.quotaMode = mode
+		return nil
+	}
+}
+
+// quotaContexts holds a Pool worker's contexts when a ContextQuota is in
+// use. At most one of c and d is set, and each set context owns one slot.
+type quotaContexts struct {
+	quota *ContextQuota
+	mode  QuotaMode
+	c     *Compressor
+	d     *Decompressor
+}
+
+// release frees the held context, if any, and returns its slot.
+func (w *quotaContexts) release() {
+	if w.c != nil {
+		w.c.Close()
+		w.c = nil
+		w.quota.release()
+	}
+	if w.d != nil {
+		w.d.Close()
+		w.d = nil
+		w.quota.release()
+	}
+}
+
+// compressor returns a compression context, swapping out a held
+// decompression context if necessary.
+func (w *quotaContexts) compressor() (*Compressor, error) {
+	if w.c != nil {
+		return w.c, nil
+	}
+	w.release()
+	if err := w.quota.acquire(w.mode); err != nil {
+		return nil, err
+	}
+	c, err := NewCompressor()
+	if err != nil {
+		w.quota.release()
+		return nil, fmt.Errorf("create compressor: %w", err)
+	}
+	w.c = c
+	return c, nil
+}
+
+// decompressor returns a decompression context, swapping out a held
+// compression context if necessary.
+func (w *quotaContexts) decompressor() (*Decompressor, error) {
+	if w.d != nil {
+		return w.d, nil
+	}
+	w.release()
+	if err := w.quota.acquire(w.mode); err != nil {
+		return nil, err
+	}
+	d, err := NewDecompressor()
+	if err != nil {
+		w.quota.release()
+		return nil, fmt.Errorf("create decompressor: %w", err)
+	}
+	w.d = d
+	return d, nil
+}
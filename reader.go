@@ -5,8 +5,12 @@ package openzl
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
+	"hash/crc32"
 	"io"
+
+	"github.com/borischu/go-openzl/internal/fault"
 )
 
 // Reader implements io.ReadCloser for streaming decompression.
@@ -25,18 +29,101 @@ import (
 //	io.Copy(destWriter, reader)
 //
 // The Reader reads frames written by Writer, which have a 4-byte little-endian
-// frame length header followed by compressed data.
+// frame length header followed by compressed data and, for Writers created
+// with WithFrameChecksum, a CRC32C trailer that is verified before decoding.
 type Reader struct {
 	r            io.Reader     // Underlying reader for compressed data
 	decompressor *Decompressor // Reusable decompressor context
 	buf          []byte        // Buffer for decompressed data from current frame
 	bufPos       int           // Current read position in buffer
 	bufSize      int           // Amount of valid data in buffer
+	frame        []byte        // Compressed frame buffer from the package Allocator
 	closed       bool          // Whether Close() has been called
 	eof          bool          // Whether we've reached end-of-stream marker
+	started      bool          // Whether the first frame header has been read
+	bareFrames   bool          // Accept a bare one-shot frame instead of a stream
+	strictReset  bool          // Reset fails if the stream was not consumed
+	truncated    bool          // Report truncation with the recovered size
+	bare         bool          // The input is a bare frame, without end marker
+	recovered    int64         // Bytes decoded from complete frames of this stream
 	err          error         // Sticky error from previous operations
 }
 
+// ReaderOption configures a Reader.
+type ReaderOption func(*Reader) error
+
+// WithBareFrames lets the Reader consume bare OpenZL frames, such as the
+// output of Compress, in addition to Writer streams.
+//
+// The format is detected from the first bytes of input: Writer streams never
+// begin with an OpenZL magic number, so the two cannot be confused. A bare
+// frame carries no framing of its own, so the Reader reads the remaining input
+// in full and decodes it as a single frame. This is convenient for consuming
+// one-shot outputs through the streaming API, but the whole frame must fit in
+// memory.
+//
+// Without this option, a bare frame is rejected with ErrFrameInput.
+func WithBareFrames(enabled bool) ReaderOption {
+	return func(r *Reader) error {
+		r.bareFrames = enabled
+		return nil
+	}
+}
+
+// WithStrictReset makes Reset return ErrUnconsumedData if the previous stream
+// was neither read to its end-of-stream marker nor explicitly abandoned with
+// Discard.
+//
+// By default, Reset drops any data left in the previous stream and only
+// reports it to the handler set with WithWarnHandler, which can hide bugs
+// such as a decoder that stops reading early. Streams that ended with a read
+// error are considered finished.
+func WithStrictReset(enabled bool) ReaderOption {
+	return func(r *Reader) error {
+		r.strictReset = enabled
+		return nil
+	}
+}
+
+// WithAllowTruncated makes the Reader recover what it can from a stream that
+// was cut short, such as an archive whose writer crashed before Close.
+//
+// Read returns the data of every complete frame and then a *TruncatedError,
+// which matches io.ErrUnexpectedEOF with errors.Is and reports how many bytes
+// were recovered. A stream that ends on a frame boundary without its
+// end-of-stream marker is reported as truncated too, while without this
+// option it reads as if it were complete. Empty input is still an empty
+// stream.
+//
+// Example:
+//
+//	n, err := io.Copy(out, reader)
+//	var trunc *openzl.TruncatedError
+//	if errors.As(err, &trunc) {
+//		log.Printf("archive truncated, recovered %d bytes", trunc.Recovered)
+//	}
+func WithAllowTruncated(enabled bool) ReaderOption {
+	return func(r *Reader) error {
+		r.truncated = enabled
+		return nil
+	}
+}
+
+// TruncatedError reports a stream that ended before its end-of-stream
+// marker, for Readers created with WithAllowTruncated.
+type TruncatedError struct {
+	Recovered int64 // Bytes decoded from the complete frames before the cut
+}
+
+func (e *TruncatedError) Error() string {
+	return fmt.Sprintf("openzl: stream truncated after %d recovered bytes", e.Recovered)
+}
+
+// Unwrap returns io.ErrUnexpectedEOF.
+func (e *TruncatedError) Unwrap() error {
+	return io.ErrUnexpectedEOF
+}
+
 // NewReader creates a new Reader that reads compressed data from r and
 // decompresses it.
 //
@@ -61,7 +148,7 @@ type Reader struct {
 //	if err != nil {
 //	    log.Fatal(err)
 //	}
-func NewReader(r io.Reader) (*Reader, error) {
+func NewReader(r io.Reader, opts ...ReaderOption) (*Reader, error) {
 	if r == nil {
 		return nil, fmt.Errorf("nil reader")
 	}
@@ -72,10 +159,21 @@ func NewReader(r io.Reader) (*Reader, error) {
 		return nil, fmt.Errorf("create decompressor: %w", err)
 	}
 
-	return &Reader{
-		r:            r,
+	decompressor.unclosed.kind = "Reader"
+	reader := &Reader{
+		r:            fault.Reader(r),
 		decompressor: decompressor,
-	}, nil
+	}
+
+	// Apply options
+	for _, opt := range opts {
+		if err := opt(reader); err != nil {
+			decompressor.Close()
+			return nil, err
+		}
+	}
+
+	return reader, nil
 }
 
 // Read decompresses data from the underlying reader into p.
@@ -110,6 +208,9 @@ func (r *Reader) Read(p []byte) (n int, err error) {
 					}
 					return 0, io.EOF
 				}
+				if r.truncated && errors.Is(err, io.ErrUnexpectedEOF) {
+					err = &TruncatedError{Recovered: r.recovered}
+				}
 				r.err = err
 				if totalRead > 0 {
 					return totalRead, nil
@@ -136,24 +237,46 @@ func (r *Reader) Read(p []byte) (n int, err error) {
 // readFrame reads and decompresses the next frame from the underlying reader.
 func (r *Reader) readFrame() error {
 	// Read 4-byte frame header (little-endian compressed size)
-	var header [4]byte
+	var header [FrameHeaderSize]byte
 	if _, err := io.ReadFull(r.r, header[:]); err != nil {
+		if r.truncated && (err == io.ErrUnexpectedEOF || err == io.EOF && r.started && !r.bare) {
+			return io.ErrUnexpectedEOF
+		}
 		if err == io.EOF || err == io.ErrUnexpectedEOF {
 			return io.EOF
 		}
 		return fmt.Errorf("read header: %w", err)
 	}
 
-	// Parse frame size
-	frameSize := binary.LittleEndian.Uint32(header[:])
+	// A stream never starts with an OpenZL magic number, so catch callers
+	// passing one-shot Compress output to NewReader with a clear error.
+	if !r.started {
+		r.started = true
+		if isBareFrame(header[:]) {
+			if !r.bareFrames {
+				return ErrFrameInput
+			}
+			return r.readBareFrame(header[:])
+		}
+	}
+
+	// Parse frame size and flags
+	h, err := ParseFrameHeader(header[:])
+	if err != nil {
+		return err
+	}
 
 	// Zero-length frame is end-of-stream marker
-	if frameSize == 0 {
+	if h.EndOfStream() {
 		return io.EOF
 	}
 
-	// Read compressed frame data
-	compressed := make([]byte, frameSize)
+	// Read compressed frame data into a buffer from the package Allocator.
+	// It is released after decompression, or with the next frame if the
+	// frame is stored and the buffer holds its data.
+	r.releaseFrame()
+	compressed := allocBuf(h.PayloadSize)
+	r.frame = compressed
 	if _, err := io.ReadFull(r.r, compressed); err != nil {
 		if err == io.EOF {
 			return io.ErrUnexpectedEOF
@@ -161,8 +284,32 @@ func (r *Reader) readFrame() error {
 		return fmt.Errorf("read frame: %w", err)
 	}
 
+	// Verify the optional CRC32C trailer before decoding
+	if h.Checksum {
+		var trailer [FrameChecksumSize]byte
+		if _, err := io.ReadFull(r.r, trailer[:]); err != nil {
+			if err == io.EOF {
+				return io.ErrUnexpectedEOF
+			}
+			return fmt.Errorf("read checksum: %w", err)
+		}
+		if binary.LittleEndian.Uint32(trailer[:]) != crc32.Checksum(compressed, crc32cTable) {
+			return ErrChecksumMismatch
+		}
+	}
+
+	// Stored frames hold the uncompressed data as-is
+	if h.Stored {
+		r.buf = compressed
+		r.bufPos = 0
+		r.bufSize = len(compressed)
+		r.recovered += int64(len(compressed))
+		return nil
+	}
+
 	// Decompress frame
 	decompressed, err := r.decompressor.Decompress(compressed)
+	r.releaseFrame()
 	if err != nil {
 		return fmt.Errorf("decompress: %w", err)
 	}
@@ -171,10 +318,48 @@ func (r *Reader) readFrame() error {
 	r.buf = decompressed
 	r.bufPos = 0
 	r.bufSize = len(decompressed)
+	r.recovered += int64(len(decompressed))
 
 	return nil
 }
 
+// readBareFrame decodes the rest of the input as a single one-shot frame
+// whose first bytes have already been consumed into prefix.
+func (r *Reader) readBareFrame(prefix []byte) error {
+	rest, err := io.ReadAll(r.r)
+	if err != nil {
+		return fmt.Errorf("read frame: %w", err)
+	}
+	compressed := append(append([]byte(nil), prefix...), rest...)
+
+	decompressed, err := r.decompressor.Decompress(compressed)
+	if err != nil {
+		return fmt.Errorf("decompress: %w", err)
+	}
+
+	// The underlying reader is drained, so the next readFrame sees EOF
+	r.bare = true
+	r.buf = decompressed
+	r.bufPos = 0
+	r.bufSize = len(decompressed)
+
+	return nil
+}
+
+// Discard abandons the rest of the current stream.
+//
+// Buffered decompressed data is dropped without reading any further input,
+// and subsequent Read calls return io.EOF until the Reader is Reset. Use it
+// before Reset on a Reader created with WithStrictReset when stopping early is
+// intentional.
+func (r *Reader) Discard() {
+	r.releaseFrame()
+	r.buf = nil
+	r.bufPos = 0
+	r.bufSize = 0
+	r.eof = true
+}
+
 // Close releases resources associated with the Reader.
 //
 // Calling Close() multiple times is safe and has no effect after the first call.
@@ -186,10 +371,20 @@ func (r *Reader) Close() error {
 
 	// Close decompressor
 	r.decompressor.Close()
+	r.releaseFrame()
+	r.buf = nil
 
 	return nil
 }
 
+// releaseFrame returns the compressed frame buffer to the package Allocator.
+// Stored frames are decoded in place, so r.buf must not reference it any
+// more.
+func (r *Reader) releaseFrame() {
+	freeBuf(r.frame)
+	r.frame = nil
+}
+
 // Reset resets the Reader to read from a new underlying reader.
 //
 // This allows reuse of the Reader and its internal decompressor context for
@@ -197,6 +392,11 @@ func (r *Reader) Close() error {
 //
 // If the Reader was previously closed, Reset will create a new decompressor.
 //
+// With WithStrictReset, Reset returns ErrUnconsumedData and leaves the Reader
+// unchanged if the previous stream was started but not fully read; call
+// Discard first to abandon it deliberately. A stream never read from, or
+// whose Reader was closed, is not considered unconsumed.
+//
 // Example:
 //
 //	reader, _ := openzl.NewReader(file1)
@@ -211,22 +411,34 @@ func (r *Reader) Reset(reader io.Reader) error {
 		return fmt.Errorf("nil reader")
 	}
 
+	if r.started && !r.closed && !r.eof && r.err == nil {
+		if r.strictReset {
+			return ErrUnconsumedData
+		}
+		warn(fmt.Errorf("%w: reset discarded the rest of the stream", ErrUnconsumedData))
+	}
+
 	// If closed, need to recreate decompressor
 	if r.closed || r.decompressor == nil {
 		decompressor, err := NewDecompressor()
 		if err != nil {
 			return fmt.Errorf("create decompressor: %w", err)
 		}
+		decompressor.unclosed.kind = "Reader"
 		r.decompressor = decompressor
 	}
 
 	// Reset state
-	r.r = reader
+	r.r = fault.Reader(reader)
+	r.releaseFrame()
 	r.buf = nil
 	r.bufPos = 0
 	r.bufSize = 0
 	r.closed = false
 	r.eof = false
+	r.started = false
+	r.bare = false
+	r.recovered = 0
 	r.err = nil
 
 	return nil
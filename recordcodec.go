@@ -0,0 +1,560 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Record container format, shared by CompressRecords, RecordEncoder, and
+// code generated by `zlgo gen`:
+//
+//	section 0:    uvarint rows | uvarint columns | columns x (uvarint len, name, kind, width)
+//	section 1..n: one compressed column each, in schema order
+
+// recordColumnKind is the encoding of one column of a record container.
+type recordColumnKind byte
+
+const (
+	recordInt    recordColumnKind = iota + 1 // Signed integers of the column width
+	recordUint                               // Unsigned integers of the column width
+	recordFloat                              // IEEE floats of the column width
+	recordBool                               // One byte per value
+	recordString                             // Lengths column plus concatenated bytes
+)
+
+// maxRecordRows bounds the number of rows a record container may declare.
+const maxRecordRows = 1 << 30
+
+// recordColumn describes one column of a record container.
+type recordColumn struct {
+	name  string
+	kind  recordColumnKind
+	width int // Element width in bytes for numeric columns, 0 otherwise
+}
+
+// RecordEncoder builds a record container column by column, in the format
+// produced by CompressRecords.
+//
+// It is the reflection-free building block used by codecs generated with
+// `zlgo gen`, and can be used directly to compress columns that are already
+// laid out as slices. Errors are sticky: after the first failed column, the
+// remaining calls do nothing and Encode returns the error.
+//
+// Example:
+//
+//	enc := openzl.NewRecordEncoder(len(ids))
+//	enc.Int("ID", ids, 8)
+//	enc.String("Name", names)
+//	compressed, err := enc.Encode()
+type RecordEncoder struct {
+	rows    int
+	columns []recordColumn
+	data    [][]byte
+	err     error
+}
+
+// NewRecordEncoder returns an encoder for rows records. Every column added
+// must hold exactly rows values.
+func NewRecordEncoder(rows int) *RecordEncoder {
+	return &RecordEncoder{rows: rows}
+}
+
+// add compresses and appends one column unless an earlier column failed.
+func (e *RecordEncoder) add(c recordColumn, n int, compress func() ([]byte, error)) {
+	if e.err != nil {
+		return
+	}
+	if n != e.rows {
+		e.err = fmt.Errorf("%w: column %s has %d values, want %d", ErrInvalidParameter, c.name, n, e.rows)
+		return
+	}
+	for _, prev := range e.columns {
+		if prev.name == c.name {
+			e.err = fmt.Errorf("%w: duplicate column %s", ErrInvalidParameter, c.name)
+			return
+		}
+	}
+	frame, err := compress()
+	if err != nil {
+		e.err = fmt.Errorf("column %s: %w", c.name, err)
+		return
+	}
+	e.columns = append(e.columns, c)
+	e.data = append(e.data, frame)
+}
+
+// checkRecordWidth validates an integer column width.
+func checkRecordWidth(width int) error {
+	if width != 1 && width != 2 && width != 4 && width != 8 {
+		return fmt.Errorf("%w: width must be 1, 2, 4, or 8, got %d", ErrInvalidParameter, width)
+	}
+	return nil
+}
+
+// Int adds a column of signed integers stored in width bytes each (1, 2, 4,
+// or 8). Every value must fit in the width.
+func (e *RecordEncoder) Int(name string, values []int64, width int) {
+	e.add(recordColumn{name, recordInt, width}, len(values), func() ([]byte, error) {
+		if err := checkRecordWidth(width); err != nil {
+			return nil, err
+		}
+		shift := 64 - 8*width
+		for i, v := range values {
+			if v<<shift>>shift != v {
+				return nil, fmt.Errorf("%w: value %d at row %d does not fit in %d bytes", ErrInvalidParameter, v, i, width)
+			}
+		}
+		return compressIntColumn(values, width)
+	})
+}
+
+// Uint adds a column of unsigned integers stored in width bytes each (1, 2,
+// 4, or 8). Every value must fit in the width.
+func (e *RecordEncoder) Uint(name string, values []uint64, width int) {
+	e.add(recordColumn{name, recordUint, width}, len(values), func() ([]byte, error) {
+		if err := checkRecordWidth(width); err != nil {
+			return nil, err
+		}
+		for i, v := range values {
+			if width < 8 && v>>(8*width) != 0 {
+				return nil, fmt.Errorf("%w: value %d at row %d does not fit in %d bytes", ErrInvalidParameter, v, i, width)
+			}
+		}
+		return compressUintColumn(values, width)
+	})
+}
+
+// Float32 adds a column of 4-byte floats.
+func (e *RecordEncoder) Float32(name string, values []float32) {
+	e.add(recordColumn{name, recordFloat, 4}, len(values), func() ([]byte, error) {
+		return CompressNumeric(values)
+	})
+}
+
+// Float64 adds a column of 8-byte floats.
+func (e *RecordEncoder) Float64(name string, values []float64) {
+	e.add(recordColumn{name, recordFloat, 8}, len(values), func() ([]byte, error) {
+		return CompressNumeric(values)
+	})
+}
+
+// Bool adds a column of booleans.
+func (e *RecordEncoder) Bool(name string, values []bool) {
+	e.add(recordColumn{name, recordBool, 0}, len(values), func() ([]byte, error) {
+		bytes := make([]uint8, len(values))
+		for i, v := range values {
+			if v {
+				bytes[i] = 1
+			}
+		}
+		return CompressNumeric(bytes)
+	})
+}
+
+// String adds a column of strings.
+func (e *RecordEncoder) String(name string, values []string) {
+	e.add(recordColumn{name, recordString, 0}, len(values), func() ([]byte, error) {
+		lengths := make([]uint32, len(values))
+		var data []byte
+		for i, s := range values {
+			if len(s) > math.MaxUint32 {
+				return nil, fmt.Errorf("%w: string at row %d is too long", ErrInvalidParameter, i)
+			}
+			lengths[i] = uint32(len(s))
+			data = append(data, s...)
+		}
+		return compressStringColumn(lengths, data)
+	})
+}
+
+// Encode returns the record container holding the columns added so far.
+//
+// Returns the first error from adding a column, ErrEmptyInput if there are
+// no rows, or ErrInvalidParameter if no column was added.
+func (e *RecordEncoder) Encode() ([]byte, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	if e.rows <= 0 {
+		return nil, ErrEmptyInput
+	}
+	if len(e.columns) == 0 {
+		return nil, fmt.Errorf("%w: no columns", ErrInvalidParameter)
+	}
+
+	schema := binary.AppendUvarint(nil, uint64(e.rows))
+	schema = binary.AppendUvarint(schema, uint64(len(e.columns)))
+	for _, c := range e.columns {
+		schema = binary.AppendUvarint(schema, uint64(len(c.name)))
+		schema = append(schema, c.name...)
+		schema = append(schema, byte(c.kind), byte(c.width))
+	}
+	return encodeContainer(containerRecords, append([][]byte{schema}, e.data...)...), nil
+}
+
+// RecordDecoder reads the columns of a record container produced by
+// CompressRecords or RecordEncoder.
+//
+// Columns are looked up by name and may be read in any order. Errors are
+// sticky: after the first failure, the remaining calls return nil and Finish
+// returns the error. Every column returned before a successful Finish holds
+// exactly Len values.
+//
+// Example:
+//
+//	dec, err := openzl.NewRecordDecoder(compressed)
+//	if err != nil {
+//		return err
+//	}
+//	ids := dec.Int("ID", 8)
+//	names := dec.String("Name")
+//	if err := dec.Finish(); err != nil {
+//		return err
+//	}
+type RecordDecoder struct {
+	rows    int
+	columns []recordColumn
+	data    [][]byte
+	read    []bool
+	err     error
+}
+
+// NewRecordDecoder parses the schema of a record container.
+//
+// Returns ErrEmptyInput if compressed is empty, or ErrCorruptedData if it is
+// not a valid record container.
+func NewRecordDecoder(compressed []byte) (*RecordDecoder, error) {
+	if len(compressed) == 0 {
+		return nil, ErrEmptyInput
+	}
+	sections, err := decodeContainer(compressed, containerRecords, -1)
+	if err != nil {
+		return nil, err
+	}
+	if len(sections) == 0 {
+		return nil, fmt.Errorf("%w: missing record schema", ErrCorruptedData)
+	}
+	rows, columns, err := parseRecordSchema(sections[0])
+	if err != nil {
+		return nil, err
+	}
+	if len(columns) != len(sections)-1 {
+		return nil, fmt.Errorf("%w: schema has %d columns, data has %d", ErrCorruptedData, len(columns), len(sections)-1)
+	}
+	return &RecordDecoder{
+		rows:    rows,
+		columns: columns,
+		data:    sections[1:],
+		read:    make([]bool, len(columns)),
+	}, nil
+}
+
+// parseRecordSchema parses the schema section of a record container.
+func parseRecordSchema(b []byte) (int, []recordColumn, error) {
+	corrupt := fmt.Errorf("%w: invalid record schema", ErrCorruptedData)
+	n, k := binary.Uvarint(b)
+	if k <= 0 || n == 0 || n > maxRecordRows {
+		return 0, nil, corrupt
+	}
+	b = b[k:]
+	count, k := binary.Uvarint(b)
+	if k <= 0 || count > uint64(len(b)) {
+		return 0, nil, corrupt
+	}
+	b = b[k:]
+
+	columns := make([]recordColumn, count)
+	for i := range columns {
+		size, k := binary.Uvarint(b)
+		if k <= 0 || size > uint64(len(b)-k) || uint64(len(b)-k)-size < 2 {
+			return 0, nil, corrupt
+		}
+		b = b[k:]
+		columns[i] = recordColumn{
+			name:  string(b[:size]),
+			kind:  recordColumnKind(b[size]),
+			width: int(b[size+1]),
+		}
+		b = b[size+2:]
+	}
+	if len(b) != 0 {
+		return 0, nil, corrupt
+	}
+	return int(n), columns, nil
+}
+
+// Len returns the number of records.
+func (d *RecordDecoder) Len() int {
+	return d.rows
+}
+
+// column returns the compressed data of the named column after checking its
+// encoding, or nil after recording an error.
+func (d *RecordDecoder) column(want recordColumn) []byte {
+	if d.err != nil {
+		return nil
+	}
+	for i, c := range d.columns {
+		if c.name != want.name {
+			continue
+		}
+		if c.kind != want.kind || c.width != want.width {
+			d.err = fmt.Errorf("%w: column %s does not match the requested type or width", ErrCorruptedData, c.name)
+			return nil
+		}
+		d.read[i] = true
+		return d.data[i]
+	}
+	d.err = fmt.Errorf("%w: no column %s", ErrCorruptedData, want.name)
+	return nil
+}
+
+// check records err, or a length mismatch if the column does not hold one
+// value per record, and reports whether the column is usable.
+func (d *RecordDecoder) check(name string, n int, err error) bool {
+	if d.err != nil {
+		return false
+	}
+	if err != nil {
+		d.err = fmt.Errorf("column %s: %w", name, err)
+		return false
+	}
+	if n != d.rows {
+		d.err = fmt.Errorf("%w: column %s has %d values, want %d", ErrCorruptedData, name, n, d.rows)
+		return false
+	}
+	return true
+}
+
+// Int returns a column of signed integers stored in width bytes each.
+func (d *RecordDecoder) Int(name string, width int) []int64 {
+	b := d.column(recordColumn{name, recordInt, width})
+	if b == nil {
+		return nil
+	}
+	values, err := decompressIntColumn(b, width)
+	if !d.check(name, len(values), err) {
+		return nil
+	}
+	return values
+}
+
+// Uint returns a column of unsigned integers stored in width bytes each.
+func (d *RecordDecoder) Uint(name string, width int) []uint64 {
+	b := d.column(recordColumn{name, recordUint, width})
+	if b == nil {
+		return nil
+	}
+	values, err := decompressUintColumn(b, width)
+	if !d.check(name, len(values), err) {
+		return nil
+	}
+	return values
+}
+
+// Float32 returns a column of 4-byte floats.
+func (d *RecordDecoder) Float32(name string) []float32 {
+	b := d.column(recordColumn{name, recordFloat, 4})
+	if b == nil {
+		return nil
+	}
+	values, err := DecompressNumeric[float32](b)
+	if !d.check(name, len(values), err) {
+		return nil
+	}
+	return values
+}
+
+// Float64 returns a column of 8-byte floats.
+func (d *RecordDecoder) Float64(name string) []float64 {
+	b := d.column(recordColumn{name, recordFloat, 8})
+	if b == nil {
+		return nil
+	}
+	values, err := DecompressNumeric[float64](b)
+	if !d.check(name, len(values), err) {
+		return nil
+	}
+	return values
+}
+
+// Bool returns a column of booleans.
+func (d *RecordDecoder) Bool(name string) []bool {
+	b := d.column(recordColumn{name, recordBool, 0})
+	if b == nil {
+		return nil
+	}
+	bytes, err := DecompressNumeric[uint8](b)
+	if !d.check(name, len(bytes), err) {
+		return nil
+	}
+	values := make([]bool, len(bytes))
+	for i, v := range bytes {
+		values[i] = v != 0
+	}
+	return values
+}
+
+// String returns a column of strings.
+func (d *RecordDecoder) String(name string) []string {
+	b := d.column(recordColumn{name, recordString, 0})
+	if b == nil {
+		return nil
+	}
+	lengths, data, err := decompressStringColumn(b)
+	if !d.check(name, len(lengths), err) {
+		return nil
+	}
+	// Share one allocation between all strings
+	all := string(data)
+	values := make([]string, len(lengths))
+	off := 0
+	for i, l := range lengths {
+		values[i] = all[off : off+int(l)]
+		off += int(l)
+	}
+	return values
+}
+
+// Err returns the first error from reading a column. Unlike Finish, it does
+// not require every column to be read, for callers that project a subset of
+// the columns.
+func (d *RecordDecoder) Err() error {
+	return d.err
+}
+
+// Finish returns the first error from reading a column, or ErrCorruptedData
+// if the container holds columns that were not read, which means the data
+// was written from a different record type.
+func (d *RecordDecoder) Finish() error {
+	if d.err != nil {
+		return d.err
+	}
+	for i, read := range d.read {
+		if !read {
+			return fmt.Errorf("%w: column %s was not read", ErrCorruptedData, d.columns[i].name)
+		}
+	}
+	return nil
+}
+
+// widen converts a slice element by element.
+func widen[From, To Numeric](values []From) []To {
+	out := make([]To, len(values))
+	for i, v := range values {
+		out[i] = To(v)
+	}
+	return out
+}
+
+// compressIntColumn compresses values as signed integers of the given width.
+// Every value must fit in the width.
+func compressIntColumn(values []int64, width int) ([]byte, error) {
+	switch width {
+	case 1:
+		return CompressNumeric(widen[int64, int8](values))
+	case 2:
+		return CompressNumeric(widen[int64, int16](values))
+	case 4:
+		return CompressNumeric(widen[int64, int32](values))
+	default:
+		return CompressNumeric(values)
+	}
+}
+
+// decompressIntColumn reverses compressIntColumn.
+func decompressIntColumn(b []byte, width int) ([]int64, error) {
+	switch width {
+	case 1:
+		values, err := DecompressNumeric[int8](b)
+		return widen[int8, int64](values), err
+	case 2:
+		values, err := DecompressNumeric[int16](b)
+		return widen[int16, int64](values), err
+	case 4:
+		values, err := DecompressNumeric[int32](b)
+		return widen[int32, int64](values), err
+	default:
+		return DecompressNumeric[int64](b)
+	}
+}
+
+// compressUintColumn compresses values as unsigned integers of the given
+// width. Every value must fit in the width.
+func compressUintColumn(values []uint64, width int) ([]byte, error) {
+	switch width {
+	case 1:
+		return CompressNumeric(widen[uint64, uint8](values))
+	case 2:
+		return CompressNumeric(widen[uint64, uint16](values))
+	case 4:
+		return CompressNumeric(widen[uint64, uint32](values))
+	default:
+		return CompressNumeric(values)
+	}
+}
+
+// decompressUintColumn reverses compressUintColumn.
+func decompressUintColumn(b []byte, width int) ([]uint64, error) {
+	switch width {
+	case 1:
+		values, err := DecompressNumeric[uint8](b)
+		return widen[uint8, uint64](values), err
+	case 2:
+		values, err := DecompressNumeric[uint16](b)
+		return widen[uint16, uint64](values), err
+	case 4:
+		values, err := DecompressNumeric[uint32](b)
+		return widen[uint32, uint64](values), err
+	default:
+		return DecompressNumeric[uint64](b)
+	}
+}
+
+// compressStringColumn compresses string lengths with the numeric graph and
+// their concatenated bytes with the generic graph, as one column:
+//
+//	uvarint len(lengths frame) | lengths frame | bytes section
+func compressStringColumn(lengths []uint32, data []byte) ([]byte, error) {
+	lengthFrame, err := CompressNumeric(lengths)
+	if err != nil {
+		return nil, err
+	}
+	dataFrame, err := compressSection(data)
+	if err != nil {
+		return nil, err
+	}
+	out := binary.AppendUvarint(nil, uint64(len(lengthFrame)))
+	out = append(out, lengthFrame...)
+	return append(out, dataFrame...), nil
+}
+
+// decompressStringColumn reverses compressStringColumn. It verifies that the
+// lengths add up to the size of the data.
+func decompressStringColumn(b []byte) ([]uint32, []byte, error) {
+	size, k := binary.Uvarint(b)
+	if k <= 0 || size > uint64(len(b)-k) {
+		return nil, nil, fmt.Errorf("%w: truncated string column", ErrCorruptedData)
+	}
+	lengths, err := DecompressNumeric[uint32](b[k : k+int(size)])
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := decompressSection(b[k+int(size):])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	total := 0
+	for _, l := range lengths {
+		total += int(l)
+	}
+	if total != len(data) {
+		return nil, nil, fmt.Errorf("%w: string lengths do not match data", ErrCorruptedData)
+	}
+	return lengths, data, nil
+}
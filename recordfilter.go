@@ -0,0 +1,263 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"slices"
+	"unsafe"
+)
+
+// recordStat is the value range of one numeric column in a batch of a record
+// stream. RecordWriter stores it ahead of each batch so that readers can skip
+// batches that cannot match a filter without decompressing them:
+//
+//	count (uvarint) | count x (name len uvarint, name, kind, min, max)
+//
+// min and max are 8-byte little-endian int64, uint64, or float64 bits,
+// depending on the kind. Float columns whose values are all NaN have no
+// entry.
+type recordStat struct {
+	kind     recordColumnKind
+	min, max uint64
+}
+
+// recordStats holds the column ranges of one batch, by column name.
+type recordStats map[string]recordStat
+
+// batchStats computes the ranges of the numeric fields of records.
+func batchStats[T any](records []T, fields []recordField) recordStats {
+	stats := make(recordStats)
+	rows := reflect.ValueOf(records)
+	for _, f := range fields {
+		field := func(i int) reflect.Value { return rows.Index(i).Field(f.index) }
+		switch f.kind {
+		case recordInt:
+			lo, hi := int64(math.MaxInt64), int64(math.MinInt64)
+			for i := range records {
+				v := field(i).Int()
+				if v < lo {
+					lo = v
+				}
+				if v > hi {
+					hi = v
+				}
+			}
+			stats[f.name] = recordStat{recordInt, uint64(lo), uint64(hi)}
+		case recordUint:
+			lo, hi := uint64(math.MaxUint64), uint64(0)
+			for i := range records {
+				v := field(i).Uint()
+				if v < lo {
+					lo = v
+				}
+				if v > hi {
+					hi = v
+				}
+			}
+			stats[f.name] = recordStat{recordUint, lo, hi}
+		case recordFloat:
+			lo, hi := math.Inf(1), math.Inf(-1)
+			for i := range records {
+				v := field(i).Float()
+				if v < lo {
+					lo = v
+				}
+				if v > hi {
+					hi = v
+				}
+			}
+			// NaN compares false, so it never widens the range
+			if lo <= hi {
+				stats[f.name] = recordStat{recordFloat, math.Float64bits(lo), math.Float64bits(hi)}
+			}
+		}
+	}
+	return stats
+}
+
+// appendStats appends the encoding of stats to b, in column name order.
+func appendStats(b []byte, stats recordStats) []byte {
+	names := make([]string, 0, len(stats))
+	for name := range stats {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	b = binary.AppendUvarint(b, uint64(len(names)))
+	for _, name := range names {
+		s := stats[name]
+		b = binary.AppendUvarint(b, uint64(len(name)))
+		b = append(b, name...)
+		b = append(b, byte(s.kind))
+		b = binary.LittleEndian.AppendUint64(b, s.min)
+		b = binary.LittleEndian.AppendUint64(b, s.max)
+	}
+	return b
+}
+
+// parseStats parses a stats block written by appendStats.
+func parseStats(b []byte) (recordStats, error) {
+	corrupt := fmt.Errorf("%w: invalid batch statistics", ErrCorruptedData)
+	n, k := binary.Uvarint(b)
+	if k <= 0 || n > uint64(len(b)) {
+		return nil, corrupt
+	}
+	b = b[k:]
+
+	stats := make(recordStats, n)
+	for range n {
+		size, k := binary.Uvarint(b)
+		if k <= 0 || size > uint64(len(b)-k) {
+			return nil, corrupt
+		}
+		name := string(b[k : k+int(size)])
+		b = b[k+int(size):]
+		if len(b) < 17 {
+			return nil, corrupt
+		}
+		s := recordStat{
+			kind: recordColumnKind(b[0]),
+			min:  binary.LittleEndian.Uint64(b[1:]),
+			max:  binary.LittleEndian.Uint64(b[9:]),
+		}
+		if s.kind != recordInt && s.kind != recordUint && s.kind != recordFloat {
+			return nil, corrupt
+		}
+		stats[name] = s
+		b = b[17:]
+	}
+	if len(b) != 0 {
+		return nil, corrupt
+	}
+	return stats, nil
+}
+
+// Predicate selects records by the value of one numeric column.
+//
+// Values must lie in the inclusive range [Min, Max]; batches whose stored
+// range does not overlap it are skipped without being decompressed. Match,
+// if set, is an exact filter applied to the values in range, for conditions
+// that a range cannot express.
+type Predicate[V Numeric] struct {
+	Min, Max V
+	Match    func(V) bool
+}
+
+// Between returns a Predicate matching values in the inclusive range
+// [lo, hi].
+func Between[V Numeric](lo, hi V) Predicate[V] {
+	return Predicate[V]{Min: lo, Max: hi}
+}
+
+// ScanWhere reads records from a record stream and calls fn, in order, with
+// each record whose column value satisfies pred.
+//
+// RecordWriter stores the minimum and maximum of every numeric column ahead
+// of each batch, so a selective range query reads past most batches without
+// decompressing them, which turns a record stream into a lightweight
+// analytical store. A single CompressRecords container carries no ranges
+// and is filtered record by record. The scan stops at the first error from
+// fn, which ScanWhere returns.
+//
+// V must be the same class of number as the column (signed, unsigned, or
+// float) and at least as wide as it is stored, so that every value converts
+// to V exactly.
+//
+// Example:
+//
+//	// Events of one hour that took longer than a second
+//	err := openzl.ScanWhere(file, "Timestamp", openzl.Between(start, start+3600_000),
+//		func(ev Event) error {
+//			if ev.Latency > time.Second {
+//				slow = append(slow, ev)
+//			}
+//			return nil
+//		})
+//
+// Returns ErrInvalidParameter if column is not a stored numeric field of T
+// or does not fit V, and ErrCorruptedData if the stream is invalid.
+func ScanWhere[T any, V Numeric](r io.Reader, column string, pred Predicate[V], fn func(T) error) error {
+	rr, err := NewRecordReader[T](r)
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(rr.fields, func(f recordField) bool { return f.name == column })
+	if i < 0 {
+		return fmt.Errorf("%w: %v has no stored field %s", ErrInvalidParameter, reflect.TypeFor[T](), column)
+	}
+	f := rr.fields[i]
+	var zero V
+	if numericKind[V]() != f.kind || int(unsafe.Sizeof(zero)) < f.width {
+		return fmt.Errorf("%w: %d-byte column %s cannot be compared as %T", ErrInvalidParameter, f.width, column, zero)
+	}
+
+	rr.skip = func(stats recordStats) bool {
+		s, ok := stats[column]
+		return ok && s.kind == f.kind && !pred.overlaps(s)
+	}
+	for {
+		if err := rr.readBatch(); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		for row := range rr.columns.rows {
+			if !pred.matches(rr.columns.values[i], row) {
+				continue
+			}
+			var rec T
+			if err := rr.columns.set(reflect.ValueOf(&rec).Elem(), row); err != nil {
+				return err
+			}
+			if err := fn(rec); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// numericKind returns the column kind that stores values of type V.
+func numericKind[V Numeric]() recordColumnKind {
+	switch reflect.TypeFor[V]().Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return recordInt
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return recordUint
+	default:
+		return recordFloat
+	}
+}
+
+// overlaps reports whether a batch with the value range s may hold matches.
+func (p Predicate[V]) overlaps(s recordStat) bool {
+	switch s.kind {
+	case recordInt:
+		return int64(s.min) <= int64(p.Max) && int64(s.max) >= int64(p.Min)
+	case recordUint:
+		return s.min <= uint64(p.Max) && s.max >= uint64(p.Min)
+	default:
+		return math.Float64frombits(s.min) <= float64(p.Max) && math.Float64frombits(s.max) >= float64(p.Min)
+	}
+}
+
+// matches reports whether the value at row of a decoded column satisfies p.
+func (p Predicate[V]) matches(column any, row int) bool {
+	var v V
+	switch values := column.(type) {
+	case []int64:
+		v = V(values[row])
+	case []uint64:
+		v = V(values[row])
+	case []float32:
+		v = V(values[row])
+	case []float64:
+		v = V(values[row])
+	}
+	return v >= p.Min && v <= p.Max && (p.Match == nil || p.Match(v))
+}
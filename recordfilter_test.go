@@ -0,0 +1,147 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestScanWhere(t *testing.T) {
+	events := testEvents(1000)
+	stream := writeRecordStream(t, events, 100)
+	start := events[0].Timestamp
+
+	tests := []struct {
+		name string
+		scan func(fn func(testEvent) error) error
+		want func(testEvent) bool
+	}{
+		{
+			"range",
+			func(fn func(testEvent) error) error {
+				return ScanWhere(bytes.NewReader(stream), "Timestamp", Between(start+250*120, start+250*340), fn)
+			},
+			func(ev testEvent) bool { return ev.Timestamp >= start+250*120 && ev.Timestamp <= start+250*340 },
+		},
+		{
+			"exact filter",
+			func(fn func(testEvent) error) error {
+				pred := Predicate[uint32]{Min: 1010, Max: 1020, Match: func(v uint32) bool { return v%2 == 0 }}
+				return ScanWhere(bytes.NewReader(stream), "UserID", pred, fn)
+			},
+			func(ev testEvent) bool { return ev.UserID >= 1010 && ev.UserID <= 1020 && ev.UserID%2 == 0 },
+		},
+		{
+			"float",
+			func(fn func(testEvent) error) error {
+				return ScanWhere(bytes.NewReader(stream), "Score", Between[float32](1, 1.5), fn)
+			},
+			func(ev testEvent) bool { return ev.Score >= 1 && ev.Score <= 1.5 },
+		},
+		{
+			"no matches",
+			func(fn func(testEvent) error) error {
+				return ScanWhere(bytes.NewReader(stream), "Latency", Between[int64](-10, -1), fn)
+			},
+			func(testEvent) bool { return false },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []testEvent
+			if err := tt.scan(func(ev testEvent) error {
+				got = append(got, ev)
+				return nil
+			}); err != nil {
+				t.Fatalf("ScanWhere() failed: %v", err)
+			}
+			var want []testEvent
+			for _, ev := range events {
+				if tt.want(ev) {
+					want = append(want, ev)
+				}
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ScanWhere() returned %d records, want %d", len(got), len(want))
+			}
+		})
+	}
+}
+
+func TestScanWhere_SkipsBatches(t *testing.T) {
+	events := testEvents(200)
+	stream := writeRecordStream(t, events, 100)
+
+	// Corrupt the container magic of the second batch, past the stats and
+	// container of the first batch and the stats of the second
+	off := len(recordStreamMagic)
+	for range 3 {
+		n, k := binary.Uvarint(stream[off:])
+		off += k + int(n)
+	}
+	_, k := binary.Uvarint(stream[off:])
+	corrupted := append([]byte(nil), stream...)
+	corrupted[off+k] ^= 0xff
+
+	// A query on the first batch never decodes the second
+	var count int
+	err := ScanWhere(bytes.NewReader(corrupted), "Timestamp", Between(events[0].Timestamp, events[99].Timestamp), func(testEvent) error {
+		count++
+		return nil
+	})
+	if err != nil || count != 100 {
+		t.Errorf("ScanWhere() = %d records, %v; want 100 records, nil", count, err)
+	}
+
+	err = ScanWhere(bytes.NewReader(corrupted), "Timestamp", Between[int64](math.MinInt64, math.MaxInt64), func(testEvent) error { return nil })
+	if err == nil {
+		t.Error("ScanWhere() over the corrupted batch succeeded")
+	}
+}
+
+func TestScanWhere_Errors(t *testing.T) {
+	stream := writeRecordStream(t, testEvents(100), 50)
+	noop := func(testEvent) error { return nil }
+
+	for name, err := range map[string]error{
+		"unknown column":  ScanWhere(bytes.NewReader(stream), "Missing", Between[int64](0, 1), noop),
+		"string column":   ScanWhere(bytes.NewReader(stream), "Kind", Between[int64](0, 1), noop),
+		"wrong class":     ScanWhere(bytes.NewReader(stream), "Timestamp", Between[uint64](0, 1), noop),
+		"narrower than 8": ScanWhere(bytes.NewReader(stream), "Timestamp", Between[int32](0, 1), noop),
+	} {
+		if !errors.Is(err, ErrInvalidParameter) {
+			t.Errorf("%s: ScanWhere() = %v, want ErrInvalidParameter", name, err)
+		}
+	}
+
+	errStop := errors.New("stop")
+	err := ScanWhere(bytes.NewReader(stream), "Latency", Between(int64(0), int64(time.Hour)), func(testEvent) error { return errStop })
+	if !errors.Is(err, errStop) {
+		t.Errorf("ScanWhere() = %v, want the callback error", err)
+	}
+}
+
+func TestRecordStats_RoundTrip(t *testing.T) {
+	stats := recordStats{
+		"a": {recordInt, uint64(math.MaxUint64), 5},
+		"b": {recordUint, 1, math.MaxUint64},
+		"c": {recordFloat, math.Float64bits(-1.5), math.Float64bits(2)},
+	}
+	got, err := parseStats(appendStats(nil, stats))
+	if err != nil {
+		t.Fatalf("parseStats() failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, stats) {
+		t.Errorf("parseStats() = %v, want %v", got, stats)
+	}
+	if _, err := parseStats([]byte{1, 1, 'a', byte(recordString)}); !errors.Is(err, ErrCorruptedData) {
+		t.Errorf("parseStats() of truncated stats = %v, want ErrCorruptedData", err)
+	}
+}
@@ -0,0 +1,301 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/borischu/go-openzl/internal/recordtag"
+)
+
+// recordField describes how one struct field is stored.
+type recordField struct {
+	recordColumn
+	index int // Field index in the struct
+}
+
+// recordPlans caches the fields of each struct type passed to CompressRecords
+// or DecompressRecords, keyed by reflect.Type.
+var recordPlans sync.Map
+
+// recordFieldsOf returns the stored fields of struct type t.
+//
+// Exported fields of integer, float, bool, and string kinds (including named
+// types such as time.Duration) are stored as columns. The openzl tag adjusts
+// this per field, with comma-separated options:
+//   - "column" stores the field; this is the default and may be omitted
+//   - "skip" (or "-") leaves the field out; it is zero after decompression
+//   - "width=N" stores an integer field in N bytes (1, 2, 4, or 8)
+func recordFieldsOf(t reflect.Type) ([]recordField, error) {
+	if plan, ok := recordPlans.Load(t); ok {
+		return plan.([]recordField), nil
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: records must be structs, got %v", ErrInvalidParameter, t)
+	}
+
+	var fields []recordField
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		f, skip, err := parseRecordField(sf)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v.%s: %v", ErrInvalidParameter, t, sf.Name, err)
+		}
+		if skip {
+			continue
+		}
+		f.index = i
+		fields = append(fields, f)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("%w: %v has no fields to store", ErrInvalidParameter, t)
+	}
+
+	recordPlans.Store(t, fields)
+	return fields, nil
+}
+
+// parseRecordField derives the column of a struct field from its type and
+// openzl tag. It reports skip for unexported and skipped fields.
+func parseRecordField(sf reflect.StructField) (f recordField, skip bool, err error) {
+	tag, tagged := sf.Tag.Lookup(recordtag.Key)
+	opts, err := recordtag.Parse(tag)
+	if err != nil {
+		return f, false, err
+	}
+	if opts.Skip {
+		return f, true, nil
+	}
+	if !sf.IsExported() {
+		if tagged {
+			return f, false, fmt.Errorf("unexported field cannot be stored")
+		}
+		return f, true, nil
+	}
+
+	f.name = sf.Name
+	f.width = int(sf.Type.Size())
+	switch sf.Type.Kind() {
+	case reflect.Int, reflect.Uint, reflect.Uintptr:
+		// Platform-sized integers are always stored in 8 bytes, so the
+		// data can be read on platforms with a different word size
+		f.width = 8
+	}
+	switch sf.Type.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		f.kind = recordInt
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		f.kind = recordUint
+	case reflect.Float32, reflect.Float64:
+		f.kind = recordFloat
+	case reflect.Bool:
+		f.kind = recordBool
+	case reflect.String:
+		f.kind = recordString
+	default:
+		return f, false, fmt.Errorf("unsupported type %v, tag it openzl:\"skip\"", sf.Type)
+	}
+
+	if opts.Width != 0 {
+		if f.kind != recordInt && f.kind != recordUint {
+			return f, false, fmt.Errorf("width applies only to integer fields")
+		}
+		if opts.Width > f.width {
+			return f, false, fmt.Errorf("width %d exceeds the %d-byte field", opts.Width, f.width)
+		}
+		f.width = opts.Width
+	}
+	if f.kind == recordBool || f.kind == recordString {
+		f.width = 0
+	}
+	return f, false, nil
+}
+
+// CompressRecords compresses a slice of structs column by column.
+//
+// Each stored field becomes a column that is compressed with the graph suited
+// to its type: integers and floats with OpenZL's numeric graph, booleans as
+// bytes, and strings as a lengths column plus their concatenated bytes. This
+// typically compresses much better than serializing the records row by row,
+// and existing structs only need tags where the defaults do not fit:
+//
+//	type Event struct {
+//		Timestamp int64
+//		UserID    uint64 `openzl:"width=4"` // Values fit in 32 bits
+//		Kind      string
+//		Score     float32
+//		cache     []byte // Unexported fields are never stored
+//		Raw       []byte `openzl:"skip"`
+//	}
+//
+// Exported fields of integer, float, bool, and string kinds are stored by
+// default. The openzl tag takes comma-separated options: "column" (the
+// default), "skip" to leave a field out, and "width=N" to store an integer
+// field in N bytes (1, 2, 4, or 8). Fields of other types must be skipped.
+//
+// For hot paths, `zlgo gen` generates equivalent codecs without reflection;
+// both produce the same format.
+//
+// Example:
+//
+//	compressed, err := openzl.CompressRecords(events)
+//	...
+//	events, err := openzl.DecompressRecords[Event](compressed)
+//
+// Returns ErrEmptyInput if records is empty, or ErrInvalidParameter if T is
+// not a struct, has an unsupported field, or a value does not fit the width
+// given in its tag.
+func CompressRecords[T any](records []T) ([]byte, error) {
+	if len(records) == 0 {
+		return nil, ErrEmptyInput
+	}
+	fields, err := recordFieldsOf(reflect.TypeFor[T]())
+	if err != nil {
+		return nil, err
+	}
+
+	rows := reflect.ValueOf(records)
+	enc := NewRecordEncoder(len(records))
+	for _, f := range fields {
+		field := func(i int) reflect.Value { return rows.Index(i).Field(f.index) }
+		switch f.kind {
+		case recordInt:
+			enc.Int(f.name, gatherRecords(len(records), func(i int) int64 { return field(i).Int() }), f.width)
+		case recordUint:
+			enc.Uint(f.name, gatherRecords(len(records), func(i int) uint64 { return field(i).Uint() }), f.width)
+		case recordFloat:
+			if f.width == 4 {
+				enc.Float32(f.name, gatherRecords(len(records), func(i int) float32 { return float32(field(i).Float()) }))
+			} else {
+				enc.Float64(f.name, gatherRecords(len(records), func(i int) float64 { return field(i).Float() }))
+			}
+		case recordBool:
+			enc.Bool(f.name, gatherRecords(len(records), func(i int) bool { return field(i).Bool() }))
+		default:
+			enc.String(f.name, gatherRecords(len(records), func(i int) string { return field(i).String() }))
+		}
+	}
+	return enc.Encode()
+}
+
+// gatherRecords returns the n values returned by get for each row.
+func gatherRecords[V any](n int, get func(int) V) []V {
+	values := make([]V, n)
+	for i := range values {
+		values[i] = get(i)
+	}
+	return values
+}
+
+// DecompressRecords restores records compressed by CompressRecords.
+//
+// T must store the same columns, by name, type, and width, as the type the
+// records were compressed from; field order does not matter. Skipped fields
+// are left at their zero value.
+//
+// Returns ErrCorruptedData if the data is invalid or its columns do not match
+// the fields of T, or ErrInvalidParameter if T is not a valid record type.
+func DecompressRecords[T any](compressed []byte) ([]T, error) {
+	if len(compressed) == 0 {
+		return nil, ErrEmptyInput
+	}
+	fields, err := recordFieldsOf(reflect.TypeFor[T]())
+	if err != nil {
+		return nil, err
+	}
+	return decodeRecords[T](compressed, fields)
+}
+
+// decodeRecords decodes the records of one record container.
+func decodeRecords[T any](compressed []byte, fields []recordField) ([]T, error) {
+	columns, err := decodeRecordColumns(compressed, fields, false)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]T, columns.rows)
+	rows := reflect.ValueOf(records)
+	for i := range records {
+		if err := columns.set(rows.Index(i), i); err != nil {
+			return nil, err
+		}
+	}
+	return records, nil
+}
+
+// recordColumns holds the decoded columns of a record container, one per
+// stored field.
+type recordColumns struct {
+	fields []recordField
+	values []any // []int64, []uint64, []float32, []float64, []bool, or []string
+	rows   int
+}
+
+// decodeRecordColumns decodes the columns of fields from a record container.
+// With partial set, columns of the container that are not among fields are
+// skipped without being decompressed; otherwise they are an error.
+func decodeRecordColumns(compressed []byte, fields []recordField, partial bool) (*recordColumns, error) {
+	dec, err := NewRecordDecoder(compressed)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &recordColumns{fields: fields, values: make([]any, len(fields)), rows: dec.Len()}
+	for i, f := range fields {
+		switch f.kind {
+		case recordInt:
+			c.values[i] = dec.Int(f.name, f.width)
+		case recordUint:
+			c.values[i] = dec.Uint(f.name, f.width)
+		case recordFloat:
+			if f.width == 4 {
+				c.values[i] = dec.Float32(f.name)
+			} else {
+				c.values[i] = dec.Float64(f.name)
+			}
+		case recordBool:
+			c.values[i] = dec.Bool(f.name)
+		default:
+			c.values[i] = dec.String(f.name)
+		}
+	}
+	finish := dec.Finish
+	if partial {
+		finish = dec.Err
+	}
+	if err := finish(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// set stores the given row in the struct v.
+func (c *recordColumns) set(v reflect.Value, row int) error {
+	for i, f := range c.fields {
+		field := v.Field(f.index)
+		switch values := c.values[i].(type) {
+		case []int64:
+			if field.OverflowInt(values[row]) {
+				return fmt.Errorf("%w: value %d at row %d overflows field %s", ErrCorruptedData, values[row], row, f.name)
+			}
+			field.SetInt(values[row])
+		case []uint64:
+			if field.OverflowUint(values[row]) {
+				return fmt.Errorf("%w: value %d at row %d overflows field %s", ErrCorruptedData, values[row], row, f.name)
+			}
+			field.SetUint(values[row])
+		case []float32:
+			field.SetFloat(float64(values[row]))
+		case []float64:
+			field.SetFloat(values[row])
+		case []bool:
+			field.SetBool(values[row])
+		case []string:
+			field.SetString(values[row])
+		}
+	}
+	return nil
+}
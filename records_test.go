@@ -0,0 +1,151 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testEvent struct {
+	Timestamp int64
+	UserID    uint64 `openzl:"column,width=4"`
+	Kind      string
+	Score     float32
+	Latency   time.Duration
+	Error     bool
+	Count     int    `openzl:"width=2"`
+	Raw       []byte `openzl:"skip"`
+	note      string
+}
+
+func testEvents(n int) []testEvent {
+	kinds := []string{"click", "view", "purchase", ""}
+	events := make([]testEvent, n)
+	for i := range events {
+		events[i] = testEvent{
+			Timestamp: 1_700_000_000_000 + int64(i)*250,
+			UserID:    uint64(1000 + i%37),
+			Kind:      kinds[i%len(kinds)],
+			Score:     float32(i%10) / 4,
+			Latency:   time.Duration(i%50) * time.Millisecond,
+			Error:     i%13 == 0,
+			Count:     i % 300,
+		}
+	}
+	return events
+}
+
+func TestCompressRecords(t *testing.T) {
+	events := testEvents(2000)
+	for i := range events {
+		events[i].Raw = []byte("not stored")
+		events[i].note = "not stored either"
+	}
+
+	compressed, err := CompressRecords(events)
+	if err != nil {
+		t.Fatalf("CompressRecords() failed: %v", err)
+	}
+	got, err := DecompressRecords[testEvent](compressed)
+	if err != nil {
+		t.Fatalf("DecompressRecords() failed: %v", err)
+	}
+
+	want := testEvents(2000)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip mismatch, first record %+v, want %+v", got[0], want[0])
+	}
+	t.Logf("%d records -> %d bytes", len(events), len(compressed))
+}
+
+func TestCompressRecords_Invalid(t *testing.T) {
+	type unsupported struct {
+		Tags []string
+	}
+	type badWidth struct {
+		Value float64 `openzl:"width=4"`
+	}
+	type badOption struct {
+		Value int `openzl:"compress"`
+	}
+	type small struct {
+		Value int32 `openzl:"width=1"`
+	}
+
+	tests := []struct {
+		name string
+		fn   func() error
+	}{
+		{"not a struct", func() error { _, err := CompressRecords([]int{1}); return err }},
+		{"unsupported field", func() error { _, err := CompressRecords([]unsupported{{}}); return err }},
+		{"width on float", func() error { _, err := CompressRecords([]badWidth{{}}); return err }},
+		{"unknown option", func() error { _, err := CompressRecords([]badOption{{}}); return err }},
+		{"value too wide", func() error { _, err := CompressRecords([]small{{Value: 300}}); return err }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.fn(); !errors.Is(err, ErrInvalidParameter) {
+				t.Errorf("error = %v, want ErrInvalidParameter", err)
+			}
+		})
+	}
+
+	if _, err := CompressRecords([]testEvent(nil)); !errors.Is(err, ErrEmptyInput) {
+		t.Errorf("empty input error = %v, want ErrEmptyInput", err)
+	}
+}
+
+func TestDecompressRecords_SchemaMismatch(t *testing.T) {
+	type v1 struct {
+		ID   int64
+		Name string
+	}
+	type reordered struct {
+		Name string
+		ID   int64
+	}
+	type renamed struct {
+		ID    int64
+		Label string
+	}
+
+	compressed, err := CompressRecords([]v1{{1, "a"}, {2, "b"}})
+	if err != nil {
+		t.Fatalf("CompressRecords() failed: %v", err)
+	}
+
+	got, err := DecompressRecords[reordered](compressed)
+	if err != nil {
+		t.Fatalf("DecompressRecords() with reordered fields failed: %v", err)
+	}
+	if fmt.Sprint(got) != "[{a 1} {b 2}]" {
+		t.Errorf("reordered records = %v", got)
+	}
+
+	if _, err := DecompressRecords[renamed](compressed); !errors.Is(err, ErrCorruptedData) {
+		t.Errorf("renamed field error = %v, want ErrCorruptedData", err)
+	}
+}
+
+func TestDecompressRecords_Corrupt(t *testing.T) {
+	// A column name length near MaxUint64 must not wrap the bounds check
+	schema := binary.AppendUvarint(nil, 1)
+	schema = binary.AppendUvarint(schema, 1)
+	schema = binary.AppendUvarint(schema, math.MaxUint64)
+	schema = append(schema, "ID"...)
+	compressed := encodeContainer(containerRecords, schema, nil)
+
+	if _, err := NewRecordDecoder(compressed); !errors.Is(err, ErrCorruptedData) {
+		t.Errorf("NewRecordDecoder() error = %v, want ErrCorruptedData", err)
+	}
+	if _, err := DecompressRecords[testEvent](compressed); !errors.Is(err, ErrCorruptedData) {
+		t.Errorf("DecompressRecords() error = %v, want ErrCorruptedData", err)
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"io"
+	"runtime"
+	"sync"
+)
+
+// ParallelScan reads a record stream from r and decodes its chunks on
+// parallelism worker goroutines, calling fn with the records of each chunk.
+//
+// A chunk is one batch of a stream written by RecordWriter, so the batch size
+// given to the writer sets the unit of parallel work. Input is read by the
+// calling goroutine while workers decode earlier chunks, which keeps all
+// cores busy on analytics jobs that scan a large compressed dataset. A single
+// CompressRecords container is accepted too, but forms a single chunk.
+//
+// fn is called concurrently from several goroutines and in no particular
+// order; it owns the chunk it is given. The scan stops at the first error,
+// from reading, decoding, or fn, and ParallelScan returns it after all
+// workers have stopped. If parallelism is zero or negative,
+// runtime.GOMAXPROCS(0) workers are used.
+//
+// Example:
+//
+//	var total atomic.Int64
+//	err := openzl.ParallelScan(file, func(events []Event) error {
+//		for _, ev := range events {
+//			total.Add(ev.Bytes)
+//		}
+//		return nil
+//	}, 0)
+//
+// Returns ErrInvalidParameter if T is not a valid record type.
+func ParallelScan[T any](r io.Reader, fn func(chunk []T) error, parallelism int) error {
+	if parallelism <= 0 {
+		parallelism = runtime.GOMAXPROCS(0)
+	}
+	rr, err := NewRecordReader[T](r)
+	if err != nil {
+		return err
+	}
+
+	var (
+		once     sync.Once
+		firstErr error
+		done     = make(chan struct{})
+	)
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			close(done)
+		})
+	}
+
+	// Queue one chunk per worker so reading stays ahead of decoding without
+	// buffering an unbounded part of the input
+	chunks := make(chan []byte, parallelism)
+	var wg sync.WaitGroup
+	for range parallelism {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for data := range chunks {
+				select {
+				case <-done:
+					continue
+				default:
+				}
+				records, err := decodeRecords[T](data, rr.fields)
+				if err == nil {
+					err = fn(records)
+				}
+				if err != nil {
+					fail(err)
+				}
+			}
+		}()
+	}
+
+read:
+	for {
+		data, err := rr.readContainer()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			fail(err)
+			break
+		}
+		select {
+		case chunks <- data:
+		case <-done:
+			break read
+		}
+	}
+	close(chunks)
+	wg.Wait()
+	return firstErr
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestParallelScan(t *testing.T) {
+	events := testEvents(1000)
+	stream := writeRecordStream(t, events, 64)
+
+	for _, parallelism := range []int{0, 1, 4} {
+		var (
+			mu     sync.Mutex
+			got    []testEvent
+			chunks int
+		)
+		err := ParallelScan(bytes.NewReader(stream), func(chunk []testEvent) error {
+			mu.Lock()
+			defer mu.Unlock()
+			got = append(got, chunk...)
+			chunks++
+			return nil
+		}, parallelism)
+		if err != nil {
+			t.Fatalf("ParallelScan(parallelism=%d) failed: %v", parallelism, err)
+		}
+		if want := (len(events) + 63) / 64; chunks != want {
+			t.Errorf("parallelism=%d: got %d chunks, want %d", parallelism, chunks, want)
+		}
+
+		// Chunks arrive in any order
+		slices.SortFunc(got, func(a, b testEvent) int { return int(a.Timestamp - b.Timestamp) })
+		if !reflect.DeepEqual(got, events) {
+			t.Errorf("parallelism=%d: scanned records do not match", parallelism)
+		}
+	}
+}
+
+func TestParallelScan_Errors(t *testing.T) {
+	stream := writeRecordStream(t, testEvents(1000), 16)
+
+	// The first callback error stops the scan
+	errStop := errors.New("stop")
+	var (
+		mu    sync.Mutex
+		calls int
+	)
+	err := ParallelScan(bytes.NewReader(stream), func([]testEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		return errStop
+	}, 2)
+	if !errors.Is(err, errStop) {
+		t.Errorf("ParallelScan() = %v, want the callback error", err)
+	}
+	if calls > 2 {
+		t.Errorf("callback ran %d times after failing, want at most one call per worker", calls)
+	}
+
+	// Read errors are returned
+	err = ParallelScan(bytes.NewReader(stream[:len(stream)-5]), func([]testEvent) error { return nil }, 2)
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("ParallelScan() on truncated stream = %v, want io.ErrUnexpectedEOF", err)
+	}
+
+	err = ParallelScan(bytes.NewReader(stream), func([]int) error { return nil }, 2)
+	if !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("ParallelScan() with non-struct type = %v, want ErrInvalidParameter", err)
+	}
+}
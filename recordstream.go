@@ -0,0 +1,426 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"math"
+	"reflect"
+	"slices"
+
+	"github.com/borischu/go-openzl/internal/fault"
+)
+
+// Record streams split records into batches so that neither side has to hold
+// the whole dataset:
+//
+//	+--------------+----------------------------------------------------------+
+//	| magic "ZLRS" | (len uvarint, stats, len uvarint, container) until EOF   |
+//	+--------------+----------------------------------------------------------+
+//
+// Each batch is a record container as produced by CompressRecords, preceded
+// by the value ranges of its numeric columns (see recordStat).
+const recordStreamMagic = "ZLRS"
+
+const (
+	// DefaultRecordBatchSize is the number of records per batch written by
+	// RecordWriter unless WithRecordBatchSize is given.
+	DefaultRecordBatchSize = 64 * 1024
+
+	// maxRecordBatchBytes bounds the compressed size of one batch.
+	maxRecordBatchBytes = 1 << 30
+)
+
+// RecordWriter compresses records into a record stream in batches.
+//
+// Records are buffered until a batch is full and then compressed column by
+// column like CompressRecords, so memory use is bounded by the batch size
+// rather than the dataset. Close must be called to write the final batch.
+// Errors are sticky: after a failed write, every later call returns the same
+// error.
+//
+// Example:
+//
+//	w, err := openzl.NewRecordWriter[Event](file)
+//	...
+//	for ev := range events {
+//		if err := w.Write(ev); err != nil {
+//			return err
+//		}
+//	}
+//	return w.Close()
+type RecordWriter[T any] struct {
+	w         io.Writer
+	fields    []recordField
+	batch     []T
+	batchSize int
+	started   bool
+	closed    bool
+	err       error
+}
+
+// RecordWriterOption configures a RecordWriter.
+type RecordWriterOption func(*recordWriterConfig) error
+
+// recordWriterConfig holds the options of a RecordWriter, which cannot be
+// generic.
+type recordWriterConfig struct {
+	batchSize int
+}
+
+// WithRecordBatchSize sets the number of records per batch. Larger batches
+// compress better; smaller batches bound the memory of both the writer and
+// RecordReader. The default is DefaultRecordBatchSize.
+func WithRecordBatchSize(n int) RecordWriterOption {
+	return func(c *recordWriterConfig) error {
+		if n <= 0 {
+			return rangeError("WithRecordBatchSize", n, 1, math.MaxInt64)
+		}
+		c.batchSize = n
+		return nil
+	}
+}
+
+// NewRecordWriter creates a RecordWriter that writes a record stream to w.
+//
+// Returns ErrInvalidParameter if T is not a valid record type (see
+// CompressRecords) or an option is invalid.
+func NewRecordWriter[T any](w io.Writer, opts ...RecordWriterOption) (*RecordWriter[T], error) {
+	cfg := recordWriterConfig{batchSize: DefaultRecordBatchSize}
+	for _, opt := range opts {
+		if err := opt(&cfg); err != nil {
+			return nil, fmt.Errorf("apply option: %w", err)
+		}
+	}
+	fields, err := recordFieldsOf(reflect.TypeFor[T]())
+	if err != nil {
+		return nil, err
+	}
+	return &RecordWriter[T]{w: w, fields: fields, batchSize: cfg.batchSize}, nil
+}
+
+// Write adds records to the stream, compressing and writing each batch as it
+// fills up.
+func (w *RecordWriter[T]) Write(records ...T) error {
+	if w.closed {
+		return fmt.Errorf("write to closed RecordWriter")
+	}
+	for len(records) > 0 && w.err == nil {
+		n := min(len(records), w.batchSize-len(w.batch))
+		w.batch = append(w.batch, records[:n]...)
+		records = records[n:]
+		if len(w.batch) == w.batchSize {
+			w.err = w.flush()
+		}
+	}
+	return w.err
+}
+
+// Flush compresses and writes the buffered records as a batch, if any.
+func (w *RecordWriter[T]) Flush() error {
+	if w.closed {
+		return fmt.Errorf("flush closed RecordWriter")
+	}
+	if w.err == nil {
+		w.err = w.flush()
+	}
+	return w.err
+}
+
+// flush writes the stream header, if not yet written, and the buffered batch.
+func (w *RecordWriter[T]) flush() error {
+	if !w.started {
+		if err := writeFull(w.w, []byte(recordStreamMagic)); err != nil {
+			return fmt.Errorf("write header: %w", err)
+		}
+		w.started = true
+	}
+	if len(w.batch) == 0 {
+		return nil
+	}
+
+	container, err := CompressRecords(w.batch)
+	if err != nil {
+		return err
+	}
+	stats := appendStats(nil, batchStats(w.batch, w.fields))
+	clear(w.batch)
+	w.batch = w.batch[:0]
+
+	frame := make([]byte, 0, 2*binary.MaxVarintLen64+len(stats)+len(container))
+	frame = binary.AppendUvarint(frame, uint64(len(stats)))
+	frame = append(frame, stats...)
+	frame = binary.AppendUvarint(frame, uint64(len(container)))
+	if err := writeFull(w.w, append(frame, container...)); err != nil {
+		return fmt.Errorf("write batch: %w", err)
+	}
+	return nil
+}
+
+// Close writes the remaining records. It does not close the underlying
+// writer. Closing a stream with no records still writes a valid empty stream.
+func (w *RecordWriter[T]) Close() error {
+	if w.closed {
+		return w.err
+	}
+	if w.err == nil {
+		w.err = w.flush()
+	}
+	w.closed = true
+	return w.err
+}
+
+// RecordReader decodes records one at a time from a record stream written by
+// RecordWriter, or from a single CompressRecords container.
+//
+// Only one batch is decoded at a time, and records are assembled from its
+// columns as they are returned, so large datasets can be scanned without
+// materializing all rows. Each call to Next returns a new value; T must store
+// the same columns as the type the records were written from (see
+// DecompressRecords).
+//
+// Example:
+//
+//	r, err := openzl.NewRecordReader[Event](file)
+//	...
+//	for ev := range r.All() {
+//		process(ev)
+//	}
+//	if err := r.Err(); err != nil {
+//		return err
+//	}
+type RecordReader[T any] struct {
+	r         *bufio.Reader
+	fields    []recordField
+	columns   *recordColumns // Current batch
+	row       int            // Next row of the current batch
+	started   bool
+	single    bool                   // The input is a single container
+	projected bool                   // Only some columns are decoded (ReadColumns)
+	skip      func(recordStats) bool // Reports batches to skip undecoded (ScanWhere)
+	err       error
+}
+
+// NewRecordReader creates a RecordReader that reads records from r. The input
+// format is detected when the first record is read.
+//
+// Returns ErrInvalidParameter if T is not a valid record type.
+func NewRecordReader[T any](r io.Reader) (*RecordReader[T], error) {
+	fields, err := recordFieldsOf(reflect.TypeFor[T]())
+	if err != nil {
+		return nil, err
+	}
+	return &RecordReader[T]{r: bufio.NewReader(fault.Reader(r)), fields: fields}, nil
+}
+
+// ReadColumns restricts decoding to the named columns, which are the names of
+// fields of T. The compressed data of the other columns is skipped without
+// being decompressed, and their fields are left at their zero value, so
+// queries that need a few columns of a wide record pay only for those.
+//
+// ReadColumns must be called before the first record is read. It returns
+// ErrInvalidParameter if a name is not a stored field of T.
+//
+// Example:
+//
+//	r, err := openzl.NewRecordReader[Event](file)
+//	...
+//	if err := r.ReadColumns("Timestamp", "Latency"); err != nil {
+//		return err
+//	}
+func (r *RecordReader[T]) ReadColumns(names ...string) error {
+	if r.started {
+		return fmt.Errorf("%w: ReadColumns called after reading started", ErrInvalidParameter)
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("%w: no columns selected", ErrInvalidParameter)
+	}
+
+	all, err := recordFieldsOf(reflect.TypeFor[T]())
+	if err != nil {
+		return err
+	}
+	var fields []recordField
+	for _, name := range names {
+		i := slices.IndexFunc(all, func(f recordField) bool { return f.name == name })
+		if i < 0 {
+			return fmt.Errorf("%w: %v has no stored field %s", ErrInvalidParameter, reflect.TypeFor[T](), name)
+		}
+		if !slices.ContainsFunc(fields, func(f recordField) bool { return f.name == name }) {
+			fields = append(fields, all[i])
+		}
+	}
+	r.fields = fields
+	r.projected = true
+	return nil
+}
+
+// Next returns the next record. It returns io.EOF after the last record, and
+// ErrCorruptedData or io.ErrUnexpectedEOF if the stream is invalid or
+// truncated. Errors are sticky.
+func (r *RecordReader[T]) Next() (T, error) {
+	var rec T
+	for r.err == nil && (r.columns == nil || r.row == r.columns.rows) {
+		r.err = r.readBatch()
+	}
+	if r.err != nil {
+		return rec, r.err
+	}
+	if err := r.columns.set(reflect.ValueOf(&rec).Elem(), r.row); err != nil {
+		r.err = err
+		return rec, err
+	}
+	r.row++
+	return rec, nil
+}
+
+// All returns an iterator over the remaining records. Iteration stops at the
+// end of the stream or at the first error, which Err then reports.
+func (r *RecordReader[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			rec, err := r.Next()
+			if err != nil || !yield(rec) {
+				return
+			}
+		}
+	}
+}
+
+// Err returns the error that stopped iteration, or nil if the stream was read
+// to its end.
+func (r *RecordReader[T]) Err() error {
+	if r.err == io.EOF {
+		return nil
+	}
+	return r.err
+}
+
+// readBatch decodes the next batch, returning io.EOF at the end of input.
+func (r *RecordReader[T]) readBatch() error {
+	r.columns, r.row = nil, 0
+
+	data, err := r.readContainer()
+	if err != nil {
+		return err
+	}
+	columns, err := decodeRecordColumns(data, r.fields, r.projected)
+	if err != nil {
+		return err
+	}
+	r.columns = columns
+	return nil
+}
+
+// readContainer reads the record container of the next batch without
+// decoding it, returning io.EOF at the end of input.
+func (r *RecordReader[T]) readContainer() ([]byte, error) {
+	if !r.started {
+		r.started = true
+		magic, err := r.r.Peek(len(recordStreamMagic))
+		if err != nil {
+			if err == io.EOF && len(magic) == 0 {
+				return nil, io.ErrUnexpectedEOF
+			}
+			if err == io.EOF {
+				return nil, fmt.Errorf("%w: not a record stream", ErrCorruptedData)
+			}
+			return nil, fmt.Errorf("read header: %w", err)
+		}
+		switch string(magic) {
+		case recordStreamMagic:
+			r.r.Discard(len(magic))
+		case containerMagic:
+			r.single = true
+			data, err := io.ReadAll(r.r)
+			if err != nil {
+				return nil, fmt.Errorf("read records: %w", err)
+			}
+			return data, nil
+		default:
+			return nil, fmt.Errorf("%w: not a record stream", ErrCorruptedData)
+		}
+	}
+	if r.single {
+		return nil, io.EOF
+	}
+
+	for {
+		stats, err := r.readSection(maxRecordBatchBytes, true)
+		if err != nil {
+			return nil, err
+		}
+		size, err := r.readLength(maxRecordBatchBytes)
+		if err != nil {
+			return nil, err
+		}
+		if r.skip != nil {
+			s, err := parseStats(stats)
+			if err != nil {
+				return nil, err
+			}
+			if r.skip(s) {
+				if _, err := r.r.Discard(size); err != nil {
+					if err == io.EOF {
+						return nil, io.ErrUnexpectedEOF
+					}
+					return nil, fmt.Errorf("skip batch: %w", err)
+				}
+				continue
+			}
+		}
+		return r.readBytes(size)
+	}
+}
+
+// readLength reads the uvarint length of a section of the stream, which must
+// be positive and at most limit.
+func (r *RecordReader[T]) readLength(limit int) (int, error) {
+	size, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return 0, io.ErrUnexpectedEOF
+		}
+		return 0, fmt.Errorf("read batch length: %w", err)
+	}
+	if size == 0 || size > uint64(limit) {
+		return 0, fmt.Errorf("%w: invalid batch length %d", ErrCorruptedData, size)
+	}
+	return int(size), nil
+}
+
+// readSection reads a length-prefixed section. At a batch boundary, given by
+// first, the end of input is io.EOF rather than a truncation.
+func (r *RecordReader[T]) readSection(limit int, first bool) ([]byte, error) {
+	if first {
+		if _, err := r.r.Peek(1); err == io.EOF {
+			return nil, io.EOF
+		}
+	}
+	size, err := r.readLength(limit)
+	if err != nil {
+		return nil, err
+	}
+	return r.readBytes(size)
+}
+
+// readBytes reads the next size bytes of the stream.
+func (r *RecordReader[T]) readBytes(size int) ([]byte, error) {
+	// Grow the buffer as data arrives, so a corrupted length cannot force a
+	// huge allocation
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, r.r, int64(size)); err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("read batch: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,221 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"testing/iotest"
+)
+
+// writeRecordStream writes events as a record stream with the given batch size.
+func writeRecordStream(t *testing.T, events []testEvent, batchSize int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewRecordWriter[testEvent](&buf, WithRecordBatchSize(batchSize))
+	if err != nil {
+		t.Fatalf("NewRecordWriter() failed: %v", err)
+	}
+	// Write in uneven chunks to cross batch boundaries
+	for i := 0; i < len(events); i += 7 {
+		if err := w.Write(events[i:min(i+7, len(events))]...); err != nil {
+			t.Fatalf("Write() failed: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestRecordReader_Next(t *testing.T) {
+	events := testEvents(1000)
+	stream := writeRecordStream(t, events, 128)
+
+	r, err := NewRecordReader[testEvent](iotest.OneByteReader(bytes.NewReader(stream)))
+	if err != nil {
+		t.Fatalf("NewRecordReader() failed: %v", err)
+	}
+	var got []testEvent
+	for {
+		ev, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next() failed after %d records: %v", len(got), err)
+		}
+		got = append(got, ev)
+	}
+	if !reflect.DeepEqual(got, events) {
+		t.Error("records do not round-trip through a record stream")
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("Next() after end = %v, want io.EOF", err)
+	}
+}
+
+func TestRecordReader_All(t *testing.T) {
+	events := testEvents(300)
+
+	// A single CompressRecords container is read the same way
+	compressed, err := CompressRecords(events)
+	if err != nil {
+		t.Fatalf("CompressRecords() failed: %v", err)
+	}
+	for name, input := range map[string][]byte{
+		"stream":    writeRecordStream(t, events, 64),
+		"container": compressed,
+	} {
+		t.Run(name, func(t *testing.T) {
+			r, err := NewRecordReader[testEvent](bytes.NewReader(input))
+			if err != nil {
+				t.Fatalf("NewRecordReader() failed: %v", err)
+			}
+			var got []testEvent
+			for ev := range r.All() {
+				got = append(got, ev)
+			}
+			if err := r.Err(); err != nil {
+				t.Fatalf("Err() = %v", err)
+			}
+			if !reflect.DeepEqual(got, events) {
+				t.Error("records do not round-trip")
+			}
+		})
+	}
+}
+
+func TestRecordReader_EmptyStream(t *testing.T) {
+	stream := writeRecordStream(t, nil, 16)
+	r, err := NewRecordReader[testEvent](bytes.NewReader(stream))
+	if err != nil {
+		t.Fatalf("NewRecordReader() failed: %v", err)
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("Next() on empty stream = %v, want io.EOF", err)
+	}
+}
+
+func TestRecordReader_Invalid(t *testing.T) {
+	stream := writeRecordStream(t, testEvents(100), 32)
+
+	tests := []struct {
+		name  string
+		input []byte
+		want  error
+	}{
+		{"empty", nil, io.ErrUnexpectedEOF},
+		{"not a stream", []byte("hello world"), ErrCorruptedData},
+		{"truncated batch", stream[:len(stream)-10], io.ErrUnexpectedEOF},
+		{"zero batch length", append([]byte(recordStreamMagic), 0), ErrCorruptedData},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewRecordReader[testEvent](bytes.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("NewRecordReader() failed: %v", err)
+			}
+			for range r.All() {
+			}
+			if !errors.Is(r.Err(), tt.want) {
+				t.Errorf("Err() = %v, want %v", r.Err(), tt.want)
+			}
+		})
+	}
+
+	// A record type with different columns is rejected
+	type other struct{ Name string }
+	r, err := NewRecordReader[other](bytes.NewReader(stream))
+	if err != nil {
+		t.Fatalf("NewRecordReader() failed: %v", err)
+	}
+	if _, err := r.Next(); !errors.Is(err, ErrCorruptedData) {
+		t.Errorf("Next() with mismatched type = %v, want ErrCorruptedData", err)
+	}
+}
+
+func TestRecordWriter_Errors(t *testing.T) {
+	if _, err := NewRecordWriter[testEvent](io.Discard, WithRecordBatchSize(0)); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("zero batch size error = %v, want ErrInvalidParameter", err)
+	}
+	if _, err := NewRecordWriter[int](io.Discard); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("non-struct record error = %v, want ErrInvalidParameter", err)
+	}
+
+	// Write errors are sticky
+	fw := &failingWriter{failAfter: 0}
+	w, err := NewRecordWriter[testEvent](fw, WithRecordBatchSize(4))
+	if err != nil {
+		t.Fatalf("NewRecordWriter() failed: %v", err)
+	}
+	if err := w.Write(testEvents(4)...); err == nil {
+		t.Fatal("Write() to failing writer succeeded")
+	}
+	if err := w.Close(); err == nil {
+		t.Error("Close() after failed write succeeded")
+	}
+	if err := w.Write(testEvents(1)...); err == nil {
+		t.Error("Write() after Close succeeded")
+	}
+}
+
+func TestRecordReader_ReadColumns(t *testing.T) {
+	events := testEvents(200)
+	stream := writeRecordStream(t, events, 50)
+
+	r, err := NewRecordReader[testEvent](bytes.NewReader(stream))
+	if err != nil {
+		t.Fatalf("NewRecordReader() failed: %v", err)
+	}
+	if err := r.ReadColumns("Timestamp", "Latency", "Timestamp"); err != nil {
+		t.Fatalf("ReadColumns() failed: %v", err)
+	}
+	var got []testEvent
+	for ev := range r.All() {
+		got = append(got, ev)
+	}
+	if err := r.Err(); err != nil {
+		t.Fatalf("Err() = %v", err)
+	}
+	if len(got) != len(events) {
+		t.Fatalf("got %d records, want %d", len(got), len(events))
+	}
+	for i, ev := range got {
+		want := testEvent{Timestamp: events[i].Timestamp, Latency: events[i].Latency}
+		if !reflect.DeepEqual(ev, want) {
+			t.Fatalf("record %d = %+v, want only the projected columns %+v", i, ev, want)
+		}
+	}
+	if err := r.ReadColumns("Kind"); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("ReadColumns() after reading = %v, want ErrInvalidParameter", err)
+	}
+
+	// Unknown and skipped fields cannot be selected
+	for _, names := range [][]string{{"Missing"}, {"Raw"}, {"note"}, nil} {
+		r, _ := NewRecordReader[testEvent](bytes.NewReader(stream))
+		if err := r.ReadColumns(names...); !errors.Is(err, ErrInvalidParameter) {
+			t.Errorf("ReadColumns(%q) = %v, want ErrInvalidParameter", names, err)
+		}
+	}
+
+	// A projected column must still match the stored type
+	type narrow struct {
+		Timestamp int32
+		Kind      string
+	}
+	nr, err := NewRecordReader[narrow](bytes.NewReader(stream))
+	if err != nil {
+		t.Fatalf("NewRecordReader() failed: %v", err)
+	}
+	if err := nr.ReadColumns("Timestamp"); err != nil {
+		t.Fatalf("ReadColumns() failed: %v", err)
+	}
+	if _, err := nr.Next(); !errors.Is(err, ErrCorruptedData) {
+		t.Errorf("Next() with mismatched column = %v, want ErrCorruptedData", err)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+// StageReport describes one codec invocation in the compression graph.
+type StageReport struct {
+	// Name is the codec name, e.g. "delta_int" or "huffman".
+	Name string
+
+	// InputSize is the number of bytes the codec consumed.
+	InputSize int
+
+	// OutputSize is the number of bytes the codec produced.
+	OutputSize int
+}
+
+// Ratio returns InputSize / OutputSize for this stage.
+//
+// Values above 1 mean the stage reduced the data; a ratio of 0 is returned
+// when the stage produced no output.
+func (s StageReport) Ratio() float64 {
+	if s.OutputSize == 0 {
+		return 0
+	}
+	return float64(s.InputSize) / float64(s.OutputSize)
+}
+
+// Report summarizes the most recent compression performed by a Compressor.
+type Report struct {
+	// InputSize is the uncompressed size of the input.
+	InputSize int
+
+	// OutputSize is the size of the compressed frame, including headers.
+	OutputSize int
+
+	// Stages lists the codecs that ran, in execution order.
+	Stages []StageReport
+}
+
+// LastReport returns the report for the most recent compression.
+//
+// Stages are only recorded when the Compressor was created with
+// WithStageReport(true); otherwise only InputSize and OutputSize are set.
+// The returned report is a copy and safe to retain.
+//
+// Example:
+//
+//	compressor, _ := openzl.NewCompressor(openzl.WithStageReport(true))
+//	compressor.Compress(data)
+//	for _, s := range compressor.LastReport().Stages {
+//		fmt.Printf("%-16s %8d -> %8d\n", s.Name, s.InputSize, s.OutputSize)
+//	}
+func (c *Compressor) LastReport() Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	r := c.report
+	r.Stages = append([]StageReport(nil), c.report.Stages...)
+	return r
+}
+
+// recordReport captures the report for a finished compression.
+// The caller must hold c.mu.
+func (c *Compressor) recordReport(inputSize, outputSize int) {
+	c.report = Report{
+		InputSize:  inputSize,
+		OutputSize: outputSize,
+	}
+	if !c.cfg.stageReport {
+		return
+	}
+
+	stages := c.ctx.Report()
+	c.report.Stages = make([]StageReport, len(stages))
+	for i, s := range stages {
+		c.report.Stages[i] = StageReport{
+			Name:       s.Name,
+			InputSize:  s.InputSize,
+			OutputSize: s.OutputSize,
+		}
+	}
+}
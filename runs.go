@@ -0,0 +1,134 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"encoding/binary"
+	"fmt"
+	"unsafe"
+
+	"github.com/borischu/go-openzl/internal/cgo"
+)
+
+// runShortCircuitRatio is the minimum average run length for which numeric
+// data is stored as runs instead of being compressed by OpenZL.
+const runShortCircuitRatio = 16
+
+// maxRunSize bounds the bytes a run frame may expand to, whatever its
+// element width, so that a frame of a few bytes cannot claim gigabytes of
+// output. Larger inputs are compressed by OpenZL instead.
+const maxRunSize = 1 << 30
+
+// WithRunShortCircuit lets CompressorCompressNumeric skip OpenZL entirely for
+// constant and run-length-dominated inputs.
+//
+// All-zero and single-value columns are extremely common, and running the
+// full compression graph on them costs far more CPU than the trivial result
+// warrants. With this option, inputs whose runs of identical values average
+// at least 16 elements are stored as a list of (value, count) pairs instead.
+// The result is smaller or comparable and produced at memory speed.
+//
+// Run frames are a go-openzl container rather than an OpenZL frame. Both
+// DecompressNumeric and DecompressorDecompressNumeric read them
+// transparently, but other OpenZL implementations cannot, so the option is
+// disabled by default.
+func WithRunShortCircuit(enabled bool) CompressorOption {
+	return func(cfg *config) error {
+		cfg.runShortCircuit = enabled
+		return nil
+	}
+}
+
+// encodeRuns stores data as runs if it is run-length dominated. It returns
+// false, without allocating the output, if the data has too many runs.
+func encodeRuns[T Numeric](data []T) ([]byte, bool) {
+	width := int(unsafe.Sizeof(data[0]))
+	if len(data) > maxRunSize/width {
+		return nil, false
+	}
+	raw := unsafe.Slice((*byte)(unsafe.Pointer(&data[0])), len(data)*width)
+	maxRuns := len(data) / runShortCircuitRatio
+	if maxRuns == 0 {
+		return nil, false
+	}
+
+	// Compare raw bits so that NaN payloads and signed zeros are preserved
+	var values []byte
+	var counts []byte
+	runs := 0
+	for start := 0; start < len(data); {
+		v := raw[start*width : (start+1)*width]
+		end := start + 1
+		for end < len(data) && string(raw[end*width:(end+1)*width]) == string(v) {
+			end++
+		}
+		runs++
+		if runs > maxRuns {
+			return nil, false
+		}
+		values = append(values, v...)
+		counts = binary.AppendUvarint(counts, uint64(end-start))
+		start = end
+	}
+
+	meta := []byte{byte(width)}
+	return encodeContainer(containerRuns, meta, values, counts), true
+}
+
+// isRunFrame reports whether b was produced by encodeRuns.
+func isRunFrame(b []byte) bool {
+	return len(b) > len(containerMagic) &&
+		string(b[:len(containerMagic)]) == containerMagic &&
+		containerKind(b[len(containerMagic)]) == containerRuns
+}
+
+// decodeRuns expands a run frame produced by encodeRuns. If checkSize is not
+// nil, it is called with the size of the output, computed from the run
+// counts, before the output is allocated.
+func decodeRuns[T Numeric](b []byte, checkSize func(int) error) ([]T, error) {
+	sections, err := decodeContainer(b, containerRuns, 3)
+	if err != nil {
+		return nil, err
+	}
+	var zero T
+	width := int(unsafe.Sizeof(zero))
+	if len(sections[0]) != 1 || int(sections[0][0]) != width {
+		return nil, fmt.Errorf("%w: run frame element size does not match %T", ErrTypeMismatch, zero)
+	}
+
+	// Copy to an aligned buffer before viewing it as []T
+	values, err := cgo.BytesToTypedSlice[T](append([]byte(nil), sections[1]...))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorruptedData, err)
+	}
+
+	// Sum the counts first so that corrupt input cannot grow data unbounded
+	counts := make([]int, len(values))
+	rest, total := sections[2], 0
+	for i := range counts {
+		n, k := binary.Uvarint(rest)
+		if k <= 0 || n == 0 || n > uint64(maxRunSize/width-total) {
+			return nil, fmt.Errorf("%w: invalid run length", ErrCorruptedData)
+		}
+		rest = rest[k:]
+		counts[i] = int(n)
+		total += int(n)
+	}
+	if len(rest) != 0 || total == 0 {
+		return nil, fmt.Errorf("%w: run counts do not match values", ErrCorruptedData)
+	}
+	if checkSize != nil {
+		if err := checkSize(total * width); err != nil {
+			return nil, err
+		}
+	}
+
+	data := make([]T, 0, total)
+	for i, v := range values {
+		for range counts[i] {
+			data = append(data, v)
+		}
+	}
+	return data, nil
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestRunShortCircuit(t *testing.T) {
+	compressor, err := NewCompressor(WithRunShortCircuit(true))
+	if err != nil {
+		t.Fatalf("NewCompressor() failed: %v", err)
+	}
+	defer compressor.Close()
+
+	constant := make([]int64, 1000)
+	runs := make([]int64, 1000)
+	for i := range runs {
+		runs[i] = int64(i / 100)
+	}
+	varied := make([]int64, 1000)
+	for i := range varied {
+		varied[i] = int64(i * 7919 % 1000)
+	}
+
+	tests := []struct {
+		name    string
+		data    []int64
+		wantRun bool
+	}{
+		{"constant", constant, true},
+		{"long runs", runs, true},
+		{"varied", varied, false},
+		{"too short", []int64{1, 1, 1}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compressed, err := CompressorCompressNumeric(compressor, tt.data)
+			if err != nil {
+				t.Fatalf("CompressorCompressNumeric() failed: %v", err)
+			}
+			if got := isRunFrame(compressed); got != tt.wantRun {
+				t.Errorf("isRunFrame() = %v, want %v", got, tt.wantRun)
+			}
+
+			decompressed, err := DecompressNumeric[int64](compressed)
+			if err != nil {
+				t.Fatalf("DecompressNumeric() failed: %v", err)
+			}
+			if len(decompressed) != len(tt.data) {
+				t.Fatalf("length mismatch: got %d, want %d", len(decompressed), len(tt.data))
+			}
+			for i := range tt.data {
+				if decompressed[i] != tt.data[i] {
+					t.Fatalf("mismatch at index %d: got %d, want %d", i, decompressed[i], tt.data[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRunShortCircuit_FloatBits(t *testing.T) {
+	// Runs are detected on raw bits, so -0 and NaN payloads survive
+	data := make([]float64, 64)
+	for i := range data {
+		if i < 32 {
+			data[i] = math.Copysign(0, -1)
+		} else {
+			data[i] = math.NaN()
+		}
+	}
+
+	frame, ok := encodeRuns(data)
+	if !ok {
+		t.Fatal("encodeRuns() did not short-circuit")
+	}
+	decoded, err := decodeRuns[float64](frame, nil)
+	if err != nil {
+		t.Fatalf("decodeRuns() failed: %v", err)
+	}
+	for i := range data {
+		if math.Float64bits(decoded[i]) != math.Float64bits(data[i]) {
+			t.Fatalf("bits mismatch at index %d", i)
+		}
+	}
+
+	// The element size is checked against the requested type
+	if _, err := decodeRuns[float32](frame, nil); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("decodeRuns[float32]() error = %v, want ErrTypeMismatch", err)
+	}
+}
+
+func TestRunShortCircuit_Accounting(t *testing.T) {
+	compressor, err := NewCompressor(WithRunShortCircuit(true), WithLatencyHistogram(true))
+	if err != nil {
+		t.Fatalf("NewCompressor() failed: %v", err)
+	}
+	defer compressor.Close()
+
+	data := make([]int64, 4096)
+	frame, err := CompressorCompressNumeric(compressor, data)
+	if err != nil || !isRunFrame(frame) {
+		t.Fatalf("CompressorCompressNumeric() = run frame %v, %v", isRunFrame(frame), err)
+	}
+	if r := compressor.LastReport(); r.InputSize != len(data)*8 || r.OutputSize != len(frame) {
+		t.Errorf("LastReport() = %+v, want %d -> %d bytes", r, len(data)*8, len(frame))
+	}
+	if n := compressor.Stats().Latency.Count; n != 1 {
+		t.Errorf("Stats().Latency.Count = %d, want 1", n)
+	}
+
+	// The memory budget applies to the expanded runs
+	d, err := NewDecompressor(WithMemoryBudget(int64(len(data))*8), WithDecompressLatencyHistogram(true))
+	if err != nil {
+		t.Fatalf("NewDecompressor() failed: %v", err)
+	}
+	defer d.Close()
+	if _, err := DecompressorDecompressNumeric[int64](d, frame); !errors.Is(err, ErrMemoryBudgetExceeded) {
+		t.Errorf("DecompressorDecompressNumeric() over budget error = %v, want ErrMemoryBudgetExceeded", err)
+	}
+	if n := d.Stats().Latency.Count; n != 1 {
+		t.Errorf("Decompressor Stats().Latency.Count = %d, want 1", n)
+	}
+
+	// A tiny frame claiming more than maxRunSize of output is rejected
+	huge := encodeContainer(containerRuns, []byte{8}, make([]byte, 8), binary.AppendUvarint(nil, maxRunSize/8+1))
+	if _, err := DecompressNumeric[int64](huge); !errors.Is(err, ErrCorruptedData) {
+		t.Errorf("DecompressNumeric(oversized runs) error = %v, want ErrCorruptedData", err)
+	}
+}
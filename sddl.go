@@ -0,0 +1,70 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/borischu/go-openzl/internal/cgo"
+)
+
+// NewSDDLGraph builds a custom graph for Compressor from a compiled
+// description of a binary record format, written in OpenZL's Simple Data
+// Description Language. The graph splits its input into the fields the
+// description declares and compresses each field separately, which is how
+// OpenZL compresses structured records far better than a general-purpose
+// codec without any C code for the format.
+//
+// compiled is the output of the upstream SDDL compiler (for example
+// "zli sddl compile"), typically loaded from a file checked in next to the
+// source description. OpenZL's C library only accepts descriptions in this
+// form; see CompileSDDL.
+//
+// The graph is reusable and may be shared by any number of Compressors, see
+// WithGraph. It applies to Compress and the other single-input methods; the
+// frames it produces decompress with Decompress as usual.
+//
+// Example:
+//
+//	compiled, err := os.ReadFile("trades.sddl.bin")
+//	...
+//	graph, err := openzl.NewSDDLGraph(compiled)
+//	if err != nil {
+//		return err
+//	}
+//	compressor, err := openzl.NewCompressor(openzl.WithGraph(graph))
+//	...
+//	compressed, err := compressor.Compress(records)
+//
+// Returns an error wrapping errors.ErrUnsupported if the linked library was
+// built without SDDL (Features().SDDL is false), ErrInvalidParameter if
+// compiled is empty, or an error if OpenZL rejects the description.
+func NewSDDLGraph(compiled []byte) (*Graph, error) {
+	if len(compiled) == 0 {
+		return nil, fmt.Errorf("%w: empty SDDL description", ErrInvalidParameter)
+	}
+	if !Features().SDDL {
+		return nil, fmt.Errorf("SDDL: %w", errors.ErrUnsupported)
+	}
+	g, err := cgo.NewSDDLGraph(compiled)
+	if err != nil {
+		return nil, fmt.Errorf("load SDDL: %w", err)
+	}
+	return &Graph{graph: g, desc: "sddl -> generic"}, nil
+}
+
+// CompileSDDL would compile the source text of an SDDL description into a
+// graph. The SDDL compiler is not part of OpenZL's C library, so it is not
+// supported: compile descriptions with the upstream tools ahead of time and
+// load the result with NewSDDLGraph.
+//
+// Returns ErrInvalidParameter if source is empty, and otherwise an error
+// wrapping errors.ErrUnsupported.
+func CompileSDDL(source string) (*Graph, error) {
+	if source == "" {
+		return nil, fmt.Errorf("%w: empty SDDL description", ErrInvalidParameter)
+	}
+	return nil, fmt.Errorf("compile SDDL: %w; use NewSDDLGraph with a compiled description", errors.ErrUnsupported)
+}
@@ -0,0 +1,210 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ShadowCriterion selects which of the two results of a ShadowCompressor is
+// kept.
+type ShadowCriterion int
+
+const (
+	// ShadowSmaller keeps the smaller frame. This is the default.
+	ShadowSmaller ShadowCriterion = iota
+
+	// ShadowFaster keeps the frame that took less time to compress.
+	ShadowFaster
+)
+
+// ShadowOption configures a ShadowCompressor.
+type ShadowOption func(*ShadowCompressor) error
+
+// WithShadowCriterion sets which result a ShadowCompressor keeps.
+func WithShadowCriterion(c ShadowCriterion) ShadowOption {
+	return func(s *ShadowCompressor) error {
+		if c != ShadowSmaller && c != ShadowFaster {
+			return &OptionError{Option: "WithShadowCriterion", Value: c, Allowed: "ShadowSmaller or ShadowFaster"}
+		}
+		s.criterion = c
+		return nil
+	}
+}
+
+// ShadowStats summarizes the payloads compressed by a ShadowCompressor.
+type ShadowStats struct {
+	// Payloads is the number of payloads compressed.
+	Payloads int64
+
+	// PrimaryWins and CandidateWins count the payloads whose kept result
+	// came from each configuration. Ties are kept from the primary and
+	// counted in Ties instead.
+	PrimaryWins, CandidateWins, Ties int64
+
+	// PrimaryErrors and CandidateErrors count failed compressions. A
+	// payload on which one configuration failed is won by the other.
+	PrimaryErrors, CandidateErrors int64
+
+	// InputBytes is the total size of the payloads.
+	InputBytes int64
+
+	// PrimaryBytes and CandidateBytes are the total sizes each configuration
+	// produced, over payloads on which both succeeded.
+	PrimaryBytes, CandidateBytes int64
+
+	// PrimaryTime and CandidateTime are the total time each configuration
+	// spent compressing, over payloads on which both succeeded.
+	PrimaryTime, CandidateTime time.Duration
+}
+
+// CandidateWinRate returns the fraction of payloads won by the candidate,
+// or 0 before the first payload.
+func (s ShadowStats) CandidateWinRate() float64 {
+	if s.Payloads == 0 {
+		return 0
+	}
+	return float64(s.CandidateWins) / float64(s.Payloads)
+}
+
+// SizeDelta returns how much smaller the candidate's output is than the
+// primary's, as a fraction of the primary's: 0.1 means 10% smaller, and a
+// negative value means larger. It returns 0 before both have succeeded on a
+// payload.
+func (s ShadowStats) SizeDelta() float64 {
+	if s.PrimaryBytes == 0 {
+		return 0
+	}
+	return 1 - float64(s.CandidateBytes)/float64(s.PrimaryBytes)
+}
+
+// String summarizes the stats on one line, for logs.
+func (s ShadowStats) String() string {
+	return fmt.Sprintf("%d payloads, candidate won %.1f%% (%d ties), %.1f%% smaller, %v vs %v",
+		s.Payloads, 100*s.CandidateWinRate(), s.Ties, 100*s.SizeDelta(), s.CandidateTime, s.PrimaryTime)
+}
+
+// ShadowCompressor compresses every payload with two configurations, a
+// primary and a candidate, keeps the better result, and records how often
+// each one wins. Running it on production traffic shows whether a new
+// configuration beats the current one before committing to it.
+//
+// Both configurations produce regular OpenZL frames, so the kept result is
+// read with Decompress whichever configuration produced it. The cost is
+// compressing every payload twice.
+//
+// A ShadowCompressor is safe for concurrent use. It does not own its
+// Compressors; close them after the ShadowCompressor is no longer used.
+type ShadowCompressor struct {
+	primary   *Compressor
+	candidate *Compressor
+	criterion ShadowCriterion
+
+	mu    sync.Mutex
+	stats ShadowStats
+}
+
+// NewShadowCompressor creates a ShadowCompressor comparing candidate against
+// primary.
+//
+// Example:
+//
+//	current, _ := openzl.NewCompressor()
+//	defer current.Close()
+//	trial, _ := openzl.NewCompressor(openzl.WithRunShortCircuit(true))
+//	defer trial.Close()
+//
+//	shadow, err := openzl.NewShadowCompressor(current, trial)
+//	...
+//	compressed, err := shadow.Compress(payload)
+//	...
+//	log.Printf("shadow: %v", shadow.Stats())
+//
+// Returns an error if either Compressor is nil or an option is invalid.
+func NewShadowCompressor(primary, candidate *Compressor, opts ...ShadowOption) (*ShadowCompressor, error) {
+	if primary == nil || candidate == nil {
+		return nil, fmt.Errorf("%w: nil Compressor", ErrInvalidParameter)
+	}
+	s := &ShadowCompressor{primary: primary, candidate: candidate}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, fmt.Errorf("apply option: %w", err)
+		}
+	}
+	return s, nil
+}
+
+// Compress compresses src with both configurations and returns the result
+// chosen by the criterion.
+//
+// Returns the primary's error if both configurations fail.
+func (s *ShadowCompressor) Compress(src []byte) ([]byte, error) {
+	if len(src) == 0 {
+		return nil, ErrEmptyInput
+	}
+
+	start := time.Now()
+	p, perr := s.primary.Compress(src)
+	mid := time.Now()
+	c, cerr := s.candidate.Compress(src)
+	pTime, cTime := mid.Sub(start), time.Since(mid)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := &s.stats
+	if perr != nil && cerr != nil {
+		st.PrimaryErrors++
+		st.CandidateErrors++
+		return nil, perr
+	}
+	st.Payloads++
+	st.InputBytes += int64(len(src))
+	switch {
+	case perr != nil:
+		st.PrimaryErrors++
+		st.CandidateWins++
+		return c, nil
+	case cerr != nil:
+		st.CandidateErrors++
+		st.PrimaryWins++
+		return p, nil
+	}
+
+	st.PrimaryBytes += int64(len(p))
+	st.CandidateBytes += int64(len(c))
+	st.PrimaryTime += pTime
+	st.CandidateTime += cTime
+
+	better, tie := len(c) < len(p), len(c) == len(p)
+	if s.criterion == ShadowFaster {
+		better, tie = cTime < pTime, cTime == pTime
+	}
+	switch {
+	case better:
+		st.CandidateWins++
+		return c, nil
+	case tie:
+		st.Ties++
+	default:
+		st.PrimaryWins++
+	}
+	return p, nil
+}
+
+// Stats returns the stats recorded so far.
+func (s *ShadowCompressor) Stats() ShadowStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+// ResetStats clears the recorded stats, for example to compare windows of
+// traffic.
+func (s *ShadowCompressor) ResetStats() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats = ShadowStats{}
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestShadowCompressor(t *testing.T) {
+	primary, err := NewCompressor()
+	if err != nil {
+		t.Fatalf("NewCompressor failed: %v", err)
+	}
+	defer primary.Close()
+	candidate, err := NewCompressor()
+	if err != nil {
+		t.Fatalf("NewCompressor failed: %v", err)
+	}
+	defer candidate.Close()
+
+	shadow, err := NewShadowCompressor(primary, candidate)
+	if err != nil {
+		t.Fatalf("NewShadowCompressor failed: %v", err)
+	}
+	payloads := [][]byte{
+		bytes.Repeat([]byte("shadow "), 500),
+		[]byte("short payload"),
+		bytes.Repeat([]byte{1, 2, 3, 4}, 2000),
+	}
+	for _, p := range payloads {
+		compressed, err := shadow.Compress(p)
+		if err != nil {
+			t.Fatalf("Compress failed: %v", err)
+		}
+		got, err := Decompress(compressed)
+		if err != nil || !bytes.Equal(got, p) {
+			t.Fatalf("round trip failed: %v", err)
+		}
+	}
+
+	// Identical configurations produce identical frames, so every payload
+	// is a tie kept from the primary
+	st := shadow.Stats()
+	if st.Payloads != 3 || st.Ties != 3 || st.CandidateWins != 0 || st.CandidateWinRate() != 0 {
+		t.Errorf("Stats() = %+v, want 3 ties", st)
+	}
+	if st.PrimaryBytes != st.CandidateBytes || st.SizeDelta() != 0 {
+		t.Errorf("PrimaryBytes %d != CandidateBytes %d", st.PrimaryBytes, st.CandidateBytes)
+	}
+	t.Logf("Stats: %v", st)
+
+	shadow.ResetStats()
+	if st := shadow.Stats(); st != (ShadowStats{}) {
+		t.Errorf("Stats() after reset = %+v", st)
+	}
+
+	if _, err := NewShadowCompressor(primary, nil); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("nil candidate: error = %v, want ErrInvalidParameter", err)
+	}
+	if _, err := NewShadowCompressor(primary, candidate, WithShadowCriterion(7)); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("invalid criterion: error = %v, want ErrInvalidParameter", err)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// shutDown is set by Shutdown, while holding poolsMu.
+var shutDown atomic.Bool
+
+// pools holds every Pool that has not been closed, for Shutdown.
+var (
+	poolsMu sync.Mutex
+	pools   = map[*Pool]struct{}{}
+)
+
+// registerPool adds p to the pools closed by Shutdown, or returns
+// ErrShutdown if Shutdown has started. Checking under poolsMu ensures that
+// a Pool is either seen by Shutdown or never accepted.
+func registerPool(p *Pool) error {
+	poolsMu.Lock()
+	defer poolsMu.Unlock()
+	if shutDown.Load() {
+		return ErrShutdown
+	}
+	pools[p] = struct{}{}
+	return nil
+}
+
+func unregisterPool(p *Pool) {
+	poolsMu.Lock()
+	delete(pools, p)
+	poolsMu.Unlock()
+}
+
+// shutdownPollInterval is how often Shutdown checks for one-shot operations
+// still in flight.
+const shutdownPollInterval = time.Millisecond
+
+// Shutdown winds down the package's background work for process exit, such
+// as on SIGTERM in a container:
+//   - NewPool fails with ErrShutdown from now on.
+//   - Every open Pool is closed: it stops accepting jobs, finishes the jobs
+//     already queued, and frees its workers' native contexts.
+//   - Shutdown waits for one-shot Compress and Decompress calls in flight,
+//     then frees the native contexts cached for them. Later one-shot calls
+//     still work but no longer cache contexts.
+//
+// Native code is never interrupted, so no operation is left half done in C.
+// If ctx ends first, Shutdown returns its error; the Pools keep draining in
+// the background and native contexts still in use are freed by their
+// owners once done. Compressors, Decompressors, Writers, and Readers are
+// owned by the caller and must still be closed by it.
+//
+// Shutdown may be called more than once; each call waits for the work
+// started since.
+//
+// Example:
+//
+//	sig := make(chan os.Signal, 1)
+//	signal.Notify(sig, syscall.SIGTERM)
+//	<-sig
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer cancel()
+//	if err := openzl.Shutdown(ctx); err != nil {
+//		log.Printf("openzl: shutdown incomplete: %v", err)
+//	}
+func Shutdown(ctx context.Context) error {
+	poolsMu.Lock()
+	shutDown.Store(true)
+	open := make([]*Pool, 0, len(pools))
+	for p := range pools {
+		open = append(open, p)
+	}
+	poolsMu.Unlock()
+
+	// Close concurrently, so that every Pool stops accepting work at once
+	// rather than after the previous one has drained
+	var wg sync.WaitGroup
+	for _, p := range open {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.Close()
+		}()
+	}
+	closed := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	ticker := time.NewTicker(shutdownPollInterval)
+	defer ticker.Stop()
+	for cctxCache.inUse.Load() > 0 || dctxCache.inUse.Load() > 0 {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	cctxCache.drain()
+	dctxCache.drain()
+	return nil
+}
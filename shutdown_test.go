@@ -0,0 +1,111 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// resetShutdown undoes Shutdown when the test ends.
+func resetShutdown(t *testing.T) {
+	t.Cleanup(func() { shutDown.Store(false) })
+}
+
+func TestShutdown(t *testing.T) {
+	resetShutdown(t)
+
+	pool, err := NewPool(WithWorkers(2))
+	if err != nil {
+		t.Fatalf("NewPool() failed: %v", err)
+	}
+	data := []byte("shutdown drains queued jobs before freeing contexts")
+	results := make([]<-chan Result, 20)
+	for i := range results {
+		results[i] = pool.CompressAsync(data)
+	}
+	if _, err := Compress(data); err != nil {
+		t.Fatalf("Compress() failed: %v", err)
+	}
+
+	if err := Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() failed: %v", err)
+	}
+	for i, ch := range results {
+		if r := <-ch; r.Err != nil {
+			t.Errorf("job %d failed: %v", i, r.Err)
+		}
+	}
+	if r := <-pool.CompressAsync(data); !errors.Is(r.Err, ErrPoolClosed) {
+		t.Errorf("CompressAsync() after Shutdown error = %v, want ErrPoolClosed", r.Err)
+	}
+	if _, err := NewPool(); !errors.Is(err, ErrShutdown) {
+		t.Errorf("NewPool() after Shutdown error = %v, want ErrShutdown", err)
+	}
+
+	// One-shot calls keep working without caching contexts
+	if _, err := Compress(data); err != nil {
+		t.Fatalf("Compress() after Shutdown failed: %v", err)
+	}
+	for i := range cctxCache.stripes {
+		if n := len(cctxCache.stripes[i].free); n != 0 {
+			t.Errorf("stripe %d holds %d contexts after Shutdown", i, n)
+		}
+	}
+	poolsMu.Lock()
+	defer poolsMu.Unlock()
+	if len(pools) != 0 {
+		t.Errorf("%d pools still registered", len(pools))
+	}
+}
+
+func TestShutdown_Deadline(t *testing.T) {
+	resetShutdown(t)
+
+	// A one-shot operation that never finishes in time
+	ctx, err := cctxCache.get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cctxCache.put(ctx)
+
+	deadline, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := Shutdown(deadline); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestShutdown_ConcurrentNewPool(t *testing.T) {
+	resetShutdown(t)
+
+	// Every Pool created while Shutdown runs is either refused or drained
+	created := make(chan *Pool, 64)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range cap(created) {
+			p, err := NewPool(WithWorkers(1))
+			if err != nil {
+				if !errors.Is(err, ErrShutdown) {
+					t.Errorf("NewPool() error = %v, want ErrShutdown", err)
+				}
+				return
+			}
+			created <- p
+		}
+	}()
+	if err := Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() failed: %v", err)
+	}
+	<-done
+	close(created)
+	for p := range created {
+		if r := <-p.CompressAsync([]byte("after shutdown")); !errors.Is(r.Err, ErrPoolClosed) {
+			t.Errorf("CompressAsync() on a pool created during Shutdown error = %v, want ErrPoolClosed", r.Err)
+		}
+	}
+}
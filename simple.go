@@ -4,7 +4,9 @@
 package openzl
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 
 	"github.com/borischu/go-openzl/internal/cgo"
 )
@@ -12,8 +14,15 @@ import (
 // Compress compresses the input data using OpenZL with default settings.
 // It returns the compressed data or an error.
 //
-// This is a simple one-shot compression function suitable for occasional use.
-// For better performance with repeated operations, use the Compressor type.
+// This is a simple one-shot compression function. It reuses native contexts
+// across calls and scales across goroutines, but a Compressor avoids even
+// the cache lookup and allows per-context options.
+//
+// Inputs larger than the chunk threshold, 1GB unless set with
+// WithChunkThreshold, are split into chunks compressed into separate frames.
+// The result is then not a single OpenZL frame but a container of them,
+// which Decompress and Decompressor.Decompress reassemble; other functions
+// that take a frame reject it.
 //
 // Example:
 //
@@ -27,31 +36,80 @@ func Compress(src []byte) ([]byte, error) {
 		return nil, ErrEmptyInput
 	}
 
-	// Create compression context
-	ctx, err := cgo.NewCCtx()
+	// Take a cached compression context
+	ctx, err := cctxCache.get()
 	if err != nil {
 		return nil, fmt.Errorf("create context: %w", err)
 	}
-	defer ctx.Free()
+	ctx.SetCompressionLevel(defaults().level)
+
+	if size := defaults().chunkSize; len(src) > size {
+		dst, err := compressChunks(ctx, src, size)
+		if err != nil {
+			cctxCache.discard(ctx)
+			return nil, fmt.Errorf("compress: %w", err)
+		}
+		cctxCache.put(ctx)
+		return dst, nil
+	}
 
 	// Allocate destination buffer
-	dstSize := cgo.CompressBound(len(src))
+	dstSize, err := compressBound(len(src), 1)
+	if err != nil {
+		cctxCache.put(ctx)
+		return nil, err
+	}
 	dst := make([]byte, dstSize)
 
 	// Compress
 	n, err := ctx.Compress(dst, src)
 	if err != nil {
+		cctxCache.discard(ctx)
 		return nil, fmt.Errorf("compress: %w", err)
 	}
+	cctxCache.put(ctx)
 
 	return dst[:n], nil
 }
 
+// Compress2D compresses the concatenation of srcs with default settings,
+// without first concatenating them in Go memory. See Compressor.Compress2D.
+//
+// Example:
+//
+//	compressed, err := openzl.Compress2D([][]byte{header, body})
+func Compress2D(srcs [][]byte) ([]byte, error) {
+	total := 0
+	for _, src := range srcs {
+		total += len(src)
+	}
+	if total == 0 {
+		return nil, ErrEmptyInput
+	}
+
+	ctx, err := cctxCache.get()
+	if err != nil {
+		return nil, fmt.Errorf("create context: %w", err)
+	}
+	ctx.SetCompressionLevel(defaults().level)
+
+	dst, err := ctx.CompressGather(srcs)
+	if err != nil {
+		cctxCache.discard(ctx)
+		return nil, fmt.Errorf("compress: %w", err)
+	}
+	cctxCache.put(ctx)
+
+	return dst, nil
+}
+
 // Decompress decompresses OpenZL-compressed data.
 // It returns the decompressed data or an error.
 //
-// This is a simple one-shot decompression function suitable for occasional use.
-// For better performance with repeated operations, use the Decompressor type.
+// This is a simple one-shot decompression function. Like Compress, it reuses
+// native contexts across calls; a Decompressor allows per-context options
+// such as WithMemoryBudget. It also reassembles the chunks of large inputs
+// split by Compress.
 //
 // Example:
 //
@@ -63,28 +121,164 @@ func Decompress(src []byte) ([]byte, error) {
 	if len(src) == 0 {
 		return nil, ErrEmptyInput
 	}
+	if isChunked(src) {
+		return decompressChunked(src)
+	}
 
 	// Get decompressed size
 	dstSize, err := cgo.GetDecompressedSize(src)
 	if err != nil {
-		return nil, fmt.Errorf("get decompressed size: %w", err)
+		return nil, fmt.Errorf("get decompressed size: %w", diagnoseFrameError(src, err))
 	}
 
 	// Allocate destination buffer
 	dst := make([]byte, dstSize)
 
-	// Create decompression context
-	ctx, err := cgo.NewDCtx()
+	// Take a cached decompression context
+	ctx, err := dctxCache.get()
 	if err != nil {
 		return nil, fmt.Errorf("create context: %w", err)
 	}
-	defer ctx.Free()
 
 	// Decompress
 	n, err := ctx.Decompress(dst, src)
 	if err != nil {
+		dctxCache.discard(ctx)
 		return nil, fmt.Errorf("decompress: %w", err)
 	}
+	dctxCache.put(ctx)
 
 	return dst[:n], nil
 }
+
+// decompressChunked decompresses the output of Compress for a chunked input
+// with a cached context.
+func decompressChunked(src []byte) ([]byte, error) {
+	ctx, err := dctxCache.get()
+	if err != nil {
+		return nil, fmt.Errorf("create context: %w", err)
+	}
+	dst, err := decompressChunks(src, ctx.Decompress, func(int) error { return nil })
+	if err != nil {
+		dctxCache.discard(ctx)
+		return nil, err
+	}
+	dctxCache.put(ctx)
+	return dst, nil
+}
+
+// DecompressScatter decompresses compressed across the buffers dsts, in
+// order, and returns the number of bytes written. See
+// Decompressor.DecompressScatter.
+//
+// Example:
+//
+//	n, err := openzl.DecompressScatter([][]byte{page0, page1}, compressed)
+func DecompressScatter(dsts [][]byte, compressed []byte) (int, error) {
+	if len(compressed) == 0 {
+		return 0, ErrEmptyInput
+	}
+
+	ctx, err := dctxCache.get()
+	if err != nil {
+		return 0, fmt.Errorf("create context: %w", err)
+	}
+	if isChunked(compressed) {
+		n, err := scatterChunks(dsts, compressed, ctx.DecompressScatter)
+		if err != nil {
+			dctxCache.discard(ctx)
+			return 0, err
+		}
+		dctxCache.put(ctx)
+		return n, nil
+	}
+
+	n, size, err := ctx.DecompressScatter(dsts, compressed)
+	if err != nil {
+		dctxCache.discard(ctx)
+		if size == 0 {
+			return 0, fmt.Errorf("get decompressed size: %w", diagnoseFrameError(compressed, err))
+		}
+		return 0, fmt.Errorf("decompress: %w", err)
+	}
+	dctxCache.put(ctx)
+	if n < size {
+		return 0, fmt.Errorf("%w: need %d bytes", ErrBufferTooSmall, size)
+	}
+
+	return n, nil
+}
+
+// decompressToChunkSize is the size of the writes made by DecompressTo.
+const decompressToChunkSize = 1 << 20
+
+// DecompressTo decompresses compressed and writes the result to w, returning
+// the number of bytes written.
+//
+// Unlike Decompress, it never allocates the whole output on the Go heap, so
+// decompressing hundreds of megabytes does not grow the heap or raise the
+// garbage collector's target:
+//   - Writer streams are decoded frame by frame, so memory use is bounded by
+//     the stream's frame size.
+//   - A bare frame from Compress cannot be decoded incrementally. It is
+//     decompressed into a native buffer, which is written to w in 1MB chunks
+//     and released before DecompressTo returns. Large inputs that Compress
+//     split into chunks are decoded one chunk at a time.
+//
+// Example:
+//
+//	f, _ := os.Create("dump.bin")
+//	defer f.Close()
+//	n, err := openzl.DecompressTo(f, compressed)
+//
+// Returns ErrEmptyInput if compressed is empty, a decompression error if it
+// is invalid or corrupted, or the first error returned by w.
+func DecompressTo(w io.Writer, compressed []byte) (int64, error) {
+	if len(compressed) == 0 {
+		return 0, ErrEmptyInput
+	}
+
+	if isStream(compressed) {
+		r, err := NewReader(bytes.NewReader(compressed))
+		if err != nil {
+			return 0, err
+		}
+		defer r.Close()
+		return io.Copy(w, r)
+	}
+
+	ctx, err := cgo.NewDCtx()
+	if err != nil {
+		return 0, fmt.Errorf("create context: %w", err)
+	}
+	defer ctx.Free()
+	if isChunked(compressed) {
+		return writeChunks(w, ctx, compressed)
+	}
+	return writeNative(w, ctx, compressed)
+}
+
+// writeNative decompresses the bare frame src into a native buffer, writes
+// it to w in chunks of decompressToChunkSize, and releases it.
+func writeNative(w io.Writer, ctx *cgo.DCtx, src []byte) (int64, error) {
+	buf, err := ctx.DecompressNative(src)
+	if err != nil {
+		return 0, fmt.Errorf("decompress: %w", err)
+	}
+	defer buf.Free()
+
+	var written int64
+	for data := buf.Bytes(); len(data) > 0; {
+		chunk := data[:min(len(data), decompressToChunkSize)]
+		n, err := w.Write(chunk)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+		if n < len(chunk) {
+			return written, io.ErrShortWrite
+		}
+		data = data[n:]
+	}
+	return written, nil
+}
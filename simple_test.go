@@ -5,6 +5,8 @@ package openzl_test
 
 import (
 	"bytes"
+	"errors"
+	"io"
 	"testing"
 
 	"github.com/borischu/go-openzl"
@@ -101,3 +103,109 @@ func BenchmarkDecompress(b *testing.B) {
 		}
 	}
 }
+
+func TestFrameHeaderLength(t *testing.T) {
+	data := bytes.Repeat([]byte("frame header length "), 100)
+	compressed, err := openzl.Compress(data)
+	if err != nil {
+		t.Fatalf("Compress() failed: %v", err)
+	}
+
+	n, err := openzl.FrameHeaderLength(compressed)
+	if err != nil {
+		t.Fatalf("FrameHeaderLength() failed: %v", err)
+	}
+	if n <= 0 || n >= len(compressed) {
+		t.Errorf("FrameHeaderLength() = %d, want within (0, %d)", n, len(compressed))
+	}
+
+	if _, err := openzl.FrameHeaderLength(nil); !errors.Is(err, openzl.ErrEmptyInput) {
+		t.Errorf("empty input error = %v, want ErrEmptyInput", err)
+	}
+
+	var buf bytes.Buffer
+	writer, _ := openzl.NewWriter(&buf)
+	writer.Write(data)
+	writer.Close()
+	if _, err := openzl.FrameHeaderLength(buf.Bytes()); !errors.Is(err, openzl.ErrStreamInput) {
+		t.Errorf("stream input error = %v, want ErrStreamInput", err)
+	}
+}
+
+func TestDecompressTo(t *testing.T) {
+	data := bytes.Repeat([]byte("decompress straight into a writer "), 100000)
+
+	compressed, err := openzl.Compress(data)
+	if err != nil {
+		t.Fatalf("Compress() failed: %v", err)
+	}
+	var stream bytes.Buffer
+	writer, _ := openzl.NewWriter(&stream)
+	writer.Write(data)
+	writer.Close()
+
+	inputs := map[string][]byte{"bare frame": compressed, "stream": stream.Bytes()}
+	for name, input := range inputs {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			n, err := openzl.DecompressTo(&out, input)
+			if err != nil {
+				t.Fatalf("DecompressTo() failed: %v", err)
+			}
+			if n != int64(len(data)) || !bytes.Equal(out.Bytes(), data) {
+				t.Errorf("DecompressTo() wrote %d bytes, want %d", n, len(data))
+			}
+		})
+	}
+
+	if _, err := openzl.DecompressTo(io.Discard, nil); !errors.Is(err, openzl.ErrEmptyInput) {
+		t.Errorf("empty input error = %v, want ErrEmptyInput", err)
+	}
+	if _, err := openzl.DecompressTo(io.Discard, []byte("not compressed")); err == nil {
+		t.Error("DecompressTo() accepted invalid input")
+	}
+}
+
+func TestDecompressScatter(t *testing.T) {
+	data := bytes.Repeat([]byte("scatter into pages "), 100)
+	compressed, err := openzl.Compress(data)
+	if err != nil {
+		t.Fatalf("Compress() failed: %v", err)
+	}
+	decompressor, err := openzl.NewDecompressor()
+	if err != nil {
+		t.Fatalf("NewDecompressor() failed: %v", err)
+	}
+	defer decompressor.Close()
+
+	for name, scatter := range map[string]func([][]byte, []byte) (int, error){
+		"Decompressor": decompressor.DecompressScatter,
+		"package":      openzl.DecompressScatter,
+	} {
+		for _, sizes := range [][]int{{len(data)}, {len(data) + 10, 5}, {512, 0, 512, 1024}} {
+			dsts := make([][]byte, len(sizes))
+			for i, size := range sizes {
+				dsts[i] = make([]byte, size)
+			}
+			n, err := scatter(dsts, compressed)
+			if err != nil {
+				t.Fatalf("%s DecompressScatter(%v) failed: %v", name, sizes, err)
+			}
+			if n != len(data) {
+				t.Fatalf("%s DecompressScatter(%v) = %d, want %d", name, sizes, n, len(data))
+			}
+			got := bytes.Join(dsts, nil)[:n]
+			if !bytes.Equal(got, data) {
+				t.Errorf("%s DecompressScatter(%v) output mismatch", name, sizes)
+			}
+		}
+
+		dsts := [][]byte{make([]byte, 100), make([]byte, 100)}
+		if _, err := scatter(dsts, compressed); !errors.Is(err, openzl.ErrBufferTooSmall) {
+			t.Errorf("%s DecompressScatter(small) error = %v, want ErrBufferTooSmall", name, err)
+		}
+		if _, err := scatter(dsts, nil); !errors.Is(err, openzl.ErrEmptyInput) {
+			t.Errorf("%s DecompressScatter(nil) error = %v, want ErrEmptyInput", name, err)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"io"
+	"math"
+	"sync"
+)
+
+// asyncSink writes frames to an underlying writer on a background goroutine,
+// bounding the number of compressed bytes that are queued but not yet written.
+//
+// It lets a Writer keep compressing while a slow destination (for example a
+// rate-limited network connection) catches up, and provides the back-pressure
+// signal used by Write and TryWrite once the limit is reached.
+type asyncSink struct {
+	w        io.Writer     // Destination for queued frames
+	max      int           // Maximum in-flight bytes before enqueue blocks
+	mu       sync.Mutex    // Protects all fields below
+	cond     *sync.Cond    // Signaled when pending, inFlight, or closing change
+	pending  [][]byte      // Queued chunks, written in order
+	inFlight int           // Total bytes in pending plus the chunk being written
+	closing  bool          // No more chunks will be enqueued
+	err      error         // First write error, sticky
+	ready    chan struct{} // Receives a value whenever capacity is freed
+	done     chan struct{} // Closed when the background goroutine exits
+}
+
+// newAsyncSink starts a sink that writes to w with the given in-flight limit.
+func newAsyncSink(w io.Writer, limit int) *asyncSink {
+	s := &asyncSink{
+		w:     w,
+		max:   limit,
+		ready: make(chan struct{}, 1),
+		done:  make(chan struct{}),
+	}
+	s.cond = sync.NewCond(&s.mu)
+	go s.run()
+	return s
+}
+
+// run writes queued chunks until the sink is closed and drained, or a write fails.
+func (s *asyncSink) run() {
+	defer close(s.done)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for {
+		for len(s.pending) == 0 && !s.closing {
+			s.cond.Wait()
+		}
+		if len(s.pending) == 0 {
+			return
+		}
+
+		chunk := s.pending[0]
+		s.pending[0] = nil
+		s.pending = s.pending[1:]
+
+		s.mu.Unlock()
+		err := writeFull(s.w, chunk)
+		s.mu.Lock()
+
+		s.inFlight -= len(chunk)
+		if err != nil && s.err == nil {
+			s.err = err
+		}
+
+		// Notify TryWrite callers without blocking if nobody is listening
+		select {
+		case s.ready <- struct{}{}:
+		default:
+		}
+		s.cond.Broadcast()
+
+		if s.err != nil {
+			// Drop everything queued after the failure
+			for _, c := range s.pending {
+				s.inFlight -= len(c)
+			}
+			s.pending = nil
+			return
+		}
+	}
+}
+
+// full reports whether enqueueing would block right now.
+func (s *asyncSink) full() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err == nil && s.inFlight >= s.max
+}
+
+// enqueue queues chunks as one unit, blocking while the in-flight limit is
+// reached. The limit is soft: a single frame may push the total above max.
+func (s *asyncSink) enqueue(chunks ...[]byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for s.err == nil && s.inFlight >= s.max {
+		s.cond.Wait()
+	}
+	if s.err != nil {
+		return s.err
+	}
+
+	for _, c := range chunks {
+		s.pending = append(s.pending, c)
+		s.inFlight += len(c)
+	}
+	s.cond.Broadcast()
+	return nil
+}
+
+// close waits for all queued chunks to be written and stops the goroutine.
+// It returns the first write error, if any.
+func (s *asyncSink) close() error {
+	s.mu.Lock()
+	s.closing = true
+	s.cond.Broadcast()
+	s.mu.Unlock()
+
+	<-s.done
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// WithMaxInFlight enables asynchronous writing to the underlying writer with
+// at most n compressed bytes queued but not yet written.
+//
+// Without this option, Write compresses and writes each frame synchronously,
+// so a slow destination stalls compression entirely. With it, frames are
+// handed to a background goroutine and Write only blocks once n bytes are
+// waiting, letting compression overlap with slow network or disk writes while
+// still bounding memory. TryWrite becomes available as a non-blocking
+// alternative to Write.
+//
+// The limit is soft: a single frame may take the total above n. Errors from
+// the underlying writer are reported by the next Write, TryWrite, or Close.
+func WithMaxInFlight(n int) WriterOption {
+	return func(w *Writer) error {
+		if n < 1 {
+			return rangeError("WithMaxInFlight", n, 1, math.MaxInt64)
+		}
+		w.maxInFlight = n
+		return nil
+	}
+}
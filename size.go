@@ -0,0 +1,68 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/borischu/go-openzl/internal/cgo"
+)
+
+// MaxInputSize is the largest input a single OpenZL frame can hold, as
+// declared by the library the package is built with. Larger inputs fail with
+// ErrInputTooLarge before any native call, except in Compress, which splits
+// inputs above the chunk threshold into several frames.
+const MaxInputSize = cgo.MaxContentSize
+
+// DecompressedSize returns the size of the data of the OpenZL frame src, as
+// declared by its header, without decompressing it.
+//
+// The size is an int64 so that frames written on 64-bit platforms can be
+// inspected on 32-bit ones, where Decompress fails with ErrTooLarge for
+// frames of 2GB or more: such frames can be rejected, or split upstream,
+// before anything is allocated. For the output of Compress on an input
+// above the chunk threshold, it returns the total size of the chunks.
+//
+// Example:
+//
+//	size, err := openzl.DecompressedSize(frame)
+//	if err != nil {
+//		return err
+//	}
+//	if size > maxBodySize {
+//		return errBodyTooLarge
+//	}
+//	body, err := openzl.Decompress(frame)
+//
+// Returns ErrEmptyInput if src is empty, or an error if the frame header is
+// invalid.
+func DecompressedSize(src []byte) (int64, error) {
+	if len(src) == 0 {
+		return 0, ErrEmptyInput
+	}
+	if isChunked(src) {
+		_, _, total, err := chunkSizes(src)
+		return int64(total), err
+	}
+	size, err := cgo.GetDecompressedSize64(src)
+	if err != nil {
+		return 0, fmt.Errorf("get decompressed size: %w", diagnoseFrameError(src, err))
+	}
+	if size > math.MaxInt64 {
+		return 0, fmt.Errorf("%w: %d bytes", ErrTooLarge, size)
+	}
+	return int64(size), nil
+}
+
+// compressBound returns margin times the compression bound of srcSize bytes,
+// or ErrTooLarge if that does not fit in an int. Typed compression uses a
+// margin of 2.
+func compressBound(srcSize, margin int) (int, error) {
+	bound := cgo.CompressBound(srcSize)
+	if bound < srcSize || bound > math.MaxInt/margin {
+		return 0, fmt.Errorf("%w: compression bound of %d bytes", ErrTooLarge, srcSize)
+	}
+	return bound * margin, nil
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"os"
+	"strconv"
+	"testing"
+)
+
+func TestCompressBoundOverflow(t *testing.T) {
+	if n, err := compressBound(1000, 2); err != nil || n < 2000 {
+		t.Errorf("compressBound(1000, 2) = %d, %v, want at least 2000", n, err)
+	}
+	for _, tc := range []struct{ size, margin int }{
+		{math.MaxInt - 10, 1},
+		{math.MaxInt / 2, 2},
+	} {
+		if _, err := compressBound(tc.size, tc.margin); !errors.Is(err, ErrTooLarge) {
+			t.Errorf("compressBound(%d, %d) error = %v, want ErrTooLarge", tc.size, tc.margin, err)
+		}
+	}
+}
+
+func TestDecompressedSize(t *testing.T) {
+	data := bytes.Repeat([]byte("size "), 1000)
+	compressed, err := Compress(data)
+	if err != nil {
+		t.Fatalf("Compress() failed: %v", err)
+	}
+	if size, err := DecompressedSize(compressed); err != nil || size != int64(len(data)) {
+		t.Errorf("DecompressedSize() = %d, %v, want %d", size, err, len(data))
+	}
+	if _, err := DecompressedSize(nil); !errors.Is(err, ErrEmptyInput) {
+		t.Errorf("DecompressedSize(nil) error = %v, want ErrEmptyInput", err)
+	}
+	if _, err := DecompressedSize([]byte("not a frame")); err == nil {
+		t.Error("DecompressedSize(garbage) succeeded")
+	}
+}
+
+// TestHugeInput round-trips inputs over 2GB, whose sizes overflow C int and
+// int32. It needs about 8GB of memory and runs with OPENZL_HUGE=1 (make
+// test-huge) on 64-bit platforms.
+func TestHugeInput(t *testing.T) {
+	if os.Getenv("OPENZL_HUGE") == "" {
+		t.Skip("set OPENZL_HUGE=1 to run")
+	}
+	if strconv.IntSize < 64 {
+		t.Skip("inputs over 2GB need a 64-bit platform")
+	}
+
+	const size = 1<<31 + 4096
+	pattern := []byte("0123456789abcdefghijklmnopqrstuvwxyz\n")
+	data := bytes.Repeat(pattern, size/len(pattern)+1)[:size]
+
+	compressed, err := Compress(data)
+	if err != nil {
+		t.Fatalf("Compress() failed: %v", err)
+	}
+	if n, err := DecompressedSize(compressed); err != nil || n != size {
+		t.Fatalf("DecompressedSize() = %d, %v, want %d", n, err, int64(size))
+	}
+	out, err := Decompress(compressed)
+	if err != nil {
+		t.Fatalf("Decompress() failed: %v", err)
+	}
+	if !bytes.Equal(out, data) {
+		t.Fatal("Decompress() output differs from input")
+	}
+	out = nil
+
+	c, err := NewCompressor()
+	if err != nil {
+		t.Fatalf("NewCompressor() failed: %v", err)
+	}
+	defer c.Close()
+	compressed, err = c.Compress(data)
+	if err != nil {
+		t.Fatalf("Compressor.Compress() failed: %v", err)
+	}
+	d, err := NewDecompressor()
+	if err != nil {
+		t.Fatalf("NewDecompressor() failed: %v", err)
+	}
+	defer d.Close()
+	if out, err = d.Decompress(compressed); err != nil || !bytes.Equal(out, data) {
+		t.Fatalf("Decompressor.Decompress() round trip failed: %v", err)
+	}
+}
+
+func TestMaxInputSize(t *testing.T) {
+	// Chunks of Compress must fit in a frame at any threshold
+	if uint64(MaxInputSize) < MaxChunkThreshold {
+		t.Errorf("MaxInputSize = %d, want at least MaxChunkThreshold (%d)", uint64(MaxInputSize), MaxChunkThreshold)
+	}
+}
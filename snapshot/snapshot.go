@@ -0,0 +1,594 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Package snapshot takes incremental, compressed snapshots of a directory
+// tree and restores them.
+//
+// A repository is a directory holding one subdirectory per snapshot, named
+// after the time it was taken. Each snapshot holds:
+//
+//	manifest.json   every file of the tree: path, size, mode, time, hash,
+//	                and where its content is stored
+//	data.zl         the content of the files that changed since the
+//	                previous snapshot, as one openzl Writer stream
+//	data.zl.zli     the frame index of data.zl (openzl.WithIndexSidecar)
+//
+// Files are compressed with the options an openzl.Policy selects for them,
+// openzl.DefaultPolicy unless set with WithPolicy. Files matching a rule of
+// the policy go to an archive of their own, data.<rule>.zl with its index,
+// written with that rule's options; the others go to data.zl.
+//
+// Files whose size and modification time match the previous snapshot are
+// not read again, and files whose content matches a file of the previous
+// snapshot, or an earlier file of the same snapshot, are not stored again:
+// their manifest entry points into the archive that already holds the
+// content. Thanks to the frame index, restoring one file decompresses only
+// the frames that hold it, whichever snapshot they belong to.
+//
+// Example:
+//
+//	m, err := snapshot.Take("/backup/home", "/home/me")
+//	...
+//	names, err := snapshot.List("/backup/home")
+//	err = snapshot.Restore("/backup/home", names[0], "/tmp/restored")
+//
+// Only regular files are captured; symbolic links, devices, and empty
+// directories are skipped. A snapshot is complete once its manifest is
+// written, so an interrupted Take leaves no snapshot behind.
+package snapshot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/borischu/go-openzl"
+)
+
+// Names of the files of a snapshot.
+const (
+	ManifestName = "manifest.json"
+	ArchiveName  = "data.zl"
+	IndexName    = ArchiveName + openzl.IndexSidecarExt
+)
+
+// ManifestVersion is the version of the manifest format.
+const ManifestVersion = 1
+
+// timeFormat names snapshots; names sort in the order snapshots were taken.
+const timeFormat = "20060102T150405.000000000Z"
+
+// ErrNoSnapshot indicates that a repository holds no snapshot of the
+// requested name.
+var ErrNoSnapshot = errors.New("snapshot: no such snapshot")
+
+// File is the manifest entry of one file.
+type File struct {
+	// Path is the path of the file relative to the snapshotted directory,
+	// with forward slashes.
+	Path    string      `json:"path"`
+	Size    int64       `json:"size"`
+	Mode    fs.FileMode `json:"mode"`
+	ModTime time.Time   `json:"mod_time"`
+
+	// SHA256 is the hex-encoded SHA-256 of the content.
+	SHA256 string `json:"sha256"`
+
+	// Snapshot names the snapshot whose archive holds the content, at
+	// uncompressed offset Offset. Archive names the archive in that
+	// snapshot, ArchiveName if empty.
+	Snapshot string `json:"snapshot"`
+	Archive  string `json:"archive,omitempty"`
+	Offset   int64  `json:"offset"`
+}
+
+// Manifest describes one snapshot.
+type Manifest struct {
+	Version int       `json:"version"`
+	Name    string    `json:"name"`
+	Created time.Time `json:"created"`
+
+	// Parent names the snapshot this one is incremental to, if any.
+	Parent string `json:"parent,omitempty"`
+
+	// Files lists the files of the tree, sorted by path.
+	Files []File `json:"files"`
+
+	// Stored is the number of bytes of content stored in this snapshot's
+	// archive, before compression; the rest is shared with earlier
+	// snapshots.
+	Stored int64 `json:"stored"`
+}
+
+// Option configures Take.
+type Option func(*config) error
+
+type config struct {
+	policy *openzl.Policy
+}
+
+// WithPolicy sets the policy that chooses how each file is compressed. A
+// nil policy means openzl.DefaultPolicy. The MIME type given to the policy
+// is taken from the file extension.
+func WithPolicy(p *openzl.Policy) Option {
+	return func(c *config) error {
+		c.policy = p
+		return nil
+	}
+}
+
+// Take snapshots the directory dir into the repository root, creating root
+// if needed, and returns the manifest of the new snapshot.
+//
+// The snapshot is incremental to the latest snapshot of root, if any.
+//
+// Returns an error if an option is invalid, if walking dir, reading a file,
+// or writing the repository fails, or if a file changes while it is stored.
+func Take(root, dir string, opts ...Option) (m *Manifest, err error) {
+	cfg := config{}
+	for _, opt := range opts {
+		if err := opt(&cfg); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.policy == nil {
+		cfg.policy = openzl.DefaultPolicy()
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	names, err := List(root)
+	if err != nil {
+		return nil, err
+	}
+
+	created := time.Now().UTC()
+	m = &Manifest{Version: ManifestVersion, Name: created.Format(timeFormat), Created: created}
+	prev := map[string]File{}  // By path, from the parent
+	known := map[string]File{} // By hash, from the parent and this snapshot
+	if len(names) > 0 {
+		parent, err := Load(root, names[len(names)-1])
+		if err != nil {
+			return nil, err
+		}
+		m.Parent = parent.Name
+		for _, f := range parent.Files {
+			prev[f.Path] = f
+			known[f.SHA256] = f
+		}
+	}
+
+	snap := filepath.Join(root, m.Name)
+	if err := os.Mkdir(snap, 0o755); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			os.RemoveAll(snap)
+		}
+	}()
+
+	writers := map[int]*archiveWriter{} // By policy rule
+	defer func() {
+		for _, w := range writers {
+			w.abort()
+		}
+	}()
+
+	// The repository may live inside dir; it is not part of the snapshot
+	rootAbs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if abs, err := filepath.Abs(path); err == nil && abs == rootAbs {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		f := File{
+			Path:    filepath.ToSlash(rel),
+			Size:    info.Size(),
+			Mode:    info.Mode().Perm(),
+			ModTime: info.ModTime().UTC(),
+		}
+
+		// Unchanged since the parent: trust size and time
+		if p, ok := prev[f.Path]; ok && p.Size == f.Size && p.ModTime.Equal(f.ModTime) {
+			f.SHA256, f.Snapshot, f.Archive, f.Offset = p.SHA256, p.Snapshot, p.Archive, p.Offset
+			m.Files = append(m.Files, f)
+			return nil
+		}
+
+		if f.SHA256, err = hashFile(path); err != nil {
+			return err
+		}
+		if k, ok := known[f.SHA256]; ok && k.Size == f.Size {
+			f.Snapshot, f.Archive, f.Offset = k.Snapshot, k.Archive, k.Offset
+		} else {
+			rule := cfg.policy.Match(f.Path, mime.TypeByExtension(filepath.Ext(f.Path)))
+			w, ok := writers[rule]
+			if !ok {
+				if w, err = newArchiveWriter(snap, rule, cfg.policy); err != nil {
+					return err
+				}
+				writers[rule] = w
+			}
+			f.Snapshot, f.Archive, f.Offset = m.Name, w.name, w.size
+			if err := storeFile(w.w, path, f); err != nil {
+				return fmt.Errorf("%s: %w", f.Path, err)
+			}
+			w.size += f.Size
+			m.Stored += f.Size
+			known[f.SHA256] = f
+		}
+		m.Files = append(m.Files, f)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for rule, w := range writers {
+		if err := w.close(); err != nil {
+			return nil, err
+		}
+		delete(writers, rule)
+	}
+
+	slices.SortFunc(m.Files, func(a, b File) int { return strings.Compare(a.Path, b.Path) })
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeManifest(snap, b); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// writeManifest writes the manifest b of the snapshot directory snap. The
+// manifest marks the snapshot complete, so it is written to a temporary file
+// that is synced and then renamed: it exists in full or not at all.
+func writeManifest(snap string, b []byte) (err error) {
+	tmp, err := os.CreateTemp(snap, ManifestName+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+	if _, err := tmp.Write(b); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(snap, ManifestName)); err != nil {
+		return err
+	}
+
+	// Make the rename itself durable, where directories can be synced
+	if d, err := os.Open(snap); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+// archiveWriter writes one archive of a snapshot and its index.
+type archiveWriter struct {
+	name    string
+	archive *os.File
+	index   *os.File
+	w       *openzl.Writer
+	size    int64 // Uncompressed bytes written
+}
+
+// newArchiveWriter creates the archive of the snapshot directory snap for
+// the files matching rule of policy, written with the rule's options.
+func newArchiveWriter(snap string, rule int, policy *openzl.Policy) (*archiveWriter, error) {
+	name := ArchiveName
+	opts := policy.Default
+	if rule >= 0 {
+		name = fmt.Sprintf("data.%d.zl", rule)
+		opts = policy.Rules[rule].Options
+	}
+	a := &archiveWriter{name: name}
+	var err error
+	if a.archive, err = os.Create(filepath.Join(snap, name)); err != nil {
+		return nil, err
+	}
+	if a.index, err = os.Create(filepath.Join(snap, name+openzl.IndexSidecarExt)); err != nil {
+		a.archive.Close()
+		return nil, err
+	}
+	opts = append([]openzl.WriterOption{openzl.WithStoredFallback(true)}, opts...)
+	opts = append(opts, openzl.WithIndexSidecar(a.index))
+	if a.w, err = openzl.NewWriter(a.archive, opts...); err != nil {
+		a.abort()
+		return nil, err
+	}
+	return a, nil
+}
+
+// close completes the archive and its index, and syncs them so that they
+// are on stable storage before the manifest that refers to them.
+func (a *archiveWriter) close() error {
+	err := a.w.Close()
+	if err == nil {
+		err = a.archive.Sync()
+	}
+	if err == nil {
+		err = a.index.Sync()
+	}
+	if cerr := a.index.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := a.archive.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// abort closes the files of an archive that will be removed.
+func (a *archiveWriter) abort() {
+	if a.w != nil {
+		a.w.Close()
+	}
+	a.index.Close()
+	a.archive.Close()
+}
+
+// hashFile returns the hex-encoded SHA-256 of the file at path.
+func hashFile(path string) (string, error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, in); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// storeFile appends the content of the file at path, described by f, to w.
+func storeFile(w io.Writer, path string, f File) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	h := sha256.New()
+	n, err := io.Copy(w, io.TeeReader(io.LimitReader(in, f.Size), h))
+	if err != nil {
+		return err
+	}
+	if n != f.Size || hex.EncodeToString(h.Sum(nil)) != f.SHA256 {
+		return errors.New("file changed during snapshot")
+	}
+	return nil
+}
+
+// List returns the names of the snapshots of the repository root, oldest
+// first. Directories without a manifest, such as those of an interrupted
+// Take, are not snapshots.
+func List(root string) ([]string, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(root, e.Name(), ManifestName)); err == nil {
+			names = append(names, e.Name())
+		}
+	}
+	return names, nil
+}
+
+// Load returns the manifest of the snapshot name of the repository root.
+//
+// Returns ErrNoSnapshot if there is no such snapshot.
+func Load(root, name string) (*Manifest, error) {
+	if !validName(name) {
+		return nil, fmt.Errorf("%w: %q", ErrNoSnapshot, name)
+	}
+	b, err := os.ReadFile(filepath.Join(root, name, ManifestName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNoSnapshot, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	m := &Manifest{}
+	if err := json.Unmarshal(b, m); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if m.Version != ManifestVersion {
+		return nil, fmt.Errorf("%s: manifest version %d, want %d", name, m.Version, ManifestVersion)
+	}
+	return m, nil
+}
+
+// Restore writes every file of the snapshot name of the repository root
+// under the directory dst, with its mode and modification time.
+//
+// Returns ErrNoSnapshot if there is no such snapshot,
+// openzl.ErrChecksumMismatch if a restored file does not match the hash in
+// the manifest, or the error from reading the repository or writing dst.
+func Restore(root, name, dst string) error {
+	m, err := Load(root, name)
+	if err != nil {
+		return err
+	}
+	readers := &archives{root: root}
+	defer readers.Close()
+	for _, f := range m.Files {
+		if !filepath.IsLocal(filepath.FromSlash(f.Path)) {
+			return fmt.Errorf("%w: %s: path %q escapes the destination", openzl.ErrCorruptedData, name, f.Path)
+		}
+		path := filepath.Join(dst, filepath.FromSlash(f.Path))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := restoreFile(readers, f, path); err != nil {
+			return fmt.Errorf("%s: %w", f.Path, err)
+		}
+	}
+	return nil
+}
+
+// restoreFile writes the content of f to a new file at path.
+func restoreFile(readers *archives, f File, path string) (err error) {
+	r, err := readers.open(f)
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+		if err == nil {
+			err = os.Chmod(path, f.Mode)
+		}
+		if err == nil {
+			err = os.Chtimes(path, f.ModTime, f.ModTime)
+		}
+	}()
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(out, h), r); err != nil {
+		return err
+	}
+	if hex.EncodeToString(h.Sum(nil)) != f.SHA256 {
+		return openzl.ErrChecksumMismatch
+	}
+	return nil
+}
+
+// Open returns the content of the file path of the snapshot name of the
+// repository root, read straight from the archive that holds it.
+//
+// Returns ErrNoSnapshot if there is no such snapshot, or fs.ErrNotExist if
+// the snapshot has no such file.
+func Open(root, name, path string) (io.ReadCloser, error) {
+	m, err := Load(root, name)
+	if err != nil {
+		return nil, err
+	}
+	i, ok := slices.BinarySearchFunc(m.Files, path, func(f File, path string) int {
+		return strings.Compare(f.Path, path)
+	})
+	if !ok {
+		return nil, fmt.Errorf("%s: %s: %w", name, path, fs.ErrNotExist)
+	}
+	readers := &archives{root: root}
+	r, err := readers.open(m.Files[i])
+	if err != nil {
+		readers.Close()
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{r, readers}, nil
+}
+
+// archives opens the archives of a repository for random access, once per
+// snapshot.
+type archives struct {
+	root    string
+	files   []*os.File
+	readers map[string]*openzl.IndexedReader
+}
+
+// open returns a reader of the content of f.
+func (a *archives) open(f File) (io.Reader, error) {
+	name := f.Archive
+	if name == "" {
+		name = ArchiveName
+	}
+	if !validName(f.Snapshot) || !validName(name) {
+		return nil, fmt.Errorf("%w: archive %q of snapshot %q is outside the repository", openzl.ErrCorruptedData, name, f.Snapshot)
+	}
+	key := f.Snapshot + "/" + name
+	r, ok := a.readers[key]
+	if !ok {
+		dir := filepath.Join(a.root, f.Snapshot)
+		x, err := openzl.ReadIndexSidecar(filepath.Join(dir, name+openzl.IndexSidecarExt))
+		if err != nil {
+			return nil, err
+		}
+		file, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		a.files = append(a.files, file)
+		r = openzl.NewIndexedReader(file, x)
+		if a.readers == nil {
+			a.readers = map[string]*openzl.IndexedReader{}
+		}
+		a.readers[key] = r
+	}
+	if f.Offset < 0 || f.Size < 0 || f.Offset > r.Size()-f.Size {
+		return nil, fmt.Errorf("%w: content outside archive of %s", openzl.ErrCorruptedData, f.Snapshot)
+	}
+	return io.NewSectionReader(r, f.Offset, f.Size), nil
+}
+
+// validName reports whether name is a single local path element, as the
+// names of snapshots and archives are.
+func validName(name string) bool {
+	return filepath.IsLocal(name) && filepath.Base(name) == name && !strings.Contains(name, "/")
+}
+
+// Close closes the archives.
+func (a *archives) Close() error {
+	for _, f := range a.files {
+		f.Close()
+	}
+	a.files, a.readers = nil, nil
+	return nil
+}
@@ -0,0 +1,260 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/borischu/go-openzl"
+	"github.com/borischu/go-openzl/datagen"
+)
+
+func writeTree(t *testing.T, dir string, files map[string][]byte) {
+	t.Helper()
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func checkTree(t *testing.T, dir string, want map[string][]byte) {
+	t.Helper()
+	got := map[string][]byte{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		got[filepath.ToSlash(rel)], err = os.ReadFile(path)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want) {
+		t.Errorf("restored %d files, want %d", len(got), len(want))
+	}
+	for name, data := range want {
+		if !bytes.Equal(got[name], data) {
+			t.Errorf("restored %s differs", name)
+		}
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	root, dir := t.TempDir(), t.TempDir()
+	logs := datagen.Logs(200 << 10)
+	v1 := map[string][]byte{
+		"app.log":        logs,
+		"config/a.json":  []byte(`{"a":1}`),
+		"config/b.json":  []byte(`{"b":2}`),
+		"empty":          {},
+		"data/random.db": datagen.Random(70 << 10),
+	}
+	writeTree(t, dir, v1)
+
+	m1, err := Take(root, dir)
+	if err != nil {
+		t.Fatalf("Take() failed: %v", err)
+	}
+	if m1.Parent != "" || len(m1.Files) != len(v1) {
+		t.Errorf("first snapshot: parent %q, %d files", m1.Parent, len(m1.Files))
+	}
+
+	// Change one file, add a copy of another, delete a third
+	v2 := map[string][]byte{
+		"app.log":        append(bytes.Clone(logs), "one more line\n"...),
+		"config/a.json":  v1["config/a.json"],
+		"config/copy":    v1["data/random.db"],
+		"empty":          {},
+		"data/random.db": v1["data/random.db"],
+	}
+	writeTree(t, dir, map[string][]byte{"app.log": v2["app.log"], "config/copy": v2["config/copy"]})
+	os.Remove(filepath.Join(dir, "config/b.json"))
+
+	m2, err := Take(root, dir)
+	if err != nil {
+		t.Fatalf("Take() failed: %v", err)
+	}
+	if m2.Parent != m1.Name {
+		t.Errorf("second snapshot parent = %q, want %q", m2.Parent, m1.Name)
+	}
+	// Only the changed log is stored again; the copy points to the first
+	// snapshot's content
+	if m2.Stored != int64(len(v2["app.log"])) {
+		t.Errorf("second snapshot stored %d bytes, want %d", m2.Stored, len(v2["app.log"]))
+	}
+	for _, f := range m2.Files {
+		if f.Path == "config/copy" && f.Snapshot != m1.Name {
+			t.Errorf("copy stored in %q, want shared with %q", f.Snapshot, m1.Name)
+		}
+	}
+
+	names, err := List(root)
+	if err != nil || len(names) != 2 || names[0] != m1.Name || names[1] != m2.Name {
+		t.Fatalf("List() = %v, %v", names, err)
+	}
+
+	for _, c := range []struct {
+		name string
+		want map[string][]byte
+	}{{m1.Name, v1}, {m2.Name, v2}} {
+		dst := t.TempDir()
+		if err := Restore(root, c.name, dst); err != nil {
+			t.Fatalf("Restore(%s) failed: %v", c.name, err)
+		}
+		checkTree(t, dst, c.want)
+	}
+
+	r, err := Open(root, m2.Name, "config/copy")
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	got, err := io.ReadAll(r)
+	r.Close()
+	if err != nil || !bytes.Equal(got, v2["config/copy"]) {
+		t.Errorf("Open(config/copy) read %d bytes, %v", len(got), err)
+	}
+	if _, err := Open(root, m2.Name, "config/b.json"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Open(deleted file) error = %v, want fs.ErrNotExist", err)
+	}
+	if err := Restore(root, "missing", t.TempDir()); !errors.Is(err, ErrNoSnapshot) {
+		t.Errorf("Restore(missing) error = %v, want ErrNoSnapshot", err)
+	}
+}
+
+func TestSnapshotUnchanged(t *testing.T) {
+	root, dir := t.TempDir(), t.TempDir()
+	writeTree(t, dir, map[string][]byte{"a": datagen.Logs(10 << 10)})
+	m1, err := Take(root, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Touching a file without changing it stores nothing
+	later := time.Now().Add(time.Hour)
+	os.Chtimes(filepath.Join(dir, "a"), later, later)
+	m2, err := Take(root, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m2.Stored != 0 || m2.Files[0].Snapshot != m1.Name {
+		t.Errorf("unchanged content stored again: %+v", m2)
+	}
+	if m2.Files[0].ModTime.Equal(m1.Files[0].ModTime) {
+		t.Error("manifest kept the old modification time")
+	}
+}
+
+func TestSnapshotRootInsideDir(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string][]byte{"a": []byte("hello")})
+	root := filepath.Join(dir, ".backup")
+	if _, err := Take(root, dir); err != nil {
+		t.Fatal(err)
+	}
+	m, err := Take(root, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Files) != 1 {
+		t.Errorf("snapshot holds %d files, want only the tree's", len(m.Files))
+	}
+}
+
+func TestSnapshotPolicy(t *testing.T) {
+	root, dir := t.TempDir(), t.TempDir()
+	files := map[string][]byte{
+		"events.json": datagen.Logs(20 << 10),
+		"photo.jpg":   datagen.Random(20 << 10),
+		"other.bin":   datagen.Random(1 << 10),
+	}
+	writeTree(t, dir, files)
+	policy := &openzl.Policy{Rules: []openzl.PolicyRule{
+		{Pattern: "*.json", Options: []openzl.WriterOption{openzl.WithFrameChecksum(true)}},
+		{Pattern: "image/*", Options: []openzl.WriterOption{openzl.WithStoredFallback(true)}},
+	}}
+	m, err := Take(root, dir, WithPolicy(policy))
+	if err != nil {
+		t.Fatalf("Take() failed: %v", err)
+	}
+
+	archives := map[string]string{}
+	for _, f := range m.Files {
+		archives[f.Path] = f.Archive
+	}
+	want := map[string]string{"events.json": "data.0.zl", "photo.jpg": "data.1.zl", "other.bin": ArchiveName}
+	for path, name := range want {
+		if archives[path] != name {
+			t.Errorf("%s stored in %q, want %q", path, archives[path], name)
+		}
+	}
+	b, err := os.ReadFile(filepath.Join(root, m.Name, "data.0.zl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h, err := openzl.ParseFrameHeader(b); err != nil || !h.Checksum {
+		t.Errorf("JSON archive frame header = %+v, %v, want the rule's checksum", h, err)
+	}
+
+	dst := t.TempDir()
+	if err := Restore(root, m.Name, dst); err != nil {
+		t.Fatalf("Restore() failed: %v", err)
+	}
+	checkTree(t, dst, files)
+}
+
+func TestSnapshotCorruptManifest(t *testing.T) {
+	root, dir := t.TempDir(), t.TempDir()
+	writeTree(t, dir, map[string][]byte{"a": []byte("hello")})
+	m, err := Take(root, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A manifest may not point outside the repository
+	for _, f := range []File{
+		{Path: "a", Snapshot: "..", Size: 5},
+		{Path: "a", Snapshot: "../elsewhere", Size: 5},
+		{Path: "a", Snapshot: m.Name, Archive: "../../data.zl", Size: 5},
+	} {
+		bad := *m
+		bad.Files = []File{f}
+		b, _ := json.Marshal(bad)
+		if err := os.WriteFile(filepath.Join(root, m.Name, ManifestName), b, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := Restore(root, m.Name, t.TempDir()); !errors.Is(err, openzl.ErrCorruptedData) {
+			t.Errorf("Restore(%+v) error = %v, want ErrCorruptedData", f, err)
+		}
+	}
+	if _, err := Load(root, "../"+m.Name); !errors.Is(err, ErrNoSnapshot) {
+		t.Errorf("Load(outside) error = %v, want ErrNoSnapshot", err)
+	}
+
+	// No temporary manifest is left behind
+	entries, err := os.ReadDir(filepath.Join(root, m.Name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if strings.HasSuffix(e.Name(), ".tmp") {
+			t.Errorf("temporary file %s left in the snapshot", e.Name())
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// CompressSparse compresses a sparse vector of the given length, given as
+// parallel arrays of the positions of its non-zero entries and their values.
+//
+// Indices must be strictly increasing and within [0, length). They are stored
+// as gaps between consecutive positions, which are small and repetitive for
+// typical feature vectors, and compressed with OpenZL's numeric graph; values
+// are compressed separately with the same graph. Both columns and the vector
+// length are stored in a single buffer, so DecompressSparse needs no
+// parameters.
+//
+// A vector without non-zero entries is valid and compresses to a few bytes.
+//
+// Returns ErrInvalidParameter if indices and values differ in length, if
+// length is negative, or if the indices are out of range or not strictly
+// increasing.
+//
+// Example:
+//
+//	compressed, err := openzl.CompressSparse([]int64{3, 17, 912}, []float64{0.5, 1, -2}, 4096)
+//	...
+//	indices, values, length, err := openzl.DecompressSparse(compressed)
+func CompressSparse(indices []int64, values []float64, length int) ([]byte, error) {
+	if len(indices) != len(values) {
+		return nil, fmt.Errorf("%w: %d indices but %d values", ErrInvalidParameter, len(indices), len(values))
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("%w: negative vector length %d", ErrInvalidParameter, length)
+	}
+	if err := checkSparseIndices(indices, length); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidParameter, err)
+	}
+
+	meta := binary.AppendUvarint(nil, uint64(length))
+	if len(indices) == 0 {
+		return encodeContainer(containerSparse, meta, nil, nil), nil
+	}
+
+	idxFrame, err := CompressNumeric(deltaEncode(indices))
+	if err != nil {
+		return nil, fmt.Errorf("indices: %w", err)
+	}
+	valFrame, err := CompressNumeric(values)
+	if err != nil {
+		return nil, fmt.Errorf("values: %w", err)
+	}
+	return encodeContainer(containerSparse, meta, idxFrame, valFrame), nil
+}
+
+// DecompressSparse restores a sparse vector compressed by CompressSparse,
+// returning the positions and values of its non-zero entries and its length.
+func DecompressSparse(compressed []byte) (indices []int64, values []float64, length int, err error) {
+	if len(compressed) == 0 {
+		return nil, nil, 0, ErrEmptyInput
+	}
+	sections, err := decodeContainer(compressed, containerSparse, 3)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	n, k := binary.Uvarint(sections[0])
+	if k <= 0 || k != len(sections[0]) || n > math.MaxInt {
+		return nil, nil, 0, fmt.Errorf("%w: invalid vector length", ErrCorruptedData)
+	}
+	length = int(n)
+
+	if len(sections[1]) == 0 && len(sections[2]) == 0 {
+		return []int64{}, []float64{}, length, nil
+	}
+
+	deltas, err := DecompressNumeric[int64](sections[1])
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("indices: %w", err)
+	}
+	indices = deltaDecode(deltas)
+
+	values, err = DecompressNumeric[float64](sections[2])
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("values: %w", err)
+	}
+
+	if len(values) != len(indices) {
+		return nil, nil, 0, fmt.Errorf("%w: %d indices but %d values", ErrCorruptedData, len(indices), len(values))
+	}
+	if err := checkSparseIndices(indices, length); err != nil {
+		return nil, nil, 0, fmt.Errorf("%w: %v", ErrCorruptedData, err)
+	}
+	return indices, values, length, nil
+}
+
+// checkSparseIndices verifies that indices are strictly increasing and
+// within [0, length).
+func checkSparseIndices(indices []int64, length int) error {
+	prev := int64(-1)
+	for i, idx := range indices {
+		if idx <= prev {
+			return fmt.Errorf("index %d at position %d is not strictly increasing", idx, i)
+		}
+		if idx >= int64(length) {
+			return fmt.Errorf("index %d at position %d is out of range for length %d", idx, i, length)
+		}
+		prev = idx
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCompressSparse(t *testing.T) {
+	indices := make([]int64, 0, 500)
+	values := make([]float64, 0, 500)
+	for i := 0; i < 500; i++ {
+		indices = append(indices, int64(i*37+i%5))
+		values = append(values, float64(i%7)*0.25)
+	}
+
+	tests := []struct {
+		name    string
+		indices []int64
+		values  []float64
+		length  int
+	}{
+		{"typical", indices, values, 20000},
+		{"single", []int64{41}, []float64{-1.5}, 42},
+		{"all zero", nil, nil, 1 << 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compressed, err := CompressSparse(tt.indices, tt.values, tt.length)
+			if err != nil {
+				t.Fatalf("CompressSparse() failed: %v", err)
+			}
+
+			indices, values, length, err := DecompressSparse(compressed)
+			if err != nil {
+				t.Fatalf("DecompressSparse() failed: %v", err)
+			}
+			if length != tt.length {
+				t.Errorf("length = %d, want %d", length, tt.length)
+			}
+			if len(indices) != len(tt.indices) || len(values) != len(tt.values) {
+				t.Fatalf("got %d indices and %d values, want %d", len(indices), len(values), len(tt.indices))
+			}
+			for i := range tt.indices {
+				if indices[i] != tt.indices[i] || values[i] != tt.values[i] {
+					t.Fatalf("entry %d: got (%d, %v), want (%d, %v)", i, indices[i], values[i], tt.indices[i], tt.values[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCompressSparse_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		indices []int64
+		values  []float64
+		length  int
+	}{
+		{"length mismatch", []int64{1, 2}, []float64{1}, 10},
+		{"negative length", nil, nil, -1},
+		{"unsorted", []int64{5, 3}, []float64{1, 2}, 10},
+		{"duplicate", []int64{3, 3}, []float64{1, 2}, 10},
+		{"negative index", []int64{-1}, []float64{1}, 10},
+		{"out of range", []int64{10}, []float64{1}, 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CompressSparse(tt.indices, tt.values, tt.length)
+			if !errors.Is(err, ErrInvalidParameter) {
+				t.Errorf("CompressSparse() error = %v, want ErrInvalidParameter", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package spec
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Markdown writes the specification as a Markdown document, the FORMAT.md
+// of the repository.
+func Markdown(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	p := func(format string, args ...any) { fmt.Fprintf(bw, format, args...) }
+
+	p("# go-openzl formats\n\n")
+	p("<!-- Generated by `zlgo spec` from package spec. DO NOT EDIT. -->\n\n")
+	p("This document specifies the formats go-openzl writes around OpenZL frames, ")
+	p("revision %d of the stream format. OpenZL frames themselves are specified by the OpenZL project. ", Version)
+	p("All integers are little-endian. A uvarint is an unsigned LEB128 integer, as in Go's encoding/binary.\n\n")
+
+	for _, l := range []Layout{Stream, Frame} {
+		writeLayout(p, l)
+	}
+
+	p("### Frame flags\n\n")
+	p("| Flag | Mask | Since | Description |\n|---|---|---|---|\n")
+	for _, f := range Flags {
+		p("| %s | `%#08x` | %d | %s |\n", f.Name, f.Mask, f.Since, f.Description)
+	}
+	p("\nReaders that predate the stored flag reject frames that set it.\n\n")
+
+	p("### Checksums\n\n")
+	p("Frame checksums are CRC-32C: reversed polynomial `%#08x` (Castagnoli), ", ChecksumPolynomial)
+	p("initial value and final XOR `0xffffffff`, as computed by Go's hash/crc32 and Python's `crc32c` package.\n\n")
+
+	p("### Revisions\n\n")
+	p("| Revision | Changes |\n|---|---|\n")
+	for _, r := range Revisions {
+		p("| %d | %s |\n", r.Version, r.Description)
+	}
+	p("\n")
+
+	writeLayout(p, Container)
+	p("### Container kinds\n\n")
+	p("| Kind | Name | Written by |\n|---|---|---|\n")
+	for _, k := range ContainerKinds {
+		p("| %d | %s | %s |\n", k.Value, k.Name, k.Writer)
+	}
+	p("\n")
+
+	writeLayout(p, Index)
+	p("A sidecar is named after its stream with the extension `%s` appended, as in `archive.zl%s`.\n", IndexExt, IndexExt)
+	return bw.Flush()
+}
+
+// writeLayout writes the section of l.
+func writeLayout(p func(string, ...any), l Layout) {
+	p("## %s\n\n%s\n\n", l.Name, l.Description)
+	p("| Field | Offset | Size | Type | Description |\n|---|---|---|---|---|\n")
+	for _, f := range l.Fields {
+		offset, size := strconv.Itoa(f.Offset), strconv.Itoa(f.Size)
+		if f.Offset < 0 {
+			offset = "follows"
+		}
+		if f.Size == 0 {
+			size = "variable"
+		}
+		p("| %s | %s | %s | %s | %s |\n", f.Name, offset, size, f.Type, f.Description)
+	}
+	p("\n")
+}
@@ -0,0 +1,220 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Package spec describes the formats go-openzl writes around OpenZL frames
+// as data: the Writer stream framing and the container used by the domain
+// helpers. It is the reference for implementations in other languages, such
+// as a Python reader for go-openzl streams, and for tools that check files
+// without linking OpenZL.
+//
+// The package does not use cgo. Package openzl takes its framing constants
+// from here, so the description cannot drift from the implementation.
+// Markdown renders the whole specification, and ValidateStream and
+// ValidateContainer check files against it:
+//
+//	zlgo spec > FORMAT.md
+//	zlgo spec -check archive.zl
+//
+// OpenZL frames themselves, the payloads of both formats, are specified by
+// the OpenZL project.
+package spec
+
+import "hash/crc32"
+
+// Version is the revision of the stream format described by this package;
+// see Revisions.
+const Version = 3
+
+// Stream framing.
+const (
+	// FrameHeaderSize is the size of a frame header in bytes.
+	FrameHeaderSize = 4
+
+	// FrameChecksumSize is the size of the optional checksum trailer in
+	// bytes.
+	FrameChecksumSize = 4
+
+	// FlagChecksum marks a frame whose payload is followed by a CRC32C
+	// trailer.
+	FlagChecksum uint32 = 1 << 31
+
+	// FlagStored marks a frame whose payload is the data itself rather than
+	// an OpenZL frame.
+	FlagStored uint32 = 1 << 30
+
+	// SizeMask extracts the payload size from a frame header.
+	SizeMask uint32 = 1<<30 - 1
+
+	// MaxPayloadSize is the largest payload a frame header can describe.
+	MaxPayloadSize = int(SizeMask)
+
+	// ChecksumPolynomial is the reversed CRC-32 polynomial of frame
+	// checksums: Castagnoli, as in hash/crc32.
+	ChecksumPolynomial = crc32.Castagnoli
+)
+
+// Container framing.
+const (
+	// ContainerMagic starts every container.
+	ContainerMagic = "ZLGC"
+
+	// ContainerHeaderSize is the size of the magic and kind bytes that
+	// start every container.
+	ContainerHeaderSize = len(ContainerMagic) + 1
+)
+
+// Index sidecar framing.
+const (
+	// IndexMagic starts every index sidecar.
+	IndexMagic = "ZLGI"
+
+	// IndexVersion is the version of the index sidecar format.
+	IndexVersion = 1
+
+	// IndexExt is the extension of index sidecars, appended to the name of
+	// the archive they index.
+	IndexExt = ".zli"
+
+	// IndexChecksumSize is the size of the CRC32C that ends every index
+	// sidecar.
+	IndexChecksumSize = 4
+)
+
+// Field is one field of a layout.
+type Field struct {
+	Name        string
+	Offset      int    // From the start of the layout, -1 if it follows a variable-size field
+	Size        int    // In bytes, 0 if variable
+	Type        string // Encoding, such as "uint32 LE" or "uvarint"
+	Description string
+}
+
+// Layout is a sequence of fields.
+type Layout struct {
+	Name        string
+	Description string
+	Fields      []Field
+}
+
+// Flag is a flag bit of a frame header.
+type Flag struct {
+	Name        string
+	Mask        uint32
+	Since       int // Revision that introduced the flag
+	Description string
+}
+
+// Revision is a version of the stream format.
+type Revision struct {
+	Version     int
+	Description string
+}
+
+// ContainerKind identifies the function that wrote a container.
+type ContainerKind struct {
+	Value  byte
+	Name   string
+	Writer string // Function of package openzl that writes it
+}
+
+// Revisions lists the versions of the stream format, oldest first. Each
+// revision only adds header flags, so streams that use none of them are
+// valid under every revision.
+var Revisions = []Revision{
+	{1, "Frames of a header and an OpenZL frame, ended by a zero header."},
+	{2, "Adds FlagChecksum and the CRC32C trailer (openzl.WithFrameChecksum)."},
+	{3, "Adds FlagStored for payloads stored uncompressed (openzl.WithStoredFallback)."},
+}
+
+// Flags lists the flag bits of frame headers, the bits above SizeMask.
+var Flags = []Flag{
+	{"checksum", FlagChecksum, 2, "The payload is followed by a CRC32C trailer."},
+	{"stored", FlagStored, 3, "The payload is the uncompressed data itself, not an OpenZL frame."},
+}
+
+// Stream is the layout of a stream as written by openzl.Writer.
+var Stream = Layout{
+	Name: "Stream",
+	Description: "A stream is a sequence of frames, each decodable on its own, " +
+		"followed by an end-of-stream marker: a frame header of zero. " +
+		"Empty input is an empty stream. Writers always end a stream with the marker; " +
+		"readers may accept a stream that ends on a frame boundary without it. " +
+		"Bytes after the marker are not part of the stream.",
+	Fields: []Field{
+		{"frames", 0, 0, "Frame...", "Zero or more frames."},
+		{"end", -1, FrameHeaderSize, "uint32 LE", "End-of-stream marker: 0. A marker with any flag set is invalid."},
+	},
+}
+
+// Frame is the layout of a stream frame.
+var Frame = Layout{
+	Name:        "Frame",
+	Description: "A frame holds one OpenZL frame, or stored data, of up to MaxPayloadSize bytes.",
+	Fields: []Field{
+		{"header", 0, FrameHeaderSize, "uint32 LE", "Bits 0-29: payload size, not 0. Bits 30-31: flags."},
+		{"payload", FrameHeaderSize, 0, "bytes", "An OpenZL frame, or the data itself if the stored flag is set."},
+		{"checksum", -1, FrameChecksumSize, "uint32 LE", "CRC32C (Castagnoli) of the payload. Present only if the checksum flag is set."},
+	},
+}
+
+// Container is the layout of the container written by the domain helpers of
+// package openzl, such as CompressProfile, and by Compress for inputs above
+// its chunk threshold.
+var Container = Layout{
+	Name: "Container",
+	Description: "A container concatenates sections, usually OpenZL frames compressed separately, " +
+		"with metadata specific to its kind. It must end with its last section.",
+	Fields: []Field{
+		{"magic", 0, len(ContainerMagic), "bytes", `"` + ContainerMagic + `"`},
+		{"kind", len(ContainerMagic), 1, "uint8", "Writer of the container, see the container kinds."},
+		{"count", ContainerHeaderSize, 0, "uvarint", "Number of sections."},
+		{"sections", -1, 0, "(uvarint, bytes)...", "Each section: its length, then its bytes. Sections may be empty."},
+	},
+}
+
+// Index is the layout of an index sidecar, the file IndexExt written next to
+// a stream by openzl.WriteIndexSidecar or a Writer with
+// openzl.WithIndexSidecar.
+var Index = Layout{
+	Name: "Index",
+	Description: "An index sidecar lists the frames of a stream so that readers can seek to any " +
+		"uncompressed offset without scanning it. The offset of a frame in the stream is the sum of " +
+		"the sizes of the frames before it, and likewise for its offset in the uncompressed data. " +
+		"The end-of-stream marker is not listed.",
+	Fields: []Field{
+		{"magic", 0, len(IndexMagic), "bytes", `"` + IndexMagic + `"`},
+		{"version", len(IndexMagic), 1, "uint8", "Index format version: 1."},
+		{"count", len(IndexMagic) + 1, 0, "uvarint", "Number of frames."},
+		{"frames", -1, 0, "(uvarint, uvarint)...", "Each frame: its size in the stream, header and checksum included, then the size of its data."},
+		{"checksum", -1, IndexChecksumSize, "uint32 LE", "CRC32C (Castagnoli) of all the bytes before it."},
+	},
+}
+
+// ContainerKinds lists the kinds of containers in order of their values.
+var ContainerKinds = []ContainerKind{
+	{1, "profile", "CompressProfile"},
+	{2, "uuids", "CompressUUIDs"},
+	{3, "ips", "CompressIPs"},
+	{4, "flows", "CompressFlows"},
+	{5, "ticks", "CompressTicks"},
+	{6, "reads", "CompressReads"},
+	{7, "embeddings", "CompressEmbeddings"},
+	{8, "lossy", "CompressFloatsLossy"},
+	{9, "runs", "Compressor.CompressNumeric with WithRunShortCircuit"},
+	{10, "sparse", "CompressSparse"},
+	{11, "records", "RecordEncoder"},
+	{12, "durations", "CompressDurations"},
+	{13, "counters", "CompressCounters"},
+	{14, "fallback", "FallbackCompressor"},
+	{15, "chunks", "Compress, for inputs above the chunk threshold"},
+}
+
+// Kind returns the container kind of value v, and whether it is known.
+func Kind(v byte) (ContainerKind, bool) {
+	for _, k := range ContainerKinds {
+		if k.Value == v {
+			return k, true
+		}
+	}
+	return ContainerKind{}, false
+}
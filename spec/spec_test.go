@@ -0,0 +1,110 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package spec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"os"
+	"testing"
+)
+
+// appendFrame appends a frame holding payload to b.
+func appendFrame(b []byte, payload []byte, flags uint32) []byte {
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(payload))|flags)
+	b = append(b, payload...)
+	if flags&FlagChecksum != 0 {
+		b = binary.LittleEndian.AppendUint32(b, crc32.Checksum(payload, crc32cTable))
+	}
+	return b
+}
+
+func TestValidateStream(t *testing.T) {
+	var stream []byte
+	stream = appendFrame(stream, []byte("first"), FlagStored)
+	stream = appendFrame(stream, []byte("second"), FlagStored|FlagChecksum)
+	stream = binary.LittleEndian.AppendUint32(stream, 0)
+
+	info, err := ValidateStream(bytes.NewReader(append(stream, "trailing"...)))
+	if err != nil {
+		t.Fatalf("ValidateStream() failed: %v", err)
+	}
+	if !info.Complete || len(info.Frames) != 2 || info.Size != int64(len(stream)) {
+		t.Errorf("ValidateStream() = %+v, want 2 frames in %d bytes, complete", info, len(stream))
+	}
+	if f := info.Frames[1]; f.Offset != 9 || f.PayloadSize != 6 || !f.Checksum || !f.Stored {
+		t.Errorf("frame 1 = %+v", f)
+	}
+
+	if info, err := ValidateStream(bytes.NewReader(nil)); err != nil || info.Complete {
+		t.Errorf("ValidateStream(empty) = %+v, %v, want incomplete and no error", info, err)
+	}
+	if info, err := ValidateStream(bytes.NewReader(stream[:len(stream)-4])); err != nil || info.Complete {
+		t.Errorf("ValidateStream(no end marker) = %+v, %v, want incomplete and no error", info, err)
+	}
+
+	corrupt := bytes.Clone(stream)
+	corrupt[len(corrupt)-6]++ // Last byte of the second payload
+	badMarker := binary.LittleEndian.AppendUint32(bytes.Clone(stream[:len(stream)-4]), FlagStored)
+	for name, b := range map[string][]byte{
+		"bad checksum":       corrupt,
+		"flagged end marker": badMarker,
+		"truncated header":   stream[:2],
+		"truncated payload":  stream[:7],
+		"truncated checksum": stream[:len(stream)-6],
+	} {
+		var ve *ValidationError
+		if _, err := ValidateStream(bytes.NewReader(b)); !errors.As(err, &ve) {
+			t.Errorf("ValidateStream(%s) error = %v, want *ValidationError", name, err)
+		}
+	}
+}
+
+func TestValidateContainer(t *testing.T) {
+	b := append([]byte(ContainerMagic), 15, 2, 3, 'a', 'b', 'c', 0)
+	kind, sections, err := ValidateContainer(b)
+	if err != nil {
+		t.Fatalf("ValidateContainer() failed: %v", err)
+	}
+	if kind.Name != "chunks" || len(sections) != 2 || string(sections[0]) != "abc" || len(sections[1]) != 0 {
+		t.Errorf("ValidateContainer() = %v, %q", kind, sections)
+	}
+
+	for name, bad := range map[string][]byte{
+		"no magic":      []byte("ZLGX\x0f\x00"),
+		"unknown kind":  append([]byte(ContainerMagic), 200, 0),
+		"truncated":     b[:len(b)-2],
+		"trailing data": append(bytes.Clone(b), 0),
+	} {
+		if _, _, err := ValidateContainer(bad); err == nil {
+			t.Errorf("ValidateContainer(%s) succeeded", name)
+		}
+	}
+}
+
+func TestContainerKinds(t *testing.T) {
+	for i, k := range ContainerKinds {
+		if int(k.Value) != i+1 {
+			t.Errorf("ContainerKinds[%d] = %d, want %d", i, k.Value, i+1)
+		}
+	}
+}
+
+// TestMarkdown checks that FORMAT.md is up to date; run make spec to
+// regenerate it.
+func TestMarkdown(t *testing.T) {
+	want, err := os.ReadFile("../FORMAT.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got bytes.Buffer
+	if err := Markdown(&got); err != nil {
+		t.Fatalf("Markdown() failed: %v", err)
+	}
+	if !bytes.Equal(got.Bytes(), want) {
+		t.Error("FORMAT.md is out of date, run make spec")
+	}
+}
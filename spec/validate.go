@@ -0,0 +1,146 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package spec
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+var crc32cTable = crc32.MakeTable(ChecksumPolynomial)
+
+// FrameInfo describes a frame found by ValidateStream.
+type FrameInfo struct {
+	Offset      int64 // Of the frame header in the stream
+	PayloadSize int
+	Checksum    bool
+	Stored      bool
+}
+
+// StreamInfo describes a stream checked by ValidateStream.
+type StreamInfo struct {
+	Frames []FrameInfo
+
+	// Complete reports whether the stream ends with its end-of-stream
+	// marker. Readers may accept a stream without it, but writers never
+	// produce one.
+	Complete bool
+
+	// Size is the size of the stream in bytes, end marker included.
+	Size int64
+}
+
+// ValidationError reports where a file violates the specification.
+type ValidationError struct {
+	Offset int64 // Of the offending header or section
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("spec: at offset %d: %s", e.Offset, e.Reason)
+}
+
+// ValidateStream reads a stream from r and checks its framing: every frame
+// header, every checksum, and the end-of-stream marker. Payloads are not
+// decompressed, so an OpenZL frame damaged in a frame without checksum
+// passes. Reading stops at the end marker.
+//
+// It returns the frames found, even on error, and a *ValidationError for the
+// first violation, or the error of r.
+func ValidateStream(r io.Reader) (*StreamInfo, error) {
+	br := bufio.NewReader(r)
+	info := &StreamInfo{}
+	var header [FrameHeaderSize]byte
+	for {
+		n, err := io.ReadFull(br, header[:])
+		switch {
+		case err == io.EOF:
+			return info, nil
+		case err == io.ErrUnexpectedEOF:
+			return info, &ValidationError{info.Size, fmt.Sprintf("truncated frame header of %d bytes", n)}
+		case err != nil:
+			return info, err
+		}
+		v := binary.LittleEndian.Uint32(header[:])
+		size, flags := int(v&SizeMask), v&^SizeMask
+		if size == 0 {
+			if flags != 0 {
+				return info, &ValidationError{info.Size, fmt.Sprintf("end-of-stream marker with flags %#x", flags)}
+			}
+			info.Size += FrameHeaderSize
+			info.Complete = true
+			return info, nil
+		}
+
+		f := FrameInfo{Offset: info.Size, PayloadSize: size, Checksum: flags&FlagChecksum != 0, Stored: flags&FlagStored != 0}
+		crc := crc32.New(crc32cTable)
+		if _, err := io.CopyN(crc, br, int64(size)); err != nil {
+			if err == io.EOF {
+				return info, &ValidationError{f.Offset, fmt.Sprintf("truncated payload of %d bytes", size)}
+			}
+			return info, err
+		}
+		if f.Checksum {
+			var trailer [FrameChecksumSize]byte
+			if _, err := io.ReadFull(br, trailer[:]); err != nil {
+				if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+					return info, &ValidationError{f.Offset, "truncated checksum"}
+				}
+				return info, err
+			}
+			if got, want := binary.LittleEndian.Uint32(trailer[:]), crc.Sum32(); got != want {
+				return info, &ValidationError{f.Offset, fmt.Sprintf("checksum %#08x, payload has %#08x", got, want)}
+			}
+		}
+		info.Frames = append(info.Frames, f)
+		info.Size += int64(frameSize(f))
+	}
+}
+
+// frameSize returns the size of f in the stream.
+func frameSize(f FrameInfo) int {
+	n := FrameHeaderSize + f.PayloadSize
+	if f.Checksum {
+		n += FrameChecksumSize
+	}
+	return n
+}
+
+// ValidateContainer checks that b is exactly one container of a known kind
+// and returns its kind and sections. The sections are not decoded.
+func ValidateContainer(b []byte) (ContainerKind, [][]byte, error) {
+	if len(b) < ContainerHeaderSize || string(b[:len(ContainerMagic)]) != ContainerMagic {
+		return ContainerKind{}, nil, &ValidationError{0, "missing container magic " + ContainerMagic}
+	}
+	kind, ok := Kind(b[len(ContainerMagic)])
+	if !ok {
+		return ContainerKind{}, nil, &ValidationError{int64(len(ContainerMagic)), fmt.Sprintf("unknown container kind %d", b[len(ContainerMagic)])}
+	}
+
+	off := ContainerHeaderSize
+	count, k := binary.Uvarint(b[off:])
+	// Every section takes at least one byte for its length
+	if k <= 0 || count > uint64(len(b)-off-k) {
+		return kind, nil, &ValidationError{int64(off), "invalid section count"}
+	}
+	off += k
+	sections := make([][]byte, count)
+	for i := range sections {
+		size, k := binary.Uvarint(b[off:])
+		if k <= 0 || size > uint64(len(b)-off-k) {
+			return kind, nil, &ValidationError{int64(off), fmt.Sprintf("truncated section %d", i)}
+		}
+		off += k
+		sections[i] = b[off : off+int(size) : off+int(size)]
+		off += int(size)
+	}
+	if off != len(b) {
+		return kind, nil, &ValidationError{int64(off), fmt.Sprintf("%d trailing bytes after the last section", len(b)-off)}
+	}
+	return kind, sections, nil
+}
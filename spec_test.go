@@ -0,0 +1,53 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/borischu/go-openzl/datagen"
+	"github.com/borischu/go-openzl/spec"
+)
+
+func TestSpecConformance(t *testing.T) {
+	var stream bytes.Buffer
+	w, err := NewWriter(&stream, WithFrameSize(MinFrameSize), WithFrameChecksum(true), WithStoredFallback(true))
+	if err != nil {
+		t.Fatalf("NewWriter() failed: %v", err)
+	}
+	w.Write(datagen.Text(3 * MinFrameSize))
+	w.Write(datagen.Random(MinFrameSize))
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	info, err := spec.ValidateStream(bytes.NewReader(stream.Bytes()))
+	if err != nil {
+		t.Fatalf("ValidateStream() failed: %v", err)
+	}
+	if !info.Complete || len(info.Frames) != 4 || info.Size != int64(stream.Len()) {
+		t.Errorf("ValidateStream() = %d frames in %d bytes, complete %v, want 4 in %d", len(info.Frames), info.Size, info.Complete, stream.Len())
+	}
+	if f := info.Frames[3]; !f.Stored || !f.Checksum {
+		t.Errorf("random frame = %+v, want stored with checksum", f)
+	}
+
+	container, err := CompressProfile(datagen.Text(10000))
+	if err != nil {
+		t.Fatalf("CompressProfile() failed: %v", err)
+	}
+	if kind, _, err := spec.ValidateContainer(container); err != nil || kind.Value != byte(containerProfile) {
+		t.Errorf("ValidateContainer(CompressProfile()) = %v, %v", kind, err)
+	}
+
+	// Every kind of the package is in the specification
+	for k := containerProfile; k <= containerChunks; k++ {
+		if _, ok := spec.Kind(byte(k)); !ok {
+			t.Errorf("container kind %d missing from spec.ContainerKinds", k)
+		}
+	}
+	if n := len(spec.ContainerKinds); n != int(containerChunks) {
+		t.Errorf("spec.ContainerKinds has %d kinds, package has %d", n, containerChunks)
+	}
+}
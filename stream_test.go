@@ -5,9 +5,12 @@ package openzl
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"errors"
 	"io"
 	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 func TestWriterReader_Simple(t *testing.T) {
@@ -213,6 +216,72 @@ func TestWriter_EmptyWrite(t *testing.T) {
 	}
 }
 
+func TestWriter_EmptyPolicy(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := NewWriter(&buf, WithEmptyPolicy(EmptyNoOutput))
+	if err != nil {
+		t.Fatalf("NewWriter() failed: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Compressed size = %d, want 0", buf.Len())
+	}
+
+	// Empty input reads back as an empty stream
+	reader, err := NewReader(&buf)
+	if err != nil {
+		t.Fatalf("NewReader() failed: %v", err)
+	}
+	defer reader.Close()
+	if data, err := io.ReadAll(reader); err != nil || len(data) != 0 {
+		t.Errorf("ReadAll() = %q, %v; want empty, nil", data, err)
+	}
+
+	// Streams with data still get an end marker
+	if err := writer.Reset(&buf); err != nil {
+		t.Fatalf("Reset() failed: %v", err)
+	}
+	writer.Write([]byte("data"))
+	writer.Close()
+	if frames := splitFrames(t, buf.Bytes()); len(frames) != 1 {
+		t.Errorf("got %d frames, want 1", len(frames))
+	}
+
+	if _, err := NewWriter(&buf, WithEmptyPolicy(EmptyPolicy(99))); err == nil {
+		t.Error("NewWriter() with unknown empty policy succeeded")
+	}
+}
+
+func TestWriter_FlushOnEmptyWrite(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := NewWriter(&buf, WithFlushOnEmptyWrite(true))
+	if err != nil {
+		t.Fatalf("NewWriter() failed: %v", err)
+	}
+
+	// Nothing buffered: no frame
+	writer.Write(nil)
+	if buf.Len() != 0 {
+		t.Errorf("empty Write() with empty buffer wrote %d bytes", buf.Len())
+	}
+
+	writer.Write([]byte("first"))
+	writer.Write(nil)
+	if buf.Len() == 0 {
+		t.Error("empty Write() did not flush buffered data")
+	}
+	writer.Write([]byte("second"))
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	if frames := splitFrames(t, buf.Bytes()); len(frames) != 2 {
+		t.Errorf("got %d frames, want 2", len(frames))
+	}
+}
+
 func TestWriter_FrameSize(t *testing.T) {
 	original := bytes.Repeat([]byte("test"), 1000)
 
@@ -417,3 +486,881 @@ func TestWriterReader_NilReader(t *testing.T) {
 		t.Errorf("NewReader(nil) succeeded, want error")
 	}
 }
+
+func TestWriter_LongWindow(t *testing.T) {
+	// Two copies of the same block placed further apart than MaxFrameSize
+	block := bytes.Repeat([]byte("long-range redundancy "), 8*1024)
+	filler := make([]byte, 2*MaxFrameSize)
+	for i := range filler {
+		filler[i] = byte(i * 7)
+	}
+	original := append(append(append([]byte{}, block...), filler...), block...)
+
+	var buf bytes.Buffer
+	writer, err := NewWriter(&buf, WithLongWindow(4*MaxFrameSize))
+	if err != nil {
+		t.Fatalf("NewWriter() failed: %v", err)
+	}
+	if _, err := writer.Write(original); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	reader, err := NewReader(&buf)
+	if err != nil {
+		t.Fatalf("NewReader() failed: %v", err)
+	}
+	defer reader.Close()
+
+	decompressed, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll() failed: %v", err)
+	}
+	if !bytes.Equal(decompressed, original) {
+		t.Errorf("Decompressed data mismatch")
+	}
+}
+
+func TestWriter_LongWindowInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		window int
+	}{
+		{"Below MaxFrameSize", MaxFrameSize - 1},
+		{"Above MaxLongWindowSize", MaxLongWindowSize + 1},
+		{"Negative", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if _, err := NewWriter(&buf, WithLongWindow(tt.window)); err == nil {
+				t.Errorf("NewWriter() succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestWriter_ContentHash(t *testing.T) {
+	original := bytes.Repeat([]byte("hash me please "), 10000)
+	want := sha256.Sum256(original)
+
+	var buf bytes.Buffer
+	writer, err := NewWriter(&buf, WithContentHash(sha256.New()))
+	if err != nil {
+		t.Fatalf("NewWriter() failed: %v", err)
+	}
+
+	if _, err := writer.Write(original); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if sum := writer.ContentSum(); sum != nil {
+		t.Errorf("ContentSum() before Close = %x, want nil", sum)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	if got := writer.ContentSum(); !bytes.Equal(got, want[:]) {
+		t.Errorf("ContentSum() = %x, want %x", got, want)
+	}
+
+	// Reset starts a fresh digest for the next stream
+	var buf2 bytes.Buffer
+	if err := writer.Reset(&buf2); err != nil {
+		t.Fatalf("Reset() failed: %v", err)
+	}
+	writer.Write([]byte("second"))
+	writer.Close()
+
+	want2 := sha256.Sum256([]byte("second"))
+	if got := writer.ContentSum(); !bytes.Equal(got, want2[:]) {
+		t.Errorf("ContentSum() after Reset = %x, want %x", got, want2)
+	}
+}
+
+// gatedWriter blocks every Write until gate is closed.
+type gatedWriter struct {
+	gate chan struct{}
+	buf  bytes.Buffer
+}
+
+func (g *gatedWriter) Write(p []byte) (int, error) {
+	<-g.gate
+	return g.buf.Write(p)
+}
+
+func TestWriter_MaxInFlight(t *testing.T) {
+	gw := &gatedWriter{gate: make(chan struct{})}
+	writer, err := NewWriter(gw, WithFrameSize(MinFrameSize), WithMaxInFlight(1))
+	if err != nil {
+		t.Fatalf("NewWriter() failed: %v", err)
+	}
+
+	// The first frame is queued; the next full frame cannot be flushed
+	// because the destination has not accepted anything yet.
+	original := bytes.Repeat([]byte("back-pressure "), 4*MinFrameSize/14)
+	n, err := writer.TryWrite(original)
+	if err != ErrWouldBlock {
+		t.Fatalf("TryWrite() error = %v, want ErrWouldBlock", err)
+	}
+	if n == 0 || n == len(original) {
+		t.Fatalf("TryWrite() accepted %d of %d bytes, want partial", n, len(original))
+	}
+
+	// Release the destination; Write then blocks only as long as needed.
+	close(gw.gate)
+	if _, err := writer.Write(original[n:]); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	reader, err := NewReader(&gw.buf)
+	if err != nil {
+		t.Fatalf("NewReader() failed: %v", err)
+	}
+	defer reader.Close()
+
+	decompressed, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll() failed: %v", err)
+	}
+	if !bytes.Equal(decompressed, original) {
+		t.Errorf("Decompressed data mismatch")
+	}
+}
+
+func TestWriter_TryWriteWithoutMaxInFlight(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := NewWriter(&buf)
+	if err != nil {
+		t.Fatalf("NewWriter() failed: %v", err)
+	}
+	defer writer.Close()
+
+	if _, err := writer.TryWrite([]byte("data")); err == nil {
+		t.Error("TryWrite() without WithMaxInFlight succeeded, want error")
+	}
+	if writer.Ready() != nil {
+		t.Error("Ready() without WithMaxInFlight should be nil")
+	}
+}
+
+func TestWriterReader_FrameChecksum(t *testing.T) {
+	original := bytes.Repeat([]byte("checksummed frame "), 10000)
+
+	var buf bytes.Buffer
+	writer, err := NewWriter(&buf, WithFrameChecksum(true))
+	if err != nil {
+		t.Fatalf("NewWriter() failed: %v", err)
+	}
+	if _, err := writer.Write(original); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	stream := buf.Bytes()
+
+	reader, err := NewReader(bytes.NewReader(stream))
+	if err != nil {
+		t.Fatalf("NewReader() failed: %v", err)
+	}
+	decompressed, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		t.Fatalf("ReadAll() failed: %v", err)
+	}
+	if !bytes.Equal(decompressed, original) {
+		t.Fatalf("Decompressed data mismatch")
+	}
+
+	// Flip one payload bit in the first frame
+	corrupted := bytes.Clone(stream)
+	corrupted[FrameHeaderSize+2] ^= 0x01
+
+	reader, err = NewReader(bytes.NewReader(corrupted))
+	if err != nil {
+		t.Fatalf("NewReader() failed: %v", err)
+	}
+	defer reader.Close()
+
+	if _, err := io.ReadAll(reader); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("expected ErrChecksumMismatch, got: %v", err)
+	}
+}
+
+func TestReader_BareFrames(t *testing.T) {
+	original := bytes.Repeat([]byte("one-shot output "), 1000)
+
+	frame, err := Compress(original)
+	if err != nil {
+		t.Fatalf("Compress() failed: %v", err)
+	}
+
+	reader, err := NewReader(bytes.NewReader(frame), WithBareFrames(true))
+	if err != nil {
+		t.Fatalf("NewReader() failed: %v", err)
+	}
+	defer reader.Close()
+
+	decompressed, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll() failed: %v", err)
+	}
+	if !bytes.Equal(decompressed, original) {
+		t.Errorf("Decompressed data mismatch")
+	}
+
+	// Writer streams are still read normally in auto mode
+	var buf bytes.Buffer
+	writer, _ := NewWriter(&buf)
+	writer.Write(original)
+	writer.Close()
+
+	if err := reader.Reset(&buf); err != nil {
+		t.Fatalf("Reset() failed: %v", err)
+	}
+	decompressed, err = io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll() after Reset failed: %v", err)
+	}
+	if !bytes.Equal(decompressed, original) {
+		t.Errorf("Decompressed stream mismatch")
+	}
+}
+
+// splitFrames returns the payloads of all frames in a Writer stream.
+func splitFrames(t *testing.T, stream []byte) [][]byte {
+	t.Helper()
+	var frames [][]byte
+	for len(stream) >= FrameHeaderSize {
+		size, _ := parseFrameHeader(stream)
+		if size == 0 {
+			return frames
+		}
+		frames = append(frames, stream[FrameHeaderSize:FrameHeaderSize+size])
+		stream = stream[FrameHeaderSize+size:]
+	}
+	t.Fatal("stream has no end marker")
+	return nil
+}
+
+func TestWriter_ContentDefinedChunking(t *testing.T) {
+	// Pseudo-random content so that boundaries depend on the data
+	content := make([]byte, 512*1024)
+	state := uint32(1)
+	for i := range content {
+		state = state*1664525 + 1013904223
+		content[i] = byte(state >> 24)
+	}
+
+	compressStream := func(data []byte) []byte {
+		var buf bytes.Buffer
+		writer, err := NewWriter(&buf, WithContentDefinedChunking(16*1024))
+		if err != nil {
+			t.Fatalf("NewWriter() failed: %v", err)
+		}
+		if _, err := writer.Write(data); err != nil {
+			t.Fatalf("Write() failed: %v", err)
+		}
+		if err := writer.Close(); err != nil {
+			t.Fatalf("Close() failed: %v", err)
+		}
+		return buf.Bytes()
+	}
+
+	// The same content with a few bytes inserted at the front
+	shifted := append([]byte("inserted"), content...)
+	a := compressStream(content)
+	b := compressStream(shifted)
+
+	seen := make(map[string]bool)
+	for _, f := range splitFrames(t, a) {
+		seen[string(f)] = true
+	}
+	framesB := splitFrames(t, b)
+	shared := 0
+	for _, f := range framesB {
+		if seen[string(f)] {
+			shared++
+		}
+	}
+	if shared < len(framesB)/2 {
+		t.Errorf("only %d of %d frames shared after insertion", shared, len(framesB))
+	}
+
+	// Round-trip still works
+	reader, err := NewReader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("NewReader() failed: %v", err)
+	}
+	defer reader.Close()
+	decompressed, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll() failed: %v", err)
+	}
+	if !bytes.Equal(decompressed, shifted) {
+		t.Errorf("Decompressed data mismatch")
+	}
+}
+
+func TestWriter_ContentDefinedChunkingInvalid(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := NewWriter(&buf, WithContentDefinedChunking(3000)); err == nil {
+		t.Error("non power-of-two average accepted, want error")
+	}
+	if _, err := NewWriter(&buf, WithFrameSize(MinFrameSize), WithContentDefinedChunking(64*1024)); err == nil {
+		t.Error("average larger than frame size accepted, want error")
+	}
+}
+
+func TestWriter_ResetPreservesOptions(t *testing.T) {
+	var buf1 bytes.Buffer
+	writer, err := NewWriter(&buf1,
+		WithFrameSize(MinFrameSize),
+		WithCompressorOptions(WithStageReport(true)),
+	)
+	if err != nil {
+		t.Fatalf("NewWriter() failed: %v", err)
+	}
+	if _, err := writer.Write([]byte("first stream")); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	// Reset after Close must recreate the compressor with the same options
+	var buf2 bytes.Buffer
+	if err := writer.Reset(&buf2); err != nil {
+		t.Fatalf("Reset() failed: %v", err)
+	}
+	if !writer.compressor.cfg.stageReport {
+		t.Error("Reset() dropped compressor options")
+	}
+	data := bytes.Repeat([]byte("0123456789abcdef"), MinFrameSize/8)
+	if _, err := writer.Write(data); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if frames := splitFrames(t, buf2.Bytes()); len(frames) != 2 {
+		t.Errorf("got %d frames, want 2 with frame size %d", len(frames), MinFrameSize)
+	}
+}
+
+func TestWriter_ResetWithOptions(t *testing.T) {
+	var buf1 bytes.Buffer
+	writer, err := NewWriter(&buf1, WithFrameSize(MinFrameSize))
+	if err != nil {
+		t.Fatalf("NewWriter() failed: %v", err)
+	}
+	if _, err := writer.Write([]byte("first stream")); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+
+	// Invalid options leave the Writer usable with its old configuration
+	if err := writer.ResetWithOptions(&bytes.Buffer{}, WithFrameSize(1)); err == nil {
+		t.Fatal("ResetWithOptions() with invalid frame size succeeded")
+	}
+	if writer.frameSize != MinFrameSize {
+		t.Errorf("frame size changed to %d after failed ResetWithOptions()", writer.frameSize)
+	}
+
+	var buf2 bytes.Buffer
+	if err := writer.ResetWithOptions(&buf2, WithFrameSize(2*MinFrameSize)); err != nil {
+		t.Fatalf("ResetWithOptions() failed: %v", err)
+	}
+	data := bytes.Repeat([]byte("0123456789abcdef"), MinFrameSize/8)
+	if _, err := writer.Write(data); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if frames := splitFrames(t, buf2.Bytes()); len(frames) != 1 {
+		t.Errorf("got %d frames, want 1 with frame size %d", len(frames), 2*MinFrameSize)
+	}
+
+	reader, err := NewReader(&buf2)
+	if err != nil {
+		t.Fatalf("NewReader() failed: %v", err)
+	}
+	defer reader.Close()
+	got, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll() failed: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Error("decompressed data does not match")
+	}
+}
+
+func TestReader_StrictReset(t *testing.T) {
+	var buf bytes.Buffer
+	writer, _ := NewWriter(&buf)
+	writer.Write([]byte("some data that is not read to the end"))
+	writer.Close()
+	stream := buf.Bytes()
+
+	reader, err := NewReader(bytes.NewReader(stream), WithStrictReset(true))
+	if err != nil {
+		t.Fatalf("NewReader() failed: %v", err)
+	}
+	defer reader.Close()
+
+	// A stream never read from is not unconsumed
+	if err := reader.Reset(bytes.NewReader(stream)); err != nil {
+		t.Fatalf("Reset() before any Read() failed: %v", err)
+	}
+
+	// Partially read stream
+	p := make([]byte, 4)
+	if _, err := reader.Read(p); err != nil {
+		t.Fatalf("Read() failed: %v", err)
+	}
+	if err := reader.Reset(bytes.NewReader(stream)); !errors.Is(err, ErrUnconsumedData) {
+		t.Fatalf("Reset() error = %v, want ErrUnconsumedData", err)
+	}
+
+	// Reader is unchanged and still usable after the failed Reset
+	if _, err := reader.Read(p); err != nil {
+		t.Fatalf("Read() after failed Reset() failed: %v", err)
+	}
+
+	reader.Discard()
+	if n, err := reader.Read(p); n != 0 || err != io.EOF {
+		t.Errorf("Read() after Discard() = %d, %v; want 0, io.EOF", n, err)
+	}
+	if err := reader.Reset(bytes.NewReader(stream)); err != nil {
+		t.Fatalf("Reset() after Discard() failed: %v", err)
+	}
+
+	// Fully consumed stream resets without error
+	if _, err := io.ReadAll(reader); err != nil {
+		t.Fatalf("ReadAll() failed: %v", err)
+	}
+	if err := reader.Reset(bytes.NewReader(stream)); err != nil {
+		t.Fatalf("Reset() after full read failed: %v", err)
+	}
+
+	// Neither is a partially read stream whose Reader was closed
+	if _, err := reader.Read(p); err != nil {
+		t.Fatalf("Read() failed: %v", err)
+	}
+	reader.Close()
+	if err := reader.Reset(bytes.NewReader(stream)); err != nil {
+		t.Fatalf("Reset() after Close() failed: %v", err)
+	}
+}
+
+func TestParseFrameHeader(t *testing.T) {
+	var buf bytes.Buffer
+	writer, _ := NewWriter(&buf, WithFrameSize(MinFrameSize), WithFrameChecksum(true))
+	writer.Write(bytes.Repeat([]byte("frame header parsing "), 1000))
+	writer.Close()
+	stream := buf.Bytes()
+
+	// Walk the stream using only the public framing helpers
+	frames := 0
+	off := 0
+	for {
+		h, err := ParseFrameHeader(stream[off:])
+		if err != nil {
+			t.Fatalf("ParseFrameHeader() at %d failed: %v", off, err)
+		}
+		off += h.FrameSize()
+		if h.EndOfStream() {
+			break
+		}
+		if !h.Checksum {
+			t.Errorf("frame %d: Checksum = false, want true", frames)
+		}
+		frames++
+	}
+	if off != len(stream) {
+		t.Errorf("walked %d bytes, stream has %d", off, len(stream))
+	}
+	if frames < 2 {
+		t.Errorf("got %d frames, want at least 2", frames)
+	}
+
+	if _, err := ParseFrameHeader(stream[:FrameHeaderSize-1]); err != io.ErrUnexpectedEOF {
+		t.Errorf("short header error = %v, want io.ErrUnexpectedEOF", err)
+	}
+	flaggedEnd := []byte{0, 0, 0, 0x40}
+	if _, err := ParseFrameHeader(flaggedEnd); !errors.Is(err, ErrCorruptedData) {
+		t.Errorf("flagged end marker error = %v, want ErrCorruptedData", err)
+	}
+}
+
+func TestWriterReader_StoredFallback(t *testing.T) {
+	// Random data does not compress, so every frame should be stored raw
+	data := make([]byte, 3*MinFrameSize)
+	state := uint64(42)
+	for i := range data {
+		state = state*6364136223846793005 + 1442695040888963407
+		data[i] = byte(state >> 56)
+	}
+
+	var buf bytes.Buffer
+	writer, err := NewWriter(&buf, WithFrameSize(MinFrameSize), WithStoredFallback(true), WithFrameChecksum(true))
+	if err != nil {
+		t.Fatalf("NewWriter() failed: %v", err)
+	}
+	if _, err := writer.Write(data); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	stream := buf.Bytes()
+	frames := 0
+	for off := 0; ; {
+		h, err := ParseFrameHeader(stream[off:])
+		if err != nil {
+			t.Fatalf("ParseFrameHeader() failed: %v", err)
+		}
+		if h.EndOfStream() {
+			break
+		}
+		if !h.Stored {
+			t.Errorf("frame %d not stored", frames)
+		}
+		off += h.FrameSize()
+		frames++
+	}
+	maxSize := len(data) + frames*(FrameHeaderSize+FrameChecksumSize) + FrameHeaderSize
+	if len(stream) > maxSize {
+		t.Errorf("stream size %d exceeds input plus framing (%d)", len(stream), maxSize)
+	}
+
+	reader, err := NewReader(bytes.NewReader(stream))
+	if err != nil {
+		t.Fatalf("NewReader() failed: %v", err)
+	}
+	defer reader.Close()
+	got, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll() failed: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Error("decompressed data does not match")
+	}
+}
+
+func TestCompressStream(t *testing.T) {
+	data := bytes.Repeat([]byte("one-shot streaming transfer "), 50000)
+
+	var out bytes.Buffer
+	written, err := CompressStream(&out, bytes.NewReader(data), WithFrameChecksum(true))
+	if err != nil {
+		t.Fatalf("CompressStream() failed: %v", err)
+	}
+	if written != int64(out.Len()) {
+		t.Errorf("CompressStream() = %d, wrote %d bytes", written, out.Len())
+	}
+
+	compressed, err := CompressFrom(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("CompressFrom() failed: %v", err)
+	}
+
+	for name, stream := range map[string][]byte{"CompressStream": out.Bytes(), "CompressFrom": compressed} {
+		reader, err := NewReader(bytes.NewReader(stream))
+		if err != nil {
+			t.Fatalf("%s: NewReader() failed: %v", name, err)
+		}
+		got, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("%s: ReadAll() failed: %v", name, err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("%s: round trip returned %d bytes, want %d", name, len(got), len(data))
+		}
+	}
+
+	readErr := errors.New("read failed")
+	if _, err := CompressFrom(io.MultiReader(bytes.NewReader(data), iotest.ErrReader(readErr))); !errors.Is(err, readErr) {
+		t.Errorf("CompressFrom() error = %v, want read error", err)
+	}
+
+	// A failed source leaves a stream that does not look complete
+	out.Reset()
+	if _, err := CompressStream(&out, io.MultiReader(bytes.NewReader(data), iotest.ErrReader(readErr)), WithFrameSize(MinFrameSize)); !errors.Is(err, readErr) {
+		t.Fatalf("CompressStream() error = %v, want read error", err)
+	}
+	checkUnterminated(t, out.Bytes())
+}
+
+// checkUnterminated checks that stream holds frames but no end marker.
+func checkUnterminated(t *testing.T, stream []byte) {
+	t.Helper()
+	if len(stream) == 0 {
+		t.Fatal("no frames written before the error")
+	}
+	reader, err := NewReader(bytes.NewReader(stream), WithAllowTruncated(true))
+	if err != nil {
+		t.Fatalf("NewReader() failed: %v", err)
+	}
+	defer reader.Close()
+	if _, err := io.ReadAll(reader); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("ReadAll(abandoned stream) error = %v, want io.ErrUnexpectedEOF", err)
+	}
+}
+
+// shortWriter violates the io.Writer contract by accepting at most max bytes
+// per call without returning an error.
+type shortWriter struct {
+	buf bytes.Buffer
+	max int
+}
+
+func (s *shortWriter) Write(p []byte) (int, error) {
+	return s.buf.Write(p[:min(len(p), s.max)])
+}
+
+func TestWriter_ShortWrites(t *testing.T) {
+	data := bytes.Repeat([]byte("short writes must not truncate frames "), 5000)
+
+	sw := &shortWriter{max: 7}
+	writer, err := NewWriter(sw, WithFrameSize(MinFrameSize), WithFrameChecksum(true))
+	if err != nil {
+		t.Fatalf("NewWriter() failed: %v", err)
+	}
+	if _, err := writer.Write(data); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	var out bytes.Buffer
+	if _, err := DecompressTo(&out, sw.buf.Bytes()); err != nil {
+		t.Fatalf("DecompressTo() failed: %v", err)
+	}
+	if !bytes.Equal(out.Bytes(), data) {
+		t.Errorf("round trip returned %d bytes, want %d", out.Len(), len(data))
+	}
+
+	// A writer making no progress fails instead of looping forever
+	writer, _ = NewWriter(&shortWriter{max: 0})
+	writer.Write(data[:100])
+	if err := writer.Close(); !errors.Is(err, io.ErrShortWrite) {
+		t.Errorf("Close() error = %v, want io.ErrShortWrite", err)
+	}
+}
+
+func TestWriter_StickyErrorAfterPartialFrame(t *testing.T) {
+	data := bytes.Repeat([]byte("partially written frame "), 5000)
+
+	// Fail in the middle of the first frame's payload
+	fw := &failingWriter{failAfter: FrameHeaderSize + 10}
+	writer, err := NewWriter(fw, WithFrameSize(MinFrameSize))
+	if err != nil {
+		t.Fatalf("NewWriter() failed: %v", err)
+	}
+
+	_, err = writer.Write(data)
+	if err == nil {
+		t.Fatal("Write() succeeded on a failing destination")
+	}
+	if _, err2 := writer.Write([]byte("more")); err2 == nil || err2.Error() != err.Error() {
+		t.Errorf("second Write() error = %v, want sticky %v", err2, err)
+	}
+
+	written := fw.written
+	if err2 := writer.Close(); err2 == nil || err2.Error() != err.Error() {
+		t.Errorf("Close() error = %v, want sticky %v", err2, err)
+	}
+	if fw.written != written {
+		t.Errorf("Close() wrote %d more bytes after the failure", fw.written-written)
+	}
+}
+
+func TestWriter_MaxCompressedFrameSize(t *testing.T) {
+	// Random data does not compress, so frames must be split to fit
+	data := make([]byte, 5*MinFrameSize)
+	state := uint64(7)
+	for i := range data {
+		state = state*6364136223846793005 + 1442695040888963407
+		data[i] = byte(state >> 56)
+	}
+
+	const limit = 1500
+	for _, opts := range [][]WriterOption{
+		{WithFrameSize(MinFrameSize)},
+		{WithFrameSize(MinFrameSize), WithFrameChecksum(true), WithStoredFallback(true)},
+	} {
+		var buf bytes.Buffer
+		opts = append(opts, WithMaxCompressedFrameSize(limit))
+		writer, err := NewWriter(&buf, opts...)
+		if err != nil {
+			t.Fatalf("NewWriter() failed: %v", err)
+		}
+		if _, err := writer.Write(data); err != nil {
+			t.Fatalf("Write() failed: %v", err)
+		}
+		if err := writer.Close(); err != nil {
+			t.Fatalf("Close() failed: %v", err)
+		}
+
+		stream := buf.Bytes()
+		frames := 0
+		for off := 0; ; {
+			h, err := ParseFrameHeader(stream[off:])
+			if err != nil {
+				t.Fatalf("ParseFrameHeader() failed: %v", err)
+			}
+			if h.EndOfStream() {
+				break
+			}
+			if h.FrameSize() > limit {
+				t.Errorf("frame %d is %d bytes, exceeding the %d-byte limit", frames, h.FrameSize(), limit)
+			}
+			off += h.FrameSize()
+			frames++
+		}
+		if frames <= len(data)/MinFrameSize {
+			t.Errorf("got %d frames, want frames to be split", frames)
+		}
+
+		reader, err := NewReader(bytes.NewReader(stream))
+		if err != nil {
+			t.Fatalf("NewReader() failed: %v", err)
+		}
+		got, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			t.Fatalf("ReadAll() failed: %v", err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Error("decompressed data does not match")
+		}
+	}
+
+	if _, err := NewWriter(io.Discard, WithMaxCompressedFrameSize(100)); err == nil {
+		t.Error("NewWriter() accepted a 100-byte frame limit")
+	}
+}
+
+func TestReader_AllowTruncated(t *testing.T) {
+	data := bytes.Repeat([]byte("crash recovery of a partially written archive\n"), 400)
+	var buf bytes.Buffer
+	writer, err := NewWriter(&buf, WithFrameSize(MinFrameSize), WithFrameChecksum(true))
+	if err != nil {
+		t.Fatalf("NewWriter() failed: %v", err)
+	}
+	writer.Write(data)
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	stream := buf.Bytes()
+
+	// Offsets of the first two frame boundaries
+	first, _ := ParseFrameHeader(stream)
+	second, _ := ParseFrameHeader(stream[first.FrameSize():])
+	boundary := first.FrameSize() + second.FrameSize()
+
+	tests := []struct {
+		name      string
+		cut       int
+		recovered int
+	}{
+		{"inside payload", boundary + 10, 2 * MinFrameSize},
+		{"inside header", boundary + 2, 2 * MinFrameSize},
+		{"missing end marker", len(stream) - FrameHeaderSize, len(data)},
+		{"inside first frame", first.FrameSize() - 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader, err := NewReader(bytes.NewReader(stream[:tt.cut]), WithAllowTruncated(true))
+			if err != nil {
+				t.Fatalf("NewReader() failed: %v", err)
+			}
+			defer reader.Close()
+			got, err := io.ReadAll(reader)
+			var trunc *TruncatedError
+			if !errors.As(err, &trunc) || !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Fatalf("ReadAll() error = %v, want *TruncatedError", err)
+			}
+			if trunc.Recovered != int64(tt.recovered) || !bytes.Equal(got, data[:tt.recovered]) {
+				t.Errorf("recovered %d bytes (reported %d), want %d", len(got), trunc.Recovered, tt.recovered)
+			}
+		})
+	}
+
+	// Complete and empty streams read normally
+	for _, input := range [][]byte{stream, nil} {
+		reader, _ := NewReader(bytes.NewReader(input), WithAllowTruncated(true))
+		if _, err := io.ReadAll(reader); err != nil {
+			t.Errorf("ReadAll() of %d-byte stream failed: %v", len(input), err)
+		}
+		reader.Close()
+	}
+}
+
+func TestTranscode(t *testing.T) {
+	data := bytes.Repeat([]byte("historical data re-encoded with new settings\n"), 500)
+	stream, err := CompressFrom(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("CompressFrom() failed: %v", err)
+	}
+	frame, err := Compress(data)
+	if err != nil {
+		t.Fatalf("Compress() failed: %v", err)
+	}
+
+	for name, input := range map[string][]byte{"stream": stream, "frame": frame} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			written, err := Transcode(&buf, bytes.NewReader(input), WithFrameSize(MinFrameSize), WithFrameChecksum(true))
+			if err != nil {
+				t.Fatalf("Transcode() failed: %v", err)
+			}
+			if written != int64(buf.Len()) {
+				t.Errorf("Transcode() = %d, wrote %d bytes", written, buf.Len())
+			}
+
+			h, err := ParseFrameHeader(buf.Bytes())
+			if err != nil || !h.Checksum {
+				t.Errorf("first frame header = %+v, %v, want a checksum", h, err)
+			}
+			var got bytes.Buffer
+			if _, err := DecompressTo(&got, buf.Bytes()); err != nil {
+				t.Fatalf("DecompressTo() failed: %v", err)
+			}
+			if !bytes.Equal(got.Bytes(), data) {
+				t.Error("transcoded data does not match")
+			}
+		})
+	}
+
+	// History lost to a truncated source must not look like a whole archive
+	long, err := CompressFrom(bytes.NewReader(bytes.Repeat(data, 20)), WithFrameSize(MinFrameSize))
+	if err != nil {
+		t.Fatalf("CompressFrom() failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if _, err := Transcode(&buf, bytes.NewReader(long[:len(long)/2]), WithFrameSize(MinFrameSize)); err == nil {
+		t.Error("Transcode() of a truncated stream succeeded")
+	}
+	checkUnterminated(t, buf.Bytes())
+
+	// Including when the cut falls on a frame boundary
+	buf.Reset()
+	if !bytes.HasSuffix(long, []byte{0, 0, 0, 0}) {
+		t.Fatal("stream does not end with its end marker")
+	}
+	if _, err := Transcode(&buf, bytes.NewReader(long[:len(long)-FrameHeaderSize]), WithFrameSize(MinFrameSize)); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Transcode() of a stream without end marker error = %v, want io.ErrUnexpectedEOF", err)
+	}
+	checkUnterminated(t, buf.Bytes())
+}
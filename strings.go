@@ -0,0 +1,115 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/borischu/go-openzl/internal/cgo"
+)
+
+// CompressStrings compresses an array of strings using OpenZL's string
+// typed input.
+//
+// OpenZL sees the strings and their lengths separately, rather than one
+// buffer with the boundaries lost, so columns of tokens, dictionary-style
+// values, or other repetitive strings compress better than with Compress of
+// their concatenation. Empty strings are allowed.
+//
+// Example:
+//
+//	compressed, err := openzl.CompressStrings([]string{"GET", "POST", "GET", "GET"})
+//	...
+//	methods, err := openzl.DecompressStrings(compressed)
+//
+// Returns ErrEmptyInput if strs is empty, an error wrapping
+// errors.ErrUnsupported if the linked library cannot compress strings
+// (Features().String is false), or ErrInvalidParameter if a string is 4GB
+// or larger.
+func CompressStrings(strs []string) ([]byte, error) {
+	if len(strs) == 0 {
+		return nil, ErrEmptyInput
+	}
+	if !Features().String {
+		return nil, fmt.Errorf("string compression: %w", errors.ErrUnsupported)
+	}
+
+	size := 0
+	for _, s := range strs {
+		if uint64(len(s)) > math.MaxUint32 {
+			return nil, fmt.Errorf("%w: string of %d bytes", ErrInvalidParameter, len(s))
+		}
+		size += len(s)
+	}
+	content := make([]byte, 0, size)
+	lengths := make([]uint32, len(strs))
+	for i, s := range strs {
+		content = append(content, s...)
+		lengths[i] = uint32(len(s))
+	}
+
+	tref, err := cgo.NewTypedRefString(content, lengths)
+	if err != nil {
+		return nil, fmt.Errorf("create typed ref: %w", err)
+	}
+	defer tref.Free()
+
+	ctx, err := newCCtx()
+	if err != nil {
+		return nil, fmt.Errorf("create context: %w", err)
+	}
+	defer ctx.Free()
+
+	// Typed compression may need more space than CompressBound for raw
+	// bytes, and the lengths are compressed too
+	dstSize, err := compressBound(size+4*len(strs), 2)
+	if err != nil {
+		return nil, err
+	}
+	dst := make([]byte, dstSize)
+	n, err := ctx.CompressMultiTypedRef(dst, []*cgo.TypedRef{tref})
+	if err != nil {
+		return nil, fmt.Errorf("compress typed: %w", err)
+	}
+	return dst[:n], nil
+}
+
+// DecompressStrings restores strings compressed by CompressStrings.
+//
+// Returns ErrEmptyInput if compressed is empty, or an error if it is not a
+// frame of strings or decompression fails.
+func DecompressStrings(compressed []byte) ([]string, error) {
+	if len(compressed) == 0 {
+		return nil, ErrEmptyInput
+	}
+
+	ctx, err := cgo.NewDCtx()
+	if err != nil {
+		return nil, fmt.Errorf("create context: %w", err)
+	}
+	defer ctx.Free()
+
+	content, lengths, err := ctx.DecompressStrings(compressed)
+	if err != nil {
+		return nil, fmt.Errorf("decompress strings: %w", diagnoseFrameError(compressed, err))
+	}
+
+	// One allocation holds every string; they share it as substrings
+	all := string(content)
+	strs := make([]string, len(lengths))
+	off := 0
+	for i, n := range lengths {
+		if uint64(n) > uint64(len(all)-off) {
+			return nil, fmt.Errorf("%w: string lengths exceed the content", ErrCorruptedData)
+		}
+		strs[i] = all[off : off+int(n)]
+		off += int(n)
+	}
+	if off != len(all) {
+		return nil, fmt.Errorf("%w: string lengths do not match the content", ErrCorruptedData)
+	}
+	return strs, nil
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestCompressStrings(t *testing.T) {
+	if !Features().String {
+		if _, err := CompressStrings([]string{"a"}); !errors.Is(err, errors.ErrUnsupported) {
+			t.Errorf("CompressStrings() without string support = %v, want errors.ErrUnsupported", err)
+		}
+		t.Skip("linked library cannot compress strings")
+	}
+
+	tokens := make([]string, 2000)
+	for i := range tokens {
+		tokens[i] = fmt.Sprintf("user-%d", i%50)
+	}
+	tests := []struct {
+		name string
+		strs []string
+	}{
+		{"tokens", tokens},
+		{"single", []string{"hello"}},
+		{"with empty", []string{"", "a", "", "bc", ""}},
+		{"all empty", []string{"", "", ""}},
+		{"binary", []string{"\x00\xff", "é", "\n"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compressed, err := CompressStrings(tt.strs)
+			if err != nil {
+				t.Fatalf("CompressStrings() failed: %v", err)
+			}
+			got, err := DecompressStrings(compressed)
+			if err != nil {
+				t.Fatalf("DecompressStrings() failed: %v", err)
+			}
+			if !slices.Equal(got, tt.strs) {
+				t.Errorf("round trip mismatch: got %q", got)
+			}
+		})
+	}
+}
+
+func TestCompressStrings_Invalid(t *testing.T) {
+	if _, err := CompressStrings(nil); !errors.Is(err, ErrEmptyInput) {
+		t.Errorf("CompressStrings(nil) error = %v, want ErrEmptyInput", err)
+	}
+	if _, err := DecompressStrings(nil); !errors.Is(err, ErrEmptyInput) {
+		t.Errorf("DecompressStrings(nil) error = %v, want ErrEmptyInput", err)
+	}
+	numeric, err := CompressNumeric([]int32{1, 2, 3})
+	if err != nil {
+		t.Fatalf("CompressNumeric() failed: %v", err)
+	}
+	if _, err := DecompressStrings(numeric); err == nil {
+		t.Error("DecompressStrings(numeric frame) succeeded")
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"fmt"
+	"io"
+)
+
+// TeeWriter writes data unchanged to one destination and as a compressed
+// stream to another, in a single pass.
+//
+// Pipelines that keep both an uncompressed working copy and a compressed
+// archive would otherwise have to buffer the data or read it twice.
+//
+// Example:
+//
+//	raw, _ := os.Create("events.jsonl")
+//	archive, _ := os.Create("events.jsonl.zl")
+//	tee, err := openzl.NewTeeWriter(raw, archive)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	io.Copy(tee, events)
+//	tee.Close()
+type TeeWriter struct {
+	raw io.Writer // Receives the data unchanged
+	zw  *Writer   // Compresses the data into the compressed destination
+}
+
+// NewTeeWriter returns a TeeWriter that writes to raw directly and to
+// compressed through a Writer configured with opts.
+//
+// Close must be called to finish the compressed stream. It does not close
+// either destination.
+//
+// Returns an error if any of the options is invalid.
+func NewTeeWriter(raw, compressed io.Writer, opts ...WriterOption) (*TeeWriter, error) {
+	zw, err := NewWriter(compressed, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &TeeWriter{raw: raw, zw: zw}, nil
+}
+
+// Write writes p to the raw destination and then to the compressed stream.
+//
+// If the raw write fails, nothing is passed to the compressed stream, so both
+// destinations hold the same prefix of the data.
+func (t *TeeWriter) Write(p []byte) (int, error) {
+	n, err := t.raw.Write(p)
+	if err == nil && n < len(p) {
+		err = io.ErrShortWrite
+	}
+	if err != nil {
+		return n, fmt.Errorf("write raw: %w", err)
+	}
+	if _, err := t.zw.Write(p); err != nil {
+		return n, fmt.Errorf("write compressed: %w", err)
+	}
+	return n, nil
+}
+
+// Close flushes and finishes the compressed stream.
+func (t *TeeWriter) Close() error {
+	return t.zw.Close()
+}
+
+// Ensure TeeWriter implements io.WriteCloser
+var _ io.WriteCloser = (*TeeWriter)(nil)
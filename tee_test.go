@@ -0,0 +1,58 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestTeeWriter(t *testing.T) {
+	data := bytes.Repeat([]byte("raw copy and archive in one pass "), 20000)
+
+	var raw, compressed bytes.Buffer
+	tee, err := NewTeeWriter(&raw, &compressed, WithFrameSize(MinFrameSize))
+	if err != nil {
+		t.Fatalf("NewTeeWriter() failed: %v", err)
+	}
+	if _, err := io.Copy(tee, bytes.NewReader(data)); err != nil {
+		t.Fatalf("Copy() failed: %v", err)
+	}
+	if err := tee.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	if !bytes.Equal(raw.Bytes(), data) {
+		t.Errorf("raw copy has %d bytes, want %d", raw.Len(), len(data))
+	}
+	var out bytes.Buffer
+	if _, err := DecompressTo(&out, compressed.Bytes()); err != nil {
+		t.Fatalf("DecompressTo() failed: %v", err)
+	}
+	if !bytes.Equal(out.Bytes(), data) {
+		t.Errorf("archive decompressed to %d bytes, want %d", out.Len(), len(data))
+	}
+}
+
+func TestTeeWriter_RawError(t *testing.T) {
+	var compressed bytes.Buffer
+	tee, err := NewTeeWriter(&failingWriter{}, &compressed)
+	if err != nil {
+		t.Fatalf("NewTeeWriter() failed: %v", err)
+	}
+	if _, err := tee.Write([]byte("lost")); err == nil || !strings.HasPrefix(err.Error(), "write raw") {
+		t.Errorf("Write() error = %v, want raw write error", err)
+	}
+	tee.Close()
+
+	out, err := CompressFrom(bytes.NewReader(nil))
+	if err != nil {
+		t.Fatalf("CompressFrom() failed: %v", err)
+	}
+	if !bytes.Equal(compressed.Bytes(), out) {
+		t.Error("compressed stream received data the raw destination rejected")
+	}
+}
@@ -0,0 +1,467 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// TenantLimits bounds the resources one tenant of a TenantRegistry may use.
+// Zero values mean no limit.
+type TenantLimits struct {
+	// MemoryBudget bounds the bytes of the tenant's operations in flight,
+	// counting each operation's input and its worst-case output. An
+	// operation that would exceed it fails with ErrMemoryBudgetExceeded
+	// rather than waiting, so a tenant over its budget is throttled without
+	// delaying others.
+	MemoryBudget int64 `json:"memory_budget,omitempty" yaml:"memory_budget,omitempty"`
+
+	// MaxConcurrency bounds the tenant's operations running at the same
+	// time, and therefore its native contexts. Further operations wait for
+	// a running one to finish.
+	MaxConcurrency int `json:"max_concurrency,omitempty" yaml:"max_concurrency,omitempty"`
+}
+
+// TenantConfig is the profile and limits of one tenant of a TenantRegistry.
+type TenantConfig struct {
+	// Profile configures the tenant's Compressors.
+	Profile CompressorConfig `json:"profile,omitzero" yaml:"profile,omitempty"`
+
+	// Limits bounds the tenant's resources.
+	Limits TenantLimits `json:"limits,omitzero" yaml:"limits,omitempty"`
+}
+
+// TenantStats is a snapshot of the activity of one tenant.
+type TenantStats struct {
+	// Operations is the number of operations run to completion,
+	// successfully or not. Rejected operations are not included.
+	Operations int64
+
+	// Rejected is the number of operations refused by the memory budget.
+	Rejected int64
+
+	// InFlightBytes is the memory currently reserved by running operations,
+	// as counted against MemoryBudget.
+	InFlightBytes int64
+}
+
+// DefaultMaxDefaultTenants is the number of tenants a registry serves with
+// the WithDefaultTenant configuration at once, unless set with
+// WithMaxDefaultTenants.
+const DefaultMaxDefaultTenants = 1024
+
+// TenantRegistryOption configures a TenantRegistry.
+type TenantRegistryOption func(*TenantRegistry) error
+
+// WithDefaultTenant makes the registry serve tenants that were never
+// registered with SetTenant, each with its own copy of cfg, instead of
+// rejecting them. Limits therefore apply to each such tenant separately.
+// How many such tenants are kept is bounded by WithMaxDefaultTenants.
+func WithDefaultTenant(cfg TenantConfig) TenantRegistryOption {
+	return func(r *TenantRegistry) error {
+		if err := cfg.validate(); err != nil {
+			return err
+		}
+		r.defaultCfg = &cfg
+		return nil
+	}
+}
+
+// WithMaxDefaultTenants bounds the tenants served with the WithDefaultTenant
+// configuration at once, so that a stream of distinct tenant keys cannot
+// accumulate state and contexts without limit. When a new key would exceed
+// n, the least recently used of these tenants with no operation running is
+// forgotten, along with its contexts and statistics; if all are busy, the
+// call fails with ErrQuotaExceeded. Tenants registered with SetTenant are
+// neither counted nor evicted. Defaults to DefaultMaxDefaultTenants.
+func WithMaxDefaultTenants(n int) TenantRegistryOption {
+	return func(r *TenantRegistry) error {
+		if n < 1 {
+			return rangeError("WithMaxDefaultTenants", n, 1, math.MaxInt64)
+		}
+		r.maxDefaults = n
+		return nil
+	}
+}
+
+// validate checks the limits of cfg.
+func (cfg TenantConfig) validate() error {
+	if cfg.Limits.MemoryBudget < 0 {
+		return &OptionError{Option: "TenantLimits.MemoryBudget", Value: cfg.Limits.MemoryBudget, Allowed: "0 or more"}
+	}
+	if cfg.Limits.MaxConcurrency < 0 {
+		return &OptionError{Option: "TenantLimits.MaxConcurrency", Value: cfg.Limits.MaxConcurrency, Allowed: "0 or more"}
+	}
+	return nil
+}
+
+// TenantRegistry selects the compression profile and resource limits of
+// each call by a tenant key, for multi-tenant ingestion services.
+//
+// Every tenant has its own Compressors, created from its profile and reused
+// across its calls, its own concurrency limit, and its own memory budget,
+// so that one tenant sending large or many payloads cannot exhaust the
+// contexts or memory the others rely on.
+//
+// A TenantRegistry is safe for concurrent use.
+type TenantRegistry struct {
+	defaultCfg  *TenantConfig
+	maxDefaults int
+	clock       atomic.Int64 // Orders the uses of default tenants
+
+	mu       sync.RWMutex
+	tenants  map[string]*tenantState
+	defaults int // Tenants in tenants created from defaultCfg
+	closed   bool
+}
+
+// tenantState holds the contexts and accounting of one tenant.
+type tenantState struct {
+	cfg      TenantConfig
+	sem      chan struct{} // One element per running operation, nil for no limit
+	inFlight atomic.Int64
+	ops      atomic.Int64
+	rejected atomic.Int64
+	active   atomic.Int64 // Operations between begin and release
+	lastUsed atomic.Int64 // Registry clock at the last lookup
+	implicit bool         // Created from the default configuration
+
+	mu      sync.Mutex
+	idle    []*Compressor
+	maxIdle int
+	retired bool // Replaced or closed: contexts are closed instead of kept
+}
+
+// NewTenantRegistry creates an empty TenantRegistry.
+//
+// Example:
+//
+//	tenants, _ := openzl.NewTenantRegistry()
+//	defer tenants.Close()
+//	tenants.SetTenant("acme", openzl.TenantConfig{
+//		Profile: openzl.CompressorConfig{RunShortCircuit: true},
+//		Limits:  openzl.TenantLimits{MemoryBudget: 256 << 20, MaxConcurrency: 4},
+//	})
+//	...
+//	compressed, err := tenants.CompressForTenant(req.Tenant, req.Body)
+//	if errors.Is(err, openzl.ErrMemoryBudgetExceeded) {
+//		// Ask the tenant to retry later
+//	}
+func NewTenantRegistry(opts ...TenantRegistryOption) (*TenantRegistry, error) {
+	r := &TenantRegistry{tenants: make(map[string]*tenantState), maxDefaults: DefaultMaxDefaultTenants}
+	for _, opt := range opts {
+		if err := opt(r); err != nil {
+			return nil, fmt.Errorf("apply option: %w", err)
+		}
+	}
+	return r, nil
+}
+
+// newTenantState creates the state of a tenant configured by cfg.
+func newTenantState(cfg TenantConfig) *tenantState {
+	t := &tenantState{cfg: cfg, maxIdle: cfg.Limits.MaxConcurrency}
+	if cfg.Limits.MaxConcurrency > 0 {
+		t.sem = make(chan struct{}, cfg.Limits.MaxConcurrency)
+	} else {
+		t.maxIdle = runtime.GOMAXPROCS(0)
+	}
+	return t
+}
+
+// SetTenant registers tenant with cfg, or replaces its configuration.
+// Operations already running finish with the previous configuration, and
+// its contexts are released as they finish.
+//
+// Returns an *OptionError if a limit is negative, or ErrContextClosed after
+// Close.
+func (r *TenantRegistry) SetTenant(tenant string, cfg TenantConfig) error {
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return ErrContextClosed
+	}
+	old := r.tenants[tenant]
+	if old != nil && old.implicit {
+		r.defaults--
+	}
+	r.tenants[tenant] = newTenantState(cfg)
+	r.mu.Unlock()
+
+	if old != nil {
+		old.retire()
+	}
+	return nil
+}
+
+// RemoveTenant unregisters tenant. Later calls for it are rejected, or
+// served with a fresh state if WithDefaultTenant is set.
+func (r *TenantRegistry) RemoveTenant(tenant string) {
+	r.mu.Lock()
+	old := r.tenants[tenant]
+	if old != nil && old.implicit {
+		r.defaults--
+	}
+	delete(r.tenants, tenant)
+	r.mu.Unlock()
+
+	if old != nil {
+		old.retire()
+	}
+}
+
+// tenant returns the state of tenant, creating it from the default
+// configuration if needed, and reserves it for one operation: the caller
+// must call t.done when the operation finishes.
+func (r *TenantRegistry) tenant(tenant string) (*tenantState, error) {
+	r.mu.RLock()
+	t, ok := r.tenants[tenant]
+	if ok {
+		t.acquire(r.clock.Add(1))
+	}
+	closed := r.closed
+	r.mu.RUnlock()
+	switch {
+	case closed:
+		if ok {
+			t.done()
+		}
+		return nil, ErrContextClosed
+	case ok:
+		return t, nil
+	case r.defaultCfg == nil:
+		return nil, fmt.Errorf("%w: unknown tenant %q", ErrInvalidParameter, tenant)
+	}
+
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil, ErrContextClosed
+	}
+	var evicted *tenantState
+	if t, ok = r.tenants[tenant]; !ok {
+		if r.defaults >= r.maxDefaults {
+			if evicted = r.evictDefault(); evicted == nil {
+				r.mu.Unlock()
+				return nil, fmt.Errorf("%w: %d default tenants busy", ErrQuotaExceeded, r.defaults)
+			}
+		}
+		t = newTenantState(*r.defaultCfg)
+		t.implicit = true
+		r.tenants[tenant] = t
+		r.defaults++
+	}
+	t.acquire(r.clock.Add(1))
+	r.mu.Unlock()
+
+	if evicted != nil {
+		evicted.retire()
+	}
+	return t, nil
+}
+
+// evictDefault removes the least recently used default tenant with no
+// operation running, and returns it, or nil if there is none. The caller
+// must hold r.mu and retire the tenant.
+func (r *TenantRegistry) evictDefault() *tenantState {
+	var (
+		key    string
+		oldest *tenantState
+	)
+	for k, t := range r.tenants {
+		if !t.implicit || t.active.Load() > 0 {
+			continue
+		}
+		if oldest == nil || t.lastUsed.Load() < oldest.lastUsed.Load() {
+			key, oldest = k, t
+		}
+	}
+	if oldest != nil {
+		delete(r.tenants, key)
+		r.defaults--
+	}
+	return oldest
+}
+
+// CompressForTenant compresses data with the profile of tenant, within its
+// limits.
+//
+// Returns ErrInvalidParameter for an unknown tenant without
+// WithDefaultTenant, ErrQuotaExceeded if a new default tenant cannot be
+// admitted under WithMaxDefaultTenants, ErrMemoryBudgetExceeded if the
+// operation does not fit in the tenant's remaining budget, ErrEmptyInput if
+// data is empty, or the compression error.
+func (r *TenantRegistry) CompressForTenant(tenant string, data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, ErrEmptyInput
+	}
+	t, err := r.tenant(tenant)
+	if err != nil {
+		return nil, err
+	}
+	defer t.done()
+	bound, err := compressBound(len(data), 1)
+	if err != nil {
+		return nil, err
+	}
+	release, err := t.begin(int64(len(data)) + int64(bound))
+	if err != nil {
+		return nil, fmt.Errorf("tenant %q: %w", tenant, err)
+	}
+	defer release()
+
+	c, err := t.compressor()
+	if err != nil {
+		return nil, err
+	}
+	defer t.putCompressor(c)
+	return c.Compress(data)
+}
+
+// DecompressForTenant decompresses data, counting the operation against the
+// limits of tenant. The decompressed size is read from the frame header
+// and reserved in the budget before any output is allocated.
+//
+// Returns the same errors as CompressForTenant, or the decompression error.
+func (r *TenantRegistry) DecompressForTenant(tenant string, data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, ErrEmptyInput
+	}
+	t, err := r.tenant(tenant)
+	if err != nil {
+		return nil, err
+	}
+	defer t.done()
+	size, err := DecompressedSize(data)
+	if err != nil {
+		return nil, err
+	}
+	release, err := t.begin(int64(len(data)) + size)
+	if err != nil {
+		return nil, fmt.Errorf("tenant %q: %w", tenant, err)
+	}
+	defer release()
+	return Decompress(data)
+}
+
+// TenantStats returns the activity of tenant, and whether it is registered.
+func (r *TenantRegistry) TenantStats(tenant string) (TenantStats, bool) {
+	r.mu.RLock()
+	t, ok := r.tenants[tenant]
+	r.mu.RUnlock()
+	if !ok {
+		return TenantStats{}, false
+	}
+	return TenantStats{
+		Operations:    t.ops.Load(),
+		Rejected:      t.rejected.Load(),
+		InFlightBytes: t.inFlight.Load(),
+	}, true
+}
+
+// Close releases the contexts of all tenants. Operations already running
+// finish normally; later ones fail with ErrContextClosed. Calling Close more
+// than once has no effect.
+func (r *TenantRegistry) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	tenants := r.tenants
+	r.tenants = nil
+	r.mu.Unlock()
+
+	for _, t := range tenants {
+		t.retire()
+	}
+	return nil
+}
+
+// acquire marks an operation of the tenant as started at time now, so that
+// it is not evicted while the operation runs.
+func (t *tenantState) acquire(now int64) {
+	t.active.Add(1)
+	t.lastUsed.Store(now)
+}
+
+// done marks an operation started with acquire as finished.
+func (t *tenantState) done() {
+	t.active.Add(-1)
+}
+
+// begin reserves n bytes of the memory budget and a concurrency slot, and
+// returns the function that gives them back.
+func (t *tenantState) begin(n int64) (func(), error) {
+	if budget := t.cfg.Limits.MemoryBudget; budget > 0 {
+		if t.inFlight.Add(n) > budget {
+			t.inFlight.Add(-n)
+			t.rejected.Add(1)
+			return nil, fmt.Errorf("%w: operation needs %d bytes, budget is %d", ErrMemoryBudgetExceeded, n, budget)
+		}
+	} else {
+		t.inFlight.Add(n)
+	}
+	if t.sem != nil {
+		t.sem <- struct{}{}
+	}
+	return func() {
+		if t.sem != nil {
+			<-t.sem
+		}
+		t.inFlight.Add(-n)
+		t.ops.Add(1)
+	}, nil
+}
+
+// compressor returns an idle Compressor of the tenant, or creates one.
+func (t *tenantState) compressor() (*Compressor, error) {
+	t.mu.Lock()
+	if n := len(t.idle); n > 0 {
+		c := t.idle[n-1]
+		t.idle = t.idle[:n-1]
+		t.mu.Unlock()
+		return c, nil
+	}
+	t.mu.Unlock()
+
+	c, err := NewCompressorFromConfig(t.cfg.Profile)
+	if err != nil {
+		return nil, fmt.Errorf("create compressor: %w", err)
+	}
+	return c, nil
+}
+
+// putCompressor keeps c for reuse, or closes it if enough are idle or the
+// tenant was retired.
+func (t *tenantState) putCompressor(c *Compressor) {
+	t.mu.Lock()
+	if !t.retired && len(t.idle) < t.maxIdle {
+		t.idle = append(t.idle, c)
+		t.mu.Unlock()
+		return
+	}
+	t.mu.Unlock()
+	c.Close()
+}
+
+// retire closes the idle Compressors of the tenant and makes running
+// operations close theirs when they finish.
+func (t *tenantState) retire() {
+	t.mu.Lock()
+	idle := t.idle
+	t.idle = nil
+	t.retired = true
+	t.mu.Unlock()
+	for _, c := range idle {
+		c.Close()
+	}
+}
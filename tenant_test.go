@@ -0,0 +1,147 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestTenantRegistry(t *testing.T) {
+	r, err := NewTenantRegistry()
+	if err != nil {
+		t.Fatalf("NewTenantRegistry() failed: %v", err)
+	}
+	defer r.Close()
+
+	small := bytes.Repeat([]byte("tenant payload "), 10)
+	large := bytes.Repeat([]byte("tenant payload "), 1000)
+	if err := r.SetTenant("acme", TenantConfig{Limits: TenantLimits{MaxConcurrency: 2}}); err != nil {
+		t.Fatalf("SetTenant(acme) failed: %v", err)
+	}
+	if err := r.SetTenant("tiny", TenantConfig{Limits: TenantLimits{MemoryBudget: 1024}}); err != nil {
+		t.Fatalf("SetTenant(tiny) failed: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			compressed, err := r.CompressForTenant("acme", large)
+			if err != nil {
+				t.Errorf("CompressForTenant(acme) failed: %v", err)
+				return
+			}
+			if got, err := r.DecompressForTenant("acme", compressed); err != nil || !bytes.Equal(got, large) {
+				t.Errorf("DecompressForTenant(acme) = %d bytes, %v", len(got), err)
+			}
+		}()
+	}
+	wg.Wait()
+	if st, _ := r.TenantStats("acme"); st.Operations != 16 || st.InFlightBytes != 0 {
+		t.Errorf("TenantStats(acme) = %+v, want 16 operations and nothing in flight", st)
+	}
+
+	// The budget of one tenant does not affect another
+	if _, err := r.CompressForTenant("tiny", small); err != nil {
+		t.Errorf("CompressForTenant(tiny, small) failed: %v", err)
+	}
+	if _, err := r.CompressForTenant("tiny", large); !errors.Is(err, ErrMemoryBudgetExceeded) {
+		t.Errorf("CompressForTenant(tiny, large) error = %v, want ErrMemoryBudgetExceeded", err)
+	}
+	if _, err := r.CompressForTenant("acme", large); err != nil {
+		t.Errorf("CompressForTenant(acme) after tiny's rejection failed: %v", err)
+	}
+	if st, _ := r.TenantStats("tiny"); st.Rejected != 1 || st.Operations != 1 {
+		t.Errorf("TenantStats(tiny) = %+v, want 1 operation and 1 rejection", st)
+	}
+
+	if _, err := r.CompressForTenant("unknown", small); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("CompressForTenant(unknown) error = %v, want ErrInvalidParameter", err)
+	}
+	if err := r.SetTenant("bad", TenantConfig{Limits: TenantLimits{MemoryBudget: -1}}); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("SetTenant(negative budget) error = %v, want ErrInvalidParameter", err)
+	}
+	r.RemoveTenant("acme")
+	if _, ok := r.TenantStats("acme"); ok {
+		t.Error("TenantStats(acme) found a removed tenant")
+	}
+
+	r.Close()
+	if _, err := r.CompressForTenant("tiny", small); !errors.Is(err, ErrContextClosed) {
+		t.Errorf("CompressForTenant() after Close error = %v, want ErrContextClosed", err)
+	}
+}
+
+func TestTenantRegistryDefault(t *testing.T) {
+	r, err := NewTenantRegistry(WithDefaultTenant(TenantConfig{Limits: TenantLimits{MemoryBudget: 1 << 20}}))
+	if err != nil {
+		t.Fatalf("NewTenantRegistry() failed: %v", err)
+	}
+	defer r.Close()
+
+	for _, tenant := range []string{"a", "b"} {
+		if _, err := r.CompressForTenant(tenant, []byte("default tenant payload")); err != nil {
+			t.Errorf("CompressForTenant(%s) failed: %v", tenant, err)
+		}
+		if st, ok := r.TenantStats(tenant); !ok || st.Operations != 1 {
+			t.Errorf("TenantStats(%s) = %+v, %v, want its own state with 1 operation", tenant, st, ok)
+		}
+	}
+}
+
+func TestTenantRegistryMaxDefaultTenants(t *testing.T) {
+	if _, err := NewTenantRegistry(WithMaxDefaultTenants(0)); err == nil {
+		t.Error("NewTenantRegistry(WithMaxDefaultTenants(0)) succeeded, want error")
+	}
+
+	r, err := NewTenantRegistry(WithDefaultTenant(TenantConfig{}), WithMaxDefaultTenants(2))
+	if err != nil {
+		t.Fatalf("NewTenantRegistry() failed: %v", err)
+	}
+	defer r.Close()
+	if err := r.SetTenant("registered", TenantConfig{}); err != nil {
+		t.Fatalf("SetTenant() failed: %v", err)
+	}
+
+	payload := []byte("default tenant payload")
+	for _, tenant := range []string{"registered", "a", "b", "a", "c"} {
+		if _, err := r.CompressForTenant(tenant, payload); err != nil {
+			t.Fatalf("CompressForTenant(%s) failed: %v", tenant, err)
+		}
+	}
+	// "b" is the least recently used default tenant when "c" arrives
+	for tenant, want := range map[string]bool{"registered": true, "a": true, "b": false, "c": true} {
+		if _, ok := r.TenantStats(tenant); ok != want {
+			t.Errorf("TenantStats(%s) registered = %v, want %v", tenant, ok, want)
+		}
+	}
+
+	// A default tenant with an operation running is not evicted
+	busy, err := r.tenant("a")
+	if err != nil {
+		t.Fatalf("tenant(a) failed: %v", err)
+	}
+	other, err := r.tenant("c")
+	if err != nil {
+		t.Fatalf("tenant(c) failed: %v", err)
+	}
+	if _, err := r.CompressForTenant("d", payload); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("CompressForTenant(d) with all default tenants busy error = %v, want ErrQuotaExceeded", err)
+	}
+	other.done()
+	if _, err := r.CompressForTenant("d", payload); err != nil {
+		t.Errorf("CompressForTenant(d) failed: %v", err)
+	}
+	busy.done()
+	if _, ok := r.TenantStats("a"); !ok {
+		t.Error("busy default tenant a was evicted")
+	}
+	if _, ok := r.TenantStats("c"); ok {
+		t.Error("idle default tenant c was not evicted")
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+// Reversible transforms used by the domain helpers to expose structure to
+// OpenZL before compression.
+
+import "math/bits"
+
+// splitBytePlanes transposes n records of width bytes each into width planes
+// of n bytes, so that plane i holds byte i of every record.
+//
+// Bytes at the same position in fixed-width records (version nibbles, high
+// address octets, flag bytes) tend to repeat, and placing them next to each
+// other turns that repetition into long runs.
+func splitBytePlanes(records []byte, width int) []byte {
+	n := len(records) / width
+	planes := make([]byte, len(records))
+	for r := 0; r < n; r++ {
+		for i := 0; i < width; i++ {
+			planes[i*n+r] = records[r*width+i]
+		}
+	}
+	return planes
+}
+
+// joinBytePlanes reverses splitBytePlanes.
+func joinBytePlanes(planes []byte, width int) []byte {
+	n := len(planes) / width
+	records := make([]byte, len(planes))
+	for r := 0; r < n; r++ {
+		for i := 0; i < width; i++ {
+			records[r*width+i] = planes[i*n+r]
+		}
+	}
+	return records
+}
+
+// deltaEncode replaces each value after the first with its difference from
+// the previous one. Differences wrap around on overflow, which deltaDecode
+// undoes exactly.
+func deltaEncode(values []int64) []int64 {
+	out := make([]int64, len(values))
+	var prev int64
+	for i, v := range values {
+		out[i] = v - prev
+		prev = v
+	}
+	return out
+}
+
+// deltaDecode reverses deltaEncode.
+func deltaDecode(deltas []int64) []int64 {
+	out := make([]int64, len(deltas))
+	var prev int64
+	for i, d := range deltas {
+		prev += d
+		out[i] = prev
+	}
+	return out
+}
+
+// maxDeltaOrder is the highest order of differences chooseDeltaOrder tries.
+const maxDeltaOrder = 2
+
+// chooseDeltaOrder returns how many times values should be delta-encoded,
+// from 0 to maxDeltaOrder, to make them smallest.
+//
+// Order 0 suits unordered values, order 1 steadily increasing ones such as
+// counters, and order 2 (delta-of-delta) values growing at a near-constant
+// rate, such as regularly sampled timestamps and counters under steady load.
+// The cost of an order is the total number of significant bits of its
+// zigzag-encoded differences, which tracks what the numeric graph can do
+// with them; ties go to the lower order. The first maxDeltaOrder values are
+// left out of every cost, since higher orders keep them almost unchanged.
+func chooseDeltaOrder(values []int64) int {
+	best, bestCost := 0, -1
+	cur := values
+	for order := 0; order <= maxDeltaOrder; order++ {
+		if order > 0 {
+			cur = deltaEncode(cur)
+		}
+		cost := 0
+		for _, v := range cur[min(maxDeltaOrder, len(cur)):] {
+			cost += bits.Len64(uint64(v<<1) ^ uint64(v>>63))
+		}
+		if bestCost < 0 || cost < bestCost {
+			best, bestCost = order, cost
+		}
+	}
+	return best
+}
+
+// deltaEncodeOrder applies deltaEncode order times.
+func deltaEncodeOrder(values []int64, order int) []int64 {
+	for range order {
+		values = deltaEncode(values)
+	}
+	return values
+}
+
+// deltaDecodeOrder reverses deltaEncodeOrder.
+func deltaDecodeOrder(deltas []int64, order int) []int64 {
+	for range order {
+		deltas = deltaDecode(deltas)
+	}
+	return deltas
+}
@@ -5,6 +5,8 @@ package openzl
 
 import (
 	"fmt"
+	"time"
+	"unsafe"
 
 	"github.com/borischu/go-openzl/internal/cgo"
 )
@@ -42,6 +44,17 @@ func CompressNumeric[T Numeric](data []T) ([]byte, error) {
 		return nil, ErrEmptyInput
 	}
 
+	// Create compression context
+	ctx, err := newCCtx()
+	if err != nil {
+		return nil, fmt.Errorf("create context: %w", err)
+	}
+	defer ctx.Free()
+
+	if defaults().untyped {
+		return compressUntyped(ctx, data)
+	}
+
 	// Create typed reference for the numeric array
 	tref, err := cgo.NewTypedRefNumeric(data)
 	if err != nil {
@@ -49,17 +62,13 @@ func CompressNumeric[T Numeric](data []T) ([]byte, error) {
 	}
 	defer tref.Free()
 
-	// Create compression context
-	ctx, err := cgo.NewCCtx()
-	if err != nil {
-		return nil, fmt.Errorf("create context: %w", err)
-	}
-	defer ctx.Free()
-
 	// Allocate destination buffer
 	// TypedRef compression may need more space than CompressBound for raw bytes
 	srcSize := len(data) * int(tref.ElementSize())
-	dstSize := cgo.CompressBound(srcSize) * 2 // Extra margin for typed compression
+	dstSize, err := compressBound(srcSize, 2) // Extra margin for typed compression
+	if err != nil {
+		return nil, err
+	}
 	dst := make([]byte, dstSize)
 
 	// Compress using typed reference
@@ -71,10 +80,29 @@ func CompressNumeric[T Numeric](data []T) ([]byte, error) {
 	return dst[:n], nil
 }
 
+// compressUntyped compresses the raw bytes of data with the generic graph,
+// for typed compression disabled by WithTypedCompression. The frame holds a
+// single serial output, which DecompressNumeric reads like a numeric one.
+func compressUntyped[T Numeric](ctx *cgo.CCtx, data []T) ([]byte, error) {
+	raw := unsafe.Slice((*byte)(unsafe.Pointer(&data[0])), len(data)*int(unsafe.Sizeof(data[0])))
+	dstSize, err := compressBound(len(raw), 1)
+	if err != nil {
+		return nil, err
+	}
+	dst := make([]byte, dstSize)
+	n, err := ctx.Compress(dst, raw)
+	if err != nil {
+		return nil, fmt.Errorf("compress: %w", err)
+	}
+	return dst[:n], nil
+}
+
 // DecompressNumeric decompresses data that was compressed with CompressNumeric.
 //
-// The type parameter T must match the type used during compression, otherwise
-// the decompression will fail or produce incorrect results.
+// The type parameter T must match the type used during compression. Frames
+// record the width of their elements, so reading them as a type of another
+// width, such as int64 values as int32, fails with ErrTypeMismatch. Types of
+// the same width, such as int64, uint64 and float64, cannot be told apart.
 //
 // Example:
 //
@@ -88,11 +116,15 @@ func CompressNumeric[T Numeric](data []T) ([]byte, error) {
 // Returns an error if:
 //   - the input is empty
 //   - the compressed data is invalid or corrupted
-//   - the type parameter doesn't match the original compression type
+//   - the type parameter has a different width than the original type
+//     (ErrTypeMismatch)
 func DecompressNumeric[T Numeric](compressed []byte) ([]T, error) {
 	if len(compressed) == 0 {
 		return nil, ErrEmptyInput
 	}
+	if isRunFrame(compressed) {
+		return decodeRuns[T](compressed, nil)
+	}
 
 	// Create decompression context
 	ctx, err := cgo.NewDCtx()
@@ -102,9 +134,12 @@ func DecompressNumeric[T Numeric](compressed []byte) ([]T, error) {
 	defer ctx.Free()
 
 	// Decompress to bytes
-	decompressedBytes, err := ctx.DecompressTypedToBytes(compressed)
+	decompressedBytes, info, err := ctx.DecompressTypedToBytes(compressed)
 	if err != nil {
-		return nil, fmt.Errorf("decompress typed: %w", err)
+		return nil, fmt.Errorf("decompress typed: %w", diagnoseFrameError(compressed, err))
+	}
+	if err := checkNumericOutput[T](info); err != nil {
+		return nil, err
 	}
 
 	// Convert bytes to typed slice
@@ -137,6 +172,37 @@ func CompressorCompressNumeric[T Numeric](c *Compressor, data []T) ([]byte, erro
 		return nil, ErrEmptyInput
 	}
 
+	// Constant and run-dominated data does not need the full graph
+	if c.cfg.runShortCircuit {
+		c.mu.Lock()
+		start := time.Now()
+		frame, ok := encodeRuns(data)
+		if ok {
+			// No OpenZL stage ran, so the report has none
+			c.report = Report{InputSize: len(data) * int(unsafe.Sizeof(data[0])), OutputSize: len(frame)}
+			if c.latency != nil {
+				c.latency.observe(start)
+			}
+		}
+		c.mu.Unlock()
+		if ok {
+			return frame, nil
+		}
+	}
+
+	if defaults().untyped {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.latency != nil {
+			defer c.latency.observe(time.Now())
+		}
+		compressed, err := compressUntyped(c.ctx, data)
+		if err == nil {
+			c.recordReport(len(data)*int(unsafe.Sizeof(data[0])), len(compressed))
+		}
+		return compressed, err
+	}
+
 	// Create typed reference for the numeric array
 	tref, err := cgo.NewTypedRefNumeric(data)
 	if err != nil {
@@ -147,10 +213,16 @@ func CompressorCompressNumeric[T Numeric](c *Compressor, data []T) ([]byte, erro
 	// Lock for thread safety
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	if c.latency != nil {
+		defer c.latency.observe(time.Now())
+	}
 
 	// Allocate destination buffer
 	srcSize := len(data) * int(tref.ElementSize())
-	dstSize := cgo.CompressBound(srcSize) * 2
+	dstSize, err := compressBound(srcSize, 2)
+	if err != nil {
+		return nil, err
+	}
 	dst := make([]byte, dstSize)
 
 	// Compress using typed reference with reusable context
@@ -158,6 +230,7 @@ func CompressorCompressNumeric[T Numeric](c *Compressor, data []T) ([]byte, erro
 	if err != nil {
 		return nil, fmt.Errorf("compress typed: %w", err)
 	}
+	c.recordReport(srcSize, n)
 
 	return dst[:n], nil
 }
@@ -177,20 +250,40 @@ func CompressorCompressNumeric[T Numeric](c *Compressor, data []T) ([]byte, erro
 // Returns an error if:
 //   - the input is empty
 //   - the compressed data is invalid or corrupted
-//   - the type parameter doesn't match the original compression type
+//   - the type parameter has a different width than the original type
+//     (ErrTypeMismatch)
 func DecompressorDecompressNumeric[T Numeric](d *Decompressor, compressed []byte) ([]T, error) {
 	if len(compressed) == 0 {
 		return nil, ErrEmptyInput
 	}
-
 	// Lock for thread safety
 	d.mu.Lock()
 	defer d.mu.Unlock()
+	if d.latency != nil {
+		defer d.latency.observe(time.Now())
+	}
+
+	if isRunFrame(compressed) {
+		return decodeRuns[T](compressed, d.checkBudget)
+	}
+
+	if d.budget != 0 {
+		size, err := cgo.GetDecompressedSize(compressed)
+		if err != nil {
+			return nil, fmt.Errorf("get decompressed size: %w", diagnoseFrameError(compressed, err))
+		}
+		if err := d.checkBudget(size); err != nil {
+			return nil, err
+		}
+	}
 
 	// Decompress to bytes with reusable context
-	decompressedBytes, err := d.ctx.DecompressTypedToBytes(compressed)
+	decompressedBytes, info, err := d.ctx.DecompressTypedToBytes(compressed)
 	if err != nil {
-		return nil, fmt.Errorf("decompress typed: %w", err)
+		return nil, fmt.Errorf("decompress typed: %w", diagnoseFrameError(compressed, err))
+	}
+	if err := checkNumericOutput[T](info); err != nil {
+		return nil, err
 	}
 
 	// Convert bytes to typed slice
@@ -201,3 +294,26 @@ func DecompressorDecompressNumeric[T Numeric](d *Decompressor, compressed []byte
 
 	return data, nil
 }
+
+// checkNumericOutput returns ErrTypeMismatch if the output of a typed frame
+// cannot be read as a []T.
+func checkNumericOutput[T Numeric](info cgo.OutputInfo) error {
+	var zero T
+	width := int(unsafe.Sizeof(zero))
+	switch info.Type {
+	case cgo.OutputNumeric:
+		if info.Width != width {
+			return fmt.Errorf("%w: frame holds %d-byte numbers, %T is %d bytes", ErrTypeMismatch, info.Width, zero, width)
+		}
+	case cgo.OutputSerial:
+		// Written with typed compression disabled, which records no width;
+		// BytesToTypedSlice still rejects a size that is not a multiple of it
+	case cgo.OutputStruct:
+		return fmt.Errorf("%w: frame holds %d-byte structs, not numbers", ErrTypeMismatch, info.Width)
+	case cgo.OutputString:
+		return fmt.Errorf("%w: frame holds strings, not numbers", ErrTypeMismatch)
+	default:
+		return fmt.Errorf("%w: frame holds outputs of unknown type %d", ErrTypeMismatch, info.Type)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"encoding/binary"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestCompressUUIDs(t *testing.T) {
+	// UUIDv7-style IDs: increasing millisecond timestamps plus random bits
+	rng := rand.New(rand.NewPCG(1, 2))
+	ids := make([][16]byte, 5000)
+	ms := uint64(1_700_000_000_000)
+	for i := range ids {
+		ms += uint64(rng.IntN(3))
+		var ts [8]byte
+		binary.BigEndian.PutUint64(ts[:], ms)
+		copy(ids[i][:6], ts[2:])
+		for j := 6; j < 16; j++ {
+			ids[i][j] = byte(rng.Uint32())
+		}
+		ids[i][6] = 0x70 | ids[i][6]&0x0f // version 7
+		ids[i][8] = 0x80 | ids[i][8]&0x3f // RFC 4122 variant
+	}
+
+	compressed, err := CompressUUIDs(ids)
+	if err != nil {
+		t.Fatalf("CompressUUIDs() failed: %v", err)
+	}
+	got, err := DecompressUUIDs(compressed)
+	if err != nil {
+		t.Fatalf("DecompressUUIDs() failed: %v", err)
+	}
+	if len(got) != len(ids) {
+		t.Fatalf("got %d IDs, want %d", len(got), len(ids))
+	}
+	for i := range ids {
+		if got[i] != ids[i] {
+			t.Fatalf("ID %d = %x, want %x", i, got[i], ids[i])
+		}
+	}
+
+	if _, err := CompressUUIDs(nil); err != ErrEmptyInput {
+		t.Errorf("CompressUUIDs(nil) error = %v, want ErrEmptyInput", err)
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"fmt"
+	"math"
+)
+
+// DefaultValueThreshold is the value size from which a ValueCodec
+// compresses values, unless set with WithValueThreshold.
+const DefaultValueThreshold = 1 << 10
+
+// ValueCodecOption configures a ValueCodec.
+type ValueCodecOption func(*ValueCodec) error
+
+// WithValueThreshold sets the size in bytes from which values are
+// compressed; smaller values are stored with the prefix only. The default
+// is DefaultValueThreshold.
+func WithValueThreshold(n int) ValueCodecOption {
+	return func(c *ValueCodec) error {
+		if n < 0 {
+			return rangeError("WithValueThreshold", n, 0, math.MaxInt64)
+		}
+		c.threshold = n
+		return nil
+	}
+}
+
+// WithValueProfile compresses values with a Compressor configured by cfg
+// instead of the package defaults.
+//
+// All values then go through that one Compressor, which serializes
+// concurrent calls to Marshal. Without a profile, Marshal uses the package's
+// shared pool of contexts and scales with the number of callers.
+func WithValueProfile(cfg CompressorConfig) ValueCodecOption {
+	return func(c *ValueCodec) error {
+		c.profile = &cfg
+		return nil
+	}
+}
+
+// ValueCodec encodes values for key-value stores such as Redis and
+// memcached: values of at least the threshold size are compressed, and
+// every encoded value starts with a one-byte codec prefix so that Unmarshal
+// can tell compressed values from small ones stored as is, and from values
+// written by older code once the prefix scheme is extended.
+//
+// The prefix is the ID of the Codec that encoded the value: StoredCodec.ID
+// or OpenZLCodec.ID. A value is only kept compressed if compression makes
+// it smaller.
+//
+// For gomemcache, encode Item.Value with Marshal and decode it with
+// Unmarshal. For go-redis, wrap values with Value, which implements the
+// encoding.BinaryMarshaler and encoding.BinaryUnmarshaler interfaces the
+// client uses for command arguments and Scan.
+//
+// A ValueCodec is safe for concurrent use.
+//
+// Example:
+//
+//	codec, err := openzl.NewValueCodec(openzl.WithValueThreshold(512))
+//	...
+//	// memcached
+//	value, err := codec.Marshal(blob)
+//	mc.Set(&memcache.Item{Key: key, Value: value})
+//	...
+//	blob, err = codec.Unmarshal(item.Value)
+//
+//	// Redis
+//	rdb.Set(ctx, key, codec.Value(blob), time.Hour)
+//	v := codec.Value(nil)
+//	err = rdb.Get(ctx, key).Scan(v)
+//	blob = v.Data
+type ValueCodec struct {
+	threshold  int
+	profile    *CompressorConfig
+	compressor *Compressor // Compressor of profile, nil for one-shot Compress
+}
+
+// NewValueCodec returns a ValueCodec configured by opts.
+//
+// Returns an *OptionError if an option is invalid, or the error from
+// creating the Compressor of WithValueProfile.
+func NewValueCodec(opts ...ValueCodecOption) (*ValueCodec, error) {
+	c := &ValueCodec{threshold: DefaultValueThreshold}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+	if c.profile != nil {
+		compressor, err := NewCompressorFromConfig(*c.profile)
+		if err != nil {
+			return nil, fmt.Errorf("value profile: %w", err)
+		}
+		c.compressor = compressor
+	}
+	return c, nil
+}
+
+// Marshal encodes value: the codec prefix followed by value, compressed if
+// it is at least the threshold size and compression makes it smaller.
+//
+// Returns the compression error.
+func (c *ValueCodec) Marshal(value []byte) ([]byte, error) {
+	if len(value) > 0 && len(value) >= c.threshold {
+		var compressed []byte
+		var err error
+		if c.compressor != nil {
+			compressed, err = c.compressor.Compress(value)
+		} else {
+			compressed, err = Compress(value)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(compressed) < len(value) {
+			return append([]byte{OpenZLCodec.ID}, compressed...), nil
+		}
+	}
+	return append([]byte{StoredCodec.ID}, value...), nil
+}
+
+// Unmarshal decodes a value encoded by Marshal. A value stored as is is
+// returned as a subslice of data.
+//
+// Returns ErrCorruptedData if data is empty, ErrUnknownCodec if its prefix
+// is not one of Marshal's, or the decompression error.
+func (c *ValueCodec) Unmarshal(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: empty value, missing codec prefix", ErrCorruptedData)
+	}
+	switch data[0] {
+	case StoredCodec.ID:
+		return data[1:], nil
+	case OpenZLCodec.ID:
+		return Decompress(data[1:])
+	}
+	return nil, fmt.Errorf("%w: value prefix %d", ErrUnknownCodec, data[0])
+}
+
+// Close releases the Compressor of WithValueProfile, if any. The codec must
+// not be used to Marshal afterwards; Unmarshal keeps working.
+func (c *ValueCodec) Close() error {
+	if c.compressor != nil {
+		return c.compressor.Close()
+	}
+	return nil
+}
+
+// Value returns data wrapped for go-redis and other clients that encode
+// values through encoding.BinaryMarshaler and decode them through
+// encoding.BinaryUnmarshaler.
+func (c *ValueCodec) Value(data []byte) *CodecValue {
+	return &CodecValue{Codec: c, Data: data}
+}
+
+// CodecValue is a value encoded and decoded by Codec; see ValueCodec.Value.
+type CodecValue struct {
+	Codec *ValueCodec
+	Data  []byte
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler with Codec.Marshal.
+func (v *CodecValue) MarshalBinary() ([]byte, error) {
+	return v.Codec.Marshal(v.Data)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler with
+// Codec.Unmarshal. Data holds a copy of the value, since clients may reuse
+// b.
+func (v *CodecValue) UnmarshalBinary(b []byte) error {
+	data, err := v.Codec.Unmarshal(b)
+	if err != nil {
+		return err
+	}
+	if len(data) > 0 && b[0] == StoredCodec.ID {
+		data = append([]byte(nil), data...)
+	}
+	v.Data = data
+	return nil
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"bytes"
+	"encoding"
+	"errors"
+	"testing"
+
+	"github.com/borischu/go-openzl/datagen"
+)
+
+// CodecValue plugs into clients that use the standard binary interfaces
+var (
+	_ encoding.BinaryMarshaler   = (*CodecValue)(nil)
+	_ encoding.BinaryUnmarshaler = (*CodecValue)(nil)
+)
+
+func TestValueCodec(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		opts []ValueCodecOption
+	}{
+		{"default", nil},
+		{"profile", []ValueCodecOption{WithValueThreshold(64), WithValueProfile(CompressorConfig{Level: MaxCompressionLevel})}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			codec, err := NewValueCodec(tt.opts...)
+			if err != nil {
+				t.Fatalf("NewValueCodec() failed: %v", err)
+			}
+			defer codec.Close()
+
+			for _, value := range [][]byte{nil, []byte("small"), datagen.Logs(16 << 10), datagen.Random(4 << 10)} {
+				encoded, err := codec.Marshal(value)
+				if err != nil {
+					t.Fatalf("Marshal(%d bytes) failed: %v", len(value), err)
+				}
+				if encoded[0] != StoredCodec.ID && encoded[0] != OpenZLCodec.ID {
+					t.Errorf("Marshal(%d bytes) prefix = %d", len(value), encoded[0])
+				}
+				if len(encoded) > len(value)+1 {
+					t.Errorf("Marshal(%d bytes) = %d bytes", len(value), len(encoded))
+				}
+				decoded, err := codec.Unmarshal(encoded)
+				if err != nil || !bytes.Equal(decoded, value) {
+					t.Errorf("Unmarshal(Marshal(%d bytes)) = %d bytes, %v", len(value), len(decoded), err)
+				}
+
+				v := codec.Value(nil)
+				if err := v.UnmarshalBinary(encoded); err != nil || !bytes.Equal(v.Data, value) {
+					t.Errorf("UnmarshalBinary() = %d bytes, %v", len(v.Data), err)
+				}
+			}
+		})
+	}
+}
+
+func TestValueCodec_Invalid(t *testing.T) {
+	var oe *OptionError
+	if _, err := NewValueCodec(WithValueThreshold(-1)); !errors.As(err, &oe) {
+		t.Errorf("WithValueThreshold(-1): got error %v, want an *OptionError", err)
+	}
+
+	codec, err := NewValueCodec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encoded, _ := codec.Marshal([]byte("small")); encoded[0] != StoredCodec.ID {
+		t.Errorf("Marshal() compressed a value under the threshold")
+	}
+	if _, err := codec.Unmarshal(nil); !errors.Is(err, ErrCorruptedData) {
+		t.Errorf("Unmarshal(empty) error = %v, want ErrCorruptedData", err)
+	}
+	if _, err := codec.Unmarshal([]byte{42, 1, 2}); !errors.Is(err, ErrUnknownCodec) {
+		t.Errorf("Unmarshal(unknown prefix) error = %v, want ErrUnknownCodec", err)
+	}
+}
@@ -0,0 +1,251 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"runtime"
+	"sync"
+
+	"github.com/borischu/go-openzl/internal/fault"
+)
+
+// VerifyOption configures VerifyStream.
+type VerifyOption func(*verifyConfig) error
+
+// verifyConfig holds the configuration options for VerifyStream.
+type verifyConfig struct {
+	workers int
+}
+
+// WithVerifyWorkers sets the number of goroutines that verify frames, each
+// with its own decompression context. If not specified,
+// runtime.GOMAXPROCS(0) workers are used.
+func WithVerifyWorkers(n int) VerifyOption {
+	return func(cfg *verifyConfig) error {
+		if n < 1 {
+			return rangeError("WithVerifyWorkers", n, 1, math.MaxInt64)
+		}
+		cfg.workers = n
+		return nil
+	}
+}
+
+// FrameStatus is the verification result of one frame of a Writer stream.
+type FrameStatus struct {
+	// Index is the position of the frame in the stream, starting at 0.
+	Index int
+
+	// Offset is the position of the frame header in the stream.
+	Offset int64
+
+	// Header is the decoded frame header.
+	Header FrameHeader
+
+	// DecompressedSize is the size of the frame's data. It is 0 if the
+	// frame failed verification.
+	DecompressedSize int
+
+	// Err is nil if the frame is intact: its checksum, if any, matches
+	// and its payload decompresses. Otherwise it is ErrChecksumMismatch
+	// or the decompression error.
+	Err error
+}
+
+// VerifyReport is the result of VerifyStream.
+type VerifyReport struct {
+	// Frames holds the status of every frame read, in stream order.
+	Frames []FrameStatus
+
+	// Complete reports whether the stream ended with its end-of-stream
+	// marker. A stream written by a Writer that was never closed lacks it.
+	Complete bool
+
+	// DecompressedSize is the total size of the data of the intact frames.
+	DecompressedSize int64
+}
+
+// Failed returns the frames that failed verification.
+func (r *VerifyReport) Failed() []FrameStatus {
+	var failed []FrameStatus
+	for _, f := range r.Frames {
+		if f.Err != nil {
+			failed = append(failed, f)
+		}
+	}
+	return failed
+}
+
+// OK reports whether the stream is complete and every frame is intact.
+func (r *VerifyReport) OK() bool {
+	return r.Complete && len(r.Failed()) == 0
+}
+
+// verifyJob is a frame queued for verification.
+type verifyJob struct {
+	status  *FrameStatus
+	payload []byte // From the package Allocator
+	sum     uint32 // Checksum trailer, if Header.Checksum
+}
+
+// VerifyStream reads a Writer stream from r and checks the integrity of
+// every frame, for audits of archived data: the CRC32C trailer of frames
+// written with WithFrameChecksum is compared, and every compressed frame is
+// decompressed and its output discarded.
+//
+// Frames are read by the calling goroutine and verified in parallel, so an
+// audit runs at the speed of the storage rather than of a single core. A
+// damaged frame does not stop the audit: its error is recorded in the
+// report and the next frame is verified, which tells which parts of a
+// partially damaged archive can still be read.
+//
+// Example:
+//
+//	f, _ := os.Open("archive.zl")
+//	defer f.Close()
+//	report, err := openzl.VerifyStream(f, openzl.WithVerifyWorkers(8))
+//	if err != nil {
+//		return err
+//	}
+//	for _, frame := range report.Failed() {
+//		log.Printf("frame %d at offset %d: %v", frame.Index, frame.Offset, frame.Err)
+//	}
+//
+// Returns an error, together with the report of the frames before it, if
+// the stream cannot be walked further: a read error, a corrupted frame
+// header, or a frame cut short, reported as io.ErrUnexpectedEOF. A bare
+// frame from Compress is rejected with ErrFrameInput.
+func VerifyStream(r io.Reader, opts ...VerifyOption) (*VerifyReport, error) {
+	if r == nil {
+		return nil, fmt.Errorf("%w: nil reader", ErrInvalidParameter)
+	}
+	cfg := verifyConfig{workers: runtime.GOMAXPROCS(0)}
+	for _, opt := range opts {
+		if err := opt(&cfg); err != nil {
+			return nil, fmt.Errorf("apply option: %w", err)
+		}
+	}
+
+	decompressors := make([]*Decompressor, cfg.workers)
+	for i := range decompressors {
+		d, err := NewDecompressor()
+		if err != nil {
+			for _, d := range decompressors[:i] {
+				d.Close()
+			}
+			return nil, fmt.Errorf("create decompressor: %w", err)
+		}
+		decompressors[i] = d
+	}
+
+	// Queue one frame per worker so reading stays ahead of verifying
+	// without buffering an unbounded part of the input
+	jobs := make(chan verifyJob, cfg.workers)
+	var wg sync.WaitGroup
+	for _, d := range decompressors {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer d.Close()
+			for job := range jobs {
+				verifyFrame(d, job)
+				freeBuf(job.payload)
+			}
+		}()
+	}
+
+	var statuses []*FrameStatus
+	complete, err := readVerifyJobs(fault.Reader(r), jobs, &statuses)
+	close(jobs)
+	wg.Wait()
+
+	report := &VerifyReport{Complete: complete, Frames: make([]FrameStatus, len(statuses))}
+	for i, s := range statuses {
+		report.Frames[i] = *s
+		report.DecompressedSize += int64(s.DecompressedSize)
+	}
+	return report, err
+}
+
+// readVerifyJobs reads the frames of a stream from r and queues them on
+// jobs, appending their statuses to statuses. It reports whether the
+// end-of-stream marker was reached.
+func readVerifyJobs(r io.Reader, jobs chan<- verifyJob, statuses *[]*FrameStatus) (bool, error) {
+	var offset int64
+	for index := 0; ; index++ {
+		var header [FrameHeaderSize]byte
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			if err == io.EOF {
+				return false, nil
+			}
+			if err == io.ErrUnexpectedEOF {
+				return false, err
+			}
+			return false, fmt.Errorf("read header: %w", err)
+		}
+		if index == 0 && isBareFrame(header[:]) {
+			return false, ErrFrameInput
+		}
+		h, err := ParseFrameHeader(header[:])
+		if err != nil {
+			return false, fmt.Errorf("frame %d at offset %d: %w", index, offset, err)
+		}
+		if h.EndOfStream() {
+			return true, nil
+		}
+
+		job := verifyJob{
+			status:  &FrameStatus{Index: index, Offset: offset, Header: h},
+			payload: allocBuf(h.PayloadSize),
+		}
+		if _, err := io.ReadFull(r, job.payload); err != nil {
+			freeBuf(job.payload)
+			return false, readFrameError("read frame", err)
+		}
+		if h.Checksum {
+			var trailer [FrameChecksumSize]byte
+			if _, err := io.ReadFull(r, trailer[:]); err != nil {
+				freeBuf(job.payload)
+				return false, readFrameError("read checksum", err)
+			}
+			job.sum = binary.LittleEndian.Uint32(trailer[:])
+		}
+		*statuses = append(*statuses, job.status)
+		jobs <- job
+		offset += int64(h.FrameSize())
+	}
+}
+
+// readFrameError reports an error reading the body of a frame, as
+// io.ErrUnexpectedEOF if the stream ends there.
+func readFrameError(op string, err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return io.ErrUnexpectedEOF
+	}
+	return fmt.Errorf("%s: %w", op, err)
+}
+
+// verifyFrame checks the checksum and decompresses the payload of job,
+// recording the result in its status.
+func verifyFrame(d *Decompressor, job verifyJob) {
+	s := job.status
+	if s.Header.Checksum && job.sum != crc32.Checksum(job.payload, crc32cTable) {
+		s.Err = ErrChecksumMismatch
+		return
+	}
+	if s.Header.Stored {
+		s.DecompressedSize = len(job.payload)
+		return
+	}
+	out, err := d.Decompress(job.payload)
+	if err != nil {
+		s.Err = err
+		return
+	}
+	s.DecompressedSize = len(out)
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestVerifyStream(t *testing.T) {
+	data := bytes.Repeat([]byte("archived audit record "), 4*MinFrameSize/22)
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, WithFrameSize(MinFrameSize), WithFrameChecksum(true))
+	if err != nil {
+		t.Fatalf("NewWriter() failed: %v", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	stream := buf.Bytes()
+
+	report, err := VerifyStream(bytes.NewReader(stream), WithVerifyWorkers(3))
+	if err != nil {
+		t.Fatalf("VerifyStream() failed: %v", err)
+	}
+	if !report.OK() || len(report.Frames) != 4 || report.DecompressedSize != int64(len(data)) {
+		t.Fatalf("VerifyStream() = %d frames, %d bytes, complete %v, want 4 intact frames of %d bytes",
+			len(report.Frames), report.DecompressedSize, report.Complete, len(data))
+	}
+
+	// Damage the payload of the second frame only
+	second := report.Frames[1]
+	damaged := bytes.Clone(stream)
+	damaged[second.Offset+FrameHeaderSize] ^= 0xff
+	report, err = VerifyStream(bytes.NewReader(damaged))
+	if err != nil {
+		t.Fatalf("VerifyStream(damaged) failed: %v", err)
+	}
+	failed := report.Failed()
+	if report.OK() || len(failed) != 1 || failed[0].Index != 1 || !errors.Is(failed[0].Err, ErrChecksumMismatch) {
+		t.Errorf("VerifyStream(damaged) failed frames = %+v, want frame 1 with ErrChecksumMismatch", failed)
+	}
+
+	// Without its end marker, the stream is intact but incomplete
+	report, err = VerifyStream(bytes.NewReader(stream[:len(stream)-FrameHeaderSize]))
+	if err != nil || report.Complete || len(report.Failed()) != 0 {
+		t.Errorf("VerifyStream(no end marker) = complete %v, %v, want incomplete without error", report.Complete, err)
+	}
+
+	// A frame cut short stops the walk
+	cut := second.Offset + int64(second.Header.FrameSize()) - 1
+	report, err = VerifyStream(bytes.NewReader(stream[:cut]))
+	if !errors.Is(err, io.ErrUnexpectedEOF) || len(report.Frames) != 1 {
+		t.Errorf("VerifyStream(cut) = %d frames, %v, want 1 frame and io.ErrUnexpectedEOF", len(report.Frames), err)
+	}
+
+	compressed, err := Compress(data)
+	if err != nil {
+		t.Fatalf("Compress() failed: %v", err)
+	}
+	if _, err := VerifyStream(bytes.NewReader(compressed)); !errors.Is(err, ErrFrameInput) {
+		t.Errorf("VerifyStream(bare frame) error = %v, want ErrFrameInput", err)
+	}
+	if _, err := VerifyStream(bytes.NewReader(stream), WithVerifyWorkers(0)); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("WithVerifyWorkers(0) error = %v, want ErrInvalidParameter", err)
+	}
+}
@@ -3,11 +3,87 @@
 
 package openzl
 
+import (
+	"cmp"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/borischu/go-openzl/internal/cgo"
+)
+
 // Version is the current version of go-openzl
 const Version = "0.1.0-dev"
 
-// OpenZLVersion returns the version of the underlying OpenZL C library
-// TODO: Implement this once CGO bindings are in place
+// OpenZLVersion returns the version of the underlying OpenZL C library, or
+// "unknown" if its headers do not declare one.
 func OpenZLVersion() string {
+	if v := Features().Version; v != "" {
+		return v
+	}
 	return "unknown"
 }
+
+// BuildDetails identifies the Go and C code linked into the program. Log it
+// at startup to confirm which OpenZL build a deployed binary runs.
+type BuildDetails struct {
+	// Version is the go-openzl Version constant.
+	Version string
+
+	// ModuleVersion is the version of the go-openzl module recorded by the
+	// Go toolchain, such as "v0.3.1", "(devel)" when go-openzl is the main
+	// module, or "" when the binary carries no module information.
+	ModuleVersion string
+
+	// OpenZLVersion is the OpenZL release declared by the headers the
+	// bindings were compiled against, as returned by OpenZLVersion. The
+	// commit of the linked library is not recorded anywhere go-openzl can
+	// read, so it is not reported.
+	OpenZLVersion string
+
+	// LinkMode is how the library was provided: "static" for the pre-built
+	// libraries under vendor/openzl, built from the same checkout as the
+	// headers, or "dynamic" for a system library located with pkg-config
+	// (openzl_dynamic). With "dynamic", the shared library loaded at run time
+	// may be another release than OpenZLVersion.
+	LinkMode string
+}
+
+// String returns the details on one line, for logs.
+func (b BuildDetails) String() string {
+	s := fmt.Sprintf("go-openzl %s", b.Version)
+	if b.ModuleVersion != "" {
+		s += fmt.Sprintf(" (module %s)", b.ModuleVersion)
+	}
+	return s + fmt.Sprintf(", OpenZL %s headers, %s link", b.OpenZLVersion, b.LinkMode)
+}
+
+// BuildInfo returns the build details of the program's go-openzl and OpenZL
+// code. The OpenZL details are compiled in, so they are available even in
+// binaries without debug information.
+//
+// Example:
+//
+//	log.Printf("compression: %v", openzl.BuildInfo())
+func BuildInfo() BuildDetails {
+	b := BuildDetails{
+		Version:       Version,
+		OpenZLVersion: OpenZLVersion(),
+		LinkMode:      cgo.LinkMode,
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		const path = "github.com/borischu/go-openzl"
+		if info.Main.Path == path {
+			b.ModuleVersion = info.Main.Version
+		}
+		for _, dep := range info.Deps {
+			if dep.Path == path {
+				b.ModuleVersion = dep.Version
+				if dep.Replace != nil {
+					// Replaced by a local directory, which has no version
+					b.ModuleVersion = cmp.Or(dep.Replace.Version, "(devel)")
+				}
+			}
+		}
+	}
+	return b
+}
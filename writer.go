@@ -4,8 +4,13 @@
 package openzl
 
 import (
+	"bytes"
+	"encoding/binary"
 	"fmt"
+	"hash"
+	"hash/crc32"
 	"io"
+	"math"
 )
 
 // Writer implements io.WriteCloser for streaming compression.
@@ -26,13 +31,32 @@ import (
 // Important: You must call Close() to flush any buffered data and ensure
 // all compressed data is written to the underlying writer.
 type Writer struct {
-	w          io.Writer     // Underlying writer for compressed data
-	compressor *Compressor   // Reusable compressor context
-	buf        []byte        // Buffer for incoming uncompressed data
-	bufSize    int           // Current amount of data in buffer
-	frameSize  int           // Size of each compression frame (default 64KB)
-	closed     bool          // Whether Close() has been called
-	err        error         // Sticky error from previous operations
+	w          io.Writer      // Underlying writer for compressed data
+	compressor *Compressor    // Reusable compressor context
+	buf        []byte         // Buffer for incoming uncompressed data
+	bufSize    int            // Current amount of data in buffer
+	frameSize  int            // Size of each compression frame (default 64KB)
+	frameOpts  []string       // Options that set frameSize, in order applied
+	closed     bool           // Whether Close() has been called
+	err        error          // Sticky error from previous operations
+	hash       hash.Hash      // Optional hash of uncompressed content
+	checksum   bool           // Append CRC32C of each compressed frame
+	stored     bool           // Store frames raw when compression expands them
+	cdc        *cdcChunker    // Content-defined chunking, nil for fixed frames
+	inputSize  int64          // Uncompressed bytes accepted in this stream
+	adaptive   *adaptiveLevel // Per-frame level selection, nil if disabled
+
+	emptyPolicy  EmptyPolicy // What Close writes for a stream with no data
+	flushOnEmpty bool        // Zero-length Write flushes the buffer
+	maxFrameOut  int         // Bound on the bytes of each emitted frame, 0 for none
+
+	compressorOpts []CompressorOption // Options for the compressor context
+
+	maxInFlight int        // In-flight limit for async writing (0 = synchronous)
+	sink        *asyncSink // Background writer, set when maxInFlight > 0
+
+	index  io.Writer   // Destination of the frame index, nil for none
+	frames *FrameIndex // Frames of this stream, if index is set
 }
 
 const (
@@ -45,25 +69,226 @@ const (
 
 	// MaxFrameSize is the maximum frame size (1MB).
 	MaxFrameSize = 1024 * 1024
+
+	// DefaultLongWindowSize is the frame size used by WithLongWindow when
+	// no explicit window is given (64MB).
+	DefaultLongWindowSize = 64 * 1024 * 1024
+
+	// MaxLongWindowSize is the largest window accepted by WithLongWindow (512MB).
+	// Frame headers store payload sizes in 30 bits, so larger windows could
+	// not be framed when the data is incompressible.
+	MaxLongWindowSize = 512 * 1024 * 1024
 )
 
 // WriterOption configures a Writer.
 type WriterOption func(*Writer) error
 
+// EmptyPolicy controls what a Writer emits for a stream with no data.
+type EmptyPolicy int
+
+const (
+	// EmptyEndMarker writes the 4-byte end-of-stream marker, so an empty
+	// stream is still a well-formed stream. This is the default.
+	EmptyEndMarker EmptyPolicy = iota
+
+	// EmptyNoOutput writes nothing at all. Reader treats empty input as an
+	// empty stream, but parsers that expect at least one frame, or that
+	// reject an isolated end marker as a corrupt file, never see one.
+	EmptyNoOutput
+)
+
 // WithFrameSize sets the frame size for buffered compression.
 //
 // Larger frame sizes generally provide better compression ratios but use more
 // memory. Smaller frame sizes reduce memory usage but may reduce compression ratio.
 //
 // The frame size must be between MinFrameSize (4KB) and MaxFrameSize (1MB).
-// If not specified, DefaultFrameSize (64KB) is used.
+// If not specified, DefaultFrameSize (64KB) is used, unless Init set another
+// default with WithDefaultFrameSize. If WithLongWindow is also given, the
+// later of the two wins.
 func WithFrameSize(size int) WriterOption {
 	return func(w *Writer) error {
 		if size < MinFrameSize || size > MaxFrameSize {
-			return fmt.Errorf("frame size must be between %d and %d bytes", MinFrameSize, MaxFrameSize)
+			return rangeError("WithFrameSize", size, MinFrameSize, MaxFrameSize)
 		}
 		w.frameSize = size
-		w.buf = make([]byte, size)
+		w.frameOpts = append(w.frameOpts, "WithFrameSize")
+		return nil
+	}
+}
+
+// WithLongWindow enables long-window mode for large inputs with distant redundancy.
+//
+// Each frame is compressed independently, so matches can never reach further
+// back than the frame size. With the default 64KB-1MB frames, repetition that
+// is tens or hundreds of megabytes apart is invisible to the compressor.
+// Long-window mode raises the frame size to window bytes, letting OpenZL find
+// those long-range matches in the same way zstd --long does.
+//
+// The window must be between MaxFrameSize (1MB) and MaxLongWindowSize (512MB).
+// Pass 0 to use DefaultLongWindowSize (64MB).
+//
+// Memory usage grows with the window: the Writer buffers up to window bytes of
+// uncompressed input, and the Reader holds one decompressed window at a time.
+// The buffer grows on demand, so short streams do not pay for the full window.
+func WithLongWindow(window int) WriterOption {
+	return func(w *Writer) error {
+		if window == 0 {
+			window = DefaultLongWindowSize
+		}
+		if window < MaxFrameSize || window > MaxLongWindowSize {
+			err := rangeError("WithLongWindow", window, MaxFrameSize, MaxLongWindowSize)
+			err.Allowed += " or 0"
+			return err
+		}
+		w.frameSize = window
+		w.frameOpts = append(w.frameOpts, "WithLongWindow")
+		return nil
+	}
+}
+
+// WithContentHash feeds all uncompressed bytes through h as they are written.
+//
+// This gives archival tools an integrity digest of the original content
+// without re-reading the source. The digest is available from ContentSum
+// once the Writer has been closed. The hash is reset by Reset, so each
+// stream gets its own digest.
+//
+// Example:
+//
+//	h := sha256.New()
+//	writer, _ := openzl.NewWriter(file, openzl.WithContentHash(h))
+//	io.Copy(writer, source)
+//	writer.Close()
+//	fmt.Printf("sha256: %x\n", writer.ContentSum())
+func WithContentHash(h hash.Hash) WriterOption {
+	return func(w *Writer) error {
+		if h == nil {
+			return &OptionError{Option: "WithContentHash", Value: h, Allowed: "a non-nil hash"}
+		}
+		w.hash = h
+		return nil
+	}
+}
+
+// WithFrameChecksum appends a CRC32C of each compressed frame to the stream.
+//
+// The Reader verifies the checksum before handing the frame to the OpenZL
+// decoder, so transport corruption is reported quickly as ErrChecksumMismatch
+// instead of as an opaque decoder error (or, worse, silently wrong output).
+// Each checksum adds 4 bytes per frame. Streams with and without checksums
+// can be read by the same Reader.
+func WithFrameChecksum(enabled bool) WriterOption {
+	return func(w *Writer) error {
+		w.checksum = enabled
+		return nil
+	}
+}
+
+// WithStoredFallback stores a frame uncompressed whenever compressing it would
+// not make it smaller.
+//
+// Already-compressed content such as JPEG images or the streams inside PDFs
+// usually grows slightly when compressed again. With this option, such frames
+// are written raw with a flag in the frame header, so the stream is never more
+// than the framing overhead larger than the input, and the Reader skips
+// decompression for them. Streams written with this option can only be read
+// by versions of this package that understand stored frames.
+func WithStoredFallback(enabled bool) WriterOption {
+	return func(w *Writer) error {
+		w.stored = enabled
+		return nil
+	}
+}
+
+// WithIndexSidecar makes Close write an index of the stream's frames to
+// index, usually a file named after the archive with IndexSidecarExt
+// appended, so that the archive can later be read at any offset with
+// NewIndexedReader. The index is written only if the stream was completed
+// successfully. Use ResetWithOptions to give each stream its own index.
+//
+// Example:
+//
+//	archive, _ := os.Create("2024.zl")
+//	sidecar, _ := os.Create("2024.zl" + openzl.IndexSidecarExt)
+//	writer, _ := openzl.NewWriter(archive, openzl.WithIndexSidecar(sidecar))
+//	io.Copy(writer, source)
+//	writer.Close()
+//
+// WriteIndexSidecar builds the same index for an existing archive.
+func WithIndexSidecar(index io.Writer) WriterOption {
+	return func(w *Writer) error {
+		if index == nil {
+			return &OptionError{Option: "WithIndexSidecar", Value: index, Allowed: "a non-nil writer"}
+		}
+		w.index = index
+		w.frames = &FrameIndex{}
+		return nil
+	}
+}
+
+// WithEmptyPolicy sets what Close writes when no data was written to the
+// stream. If not specified, EmptyEndMarker is used.
+func WithEmptyPolicy(policy EmptyPolicy) WriterOption {
+	return func(w *Writer) error {
+		if policy != EmptyEndMarker && policy != EmptyNoOutput {
+			return &OptionError{Option: "WithEmptyPolicy", Value: policy, Allowed: "EmptyEndMarker or EmptyNoOutput"}
+		}
+		w.emptyPolicy = policy
+		return nil
+	}
+}
+
+// WithFlushOnEmptyWrite makes a zero-length Write compress and write any
+// buffered data as a frame.
+//
+// Normally a zero-length Write does nothing. With this option, callers that
+// only have an io.Writer can still force a frame boundary, for example to make
+// everything written so far readable by a consumer tailing the stream. If the
+// buffer is empty, nothing is written: the stream format has no encoding for
+// an empty frame, since a zero-length header marks the end of the stream.
+func WithFlushOnEmptyWrite(enabled bool) WriterOption {
+	return func(w *Writer) error {
+		w.flushOnEmpty = enabled
+		return nil
+	}
+}
+
+// WithMaxCompressedFrameSize guarantees that no frame written to the stream,
+// including its header and checksum trailer, is larger than n bytes.
+//
+// This is for transports that carry each frame in one message of bounded
+// size, such as a datagram below the path MTU or a gRPC message below the
+// default 4MB receive limit. When a frame would exceed the bound, the Writer
+// compresses a smaller prefix of its input instead, halving it until the
+// frame fits, and writes the rest as further frames. Compressible data is
+// rarely split, so the cost is only paid on frames close to the bound.
+//
+// n must be at least 256 bytes. Writing fails if even a single input byte
+// cannot be framed within n bytes.
+//
+// Example:
+//
+//	// Every frame fits in one 1MB message
+//	writer, _ := openzl.NewWriter(conn, openzl.WithMaxCompressedFrameSize(1<<20))
+func WithMaxCompressedFrameSize(n int) WriterOption {
+	return func(w *Writer) error {
+		if n < 256 {
+			return rangeError("WithMaxCompressedFrameSize", n, 256, math.MaxInt64)
+		}
+		w.maxFrameOut = n
+		return nil
+	}
+}
+
+// WithCompressorOptions sets the options used to create the Writer's
+// compression context.
+//
+// The options are kept with the Writer, so a context recreated by Reset after
+// Close is configured the same way.
+func WithCompressorOptions(opts ...CompressorOption) WriterOption {
+	return func(w *Writer) error {
+		w.compressorOpts = append([]CompressorOption(nil), opts...)
 		return nil
 	}
 }
@@ -93,34 +318,79 @@ func NewWriter(w io.Writer, opts ...WriterOption) (*Writer, error) {
 		return nil, fmt.Errorf("nil writer")
 	}
 
+	writer, err := newWriterConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create reusable compressor
-	compressor, err := NewCompressor()
+	compressor, err := NewCompressor(writer.compressorOpts...)
 	if err != nil {
+		writer.freeBuffer()
 		return nil, fmt.Errorf("create compressor: %w", err)
 	}
 
+	compressor.unclosed.kind = "Writer"
+	writer.w = w
+	writer.compressor = compressor
+	if writer.adaptive != nil {
+		compressor.setLevel(writer.adaptive.level)
+	}
+	if writer.maxInFlight > 0 {
+		writer.sink = newAsyncSink(w, writer.maxInFlight)
+	}
+
+	return writer, nil
+}
+
+// newWriterConfig returns an unattached Writer configured by opts, with its
+// buffer allocated but no destination, compressor, or sink.
+func newWriterConfig(opts []WriterOption) (*Writer, error) {
 	writer := &Writer{
-		w:          w,
-		compressor: compressor,
-		frameSize:  DefaultFrameSize,
+		frameSize: defaults().frameSize,
 	}
 
 	// Apply options
 	for _, opt := range opts {
 		if err := opt(writer); err != nil {
-			compressor.Close()
 			return nil, err
 		}
 	}
 
-	// Allocate buffer if not already done by options
-	if writer.buf == nil {
-		writer.buf = make([]byte, writer.frameSize)
+	if writer.cdc != nil && writer.frameSize < 2*int(writer.cdc.mask+1) {
+		return nil, fmt.Errorf("frame size %d too small for content-defined chunks of %d bytes", writer.frameSize, writer.cdc.mask+1)
 	}
 
+	// The last option that sets the frame size wins
+	if n := len(writer.frameOpts); n > 0 {
+		last := writer.frameOpts[n-1]
+		for _, name := range writer.frameOpts[:n-1] {
+			if name != last {
+				warn(fmt.Errorf("%w: %s overridden by %s", ErrOptionIgnored, name, last))
+				break
+			}
+		}
+	}
+	if writer.adaptive != nil && defaults().level != 0 {
+		warn(fmt.Errorf("%w: default level %d not used by WithAdaptiveLevel", ErrOptionIgnored, defaults().level))
+	}
+
+	writer.allocBuffer()
 	return writer, nil
 }
 
+// allocBuffer allocates the frame buffer from the package Allocator. Long
+// windows start small and grow on demand in Write.
+func (w *Writer) allocBuffer() {
+	w.buf = allocBuf(min(w.frameSize, MaxFrameSize))
+}
+
+// freeBuffer returns the frame buffer to the package Allocator.
+func (w *Writer) freeBuffer() {
+	freeBuf(w.buf)
+	w.buf = nil
+}
+
 // Write compresses data and writes it to the underlying writer.
 //
 // Write buffers input data until a full frame is available, then compresses
@@ -128,7 +398,61 @@ func NewWriter(w io.Writer, opts ...WriterOption) (*Writer, error) {
 //
 // If an error occurs, the Writer enters an error state and all subsequent
 // Write calls will return the same error.
+//
+// With WithMaxInFlight, Write blocks while the configured number of
+// compressed bytes is waiting to be written to a slow destination.
 func (w *Writer) Write(p []byte) (n int, err error) {
+	return w.write(p, true)
+}
+
+// TryWrite is a non-blocking variant of Write for Writers created with
+// WithMaxInFlight.
+//
+// TryWrite accepts as much of p as it can without waiting for the underlying
+// writer. If it has to stop early because the in-flight limit is reached, it
+// returns the number of bytes accepted and ErrWouldBlock; the caller should
+// retry the remainder of p once Ready signals that capacity is available.
+// ErrWouldBlock does not put the Writer into an error state.
+//
+// Example:
+//
+//	for len(p) > 0 {
+//		n, err := writer.TryWrite(p)
+//		p = p[n:]
+//		if errors.Is(err, openzl.ErrWouldBlock) {
+//			select {
+//			case <-writer.Ready():
+//			case <-ctx.Done():
+//				return ctx.Err()
+//			}
+//			continue
+//		}
+//		if err != nil {
+//			return err
+//		}
+//	}
+func (w *Writer) TryWrite(p []byte) (n int, err error) {
+	if w.sink == nil {
+		return 0, fmt.Errorf("%w: TryWrite requires WithMaxInFlight", ErrInvalidParameter)
+	}
+	return w.write(p, false)
+}
+
+// Ready returns a channel that receives a value whenever previously queued
+// compressed data has been written and capacity may be available again.
+//
+// It returns nil, which blocks forever in a select, if the Writer was not
+// created with WithMaxInFlight.
+func (w *Writer) Ready() <-chan struct{} {
+	if w.sink == nil {
+		return nil
+	}
+	return w.sink.ready
+}
+
+// write implements Write and TryWrite. When block is false, it returns
+// ErrWouldBlock instead of waiting for in-flight capacity.
+func (w *Writer) write(p []byte, block bool) (n int, err error) {
 	if w.closed {
 		return 0, fmt.Errorf("write to closed Writer")
 	}
@@ -136,8 +460,19 @@ func (w *Writer) Write(p []byte) (n int, err error) {
 		return 0, w.err
 	}
 
+	if len(p) == 0 && w.flushOnEmpty && w.bufSize > 0 {
+		if !block && w.sink.full() {
+			return 0, ErrWouldBlock
+		}
+		if err := w.flush(); err != nil {
+			w.err = err
+			return 0, err
+		}
+		return 0, nil
+	}
+
 	written := 0
-	for len(p) > 0 {
+	for len(p) > 0 || w.bufSize == w.frameSize {
 		// Copy as much as possible to buffer
 		available := w.frameSize - w.bufSize
 		toCopy := len(p)
@@ -145,13 +480,33 @@ func (w *Writer) Write(p []byte) (n int, err error) {
 			toCopy = available
 		}
 
+		w.grow(w.bufSize + toCopy)
 		copy(w.buf[w.bufSize:], p[:toCopy])
+		if w.hash != nil {
+			// hash.Hash.Write never returns an error
+			w.hash.Write(p[:toCopy])
+		}
 		w.bufSize += toCopy
+		w.inputSize += int64(toCopy)
 		p = p[toCopy:]
 		written += toCopy
 
-		// If buffer is full, compress and write it
+		// Cut frames at content-defined boundaries found in the new data
+		if w.cdc != nil {
+			if err := w.flushCuts(block); err != nil {
+				if err != ErrWouldBlock {
+					w.err = err
+				}
+				return written, err
+			}
+		}
+
+		// If buffer is full, compress and write it. A full buffer is kept
+		// as-is when TryWrite cannot flush and is retried on the next call.
 		if w.bufSize == w.frameSize {
+			if !block && w.sink.full() {
+				return written, ErrWouldBlock
+			}
 			if err := w.flush(); err != nil {
 				w.err = err
 				return written, err
@@ -162,78 +517,256 @@ func (w *Writer) Write(p []byte) (n int, err error) {
 	return written, nil
 }
 
+// grow ensures the buffer can hold at least n bytes, never exceeding frameSize.
+func (w *Writer) grow(n int) {
+	if n <= len(w.buf) {
+		return
+	}
+	size := max(n, 2*len(w.buf))
+	if size > w.frameSize {
+		size = w.frameSize
+	}
+	buf := allocBuf(size)
+	copy(buf, w.buf[:w.bufSize])
+	freeBuf(w.buf)
+	w.buf = buf
+}
+
+// flushCuts writes one frame for each content-defined boundary in the buffer.
+// When block is false, it returns ErrWouldBlock instead of waiting for
+// in-flight capacity; the pending boundary is kept for the next call.
+func (w *Writer) flushCuts(block bool) error {
+	for {
+		cut := w.cdc.scan(w.buf[:w.bufSize])
+		if cut < 0 {
+			return nil
+		}
+		if !block && w.sink.full() {
+			return ErrWouldBlock
+		}
+		if err := w.flushN(cut); err != nil {
+			return err
+		}
+	}
+}
+
 // flush compresses and writes the current buffer to the underlying writer.
 func (w *Writer) flush() error {
-	if w.bufSize == 0 {
+	return w.flushN(w.bufSize)
+}
+
+// flushN compresses and writes the first n buffered bytes and moves any
+// remaining bytes to the front of the buffer. The bytes are written as one
+// frame unless WithMaxCompressedFrameSize requires splitting them.
+func (w *Writer) flushN(n int) error {
+	if n == 0 {
 		return nil
 	}
 
-	// Compress the buffered data
-	compressed, err := w.compressor.Compress(w.buf[:w.bufSize])
-	if err != nil {
-		return fmt.Errorf("compress: %w", err)
+	for off := 0; off < n; {
+		written, err := w.writeFrame(w.buf[off:n])
+		if err != nil {
+			return err
+		}
+		off += written
 	}
 
-	// Write frame header: 4-byte little-endian compressed size
-	header := []byte{
-		byte(len(compressed)),
-		byte(len(compressed) >> 8),
-		byte(len(compressed) >> 16),
-		byte(len(compressed) >> 24),
+	// Reset buffer, keeping bytes past the frame for the next one
+	w.bufSize = copy(w.buf, w.buf[n:w.bufSize])
+	if w.cdc != nil {
+		w.cdc.reset()
 	}
 
-	if _, err := w.w.Write(header); err != nil {
-		return fmt.Errorf("write header: %w", err)
+	return nil
+}
+
+// writeFrame compresses and writes a prefix of p as one frame and returns
+// the length of the prefix. The prefix is all of p unless the frame would
+// exceed the WithMaxCompressedFrameSize bound, in which case the input is
+// halved until it fits.
+func (w *Writer) writeFrame(p []byte) (int, error) {
+	// Write frame header: 4-byte little-endian compressed size and flags
+	var flags uint32
+	overhead := FrameHeaderSize
+	if w.checksum {
+		flags |= frameFlagChecksum
+		overhead += FrameChecksumSize
+	}
+
+	var compressed []byte
+	for {
+		var err error
+		compressed, err = w.compressor.Compress(p)
+		if err != nil {
+			return 0, fmt.Errorf("compress: %w", err)
+		}
+
+		// Store incompressible frames raw. The buffer is reused for the
+		// next frame, so the payload must be a copy.
+		if w.stored && len(compressed) >= len(p) {
+			compressed = append([]byte(nil), p...)
+			flags |= frameFlagStored
+		} else {
+			flags &^= frameFlagStored
+		}
+
+		if w.maxFrameOut == 0 || overhead+len(compressed) <= w.maxFrameOut {
+			break
+		}
+		if len(p) == 1 {
+			return 0, fmt.Errorf("frame of 1 byte compresses to %d bytes, exceeding the %d-byte frame limit", overhead+len(compressed), w.maxFrameOut)
+		}
+		p = p[:len(p)/2]
+	}
+	if w.adaptive != nil {
+		w.compressor.setLevel(w.adaptive.observe(len(p), len(compressed)))
 	}
 
-	// Write compressed data
-	if _, err := w.w.Write(compressed); err != nil {
-		return fmt.Errorf("write compressed: %w", err)
+	if len(compressed) > int(frameSizeMask) {
+		return 0, fmt.Errorf("compressed frame of %d bytes exceeds framing limit", len(compressed))
 	}
+	header := make([]byte, FrameHeaderSize)
+	putFrameHeader(header, len(compressed), flags)
 
-	// Reset buffer
-	w.bufSize = 0
+	// Optional trailer: CRC32C of the compressed payload
+	var trailer []byte
+	if w.checksum {
+		trailer = binary.LittleEndian.AppendUint32(nil, crc32.Checksum(compressed, crc32cTable))
+	}
 
-	return nil
+	if w.sink != nil {
+		// Hand the frame to the background writer, waiting for capacity
+		chunks := [][]byte{header, compressed}
+		if trailer != nil {
+			chunks = append(chunks, trailer)
+		}
+		if err := w.sink.enqueue(chunks...); err != nil {
+			return 0, fmt.Errorf("write frame: %w", err)
+		}
+	} else {
+		if err := writeFull(w.w, header); err != nil {
+			return 0, fmt.Errorf("write header: %w", err)
+		}
+
+		// Write compressed data
+		if err := writeFull(w.w, compressed); err != nil {
+			return 0, fmt.Errorf("write compressed: %w", err)
+		}
+
+		if trailer != nil {
+			if err := writeFull(w.w, trailer); err != nil {
+				return 0, fmt.Errorf("write checksum: %w", err)
+			}
+		}
+	}
+	if w.frames != nil {
+		w.frames.add(FrameHeaderSize+len(compressed)+len(trailer), len(p))
+	}
+
+	return len(p), nil
 }
 
 // Close flushes any buffered data, writes final compressed frame, and releases resources.
 //
 // You must call Close() to ensure all data is written. Calling Close() multiple
 // times is safe and has no effect after the first call.
+//
+// If an earlier write to the underlying writer failed, the stream may end in
+// a partially written frame. Close then writes nothing more and returns the
+// original error, so the broken stream is not mistaken for a complete one.
 func (w *Writer) Close() error {
 	if w.closed {
 		return nil
 	}
 	w.closed = true
 
-	// Flush any remaining buffered data
+	// Close compressor and wait for queued frames, whatever happens below
+	defer w.freeBuffer()
+	defer w.compressor.Close()
+	if w.sink != nil {
+		defer w.sink.close()
+	}
+
+	// After a failed write the destination may end in a partial frame, and
+	// writing more would only hide where the stream broke
+	if w.err != nil {
+		return w.err
+	}
+
+	// Flush any remaining buffered data, honoring content-defined boundaries
+	if w.cdc != nil {
+		if err := w.flushCuts(true); err != nil {
+			w.err = err
+			return err
+		}
+	}
 	if w.bufSize > 0 {
 		if err := w.flush(); err != nil {
-			w.compressor.Close()
+			w.err = err
 			return err
 		}
 	}
 
+	if w.inputSize == 0 && w.emptyPolicy == EmptyNoOutput {
+		if w.sink != nil {
+			if err := w.sink.close(); err != nil {
+				return fmt.Errorf("write frame: %w", err)
+			}
+		}
+		return w.writeIndex()
+	}
+
 	// Write end-of-stream marker (zero-length frame)
 	header := []byte{0, 0, 0, 0}
-	if _, err := w.w.Write(header); err != nil {
-		w.compressor.Close()
+	if w.sink != nil {
+		if err := w.sink.enqueue(header); err != nil {
+			return fmt.Errorf("write end marker: %w", err)
+		}
+		if err := w.sink.close(); err != nil {
+			return fmt.Errorf("write frame: %w", err)
+		}
+		return w.writeIndex()
+	}
+	if err := writeFull(w.w, header); err != nil {
 		return fmt.Errorf("write end marker: %w", err)
 	}
 
-	// Close compressor
-	w.compressor.Close()
+	return w.writeIndex()
+}
 
+// writeIndex writes the frame index of the stream, if WithIndexSidecar is
+// set.
+func (w *Writer) writeIndex() error {
+	if w.index == nil {
+		return nil
+	}
+	if _, err := w.frames.WriteTo(w.index); err != nil {
+		return fmt.Errorf("write index: %w", err)
+	}
 	return nil
 }
 
+// ContentSum returns the digest of all uncompressed bytes written to the stream.
+//
+// It returns nil if no hash was configured with WithContentHash or if the
+// Writer has not been closed yet, since the digest is only final once all
+// data has been written.
+func (w *Writer) ContentSum() []byte {
+	if w.hash == nil || !w.closed {
+		return nil
+	}
+	return w.hash.Sum(nil)
+}
+
 // Reset resets the Writer to write to a new underlying writer.
 //
 // This allows reuse of the Writer and its internal compressor context for
 // better performance when compressing multiple streams.
 //
-// If the Writer was previously closed, Reset will create a new compressor.
+// All options the Writer was created with, including frame size and
+// compressor options, are preserved. If the Writer was previously closed,
+// Reset creates a new compressor context with the same options; use
+// ResetWithOptions to change the configuration.
 //
 // Example:
 //
@@ -249,30 +782,238 @@ func (w *Writer) Reset(writer io.Writer) error {
 		return fmt.Errorf("nil writer")
 	}
 
-	// Flush any pending data first
-	if !w.closed && w.bufSize > 0 {
-		if err := w.flush(); err != nil {
-			return err
-		}
+	if err := w.detach(); err != nil {
+		return err
+	}
+	if w.sink != nil {
+		w.sink = newAsyncSink(writer, w.maxInFlight)
 	}
 
 	// If closed, need to recreate compressor
 	if w.closed || w.compressor == nil {
-		compressor, err := NewCompressor()
+		compressor, err := NewCompressor(w.compressorOpts...)
 		if err != nil {
 			return fmt.Errorf("create compressor: %w", err)
 		}
+		compressor.unclosed.kind = "Writer"
 		w.compressor = compressor
 	}
+	if w.buf == nil {
+		w.allocBuffer()
+	}
 
 	// Reset state
 	w.w = writer
 	w.bufSize = 0
+	w.inputSize = 0
 	w.closed = false
 	w.err = nil
+	if w.cdc != nil {
+		w.cdc.reset()
+	}
+	if w.hash != nil {
+		w.hash.Reset()
+	}
+	if w.frames != nil {
+		w.frames = &FrameIndex{}
+	}
+	if w.adaptive != nil {
+		w.adaptive.reset()
+		w.compressor.setLevel(w.adaptive.level)
+	}
+
+	return nil
+}
+
+// ResetWithOptions is like Reset but also reconfigures the Writer, as if it
+// had been created with NewWriter(writer, opts...). Options the Writer was
+// previously created with are discarded, not merged.
+//
+// A new compressor context is created for the new options. If any option is
+// invalid, the Writer is left unchanged and the error is returned.
+//
+// Example:
+//
+//	writer.Close()
+//	writer.ResetWithOptions(file2, openzl.WithFrameSize(256*1024))
+func (w *Writer) ResetWithOptions(writer io.Writer, opts ...WriterOption) error {
+	if writer == nil {
+		return fmt.Errorf("nil writer")
+	}
+
+	cfg, err := newWriterConfig(opts)
+	if err != nil {
+		return err
+	}
+	compressor, err := NewCompressor(cfg.compressorOpts...)
+	if err != nil {
+		cfg.freeBuffer()
+		return fmt.Errorf("create compressor: %w", err)
+	}
+
+	if err := w.detach(); err != nil {
+		compressor.Close()
+		cfg.freeBuffer()
+		return err
+	}
+	if !w.closed && w.compressor != nil {
+		w.compressor.Close()
+	}
+	w.freeBuffer()
+
+	compressor.unclosed.kind = "Writer"
+	cfg.w = writer
+	cfg.compressor = compressor
+	if cfg.adaptive != nil {
+		compressor.setLevel(cfg.adaptive.level)
+	}
+	if cfg.maxInFlight > 0 {
+		cfg.sink = newAsyncSink(writer, cfg.maxInFlight)
+	}
+	if cfg.hash != nil {
+		cfg.hash.Reset()
+	}
+	*w = *cfg
+
+	return nil
+}
+
+// detach flushes pending data to the current destination and waits for any
+// queued frames, so that the Writer can be pointed at a new one.
+func (w *Writer) detach() error {
+	// Flush any pending data first, unless the destination already failed
+	if !w.closed && w.err == nil && w.bufSize > 0 {
+		if err := w.flush(); err != nil {
+			return err
+		}
+	}
+
+	// Drain the previous destination before switching to the new one
+	if w.sink != nil {
+		if err := w.sink.close(); err != nil && !w.closed {
+			return fmt.Errorf("write frame: %w", err)
+		}
+	}
+	return nil
+}
+
+// CompressStream compresses everything read from src into a Writer stream
+// written to dst, and returns the number of compressed bytes written.
+//
+// It is a convenience for single transfers that creates, fills, and closes
+// the Writer; opts are passed to NewWriter. The output can be read back with
+// NewReader or DecompressTo.
+//
+// Example:
+//
+//	in, _ := os.Open("access.log")
+//	out, _ := os.Create("access.log.zl")
+//	written, err := openzl.CompressStream(out, in, openzl.WithFrameChecksum(true))
+//
+// Returns the first error from reading src, compressing, or writing dst. If
+// reading src fails, dst holds an incomplete stream without an end marker
+// or index sidecar, which a Reader created with WithAllowTruncated reports
+// as io.ErrUnexpectedEOF.
+func CompressStream(dst io.Writer, src io.Reader, opts ...WriterOption) (written int64, err error) {
+	cw := &countingWriter{w: dst}
+	w, err := NewWriter(cw, opts...)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := io.Copy(w, src); err != nil {
+		// Abandon the stream without an end marker or index, so that readers
+		// see it is truncated rather than taking it for all of src
+		if w.err == nil {
+			w.err = err
+		}
+		w.Close()
+		return cw.n, err
+	}
+	if err := w.Close(); err != nil {
+		return cw.n, err
+	}
+	return cw.n, nil
+}
 
+// CompressFrom compresses everything read from r and returns it as a Writer
+// stream. It is equivalent to CompressStream into a buffer.
+//
+// Example:
+//
+//	resp, _ := http.Get(url)
+//	defer resp.Body.Close()
+//	compressed, err := openzl.CompressFrom(resp.Body)
+func CompressFrom(r io.Reader, opts ...WriterOption) ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := CompressStream(&buf, r, opts...); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Transcode decodes the compressed data read from src and re-encodes it as a
+// Writer stream written to dst, and returns the number of compressed bytes
+// written.
+//
+// src may be a Writer stream or a bare frame such as the output of Compress.
+// Data is decoded and re-encoded frame by frame in a single pass, so memory
+// use is bounded by the frame sizes on both sides. opts are passed to
+// NewWriter, which makes Transcode the tool for moving historical data to a
+// new frame size, checksum setting, or compression configuration.
+//
+// Example:
+//
+//	in, _ := os.Open("2024.zl")
+//	out, _ := os.Create("2024.new.zl")
+//	_, err := openzl.Transcode(out, in, openzl.WithFrameSize(openzl.MaxFrameSize), openzl.WithFrameChecksum(true))
+//
+// Returns the first error from decoding src, compressing, or writing dst. If
+// decoding src fails, including for a src cut short anywhere, even on a
+// frame boundary, dst holds an incomplete stream without an end marker, so
+// no data is silently lost.
+func Transcode(dst io.Writer, src io.Reader, opts ...WriterOption) (written int64, err error) {
+	// A source cut short on a frame boundary must fail too, or the archive
+	// would be re-encoded as a complete, shorter one
+	r, err := NewReader(src, WithBareFrames(true), WithAllowTruncated(true))
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+	return CompressStream(dst, r, opts...)
+}
+
+// writeFull writes all of p to w.
+//
+// The io.Writer contract requires an error whenever fewer than len(p) bytes
+// are written, but some writers return short counts without one. Such short
+// writes are retried as long as they make progress, so a frame is never
+// silently truncated; a write that makes no progress fails with
+// io.ErrShortWrite.
+func writeFull(w io.Writer, p []byte) error {
+	for len(p) > 0 {
+		n, err := w.Write(p)
+		if err != nil {
+			return err
+		}
+		if n <= 0 {
+			return io.ErrShortWrite
+		}
+		p = p[min(n, len(p)):]
+	}
 	return nil
 }
 
+// countingWriter counts the bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
 // Ensure Writer implements io.WriteCloser
 var _ io.WriteCloser = (*Writer)(nil)
@@ -0,0 +1,19 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import "github.com/borischu/go-openzl/zlapi"
+
+// The concrete types satisfy the interfaces of package zlapi, which code can
+// depend on to be tested without cgo.
+var (
+	_ zlapi.Compressor      = (*Compressor)(nil)
+	_ zlapi.BatchCompressor = (*Compressor)(nil)
+	_ zlapi.Decompressor    = (*Decompressor)(nil)
+	_ zlapi.Codec           = (*FallbackCompressor)(nil)
+	_ zlapi.Compressor      = (*ShadowCompressor)(nil)
+	_ zlapi.Codec           = (*Pipeline)(nil)
+	_ zlapi.Writer          = (*Writer)(nil)
+	_ zlapi.Reader          = (*Reader)(nil)
+)
@@ -0,0 +1,248 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package zlapi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+
+	"github.com/borischu/go-openzl/spec"
+)
+
+// Stream framing of openzl.Writer, see package spec. Passthrough streams
+// hold stored frames only, which need no OpenZL to read or write.
+const (
+	frameHeaderSize   = spec.FrameHeaderSize
+	frameChecksumSize = spec.FrameChecksumSize
+	frameFlagChecksum = spec.FlagChecksum
+	frameFlagStored   = spec.FlagStored
+	frameSizeMask     = spec.SizeMask
+
+	// passthroughFrameSize is the data size of a passthrough frame, that of
+	// openzl.DefaultFrameSize.
+	passthroughFrameSize = 64 * 1024
+)
+
+// errCompressedFrame is returned when a passthrough Reader meets a frame
+// compressed by OpenZL.
+var errCompressedFrame = errors.New("openzl: compressed frame cannot be read without OpenZL")
+
+var crc32cTable = crc32.MakeTable(spec.ChecksumPolynomial)
+
+var (
+	_ Codec           = Passthrough
+	_ BatchCompressor = Passthrough
+	_ Writer          = (*PassthroughWriter)(nil)
+	_ Reader          = (*PassthroughReader)(nil)
+)
+
+// Passthrough is a Codec that leaves data uncompressed, for tests of code
+// that compresses through the interfaces of this package.
+//
+// Compress returns a stream in the format of openzl.Writer, made of frames
+// flagged as stored, so that its output round-trips through Decompress and
+// can also be read by openzl.NewReader and openzl.DecompressTo. Like the real
+// Compressor, it rejects empty input with ErrEmptyInput.
+var Passthrough PassthroughCodec
+
+// PassthroughCodec is the type of Passthrough.
+type PassthroughCodec struct{}
+
+// Compress returns src framed as a stream of stored frames.
+func (PassthroughCodec) Compress(src []byte) ([]byte, error) {
+	if len(src) == 0 {
+		return nil, ErrEmptyInput
+	}
+	var buf bytes.Buffer
+	buf.Grow(len(src) + (len(src)/passthroughFrameSize+2)*frameHeaderSize)
+	w := NewPassthroughWriter(&buf)
+	w.Write(src)
+	w.Close()
+	return buf.Bytes(), nil
+}
+
+// Decompress returns the data of a stream of stored frames.
+func (PassthroughCodec) Decompress(src []byte) ([]byte, error) {
+	if len(src) == 0 {
+		return nil, ErrEmptyInput
+	}
+	return io.ReadAll(NewPassthroughReader(bytes.NewReader(src)))
+}
+
+// CompressBatch compresses each element of srcs independently.
+func (p PassthroughCodec) CompressBatch(srcs [][]byte) ([][]byte, error) {
+	frames := make([][]byte, len(srcs))
+	for i, src := range srcs {
+		frame, err := p.Compress(src)
+		if err != nil {
+			return nil, err
+		}
+		frames[i] = frame
+	}
+	return frames, nil
+}
+
+// PassthroughWriter is a Writer that frames data without compressing it. Its
+// output can be read by PassthroughReader and by openzl.Reader.
+type PassthroughWriter struct {
+	w      io.Writer
+	buf    []byte
+	err    error
+	closed bool
+}
+
+// NewPassthroughWriter creates a PassthroughWriter writing to w.
+func NewPassthroughWriter(w io.Writer) *PassthroughWriter {
+	return &PassthroughWriter{w: w}
+}
+
+// Write buffers p and writes every full frame.
+func (w *PassthroughWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errors.New("write to closed Writer")
+	}
+	if w.err != nil {
+		return 0, w.err
+	}
+	n := len(p)
+	for len(p) > 0 {
+		k := min(len(p), passthroughFrameSize-len(w.buf))
+		w.buf = append(w.buf, p[:k]...)
+		p = p[k:]
+		if len(w.buf) == passthroughFrameSize {
+			if w.err = w.writeFrame(); w.err != nil {
+				return n - len(p), w.err
+			}
+		}
+	}
+	return n, nil
+}
+
+// writeFrame writes the buffered data as a stored frame.
+func (w *PassthroughWriter) writeFrame() error {
+	var header [frameHeaderSize]byte
+	binary.LittleEndian.PutUint32(header[:], uint32(len(w.buf))|frameFlagStored)
+	if _, err := w.w.Write(header[:]); err != nil {
+		return err
+	}
+	if _, err := w.w.Write(w.buf); err != nil {
+		return err
+	}
+	w.buf = w.buf[:0]
+	return nil
+}
+
+// Close writes the buffered data and the end-of-stream marker. Calling Close
+// more than once has no effect.
+func (w *PassthroughWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if w.err != nil {
+		return w.err
+	}
+	if len(w.buf) > 0 {
+		if err := w.writeFrame(); err != nil {
+			return err
+		}
+	}
+	_, err := w.w.Write(make([]byte, frameHeaderSize))
+	return err
+}
+
+// Reset discards any buffered data and makes the writer write a new stream
+// to dst.
+func (w *PassthroughWriter) Reset(dst io.Writer) error {
+	*w = PassthroughWriter{w: dst, buf: w.buf[:0]}
+	return nil
+}
+
+// PassthroughReader is a Reader for streams of stored frames, such as the
+// output of PassthroughWriter or of an openzl.Writer created with
+// WithStoredFallback on incompressible data. Frame checksums are verified.
+// A frame compressed by OpenZL cannot be read and fails the stream.
+type PassthroughReader struct {
+	r   io.Reader
+	buf []byte
+	pos int
+	err error
+}
+
+// NewPassthroughReader creates a PassthroughReader reading from r.
+func NewPassthroughReader(r io.Reader) *PassthroughReader {
+	return &PassthroughReader{r: r}
+}
+
+// Read reads the data of the stream into p.
+func (r *PassthroughReader) Read(p []byte) (int, error) {
+	for r.pos == len(r.buf) {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.err = r.readFrame()
+	}
+	n := copy(p, r.buf[r.pos:])
+	r.pos += n
+	return n, nil
+}
+
+// readFrame reads the next frame into buf, or returns io.EOF at the end of
+// the stream.
+func (r *PassthroughReader) readFrame() error {
+	var header [frameHeaderSize]byte
+	if _, err := io.ReadFull(r.r, header[:]); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return io.EOF
+		}
+		return fmt.Errorf("read header: %w", err)
+	}
+	v := binary.LittleEndian.Uint32(header[:])
+	size, flags := int(v&frameSizeMask), v&^frameSizeMask
+	if size == 0 {
+		if flags != 0 {
+			return fmt.Errorf("%w: flags set on end-of-stream marker", ErrCorruptedData)
+		}
+		return io.EOF
+	}
+	if flags&frameFlagStored == 0 {
+		return errCompressedFrame
+	}
+
+	r.buf, r.pos = make([]byte, size), 0
+	if _, err := io.ReadFull(r.r, r.buf); err != nil {
+		r.buf = nil
+		return io.ErrUnexpectedEOF
+	}
+	if flags&frameFlagChecksum != 0 {
+		var trailer [frameChecksumSize]byte
+		if _, err := io.ReadFull(r.r, trailer[:]); err != nil {
+			r.buf = nil
+			return io.ErrUnexpectedEOF
+		}
+		if binary.LittleEndian.Uint32(trailer[:]) != crc32.Checksum(r.buf, crc32cTable) {
+			r.buf = nil
+			return ErrChecksumMismatch
+		}
+	}
+	return nil
+}
+
+// Close releases the buffered data. It does not close the underlying reader.
+func (r *PassthroughReader) Close() error {
+	r.buf, r.pos = nil, 0
+	r.err = errors.New("read from closed Reader")
+	return nil
+}
+
+// Reset discards any buffered data and makes the reader read a new stream
+// from src.
+func (r *PassthroughReader) Reset(src io.Reader) error {
+	*r = PassthroughReader{r: src}
+	return nil
+}
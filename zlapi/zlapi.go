@@ -0,0 +1,106 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Package zlapi defines small interfaces for the compression operations of
+// go-openzl, so that code using them can be tested without OpenZL.
+//
+// Package openzl needs cgo and the OpenZL library to compile. This package
+// imports neither, so code that depends only on these interfaces, and its
+// unit tests, build with CGO_ENABLED=0; only the program that wires in the
+// real implementation needs cgo. openzl.Compressor, openzl.Decompressor,
+// openzl.FallbackCompressor, openzl.ShadowCompressor, openzl.Pipeline,
+// openzl.Writer, and openzl.Reader satisfy the interfaces below, and the
+// Passthrough implementations stand in for them where the library is
+// missing.
+//
+// Example:
+//
+//	type Store struct {
+//		codec zlapi.Codec // *openzl.FallbackCompressor in production
+//	}
+//
+//	func TestStore(t *testing.T) {
+//		s := Store{codec: zlapi.CodecFuncs{
+//			CompressFunc:   func(b []byte) ([]byte, error) { return b, nil },
+//			DecompressFunc: func(b []byte) ([]byte, error) { return b, nil },
+//		}}
+//		...
+//	}
+package zlapi
+
+import (
+	"errors"
+	"io"
+)
+
+// Errors shared with package openzl, which re-exports them, so that
+// errors.Is gives the same answer with the real implementations and the
+// Passthrough ones.
+var (
+	// ErrEmptyInput indicates that the input buffer is empty
+	ErrEmptyInput = errors.New("openzl: empty input")
+
+	// ErrCorruptedData indicates that the compressed data is corrupted
+	ErrCorruptedData = errors.New("openzl: corrupted data")
+
+	// ErrChecksumMismatch indicates that a frame failed its CRC32C check
+	ErrChecksumMismatch = errors.New("openzl: frame checksum mismatch")
+)
+
+// Compressor compresses a payload into a frame.
+type Compressor interface {
+	Compress(src []byte) ([]byte, error)
+}
+
+// Decompressor restores a payload from a frame.
+type Decompressor interface {
+	Decompress(src []byte) ([]byte, error)
+}
+
+// Codec compresses and decompresses payloads.
+type Codec interface {
+	Compressor
+	Decompressor
+}
+
+// BatchCompressor compresses many payloads in one call, returning one frame
+// per payload.
+type BatchCompressor interface {
+	CompressBatch(srcs [][]byte) ([][]byte, error)
+}
+
+// Writer compresses a stream written to it. openzl.Writer satisfies it.
+type Writer interface {
+	io.WriteCloser
+
+	// Reset discards the Writer's state and makes it write a new stream
+	// to w.
+	Reset(w io.Writer) error
+}
+
+// Reader decompresses a stream read from it. openzl.Reader satisfies it.
+type Reader interface {
+	io.ReadCloser
+
+	// Reset discards the Reader's state and makes it read a new stream
+	// from r.
+	Reset(r io.Reader) error
+}
+
+// CompressFunc adapts a function to the Compressor interface.
+type CompressFunc func(src []byte) ([]byte, error)
+
+// Compress calls f(src).
+func (f CompressFunc) Compress(src []byte) ([]byte, error) { return f(src) }
+
+// DecompressFunc adapts a function to the Decompressor interface.
+type DecompressFunc func(src []byte) ([]byte, error)
+
+// Decompress calls f(src).
+func (f DecompressFunc) Decompress(src []byte) ([]byte, error) { return f(src) }
+
+// CodecFuncs adapts a pair of functions to the Codec interface.
+type CodecFuncs struct {
+	CompressFunc
+	DecompressFunc
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package zlapi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"testing"
+)
+
+func TestCodecFuncs(t *testing.T) {
+	errCorrupt := errors.New("corrupt")
+	var codec Codec = CodecFuncs{
+		CompressFunc: func(src []byte) ([]byte, error) {
+			return append([]byte{'z'}, src...), nil
+		},
+		DecompressFunc: func(src []byte) ([]byte, error) {
+			if len(src) == 0 || src[0] != 'z' {
+				return nil, errCorrupt
+			}
+			return src[1:], nil
+		},
+	}
+
+	frame, err := codec.Compress([]byte("payload"))
+	if err != nil {
+		t.Fatalf("Compress() failed: %v", err)
+	}
+	got, err := codec.Decompress(frame)
+	if err != nil || !bytes.Equal(got, []byte("payload")) {
+		t.Errorf("Decompress() = %q, %v, want %q", got, err, "payload")
+	}
+	if _, err := codec.Decompress([]byte("x")); !errors.Is(err, errCorrupt) {
+		t.Errorf("Decompress(invalid) error = %v, want %v", err, errCorrupt)
+	}
+}
+
+func TestPassthrough(t *testing.T) {
+	for _, size := range []int{1, 100, passthroughFrameSize, 2*passthroughFrameSize + 7} {
+		data := bytes.Repeat([]byte{byte(size)}, size)
+		frame, err := Passthrough.Compress(data)
+		if err != nil {
+			t.Fatalf("Compress(%d bytes) failed: %v", size, err)
+		}
+		got, err := Passthrough.Decompress(frame)
+		if err != nil || !bytes.Equal(got, data) {
+			t.Errorf("Decompress(Compress(%d bytes)) = %d bytes, %v", size, len(got), err)
+		}
+	}
+	if _, err := Passthrough.Compress(nil); !errors.Is(err, ErrEmptyInput) {
+		t.Errorf("Compress(nil) error = %v, want ErrEmptyInput", err)
+	}
+
+	frame, _ := Passthrough.Compress([]byte("payload"))
+	frame[3] &^= byte(frameFlagStored >> 24)
+	if _, err := Passthrough.Decompress(frame); !errors.Is(err, errCompressedFrame) {
+		t.Errorf("Decompress(compressed frame) error = %v, want errCompressedFrame", err)
+	}
+}
+
+func TestPassthroughWriterReader(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewPassthroughWriter(&buf)
+	for range 3 {
+		w.Write(bytes.Repeat([]byte("stream "), passthroughFrameSize/5))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	want := bytes.Repeat([]byte("stream "), 3*(passthroughFrameSize/5))
+
+	r := NewPassthroughReader(bytes.NewReader(buf.Bytes()))
+	got, err := io.ReadAll(r)
+	if err != nil || !bytes.Equal(got, want) {
+		t.Errorf("ReadAll() = %d bytes, %v, want %d bytes", len(got), err, len(want))
+	}
+
+	// A checksummed stored frame, as written by openzl.Writer
+	payload := []byte("checked")
+	stream := binary.LittleEndian.AppendUint32(nil, uint32(len(payload))|frameFlagStored|frameFlagChecksum)
+	stream = append(stream, payload...)
+	stream = binary.LittleEndian.AppendUint32(stream, crc32.Checksum(payload, crc32cTable))
+	stream = append(stream, 0, 0, 0, 0)
+	r.Reset(bytes.NewReader(stream))
+	if got, err := io.ReadAll(r); err != nil || !bytes.Equal(got, payload) {
+		t.Errorf("ReadAll(checksummed) = %q, %v, want %q", got, err, payload)
+	}
+	stream[frameHeaderSize] ^= 1
+	r.Reset(bytes.NewReader(stream))
+	if _, err := io.ReadAll(r); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("ReadAll(damaged) error = %v, want ErrChecksumMismatch", err)
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2025 Boris Chu and contributors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openzl
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/borischu/go-openzl/zlapi"
+)
+
+func TestPassthroughInterop(t *testing.T) {
+	data := bytes.Repeat([]byte("passthrough "), 20000)
+	frame, err := zlapi.Passthrough.Compress(data)
+	if err != nil {
+		t.Fatalf("Passthrough.Compress() failed: %v", err)
+	}
+	r, err := NewReader(bytes.NewReader(frame))
+	if err != nil {
+		t.Fatalf("NewReader() failed: %v", err)
+	}
+	defer r.Close()
+	if got, err := io.ReadAll(r); err != nil || !bytes.Equal(got, data) {
+		t.Errorf("Reader on passthrough output = %d bytes, %v, want %d bytes", len(got), err, len(data))
+	}
+
+	// Incompressible data written with WithStoredFallback is all stored
+	random := make([]byte, 3*MinFrameSize)
+	rand.New(rand.NewSource(1)).Read(random)
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, WithFrameSize(MinFrameSize), WithStoredFallback(true), WithFrameChecksum(true))
+	if err != nil {
+		t.Fatalf("NewWriter() failed: %v", err)
+	}
+	w.Write(random)
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if got, err := io.ReadAll(zlapi.NewPassthroughReader(&buf)); err != nil || !bytes.Equal(got, random) {
+		t.Errorf("PassthroughReader on stored stream = %d bytes, %v, want %d bytes", len(got), err, len(random))
+	}
+}